@@ -0,0 +1,340 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+)
+
+var (
+	errAuthUsersNotFound  = errors.New("unable to find users resource for authentication")
+	errInvalidOAuthKey    = errors.New("invalid oauth key, expected a PEM encoded RSA private key")
+	errRulesWithoutAuth   = errors.New("access rules require either auth or oauth to be enabled")
+	errCSRFWithoutSession = errors.New("csrf tokens require session to be enabled")
+	errInvalidUsersFile   = errors.New("invalid users file, expected a JSON object of passwords by username")
+	errUnknownAuthScope   = errors.New("unknown auth scope, expected all or writes")
+	errConflictingAuth    = errors.New("auth-token and users cannot be combined with auth, as both use the Authorization header")
+)
+
+// Scopes of the requests requiring the static credentials.
+const (
+	authScopeAll    = "all"
+	authScopeWrites = "writes"
+)
+
+// addAuthFlags adds the flags of the mock authentication flow to the command.
+func addAuthFlags(cmd *cobra.Command) {
+	// Optional flag to enable the mock authentication flow.
+	cmd.Flags().Bool("auth", false, "Enable the login and register endpoints, and require an access token for protected resources")
+	// Optional flags to configure the mock authentication flow.
+	cmd.Flags().String("auth-users", "users", "Resource holding the users, with email and password fields")
+	cmd.Flags().StringArray("auth-protect", nil, "Resource requiring an access token. Can be repeated, defaults to all resources")
+	cmd.Flags().String("auth-secret", "", "Secret to sign access tokens with, defaults to a random one")
+	cmd.Flags().Duration("auth-expiry", time.Hour, "Lifetime of access tokens")
+	// Optional flags to require static credentials, e.g. on shared networks.
+	cmd.Flags().String("auth-token", "", "Static access token required in an 'Authorization: Bearer <token>' header")
+	cmd.Flags().String("users", "", "JSON file of the passwords by username accepted with basic authentication")
+	cmd.Flags().String("auth-scope", authScopeAll, "Requests requiring the auth-token or users credentials, either all or writes")
+	// Optional flag to enable the mock OAuth2 and OpenID Connect provider.
+	cmd.Flags().Bool("oauth", false, "Enable the mock OAuth2 and OpenID Connect provider endpoints")
+	// Optional flags to configure the mock OAuth2 and OpenID Connect provider.
+	cmd.Flags().String("oauth-issuer", "", "Issuer of the provider, defaults to the server url")
+	cmd.Flags().String("oauth-claims", `{"sub": "1", "name": "John Doe", "email": "john@example.com"}`, "JSON object of the claims added to issued tokens")
+	cmd.Flags().String("oauth-key", "", "PEM encoded RSA private key to sign tokens with, defaults to a generated one")
+	cmd.Flags().Duration("oauth-expiry", time.Hour, "Lifetime of issued tokens")
+	// Optional flags to enable cookie sessions, each with a sandbox of the data.
+	cmd.Flags().Bool("session", false, "Enable cookie sessions, with the session login and logout endpoints, serving every session with a sandbox of the data")
+	cmd.Flags().Duration("session-expiry", 24*time.Hour, "Lifetime of sessions")
+	cmd.Flags().Int("session-max", 1000, "Maximum number of live sessions, the ones expiring first are dropped on login once more")
+	cmd.Flags().Bool("csrf", false, "Issue csrf tokens on /__csrf, and reject mutating requests of sessions without one. Requires --session")
+}
+
+// sessionFlags describes the cookie sessions.
+type sessionFlags struct {
+	usersKey    string
+	expiry      time.Duration
+	maxSessions int
+	csrf        bool
+}
+
+// parseSessionFlags returns the flags of the cookie sessions, or nil when disabled. Sessions authenticate the
+// users of the same resource as the mock authentication flow.
+func parseSessionFlags(cmd *cobra.Command, resourceKeys []string) (*sessionFlags, error) {
+	// Parse command's flags.
+	enabled, err := cmd.Flags().GetBool("session")
+	if err != nil {
+		return nil, fmt.Errorf("%w: session", errFailedParseFlag)
+	}
+
+	csrf, err := cmd.Flags().GetBool("csrf")
+	if err != nil {
+		return nil, fmt.Errorf("%w: csrf", errFailedParseFlag)
+	}
+
+	if !enabled {
+		if csrf {
+			return nil, errCSRFWithoutSession
+		}
+
+		return nil, nil
+	}
+
+	usersKey, err := cmd.Flags().GetString("auth-users")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth-users", errFailedParseFlag)
+	}
+
+	expiry, err := cmd.Flags().GetDuration("session-expiry")
+	if err != nil || expiry <= 0 {
+		return nil, fmt.Errorf("%w: session-expiry", errFailedParseFlag)
+	}
+
+	maxSessions, err := cmd.Flags().GetInt("session-max")
+	if err != nil || maxSessions <= 0 {
+		return nil, fmt.Errorf("%w: session-max", errFailedParseFlag)
+	}
+
+	if !containsString(resourceKeys, usersKey) {
+		return nil, fmt.Errorf("%w: %s", errAuthUsersNotFound, usersKey)
+	}
+
+	return &sessionFlags{usersKey: usersKey, expiry: expiry, maxSessions: maxSessions, csrf: csrf}, nil
+}
+
+// authOptions returns the handler options of the mock authentication flow and OAuth provider. Tokens
+// expire based on the provided time source.
+func authOptions(cmd *cobra.Command, cfg *config.Config, resourceKeys []string, baseURL string, now func() time.Time) ([]handler.Option, error) {
+	opts := make([]handler.Option, 0)
+
+	oauthOpt, err := oauthOption(cmd, baseURL, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if oauthOpt != nil {
+		opts = append(opts, oauthOpt)
+	}
+
+	credentialsOpt, err := credentialsOption(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse command's flags.
+	enabled, err := cmd.Flags().GetBool("auth")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth", errFailedParseFlag)
+	}
+
+	if credentialsOpt != nil {
+		if enabled {
+			return nil, errConflictingAuth
+		}
+
+		opts = append(opts, credentialsOpt)
+	}
+
+	if !enabled {
+		// Access rules can only be checked against the tokens of the OAuth provider.
+		if cfg.HasAccessRules() && len(opts) == 0 {
+			return nil, errRulesWithoutAuth
+		}
+
+		return opts, nil
+	}
+
+	usersKey, err := cmd.Flags().GetString("auth-users")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth-users", errFailedParseFlag)
+	}
+
+	protected, err := cmd.Flags().GetStringArray("auth-protect")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth-protect", errFailedParseFlag)
+	}
+
+	secret, err := cmd.Flags().GetString("auth-secret")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth-secret", errFailedParseFlag)
+	}
+
+	expiry, err := cmd.Flags().GetDuration("auth-expiry")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth-expiry", errFailedParseFlag)
+	}
+
+	if !containsString(resourceKeys, usersKey) {
+		return nil, fmt.Errorf("%w: %s", errAuthUsersNotFound, usersKey)
+	}
+
+	secretBytes := []byte(secret)
+	if secret == "" {
+		secretBytes = make([]byte, 32)
+		if _, err = rand.Read(secretBytes); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens := auth.NewTokens(secretBytes, now, expiry)
+
+	return append(opts, handler.WithAuth(tokens, usersKey, protected...)), nil
+}
+
+// credentialsOption returns the handler option of the static credentials, or nil when there are none.
+func credentialsOption(cmd *cobra.Command) (handler.Option, error) {
+	// Parse command's flags.
+	token, err := cmd.Flags().GetString("auth-token")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth-token", errFailedParseFlag)
+	}
+
+	usersFile, err := cmd.Flags().GetString("users")
+	if err != nil {
+		return nil, fmt.Errorf("%w: users", errFailedParseFlag)
+	}
+
+	scope, err := cmd.Flags().GetString("auth-scope")
+	if err != nil {
+		return nil, fmt.Errorf("%w: auth-scope", errFailedParseFlag)
+	}
+
+	if scope != authScopeAll && scope != authScopeWrites {
+		return nil, fmt.Errorf("%w: %s", errUnknownAuthScope, scope)
+	}
+
+	if token == "" && usersFile == "" {
+		return nil, nil
+	}
+
+	users, err := loadUsers(usersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler.WithCredentials(token, users, scope == authScopeWrites), nil
+}
+
+// loadUsers reads the passwords by username of the users file, if any.
+func loadUsers(usersFile string) (map[string]string, error) {
+	if usersFile == "" {
+		return nil, nil
+	}
+
+	usersBytes, err := ioutil.ReadFile(usersFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidUsersFile, err)
+	}
+
+	users := make(map[string]string)
+	if err = json.Unmarshal(usersBytes, &users); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidUsersFile, err)
+	}
+
+	return users, nil
+}
+
+// oauthOption returns the handler option of the mock OAuth provider, or nil when disabled.
+func oauthOption(cmd *cobra.Command, baseURL string, now func() time.Time) (handler.Option, error) {
+	// Parse command's flags.
+	enabled, err := cmd.Flags().GetBool("oauth")
+	if err != nil {
+		return nil, fmt.Errorf("%w: oauth", errFailedParseFlag)
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	issuer, err := cmd.Flags().GetString("oauth-issuer")
+	if err != nil {
+		return nil, fmt.Errorf("%w: oauth-issuer", errFailedParseFlag)
+	}
+
+	claimsFlag, err := cmd.Flags().GetString("oauth-claims")
+	if err != nil {
+		return nil, fmt.Errorf("%w: oauth-claims", errFailedParseFlag)
+	}
+
+	keyFile, err := cmd.Flags().GetString("oauth-key")
+	if err != nil {
+		return nil, fmt.Errorf("%w: oauth-key", errFailedParseFlag)
+	}
+
+	expiry, err := cmd.Flags().GetDuration("oauth-expiry")
+	if err != nil {
+		return nil, fmt.Errorf("%w: oauth-expiry", errFailedParseFlag)
+	}
+
+	claims := make(auth.Claims)
+	if err = json.Unmarshal([]byte(claimsFlag), &claims); err != nil {
+		return nil, fmt.Errorf("%w: oauth-claims", errFailedParseFlag)
+	}
+
+	key, err := loadOAuthKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if issuer == "" {
+		issuer = baseURL
+	}
+
+	provider := auth.NewProvider(auth.NewRSATokens(key, now, expiry), issuer, claims)
+
+	return handler.WithOAuth(provider), nil
+}
+
+// loadOAuthKey reads a PEM encoded RSA private key, or generates a new one if no file is provided.
+func loadOAuthKey(keyFile string) (*rsa.PrivateKey, error) {
+	if keyFile == "" {
+		return rsa.GenerateKey(rand.Reader, 2048)
+	}
+
+	keyBytes, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidOAuthKey, err)
+	}
+
+	block, _ := pem.Decode(keyBytes)
+	if block == nil {
+		return nil, errInvalidOAuthKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, errInvalidOAuthKey
+	}
+
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errInvalidOAuthKey
+	}
+
+	return key, nil
+}
+
+func containsString(list []string, val string) bool {
+	for _, item := range list {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
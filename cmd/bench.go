@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/bench"
+)
+
+func newBenchCmd() *cobra.Command {
+	// benchCmd represents the bench command.
+	benchCmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Generate CRUD traffic against a running server and report latencies",
+		Long: `
+Generates a realistic mix of GET, POST, PUT, PATCH and DELETE requests against
+every resource of a running JSON server and reports latency percentiles per operation.
+Resources created during the run are deleted afterwards`,
+		RunE: runBench,
+	}
+
+	// Optional flag to set the target server.
+	benchCmd.Flags().StringP("target", "t", "http://localhost:3000", "Base URL of the target server")
+	// Optional flag to set the number of concurrent workers.
+	benchCmd.Flags().IntP("concurrency", "c", 10, "Number of concurrent workers")
+	// Optional flag to set the duration of the run.
+	benchCmd.Flags().DurationP("duration", "d", time.Second*10, "Duration of the run")
+
+	return benchCmd
+}
+
+func runBench(cmd *cobra.Command, _ []string) error {
+	// Parse command's flags.
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return fmt.Errorf("%w: target", errFailedParseFlag)
+	}
+
+	concurrency, err := cmd.Flags().GetInt("concurrency")
+	if err != nil {
+		return fmt.Errorf("%w: concurrency", errFailedParseFlag)
+	}
+
+	duration, err := cmd.Flags().GetDuration("duration")
+	if err != nil {
+		return fmt.Errorf("%w: duration", errFailedParseFlag)
+	}
+
+	fmt.Printf("Running benchmark against %s with %d workers for %v\n\n", target, concurrency, duration)
+
+	report, err := bench.Run(context.Background(), bench.Config{
+		Target:      target,
+		Concurrency: concurrency,
+		Duration:    duration,
+	})
+	if err != nil {
+		return err
+	}
+
+	displayReport(report)
+
+	return nil
+}
+
+func displayReport(report *bench.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Operation\tCount\tErrors\tMean\tP50\tP90\tP99\tMax")
+	for _, s := range report.Operations {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%v\t%v\t%v\t%v\t%v\n",
+			s.Operation, s.Count, s.Errors, round(s.Mean), round(s.P50), round(s.P90), round(s.P99), round(s.Max))
+	}
+
+	// nolint
+	w.Flush()
+
+	seconds := report.Duration.Seconds()
+	if seconds == 0 {
+		seconds = 1
+	}
+
+	fmt.Printf("\nRequests:\t %d (%.1f/s)\n", report.Requests, float64(report.Requests)/seconds)
+	fmt.Printf("Errors:\t\t %d\n", report.Errors)
+}
+
+// round reduces duration precision for display purposes.
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
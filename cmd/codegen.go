@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/codegen"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/schema"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+var (
+	errUnknownLanguage      = errors.New("unknown language, expected ts or go")
+	errUnknownTypesLanguage = errors.New("unknown language, expected ts")
+)
+
+func newGenClientCmd() *cobra.Command {
+	// genClientCmd represents the gen-client command.
+	genClientCmd := &cobra.Command{
+		Use:   "gen-client [file]",
+		Short: "Generate a typed client of the resources of a data file",
+		Long: `
+Generates a small typed client, in typescript or go, calling the routes the server
+generates for the resources of a data file. The types of the records are inferred
+from the data, where fields missing from some records are optional`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGenClient,
+	}
+
+	// Optional flag to set the language.
+	genClientCmd.Flags().String("lang", "ts", "Language of the client, either ts or go")
+	// Optional flag to set the client file.
+	genClientCmd.Flags().StringP("out", "o", "", "Client file to write, defaults to client.ts or client.go")
+	// Optional flag to set the go package.
+	genClientCmd.Flags().String("package", "client", "Package of the go client")
+	// Optional flag to set the configuration file.
+	genClientCmd.Flags().String("config", "", "Configuration file customizing individual resources, as passed to the start command")
+	// Optional flag to overwrite an existing file.
+	genClientCmd.Flags().Bool("force", false, "Overwrite an existing file")
+
+	return genClientCmd
+}
+
+func runGenClient(cmd *cobra.Command, args []string) error {
+	// Parse command's flags.
+	lang, err := cmd.Flags().GetString("lang")
+	if err != nil {
+		return fmt.Errorf("%w: lang", errFailedParseFlag)
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("%w: out", errFailedParseFlag)
+	}
+
+	pkg, err := cmd.Flags().GetString("package")
+	if err != nil {
+		return fmt.Errorf("%w: package", errFailedParseFlag)
+	}
+
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return fmt.Errorf("%w: config", errFailedParseFlag)
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("%w: force", errFailedParseFlag)
+	}
+
+	resources, err := inferResources(args[0], configFile)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	switch lang {
+	case "ts":
+		content = []byte(codegen.TypeScriptClient(resources))
+	case "go":
+		if content, err = codegen.Go(pkg, resources); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %s", errUnknownLanguage, lang)
+	}
+
+	if out == "" {
+		out = "client." + lang
+	}
+
+	if err = writeFile(out, content, force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote the client of %d resources to %s\n", len(resources), out)
+
+	return nil
+}
+
+// inferResources returns the resources of the data file, with the types inferred from their records, as served
+// with the configuration file, if any. Hidden resources and private fields are left out.
+func inferResources(filename, configFile string) ([]codegen.Resource, error) {
+	contentBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errFileNotFound, filename)
+	}
+
+	data, err := storage.ParseDatabase(contentBytes, storage.ArrayResourceKey(filename))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errFailedParseFile, filename, err)
+	}
+
+	cfg := &config.Config{}
+	if configFile != "" {
+		if cfg, err = config.Load(configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	resourceKeys := make([]string, 0, len(data))
+	for resourceKey := range data {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	sort.Strings(resourceKeys)
+
+	privateFields := cfg.PrivateFields(resourceKeys)
+
+	resources := make([]codegen.Resource, 0, len(resourceKeys))
+	for _, resourceKey := range resourceKeys {
+		resourceConfig := cfg.Resources[resourceKey]
+		if resourceConfig.Hidden {
+			continue
+		}
+
+		t := schema.Infer(data[resourceKey])
+		for _, field := range privateFields[resourceKey] {
+			delete(t.Fields, field)
+		}
+
+		resources = append(resources, codegen.Resource{
+			Key:   resourceKey,
+			Type:  t,
+			Read:  !resourceConfig.WriteOnly,
+			Write: !resourceConfig.ReadOnly,
+		})
+	}
+
+	return resources, nil
+}
+
+// writeFile writes the content to the file, which must not exist unless forced.
+func writeFile(file string, content []byte, force bool) error {
+	if _, err := os.Stat(file); err == nil && !force {
+		return fmt.Errorf("%w: %s", errFileExists, file)
+	}
+
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+func newGenTypesCmd() *cobra.Command {
+	// genTypesCmd represents the gen-types command.
+	genTypesCmd := &cobra.Command{
+		Use:   "gen-types [file]",
+		Short: "Generate the type definitions of the records of a data file",
+		Long: `
+Generates the type definitions of the records of the resources of a data file, inferred
+from the data. Fields missing from some records are optional, and fields holding
+different types in different records are unions of the observed types`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGenTypes,
+	}
+
+	// Optional flag to set the language.
+	genTypesCmd.Flags().String("lang", "ts", "Language of the type definitions, only ts is supported")
+	// Optional flag to set the definitions file.
+	genTypesCmd.Flags().StringP("out", "o", "types.d.ts", "Type definitions file to write")
+	// Optional flag to set the configuration file.
+	genTypesCmd.Flags().String("config", "", "Configuration file customizing individual resources, as passed to the start command")
+	// Optional flag to overwrite an existing file.
+	genTypesCmd.Flags().Bool("force", false, "Overwrite an existing file")
+
+	return genTypesCmd
+}
+
+func runGenTypes(cmd *cobra.Command, args []string) error {
+	// Parse command's flags.
+	lang, err := cmd.Flags().GetString("lang")
+	if err != nil {
+		return fmt.Errorf("%w: lang", errFailedParseFlag)
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("%w: out", errFailedParseFlag)
+	}
+
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return fmt.Errorf("%w: config", errFailedParseFlag)
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("%w: force", errFailedParseFlag)
+	}
+
+	if lang != "ts" {
+		return fmt.Errorf("%w: %s", errUnknownTypesLanguage, lang)
+	}
+
+	resources, err := inferResources(args[0], configFile)
+	if err != nil {
+		return err
+	}
+
+	if err = writeFile(out, []byte(codegen.TypeScriptTypes(resources)), force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote the types of %d resources to %s\n", len(resources), out)
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPidFile is used when running as daemon without an explicit pid file.
+const defaultPidFile = "json-server.pid"
+
+var (
+	errAlreadyRunning  = errors.New("server already running")
+	errNotRunning      = errors.New("server not running")
+	errFailedDaemonize = errors.New("failed to start server in background")
+	errInvalidPidFile  = errors.New("invalid pid file")
+)
+
+// daemonize starts a detached copy of the current process in the foreground mode, and waits until
+// it writes its pid file, which indicates that the server accepts connections.
+func daemonize(pidFile string) error {
+	if err := checkNotRunning(pidFile); err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errFailedDaemonize, err)
+	}
+
+	absPidFile, err := filepath.Abs(pidFile)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errFailedDaemonize, err)
+	}
+
+	// Later flags take precedence, so the child runs in foreground with the same pid file.
+	args := append(os.Args[1:], "--daemon=false", "--pid-file="+absPidFile)
+
+	child := exec.Command(executable, args...)
+	child.SysProcAttr = detachedProcAttr()
+
+	if err = child.Start(); err != nil {
+		return fmt.Errorf("%w: %v", errFailedDaemonize, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- child.Wait()
+	}()
+
+	timeout := time.After(time.Second * 10)
+	for {
+		select {
+		case err = <-exited:
+			return fmt.Errorf("%w: exited early: %v", errFailedDaemonize, err)
+		case <-timeout:
+			return fmt.Errorf("%w: timed out waiting for pid file", errFailedDaemonize)
+		case <-time.After(time.Millisecond * 50):
+			if pid, err := readPidFile(absPidFile); err == nil && pid == child.Process.Pid {
+				fmt.Printf("JSON Server running in background with pid %d\n", pid)
+				return nil
+			}
+		}
+	}
+}
+
+// checkNotRunning returns an error if the pid file belongs to a running process.
+func checkNotRunning(pidFile string) error {
+	pid, err := readPidFile(pidFile)
+	if err != nil {
+		// Missing or invalid pid files are overwritten.
+		return nil
+	}
+
+	if processRunning(pid) {
+		return fmt.Errorf("%w: pid %d", errAlreadyRunning, pid)
+	}
+
+	return nil
+}
+
+// writePidFile stores the pid of the current process.
+func writePidFile(pidFile string) error {
+	// On a graceful upgrade the pid file belongs to the previous process, which is about to stop.
+	if pid, err := readPidFile(pidFile); err == nil && pid == os.Getppid() && isUpgrade() {
+		return ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644)
+	}
+
+	if err := checkNotRunning(pidFile); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())), 0644)
+}
+
+// removePidFile deletes the pid file, unless it has been taken over by another process.
+func removePidFile(pidFile string) {
+	if pid, err := readPidFile(pidFile); err == nil && pid == os.Getpid() {
+		// nolint
+		os.Remove(pidFile)
+	}
+}
+
+// readPidFile returns the pid stored in the pid file.
+func readPidFile(pidFile string) (int, error) {
+	contentBytes, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(contentBytes)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("%w: %s", errInvalidPidFile, pidFile)
+	}
+
+	return pid, nil
+}
@@ -0,0 +1,29 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachedProcAttr starts the child in a new session, so it survives its parent and terminal.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
+
+// processRunning reports whether a process with the pid exists.
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	return process.Signal(syscall.Signal(0)) == nil
+}
+
+// stopProcess asks the process to gracefully shut down.
+func stopProcess(process *os.Process) error {
+	return process.Signal(os.Interrupt)
+}
@@ -0,0 +1,41 @@
+//go:build windows
+// +build windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// detachedProcAttr starts the child in a new process group without a console window.
+func detachedProcAttr() *syscall.SysProcAttr {
+	const createNoWindow = 0x08000000
+
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | createNoWindow}
+}
+
+// processRunning reports whether a process with the pid exists.
+func processRunning(pid int) bool {
+	const processQueryLimitedInformation = 0x1000
+
+	handle, err := syscall.OpenProcess(processQueryLimitedInformation, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(handle)
+
+	var exitCode uint32
+	if err = syscall.GetExitCodeProcess(handle, &exitCode); err != nil {
+		return false
+	}
+
+	const stillActive = 259
+
+	return exitCode == stillActive
+}
+
+// stopProcess terminates the process, as windows does not support sending interrupts to other processes.
+func stopProcess(process *os.Process) error {
+	return process.Kill()
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// execURLEnv is the environment variable holding the server URL, passed to the exec command.
+const execURLEnv = "JSON_SERVER_URL"
+
+// startExec starts the command through the system shell, with the server URL in its environment.
+func startExec(command, url string) (*exec.Cmd, error) {
+	var child *exec.Cmd
+	if runtime.GOOS == "windows" {
+		child = exec.Command("cmd", "/C", command)
+	} else {
+		child = exec.Command("sh", "-c", command)
+	}
+
+	child.Env = append(os.Environ(), execURLEnv+"="+url)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+
+	if err := child.Start(); err != nil {
+		return nil, err
+	}
+
+	return child, nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/fuzz"
+)
+
+var errFuzzFailures = errors.New("fuzzing found failures")
+
+func newFuzzCmd() *cobra.Command {
+	// fuzzCmd represents the fuzz command.
+	fuzzCmd := &cobra.Command{
+		Use:   "fuzz",
+		Short: "Send malformed requests against a running server and report failures",
+		Long: `
+Sends malformed bodies, weird query parameters, oversized payloads and invalid ids
+against every resource of a running JSON server. Any request that crashes the server
+or results in a 5xx response is reported. Runs are reproducible with the same seed`,
+		RunE: runFuzz,
+	}
+
+	// Optional flag to set the target server.
+	fuzzCmd.Flags().StringP("target", "t", "http://localhost:3000", "Base URL of the target server")
+	// Optional flag to set the number of requests.
+	fuzzCmd.Flags().IntP("iterations", "n", 1000, "Number of requests to send")
+	// Optional flag to set the random seed.
+	fuzzCmd.Flags().Int64P("seed", "s", 0, "Random seed, defaults to the current time")
+	// Optional flag to set the oversized payload size.
+	fuzzCmd.Flags().Int("max-payload", 1<<20, "Size in bytes of oversized payloads")
+
+	return fuzzCmd
+}
+
+func runFuzz(cmd *cobra.Command, _ []string) error {
+	// Parse command's flags.
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return fmt.Errorf("%w: target", errFailedParseFlag)
+	}
+
+	iterations, err := cmd.Flags().GetInt("iterations")
+	if err != nil {
+		return fmt.Errorf("%w: iterations", errFailedParseFlag)
+	}
+
+	seed, err := cmd.Flags().GetInt64("seed")
+	if err != nil {
+		return fmt.Errorf("%w: seed", errFailedParseFlag)
+	}
+
+	maxPayload, err := cmd.Flags().GetInt("max-payload")
+	if err != nil {
+		return fmt.Errorf("%w: max-payload", errFailedParseFlag)
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	fmt.Printf("Fuzzing %s with %d requests (seed %d)\n\n", target, iterations, seed)
+
+	report, err := fuzz.Run(context.Background(), fuzz.Config{
+		Target:     target,
+		Iterations: iterations,
+		Seed:       seed,
+		MaxPayload: maxPayload,
+	})
+	if report == nil {
+		return err
+	}
+
+	displayFuzzReport(report)
+
+	// Report data left behind by the run, after the failures.
+	if err != nil {
+		return err
+	}
+
+	if len(report.Failures) > 0 {
+		return errFuzzFailures
+	}
+
+	return nil
+}
+
+func displayFuzzReport(report *fuzz.Report) {
+	if len(report.Failures) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "Kind\tMethod\tPath\tStatus\tError")
+		for _, f := range report.Failures {
+			path := f.Path
+			if len(path) > 80 {
+				path = path[:80] + "..."
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", f.Kind, f.Method, path, f.StatusCode, f.Err)
+		}
+
+		// nolint
+		w.Flush()
+		fmt.Println()
+	}
+
+	fmt.Printf("Requests:\t %d\n", report.Requests)
+	fmt.Printf("Failures:\t %d\n", len(report.Failures))
+	fmt.Printf("Restored:\t %d\n", report.Restored)
+
+	if report.Unreachable {
+		fmt.Println("Server stopped responding during the run")
+	}
+}
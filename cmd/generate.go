@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/faker"
+	"github.com/chanioxaris/json-server/internal/generate"
+)
+
+func newGenerateCmd() *cobra.Command {
+	// generateCmd represents the generate command.
+	generateCmd := &cobra.Command{
+		Use:   "generate [template]",
+		Short: "Generate a data file from a template of random records",
+		Long: `
+Generates a data file from a template holding an object of fields per resource, e.g.
+'{"users": {"_count": 100, "name": "{{name}}", "email": "{{email}}"}}'. Every resource
+gets the number of records of its '_count' field, with sequential ids, and the
+placeholders of string fields are replaced by random values of their kind. The kinds
+are the ones of the '/__faker' endpoint`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGenerate,
+	}
+
+	// Optional flag to set the data file.
+	generateCmd.Flags().StringP("out", "o", "db.json", "Data file to write")
+	// Optional flag to set the seed of the random values.
+	generateCmd.Flags().Int64("seed", 0, "Random seed of the generated values, defaults to the current time")
+	// Optional flag to overwrite an existing file.
+	generateCmd.Flags().Bool("force", false, "Overwrite an existing file")
+
+	return generateCmd
+}
+
+func runGenerate(cmd *cobra.Command, args []string) error {
+	// Parse command's flags.
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("%w: out", errFailedParseFlag)
+	}
+
+	seed, err := cmd.Flags().GetInt64("seed")
+	if err != nil {
+		return fmt.Errorf("%w: seed", errFailedParseFlag)
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("%w: force", errFailedParseFlag)
+	}
+
+	records, err := generateFile(args[0], out, seed, force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d records to %s\n", records, out)
+
+	return nil
+}
+
+// generateFile writes the data generated from the template to the file, which must not exist unless forced. It
+// returns the number of generated records.
+func generateFile(templateFile, file string, seed int64, force bool) (int, error) {
+	tmpl, err := generate.Read(templateFile)
+	if err != nil {
+		return 0, err
+	}
+
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	data := tmpl.Generate(faker.New(seed))
+
+	if err = writeJSONFile(file, data, force); err != nil {
+		return 0, err
+	}
+
+	records := 0
+	for _, resources := range data {
+		records += len(resources)
+	}
+
+	return records, nil
+}
+
+// generateMissingFile writes the data generated from the template to the file, unless it exists, so the data
+// generated once is kept across restarts.
+func generateMissingFile(templateFile, file string, seed int64) error {
+	if _, err := os.Stat(file); err == nil {
+		return nil
+	}
+
+	_, err := generateFile(templateFile, file, seed, false)
+
+	return err
+}
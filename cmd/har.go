@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/har"
+)
+
+var errFileExists = errors.New("file already exists, use --force to overwrite it")
+
+func newFromHARCmd() *cobra.Command {
+	// fromHARCmd represents the from-har command.
+	fromHARCmd := &cobra.Command{
+		Use:   "from-har [file]",
+		Short: "Build a data file and stubs from the json api traffic of a HAR file",
+		Long: `
+Builds a data file, and a stubs file, serving the json api traffic of a HAR file exported
+from the browser devtools. Arrays of objects served by '/:resource', and objects served
+by '/:resource/:id', become the records of the resource, merged by id. Any other json
+response becomes a stub of its path, registered with the --stubs flag of the start command`,
+		Args: cobra.ExactArgs(1),
+		RunE: runFromHAR,
+	}
+
+	// Optional flag to set the data file.
+	fromHARCmd.Flags().StringP("out", "o", "db.json", "Data file to write")
+	// Optional flag to set the stubs file.
+	fromHARCmd.Flags().String("stubs-out", "stubs.json", "Stubs file to write, if any stubs are built")
+	// Optional flag to strip a prefix from the request paths.
+	fromHARCmd.Flags().String("strip-prefix", "", "Prefix stripped from the request paths, e.g. /api")
+	// Optional flag to overwrite existing files.
+	fromHARCmd.Flags().Bool("force", false, "Overwrite existing files")
+
+	return fromHARCmd
+}
+
+func runFromHAR(cmd *cobra.Command, args []string) error {
+	// Parse command's flags.
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("%w: out", errFailedParseFlag)
+	}
+
+	stubsOut, err := cmd.Flags().GetString("stubs-out")
+	if err != nil {
+		return fmt.Errorf("%w: stubs-out", errFailedParseFlag)
+	}
+
+	stripPrefix, err := cmd.Flags().GetString("strip-prefix")
+	if err != nil {
+		return fmt.Errorf("%w: strip-prefix", errFailedParseFlag)
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("%w: force", errFailedParseFlag)
+	}
+
+	entries, err := har.Read(args[0])
+	if err != nil {
+		return err
+	}
+
+	data, stubs := har.Fixtures(entries, stripPrefix)
+
+	if err = writeJSONFile(out, data, force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d resources to %s\n", len(data), out)
+
+	if len(stubs) == 0 {
+		return nil
+	}
+
+	if err = writeJSONFile(stubsOut, stubs, force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d stubs to %s, start the server with --stubs %s to serve them\n", len(stubs), stubsOut, stubsOut)
+
+	return nil
+}
+
+// writeJSONFile writes the value as indented json to the file, which must not exist unless forced.
+func writeJSONFile(file string, val interface{}, force bool) error {
+	content, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFile(file, content, force)
+}
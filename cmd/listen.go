@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+var (
+	errInvalidListenAddr = errors.New("invalid listen address")
+	errInvalidIPVersion  = errors.New("invalid ip version, supported values are 4, 6 and dual")
+)
+
+// listenAddr is an address the server listens on.
+type listenAddr struct {
+	scheme  string
+	network string
+	addr    string
+}
+
+// listenNetwork returns the network to listen on for the ip version flag value.
+func listenNetwork(ipVersion string) (string, error) {
+	switch ipVersion {
+	case "4":
+		return "tcp4", nil
+	case "6":
+		return "tcp6", nil
+	case "dual":
+		return "tcp", nil
+	default:
+		return "", fmt.Errorf("%w: %s", errInvalidIPVersion, ipVersion)
+	}
+}
+
+// parseListenAddr parses addresses like ':3000', '[::1]:3000' or 'https://:3443' on the provided network.
+func parseListenAddr(val, network string) (listenAddr, error) {
+	l := listenAddr{scheme: "http", network: network, addr: val}
+
+	if idx := strings.Index(val, "://"); idx >= 0 {
+		l.scheme, l.addr = val[:idx], val[idx+3:]
+	}
+
+	if l.scheme != "http" && l.scheme != "https" {
+		return listenAddr{}, fmt.Errorf("%w: unsupported scheme %s", errInvalidListenAddr, l.scheme)
+	}
+
+	host, _, err := net.SplitHostPort(l.addr)
+	if err != nil {
+		return listenAddr{}, fmt.Errorf("%w: %s", errInvalidListenAddr, val)
+	}
+
+	// Reject literal addresses of the wrong family early, with a clearer error than the listener would give.
+	if ip := net.ParseIP(strings.Split(host, "%")[0]); ip != nil {
+		isIPv4 := ip.To4() != nil
+		if (network == "tcp4" && !isIPv4) || (network == "tcp6" && isIPv4) {
+			return listenAddr{}, fmt.Errorf("%w: %s does not match the ip version", errInvalidListenAddr, val)
+		}
+	}
+
+	return l, nil
+}
+
+// url returns the base url for clients to reach the address. IPv6 hosts are enclosed in brackets.
+func (l listenAddr) url() string {
+	host, port, _ := net.SplitHostPort(l.addr)
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		switch {
+		case l.network == "tcp6" || (l.network == "tcp" && host == "::"):
+			// Unspecified IPv6 addresses are reached on the IPv6 loopback, as localhost might resolve to IPv4 only.
+			host = "::1"
+		case l.network == "tcp4":
+			host = "127.0.0.1"
+		default:
+			host = "localhost"
+		}
+	}
+
+	// Zones are percent-encoded in urls, e.g. [fe80::1%25eth0].
+	host = strings.Replace(host, "%", "%25", 1)
+
+	return fmt.Sprintf("%s://%s", l.scheme, net.JoinHostPort(host, port))
+}
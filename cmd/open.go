@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"os/exec"
+	"runtime"
+)
+
+// openBrowser opens the url in the default browser of the platform.
+func openBrowser(url string) error {
+	var browser *exec.Cmd
+
+	switch runtime.GOOS {
+	case "windows":
+		browser = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	case "darwin":
+		browser = exec.Command("open", url)
+	default:
+		browser = exec.Command("xdg-open", url)
+	}
+
+	return browser.Start()
+}
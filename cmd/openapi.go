@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/openapi"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func newOpenAPICmd() *cobra.Command {
+	// openAPICmd represents the openapi command.
+	openAPICmd := &cobra.Command{
+		Use:   "openapi",
+		Short: "Generate the OpenAPI document of the routes of a data file",
+		Long: `
+Writes an OpenAPI 3 document describing every route the server generates for the
+resources of a data file, with the schemas of their records inferred from the data.
+The document is written as yaml if the output file ends with .yaml or .yml, and as
+json otherwise`,
+		Args: cobra.NoArgs,
+		RunE: runOpenAPI,
+	}
+
+	// Optional flag to set the data file.
+	openAPICmd.Flags().StringP("file", "f", "db.json", "Data file whose resources are described")
+	// Optional flag to set the document file.
+	openAPICmd.Flags().StringP("out", "o", "openapi.json", "Document file to write, as yaml if ending with .yaml or .yml")
+	// Optional flag to set the configuration file.
+	openAPICmd.Flags().String("config", "", "Configuration file customizing individual resources, as passed to the start command")
+	// Optional flag to overwrite an existing file.
+	openAPICmd.Flags().Bool("force", false, "Overwrite an existing file")
+
+	return openAPICmd
+}
+
+func runOpenAPI(cmd *cobra.Command, _ []string) error {
+	// Parse command's flags.
+	filename, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("%w: file", errFailedParseFlag)
+	}
+
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("%w: out", errFailedParseFlag)
+	}
+
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return fmt.Errorf("%w: config", errFailedParseFlag)
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("%w: force", errFailedParseFlag)
+	}
+
+	contentBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errFileNotFound, filename)
+	}
+
+	data, err := storage.ParseDatabase(contentBytes, storage.ArrayResourceKey(filename))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errFailedParseFile, filename, err)
+	}
+
+	cfg := &config.Config{}
+	if configFile != "" {
+		if cfg, err = config.Load(configFile); err != nil {
+			return err
+		}
+	}
+
+	title := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	doc := openapi.New(title, data, cfg, false)
+
+	var content []byte
+	switch strings.ToLower(filepath.Ext(out)) {
+	case ".yaml", ".yml":
+		content, err = doc.YAML()
+	default:
+		content, err = doc.JSON()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err = writeFile(out, content, force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d paths to %s\n", len(doc.Paths), out)
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/postman"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func newExportPostmanCmd() *cobra.Command {
+	// exportPostmanCmd represents the export-postman command.
+	exportPostmanCmd := &cobra.Command{
+		Use:   "export-postman [file]",
+		Short: "Export the routes of a data file as a Postman collection",
+		Long: `
+Writes a Postman collection holding a request for every route the server generates for
+the resources of a data file, with example bodies taken from their first record. The
+collection is imported by Postman and Insomnia, and its baseUrl variable holds the url
+of the server`,
+		Args: cobra.ExactArgs(1),
+		RunE: runExportPostman,
+	}
+
+	// Optional flag to set the collection file.
+	exportPostmanCmd.Flags().StringP("out", "o", "postman_collection.json", "Collection file to write")
+	// Optional flag to set the url of the server.
+	exportPostmanCmd.Flags().String("base-url", "http://localhost:3000", "Base URL of the server, held by the baseUrl variable")
+	// Optional flag to set the configuration file.
+	exportPostmanCmd.Flags().String("config", "", "Configuration file customizing individual resources, as passed to the start command")
+	// Optional flag to overwrite an existing file.
+	exportPostmanCmd.Flags().Bool("force", false, "Overwrite an existing file")
+
+	return exportPostmanCmd
+}
+
+func runExportPostman(cmd *cobra.Command, args []string) error {
+	// Parse command's flags.
+	out, err := cmd.Flags().GetString("out")
+	if err != nil {
+		return fmt.Errorf("%w: out", errFailedParseFlag)
+	}
+
+	baseURL, err := cmd.Flags().GetString("base-url")
+	if err != nil {
+		return fmt.Errorf("%w: base-url", errFailedParseFlag)
+	}
+
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return fmt.Errorf("%w: config", errFailedParseFlag)
+	}
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("%w: force", errFailedParseFlag)
+	}
+
+	filename := args[0]
+
+	contentBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errFileNotFound, filename)
+	}
+
+	data, err := storage.ParseDatabase(contentBytes, storage.ArrayResourceKey(filename))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", errFailedParseFile, filename, err)
+	}
+
+	cfg := &config.Config{}
+	if configFile != "" {
+		if cfg, err = config.Load(configFile); err != nil {
+			return err
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	collection := postman.New(name, strings.TrimSuffix(baseURL, "/"), data, cfg)
+
+	if err = writeJSONFile(out, collection, force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d resources to %s\n", len(collection.Item)-1, out)
+
+	return nil
+}
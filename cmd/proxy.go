@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/proxy"
+)
+
+var errInvalidProxyURL = errors.New("invalid proxy url, expected an absolute http or https url")
+
+// addProxyFlags adds the flags of the upstream proxy to the command.
+func addProxyFlags(cmd *cobra.Command) {
+	// Optional flag to forward unmatched requests to an upstream API.
+	cmd.Flags().String("proxy", "", "Upstream API url to forward requests matching no resource to")
+	// Optional flags to cache the upstream GET responses.
+	cmd.Flags().Bool("proxy-cache", false, "Cache GET responses of the upstream, and serve them while it is offline")
+	cmd.Flags().Duration("proxy-cache-ttl", 5*time.Minute, "Duration cached responses are served without asking the upstream")
+	cmd.Flags().String("proxy-cache-dir", "", "Directory to persist cached responses in, kept in memory only when empty")
+}
+
+// proxyOptions returns the handler options of the upstream proxy. Cached responses expire based on the
+// provided time source.
+func proxyOptions(cmd *cobra.Command, now func() time.Time) ([]handler.Option, error) {
+	// Parse command's flags.
+	upstream, err := cmd.Flags().GetString("proxy")
+	if err != nil {
+		return nil, fmt.Errorf("%w: proxy", errFailedParseFlag)
+	}
+
+	if upstream == "" {
+		return nil, nil
+	}
+
+	cacheEnabled, err := cmd.Flags().GetBool("proxy-cache")
+	if err != nil {
+		return nil, fmt.Errorf("%w: proxy-cache", errFailedParseFlag)
+	}
+
+	ttl, err := cmd.Flags().GetDuration("proxy-cache-ttl")
+	if err != nil || ttl < 0 {
+		return nil, fmt.Errorf("%w: proxy-cache-ttl", errFailedParseFlag)
+	}
+
+	cacheDir, err := cmd.Flags().GetString("proxy-cache-dir")
+	if err != nil {
+		return nil, fmt.Errorf("%w: proxy-cache-dir", errFailedParseFlag)
+	}
+
+	upstreamURL, err := url.Parse(upstream)
+	if err != nil || (upstreamURL.Scheme != "http" && upstreamURL.Scheme != "https") || upstreamURL.Host == "" {
+		return nil, fmt.Errorf("%w: %s", errInvalidProxyURL, upstream)
+	}
+
+	proxyOpts := []proxy.Option{proxy.WithClock(now)}
+	if cacheEnabled || cacheDir != "" {
+		cache, err := proxy.NewCache(cacheDir)
+		if err != nil {
+			return nil, err
+		}
+
+		proxyOpts = append(proxyOpts, proxy.WithCache(cache, ttl))
+	}
+
+	return []handler.Option{handler.WithProxy(proxy.New(upstreamURL, proxyOpts...))}, nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/chanioxaris/json-server/internal/scenario"
+)
+
+var errReplayFailures = errors.New("replay found differing responses")
+
+func newReplayCmd() *cobra.Command {
+	// replayCmd represents the replay command.
+	replayCmd := &cobra.Command{
+		Use:   "replay [script]",
+		Short: "Replay a recorded script or HAR file against a running server and report differing responses",
+		Long: `
+Sends the requests of a script, recorded with the --record flag of the start command,
+in order against a running JSON server, and reports the responses differing from the
+recorded ones. Replay against a server started from the same data file, e.g. as a smoke test.
+
+Files with the .har extension are read as HAR files, exported from the browser devtools,
+whose json api requests are replayed, to check that the data file serves real traffic`,
+		Args: cobra.ExactArgs(1),
+		RunE: runReplay,
+	}
+
+	// Optional flag to set the target server.
+	replayCmd.Flags().StringP("target", "t", "http://localhost:3000", "Base URL of the target server")
+	// Optional flag to strip a prefix from the paths of HAR files.
+	replayCmd.Flags().String("strip-prefix", "", "Prefix stripped from the request paths of HAR files, e.g. /api")
+
+	return replayCmd
+}
+
+func runReplay(cmd *cobra.Command, args []string) error {
+	// Parse command's flags.
+	target, err := cmd.Flags().GetString("target")
+	if err != nil {
+		return fmt.Errorf("%w: target", errFailedParseFlag)
+	}
+
+	stripPrefix, err := cmd.Flags().GetString("strip-prefix")
+	if err != nil {
+		return fmt.Errorf("%w: strip-prefix", errFailedParseFlag)
+	}
+
+	var script *scenario.Script
+	if strings.EqualFold(filepath.Ext(args[0]), ".har") {
+		script, err = scenario.LoadHAR(args[0], stripPrefix)
+	} else {
+		script, err = scenario.Load(args[0])
+	}
+
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Replaying %d steps of %s against %s\n\n", len(script.Steps), args[0], target)
+
+	report, err := scenario.Replay(context.Background(), scenario.Config{Target: target}, script)
+	if report == nil {
+		return err
+	}
+
+	displayReplayReport(report)
+
+	if err != nil {
+		return err
+	}
+
+	if len(report.Failures) > 0 {
+		return errReplayFailures
+	}
+
+	return nil
+}
+
+func displayReplayReport(report *scenario.Report) {
+	if len(report.Failures) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(w, "Step\tMethod\tPath\tDifference")
+		for _, f := range report.Failures {
+			for _, diff := range f.Diffs {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", f.Step, f.Method, f.Path, diff)
+			}
+		}
+
+		// nolint
+		w.Flush()
+		fmt.Println()
+	}
+
+	fmt.Printf("Steps:\t\t %d\n", report.Steps)
+	fmt.Printf("Failures:\t %d\n", len(report.Failures))
+}
@@ -22,8 +22,22 @@ func newRootCmd() *cobra.Command {
 		Long:  "json-server is a cross-platform CLI tool to create within seconds a dummy REST API from a json file",
 	}
 
+	// Optional flag to generate the watch file of the start command from a template.
+	rootCmd.PersistentFlags().String("generate", "", "Generate the watch file of the start command from a template of random records, as the generate command, unless it exists")
+
 	// Add sub commands to base command.
 	rootCmd.AddCommand(newStartCmd())
+	rootCmd.AddCommand(newBenchCmd())
+	rootCmd.AddCommand(newFuzzCmd())
+	rootCmd.AddCommand(newReplayCmd())
+	rootCmd.AddCommand(newFromHARCmd())
+	rootCmd.AddCommand(newExportPostmanCmd())
+	rootCmd.AddCommand(newGenClientCmd())
+	rootCmd.AddCommand(newGenTypesCmd())
+	rootCmd.AddCommand(newOpenAPICmd())
+	rootCmd.AddCommand(newGenerateCmd())
+	rootCmd.AddCommand(newStopCmd())
+	rootCmd.AddCommand(newServiceCmd())
 	rootCmd.AddCommand(newVersionCmd())
 
 	return rootCmd
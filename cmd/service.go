@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultServiceName is used when no explicit service name is provided.
+const defaultServiceName = "json-server"
+
+var errServiceUnsupported = errors.New("service management is only supported on windows, use systemd-unit on linux")
+
+const systemdUnitTemplate = `[Unit]
+Description=JSON Server ({{ .Name }})
+After=network.target
+
+[Service]
+Type=simple
+WorkingDirectory={{ .Dir }}
+ExecStart={{ .Command }}
+Restart=on-failure
+# The server drains connections gracefully on interrupt.
+KillSignal=SIGINT
+{{- if .User }}
+User={{ .User }}
+{{- end }}
+
+[Install]
+WantedBy=multi-user.target
+`
+
+func newServiceCmd() *cobra.Command {
+	// serviceCmd represents the service command.
+	serviceCmd := &cobra.Command{
+		Use:   "service",
+		Short: "Manage the server as an operating system service",
+		Long: `
+Install, start, stop and uninstall the server as a Windows service, or generate a
+systemd unit for Linux. Any arguments after '--' are passed to the start command, e.g.
+
+json-server service install --name mock-api -- -f db.json -p 4000`,
+	}
+
+	// Optional flag to set the service name.
+	serviceCmd.PersistentFlags().StringP("name", "n", defaultServiceName, "Name of the service")
+
+	serviceCmd.AddCommand(&cobra.Command{
+		Use:   "install [-- start flags]",
+		Short: "Install the server as a windows service, started automatically on boot",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, dir, executable, err := serviceSettings(cmd)
+			if err != nil {
+				return err
+			}
+
+			// The service manager starts services in the system directory, so preserve the current one.
+			serviceArgs := append([]string{"service", "run", "--name", name, "--dir", dir, "--"}, args...)
+
+			return installService(name, executable, serviceArgs)
+		},
+	})
+
+	serviceCmd.AddCommand(&cobra.Command{
+		Use:   "uninstall",
+		Short: "Uninstall the windows service",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				return fmt.Errorf("%w: name", errFailedParseFlag)
+			}
+
+			return removeService(name)
+		},
+	})
+
+	serviceCmd.AddCommand(&cobra.Command{
+		Use:   "start",
+		Short: "Start the windows service",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				return fmt.Errorf("%w: name", errFailedParseFlag)
+			}
+
+			return startService(name)
+		},
+	})
+
+	serviceCmd.AddCommand(&cobra.Command{
+		Use:   "stop",
+		Short: "Stop the windows service",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				return fmt.Errorf("%w: name", errFailedParseFlag)
+			}
+
+			return stopService(name)
+		},
+	})
+
+	runCmd := &cobra.Command{
+		Use:    "run [-- start flags]",
+		Short:  "Run the server under the windows service manager",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				return fmt.Errorf("%w: name", errFailedParseFlag)
+			}
+
+			dir, err := cmd.Flags().GetString("dir")
+			if err != nil {
+				return fmt.Errorf("%w: dir", errFailedParseFlag)
+			}
+
+			if err = os.Chdir(dir); err != nil {
+				return err
+			}
+
+			return runService(name, args)
+		},
+	}
+	runCmd.Flags().String("dir", ".", "Working directory of the server")
+	serviceCmd.AddCommand(runCmd)
+
+	unitCmd := &cobra.Command{
+		Use:   "systemd-unit [-- start flags]",
+		Short: "Generate a systemd unit running the server",
+		RunE:  runSystemdUnit,
+	}
+	// Optional flag to set the user running the service.
+	unitCmd.Flags().String("user", "", "User running the service")
+	// Optional flag to set the output file.
+	unitCmd.Flags().StringP("output", "o", "", "File to write the unit to, defaults to stdout")
+	serviceCmd.AddCommand(unitCmd)
+
+	return serviceCmd
+}
+
+func runSystemdUnit(cmd *cobra.Command, args []string) error {
+	name, dir, executable, err := serviceSettings(cmd)
+	if err != nil {
+		return err
+	}
+
+	user, err := cmd.Flags().GetString("user")
+	if err != nil {
+		return fmt.Errorf("%w: user", errFailedParseFlag)
+	}
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("%w: output", errFailedParseFlag)
+	}
+
+	command := append([]string{executable, "start"}, args...)
+	for idx, arg := range command {
+		if strings.ContainsAny(arg, " \t\"") {
+			command[idx] = fmt.Sprintf("%q", arg)
+		}
+	}
+
+	t, err := template.New("unit").Parse(systemdUnitTemplate)
+	if err != nil {
+		return err
+	}
+
+	var unit strings.Builder
+	err = t.Execute(&unit, map[string]string{
+		"Name":    name,
+		"Dir":     dir,
+		"Command": strings.Join(command, " "),
+		"User":    user,
+	})
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Print(unit.String())
+		return nil
+	}
+
+	return ioutil.WriteFile(output, []byte(unit.String()), 0644)
+}
+
+// serviceSettings returns the service name, the current directory and the path of the running executable.
+func serviceSettings(cmd *cobra.Command) (name, dir, executable string, err error) {
+	name, err = cmd.Flags().GetString("name")
+	if err != nil {
+		return "", "", "", fmt.Errorf("%w: name", errFailedParseFlag)
+	}
+
+	dir, err = os.Getwd()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	executable, err = os.Executable()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return name, dir, executable, nil
+}
+
+// runStartArgs runs the start command with the provided arguments, until the shutdown channel is closed.
+func runStartArgs(args []string, shutdown <-chan struct{}) error {
+	startCmd := newStartCmd()
+	startCmd.RunE = func(cmd *cobra.Command, _ []string) error {
+		return startServer(cmd, shutdown)
+	}
+	startCmd.SetArgs(args)
+
+	return startCmd.Execute()
+}
@@ -0,0 +1,24 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+func installService(_, _ string, _ []string) error {
+	return errServiceUnsupported
+}
+
+func removeService(_ string) error {
+	return errServiceUnsupported
+}
+
+func startService(_ string) error {
+	return errServiceUnsupported
+}
+
+func stopService(_ string) error {
+	return errServiceUnsupported
+}
+
+func runService(_ string, _ []string) error {
+	return errServiceUnsupported
+}
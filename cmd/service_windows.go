@@ -0,0 +1,172 @@
+//go:build windows
+// +build windows
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// installService registers the executable with the service manager, started automatically on boot.
+func installService(name, executable string, args []string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already exists", name)
+	}
+
+	s, err := m.CreateService(name, executable, mgr.Config{
+		DisplayName: fmt.Sprintf("JSON Server (%s)", name),
+		Description: "Dummy REST API served from a json file",
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	fmt.Printf("Service %s installed\n", name)
+
+	return nil
+}
+
+// removeService deletes the service from the service manager.
+func removeService(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", name)
+	}
+	defer s.Close()
+
+	if err = s.Delete(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service %s uninstalled\n", name)
+
+	return nil
+}
+
+// startService asks the service manager to start the service.
+func startService(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", name)
+	}
+	defer s.Close()
+
+	if err = s.Start(); err != nil {
+		return err
+	}
+
+	fmt.Printf("Service %s started\n", name)
+
+	return nil
+}
+
+// stopService asks the service manager to stop the service, and waits until it does.
+func stopService(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", name)
+	}
+	defer s.Close()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(time.Second * 20)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("service %s did not stop in time", name)
+		}
+
+		time.Sleep(time.Millisecond * 300)
+
+		if status, err = s.Query(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Service %s stopped\n", name)
+
+	return nil
+}
+
+// runService runs the server under the service manager.
+func runService(name string, args []string) error {
+	return svc.Run(name, &serviceHandler{args: args})
+}
+
+// serviceHandler implements the svc.Handler interface to control the server.
+type serviceHandler struct {
+	args []string
+}
+
+// Execute runs the server and stops it on request of the service manager.
+func (h *serviceHandler) Execute(_ []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (bool, uint32) {
+	s <- svc.Status{State: svc.StartPending}
+
+	shutdown := make(chan struct{})
+	done := make(chan error, 1)
+
+	go func() {
+		done <- runStartArgs(h.args, shutdown)
+	}()
+
+	s <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				s <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				s <- svc.Status{State: svc.StopPending}
+				close(shutdown)
+
+				if err := <-done; err != nil {
+					return false, 1
+				}
+
+				return false, 0
+			}
+		case err := <-done:
+			if err != nil {
+				return false, 1
+			}
+
+			return false, 0
+		}
+	}
+}
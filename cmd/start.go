@@ -2,32 +2,43 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"reflect"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/chanioxaris/json-server/internal/clock"
+	"github.com/chanioxaris/json-server/internal/config"
 	"github.com/chanioxaris/json-server/internal/handler"
 	"github.com/chanioxaris/json-server/internal/logger"
+	"github.com/chanioxaris/json-server/internal/scenario"
+	"github.com/chanioxaris/json-server/internal/server"
 	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/stub"
+	"github.com/chanioxaris/json-server/internal/web"
 )
 
+// flushTimeout bounds the wait for the writes of requests still served past the shutdown timeout.
+const flushTimeout = time.Second * 5
+
 var (
-	errFailedParseFlag     = errors.New("failed to parse flag")
-	errFailedParseFile     = errors.New("failed to parse file")
-	errFileNotFound        = errors.New("unable to find requested file")
-	errUnsupportedResource = errors.New("only array type resources are supported")
-	errFailedStartServer   = errors.New("failed to start JSON server. Maybe port already in use")
-	errFailedInitResources = errors.New("failed to initialize resources")
+	errFailedParseFlag   = errors.New("failed to parse flag")
+	errFailedParseFile   = server.ErrFailedParseFile
+	errFileNotFound      = server.ErrFileNotFound
+	errFailedStartServer = errors.New("failed to start JSON server. Maybe port already in use")
+	errFailedExec        = errors.New("failed to run exec command")
+	errFailedLoadTLS     = errors.New("failed to load TLS certificate")
+	errFailedServe       = errors.New("failed to serve requests")
 )
 
 func newStartCmd() *cobra.Command {
@@ -45,15 +56,107 @@ Please note that only array data type resources are supported`,
 
 	// Optional flag to set the server port.
 	startCmd.Flags().StringP("port", "p", "3000", "Port the server will listen to")
+	// Optional flag to listen on multiple addresses.
+	startCmd.Flags().StringArray("listen", nil, "Address to listen to, e.g. ':3000' or 'https://127.0.0.1:3443'. Can be repeated, overrides port")
+	// Optional flag to set the ip version to bind.
+	startCmd.Flags().String("ip-version", "dual", "IP version to bind, either 4, 6 or dual for both")
+	// Optional flags to set the TLS certificate for https addresses.
+	startCmd.Flags().String("tls-cert", "", "TLS certificate file for https listen addresses")
+	startCmd.Flags().String("tls-key", "", "TLS key file for https listen addresses")
 	// Optional flag to set the watch file.
 	startCmd.Flags().StringP("file", "f", "db.json", "File to watch")
+	// Optional flags to select the storage of the resources, and snapshot them.
+	startCmd.Flags().String("storage", server.StorageFile, "Storage of the resources: file, memory seeded from the watch file, sqlite next to the watch file, e.g. db.sqlite, or the name of a registered driver")
+	startCmd.Flags().String("snapshot", "", "File the resources are written to on shutdown, and on demand with POST /__snapshot or /__flush, disabled when empty")
+	// Optional flags to reload the watch file once changed by hand.
+	startCmd.Flags().Bool("watch", false, "Reload the watch file once changed by another process, registering the routes of added and removed resources")
+	startCmd.Flags().Duration("watch-interval", time.Second, "Interval between checks of the watch file for changes with --watch")
+	// Optional flag to skip invalid records of the watch file.
+	startCmd.Flags().Bool("lenient", false, "Skip invalid resources and records of the watch file, instead of failing to start")
+	// Optional flag to serve tenants by subdomain.
+	startCmd.Flags().StringArray("tenant", nil, "Subdomain served with the resources of its own watch file, e.g. 'acme=acme.json'. Can be repeated")
+	// Optional flag to set the configuration file.
+	startCmd.Flags().String("config", "", "Configuration file customizing individual resources")
+	// Optional flag to reject all write requests.
+	startCmd.Flags().Bool("read-only", false, "Reject create, replace, update and delete requests with 403")
+	startCmd.Flags().Bool("readonly", false, "Reject create, replace, update and delete requests with 403")
+	// nolint
+	startCmd.Flags().MarkDeprecated("readonly", "use --read-only instead")
+	// Optional flags to relax the routing of request paths.
+	startCmd.Flags().Bool("ignore-case", false, "Match resource names in request paths case-insensitively")
+	startCmd.Flags().Bool("ignore-trailing-slash", false, "Route request paths with a trailing slash as the ones without, instead of redirecting")
+	// Optional flag to compress responses.
+	startCmd.Flags().Bool("compress", false, "Compress responses with brotli or gzip, as accepted by the client")
+	// Optional flag to require json request bodies.
+	startCmd.Flags().Bool("strict-content-type", false, "Reject write requests not sent as application/json with 415, and send json with the utf-8 charset")
+	// Optional flag to reject unknown query parameters.
+	startCmd.Flags().Bool("strict-queries", false, "Reject list requests with unknown, empty or ignored query parameters with 400")
+	// Optional flag to refuse listing large collections without pagination.
+	startCmd.Flags().Int("max-unpaginated", 0, "Largest number of resources listed without pagination, larger lists fail with 413, unlimited when 0")
+	// Optional flags to bound the heavy requests served at once.
+	startCmd.Flags().Int("heavy-concurrency", 0, "Number of heavy requests, like searches, exports and imports, served at once, unlimited when 0")
+	startCmd.Flags().Int("heavy-queue", 16, "Number of heavy requests waiting for their turn, before the next ones fail with 503")
+	// Optional flag to cache the responses of repeated GET requests.
+	startCmd.Flags().Duration("cache-ttl", 0, "Cache the responses of GET requests to the resources for the duration, dropped on writes, disabled when 0")
+	// Optional flag to allow cross-origin requests.
+	startCmd.Flags().StringSlice("allow-origin", nil, "Origins allowed to send cross-origin requests to all resources, e.g. 'https://app.example.com' or '*'. Can be repeated")
+	// Optional flag to simulate network latency.
+	startCmd.Flags().Duration("delay", 0, "Delay all responses by the duration, e.g. '200ms', unless their resource has a latency configured")
+	// Optional flag to fail a share of the requests.
+	startCmd.Flags().Float64("chaos", 0, "Fail the share of requests to the resources, between 0 and 1, with 500 or 503 picked at random")
+	// Optional flag to let requests override how they are served with headers.
+	startCmd.Flags().Bool("mock-headers", false, "Let requests override their delay with the X-Mock-Delay header, and force an error with the X-Mock-Status header")
+	// Optional flag to convert field names.
+	startCmd.Flags().String("key-case", "", "Convert field names of responses to camel, snake or kebab case. Requests accept any case")
+	// Optional flags to enable multipart uploads, stored in the provided directory.
+	startCmd.Flags().String("uploads-dir", "", "Directory to store files uploaded with multipart requests, disabled when empty")
+	startCmd.Flags().Int64("uploads-max-size", 32<<20, "Maximum size in bytes of multipart request bodies")
+	// Optional flags to serve resources referencing local files.
+	startCmd.Flags().StringArray("blob-resource", nil, "Resource whose records reference local files, served on GET by id. Can be repeated")
+	startCmd.Flags().String("blob-field", "file", "Field of blob resources holding the file path, relative to the watch file")
+	// Optional flags to stamp and expire resources.
+	startCmd.Flags().Bool("timestamps", false, "Stamp resources with createdAt and updatedAt fields, using the mock clock")
+	startCmd.Flags().Duration("ttl", 0, "Expire resources once their createdAt field is older than the duration, e.g. 1h. Implies --timestamps")
+	// Optional flag to set the seed of the faker endpoints.
+	startCmd.Flags().Int64("faker-seed", 0, "Random seed of the faker endpoints, defaults to the current time")
 	// Optional flag to enable logs.
 	startCmd.Flags().BoolP("logs", "l", false, "Enable logs")
+	// Optional flag to capture the latest requests.
+	startCmd.Flags().Int("capture-size", 100, "Number of latest requests kept with their responses and listed on /__requests, disabled when 0")
+	// Optional flag to record the requests into a script.
+	startCmd.Flags().String("record", "", "Record the requests with their responses into a script file, which can be replayed with the replay command")
+	// Optional flag to register stubs from a file.
+	startCmd.Flags().String("stubs", "", "Register the stubs of a file holding a json array of stubs, as if added on /__stubs")
+	// Optional flag to create resources on the first POST to an unknown path.
+	startCmd.Flags().Bool("auto-resources", false, "Create a resource on the first POST to an unknown path")
+	// Optional flag to enable debug logs.
+	startCmd.Flags().Bool("debug", false, "Enable debug logs, tracing the storage operations and filters of every request. Implies --logs")
+	// Optional flag to run a command once the server is ready.
+	startCmd.Flags().String("exec", "", "Command to run once the server is ready, shuts down the server when it exits")
+	// Optional flag to open a page in the browser once the server is ready.
+	startCmd.Flags().String("open", "", "Open the home page, or the provided path, in the browser once the server is ready")
+	startCmd.Flags().Lookup("open").NoOptDefVal = "/"
+	// Optional flag to run the server in background.
+	startCmd.Flags().Bool("daemon", false, "Run the server in background, use the stop command to stop it")
+	// Optional flag to set the pid file.
+	startCmd.Flags().String("pid-file", "", fmt.Sprintf("File to write the server process id to (default %q with --daemon)", defaultPidFile))
+	// Optional flag to set how long to wait for in-flight requests on shutdown.
+	startCmd.Flags().Duration("shutdown-timeout", time.Second*15, "Time to wait for in-flight requests to complete on SIGINT or SIGTERM")
+	// Optional flag to enable zero downtime restarts.
+	startCmd.Flags().Bool("graceful-upgrade", false, "Restart on SIGHUP by handing the listening socket over to a new process")
+
+	addAuthFlags(startCmd)
+	addProxyFlags(startCmd)
 
 	return startCmd
 }
 
 func runStart(cmd *cobra.Command, _ []string) error {
+	return startServer(cmd, nil)
+}
+
+// startServer runs the server until interrupted, or until the optional shutdown channel is closed.
+func startServer(cmd *cobra.Command, shutdown <-chan struct{}) error {
 	rand.Seed(time.Now().UnixNano())
 
 	// Parse command's flags.
@@ -62,74 +165,690 @@ func runStart(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("%w: port", errFailedParseFlag)
 	}
 
+	listenFlags, err := cmd.Flags().GetStringArray("listen")
+	if err != nil {
+		return fmt.Errorf("%w: listen", errFailedParseFlag)
+	}
+
+	ipVersion, err := cmd.Flags().GetString("ip-version")
+	if err != nil {
+		return fmt.Errorf("%w: ip-version", errFailedParseFlag)
+	}
+
+	network, err := listenNetwork(ipVersion)
+	if err != nil {
+		return err
+	}
+
+	tlsCert, err := cmd.Flags().GetString("tls-cert")
+	if err != nil {
+		return fmt.Errorf("%w: tls-cert", errFailedParseFlag)
+	}
+
+	tlsKey, err := cmd.Flags().GetString("tls-key")
+	if err != nil {
+		return fmt.Errorf("%w: tls-key", errFailedParseFlag)
+	}
+
 	file, err := cmd.Flags().GetString("file")
 	if err != nil {
 		return fmt.Errorf("%w: file", errFailedParseFlag)
 	}
 
+	generateTemplate, err := cmd.Flags().GetString("generate")
+	if err != nil {
+		return fmt.Errorf("%w: generate", errFailedParseFlag)
+	}
+
+	storageBackend, err := cmd.Flags().GetString("storage")
+	if err != nil {
+		return fmt.Errorf("%w: storage", errFailedParseFlag)
+	}
+
+	// Fail on unknown storages before serving. The drivers of other backends register once their package is imported.
+	if _, err = storage.Lookup(storageBackend); err != nil {
+		return err
+	}
+
+	snapshot, err := cmd.Flags().GetString("snapshot")
+	if err != nil {
+		return fmt.Errorf("%w: snapshot", errFailedParseFlag)
+	}
+
+	watch, err := cmd.Flags().GetBool("watch")
+	if err != nil {
+		return fmt.Errorf("%w: watch", errFailedParseFlag)
+	}
+
+	// Reloading the watch file would discard the resources kept by the other storages, or snapshotted.
+	if watch && (storageBackend != server.StorageFile || snapshot != "") {
+		return fmt.Errorf("%w: watch requires the file storage, without snapshot", errFailedParseFlag)
+	}
+
+	watchInterval, err := cmd.Flags().GetDuration("watch-interval")
+	if err != nil || watchInterval <= 0 {
+		return fmt.Errorf("%w: watch-interval", errFailedParseFlag)
+	}
+
+	lenient, err := cmd.Flags().GetBool("lenient")
+	if err != nil {
+		return fmt.Errorf("%w: lenient", errFailedParseFlag)
+	}
+
+	tenantFlags, err := cmd.Flags().GetStringArray("tenant")
+	if err != nil {
+		return fmt.Errorf("%w: tenant", errFailedParseFlag)
+	}
+
+	tenants, err := parseTenants(tenantFlags)
+	if err != nil {
+		return err
+	}
+
+	configFile, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return fmt.Errorf("%w: config", errFailedParseFlag)
+	}
+
+	readOnly, err := cmd.Flags().GetBool("read-only")
+	if err != nil {
+		return fmt.Errorf("%w: read-only", errFailedParseFlag)
+	}
+
+	deprecatedReadOnly, err := cmd.Flags().GetBool("readonly")
+	if err != nil {
+		return fmt.Errorf("%w: readonly", errFailedParseFlag)
+	}
+
+	readOnly = readOnly || deprecatedReadOnly
+
+	ignoreCase, err := cmd.Flags().GetBool("ignore-case")
+	if err != nil {
+		return fmt.Errorf("%w: ignore-case", errFailedParseFlag)
+	}
+
+	ignoreTrailingSlash, err := cmd.Flags().GetBool("ignore-trailing-slash")
+	if err != nil {
+		return fmt.Errorf("%w: ignore-trailing-slash", errFailedParseFlag)
+	}
+
+	keyCase, err := cmd.Flags().GetString("key-case")
+	if err != nil {
+		return fmt.Errorf("%w: key-case", errFailedParseFlag)
+	}
+
+	if keyCase != "" {
+		if err = storage.ValidateKeyCase(keyCase); err != nil {
+			return err
+		}
+	}
+
+	compress, err := cmd.Flags().GetBool("compress")
+	if err != nil {
+		return fmt.Errorf("%w: compress", errFailedParseFlag)
+	}
+
+	strictContentType, err := cmd.Flags().GetBool("strict-content-type")
+	if err != nil {
+		return fmt.Errorf("%w: strict-content-type", errFailedParseFlag)
+	}
+
+	strictQueries, err := cmd.Flags().GetBool("strict-queries")
+	if err != nil {
+		return fmt.Errorf("%w: strict-queries", errFailedParseFlag)
+	}
+
+	maxUnpaginated, err := cmd.Flags().GetInt("max-unpaginated")
+	if err != nil || maxUnpaginated < 0 {
+		return fmt.Errorf("%w: max-unpaginated", errFailedParseFlag)
+	}
+
+	heavyConcurrency, err := cmd.Flags().GetInt("heavy-concurrency")
+	if err != nil || heavyConcurrency < 0 {
+		return fmt.Errorf("%w: heavy-concurrency", errFailedParseFlag)
+	}
+
+	heavyQueue, err := cmd.Flags().GetInt("heavy-queue")
+	if err != nil || heavyQueue < 0 {
+		return fmt.Errorf("%w: heavy-queue", errFailedParseFlag)
+	}
+
+	cacheTTL, err := cmd.Flags().GetDuration("cache-ttl")
+	if err != nil || cacheTTL < 0 {
+		return fmt.Errorf("%w: cache-ttl", errFailedParseFlag)
+	}
+
+	allowOrigins, err := cmd.Flags().GetStringSlice("allow-origin")
+	if err != nil {
+		return fmt.Errorf("%w: allow-origin", errFailedParseFlag)
+	}
+
+	delay, err := cmd.Flags().GetDuration("delay")
+	if err != nil || delay < 0 {
+		return fmt.Errorf("%w: delay", errFailedParseFlag)
+	}
+
+	chaosRate, err := cmd.Flags().GetFloat64("chaos")
+	if err != nil || chaosRate < 0 || chaosRate > 1 {
+		return fmt.Errorf("%w: chaos", errFailedParseFlag)
+	}
+
+	mockHeaders, err := cmd.Flags().GetBool("mock-headers")
+	if err != nil {
+		return fmt.Errorf("%w: mock-headers", errFailedParseFlag)
+	}
+
+	uploadsDir, err := cmd.Flags().GetString("uploads-dir")
+	if err != nil {
+		return fmt.Errorf("%w: uploads-dir", errFailedParseFlag)
+	}
+
+	uploadsMaxSize, err := cmd.Flags().GetInt64("uploads-max-size")
+	if err != nil || uploadsMaxSize <= 0 {
+		return fmt.Errorf("%w: uploads-max-size", errFailedParseFlag)
+	}
+
+	blobResources, err := cmd.Flags().GetStringArray("blob-resource")
+	if err != nil {
+		return fmt.Errorf("%w: blob-resource", errFailedParseFlag)
+	}
+
+	blobField, err := cmd.Flags().GetString("blob-field")
+	if err != nil {
+		return fmt.Errorf("%w: blob-field", errFailedParseFlag)
+	}
+
+	timestamps, err := cmd.Flags().GetBool("timestamps")
+	if err != nil {
+		return fmt.Errorf("%w: timestamps", errFailedParseFlag)
+	}
+
+	ttl, err := cmd.Flags().GetDuration("ttl")
+	if err != nil {
+		return fmt.Errorf("%w: ttl", errFailedParseFlag)
+	}
+
+	fakerSeed, err := cmd.Flags().GetInt64("faker-seed")
+	if err != nil {
+		return fmt.Errorf("%w: faker-seed", errFailedParseFlag)
+	}
+
 	logs, err := cmd.Flags().GetBool("logs")
 	if err != nil {
 		return fmt.Errorf("%w: logs", errFailedParseFlag)
 	}
 
+	debug, err := cmd.Flags().GetBool("debug")
+	if err != nil {
+		return fmt.Errorf("%w: debug", errFailedParseFlag)
+	}
+
+	captureSize, err := cmd.Flags().GetInt("capture-size")
+	if err != nil || captureSize < 0 {
+		return fmt.Errorf("%w: capture-size", errFailedParseFlag)
+	}
+
+	record, err := cmd.Flags().GetString("record")
+	if err != nil {
+		return fmt.Errorf("%w: record", errFailedParseFlag)
+	}
+
+	stubsFile, err := cmd.Flags().GetString("stubs")
+	if err != nil {
+		return fmt.Errorf("%w: stubs", errFailedParseFlag)
+	}
+
+	autoResources, err := cmd.Flags().GetBool("auto-resources")
+	if err != nil {
+		return fmt.Errorf("%w: auto-resources", errFailedParseFlag)
+	}
+
+	execCommand, err := cmd.Flags().GetString("exec")
+	if err != nil {
+		return fmt.Errorf("%w: exec", errFailedParseFlag)
+	}
+
+	openPath, err := cmd.Flags().GetString("open")
+	if err != nil {
+		return fmt.Errorf("%w: open", errFailedParseFlag)
+	}
+
+	daemon, err := cmd.Flags().GetBool("daemon")
+	if err != nil {
+		return fmt.Errorf("%w: daemon", errFailedParseFlag)
+	}
+
+	pidFile, err := cmd.Flags().GetString("pid-file")
+	if err != nil {
+		return fmt.Errorf("%w: pid-file", errFailedParseFlag)
+	}
+
+	gracefulUpgrade, err := cmd.Flags().GetBool("graceful-upgrade")
+	if err != nil {
+		return fmt.Errorf("%w: graceful-upgrade", errFailedParseFlag)
+	}
+
+	shutdownTimeout, err := cmd.Flags().GetDuration("shutdown-timeout")
+	if err != nil || shutdownTimeout <= 0 {
+		return fmt.Errorf("%w: shutdown-timeout", errFailedParseFlag)
+	}
+
+	if gracefulUpgrade && upgradeSignal == nil {
+		return errUpgradeUnsupported
+	}
+
+	if gracefulUpgrade && execCommand != "" {
+		return fmt.Errorf("%w: exec and graceful-upgrade cannot be combined", errFailedParseFlag)
+	}
+
+	if len(listenFlags) == 0 {
+		listenFlags = []string{":" + port}
+	}
+
+	addrs := make([]listenAddr, 0, len(listenFlags))
+	for _, val := range listenFlags {
+		addr, err := parseListenAddr(val, network)
+		if err != nil {
+			return err
+		}
+
+		if addr.scheme == "https" && (tlsCert == "" || tlsKey == "") {
+			return fmt.Errorf("%w: tls-cert and tls-key are required for %s", errFailedParseFlag, val)
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	// Start a detached copy of the server and return once it is ready.
+	if daemon {
+		if pidFile == "" {
+			pidFile = defaultPidFile
+		}
+
+		return daemonize(pidFile)
+	}
+
 	// Setup logger.
-	logger.Setup(logs)
+	logger.Setup(logs, debug)
+
+	// Generate the watch file, seeded like the faker endpoints.
+	if generateTemplate != "" {
+		if err = generateMissingFile(generateTemplate, file, fakerSeed); err != nil {
+			return err
+		}
+	}
 
 	// Get resource keys.
-	resourceKeys, err := getResourceKeys(file)
+	resourceKeys, err := getResourceKeys(file, lenient)
 	if err != nil {
 		return err
 	}
 
-	// Create storage service for each resource.
-	resourceStorage, err := createResourceStorage(resourceKeys, file)
+	// Load the optional configuration file.
+	cfg := &config.Config{}
+	if configFile != "" {
+		if cfg, err = config.Load(configFile); err != nil {
+			return err
+		}
+	}
+
+	mockClock := clock.New()
+
+	var handlerOpts []handler.Option
+
+	if uploadsDir != "" {
+		handlerOpts = append(handlerOpts, handler.WithUploads(uploadsDir), handler.WithUploadLimit(uploadsMaxSize))
+	}
+
+	if readOnly {
+		handlerOpts = append(handlerOpts, handler.WithReadOnly())
+	}
+
+	if ignoreCase {
+		handlerOpts = append(handlerOpts, handler.WithIgnoreCase())
+	}
+
+	if ignoreTrailingSlash {
+		handlerOpts = append(handlerOpts, handler.WithIgnoreTrailingSlash())
+	}
+
+	if keyCase != "" {
+		handlerOpts = append(handlerOpts, handler.WithKeyCase(keyCase))
+	}
+
+	if compress {
+		handlerOpts = append(handlerOpts, handler.WithCompression())
+	}
+
+	if strictContentType {
+		handlerOpts = append(handlerOpts, handler.WithStrictContentType())
+	}
+
+	if strictQueries {
+		handlerOpts = append(handlerOpts, handler.WithStrictQueries())
+	}
+
+	if maxUnpaginated > 0 {
+		handlerOpts = append(handlerOpts, handler.WithMaxUnpaginated(maxUnpaginated))
+	}
+
+	if heavyConcurrency > 0 {
+		handlerOpts = append(handlerOpts, handler.WithHeavyLimit(heavyConcurrency, heavyQueue))
+	}
+
+	if cacheTTL > 0 {
+		handlerOpts = append(handlerOpts, handler.WithResponseCache(cacheTTL))
+	}
+
+	if len(allowOrigins) > 0 {
+		handlerOpts = append(handlerOpts, handler.WithAllowOrigins(allowOrigins...))
+	}
+
+	if delay > 0 {
+		handlerOpts = append(handlerOpts, handler.WithDelay(delay))
+	}
+
+	if chaosRate > 0 {
+		handlerOpts = append(handlerOpts, handler.WithChaos(chaosRate))
+	}
+
+	if mockHeaders {
+		handlerOpts = append(handlerOpts, handler.WithMockHeaders())
+	}
+
+	if fakerSeed != 0 {
+		handlerOpts = append(handlerOpts, handler.WithFakerSeed(fakerSeed))
+	}
+
+	if captureSize > 0 {
+		handlerOpts = append(handlerOpts, handler.WithCapture(captureSize))
+	}
+
+	if record != "" {
+		handlerOpts = append(handlerOpts, handler.WithRecorder(scenario.NewRecorder(record)))
+	}
+
+	if stubsFile != "" {
+		stubs, err := stub.Load(stubsFile)
+		if err != nil {
+			return err
+		}
+
+		handlerOpts = append(handlerOpts, handler.WithStubs(stubs))
+	}
+
+	if autoResources {
+		handlerOpts = append(handlerOpts, handler.WithAutoResources())
+	}
+
+	authOpts, err := authOptions(cmd, cfg, resourceKeys, addrs[0].url(), mockClock.Now)
 	if err != nil {
 		return err
 	}
 
+	handlerOpts = append(handlerOpts, authOpts...)
+
+	proxyOpts, err := proxyOptions(cmd, mockClock.Now)
+	if err != nil {
+		return err
+	}
+
+	handlerOpts = append(handlerOpts, proxyOpts...)
+
+	sessions, err := parseSessionFlags(cmd, resourceKeys)
+	if err != nil {
+		return err
+	}
+
+	serverOpts := server.Options{
+		Lenient:        lenient,
+		Storage:        storageBackend,
+		Config:         cfg,
+		Clock:          mockClock,
+		Timestamps:     timestamps,
+		TTL:            ttl,
+		BlobResources:  blobResources,
+		BlobField:      blobField,
+		HandlerOptions: handlerOpts,
+	}
+
+	// Serve the requests of every session with a sandbox of the resources, kept in memory.
+	if sessions != nil {
+		serverOpts.Sessions = &server.Sessions{
+			UsersKey:      sessions.usersKey,
+			PrivateFields: cfg.PrivateFields([]string{sessions.usersKey})[sessions.usersKey],
+			Expiry:        sessions.expiry,
+			MaxSessions:   sessions.maxSessions,
+			CSRF:          sessions.csrf,
+		}
+	}
+
+	// Stop polling the watch files once the server stops.
+	var watchers []*server.Watcher
+	defer func() {
+		for _, watcher := range watchers {
+			watcher.Close()
+		}
+	}()
+
+	// serveFile returns the handler serving the resources of a watch file, reloaded on changes if watched.
+	serveFile := func(file string) (http.Handler, error) {
+		fileOpts := serverOpts
+		fileOpts.File = file
+
+		if !watch {
+			return server.New(fileOpts)
+		}
+
+		watcher, err := server.Watch(file, watchInterval, func() (http.Handler, error) {
+			return server.New(fileOpts)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		watchers = append(watchers, watcher)
+
+		return watcher, nil
+	}
+
+	var apiHandler http.Handler
+
+	// Persist the resources of the watch file on shutdown, and on demand, unless reloaded on changes.
+	if !watch {
+		fileOpts := serverOpts
+		fileOpts.File = file
+		fileOpts.Snapshot = snapshot
+
+		srv, err := server.Open(fileOpts)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+
+		defer flushOnShutdown(srv, snapshot)
+
+		apiHandler = srv
+	} else if apiHandler, err = serveFile(file); err != nil {
+		return err
+	}
+
+	// Serve the tenants with the resources of their own watch file, by the subdomain of the request host.
+	if len(tenants) > 0 {
+		tenantHandlers := make(map[string]http.Handler, len(tenants))
+		for _, t := range tenants {
+			if _, err := getResourceKeys(t.file, lenient); err != nil {
+				return err
+			}
+
+			if tenantHandlers[t.name], err = serveFile(t.file); err != nil {
+				return err
+			}
+		}
+
+		apiHandler = handler.Tenants(tenantHandlers, apiHandler)
+	}
+
 	// Setup API server.
 	api := &http.Server{
-		Addr:    ":" + port,
-		Handler: handler.Setup(resourceStorage),
+		Handler: apiHandler,
 		// Good practice to set timeouts to avoid Slowloris attacks.
 		WriteTimeout: time.Second * 15,
 		ReadTimeout:  time.Second * 15,
 		IdleTimeout:  time.Second * 60,
+		// Long-lived responses, like the event stream, lift the timeouts through the connection of the request.
+		ConnContext: web.ConnContext,
 	}
 
+	// Load the TLS certificate upfront, so an invalid one fails the startup instead of the https listeners.
+	for _, addr := range addrs {
+		if addr.scheme != "https" {
+			continue
+		}
+
+		cert, err := tls.LoadX509KeyPair(tlsCert, tlsKey)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errFailedLoadTLS, err)
+		}
+
+		api.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		break
+	}
+
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	stopServer := func() { stopOnce.Do(func() { close(stop) }) }
+
 	// Start REST API server.
-	listener, err := net.Listen("tcp", ":"+port)
+	listeners, err := listen(addrs)
 	if err != nil {
 		return errFailedStartServer
 	}
 
-	// nolint
-	go api.Serve(listener)
+	// Stop the server once any listener fails, and report the error.
+	serveErr := make(chan error, len(listeners))
+	for idx, listener := range listeners {
+		go func(listener net.Listener, https bool) {
+			var err error
+			if https {
+				err = api.ServeTLS(listener, "", "")
+			} else {
+				err = api.Serve(listener)
+			}
+
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serveErr <- err
+				stopServer()
+			}
+		}(listener, addrs[idx].scheme == "https")
+	}
+
+	// Write the pid file once the server accepts connections.
+	if pidFile != "" {
+		if err = writePidFile(pidFile); err != nil {
+			return err
+		}
+		defer removePidFile(pidFile)
+	}
+
+	notifyUpgradeReady()
 
 	// Display info about available resources and home page.
-	displayInfo(resourceKeys, port)
+	displayInfo(resourceKeys, tenants, addrs)
+
+	// Open the requested page in the browser, unless it has been already opened before an upgrade.
+	// Failing to do so should not stop the server.
+	if openPath != "" && !isUpgrade() {
+		if err = openBrowser(addrs[0].url() + "/" + strings.TrimPrefix(openPath, "/")); err != nil {
+			fmt.Printf("failed to open browser: %v\n\n", err)
+		}
+	}
+
+	if shutdown != nil {
+		go func() {
+			<-shutdown
+			stopServer()
+		}()
+	}
 
-	gracefulShutdown(api)
+	// Hand the listener over to a new process on the upgrade signal, and stop once it accepts connections.
+	if gracefulUpgrade {
+		go func() {
+			c := make(chan os.Signal, 1)
+			signal.Notify(c, upgradeSignal)
+
+			for range c {
+				if err := spawnUpgrade(listeners); err != nil {
+					fmt.Println(err)
+					continue
+				}
+
+				stopServer()
+				return
+			}
+		}()
+	}
+
+	// Run the exec command now that the listener accepts connections, and stop the server once it exits.
+	execErr := make(chan error, 1)
+	if execCommand != "" {
+		child, err := startExec(execCommand, addrs[0].url())
+		if err != nil {
+			return fmt.Errorf("%w: %v", errFailedExec, err)
+		}
+
+		go func() {
+			execErr <- child.Wait()
+			stopServer()
+		}()
+	}
+
+	gracefulShutdown(api, stop, shutdownTimeout)
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("%w: %v", errFailedServe, err)
+	default:
+	}
+
+	select {
+	case err := <-execErr:
+		if err != nil {
+			return fmt.Errorf("%w: %v", errFailedExec, err)
+		}
+	default:
+	}
 
 	return nil
 }
 
-// gracefulShutdown handles any signal that interrupts the running server
-func gracefulShutdown(server *http.Server) {
+// gracefulShutdown handles any signal that interrupts the running server, or a close of the stop channel.
+func gracefulShutdown(server *http.Server, stop <-chan struct{}, timeout time.Duration) {
 	c := make(chan os.Signal, 1)
-	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
-	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
-	signal.Notify(c, os.Interrupt)
+	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C), or SIGTERM, as sent by docker and kubernetes
+	// to stop containers. SIGKILL and SIGQUIT (Ctrl+/) will not be caught.
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(c)
 
 	// Block until we receive our signal.
-	<-c
+	select {
+	case <-c:
+	case <-stop:
+	}
 
 	// Create a deadline to wait for.
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 	// Doesn't block if no connections, but will otherwise wait
 	// until the timeout deadline.
-	if err := server.Shutdown(ctx); err != nil {
+	err := server.Shutdown(ctx)
+
+	// Requests still served past the deadline may be writing to the watch file, let them complete before exiting.
+	if !storage.WaitWrites(flushTimeout) {
+		fmt.Println("failed to complete pending writes")
+	}
+
+	if err != nil {
 		fmt.Println("failed to gracefully shutdown server")
 		return
 	}
@@ -137,66 +856,59 @@ func gracefulShutdown(server *http.Server) {
 	fmt.Println("gracefully shutting down server")
 }
 
-func getResourceKeys(filename string) ([]string, error) {
-	// Read file contents used as storage.
-	contentBytes, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %s", errFileNotFound, filename)
-	}
-
-	content := map[string]interface{}{}
-	if err = json.Unmarshal(contentBytes, &content); err != nil {
-		return nil, fmt.Errorf("%w: %s", errFailedParseFile, filename)
+// flushOnShutdown persists the changes of the server once it stopped serving requests, and warns about the ones
+// lost, e.g. of the resources kept in memory without a snapshot file.
+func flushOnShutdown(srv *server.Server, snapshot string) {
+	if err := srv.Flush(); err != nil {
+		fmt.Printf("warning: failed to persist changes: %v\n", err)
+		return
 	}
 
-	resourceKeys := make([]string, 0)
-
-	// Range on content to retrieve resource keys.
-	for resource, data := range content {
-		switch reflect.TypeOf(data).Kind() {
-		case reflect.Slice:
-			resourceKeys = append(resourceKeys, resource)
-		default:
-			return nil, errUnsupportedResource
-		}
+	if snapshot != "" {
+		fmt.Printf("wrote snapshot to %s\n", snapshot)
 	}
-
-	return resourceKeys, nil
 }
 
-func createResourceStorage(resourceKeys []string, filename string) (map[string]storage.Storage, error) {
-	resourceStorage := make(map[string]storage.Storage)
-
-	for _, resourceKey := range resourceKeys {
-		storageSvc, err := storage.NewFile(filename, resourceKey)
-		if err != nil {
-			return nil, errFailedInitResources
-		}
-
-		resourceStorage[resourceKey] = storageSvc
-	}
-
-	// Create storage service for common db endpoint.
-	storageSvcDB, err := storage.NewFile(filename, "")
+// getResourceKeys returns the keys of the resources of the watch file, listing the skipped ones if lenient.
+func getResourceKeys(filename string, lenient bool) ([]string, error) {
+	data, skipped, err := server.Load(filename, lenient)
 	if err != nil {
-		return nil, errFailedInitResources
+		return nil, err
 	}
 
-	resourceStorage["db"] = storageSvcDB
+	for _, skippedErr := range skipped {
+		fmt.Printf("skipping %v\n", skippedErr)
+	}
 
-	return resourceStorage, nil
+	return server.ResourceKeys(data), nil
 }
 
-func displayInfo(resourceKeys []string, port string) {
+func displayInfo(resourceKeys []string, tenants []tenant, addrs []listenAddr) {
 	fmt.Printf("JSON Server successfully running\n\n")
 
+	// Resources are listed for the first address only, to keep the output short.
+	baseURL := addrs[0].url()
+
 	fmt.Println("Resources")
 	for _, resource := range resourceKeys {
-		fmt.Printf("http://localhost:%s/%s\n", port, resource)
+		fmt.Printf("%s/%s\n", baseURL, resource)
 	}
 
-	fmt.Printf("http://localhost:%s/db\n\n", port)
+	fmt.Printf("%s/db\n\n", baseURL)
+
+	if len(tenants) > 0 {
+		fmt.Println("Tenants")
+		for _, t := range tenants {
+			fmt.Printf("%s.<host> serves %s\n", t.name, t.file)
+		}
+
+		fmt.Println()
+	}
 
 	fmt.Println("Home")
-	fmt.Printf("http://localhost:%s\n\n", port)
+	for _, addr := range addrs {
+		fmt.Println(addr.url())
+	}
+
+	fmt.Println()
 }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newStopCmd() *cobra.Command {
+	// stopCmd represents the stop command.
+	stopCmd := &cobra.Command{
+		Use:   "stop",
+		Short: "Stop a server running in background",
+		Long:  "Stop a server started with the daemon flag, using the process id stored in its pid file",
+		RunE:  runStop,
+	}
+
+	// Optional flag to set the pid file.
+	stopCmd.Flags().String("pid-file", defaultPidFile, "File containing the server process id")
+	// Optional flag to set how long to wait for the server to stop.
+	stopCmd.Flags().Duration("timeout", time.Second*20, "Time to wait for the server to stop")
+
+	return stopCmd
+}
+
+func runStop(cmd *cobra.Command, _ []string) error {
+	// Parse command's flags.
+	pidFile, err := cmd.Flags().GetString("pid-file")
+	if err != nil {
+		return fmt.Errorf("%w: pid-file", errFailedParseFlag)
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return fmt.Errorf("%w: timeout", errFailedParseFlag)
+	}
+
+	pid, err := readPidFile(pidFile)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errNotRunning, err)
+	}
+
+	if !processRunning(pid) {
+		// Clean up the stale pid file.
+		// nolint
+		os.Remove(pidFile)
+		return fmt.Errorf("%w: pid %d", errNotRunning, pid)
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("%w: pid %d", errNotRunning, pid)
+	}
+
+	if err = stopProcess(process); err != nil {
+		return err
+	}
+
+	// Wait for the server to drain its connections and exit.
+	deadline := time.Now().Add(timeout)
+	for processRunning(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("server with pid %d did not stop within %v", pid, timeout)
+		}
+
+		time.Sleep(time.Millisecond * 100)
+	}
+
+	fmt.Printf("JSON Server with pid %d stopped\n", pid)
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var errInvalidTenant = errors.New("invalid tenant, expected a subdomain and a watch file like 'acme=acme.json'")
+
+// tenantPattern matches the subdomains of tenants, which are single dns labels.
+var tenantPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// tenant is a subdomain served with the resources of its own watch file.
+type tenant struct {
+	name string
+	file string
+}
+
+// parseTenants parses the tenant flags, like 'acme=acme.json'. Subdomains are matched case-insensitively.
+func parseTenants(vals []string) ([]tenant, error) {
+	tenants := make([]tenant, 0, len(vals))
+	seen := make(map[string]bool)
+
+	for _, val := range vals {
+		parts := strings.SplitN(val, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("%w: %s", errInvalidTenant, val)
+		}
+
+		name := strings.ToLower(parts[0])
+		if !tenantPattern.MatchString(name) || seen[name] {
+			return nil, fmt.Errorf("%w: %s", errInvalidTenant, val)
+		}
+
+		seen[name] = true
+		tenants = append(tenants, tenant{name: name, file: parts[1]})
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// upgradeEnv marks a process started by a graceful upgrade, which inherits a readiness
+// pipe as file descriptor 3 and its listeners as the following file descriptors.
+const upgradeEnv = "JSON_SERVER_UPGRADE"
+
+const (
+	inheritedReadyFd    = 3
+	inheritedListenerFd = 4
+)
+
+var (
+	errUpgradeUnsupported = errors.New("graceful upgrade is not supported on this platform")
+	errFailedUpgrade      = errors.New("failed to upgrade server")
+)
+
+// isUpgrade reports whether the current process was started by a graceful upgrade.
+func isUpgrade() bool {
+	return os.Getenv(upgradeEnv) == "1"
+}
+
+// listen returns the listeners inherited from the previous process on a graceful upgrade,
+// or new listeners on the provided addresses.
+func listen(addrs []listenAddr) ([]net.Listener, error) {
+	listeners := make([]net.Listener, 0, len(addrs))
+
+	for idx, addr := range addrs {
+		var (
+			listener net.Listener
+			err      error
+		)
+
+		if isUpgrade() {
+			f := os.NewFile(uintptr(inheritedListenerFd+idx), "listener")
+			listener, err = net.FileListener(f)
+			f.Close()
+		} else {
+			listener, err = net.Listen(addr.network, addr.addr)
+		}
+
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+
+			return nil, err
+		}
+
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
+// notifyUpgradeReady tells the previous process that this one accepts connections, so it can shut down.
+func notifyUpgradeReady() {
+	if !isUpgrade() {
+		return
+	}
+
+	f := os.NewFile(inheritedReadyFd, "ready")
+	defer f.Close()
+
+	// nolint
+	f.Write([]byte{1})
+}
+
+// spawnUpgrade starts a new copy of the current executable, handing over the listeners. It returns
+// once the new process accepts connections, after which the current process should stop serving.
+func spawnUpgrade(listeners []net.Listener) error {
+	listenerFiles := make([]*os.File, 0, len(listeners))
+	defer func() {
+		for _, f := range listenerFiles {
+			f.Close()
+		}
+	}()
+
+	for _, listener := range listeners {
+		tcpListener, ok := listener.(*net.TCPListener)
+		if !ok {
+			return fmt.Errorf("%w: unsupported listener", errFailedUpgrade)
+		}
+
+		f, err := tcpListener.File()
+		if err != nil {
+			return fmt.Errorf("%w: %v", errFailedUpgrade, err)
+		}
+
+		listenerFiles = append(listenerFiles, f)
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errFailedUpgrade, err)
+	}
+	defer readyReader.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		readyWriter.Close()
+		return fmt.Errorf("%w: %v", errFailedUpgrade, err)
+	}
+
+	child := exec.Command(executable, os.Args[1:]...)
+	child.Env = append(os.Environ(), upgradeEnv+"=1")
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	child.ExtraFiles = append([]*os.File{readyWriter}, listenerFiles...)
+
+	err = child.Start()
+	// The child holds its own copy of the write end, so reading returns once it writes or exits.
+	readyWriter.Close()
+	if err != nil {
+		return fmt.Errorf("%w: %v", errFailedUpgrade, err)
+	}
+
+	ready := make(chan bool, 1)
+	go func() {
+		buf := make([]byte, 1)
+		n, _ := readyReader.Read(buf)
+		ready <- n == 1
+	}()
+
+	select {
+	case ok := <-ready:
+		if !ok {
+			return fmt.Errorf("%w: new process exited before accepting connections", errFailedUpgrade)
+		}
+	case <-time.After(time.Second * 30):
+		// nolint
+		child.Process.Kill()
+		return fmt.Errorf("%w: timed out waiting for new process", errFailedUpgrade)
+	}
+
+	// The new process outlives the current one, so it is not waited for.
+	return child.Process.Release()
+}
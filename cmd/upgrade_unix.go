@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// upgradeSignal triggers a graceful upgrade of a running server.
+var upgradeSignal os.Signal = syscall.SIGHUP
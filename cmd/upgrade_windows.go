@@ -0,0 +1,11 @@
+//go:build windows
+// +build windows
+
+package cmd
+
+import (
+	"os"
+)
+
+// upgradeSignal is not available, as windows does not support inheriting listeners this way.
+var upgradeSignal os.Signal
@@ -3,8 +3,14 @@ module github.com/chanioxaris/json-server
 go 1.14
 
 require (
+	github.com/andybalholm/brotli v1.0.0
 	github.com/gookit/color v1.2.7
 	github.com/gorilla/mux v1.7.4
+	github.com/mattn/go-sqlite3 v1.14.0
 	github.com/sirupsen/logrus v1.6.0
 	github.com/spf13/cobra v1.0.0
+	github.com/yuin/gopher-lua v0.0.0-20200816102855-ee81675732da
+	golang.org/x/crypto v0.0.0-20200429183012-4b2356b1ed79
+	golang.org/x/image v0.0.0-20200430140353-33d19683fad8
+	golang.org/x/sys v0.0.0-20200323222414-85ca7c5b95cd
 )
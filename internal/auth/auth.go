@@ -0,0 +1,215 @@
+// Package auth issues and verifies the JSON Web Tokens of the mock authentication flow.
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken returns an error when a token is malformed or its signature is invalid.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired returns an error when a token is past its expiration time.
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims represents the payload of a token.
+type Claims map[string]interface{}
+
+// Tokens issues and verifies HS256 or RS256 signed tokens.
+type Tokens struct {
+	alg    string
+	secret []byte
+	key    *rsa.PrivateKey
+	keyID  string
+	now    func() time.Time
+	ttl    time.Duration
+}
+
+// NewTokens returns a new tokens instance. Tokens are HS256 signed with the secret, and expire after the
+// time to live, based on the provided time source.
+func NewTokens(secret []byte, now func() time.Time, ttl time.Duration) *Tokens {
+	return &Tokens{alg: "HS256", secret: secret, now: now, ttl: ttl}
+}
+
+// NewRSATokens returns a new tokens instance. Tokens are RS256 signed with the key, and expire after the
+// time to live, based on the provided time source.
+func NewRSATokens(key *rsa.PrivateKey, now func() time.Time, ttl time.Duration) *Tokens {
+	keyHash := sha256.Sum256(key.PublicKey.N.Bytes())
+
+	return &Tokens{alg: "RS256", key: key, keyID: hex.EncodeToString(keyHash[:8]), now: now, ttl: ttl}
+}
+
+// TTL returns the lifetime of issued tokens.
+func (t *Tokens) TTL() time.Duration {
+	return t.ttl
+}
+
+// Issue returns a signed token for the claims, adding the issued at and expiration time.
+func (t *Tokens) Issue(claims Claims) (string, error) {
+	now := t.now()
+
+	payload := make(Claims, len(claims)+2)
+	for key, val := range claims {
+		payload[key] = val
+	}
+
+	payload["iat"] = now.Unix()
+	payload["exp"] = now.Add(t.ttl).Unix()
+
+	headerFields := map[string]string{"alg": t.alg, "typ": "JWT"}
+	if t.keyID != "" {
+		headerFields["kid"] = t.keyID
+	}
+
+	header, err := encodeSegment(headerFields)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := encodeSegment(payload)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := header + "." + body
+
+	signature, err := t.sign(signingInput)
+	if err != nil {
+		return "", err
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks the signature and expiration time of the token, and returns its claims.
+func (t *Tokens) Verify(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != t.alg {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !t.verifySignature(parts[0]+"."+parts[1], signature) {
+		return nil, ErrInvalidToken
+	}
+
+	claims := make(Claims)
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && !t.now().Before(time.Unix(int64(exp), 0)) {
+		return nil, ErrTokenExpired
+	}
+
+	return claims, nil
+}
+
+// JWKS returns the JSON Web Key Set to verify RS256 tokens with. It is empty for HS256 tokens, as the
+// secret must not be published.
+func (t *Tokens) JWKS() map[string]interface{} {
+	keys := make([]map[string]string, 0, 1)
+
+	if t.key != nil {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": t.alg,
+			"kid": t.keyID,
+			"n":   base64.RawURLEncoding.EncodeToString(t.key.PublicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(t.key.PublicKey.E)).Bytes()),
+		})
+	}
+
+	return map[string]interface{}{"keys": keys}
+}
+
+func (t *Tokens) sign(signingInput string) ([]byte, error) {
+	if t.key != nil {
+		hashed := sha256.Sum256([]byte(signingInput))
+		return rsa.SignPKCS1v15(rand.Reader, t.key, crypto.SHA256, hashed[:])
+	}
+
+	mac := hmac.New(sha256.New, t.secret)
+	// nolint
+	mac.Write([]byte(signingInput))
+
+	return mac.Sum(nil), nil
+}
+
+func (t *Tokens) verifySignature(signingInput string, signature []byte) bool {
+	if t.key != nil {
+		hashed := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(&t.key.PublicKey, crypto.SHA256, hashed[:], signature) == nil
+	}
+
+	expected, err := t.sign(signingInput)
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(expected, signature)
+}
+
+func encodeSegment(v interface{}) (string, error) {
+	segmentBytes, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(segmentBytes), nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	segmentBytes, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(segmentBytes, v)
+}
+
+// BearerToken extracts the token of an 'Authorization: Bearer <token>' header value.
+func BearerToken(header string) (string, error) {
+	const prefix = "Bearer "
+
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", fmt.Errorf("%w: missing bearer token", ErrInvalidToken)
+	}
+
+	return header[len(prefix):], nil
+}
+
+type contextKey struct{}
+
+// WithClaims returns a copy of the context holding the claims of the authenticated request.
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, contextKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated request, if any.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(contextKey{}).(Claims)
+	return claims, ok
+}
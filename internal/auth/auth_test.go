@@ -0,0 +1,73 @@
+package auth_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+)
+
+func TestTokens(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	nowFunc := func() time.Time { return now }
+
+	tokens := auth.NewTokens([]byte("secret"), nowFunc, time.Hour)
+
+	token, err := tokens.Issue(auth.Claims{"sub": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parts := strings.Split(token, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+
+	testCases := []struct {
+		name   string
+		tokens *auth.Tokens
+		token  string
+		err    error
+	}{
+		{
+			name:   "Valid token",
+			tokens: tokens,
+			token:  token,
+		},
+		{
+			name:   "Tampered token",
+			tokens: tokens,
+			token:  tampered,
+			err:    auth.ErrInvalidToken,
+		},
+		{
+			name:   "Token signed with another secret",
+			tokens: auth.NewTokens([]byte("other"), nowFunc, time.Hour),
+			token:  token,
+			err:    auth.ErrInvalidToken,
+		},
+		{
+			name:   "Malformed token",
+			tokens: tokens,
+			token:  "abc",
+			err:    auth.ErrInvalidToken,
+		},
+		{
+			name:   "Expired token",
+			tokens: auth.NewTokens([]byte("secret"), func() time.Time { return now.Add(time.Hour) }, time.Hour),
+			token:  token,
+			err:    auth.ErrTokenExpired,
+		},
+	}
+
+	for _, tt := range testCases {
+		claims, err := tt.tokens.Verify(tt.token)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if tt.err == nil && claims["sub"] != "1" {
+			t.Fatalf("%s: expected claim sub %v, but got %v", tt.name, "1", claims["sub"])
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// codeTTL is the lifetime of authorization codes.
+const codeTTL = time.Minute * 5
+
+var (
+	// ErrInvalidRequest returns an error when an OAuth request misses a required parameter.
+	ErrInvalidRequest = errors.New("invalid_request")
+	// ErrInvalidGrant returns an error when an authorization code or refresh token is invalid.
+	ErrInvalidGrant = errors.New("invalid_grant")
+)
+
+// AuthorizeRequest holds the parameters of an authorization request.
+type AuthorizeRequest struct {
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// Claims override the default claims of the provider for this login.
+	Claims Claims
+}
+
+// TokenResponse is the successful response of the token endpoint.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// grant describes the authorization a client received.
+type grant struct {
+	clientID string
+	scope    string
+	nonce    string
+	claims   Claims
+}
+
+// authCode is a pending authorization code.
+type authCode struct {
+	grant
+	redirectURI         string
+	codeChallenge       string
+	codeChallengeMethod string
+	expiresAt           time.Time
+}
+
+// Provider is a mock OAuth2 and OpenID Connect provider, which approves every authorization request.
+// It is safe for concurrent use.
+type Provider struct {
+	tokens *Tokens
+	issuer string
+	claims Claims
+
+	mu            sync.Mutex
+	codes         map[string]authCode
+	refreshTokens map[string]grant
+}
+
+// NewProvider returns a new provider, issuing tokens signed by the provided tokens instance. The claims are
+// added to every issued token.
+func NewProvider(tokens *Tokens, issuer string, claims Claims) *Provider {
+	return &Provider{
+		tokens:        tokens,
+		issuer:        strings.TrimSuffix(issuer, "/"),
+		claims:        claims,
+		codes:         make(map[string]authCode),
+		refreshTokens: make(map[string]grant),
+	}
+}
+
+// Issuer returns the issuer identifier of the provider.
+func (p *Provider) Issuer() string {
+	return p.issuer
+}
+
+// Tokens returns the tokens instance the provider signs tokens with.
+func (p *Provider) Tokens() *Tokens {
+	return p.tokens
+}
+
+// Authorize approves the authorization request, and returns a single use authorization code.
+func (p *Provider) Authorize(req AuthorizeRequest) (string, error) {
+	if req.ClientID == "" || req.RedirectURI == "" {
+		return "", ErrInvalidRequest
+	}
+
+	if req.CodeChallengeMethod != "" && req.CodeChallengeMethod != "plain" && req.CodeChallengeMethod != "S256" {
+		return "", ErrInvalidRequest
+	}
+
+	claims := make(Claims, len(p.claims)+len(req.Claims))
+	for key, val := range p.claims {
+		claims[key] = val
+	}
+
+	for key, val := range req.Claims {
+		claims[key] = val
+	}
+
+	code, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.codes[code] = authCode{
+		grant:               grant{clientID: req.ClientID, scope: req.Scope, nonce: req.Nonce, claims: claims},
+		redirectURI:         req.RedirectURI,
+		codeChallenge:       req.CodeChallenge,
+		codeChallengeMethod: req.CodeChallengeMethod,
+		expiresAt:           p.tokens.now().Add(codeTTL),
+	}
+
+	return code, nil
+}
+
+// Exchange redeems an authorization code for tokens. The code verifier is required when the authorization
+// request contained a code challenge.
+func (p *Provider) Exchange(code, clientID, redirectURI, codeVerifier string) (*TokenResponse, error) {
+	p.mu.Lock()
+	c, ok := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	if !ok || !p.tokens.now().Before(c.expiresAt) {
+		return nil, ErrInvalidGrant
+	}
+
+	if (clientID != "" && clientID != c.clientID) || redirectURI != c.redirectURI {
+		return nil, ErrInvalidGrant
+	}
+
+	if c.codeChallenge != "" && !verifyCodeChallenge(c.codeChallenge, c.codeChallengeMethod, codeVerifier) {
+		return nil, ErrInvalidGrant
+	}
+
+	return p.issue(c.grant, true)
+}
+
+// Refresh issues new tokens for a refresh token. Refresh tokens are rotated on every use.
+func (p *Provider) Refresh(refreshToken string) (*TokenResponse, error) {
+	p.mu.Lock()
+	g, ok := p.refreshTokens[refreshToken]
+	delete(p.refreshTokens, refreshToken)
+	p.mu.Unlock()
+
+	if !ok {
+		return nil, ErrInvalidGrant
+	}
+
+	return p.issue(g, true)
+}
+
+// ClientCredentials issues an access token for the client itself.
+func (p *Provider) ClientCredentials(clientID, scope string) (*TokenResponse, error) {
+	if clientID == "" {
+		return nil, ErrInvalidRequest
+	}
+
+	return p.issue(grant{clientID: clientID, scope: scope, claims: Claims{"sub": clientID}}, false)
+}
+
+func (p *Provider) issue(g grant, withUser bool) (*TokenResponse, error) {
+	claims := make(Claims, len(g.claims)+4)
+	for key, val := range g.claims {
+		claims[key] = val
+	}
+
+	claims["iss"] = p.issuer
+	claims["aud"] = g.clientID
+
+	accessClaims := make(Claims, len(claims)+2)
+	for key, val := range claims {
+		accessClaims[key] = val
+	}
+
+	accessClaims["client_id"] = g.clientID
+	if g.scope != "" {
+		accessClaims["scope"] = g.scope
+	}
+
+	accessToken, err := p.tokens.Issue(accessClaims)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(p.tokens.TTL() / time.Second),
+		Scope:       g.scope,
+	}
+
+	if !withUser {
+		return resp, nil
+	}
+
+	// The ID token is only issued for OpenID Connect requests.
+	if containsScope(g.scope, "openid") {
+		if g.nonce != "" {
+			claims["nonce"] = g.nonce
+		}
+
+		if resp.IDToken, err = p.tokens.Issue(claims); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.RefreshToken, err = randomToken(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.refreshTokens[resp.RefreshToken] = g
+	p.mu.Unlock()
+
+	return resp, nil
+}
+
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	if verifier == "" {
+		return false
+	}
+
+	if method == "S256" {
+		hashed := sha256.Sum256([]byte(verifier))
+		return base64.RawURLEncoding.EncodeToString(hashed[:]) == challenge
+	}
+
+	return verifier == challenge
+}
+
+func containsScope(scope, val string) bool {
+	for _, item := range strings.Fields(scope) {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
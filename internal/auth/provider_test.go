@@ -0,0 +1,89 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+)
+
+func TestProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := auth.NewRSATokens(key, time.Now, time.Hour)
+	provider := auth.NewProvider(tokens, "http://localhost:3000/", auth.Claims{"sub": "1", "role": "user"})
+
+	verifier := "verifier-0123456789"
+	hashed := sha256.Sum256([]byte(verifier))
+
+	code, err := provider.Authorize(auth.AuthorizeRequest{
+		ClientID:            "app",
+		RedirectURI:         "http://localhost:8080/callback",
+		Scope:               "openid profile",
+		Nonce:               "nonce-1",
+		CodeChallenge:       base64.RawURLEncoding.EncodeToString(hashed[:]),
+		CodeChallengeMethod: "S256",
+		Claims:              auth.Claims{"role": "admin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = provider.Exchange(code, "app", "http://localhost:8080/callback", "wrong"); !errors.Is(err, auth.ErrInvalidGrant) {
+		t.Fatalf("expected error %v, but got %v", auth.ErrInvalidGrant, err)
+	}
+
+	// Codes are single use, even after a failed exchange.
+	if _, err = provider.Exchange(code, "app", "http://localhost:8080/callback", verifier); !errors.Is(err, auth.ErrInvalidGrant) {
+		t.Fatalf("expected error %v, but got %v", auth.ErrInvalidGrant, err)
+	}
+
+	code, err = provider.Authorize(auth.AuthorizeRequest{
+		ClientID:    "app",
+		RedirectURI: "http://localhost:8080/callback",
+		Scope:       "openid",
+		Nonce:       "nonce-1",
+		Claims:      auth.Claims{"role": "admin"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := provider.Exchange(code, "app", "http://localhost:8080/callback", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := tokens.Verify(resp.IDToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := auth.Claims{"iss": "http://localhost:3000", "aud": "app", "sub": "1", "role": "admin", "nonce": "nonce-1"}
+	for field, val := range expected {
+		if claims[field] != val {
+			t.Fatalf("expected claim %s %v, but got %v", field, val, claims[field])
+		}
+	}
+
+	refreshed, err := provider.Refresh(resp.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = tokens.Verify(refreshed.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = provider.Refresh(resp.RefreshToken); !errors.Is(err, auth.ErrInvalidGrant) {
+		t.Fatalf("expected error %v, but got %v", auth.ErrInvalidGrant, err)
+	}
+}
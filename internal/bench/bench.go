@@ -0,0 +1,431 @@
+// Package bench generates realistic CRUD traffic against a running JSON server
+// and reports latency statistics per operation.
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoResources returns an error when the target server exposes no resources.
+	ErrNoResources = errors.New("target server has no resources")
+	// ErrUnexpectedStatus returns an error when the target server responds with an unexpected status code.
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// Operation names used in reports.
+const (
+	OpList    = "list"
+	OpRead    = "read"
+	OpCreate  = "create"
+	OpReplace = "replace"
+	OpUpdate  = "update"
+	OpDelete  = "delete"
+)
+
+// operationWeights defines the traffic mix, favoring reads over writes like most real clients do.
+var operationWeights = []struct {
+	op     string
+	weight int
+}{
+	{OpList, 30},
+	{OpRead, 35},
+	{OpCreate, 15},
+	{OpUpdate, 10},
+	{OpReplace, 5},
+	{OpDelete, 5},
+}
+
+// Config holds the settings of a benchmark run.
+type Config struct {
+	Target      string
+	Concurrency int
+	Duration    time.Duration
+	Client      *http.Client
+}
+
+// Stats contains the latency statistics of a single operation.
+type Stats struct {
+	Operation string
+	Count     int
+	Errors    int
+	Mean      time.Duration
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+}
+
+// Report contains the outcome of a benchmark run.
+type Report struct {
+	Duration   time.Duration
+	Requests   int
+	Errors     int
+	Operations []Stats
+}
+
+// sample is a single measured request.
+type sample struct {
+	op       string
+	duration time.Duration
+	failed   bool
+}
+
+// target describes the resources of the server under test.
+type target struct {
+	baseURL string
+	client  *http.Client
+	// samples of each resource, used to build request bodies and ids.
+	resources map[string][]map[string]interface{}
+	keys      []string
+}
+
+// Run executes a benchmark against the configured target and returns the collected report.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+
+	if cfg.Client == nil {
+		// Keep one idle connection per worker, otherwise connection setup dominates the measured latency.
+		cfg.Client = &http.Client{
+			Timeout:   time.Second * 30,
+			Transport: &http.Transport{MaxIdleConnsPerHost: cfg.Concurrency},
+		}
+	}
+
+	t, err := discover(ctx, cfg.Client, strings.TrimSuffix(cfg.Target, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Duration)
+	defer cancel()
+
+	results := make([][]sample, cfg.Concurrency)
+	created := make([][]createdResource, cfg.Concurrency)
+
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for idx := 0; idx < cfg.Concurrency; idx++ {
+		wg.Add(1)
+
+		go func(worker int) {
+			defer wg.Done()
+			results[worker], created[worker] = t.work(ctx)
+		}(idx)
+	}
+
+	wg.Wait()
+
+	elapsed := time.Since(start)
+
+	// Remove any leftover resources created during the run, so the fixture data stays untouched.
+	for _, resources := range created {
+		for _, res := range resources {
+			// nolint
+			t.do(context.Background(), http.MethodDelete, res.path(), nil)
+		}
+	}
+
+	return newReport(results, elapsed), nil
+}
+
+// createdResource identifies a resource created by a worker.
+type createdResource struct {
+	key string
+	id  string
+}
+
+func (c createdResource) path() string {
+	return fmt.Sprintf("/%s/%s", c.key, c.id)
+}
+
+// work issues requests until the context is done and returns the measured samples.
+func (t *target) work(ctx context.Context) ([]sample, []createdResource) {
+	samples := make([]sample, 0)
+	created := make([]createdResource, 0)
+
+	for ctx.Err() == nil {
+		key := t.keys[rand.Intn(len(t.keys))]
+		op := pickOperation()
+
+		// Mutations of existing resources only target resources created by this worker.
+		if (op == OpUpdate || op == OpReplace || op == OpDelete) && len(created) == 0 {
+			op = OpCreate
+		}
+
+		var (
+			method string
+			path   string
+			body   interface{}
+			owned  = -1
+		)
+
+		switch op {
+		case OpList:
+			method, path = http.MethodGet, "/"+key
+		case OpRead:
+			method, path = http.MethodGet, t.randomPath(key)
+		case OpCreate:
+			method, path, body = http.MethodPost, "/"+key, t.randomBody(key)
+		case OpUpdate, OpReplace, OpDelete:
+			owned = rand.Intn(len(created))
+			key = created[owned].key
+			path = created[owned].path()
+
+			switch op {
+			case OpUpdate:
+				method, body = http.MethodPatch, t.randomBody(key)
+			case OpReplace:
+				method, body = http.MethodPut, t.randomBody(key)
+			default:
+				method = http.MethodDelete
+			}
+		}
+
+		begin := time.Now()
+		data, err := t.do(ctx, method, path, body)
+		duration := time.Since(begin)
+
+		// Track the resources of successful requests first, so resources created right before the end of
+		// the run are cleaned up as well.
+		if err == nil {
+			switch op {
+			case OpCreate:
+				if id, ok := data["id"]; ok {
+					created = append(created, createdResource{key: key, id: fmt.Sprintf("%v", id)})
+				}
+			case OpDelete:
+				created = append(created[:owned], created[owned+1:]...)
+			}
+		}
+
+		// Requests interrupted by the end of the run are not measured.
+		if ctx.Err() != nil {
+			break
+		}
+
+		samples = append(samples, sample{op: op, duration: duration, failed: err != nil})
+	}
+
+	return samples, created
+}
+
+// randomPath returns the path of a random fixture resource, or the collection path if it is empty.
+func (t *target) randomPath(key string) string {
+	resources := t.resources[key]
+	if len(resources) == 0 {
+		return "/" + key
+	}
+
+	return fmt.Sprintf("/%s/%v", key, resources[rand.Intn(len(resources))]["id"])
+}
+
+// randomBody builds a request body from a random fixture resource, without its id.
+func (t *target) randomBody(key string) map[string]interface{} {
+	body := make(map[string]interface{})
+
+	resources := t.resources[key]
+	if len(resources) > 0 {
+		for field, val := range resources[rand.Intn(len(resources))] {
+			body[field] = val
+		}
+	}
+
+	delete(body, "id")
+
+	if len(body) == 0 {
+		body["bench"] = true
+	}
+
+	return body
+}
+
+// do sends a single request and decodes any json object in the response.
+func (t *target) do(ctx context.Context, method, path string, body interface{}) (map[string]interface{}, error) {
+	var reader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		reader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	data := make(map[string]interface{})
+	// Not every response body is a json object, e.g. lists or empty bodies.
+	// nolint
+	json.Unmarshal(respBytes, &data)
+
+	return data, nil
+}
+
+// discover retrieves the available resources of the target server from the '/db' endpoint.
+func discover(ctx context.Context, client *http.Client, baseURL string) (*target, error) {
+	t := &target{
+		baseURL:   baseURL,
+		client:    client,
+		resources: make(map[string][]map[string]interface{}),
+		keys:      make([]string, 0),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/db", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode)
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&t.resources); err != nil {
+		return nil, err
+	}
+
+	for key := range t.resources {
+		t.keys = append(t.keys, key)
+	}
+
+	if len(t.keys) == 0 {
+		return nil, ErrNoResources
+	}
+
+	sort.Strings(t.keys)
+
+	return t, nil
+}
+
+// pickOperation returns a random operation based on the traffic mix weights.
+func pickOperation() string {
+	total := 0
+	for _, w := range operationWeights {
+		total += w.weight
+	}
+
+	n := rand.Intn(total)
+	for _, w := range operationWeights {
+		if n < w.weight {
+			return w.op
+		}
+
+		n -= w.weight
+	}
+
+	return OpList
+}
+
+// newReport aggregates the samples of all workers.
+func newReport(results [][]sample, elapsed time.Duration) *Report {
+	report := &Report{Duration: elapsed}
+
+	durations := make(map[string][]time.Duration)
+	errorCount := make(map[string]int)
+
+	for _, samples := range results {
+		for _, s := range samples {
+			report.Requests++
+			durations[s.op] = append(durations[s.op], s.duration)
+
+			if s.failed {
+				report.Errors++
+				errorCount[s.op]++
+			}
+		}
+	}
+
+	for _, w := range operationWeights {
+		d, ok := durations[w.op]
+		if !ok {
+			continue
+		}
+
+		stats := newStats(d)
+		stats.Operation = w.op
+		stats.Errors = errorCount[w.op]
+
+		report.Operations = append(report.Operations, stats)
+	}
+
+	return report
+}
+
+// newStats computes the latency statistics of the provided durations.
+func newStats(durations []time.Duration) Stats {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+
+	return Stats{
+		Count: len(durations),
+		Mean:  total / time.Duration(len(durations)),
+		P50:   Percentile(durations, 50),
+		P90:   Percentile(durations, 90),
+		P99:   Percentile(durations, 99),
+		Max:   durations[len(durations)-1],
+	}
+}
+
+// Percentile returns the nearest-rank percentile of the sorted durations.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+
+	return sorted[rank]
+}
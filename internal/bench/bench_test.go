@@ -0,0 +1,59 @@
+package bench_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/bench"
+)
+
+func TestPercentile(t *testing.T) {
+	durations := make([]time.Duration, 0)
+	for idx := 1; idx <= 100; idx++ {
+		durations = append(durations, time.Duration(idx)*time.Millisecond)
+	}
+
+	testCases := []struct {
+		name     string
+		sorted   []time.Duration
+		p        float64
+		expected time.Duration
+	}{
+		{
+			name:     "Percentile of empty durations",
+			sorted:   nil,
+			p:        50,
+			expected: 0,
+		},
+		{
+			name:     "Median percentile",
+			sorted:   durations,
+			p:        50,
+			expected: 50 * time.Millisecond,
+		},
+		{
+			name:     "High percentile",
+			sorted:   durations,
+			p:        99,
+			expected: 99 * time.Millisecond,
+		},
+		{
+			name:     "Max percentile",
+			sorted:   durations,
+			p:        100,
+			expected: 100 * time.Millisecond,
+		},
+		{
+			name:     "Percentile of single duration",
+			sorted:   []time.Duration{time.Second},
+			p:        90,
+			expected: time.Second,
+		},
+	}
+
+	for _, tt := range testCases {
+		if got := bench.Percentile(tt.sorted, tt.p); got != tt.expected {
+			t.Fatalf("%s: expected percentile %v, but got %v", tt.name, tt.expected, got)
+		}
+	}
+}
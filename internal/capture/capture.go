@@ -0,0 +1,242 @@
+// Package capture keeps the last requests a server handled, with their responses, so tests can verify the
+// requests their application made.
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxBodySize is the size in bytes up to which bodies are kept. Larger bodies are truncated.
+	MaxBodySize = 64 << 10
+	// Redacted replaces the values of credentials in captured entries.
+	Redacted = "[redacted]"
+)
+
+// redactedHeaders are the headers holding credentials, which are never kept.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// Entry is a captured request and its response.
+type Entry struct {
+	ID       int         `json:"id"`
+	Time     time.Time   `json:"time"`
+	Method   string      `json:"method"`
+	Path     string      `json:"path"`
+	Query    url.Values  `json:"query"`
+	Header   http.Header `json:"headers"`
+	Body     interface{} `json:"body"`
+	Response Response    `json:"response"`
+}
+
+// Response is a captured response.
+type Response struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"headers"`
+	Body   interface{} `json:"body"`
+}
+
+// Filter selects captured entries. Empty fields select all entries.
+type Filter struct {
+	Method string
+	// Path matches exactly, or as prefix when ending with '*'.
+	Path   string
+	Status int
+	// Limit keeps the latest entries only.
+	Limit int
+}
+
+// Sink receives captured entries.
+type Sink interface {
+	Add(entry Entry)
+}
+
+// Buffer keeps the last captured entries, dropping the oldest once full. It is safe for concurrent use.
+type Buffer struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int
+	full    bool
+	lastID  int
+}
+
+// NewBuffer returns a buffer keeping the provided number of entries.
+func NewBuffer(size int) *Buffer {
+	return &Buffer{entries: make([]Entry, size)}
+}
+
+// Add captures an entry, assigning its id. Credentials are redacted, as the entries are served to anyone able to
+// list them.
+func (b *Buffer) Add(entry Entry) {
+	entry = Redact(entry)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.entries) == 0 {
+		return
+	}
+
+	b.lastID++
+	entry.ID = b.lastID
+
+	b.entries[b.next] = entry
+	b.next = (b.next + 1) % len(b.entries)
+
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Entries returns the captured entries selected by the filter, oldest first.
+func (b *Buffer) Entries(filter Filter) []Entry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ordered := b.entries[:b.next]
+	if b.full {
+		ordered = append(append([]Entry{}, b.entries[b.next:]...), b.entries[:b.next]...)
+	}
+
+	selected := make([]Entry, 0)
+	for _, entry := range ordered {
+		if filter.matches(entry) {
+			selected = append(selected, entry)
+		}
+	}
+
+	if filter.Limit > 0 && len(selected) > filter.Limit {
+		selected = selected[len(selected)-filter.Limit:]
+	}
+
+	return selected
+}
+
+// Reset drops all captured entries.
+func (b *Buffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries = make([]Entry, len(b.entries))
+	b.next = 0
+	b.full = false
+}
+
+func (f Filter) matches(entry Entry) bool {
+	if f.Method != "" && !strings.EqualFold(f.Method, entry.Method) {
+		return false
+	}
+
+	if f.Status != 0 && f.Status != entry.Response.Status {
+		return false
+	}
+
+	if strings.HasSuffix(f.Path, "*") {
+		return strings.HasPrefix(entry.Path, strings.TrimSuffix(f.Path, "*"))
+	}
+
+	return f.Path == "" || f.Path == entry.Path
+}
+
+// Redact returns the entry with the credentials of its request and response replaced, i.e. the values of the
+// authorization and cookie headers, and of the password, secret and token fields of json and form bodies, at
+// any depth.
+func Redact(entry Entry) Entry {
+	entry.Header = redactHeader(entry.Header)
+	entry.Body = redactBody(entry.Body, entry.Header.Get("Content-Type"))
+	entry.Response.Header = redactHeader(entry.Response.Header)
+	entry.Response.Body = redactBody(entry.Response.Body, entry.Response.Header.Get("Content-Type"))
+
+	return entry
+}
+
+// redactHeader returns a copy of the header without the values of credentials.
+func redactHeader(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+
+	redacted := header.Clone()
+	for _, name := range redactedHeaders {
+		if values, ok := redacted[name]; ok {
+			redacted[name] = make([]string, len(values))
+			for idx := range values {
+				redacted[name][idx] = Redacted
+			}
+		}
+	}
+
+	return redacted
+}
+
+// redactBody returns the decoded body without the values of credential fields. Form bodies, which are decoded
+// as strings, are redacted by their content type.
+func redactBody(body interface{}, contentType string) interface{} {
+	switch v := body.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for field, val := range v {
+			if sensitiveField(field) {
+				redacted[field] = Redacted
+				continue
+			}
+
+			redacted[field] = redactBody(val, "")
+		}
+
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			redacted = append(redacted, redactBody(item, ""))
+		}
+
+		return redacted
+	case string:
+		if !strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+			return v
+		}
+
+		form, err := url.ParseQuery(v)
+		if err != nil {
+			return v
+		}
+
+		for field := range form {
+			if sensitiveField(field) {
+				form.Set(field, Redacted)
+			}
+		}
+
+		return form.Encode()
+	}
+
+	return body
+}
+
+// sensitiveField reports whether the field holds credentials, e.g. 'password', 'client_secret' or 'accessToken'.
+func sensitiveField(field string) bool {
+	field = strings.ToLower(field)
+
+	return strings.Contains(field, "password") || strings.Contains(field, "secret") ||
+		strings.HasSuffix(field, "token")
+}
+
+// DecodeBody returns the json value of the body, or the body as string if it is no json. Empty bodies
+// are nil.
+func DecodeBody(body []byte) interface{} {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil
+	}
+
+	var val interface{}
+	if err := json.Unmarshal(body, &val); err != nil {
+		return string(body)
+	}
+
+	return val
+}
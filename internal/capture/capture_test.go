@@ -0,0 +1,111 @@
+package capture_test
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+)
+
+func TestBuffer(t *testing.T) {
+	buffer := capture.NewBuffer(3)
+
+	requests := []capture.Entry{
+		{Method: "GET", Path: "/posts", Response: capture.Response{Status: 200}},
+		{Method: "POST", Path: "/posts", Response: capture.Response{Status: 201}},
+		{Method: "GET", Path: "/posts/1", Response: capture.Response{Status: 404}},
+		{Method: "POST", Path: "/orders", Response: capture.Response{Status: 201}},
+	}
+
+	for _, entry := range requests {
+		buffer.Add(entry)
+	}
+
+	testCases := []struct {
+		name   string
+		filter capture.Filter
+		ids    []int
+	}{
+		{name: "Keep latest entries", filter: capture.Filter{}, ids: []int{2, 3, 4}},
+		{name: "Filter by method", filter: capture.Filter{Method: "post"}, ids: []int{2, 4}},
+		{name: "Filter by path", filter: capture.Filter{Path: "/posts"}, ids: []int{2}},
+		{name: "Filter by path prefix", filter: capture.Filter{Path: "/posts*"}, ids: []int{2, 3}},
+		{name: "Filter by status", filter: capture.Filter{Status: 201}, ids: []int{2, 4}},
+		{name: "Limit to latest", filter: capture.Filter{Limit: 2}, ids: []int{3, 4}},
+	}
+
+	for _, tt := range testCases {
+		ids := make([]int, 0)
+		for _, entry := range buffer.Entries(tt.filter) {
+			ids = append(ids, entry.ID)
+		}
+
+		if !reflect.DeepEqual(ids, tt.ids) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.ids, ids)
+		}
+	}
+
+	buffer.Reset()
+	buffer.Add(capture.Entry{Method: "GET", Path: "/posts"})
+
+	if entries := buffer.Entries(capture.Filter{}); len(entries) != 1 || entries[0].ID != 5 {
+		t.Fatalf("expected a single entry with id 5 after reset, but got %v", entries)
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	testCases := []struct {
+		body     string
+		expected interface{}
+	}{
+		{body: "", expected: nil},
+		{body: `{"id": "1"}`, expected: map[string]interface{}{"id": "1"}},
+		{body: "plain text", expected: "plain text"},
+	}
+
+	for _, tt := range testCases {
+		if got := capture.DecodeBody([]byte(tt.body)); !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("expected body %v for %q, but got %v", tt.expected, tt.body, got)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	entry := capture.Redact(capture.Entry{
+		Header: http.Header{"Authorization": {"Bearer s3cret"}, "Cookie": {"session=s3cret"}, "Accept": {"*/*"}},
+		Body: map[string]interface{}{
+			"email":   "olivia@example.com",
+			"profile": map[string]interface{}{"newPassword": "s3cret"},
+		},
+		Response: capture.Response{
+			Header: http.Header{"Set-Cookie": {"session=s3cret"}, "Content-Type": {"application/json"}},
+			Body:   map[string]interface{}{"accessToken": "s3cret"},
+		},
+	})
+
+	expected := capture.Entry{
+		Header: http.Header{"Authorization": {capture.Redacted}, "Cookie": {capture.Redacted}, "Accept": {"*/*"}},
+		Body: map[string]interface{}{
+			"email":   "olivia@example.com",
+			"profile": map[string]interface{}{"newPassword": capture.Redacted},
+		},
+		Response: capture.Response{
+			Header: http.Header{"Set-Cookie": {capture.Redacted}, "Content-Type": {"application/json"}},
+			Body:   map[string]interface{}{"accessToken": capture.Redacted},
+		},
+	}
+
+	if !reflect.DeepEqual(entry, expected) {
+		t.Fatalf("expected entry %v, but got %v", expected, entry)
+	}
+
+	form := capture.Redact(capture.Entry{
+		Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:   "grant_type=password&password=s3cret&username=olivia",
+	})
+
+	if expected := "grant_type=password&password=%5Bredacted%5D&username=olivia"; form.Body != expected {
+		t.Fatalf("expected form body %v, but got %v", expected, form.Body)
+	}
+}
@@ -0,0 +1,183 @@
+package capture
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// maxNearMisses is the number of requests reported which match the method and path of an expectation,
+// but not the rest of it.
+const maxNearMisses = 5
+
+// Expectation describes requests a test expects the server to have received. Without count bounds, at
+// least one request is expected.
+type Expectation struct {
+	Method string `json:"method"`
+	// Path matches exactly, or as prefix when ending with '*'.
+	Path string `json:"path"`
+	// Query holds query parameters the requests must have, with the value of their first occurrence.
+	Query map[string]string `json:"query,omitempty"`
+	// Headers holds headers the requests must have.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body holds the json body the requests must have. Objects may omit fields of the request body.
+	Body    interface{} `json:"body,omitempty"`
+	Count   *int        `json:"count,omitempty"`
+	AtLeast *int        `json:"atLeast,omitempty"`
+	AtMost  *int        `json:"atMost,omitempty"`
+}
+
+// Result is the outcome of verifying an expectation.
+type Result struct {
+	Expectation Expectation `json:"expectation"`
+	Passed      bool        `json:"passed"`
+	// Matched is the number of requests matching the expectation.
+	Matched int `json:"matched"`
+	// Diffs explains why the expectation failed, including the differences of near misses.
+	Diffs []string `json:"diffs,omitempty"`
+}
+
+// Verify checks the expectation against the captured entries.
+func Verify(entries []Entry, expectation Expectation) Result {
+	filter := Filter{Method: expectation.Method, Path: expectation.Path}
+	result := Result{Expectation: expectation}
+
+	nearMisses := make([]string, 0)
+	for _, entry := range entries {
+		if !filter.matches(entry) {
+			continue
+		}
+
+		diffs := expectation.diff(entry)
+		if len(diffs) == 0 {
+			result.Matched++
+			continue
+		}
+
+		if len(nearMisses) < maxNearMisses {
+			nearMisses = append(nearMisses, fmt.Sprintf("request %d: %s", entry.ID, strings.Join(diffs, ", ")))
+		}
+	}
+
+	result.Passed = true
+
+	if expectation.Count != nil && result.Matched != *expectation.Count {
+		result.Passed = false
+		result.Diffs = append(result.Diffs, fmt.Sprintf("expected exactly %d matching requests, but got %d", *expectation.Count, result.Matched))
+	}
+
+	atLeast := expectation.AtLeast
+	if expectation.Count == nil && expectation.AtLeast == nil && expectation.AtMost == nil {
+		one := 1
+		atLeast = &one
+	}
+
+	if atLeast != nil && result.Matched < *atLeast {
+		result.Passed = false
+		result.Diffs = append(result.Diffs, fmt.Sprintf("expected at least %d matching requests, but got %d", *atLeast, result.Matched))
+	}
+
+	if expectation.AtMost != nil && result.Matched > *expectation.AtMost {
+		result.Passed = false
+		result.Diffs = append(result.Diffs, fmt.Sprintf("expected at most %d matching requests, but got %d", *expectation.AtMost, result.Matched))
+	}
+
+	if !result.Passed {
+		result.Diffs = append(result.Diffs, nearMisses...)
+	}
+
+	return result
+}
+
+// diff returns the differences of the entry from the query, headers and body of the expectation.
+func (e Expectation) diff(entry Entry) []string {
+	diffs := make([]string, 0)
+
+	for _, key := range sortedKeys(e.Query) {
+		if got := entry.Query.Get(key); got != e.Query[key] || !hasKey(entry.Query, key) {
+			diffs = append(diffs, fmt.Sprintf("query %s: expected %q, but got %s", key, e.Query[key], describe(entry.Query[key])))
+		}
+	}
+
+	for _, key := range sortedKeys(e.Headers) {
+		if got := entry.Header.Get(key); got != e.Headers[key] {
+			diffs = append(diffs, fmt.Sprintf("header %s: expected %q, but got %s", key, e.Headers[key], describe(entry.Header.Values(key))))
+		}
+	}
+
+	if e.Body != nil {
+		diffs = append(diffs, diffValue("body", e.Body, entry.Body)...)
+	}
+
+	return diffs
+}
+
+// Diff returns the differences of a json value from the expected one, where expected objects may omit fields.
+// Differences are reported under the path, e.g. 'body.user.name'.
+func Diff(path string, expected, got interface{}) []string {
+	return diffValue(path, expected, got)
+}
+
+// diffValue compares a json value with the expected one, where expected objects may omit fields.
+func diffValue(path string, expected, got interface{}) []string {
+	if expectedObj, ok := expected.(map[string]interface{}); ok {
+		gotObj, ok := got.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, but got %s", path, marshal(got))}
+		}
+
+		diffs := make([]string, 0)
+		for _, key := range sortedKeys(expectedObj) {
+			gotVal, ok := gotObj[key]
+			if !ok {
+				diffs = append(diffs, fmt.Sprintf("%s.%s: expected %s, but it is missing", path, key, marshal(expectedObj[key])))
+				continue
+			}
+
+			diffs = append(diffs, diffValue(path+"."+key, expectedObj[key], gotVal)...)
+		}
+
+		return diffs
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		return []string{fmt.Sprintf("%s: expected %s, but got %s", path, marshal(expected), marshal(got))}
+	}
+
+	return nil
+}
+
+func hasKey(values map[string][]string, key string) bool {
+	_, ok := values[key]
+	return ok
+}
+
+func describe(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+
+	return fmt.Sprintf("%q", values[0])
+}
+
+func marshal(val interface{}) string {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+
+	return string(b)
+}
+
+func sortedKeys(m interface{}) []string {
+	keys := make([]string, 0)
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,93 @@
+package capture_test
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+)
+
+func TestVerify(t *testing.T) {
+	entries := []capture.Entry{
+		{ID: 1, Method: "GET", Path: "/users", Query: url.Values{"role": {"admin"}}},
+		{ID: 2, Method: "GET", Path: "/users", Query: url.Values{"role": {"user"}}},
+		{ID: 3, Method: "GET", Path: "/users", Query: url.Values{"role": {"admin"}, "page": {"2"}}},
+		{
+			ID:     4,
+			Method: "POST",
+			Path:   "/orders",
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   map[string]interface{}{"total": float64(10), "items": []interface{}{"a"}, "note": "gift"},
+		},
+	}
+
+	intPtr := func(n int) *int { return &n }
+
+	testCases := []struct {
+		name        string
+		expectation capture.Expectation
+		passed      bool
+		matched     int
+		diffs       []string
+	}{
+		{
+			name:        "Exact count with query",
+			expectation: capture.Expectation{Method: "GET", Path: "/users", Query: map[string]string{"role": "admin"}, Count: intPtr(2)},
+			passed:      true,
+			matched:     2,
+		},
+		{
+			name:        "Exact count mismatch with near misses",
+			expectation: capture.Expectation{Method: "GET", Path: "/users", Query: map[string]string{"role": "admin"}, Count: intPtr(3)},
+			matched:     2,
+			diffs: []string{
+				"expected exactly 3 matching requests, but got 2",
+				`request 2: query role: expected "admin", but got "user"`,
+			},
+		},
+		{
+			name: "Body subset and headers",
+			expectation: capture.Expectation{
+				Method:  "POST",
+				Path:    "/orders",
+				Headers: map[string]string{"content-type": "application/json"},
+				Body:    map[string]interface{}{"total": float64(10), "items": []interface{}{"a"}},
+			},
+			passed:  true,
+			matched: 1,
+		},
+		{
+			name:        "Body mismatch",
+			expectation: capture.Expectation{Method: "POST", Path: "/orders", Body: map[string]interface{}{"total": float64(20), "coupon": "X"}},
+			diffs: []string{
+				"expected at least 1 matching requests, but got 0",
+				`request 4: body.coupon: expected "X", but it is missing, body.total: expected 20, but got 10`,
+			},
+		},
+		{
+			name:        "At most bound",
+			expectation: capture.Expectation{Method: "GET", Path: "/users*", AtMost: intPtr(1)},
+			matched:     3,
+			diffs:       []string{"expected at most 1 matching requests, but got 3"},
+		},
+		{
+			name:        "Never called",
+			expectation: capture.Expectation{Method: "DELETE", Path: "/users", Count: intPtr(0)},
+			passed:      true,
+		},
+	}
+
+	for _, tt := range testCases {
+		result := capture.Verify(entries, tt.expectation)
+
+		if result.Passed != tt.passed || result.Matched != tt.matched {
+			t.Fatalf("%s: expected passed %v with %v matches, but got %v with %v", tt.name, tt.passed, tt.matched, result.Passed, result.Matched)
+		}
+
+		if !reflect.DeepEqual(result.Diffs, tt.diffs) {
+			t.Fatalf("%s: expected diffs %q, but got %q", tt.name, tt.diffs, result.Diffs)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package clock provides a virtual clock, which can be frozen, set and advanced, to test
+// time dependent behavior deterministically.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a virtual clock. Unless modified, it follows the system time. It is safe for concurrent use.
+type Clock struct {
+	mu sync.RWMutex
+	// offset is the difference between the virtual and the system time, while running.
+	offset time.Duration
+	// frozenAt is the virtual time while frozen.
+	frozenAt time.Time
+	frozen   bool
+	// now returns the system time.
+	now func() time.Time
+}
+
+// New returns a new clock, following the system time.
+func New() *Clock {
+	return &Clock{now: time.Now}
+}
+
+// Now returns the current virtual time.
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.current()
+}
+
+// Frozen reports whether the clock is frozen.
+func (c *Clock) Frozen() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.frozen
+}
+
+// Freeze stops the clock at the current virtual time.
+func (c *Clock) Freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.frozenAt = c.current()
+	c.frozen = true
+}
+
+// Unfreeze lets the clock run again, from the time it was frozen at.
+func (c *Clock) Unfreeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.frozen {
+		return
+	}
+
+	c.offset = c.frozenAt.Sub(c.now())
+	c.frozen = false
+}
+
+// Set moves the clock to the provided time. A frozen clock stays frozen.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.frozen {
+		c.frozenAt = t
+		return
+	}
+
+	c.offset = t.Sub(c.now())
+}
+
+// Advance moves the clock forward by the provided duration. A frozen clock stays frozen.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.frozen {
+		c.frozenAt = c.frozenAt.Add(d)
+		return
+	}
+
+	c.offset += d
+}
+
+// Reset makes the clock follow the system time again.
+func (c *Clock) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.offset = 0
+	c.frozen = false
+}
+
+// current returns the virtual time. Callers must hold the lock.
+func (c *Clock) current() time.Time {
+	if c.frozen {
+		return c.frozenAt
+	}
+
+	return c.now().Add(c.offset)
+}
@@ -0,0 +1,47 @@
+package clock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/clock"
+)
+
+func TestClock(t *testing.T) {
+	c := clock.New()
+
+	c.Freeze()
+
+	frozenAt := c.Now()
+	time.Sleep(time.Millisecond * 5)
+
+	if now := c.Now(); !now.Equal(frozenAt) {
+		t.Fatalf("expected frozen time %v, but got %v", frozenAt, now)
+	}
+
+	c.Advance(time.Hour)
+	if now, expected := c.Now(), frozenAt.Add(time.Hour); !now.Equal(expected) {
+		t.Fatalf("expected advanced time %v, but got %v", expected, now)
+	}
+
+	date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c.Set(date)
+	if now := c.Now(); !now.Equal(date) {
+		t.Fatalf("expected set time %v, but got %v", date, now)
+	}
+
+	c.Unfreeze()
+	if c.Frozen() {
+		t.Fatalf("expected clock to be running")
+	}
+
+	if now := c.Now(); now.Before(date) || now.After(date.Add(time.Minute)) {
+		t.Fatalf("expected time to resume from %v, but got %v", date, now)
+	}
+
+	c.Reset()
+	if now := c.Now(); now.Sub(time.Now()) > time.Minute {
+		t.Fatalf("expected system time, but got %v", now)
+	}
+}
@@ -0,0 +1,101 @@
+// Package codegen generates typed clients of the resources a server serves, from the schemas inferred from their
+// records.
+package codegen
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/chanioxaris/json-server/internal/schema"
+)
+
+// Resource is a resource of the generated client.
+type Resource struct {
+	Key  string
+	Type *schema.Type
+	// Read and Write tell whether the read and write routes are served.
+	Read  bool
+	Write bool
+}
+
+// initialisms are written in upper case in Go names, e.g. 'ID' of 'userId'.
+var initialisms = map[string]bool{"Id": true, "Url": true, "Uri": true, "Api": true, "Http": true, "Uuid": true, "Json": true}
+
+// TypeName returns the name of the type of the records of the resource, i.e. the singular of its key in pascal
+// case, e.g. 'BlogPost' of 'blog-posts'.
+func TypeName(resourceKey string) string {
+	return pascal(singular(resourceKey), false)
+}
+
+// singular returns the singular of the english plural, e.g. 'category' of 'categories'.
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "ss"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return strings.TrimSuffix(word, "s")
+	}
+
+	return word
+}
+
+// pascal returns the name in pascal case, splitting it into words at characters other than letters and digits,
+// and at the upper case letters of camel case. Initialisms are written in upper case if set.
+func pascal(name string, useInitialisms bool) string {
+	words := make([]string, 0)
+
+	word := make([]rune, 0)
+	for _, r := range name {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			words = append(words, string(word))
+			word = word[:0]
+		case unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			words = append(words, string(word))
+			word = append(word[:0], r)
+		default:
+			word = append(word, r)
+		}
+	}
+
+	words = append(words, string(word))
+
+	var b strings.Builder
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+
+		runes := []rune(strings.ToLower(w))
+		runes[0] = unicode.ToUpper(runes[0])
+		w = string(runes)
+
+		if useInitialisms && initialisms[w] {
+			w = strings.ToUpper(w)
+		}
+
+		b.WriteString(w)
+	}
+
+	result := b.String()
+	if result == "" || unicode.IsDigit([]rune(result)[0]) {
+		result = "X" + result
+	}
+
+	return result
+}
+
+// camel returns the name in camel case, e.g. 'blogPosts' of 'blog-posts'.
+func camel(name string) string {
+	runes := []rune(pascal(name, false))
+	runes[0] = unicode.ToLower(runes[0])
+
+	return string(runes)
+}
+
+// PluralName returns the name of the resource in pascal case, e.g. 'BlogPosts' of 'blog-posts'.
+func PluralName(resourceKey string) string {
+	return pascal(resourceKey, false)
+}
@@ -0,0 +1,125 @@
+package codegen_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/codegen"
+	"github.com/chanioxaris/json-server/internal/schema"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func testResources() []codegen.Resource {
+	return []codegen.Resource{
+		{
+			Key: "blog-posts",
+			Type: schema.Infer([]storage.Resource{
+				{"id": "1", "title": "json-server", "authorId": float64(1), "meta": map[string]interface{}{"views": float64(3)}},
+				{"id": "2", "title": nil, "authorId": float64(2), "tags": []interface{}{"go"}},
+			}),
+			Read:  true,
+			Write: true,
+		},
+		{Key: "categories", Type: schema.Infer(nil), Read: true},
+	}
+}
+
+func TestTypeScriptClient(t *testing.T) {
+	client := codegen.TypeScriptClient(testResources())
+
+	expected := []string{
+		"export interface BlogPost {\n  authorId: number;\n  id: string;\n  meta?: {\n    views: number;\n  };\n  tags?: string[];\n  title: null | string;\n}",
+		"export interface Category { [key: string]: unknown }",
+		"listBlogPosts(query: Record<string, string> = {}): Promise<BlogPost[]>",
+		"createBlogPost(body: Omit<BlogPost, \"id\"> & Partial<Pick<BlogPost, \"id\">>): Promise<BlogPost>",
+		"deleteBlogPost(id: string | number): Promise<void>",
+		"getCategory(id: string | number): Promise<Category>",
+	}
+
+	for _, s := range expected {
+		if !strings.Contains(client, s) {
+			t.Fatalf("expected client to contain %q, but got:\n%s", s, client)
+		}
+	}
+
+	if strings.Contains(client, "createCategory") {
+		t.Fatalf("expected no write methods of read-only categories, but got:\n%s", client)
+	}
+}
+
+func TestGo(t *testing.T) {
+	src, err := codegen.Go("client", testResources())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "client.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Type check the client, so it compiles once written.
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	pkg, err := conf.Check("client", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("expected a valid client, but got %v:\n%s", err, src)
+	}
+
+	for _, name := range []string{"BlogPost", "Category", "Client", "New"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Fatalf("expected client to declare %v, but got none", name)
+		}
+	}
+
+	expected := []string{
+		"AuthorID int64",
+		"`json:\"id,omitempty\"`",
+		"Title *string",
+		"func (c *Client) ListBlogPosts(ctx context.Context, query url.Values) ([]BlogPost, error)",
+		"func (c *Client) GetCategory(ctx context.Context, id string) (*Category, error)",
+	}
+
+	for _, s := range expected {
+		if !strings.Contains(string(src), s) {
+			t.Fatalf("expected client to contain %q, but got:\n%s", s, src)
+		}
+	}
+}
+
+func TestTypeScriptTypes(t *testing.T) {
+	resources := []codegen.Resource{
+		{
+			Key: "events",
+			Type: schema.Infer([]storage.Resource{
+				{"id": float64(1), "payload": "created", "at": nil, "tags": []interface{}{"a"}},
+				{"id": float64(2), "payload": map[string]interface{}{"user-id": "7"}, "at": "2020-01-01", "tags": []interface{}{float64(1)}},
+				{"id": float64(3), "payload": true},
+			}),
+		},
+	}
+
+	types := codegen.TypeScriptTypes(resources)
+
+	expected := "export interface Event {\n" +
+		"  at?: null | string;\n" +
+		"  id: number;\n" +
+		"  payload: boolean | {\n    \"user-id\": string;\n  } | string;\n" +
+		"  tags?: (number | string)[];\n" +
+		"}\n"
+
+	if !strings.Contains(types, expected) {
+		t.Fatalf("expected types to contain %q, but got:\n%s", expected, types)
+	}
+
+	if strings.Contains(types, "class Client") {
+		t.Fatalf("expected types only, but got:\n%s", types)
+	}
+}
@@ -0,0 +1,214 @@
+package codegen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/schema"
+)
+
+// goClient is the client type, calling the routes with net/http.
+const goClient = `
+// Client calls the routes of a json server.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// New returns a client of the json server at the base url, e.g. 'http://localhost:3000'.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), HTTPClient: http.DefaultClient}
+}
+
+// Error is returned for responses with a status code other than 2xx.
+type Error struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("json server responded with %d: %s", e.StatusCode, e.Body)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		content, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(content)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &Error{StatusCode: resp.StatusCode, Body: string(content)}
+	}
+
+	if out == nil || len(content) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(content, out)
+}
+`
+
+// Go returns a go client of the resources, as source of the package, with the structs of their records and a
+// client calling their routes.
+func Go(pkg string, resources []Resource) ([]byte, error) {
+	var b strings.Builder
+
+	b.WriteString("// Code generated by json-server. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\t\"bytes\"\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"io\"\n\t\"io/ioutil\"\n\t\"net/http\"\n")
+
+	// The url package is only used by the routes.
+	for _, resource := range resources {
+		if resource.Read || resource.Write {
+			b.WriteString("\t\"net/url\"\n")
+			break
+		}
+	}
+
+	b.WriteString("\t\"strings\"\n)\n")
+
+	for _, resource := range resources {
+		name := TypeName(resource.Key)
+
+		fmt.Fprintf(&b, "\n// %s is a record of %s.\n", name, resource.Key)
+		fmt.Fprintf(&b, "type %s %s\n", name, goStruct(resource.Type))
+	}
+
+	b.WriteString(goClient)
+
+	for _, resource := range resources {
+		name := TypeName(resource.Key)
+		plural := pascal(resource.Key, true)
+		path := "/" + resource.Key
+
+		if resource.Read {
+			fmt.Fprintf(&b, "\n// List%s returns the %s matching the query, e.g. filters, sorting and pagination.\n", plural, resource.Key)
+			fmt.Fprintf(&b, "func (c *Client) List%s(ctx context.Context, query url.Values) ([]%s, error) {\n", plural, name)
+			fmt.Fprintf(&b, "\tpath := %q\n\tif len(query) > 0 {\n\t\tpath += \"?\" + query.Encode()\n\t}\n\n", path)
+			fmt.Fprintf(&b, "\tvar out []%s\n\tif err := c.do(ctx, http.MethodGet, path, nil, &out); err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn out, nil\n}\n", name)
+
+			fmt.Fprintf(&b, "\n// Get%s returns the record of %s with the id.\n", name, resource.Key)
+			fmt.Fprintf(&b, "func (c *Client) Get%s(ctx context.Context, id string) (*%s, error) {\n", name, name)
+			fmt.Fprintf(&b, "\tout := &%s{}\n\tif err := c.do(ctx, http.MethodGet, %q+url.PathEscape(id), nil, out); err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn out, nil\n}\n", name, path+"/")
+		}
+
+		if resource.Write {
+			fmt.Fprintf(&b, "\n// Create%s creates a record of %s, with a generated id unless set.\n", name, resource.Key)
+			fmt.Fprintf(&b, "func (c *Client) Create%s(ctx context.Context, record %s) (*%s, error) {\n", name, name, name)
+			fmt.Fprintf(&b, "\tout := &%s{}\n\tif err := c.do(ctx, http.MethodPost, %q, record, out); err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn out, nil\n}\n", name, path)
+
+			fmt.Fprintf(&b, "\n// Replace%s replaces the record of %s with the id.\n", name, resource.Key)
+			fmt.Fprintf(&b, "func (c *Client) Replace%s(ctx context.Context, id string, record %s) (*%s, error) {\n", name, name, name)
+			fmt.Fprintf(&b, "\tout := &%s{}\n\tif err := c.do(ctx, http.MethodPut, %q+url.PathEscape(id), record, out); err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn out, nil\n}\n", name, path+"/")
+
+			fmt.Fprintf(&b, "\n// Update%s updates the fields of the record of %s with the id.\n", name, resource.Key)
+			fmt.Fprintf(&b, "func (c *Client) Update%s(ctx context.Context, id string, fields map[string]interface{}) (*%s, error) {\n", name, name)
+			fmt.Fprintf(&b, "\tout := &%s{}\n\tif err := c.do(ctx, http.MethodPatch, %q+url.PathEscape(id), fields, out); err != nil {\n\t\treturn nil, err\n\t}\n\n\treturn out, nil\n}\n", name, path+"/")
+
+			fmt.Fprintf(&b, "\n// Delete%s deletes the record of %s with the id.\n", name, resource.Key)
+			fmt.Fprintf(&b, "func (c *Client) Delete%s(ctx context.Context, id string) error {\n", name)
+			fmt.Fprintf(&b, "\treturn c.do(ctx, http.MethodDelete, %q+url.PathEscape(id), nil, nil)\n}\n", path+"/")
+		}
+	}
+
+	return format.Source([]byte(b.String()))
+}
+
+// goStruct returns the go struct type of the fields of the observed objects. Optional and nullable fields are
+// pointers, unless their type holds nil already, and optional fields and ids are omitted when empty.
+func goStruct(t *schema.Type) string {
+	if len(t.Fields) == 0 {
+		return "map[string]interface{}"
+	}
+
+	var b strings.Builder
+
+	b.WriteString("struct {\n")
+
+	used := make(map[string]bool)
+	for _, name := range t.FieldNames() {
+		field := t.Fields[name]
+
+		fieldName := pascal(name, true)
+		for idx := 2; used[fieldName]; idx++ {
+			fieldName = fmt.Sprintf("%s%d", pascal(name, true), idx)
+		}
+
+		used[fieldName] = true
+
+		tag := name
+		if field.Optional || name == "id" {
+			tag += ",omitempty"
+		}
+
+		fmt.Fprintf(&b, "%s %s `json:%q`\n", fieldName, goType(field.Type, field.Optional || field.Type.Nullable()), tag)
+	}
+
+	b.WriteString("}")
+
+	return b.String()
+}
+
+// goType returns the go type of the observed values, interface{} if they have different kinds. Scalars and
+// structs are pointers if nullable.
+func goType(t *schema.Type, nullable bool) string {
+	kinds := t.NonNull()
+	if len(kinds) != 1 {
+		return "interface{}"
+	}
+
+	var typ string
+	switch kinds[0] {
+	case schema.KindString:
+		typ = "string"
+	case schema.KindInteger:
+		typ = "int64"
+	case schema.KindNumber:
+		typ = "float64"
+	case schema.KindBoolean:
+		typ = "bool"
+	case schema.KindObject:
+		typ = goStruct(t)
+		if len(t.Fields) == 0 {
+			return typ
+		}
+	case schema.KindArray:
+		if t.Items == nil {
+			return "[]interface{}"
+		}
+
+		return "[]" + goType(t.Items, t.Items.Nullable())
+	}
+
+	if nullable {
+		return "*" + typ
+	}
+
+	return typ
+}
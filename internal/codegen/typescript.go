@@ -0,0 +1,166 @@
+package codegen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/schema"
+)
+
+// tsIdentifier matches the field names which need no quotes in typescript.
+var tsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// tsClient is the client class, calling the routes with fetch.
+const tsClient = `export class Client {
+  constructor(private baseUrl: string = "http://localhost:3000", private init: RequestInit = {}) {
+    this.baseUrl = baseUrl.replace(/\/$/, "");
+  }
+
+  private async request<T>(method: string, path: string, body?: unknown): Promise<T> {
+    const headers: Record<string, string> = { ...(this.init.headers as Record<string, string>) };
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/json";
+    }
+
+    const res = await fetch(this.baseUrl + path, {
+      ...this.init,
+      method,
+      headers,
+      body: body === undefined ? undefined : JSON.stringify(body),
+    });
+
+    const text = await res.text();
+    if (!res.ok) {
+      throw new Error(` + "`${method} ${path}: ${res.status} ${text}`" + `);
+    }
+
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+`
+
+// TypeScriptTypes returns the typescript interfaces of the records of the resources.
+func TypeScriptTypes(resources []Resource) string {
+	var b strings.Builder
+
+	b.WriteString("// Code generated by json-server. DO NOT EDIT.\n")
+
+	for _, resource := range resources {
+		fmt.Fprintf(&b, "\nexport interface %s %s\n", TypeName(resource.Key), tsObject(resource.Type, ""))
+	}
+
+	return b.String()
+}
+
+// TypeScriptClient returns a typescript client of the resources, with the interfaces of their records and a
+// client class calling their routes.
+func TypeScriptClient(resources []Resource) string {
+	var b strings.Builder
+
+	b.WriteString(TypeScriptTypes(resources))
+	b.WriteString("\n" + tsClient)
+
+	for _, resource := range resources {
+		name := TypeName(resource.Key)
+		plural := pascal(resource.Key, false)
+		path := "/" + resource.Key
+
+		idType := "string | number"
+		input := name
+		if _, ok := resource.Type.Fields["id"]; ok {
+			input = fmt.Sprintf("Omit<%s, \"id\"> & Partial<Pick<%s, \"id\">>", name, name)
+		}
+
+		itemPath := fmt.Sprintf("`%s/${encodeURIComponent(String(id))}`", path)
+
+		if resource.Read {
+			fmt.Fprintf(&b, "\n  list%s(query: Record<string, string> = {}): Promise<%s[]> {\n", plural, name)
+			b.WriteString("    const qs = new URLSearchParams(query).toString();\n")
+			fmt.Fprintf(&b, "    return this.request(\"GET\", \"%s\" + (qs ? \"?\" + qs : \"\"));\n  }\n", path)
+
+			fmt.Fprintf(&b, "\n  get%s(id: %s): Promise<%s> {\n", name, idType, name)
+			fmt.Fprintf(&b, "    return this.request(\"GET\", %s);\n  }\n", itemPath)
+		}
+
+		if resource.Write {
+			fmt.Fprintf(&b, "\n  create%s(body: %s): Promise<%s> {\n", name, input, name)
+			fmt.Fprintf(&b, "    return this.request(\"POST\", \"%s\", body);\n  }\n", path)
+
+			fmt.Fprintf(&b, "\n  replace%s(id: %s, body: %s): Promise<%s> {\n", name, idType, input, name)
+			fmt.Fprintf(&b, "    return this.request(\"PUT\", %s, body);\n  }\n", itemPath)
+
+			fmt.Fprintf(&b, "\n  update%s(id: %s, body: Partial<%s>): Promise<%s> {\n", name, idType, name, name)
+			fmt.Fprintf(&b, "    return this.request(\"PATCH\", %s, body);\n  }\n", itemPath)
+
+			fmt.Fprintf(&b, "\n  delete%s(id: %s): Promise<void> {\n", name, idType)
+			fmt.Fprintf(&b, "    return this.request(\"DELETE\", %s);\n  }\n", itemPath)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// tsType returns the typescript type of the observed values, a union if they have different kinds.
+func tsType(t *schema.Type, indent string) string {
+	if t == nil || len(t.Kinds) == 0 {
+		return "unknown"
+	}
+
+	types := make([]string, 0, len(t.Kinds))
+	for _, kind := range t.Kinds {
+		switch kind {
+		case schema.KindString:
+			types = append(types, "string")
+		case schema.KindInteger, schema.KindNumber:
+			types = append(types, "number")
+		case schema.KindBoolean:
+			types = append(types, "boolean")
+		case schema.KindNull:
+			types = append(types, "null")
+		case schema.KindObject:
+			types = append(types, tsObject(t, indent))
+		case schema.KindArray:
+			items := tsType(t.Items, indent)
+			if t.Items != nil && len(t.Items.Kinds) > 1 {
+				items = "(" + items + ")"
+			}
+
+			types = append(types, items+"[]")
+		}
+	}
+
+	return strings.Join(types, " | ")
+}
+
+// tsObject returns the typescript object type of the fields of the observed objects.
+func tsObject(t *schema.Type, indent string) string {
+	if len(t.Fields) == 0 {
+		return "{ [key: string]: unknown }"
+	}
+
+	var b strings.Builder
+
+	b.WriteString("{\n")
+
+	for _, name := range t.FieldNames() {
+		field := t.Fields[name]
+
+		key := name
+		if !tsIdentifier.MatchString(name) {
+			key = fmt.Sprintf("%q", name)
+		}
+
+		optional := ""
+		if field.Optional {
+			optional = "?"
+		}
+
+		fmt.Fprintf(&b, "%s  %s%s: %s;\n", indent, key, optional, tsType(field.Type, indent+"  "))
+	}
+
+	b.WriteString(indent + "}")
+
+	return b.String()
+}
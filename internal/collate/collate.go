@@ -0,0 +1,211 @@
+// Package collate compares strings following the alphabetical order of a language, and normalizes strings to
+// their composed Unicode form, so that text compares equal however its accents are encoded. Latin letters are
+// covered, other characters sort by code point.
+package collate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrUnknownCollation returns an error when the language of a collation is not supported.
+var ErrUnknownCollation = errors.New("unknown collation")
+
+// Primary weights are spaced out, so tailored letters fit between the letters of the alphabet.
+const weightStep = 4
+
+// languages maps the supported languages to the letters they sort apart from their base letter. Languages
+// without tailored letters follow the root order, where accented letters sort with their base letter.
+var languages = map[string]map[rune]int{
+	"und": nil,
+	"de":  nil,
+	"en":  nil,
+	"fr":  nil,
+	"it":  nil,
+	"nl":  nil,
+	"pt":  nil,
+	"sv":  swedish,
+	"fi":  swedish,
+	"da":  danish,
+	"nb":  danish,
+	"nn":  danish,
+	"no":  danish,
+	"es":  spanish,
+}
+
+var (
+	// swedish sorts 'å', 'ä' and 'ö' after 'z', with 'æ' and 'ø' as 'ä' and 'ö'.
+	swedish = map[rune]int{
+		'å': afterZ(1),
+		'ä': afterZ(2),
+		'æ': afterZ(2),
+		'ö': afterZ(3),
+		'ø': afterZ(3),
+	}
+	// danish sorts 'æ', 'ø' and 'å' after 'z', with 'ä' and 'ö' as 'æ' and 'ø'.
+	danish = map[rune]int{
+		'æ': afterZ(1),
+		'ä': afterZ(1),
+		'ø': afterZ(2),
+		'ö': afterZ(2),
+		'å': afterZ(3),
+	}
+	// spanish sorts 'ñ' after 'n'.
+	spanish = map[rune]int{
+		'ñ': 'n'*weightStep + 1,
+	}
+)
+
+// expansions holds the letters sorting as several letters in the root order.
+var expansions = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+}
+
+func afterZ(offset int) int {
+	return 'z'*weightStep + offset
+}
+
+// Collator compares strings following the alphabetical order of a language. It is safe for concurrent use.
+type Collator struct {
+	tailoring map[rune]int
+}
+
+// New returns a collator for the language of the locale, e.g. 'sv' or 'sv-SE'.
+func New(locale string) (*Collator, error) {
+	language := strings.ToLower(locale)
+	if idx := strings.IndexAny(language, "-_"); idx >= 0 {
+		language = language[:idx]
+	}
+
+	tailoring, ok := languages[language]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCollation, locale)
+	}
+
+	return &Collator{tailoring: tailoring}, nil
+}
+
+// element holds the weights of a letter. Letters are compared by their primary weights first, i.e. their base
+// letter, then by their accents, and at last by their case, with lowercase first.
+type element struct {
+	primary   int
+	secondary int
+	tertiary  int
+}
+
+// Compare returns an integer comparing two strings, which is 0 if a == b, -1 if a < b and +1 if a > b.
+// Strings equal on every level are compared by code point, so that the order is total.
+func (c *Collator) Compare(a, b string) int {
+	keyA, keyB := c.key(a), c.key(b)
+
+	levels := []func(element) int{
+		func(e element) int { return e.primary },
+		func(e element) int { return e.secondary },
+		func(e element) int { return e.tertiary },
+	}
+
+	for _, level := range levels {
+		for idx := 0; idx < len(keyA) && idx < len(keyB); idx++ {
+			if wa, wb := level(keyA[idx]), level(keyB[idx]); wa != wb {
+				return compareInts(wa, wb)
+			}
+		}
+
+		if len(keyA) != len(keyB) {
+			return compareInts(len(keyA), len(keyB))
+		}
+	}
+
+	return strings.Compare(Normalize(a), Normalize(b))
+}
+
+func (c *Collator) key(s string) []element {
+	key := make([]element, 0, len(s))
+
+	for _, r := range Normalize(s) {
+		lower := unicode.ToLower(r)
+
+		tertiary := 0
+		if lower != r {
+			tertiary = 1
+		}
+
+		if weight, ok := c.tailoring[lower]; ok {
+			key = append(key, element{primary: weight, tertiary: tertiary})
+			continue
+		}
+
+		if expansion, ok := expansions[lower]; ok {
+			for _, e := range expansion {
+				key = append(key, element{primary: int(e) * weightStep, secondary: 1, tertiary: tertiary})
+			}
+
+			continue
+		}
+
+		base, marks := decompose(lower)
+		key = append(key, element{primary: int(base) * weightStep, secondary: marks, tertiary: tertiary})
+	}
+
+	return key
+}
+
+// decompose returns the base letter of a letter, and the weight of its marks.
+func decompose(r rune) (rune, int) {
+	marks := 0
+	for {
+		p, ok := decompositions[r]
+		if !ok {
+			p, ok = strokes[r]
+		}
+
+		if !ok {
+			return r, marks
+		}
+
+		r = p.base
+		marks = marks<<16 | int(p.mark)
+	}
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+
+	if a > b {
+		return 1
+	}
+
+	return 0
+}
+
+// Normalize returns the string in Unicode normalization form C, composing letters followed by combining marks,
+// e.g. 'e' followed by U+0301 into 'é'.
+func Normalize(s string) string {
+	if strings.IndexFunc(s, isMark) < 0 {
+		return s
+	}
+
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		if last := len(runes) - 1; last >= 0 && isMark(r) {
+			if composed, ok := compositions[pair{base: runes[last], mark: r}]; ok {
+				runes[last] = composed
+				continue
+			}
+		}
+
+		runes = append(runes, r)
+	}
+
+	return string(runes)
+}
+
+func isMark(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
@@ -0,0 +1,69 @@
+package collate_test
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/collate"
+)
+
+func TestCollatorCompare(t *testing.T) {
+	testCases := []struct {
+		name     string
+		locale   string
+		words    []string
+		expected []string
+	}{
+		{name: "Swedish", locale: "sv", words: []string{"Östra", "Zorn", "Åsa", "Ängel", "Anna"}, expected: []string{"Anna", "Zorn", "Åsa", "Ängel", "Östra"}},
+		{name: "Swedish with region", locale: "sv-SE", words: []string{"ö", "o", "z"}, expected: []string{"o", "z", "ö"}},
+		{name: "Danish", locale: "da", words: []string{"Åge", "Ørsted", "Ærø", "Zealand"}, expected: []string{"Zealand", "Ærø", "Ørsted", "Åge"}},
+		{name: "German", locale: "de", words: []string{"Zebra", "Äpfel", "Apfel", "Straße", "Strasse"}, expected: []string{"Apfel", "Äpfel", "Strasse", "Straße", "Zebra"}},
+		{name: "Spanish", locale: "es", words: []string{"oso", "ñu", "nube"}, expected: []string{"nube", "ñu", "oso"}},
+		{name: "Root", locale: "und", words: []string{"Zoë", "zoe", "Émile", "emile", "Zoe"}, expected: []string{"emile", "Émile", "zoe", "Zoe", "Zoë"}},
+		{name: "Decomposed accents", locale: "sv", words: []string{"öl", "zon"}, expected: []string{"zon", "öl"}},
+	}
+
+	for _, tt := range testCases {
+		c, err := collate.New(tt.locale)
+		if err != nil {
+			t.Fatalf("%s: expected no error, but got %v", tt.name, err)
+		}
+
+		words := append([]string(nil), tt.words...)
+		sort.SliceStable(words, func(i, j int) bool {
+			return c.Compare(words[i], words[j]) < 0
+		})
+
+		if !reflect.DeepEqual(words, tt.expected) {
+			t.Fatalf("%s: expected order %v, but got %v", tt.name, tt.expected, words)
+		}
+	}
+}
+
+func TestNewUnknownCollation(t *testing.T) {
+	if _, err := collate.New("tlh"); !errors.Is(err, collate.ErrUnknownCollation) {
+		t.Fatalf("expected error %v, but got %v", collate.ErrUnknownCollation, err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "Composed", input: "café", expected: "café"},
+		{name: "Decomposed", input: "café", expected: "café"},
+		{name: "Decomposed uppercase", input: "Ångström", expected: "Ångström"},
+		{name: "Mark without composition", input: "q́", expected: "q́"},
+		{name: "Leading mark", input: "́a", expected: "́a"},
+	}
+
+	for _, tt := range testCases {
+		if got := collate.Normalize(tt.input); got != tt.expected {
+			t.Fatalf("%s: expected %q, but got %q", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package collate
+
+// pair is a letter and the combining mark it carries.
+type pair struct {
+	base rune
+	mark rune
+}
+
+// decompositions holds the canonical decompositions of the Latin letters with diacritics, into the letter and
+// the combining mark. Letters carrying two marks decompose into a letter carrying the first one.
+var decompositions = map[rune]pair{
+	0x00C0: {'A', 0x0300},    // À
+	0x00C1: {'A', 0x0301},    // Á
+	0x00C2: {'A', 0x0302},    // Â
+	0x00C3: {'A', 0x0303},    // Ã
+	0x00C4: {'A', 0x0308},    // Ä
+	0x00C5: {'A', 0x030A},    // Å
+	0x00C7: {'C', 0x0327},    // Ç
+	0x00C8: {'E', 0x0300},    // È
+	0x00C9: {'E', 0x0301},    // É
+	0x00CA: {'E', 0x0302},    // Ê
+	0x00CB: {'E', 0x0308},    // Ë
+	0x00CC: {'I', 0x0300},    // Ì
+	0x00CD: {'I', 0x0301},    // Í
+	0x00CE: {'I', 0x0302},    // Î
+	0x00CF: {'I', 0x0308},    // Ï
+	0x00D1: {'N', 0x0303},    // Ñ
+	0x00D2: {'O', 0x0300},    // Ò
+	0x00D3: {'O', 0x0301},    // Ó
+	0x00D4: {'O', 0x0302},    // Ô
+	0x00D5: {'O', 0x0303},    // Õ
+	0x00D6: {'O', 0x0308},    // Ö
+	0x00D9: {'U', 0x0300},    // Ù
+	0x00DA: {'U', 0x0301},    // Ú
+	0x00DB: {'U', 0x0302},    // Û
+	0x00DC: {'U', 0x0308},    // Ü
+	0x00DD: {'Y', 0x0301},    // Ý
+	0x00E0: {'a', 0x0300},    // à
+	0x00E1: {'a', 0x0301},    // á
+	0x00E2: {'a', 0x0302},    // â
+	0x00E3: {'a', 0x0303},    // ã
+	0x00E4: {'a', 0x0308},    // ä
+	0x00E5: {'a', 0x030A},    // å
+	0x00E7: {'c', 0x0327},    // ç
+	0x00E8: {'e', 0x0300},    // è
+	0x00E9: {'e', 0x0301},    // é
+	0x00EA: {'e', 0x0302},    // ê
+	0x00EB: {'e', 0x0308},    // ë
+	0x00EC: {'i', 0x0300},    // ì
+	0x00ED: {'i', 0x0301},    // í
+	0x00EE: {'i', 0x0302},    // î
+	0x00EF: {'i', 0x0308},    // ï
+	0x00F1: {'n', 0x0303},    // ñ
+	0x00F2: {'o', 0x0300},    // ò
+	0x00F3: {'o', 0x0301},    // ó
+	0x00F4: {'o', 0x0302},    // ô
+	0x00F5: {'o', 0x0303},    // õ
+	0x00F6: {'o', 0x0308},    // ö
+	0x00F9: {'u', 0x0300},    // ù
+	0x00FA: {'u', 0x0301},    // ú
+	0x00FB: {'u', 0x0302},    // û
+	0x00FC: {'u', 0x0308},    // ü
+	0x00FD: {'y', 0x0301},    // ý
+	0x00FF: {'y', 0x0308},    // ÿ
+	0x0100: {'A', 0x0304},    // Ā
+	0x0101: {'a', 0x0304},    // ā
+	0x0102: {'A', 0x0306},    // Ă
+	0x0103: {'a', 0x0306},    // ă
+	0x0104: {'A', 0x0328},    // Ą
+	0x0105: {'a', 0x0328},    // ą
+	0x0106: {'C', 0x0301},    // Ć
+	0x0107: {'c', 0x0301},    // ć
+	0x0108: {'C', 0x0302},    // Ĉ
+	0x0109: {'c', 0x0302},    // ĉ
+	0x010A: {'C', 0x0307},    // Ċ
+	0x010B: {'c', 0x0307},    // ċ
+	0x010C: {'C', 0x030C},    // Č
+	0x010D: {'c', 0x030C},    // č
+	0x010E: {'D', 0x030C},    // Ď
+	0x010F: {'d', 0x030C},    // ď
+	0x0112: {'E', 0x0304},    // Ē
+	0x0113: {'e', 0x0304},    // ē
+	0x0114: {'E', 0x0306},    // Ĕ
+	0x0115: {'e', 0x0306},    // ĕ
+	0x0116: {'E', 0x0307},    // Ė
+	0x0117: {'e', 0x0307},    // ė
+	0x0118: {'E', 0x0328},    // Ę
+	0x0119: {'e', 0x0328},    // ę
+	0x011A: {'E', 0x030C},    // Ě
+	0x011B: {'e', 0x030C},    // ě
+	0x011C: {'G', 0x0302},    // Ĝ
+	0x011D: {'g', 0x0302},    // ĝ
+	0x011E: {'G', 0x0306},    // Ğ
+	0x011F: {'g', 0x0306},    // ğ
+	0x0120: {'G', 0x0307},    // Ġ
+	0x0121: {'g', 0x0307},    // ġ
+	0x0122: {'G', 0x0327},    // Ģ
+	0x0123: {'g', 0x0327},    // ģ
+	0x0124: {'H', 0x0302},    // Ĥ
+	0x0125: {'h', 0x0302},    // ĥ
+	0x0128: {'I', 0x0303},    // Ĩ
+	0x0129: {'i', 0x0303},    // ĩ
+	0x012A: {'I', 0x0304},    // Ī
+	0x012B: {'i', 0x0304},    // ī
+	0x012C: {'I', 0x0306},    // Ĭ
+	0x012D: {'i', 0x0306},    // ĭ
+	0x012E: {'I', 0x0328},    // Į
+	0x012F: {'i', 0x0328},    // į
+	0x0130: {'I', 0x0307},    // İ
+	0x0134: {'J', 0x0302},    // Ĵ
+	0x0135: {'j', 0x0302},    // ĵ
+	0x0136: {'K', 0x0327},    // Ķ
+	0x0137: {'k', 0x0327},    // ķ
+	0x0139: {'L', 0x0301},    // Ĺ
+	0x013A: {'l', 0x0301},    // ĺ
+	0x013B: {'L', 0x0327},    // Ļ
+	0x013C: {'l', 0x0327},    // ļ
+	0x013D: {'L', 0x030C},    // Ľ
+	0x013E: {'l', 0x030C},    // ľ
+	0x0143: {'N', 0x0301},    // Ń
+	0x0144: {'n', 0x0301},    // ń
+	0x0145: {'N', 0x0327},    // Ņ
+	0x0146: {'n', 0x0327},    // ņ
+	0x0147: {'N', 0x030C},    // Ň
+	0x0148: {'n', 0x030C},    // ň
+	0x014C: {'O', 0x0304},    // Ō
+	0x014D: {'o', 0x0304},    // ō
+	0x014E: {'O', 0x0306},    // Ŏ
+	0x014F: {'o', 0x0306},    // ŏ
+	0x0150: {'O', 0x030B},    // Ő
+	0x0151: {'o', 0x030B},    // ő
+	0x0154: {'R', 0x0301},    // Ŕ
+	0x0155: {'r', 0x0301},    // ŕ
+	0x0156: {'R', 0x0327},    // Ŗ
+	0x0157: {'r', 0x0327},    // ŗ
+	0x0158: {'R', 0x030C},    // Ř
+	0x0159: {'r', 0x030C},    // ř
+	0x015A: {'S', 0x0301},    // Ś
+	0x015B: {'s', 0x0301},    // ś
+	0x015C: {'S', 0x0302},    // Ŝ
+	0x015D: {'s', 0x0302},    // ŝ
+	0x015E: {'S', 0x0327},    // Ş
+	0x015F: {'s', 0x0327},    // ş
+	0x0160: {'S', 0x030C},    // Š
+	0x0161: {'s', 0x030C},    // š
+	0x0162: {'T', 0x0327},    // Ţ
+	0x0163: {'t', 0x0327},    // ţ
+	0x0164: {'T', 0x030C},    // Ť
+	0x0165: {'t', 0x030C},    // ť
+	0x0168: {'U', 0x0303},    // Ũ
+	0x0169: {'u', 0x0303},    // ũ
+	0x016A: {'U', 0x0304},    // Ū
+	0x016B: {'u', 0x0304},    // ū
+	0x016C: {'U', 0x0306},    // Ŭ
+	0x016D: {'u', 0x0306},    // ŭ
+	0x016E: {'U', 0x030A},    // Ů
+	0x016F: {'u', 0x030A},    // ů
+	0x0170: {'U', 0x030B},    // Ű
+	0x0171: {'u', 0x030B},    // ű
+	0x0172: {'U', 0x0328},    // Ų
+	0x0173: {'u', 0x0328},    // ų
+	0x0174: {'W', 0x0302},    // Ŵ
+	0x0175: {'w', 0x0302},    // ŵ
+	0x0176: {'Y', 0x0302},    // Ŷ
+	0x0177: {'y', 0x0302},    // ŷ
+	0x0178: {'Y', 0x0308},    // Ÿ
+	0x0179: {'Z', 0x0301},    // Ź
+	0x017A: {'z', 0x0301},    // ź
+	0x017B: {'Z', 0x0307},    // Ż
+	0x017C: {'z', 0x0307},    // ż
+	0x017D: {'Z', 0x030C},    // Ž
+	0x017E: {'z', 0x030C},    // ž
+	0x01A0: {'O', 0x031B},    // Ơ
+	0x01A1: {'o', 0x031B},    // ơ
+	0x01AF: {'U', 0x031B},    // Ư
+	0x01B0: {'u', 0x031B},    // ư
+	0x01CD: {'A', 0x030C},    // Ǎ
+	0x01CE: {'a', 0x030C},    // ǎ
+	0x01CF: {'I', 0x030C},    // Ǐ
+	0x01D0: {'i', 0x030C},    // ǐ
+	0x01D1: {'O', 0x030C},    // Ǒ
+	0x01D2: {'o', 0x030C},    // ǒ
+	0x01D3: {'U', 0x030C},    // Ǔ
+	0x01D4: {'u', 0x030C},    // ǔ
+	0x01D5: {0x00DC, 0x0304}, // Ǖ
+	0x01D6: {0x00FC, 0x0304}, // ǖ
+	0x01D7: {0x00DC, 0x0301}, // Ǘ
+	0x01D8: {0x00FC, 0x0301}, // ǘ
+	0x01D9: {0x00DC, 0x030C}, // Ǚ
+	0x01DA: {0x00FC, 0x030C}, // ǚ
+	0x01DB: {0x00DC, 0x0300}, // Ǜ
+	0x01DC: {0x00FC, 0x0300}, // ǜ
+	0x01DE: {0x00C4, 0x0304}, // Ǟ
+	0x01DF: {0x00E4, 0x0304}, // ǟ
+	0x01E0: {0x0226, 0x0304}, // Ǡ
+	0x01E1: {0x0227, 0x0304}, // ǡ
+	0x01E2: {0x00C6, 0x0304}, // Ǣ
+	0x01E3: {0x00E6, 0x0304}, // ǣ
+	0x01E6: {'G', 0x030C},    // Ǧ
+	0x01E7: {'g', 0x030C},    // ǧ
+	0x01E8: {'K', 0x030C},    // Ǩ
+	0x01E9: {'k', 0x030C},    // ǩ
+	0x01EA: {'O', 0x0328},    // Ǫ
+	0x01EB: {'o', 0x0328},    // ǫ
+	0x01EC: {0x01EA, 0x0304}, // Ǭ
+	0x01ED: {0x01EB, 0x0304}, // ǭ
+	0x01EE: {0x01B7, 0x030C}, // Ǯ
+	0x01EF: {0x0292, 0x030C}, // ǯ
+	0x01F0: {'j', 0x030C},    // ǰ
+	0x01F4: {'G', 0x0301},    // Ǵ
+	0x01F5: {'g', 0x0301},    // ǵ
+	0x01F8: {'N', 0x0300},    // Ǹ
+	0x01F9: {'n', 0x0300},    // ǹ
+	0x01FA: {0x00C5, 0x0301}, // Ǻ
+	0x01FB: {0x00E5, 0x0301}, // ǻ
+	0x01FC: {0x00C6, 0x0301}, // Ǽ
+	0x01FD: {0x00E6, 0x0301}, // ǽ
+	0x01FE: {0x00D8, 0x0301}, // Ǿ
+	0x01FF: {0x00F8, 0x0301}, // ǿ
+	0x0200: {'A', 0x030F},    // Ȁ
+	0x0201: {'a', 0x030F},    // ȁ
+	0x0202: {'A', 0x0311},    // Ȃ
+	0x0203: {'a', 0x0311},    // ȃ
+	0x0204: {'E', 0x030F},    // Ȅ
+	0x0205: {'e', 0x030F},    // ȅ
+	0x0206: {'E', 0x0311},    // Ȇ
+	0x0207: {'e', 0x0311},    // ȇ
+	0x0208: {'I', 0x030F},    // Ȉ
+	0x0209: {'i', 0x030F},    // ȉ
+	0x020A: {'I', 0x0311},    // Ȋ
+	0x020B: {'i', 0x0311},    // ȋ
+	0x020C: {'O', 0x030F},    // Ȍ
+	0x020D: {'o', 0x030F},    // ȍ
+	0x020E: {'O', 0x0311},    // Ȏ
+	0x020F: {'o', 0x0311},    // ȏ
+	0x0210: {'R', 0x030F},    // Ȑ
+	0x0211: {'r', 0x030F},    // ȑ
+	0x0212: {'R', 0x0311},    // Ȓ
+	0x0213: {'r', 0x0311},    // ȓ
+	0x0214: {'U', 0x030F},    // Ȕ
+	0x0215: {'u', 0x030F},    // ȕ
+	0x0216: {'U', 0x0311},    // Ȗ
+	0x0217: {'u', 0x0311},    // ȗ
+	0x0218: {'S', 0x0326},    // Ș
+	0x0219: {'s', 0x0326},    // ș
+	0x021A: {'T', 0x0326},    // Ț
+	0x021B: {'t', 0x0326},    // ț
+	0x021E: {'H', 0x030C},    // Ȟ
+	0x021F: {'h', 0x030C},    // ȟ
+	0x0226: {'A', 0x0307},    // Ȧ
+	0x0227: {'a', 0x0307},    // ȧ
+	0x0228: {'E', 0x0327},    // Ȩ
+	0x0229: {'e', 0x0327},    // ȩ
+	0x022A: {0x00D6, 0x0304}, // Ȫ
+	0x022B: {0x00F6, 0x0304}, // ȫ
+	0x022C: {0x00D5, 0x0304}, // Ȭ
+	0x022D: {0x00F5, 0x0304}, // ȭ
+	0x022E: {'O', 0x0307},    // Ȯ
+	0x022F: {'o', 0x0307},    // ȯ
+	0x0230: {0x022E, 0x0304}, // Ȱ
+	0x0231: {0x022F, 0x0304}, // ȱ
+	0x0232: {'Y', 0x0304},    // Ȳ
+	0x0233: {'y', 0x0304},    // ȳ
+}
+
+// strokes holds the letters with a stroke, which have no canonical decomposition, but sort as their base
+// letter with an accent.
+var strokes = map[rune]pair{
+	'Đ': {'D', 0x0335},
+	'đ': {'d', 0x0335},
+	'Ł': {'L', 0x0337},
+	'ł': {'l', 0x0337},
+	'Ø': {'O', 0x0338},
+	'ø': {'o', 0x0338},
+}
+
+// compositions is the inverse of decompositions, to compose letters followed by combining marks.
+var compositions = func() map[pair]rune {
+	c := make(map[pair]rune, len(decompositions))
+	for r, p := range decompositions {
+		c[p] = r
+	}
+
+	return c
+}()
@@ -0,0 +1,672 @@
+// Package config describes the optional configuration file, which customizes the behavior of
+// individual resources.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/expr"
+	"github.com/chanioxaris/json-server/internal/serializer"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/stub"
+)
+
+var (
+	// ErrFailedReadConfig returns an error when the configuration file cannot be read.
+	ErrFailedReadConfig = errors.New("failed to read config file")
+	// ErrFailedParseConfig returns an error when the configuration file is not valid.
+	ErrFailedParseConfig = errors.New("failed to parse config file")
+
+	errInvalidRoute         = errors.New("invalid route, expected a method and a path like 'GET /posts/:id'")
+	errUnknownGuardAction   = errors.New("unknown guard action, expected allow, deny or redirect")
+	errInvalidGuardStatus   = errors.New("invalid guard status")
+	errMissingGuardLocation = errors.New("missing location of redirect guard")
+	errInvalidSuccessStatus = errors.New("invalid success status, expected a 2xx status")
+	errInvalidDuration      = errors.New("invalid duration, expected a string like '250ms'")
+	errUnknownDistribution  = errors.New("unknown latency distribution, expected fixed, uniform, normal or poisson")
+	errInvalidLatencyRange  = errors.New("min latency exceeds max latency")
+	errInvalidBurstRate     = errors.New("invalid burst rate, expected between 0 and 100")
+	errInvalidMaxRecords    = errors.New("invalid max records, expected a positive number")
+	errUnknownEviction      = errors.New("unknown eviction policy, expected fifo or lru")
+	errInvalidAlias         = errors.New("invalid alias, expected letters, digits, '-' and '_' only")
+	errDuplicateAlias       = errors.New("alias already names a resource or another alias")
+	errInvalidOrigin        = errors.New("invalid origin, expected '*' or a scheme and host like 'https://app.example.com'")
+	errInvalidMethod        = errors.New("invalid method")
+	errInvalidFaultRate     = errors.New("invalid fault rate, expected above 0 and at most 1")
+	errMissingFaultKinds    = errors.New("missing fault kinds")
+	errUnknownMiddleware    = errors.New("unknown middleware, expected cors, logging, compress, auth, delay or chaos")
+	errDuplicateMiddleware  = errors.New("duplicate middleware")
+	errMiddlewareOrder      = errors.New("cors must come first, as it answers preflight requests before routing")
+)
+
+// aliasPattern matches the names of aliases, which are used as the first segment of url paths.
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+const (
+	// RulePublic allows requests without an access token.
+	RulePublic = "public"
+	// RuleAny allows requests with any valid access token.
+	RuleAny = "any"
+)
+
+// Config represents the structure of the configuration file.
+type Config struct {
+	// Private fields are stripped from the responses of all resources.
+	Private   []string            `json:"private"`
+	Resources map[string]Resource `json:"resources"`
+	// Scripts handle the requests of a route with a Lua script, by route e.g. 'POST /orders/:id/cancel'.
+	// Relative script files are resolved against the directory of the configuration file.
+	Scripts map[string]string `json:"scripts"`
+	// Guards decide whether the requests of a route are served, by route e.g. 'PATCH /posts/:id'. The
+	// first guard whose condition holds decides, and requests matching none are served.
+	Guards map[string][]Guard `json:"guards"`
+	// StatusCodes override the success status codes of routes, by route e.g. 'POST /orders'.
+	StatusCodes map[string]int `json:"statusCodes"`
+	// CORS allows cross-origin requests to all resources, except the ones with their own rules.
+	CORS *CORS `json:"cors"`
+	// Format encodes the responses of requests which do not ask for a format with the Accept header, e.g. 'xml',
+	// json if empty.
+	Format string `json:"format"`
+	// Middleware lists the layers requests pass through, in order, e.g. '["logging", "auth", "delay"]'. Layers
+	// left out are disabled, and all of them run in the default order if empty.
+	Middleware []string `json:"middleware"`
+}
+
+// Resource holds the configuration of a single resource.
+type Resource struct {
+	Access Access `json:"access"`
+	// ReadOnly rejects create, replace, update and delete requests.
+	ReadOnly bool `json:"readOnly"`
+	// WriteOnly rejects read requests, and omits the resource from the db endpoint.
+	WriteOnly bool `json:"writeOnly"`
+	// Hidden removes all routes of the resource, as if it did not exist.
+	Hidden bool `json:"hidden"`
+	// Private fields are stripped from responses, while kept in storage.
+	Private []string `json:"private"`
+	// Computed fields are derived from the stored fields at read time, with an expression per field.
+	Computed map[string]string `json:"computed"`
+	// Required fields must be present and not null in created and replaced resources, and cannot be
+	// set to null by updates.
+	Required []string `json:"required"`
+	// Unique fields cannot hold the same value in more than one resource.
+	Unique []string `json:"unique"`
+	// Defaults are assigned to the fields a created resource omits. String values may contain templates,
+	// e.g. '{{ auth.sub }}'.
+	Defaults map[string]interface{} `json:"defaults"`
+	// Latency delays the responses of the resource, with a delay sampled from a distribution.
+	Latency *Latency `json:"latency"`
+	// MaxRecords caps the number of resources, evicting resources on create once exceeded.
+	MaxRecords int `json:"maxRecords"`
+	// Eviction is the policy choosing the evicted resources, either 'fifo' (default) or 'lru'.
+	Eviction string `json:"eviction"`
+	// Aliases are other names serving the same resources, e.g. 'people' for 'users'.
+	Aliases []string `json:"aliases"`
+	// CORS allows cross-origin requests to the resource, instead of the rules for all resources.
+	CORS *CORS `json:"cors"`
+	// Faults break a share of the responses of the resource, e.g. with truncated bodies.
+	Faults *Faults `json:"faults"`
+	// Format encodes the responses of the resource, instead of the format for all resources.
+	Format string `json:"format"`
+}
+
+// Faults describes the share of responses broken, and the faults breaking them.
+type Faults struct {
+	// Rate is the share of broken responses, above 0 and at most 1.
+	Rate float64 `json:"rate"`
+	// Kinds are the faults picked at random for a broken response, each either 'hang', 'reset', 'truncate',
+	// 'malformed' or 'content-length'.
+	Kinds []string `json:"kinds"`
+}
+
+// CORS describes the cross-origin requests browsers are allowed to send.
+type CORS struct {
+	// Origins allowed to send requests, e.g. 'https://app.example.com', or '*' for any origin. Without any,
+	// only same-origin requests are allowed.
+	Origins []string `json:"origins"`
+	// Methods allowed, any method when empty.
+	Methods []string `json:"methods"`
+	// Headers allowed in requests, the requested ones when empty.
+	Headers []string `json:"headers"`
+	// Expose lists the response headers readable by clients, besides the safelisted ones.
+	Expose []string `json:"expose"`
+	// Credentials allows requests with cookies and authorization headers.
+	Credentials bool `json:"credentials"`
+	// MaxAge is how long browsers cache the preflight responses.
+	MaxAge Duration `json:"maxAge"`
+}
+
+// Latency distributions.
+const (
+	// LatencyFixed delays every response by the same delay.
+	LatencyFixed = "fixed"
+	// LatencyUniform delays responses uniformly between min and max.
+	LatencyUniform = "uniform"
+	// LatencyNormal delays responses normally distributed around the mean, within min and max if set.
+	LatencyNormal = "normal"
+	// LatencyPoisson delays responses by the delay, plus a burst for each of a Poisson distributed number
+	// of bursts, averaging rate per response.
+	LatencyPoisson = "poisson"
+)
+
+// Middleware layers.
+const (
+	// MiddlewareCORS allows cross-origin requests.
+	MiddlewareCORS = "cors"
+	// MiddlewareLogging logs the requests.
+	MiddlewareLogging = "logging"
+	// MiddlewareCompress compresses the responses.
+	MiddlewareCompress = "compress"
+	// MiddlewareAuth requires access tokens and credentials.
+	MiddlewareAuth = "auth"
+	// MiddlewareDelay delays the responses.
+	MiddlewareDelay = "delay"
+	// MiddlewareChaos fails a share of the requests with server errors.
+	MiddlewareChaos = "chaos"
+)
+
+// defaultMiddleware is the order of the layers, unless configured.
+var defaultMiddleware = []string{
+	MiddlewareCORS,
+	MiddlewareLogging,
+	MiddlewareCompress,
+	MiddlewareAuth,
+	MiddlewareDelay,
+	MiddlewareChaos,
+}
+
+// maxBurstRate is the highest average number of bursts per response.
+const maxBurstRate = 100
+
+// Latency describes the distribution of response delays.
+type Latency struct {
+	// Distribution is either 'fixed', 'uniform', 'normal' or 'poisson'.
+	Distribution string   `json:"distribution"`
+	Delay        Duration `json:"delay"`
+	Min          Duration `json:"min"`
+	Max          Duration `json:"max"`
+	Mean         Duration `json:"mean"`
+	StdDev       Duration `json:"stddev"`
+	Burst        Duration `json:"burst"`
+	Rate         float64  `json:"rate"`
+}
+
+// Duration is a duration written as a string, e.g. '250ms'.
+type Duration time.Duration
+
+// UnmarshalJSON parses the duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var src string
+	if err := json.Unmarshal(data, &src); err != nil {
+		return fmt.Errorf("%w: %s", errInvalidDuration, data)
+	}
+
+	parsed, err := time.ParseDuration(src)
+	if err != nil || parsed < 0 {
+		return fmt.Errorf("%w: %s", errInvalidDuration, data)
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// Guard actions.
+const (
+	// GuardAllow serves the request.
+	GuardAllow = "allow"
+	// GuardDeny rejects the request, with 403 unless set otherwise.
+	GuardDeny = "deny"
+	// GuardRedirect redirects the request, with 307 unless set otherwise.
+	GuardRedirect = "redirect"
+)
+
+// Guard decides on requests for which its condition holds.
+type Guard struct {
+	// When is an expression over the variables 'request', 'resource' and 'auth', e.g.
+	// 'request.header["x-feature"] == "beta" && resource.status != "locked"'.
+	When string `json:"when"`
+	// Action is either 'allow', 'deny' or 'redirect'.
+	Action string `json:"action"`
+	// Status overrides the status code of denied and redirected requests.
+	Status int `json:"status"`
+	// Message overrides the error message of denied requests.
+	Message string `json:"message"`
+	// Location is the target of redirects, and may contain templates, e.g. '/v2/posts/{{ request.params.id }}'.
+	Location string `json:"location"`
+}
+
+// Access holds the access rules of a resource, for read (GET and HEAD) and write requests. A rule is
+// either 'public', 'any', or a comma separated list of roles or scopes, one of which the access token
+// must grant. Empty rules fall back to the authentication flags.
+type Access struct {
+	Read  string `json:"read"`
+	Write string `json:"write"`
+}
+
+// Roles returns the roles or scopes of a rule, or nil for the 'public' and 'any' rules.
+func Roles(rule string) []string {
+	if rule == "" || rule == RulePublic || rule == RuleAny {
+		return nil
+	}
+
+	roles := make([]string, 0)
+	for _, role := range strings.Split(rule, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// Load reads and parses the configuration file.
+func Load(filename string) (*Config, error) {
+	contentBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedReadConfig, err)
+	}
+
+	cfg, err := Parse(contentBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	for route, scriptFile := range cfg.Scripts {
+		if !filepath.IsAbs(scriptFile) {
+			cfg.Scripts[route] = filepath.Join(filepath.Dir(filename), scriptFile)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Parse the configuration file content. Unknown fields are rejected, to catch typos early.
+func Parse(contentBytes []byte) (*Config, error) {
+	cfg := &Config{}
+
+	decoder := json.NewDecoder(bytes.NewReader(contentBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedParseConfig, err)
+	}
+
+	if cfg.Resources == nil {
+		cfg.Resources = make(map[string]Resource)
+	}
+
+	for resourceKey, resource := range cfg.Resources {
+		for field, src := range resource.Computed {
+			if _, err := expr.Parse(src); err != nil {
+				return nil, fmt.Errorf("%w: computed field %s.%s: %v", ErrFailedParseConfig, resourceKey, field, err)
+			}
+		}
+
+		for field, val := range resource.Defaults {
+			src, ok := val.(string)
+			if !ok {
+				continue
+			}
+
+			if _, err := expr.ParseTemplate(src); err != nil {
+				return nil, fmt.Errorf("%w: default field %s.%s: %v", ErrFailedParseConfig, resourceKey, field, err)
+			}
+		}
+	}
+
+	for resourceKey, resource := range cfg.Resources {
+		if resource.Latency == nil {
+			continue
+		}
+
+		if err := resource.Latency.validate(); err != nil {
+			return nil, fmt.Errorf("%w: latency of %s: %v", ErrFailedParseConfig, resourceKey, err)
+		}
+	}
+
+	for resourceKey, resource := range cfg.Resources {
+		if resource.Faults == nil {
+			continue
+		}
+
+		if err := resource.Faults.validate(); err != nil {
+			return nil, fmt.Errorf("%w: faults of %s: %v", ErrFailedParseConfig, resourceKey, err)
+		}
+	}
+
+	for resourceKey, resource := range cfg.Resources {
+		if resource.MaxRecords < 0 {
+			return nil, fmt.Errorf("%w: %s: %v: %d", ErrFailedParseConfig, resourceKey, errInvalidMaxRecords, resource.MaxRecords)
+		}
+
+		switch resource.Eviction {
+		case "", storage.EvictFIFO, storage.EvictLRU:
+		default:
+			return nil, fmt.Errorf("%w: %s: %v: %q", ErrFailedParseConfig, resourceKey, errUnknownEviction, resource.Eviction)
+		}
+	}
+
+	if cfg.Format != "" {
+		if _, err := serializer.Lookup(cfg.Format); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrFailedParseConfig, err)
+		}
+	}
+
+	for resourceKey, resource := range cfg.Resources {
+		if resource.Format == "" {
+			continue
+		}
+
+		if _, err := serializer.Lookup(resource.Format); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrFailedParseConfig, resourceKey, err)
+		}
+	}
+
+	if err := cfg.validateAliases(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedParseConfig, err)
+	}
+
+	if cfg.CORS != nil {
+		if err := cfg.CORS.validate(); err != nil {
+			return nil, fmt.Errorf("%w: cors: %v", ErrFailedParseConfig, err)
+		}
+	}
+
+	for resourceKey, resource := range cfg.Resources {
+		if resource.CORS == nil {
+			continue
+		}
+
+		if err := resource.CORS.validate(); err != nil {
+			return nil, fmt.Errorf("%w: cors of %s: %v", ErrFailedParseConfig, resourceKey, err)
+		}
+	}
+
+	for route, guards := range cfg.Guards {
+		if _, _, err := ParseRoute(route); err != nil {
+			return nil, fmt.Errorf("%w: guard %s: %v", ErrFailedParseConfig, route, err)
+		}
+
+		for _, guard := range guards {
+			if err := guard.validate(); err != nil {
+				return nil, fmt.Errorf("%w: guard %s: %v", ErrFailedParseConfig, route, err)
+			}
+		}
+	}
+
+	for route, statusCode := range cfg.StatusCodes {
+		if _, _, err := ParseRoute(route); err != nil {
+			return nil, fmt.Errorf("%w: status code %s: %v", ErrFailedParseConfig, route, err)
+		}
+
+		if statusCode < 200 || statusCode > 299 {
+			return nil, fmt.Errorf("%w: status code %s: %v: %d", ErrFailedParseConfig, route, errInvalidSuccessStatus, statusCode)
+		}
+	}
+
+	for route := range cfg.Scripts {
+		if _, _, err := ParseRoute(route); err != nil {
+			return nil, fmt.Errorf("%w: script %s: %v", ErrFailedParseConfig, route, err)
+		}
+	}
+
+	if err := cfg.validateMiddleware(); err != nil {
+		return nil, fmt.Errorf("%w: middleware: %v", ErrFailedParseConfig, err)
+	}
+
+	return cfg, nil
+}
+
+// validateMiddleware checks that the layers are known and listed once, with cors first if listed.
+func (c *Config) validateMiddleware() error {
+	known := make(map[string]bool)
+	for _, name := range defaultMiddleware {
+		known[name] = true
+	}
+
+	seen := make(map[string]bool)
+	for idx, name := range c.Middleware {
+		if !known[name] {
+			return fmt.Errorf("%w: %q", errUnknownMiddleware, name)
+		}
+
+		if seen[name] {
+			return fmt.Errorf("%w: %q", errDuplicateMiddleware, name)
+		}
+
+		if name == MiddlewareCORS && idx > 0 {
+			return errMiddlewareOrder
+		}
+
+		seen[name] = true
+	}
+
+	return nil
+}
+
+// validateAliases checks that aliases are valid names, and name neither a configured resource nor another alias.
+func (c *Config) validateAliases() error {
+	seen := make(map[string]bool)
+	for resourceKey := range c.Resources {
+		seen[resourceKey] = true
+	}
+
+	for resourceKey, resource := range c.Resources {
+		for _, alias := range resource.Aliases {
+			if !aliasPattern.MatchString(alias) || alias == "db" {
+				return fmt.Errorf("%w: %s: %q", errInvalidAlias, resourceKey, alias)
+			}
+
+			if seen[alias] {
+				return fmt.Errorf("%w: %s: %q", errDuplicateAlias, resourceKey, alias)
+			}
+
+			seen[alias] = true
+		}
+	}
+
+	return nil
+}
+
+// validate checks the origins and methods of the rules.
+func (c *CORS) validate() error {
+	for _, origin := range c.Origins {
+		if origin == "*" {
+			continue
+		}
+
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			return fmt.Errorf("%w: %q", errInvalidOrigin, origin)
+		}
+	}
+
+	for _, method := range c.Methods {
+		switch strings.ToUpper(method) {
+		case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+		default:
+			return fmt.Errorf("%w: %q", errInvalidMethod, method)
+		}
+	}
+
+	return nil
+}
+
+// validate checks the distribution and its parameters.
+func (l *Latency) validate() error {
+	switch l.Distribution {
+	case LatencyFixed, LatencyPoisson:
+		if l.Rate < 0 || l.Rate > maxBurstRate {
+			return fmt.Errorf("%w: %v", errInvalidBurstRate, l.Rate)
+		}
+	case LatencyUniform, LatencyNormal:
+		if l.Max != 0 && l.Min > l.Max {
+			return errInvalidLatencyRange
+		}
+	default:
+		return fmt.Errorf("%w: %q", errUnknownDistribution, l.Distribution)
+	}
+
+	return nil
+}
+
+// validate checks the rate and kinds of the faults.
+func (f *Faults) validate() error {
+	if f.Rate <= 0 || f.Rate > 1 {
+		return fmt.Errorf("%w: %v", errInvalidFaultRate, f.Rate)
+	}
+
+	if len(f.Kinds) == 0 {
+		return errMissingFaultKinds
+	}
+
+	for _, kind := range f.Kinds {
+		if err := stub.ValidateFault(kind); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validate checks the expression, action, status and location of the guard.
+func (g Guard) validate() error {
+	if _, err := expr.Parse(g.When); err != nil {
+		return err
+	}
+
+	switch g.Action {
+	case GuardAllow:
+	case GuardDeny:
+		if g.Status != 0 && (g.Status < 400 || g.Status > 599) {
+			return fmt.Errorf("%w: %d", errInvalidGuardStatus, g.Status)
+		}
+	case GuardRedirect:
+		if g.Status != 0 && (g.Status < 300 || g.Status > 399) {
+			return fmt.Errorf("%w: %d", errInvalidGuardStatus, g.Status)
+		}
+
+		if g.Location == "" {
+			return errMissingGuardLocation
+		}
+
+		if _, err := expr.ParseTemplate(g.Location); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("%w: %q", errUnknownGuardAction, g.Action)
+	}
+
+	return nil
+}
+
+// ParseRoute splits a route like 'DELETE /users/:id' into its method and a router path, where parameters
+// are written as '{id}'.
+func ParseRoute(route string) (string, string, error) {
+	parts := strings.Fields(route)
+	if len(parts) != 2 || !strings.HasPrefix(parts[1], "/") {
+		return "", "", errInvalidRoute
+	}
+
+	method := strings.ToUpper(parts[0])
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions:
+	default:
+		return "", "", errInvalidRoute
+	}
+
+	segments := strings.Split(parts[1], "/")
+	for idx, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			if len(segment) == 1 {
+				return "", "", errInvalidRoute
+			}
+
+			segments[idx] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return method, strings.Join(segments, "/"), nil
+}
+
+// HasAccessRules reports whether any resource declares access rules.
+func (c *Config) HasAccessRules() bool {
+	for _, resource := range c.Resources {
+		if resource.Access.Read != "" || resource.Access.Write != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MiddlewareOrder returns the enabled middleware layers, in the order requests pass through them.
+func (c *Config) MiddlewareOrder() []string {
+	if len(c.Middleware) == 0 {
+		return append([]string{}, defaultMiddleware...)
+	}
+
+	return append([]string{}, c.Middleware...)
+}
+
+// Aliases returns the resource key of every alias.
+func (c *Config) Aliases() map[string]string {
+	aliases := make(map[string]string)
+
+	for resourceKey, resource := range c.Resources {
+		for _, alias := range resource.Aliases {
+			aliases[alias] = resourceKey
+		}
+	}
+
+	return aliases
+}
+
+// PrivateFields returns the private fields of every resource, including the ones of all resources.
+func (c *Config) PrivateFields(resourceKeys []string) map[string][]string {
+	fields := make(map[string][]string)
+
+	for _, resourceKey := range resourceKeys {
+		resourceFields := append(append([]string{}, c.Private...), c.Resources[resourceKey].Private...)
+		if len(resourceFields) > 0 {
+			fields[resourceKey] = resourceFields
+		}
+	}
+
+	return fields
+}
+
+// ComputedFields returns the computed fields of every resource, as functions evaluating their expression.
+// Fields with invalid expressions are skipped.
+func (c *Config) ComputedFields() map[string]map[string]storage.ComputeFunc {
+	fields := make(map[string]map[string]storage.ComputeFunc)
+
+	for resourceKey, resource := range c.Resources {
+		for field, src := range resource.Computed {
+			e, err := expr.Parse(src)
+			if err != nil {
+				continue
+			}
+
+			if fields[resourceKey] == nil {
+				fields[resourceKey] = make(map[string]storage.ComputeFunc)
+			}
+
+			fields[resourceKey][field] = func(resource storage.Resource) (interface{}, error) {
+				return e.Eval(resource)
+			}
+		}
+	}
+
+	return fields
+}
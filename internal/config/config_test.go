@@ -0,0 +1,322 @@
+package config_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		access  config.Access
+		err     error
+	}{
+		{
+			name:    "Parse access rules",
+			content: `{"resources": {"posts": {"access": {"read": "public", "write": "admin"}}}}`,
+			access:  config.Access{Read: "public", Write: "admin"},
+		},
+		{
+			name:    "Parse unknown field",
+			content: `{"resources": {"posts": {"acess": {}}}}`,
+			err:     config.ErrFailedParseConfig,
+		},
+		{
+			name:    "Parse invalid json",
+			content: `{"resources": `,
+			err:     config.ErrFailedParseConfig,
+		},
+	}
+
+	for _, tt := range testCases {
+		cfg, err := config.Parse([]byte(tt.content))
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if tt.err == nil && cfg.Resources["posts"].Access != tt.access {
+			t.Fatalf("%s: expected access %v, but got %v", tt.name, tt.access, cfg.Resources["posts"].Access)
+		}
+	}
+}
+
+func TestRoles(t *testing.T) {
+	testCases := []struct {
+		rule  string
+		roles []string
+	}{
+		{rule: "", roles: nil},
+		{rule: config.RulePublic, roles: nil},
+		{rule: config.RuleAny, roles: nil},
+		{rule: "admin", roles: []string{"admin"}},
+		{rule: "admin, editor,", roles: []string{"admin", "editor"}},
+	}
+
+	for _, tt := range testCases {
+		if roles := config.Roles(tt.rule); !reflect.DeepEqual(roles, tt.roles) {
+			t.Fatalf("expected roles %v for rule %q, but got %v", tt.roles, tt.rule, roles)
+		}
+	}
+}
+
+func TestParseComputed(t *testing.T) {
+	if _, err := config.Parse([]byte(`{"resources": {"orders": {"computed": {"total": "qty *"}}}}`)); !errors.Is(err, config.ErrFailedParseConfig) {
+		t.Fatalf("expected error %v, but got %v", config.ErrFailedParseConfig, err)
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	if _, err := config.Parse([]byte(`{"resources": {"posts": {"defaults": {"author": "{{ auth.sub"}}}}`)); !errors.Is(err, config.ErrFailedParseConfig) {
+		t.Fatalf("expected error %v, but got %v", config.ErrFailedParseConfig, err)
+	}
+}
+
+func TestParseRoute(t *testing.T) {
+	testCases := []struct {
+		route  string
+		method string
+		path   string
+		valid  bool
+	}{
+		{route: "POST /orders", method: "POST", path: "/orders", valid: true},
+		{route: "delete /users/:id", method: "DELETE", path: "/users/{id}", valid: true},
+		{route: "POST /orders/:id/cancel", method: "POST", path: "/orders/{id}/cancel", valid: true},
+		{route: "/orders"},
+		{route: "FETCH /orders"},
+		{route: "GET orders"},
+		{route: "GET /users/:"},
+	}
+
+	for _, tt := range testCases {
+		method, path, err := config.ParseRoute(tt.route)
+		if (err == nil) != tt.valid {
+			t.Fatalf("expected route %q to be valid %v, but got error %v", tt.route, tt.valid, err)
+		}
+
+		if method != tt.method || path != tt.path {
+			t.Fatalf("expected route %q as %s %s, but got %s %s", tt.route, tt.method, tt.path, method, path)
+		}
+	}
+}
+
+func TestParseGuards(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{
+			name:    "Parse valid guards",
+			content: `{"guards": {"GET /posts/:id": [{"when": "resource.status == 'locked'", "action": "redirect", "location": "/posts"}]}}`,
+		},
+		{
+			name:    "Parse invalid condition",
+			content: `{"guards": {"GET /posts": [{"when": "status ==", "action": "deny"}]}}`,
+			err:     config.ErrFailedParseConfig,
+		},
+		{
+			name:    "Parse unknown action",
+			content: `{"guards": {"GET /posts": [{"when": "true", "action": "block"}]}}`,
+			err:     config.ErrFailedParseConfig,
+		},
+		{
+			name:    "Parse redirect without location",
+			content: `{"guards": {"GET /posts": [{"when": "true", "action": "redirect"}]}}`,
+			err:     config.ErrFailedParseConfig,
+		},
+		{
+			name:    "Parse deny with success status",
+			content: `{"guards": {"GET /posts": [{"when": "true", "action": "deny", "status": 200}]}}`,
+			err:     config.ErrFailedParseConfig,
+		},
+		{
+			name:    "Parse invalid route",
+			content: `{"guards": {"/posts": [{"when": "true", "action": "deny"}]}}`,
+			err:     config.ErrFailedParseConfig,
+		},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseStatusCodes(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse valid status codes", content: `{"statusCodes": {"POST /orders": 202, "DELETE /users/:id": 204}}`},
+		{name: "Parse error status code", content: `{"statusCodes": {"POST /orders": 404}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse invalid route", content: `{"statusCodes": {"/orders": 202}}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseLatency(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse fixed latency", content: `{"resources": {"posts": {"latency": {"distribution": "fixed", "delay": "200ms"}}}}`},
+		{name: "Parse poisson latency", content: `{"resources": {"posts": {"latency": {"distribution": "poisson", "delay": "50ms", "burst": "2s", "rate": 0.05}}}}`},
+		{name: "Parse unknown distribution", content: `{"resources": {"posts": {"latency": {"distribution": "gamma"}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse invalid duration", content: `{"resources": {"posts": {"latency": {"distribution": "fixed", "delay": 200}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse negative duration", content: `{"resources": {"posts": {"latency": {"distribution": "fixed", "delay": "-1s"}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse inverted range", content: `{"resources": {"posts": {"latency": {"distribution": "uniform", "min": "2s", "max": "1s"}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse excessive burst rate", content: `{"resources": {"posts": {"latency": {"distribution": "poisson", "rate": 1000}}}}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseMaxRecords(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse max records", content: `{"resources": {"events": {"maxRecords": 1000}}}`},
+		{name: "Parse lru eviction", content: `{"resources": {"events": {"maxRecords": 1000, "eviction": "lru"}}}`},
+		{name: "Parse negative max records", content: `{"resources": {"events": {"maxRecords": -1}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse unknown eviction", content: `{"resources": {"events": {"maxRecords": 10, "eviction": "random"}}}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseAliases(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse aliases", content: `{"resources": {"users": {"aliases": ["people", "members"]}}}`},
+		{name: "Parse invalid alias", content: `{"resources": {"users": {"aliases": ["people/1"]}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse alias of a resource", content: `{"resources": {"users": {"aliases": ["posts"]}, "posts": {}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse duplicate alias", content: `{"resources": {"users": {"aliases": ["people"]}, "authors": {"aliases": ["people"]}}}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseCORS(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse cors", content: `{"cors": {"origins": ["*"]}, "resources": {"users": {"cors": {"origins": ["https://app.example.com"], "methods": ["get"], "credentials": true, "maxAge": "10m"}}}}`},
+		{name: "Parse same-origin resource", content: `{"cors": {"origins": ["*"]}, "resources": {"users": {"cors": {}}}}`},
+		{name: "Parse origin without scheme", content: `{"cors": {"origins": ["app.example.com"]}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse origin with path", content: `{"resources": {"users": {"cors": {"origins": ["https://app.example.com/login"]}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse invalid method", content: `{"resources": {"users": {"cors": {"origins": ["*"], "methods": ["FETCH"]}}}}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseFaults(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse faults", content: `{"resources": {"posts": {"faults": {"rate": 0.1, "kinds": ["truncate", "malformed", "content-length"]}}}}`},
+		{name: "Parse zero rate", content: `{"resources": {"posts": {"faults": {"rate": 0, "kinds": ["reset"]}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse excessive rate", content: `{"resources": {"posts": {"faults": {"rate": 1.5, "kinds": ["reset"]}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse missing kinds", content: `{"resources": {"posts": {"faults": {"rate": 0.5}}}}`, err: config.ErrFailedParseConfig},
+		{name: "Parse unknown kind", content: `{"resources": {"posts": {"faults": {"rate": 0.5, "kinds": ["close"]}}}}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse formats", content: `{"format": "xml", "resources": {"reports": {"format": "csv"}}}`},
+		{name: "Parse unknown format", content: `{"format": "yaml"}`, err: config.ErrFailedParseConfig},
+		{name: "Parse unknown resource format", content: `{"resources": {"reports": {"format": "xlsx"}}}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		if _, err := config.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseMiddleware(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+		err      error
+	}{
+		{
+			name:     "Parse default middleware",
+			content:  `{}`,
+			expected: []string{"cors", "logging", "compress", "auth", "delay", "chaos"},
+		},
+		{
+			name:     "Parse middleware order",
+			content:  `{"middleware": ["cors", "delay", "auth", "logging"]}`,
+			expected: []string{"cors", "delay", "auth", "logging"},
+		},
+		{name: "Parse unknown middleware", content: `{"middleware": ["gzip"]}`, err: config.ErrFailedParseConfig},
+		{name: "Parse duplicate middleware", content: `{"middleware": ["auth", "auth"]}`, err: config.ErrFailedParseConfig},
+		{name: "Parse cors after other middleware", content: `{"middleware": ["logging", "cors"]}`, err: config.ErrFailedParseConfig},
+	}
+
+	for _, tt := range testCases {
+		cfg, err := config.Parse([]byte(tt.content))
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if err != nil {
+			continue
+		}
+
+		if got := cfg.MiddlewareOrder(); !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("%s: expected middleware %v, but got %v", tt.name, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package events broadcasts the changes of the resources to their subscribers, e.g. the clients of the event
+// stream, so they can update once a resource is created, changed or deleted through the API.
+package events
+
+import (
+	"sync"
+)
+
+// Actions of the events.
+const (
+	ActionCreate  = "create"
+	ActionReplace = "replace"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+)
+
+// subscriberBuffer is the number of events a subscriber may fall behind, before it is dropped.
+const subscriberBuffer = 64
+
+// Event describes a change of a resource.
+type Event struct {
+	// Seq numbers the events in the order they were published, starting from 1.
+	Seq      int         `json:"-"`
+	Resource string      `json:"resource"`
+	Action   string      `json:"action"`
+	ID       interface{} `json:"id"`
+	// Data is the resource once changed, as responded, and is empty once deleted.
+	Data map[string]interface{} `json:"data,omitempty"`
+}
+
+// Broker publishes events to their subscribers, and keeps the last ones, so subscribers reconnecting can catch up
+// with the events they missed. It is safe for concurrent use.
+type Broker struct {
+	mu          sync.Mutex
+	seq         int
+	history     []Event
+	size        int
+	subscribers map[*subscriber]bool
+}
+
+// subscriber receives the events of the resources, or of all resources if empty.
+type subscriber struct {
+	resources map[string]bool
+	events    chan Event
+}
+
+// NewBroker returns a broker keeping the provided number of events.
+func NewBroker(size int) *Broker {
+	return &Broker{size: size, subscribers: make(map[*subscriber]bool)}
+}
+
+// Publish numbers the event, and sends it to the subscribers of its resource. Subscribers too slow to keep up are
+// dropped, their channel closed, rather than slowing down the writes.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.Seq = b.seq
+
+	if b.size > 0 {
+		if len(b.history) >= b.size {
+			b.history = append(b.history[:0], b.history[1:]...)
+		}
+
+		b.history = append(b.history, event)
+	}
+
+	for s := range b.subscribers {
+		if !s.wants(event) {
+			continue
+		}
+
+		select {
+		case s.events <- event:
+		default:
+			b.drop(s)
+		}
+	}
+}
+
+// Subscribe returns the channel of the events of the resources, or of all resources if none, the sequence number
+// of the last event published so far, and the function ending the subscription. Unless negative, the kept events
+// published after the sequence number are sent first. The channel is closed once the subscription ends, or the
+// subscriber falls behind.
+func (b *Broker) Subscribe(resources []string, after int) (<-chan Event, int, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &subscriber{resources: make(map[string]bool, len(resources)), events: make(chan Event, subscriberBuffer+b.size)}
+	for _, resource := range resources {
+		s.resources[resource] = true
+	}
+
+	for _, event := range b.history {
+		if after >= 0 && event.Seq > after && s.wants(event) {
+			s.events <- event
+		}
+	}
+
+	b.subscribers[s] = true
+
+	return s.events, b.seq, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.drop(s)
+	}
+}
+
+// drop ends the subscription, if not ended yet. Callers must hold the lock.
+func (b *Broker) drop(s *subscriber) {
+	if !b.subscribers[s] {
+		return
+	}
+
+	delete(b.subscribers, s)
+	close(s.events)
+}
+
+// wants reports whether the event is of the resources of the subscriber.
+func (s *subscriber) wants(event Event) bool {
+	return len(s.resources) == 0 || s.resources[event.Resource]
+}
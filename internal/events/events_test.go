@@ -0,0 +1,79 @@
+package events_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/events"
+)
+
+func TestBroker(t *testing.T) {
+	broker := events.NewBroker(2)
+
+	broker.Publish(events.Event{Resource: "posts", Action: events.ActionCreate, ID: "1"})
+	broker.Publish(events.Event{Resource: "comments", Action: events.ActionCreate, ID: "1"})
+	broker.Publish(events.Event{Resource: "posts", Action: events.ActionUpdate, ID: "1"})
+
+	testCases := []struct {
+		name      string
+		resources []string
+		after     int
+		seqs      []int
+	}{
+		{name: "Subscribe to new events", after: -1},
+		{name: "Replay kept events", after: 0, seqs: []int{2, 3}},
+		{name: "Replay events after sequence number", after: 2, seqs: []int{3}},
+		{name: "Replay events of resource", resources: []string{"comments"}, after: 0, seqs: []int{2}},
+	}
+
+	for _, tt := range testCases {
+		stream, seq, unsubscribe := broker.Subscribe(tt.resources, tt.after)
+		if seq != 3 {
+			t.Fatalf("%s: expected sequence number 3, but got %v", tt.name, seq)
+		}
+
+		unsubscribe()
+
+		seqs := make([]int, 0)
+		for event := range stream {
+			seqs = append(seqs, event.Seq)
+		}
+
+		if len(tt.seqs) == 0 {
+			tt.seqs = []int{}
+		}
+
+		if !reflect.DeepEqual(seqs, tt.seqs) {
+			t.Fatalf("%s: expected events %v, but got %v", tt.name, tt.seqs, seqs)
+		}
+	}
+}
+
+func TestBroker_Publish(t *testing.T) {
+	broker := events.NewBroker(0)
+
+	posts, _, unsubscribe := broker.Subscribe([]string{"posts"}, -1)
+	defer unsubscribe()
+
+	broker.Publish(events.Event{Resource: "comments", Action: events.ActionCreate, ID: "1"})
+	broker.Publish(events.Event{Resource: "posts", Action: events.ActionDelete, ID: "1"})
+
+	event := <-posts
+	if event.Resource != "posts" || event.Action != events.ActionDelete || event.Seq != 2 {
+		t.Fatalf("expected the delete of posts, but got %+v", event)
+	}
+
+	// Subscribers falling behind are dropped.
+	for i := 0; i < 100; i++ {
+		broker.Publish(events.Event{Resource: "posts", Action: events.ActionUpdate, ID: "1"})
+	}
+
+	count := 0
+	for range posts {
+		count++
+	}
+
+	if count == 0 || count == 100 {
+		t.Fatalf("expected the subscriber to be dropped once behind, but got %v events", count)
+	}
+}
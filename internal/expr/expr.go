@@ -0,0 +1,412 @@
+// Package expr evaluates small expressions over JSON values, e.g. 'firstName + " " + lastName' or
+// 'qty * price'. The syntax resembles CEL and JavaScript: literals, field access, arithmetic, comparison,
+// logical and conditional operators, and a few built-in functions.
+package expr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrSyntax returns an error when an expression cannot be parsed.
+	ErrSyntax = errors.New("syntax error")
+	// ErrEval returns an error when an expression cannot be evaluated, e.g. due to mismatched types.
+	ErrEval = errors.New("evaluation error")
+)
+
+// Expr is a parsed expression. It is safe for concurrent use.
+type Expr struct {
+	src  string
+	root node
+}
+
+// Parse an expression.
+func Parse(src string) (*Expr, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+
+	return &Expr{src: src, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression with the provided variables. Unknown variables and missing fields
+// evaluate to null.
+func (e *Expr) Eval(vars map[string]interface{}) (interface{}, error) {
+	return e.root.eval(vars)
+}
+
+// EvalBool evaluates the expression, which must result in a boolean.
+func (e *Expr) EvalBool(vars map[string]interface{}) (bool, error) {
+	val, err := e.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+
+	b, ok := val.(bool)
+	if !ok {
+		return false, fmt.Errorf("%w: expected boolean result, but got %v", ErrEval, describe(val))
+	}
+
+	return b, nil
+}
+
+func (n *literalNode) eval(map[string]interface{}) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *identNode) eval(vars map[string]interface{}) (interface{}, error) {
+	return normalize(vars[n.name]), nil
+}
+
+func (n *memberNode) eval(vars map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	return field(target, n.name)
+}
+
+func (n *indexNode) eval(vars map[string]interface{}) (interface{}, error) {
+	target, err := n.target.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := n.index.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch idx := index.(type) {
+	case string:
+		return field(target, idx)
+	case float64:
+		list, ok := target.([]interface{})
+		if !ok {
+			if target == nil {
+				return nil, nil
+			}
+
+			return nil, fmt.Errorf("%w: cannot index %v with a number", ErrEval, describe(target))
+		}
+
+		if idx < 0 || int(idx) >= len(list) || idx != math.Trunc(idx) {
+			return nil, nil
+		}
+
+		return normalize(list[int(idx)]), nil
+	}
+
+	return nil, fmt.Errorf("%w: invalid index %v", ErrEval, describe(index))
+}
+
+func (n *callNode) eval(vars map[string]interface{}) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		val, err := arg.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, val)
+	}
+
+	return functions[n.name](args)
+}
+
+func (n *unaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	operand, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		b, ok := operand.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: cannot negate %v", ErrEval, describe(operand))
+		}
+
+		return !b, nil
+	}
+
+	number, ok := operand.(float64)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot negate %v", ErrEval, describe(operand))
+	}
+
+	return -number, nil
+}
+
+func (n *binaryNode) eval(vars map[string]interface{}) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	// Logical operators short circuit.
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: operator %s requires booleans, but got %v", ErrEval, n.op, describe(left))
+		}
+
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+
+		right, err := n.right.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+
+		r, ok := right.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%w: operator %s requires booleans, but got %v", ErrEval, n.op, describe(right))
+		}
+
+		return r, nil
+	}
+
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "+":
+		return add(left, right)
+	}
+
+	// Remaining operators compare or combine numbers, or compare strings.
+	if l, ok := left.(string); ok {
+		if r, ok := right.(string); ok {
+			switch n.op {
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			}
+		}
+	}
+
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if !lok || !rok {
+		return nil, fmt.Errorf("%w: operator %s requires numbers, but got %v and %v", ErrEval, n.op, describe(left), describe(right))
+	}
+
+	switch n.op {
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("%w: division by zero", ErrEval)
+		}
+
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("%w: division by zero", ErrEval)
+		}
+
+		return math.Mod(l, r), nil
+	case "<":
+		return l < r, nil
+	case "<=":
+		return l <= r, nil
+	case ">":
+		return l > r, nil
+	default:
+		return l >= r, nil
+	}
+}
+
+func (n *conditionalNode) eval(vars map[string]interface{}) (interface{}, error) {
+	cond, err := n.cond.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	b, ok := cond.(bool)
+	if !ok {
+		return nil, fmt.Errorf("%w: condition must be a boolean, but got %v", ErrEval, describe(cond))
+	}
+
+	if b {
+		return n.then.eval(vars)
+	}
+
+	return n.otherwise.eval(vars)
+}
+
+// field returns the field of an object, or null if the target is not an object or misses the field.
+func field(target interface{}, name string) (interface{}, error) {
+	switch obj := target.(type) {
+	case map[string]interface{}:
+		return normalize(obj[name]), nil
+	case map[string]string:
+		val, ok := obj[name]
+		if !ok {
+			return nil, nil
+		}
+
+		return val, nil
+	case map[string][]string:
+		// Multi-valued maps, like http headers and query parameters, resolve to their first value.
+		if vals := obj[name]; len(vals) > 0 {
+			return vals[0], nil
+		}
+
+		return nil, nil
+	case nil:
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("%w: cannot access field %q of %v", ErrEval, name, describe(target))
+}
+
+// add sums numbers, concatenates lists, and concatenates strings with any other value.
+func add(left, right interface{}) (interface{}, error) {
+	l, lok := left.(float64)
+	r, rok := right.(float64)
+	if lok && rok {
+		return l + r, nil
+	}
+
+	if ll, ok := left.([]interface{}); ok {
+		if rl, ok := right.([]interface{}); ok {
+			return append(append([]interface{}{}, ll...), rl...), nil
+		}
+	}
+
+	_, lstr := left.(string)
+	_, rstr := right.(string)
+	if lstr || rstr {
+		return Format(left) + Format(right), nil
+	}
+
+	return nil, fmt.Errorf("%w: cannot add %v and %v", ErrEval, describe(left), describe(right))
+}
+
+func equal(left, right interface{}) bool {
+	return reflect.DeepEqual(left, right)
+}
+
+var (
+	mapType      = reflect.TypeOf(map[string]interface{}{})
+	multiMapType = reflect.TypeOf(map[string][]string{})
+)
+
+// normalize converts Go values to the JSON value types the evaluator works with.
+func normalize(val interface{}) interface{} {
+	switch v := val.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return v.String()
+		}
+
+		return f
+	case []string:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, item)
+		}
+
+		return list
+	}
+
+	// Named map types, like storage resources or http headers, are converted to plain maps.
+	rv := reflect.ValueOf(val)
+	if rv.IsValid() && rv.Type() != mapType && rv.Type().ConvertibleTo(mapType) {
+		return rv.Convert(mapType).Interface()
+	}
+
+	if rv.IsValid() && rv.Type() != multiMapType && rv.Type().ConvertibleTo(multiMapType) {
+		return rv.Convert(multiMapType).Interface()
+	}
+
+	return val
+}
+
+// Format returns the string representation of a value, as used by string concatenation.
+func Format(val interface{}) string {
+	switch v := normalize(val).(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	valBytes, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+
+	return string(valBytes)
+}
+
+func describe(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	}
+
+	return strings.TrimPrefix(fmt.Sprintf("%T", val), "*")
+}
@@ -0,0 +1,119 @@
+package expr_test
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/expr"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]interface{}{
+		"firstName": "John",
+		"lastName":  "Doe",
+		"qty":       float64(3),
+		"price":     2.5,
+		"count":     2,
+		"tags":      []interface{}{"a", "b"},
+		"address":   map[string]interface{}{"city": "Athens"},
+		"header":    http.Header{"X-Feature": {"beta"}},
+	}
+
+	testCases := []struct {
+		name     string
+		src      string
+		expected interface{}
+		err      error
+	}{
+		{name: "String concatenation", src: `firstName + " " + lastName`, expected: "John Doe"},
+		{name: "Arithmetic", src: `qty * price + count`, expected: 9.5},
+		{name: "Precedence", src: `1 + 2 * 3 - (4 - 2) / 2`, expected: float64(6)},
+		{name: "Concatenate number", src: `"qty: " + qty`, expected: "qty: 3"},
+		{name: "Comparison", src: `qty > 2 && price <= 2.5`, expected: true},
+		{name: "Equality", src: `firstName == 'John' || missing.field == 1`, expected: true},
+		{name: "Negation", src: `!(qty == 3)`, expected: false},
+		{name: "Conditional", src: `qty > 5 ? "many" : "few"`, expected: "few"},
+		{name: "Member access", src: `address.city`, expected: "Athens"},
+		{name: "Index access", src: `tags[1] + header["X-Feature"]`, expected: "bbeta"},
+		{name: "Missing field", src: `address.zip`, expected: nil},
+		{name: "Functions", src: `upper(firstName) + len(tags) + contains(tags, "a")`, expected: "JOHN2true"},
+		{name: "Has function", src: `has(address.zip)`, expected: false},
+		{name: "Type mismatch", src: `firstName * 2`, err: expr.ErrEval},
+		{name: "Division by zero", src: `qty / 0`, err: expr.ErrEval},
+	}
+
+	for _, tt := range testCases {
+		e, err := expr.Parse(tt.src)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		got, err := e.Eval(vars)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("%s: expected %v, but got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	testCases := []string{
+		`firstName +`,
+		`(qty * price`,
+		`"unterminated`,
+		`qty # price`,
+		`unknown(qty)`,
+		`qty ? 1`,
+		`address.`,
+		`qty price`,
+	}
+
+	for _, src := range testCases {
+		if _, err := expr.Parse(src); !errors.Is(err, expr.ErrSyntax) {
+			t.Fatalf("expected error %v for %q, but got %v", expr.ErrSyntax, src, err)
+		}
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	vars := map[string]interface{}{
+		"auth": map[string]interface{}{"sub": "42"},
+		"qty":  float64(3),
+	}
+
+	testCases := []struct {
+		src      string
+		expected interface{}
+	}{
+		{src: `draft`, expected: "draft"},
+		{src: `{{ auth.sub }}`, expected: "42"},
+		{src: `{{qty * 2}}`, expected: float64(6)},
+		{src: `user-{{ auth.sub }} ({{ qty }})`, expected: "user-42 (3)"},
+		{src: `{{ auth.missing }}`, expected: nil},
+	}
+
+	for _, tt := range testCases {
+		tmpl, err := expr.ParseTemplate(tt.src)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := tmpl.Eval(vars)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("expected %v for %q, but got %v", tt.expected, tt.src, got)
+		}
+	}
+
+	if _, err := expr.ParseTemplate(`{{ auth.sub `); !errors.Is(err, expr.ErrSyntax) {
+		t.Fatalf("expected error %v, but got %v", expr.ErrSyntax, err)
+	}
+}
@@ -0,0 +1,171 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// functions holds the built-in functions, by name.
+var functions = map[string]func(args []interface{}) (interface{}, error){
+	"len":        fnLen,
+	"upper":      stringFunc("upper", strings.ToUpper),
+	"lower":      stringFunc("lower", strings.ToLower),
+	"trim":       stringFunc("trim", strings.TrimSpace),
+	"string":     fnString,
+	"number":     fnNumber,
+	"round":      numberFunc("round", math.Round),
+	"floor":      numberFunc("floor", math.Floor),
+	"ceil":       numberFunc("ceil", math.Ceil),
+	"abs":        numberFunc("abs", math.Abs),
+	"contains":   fnContains,
+	"startsWith": stringPredicate("startsWith", strings.HasPrefix),
+	"endsWith":   stringPredicate("endsWith", strings.HasSuffix),
+	"has":        fnHas,
+}
+
+func checkArgs(name string, args []interface{}, count int) error {
+	if len(args) != count {
+		return fmt.Errorf("%w: %s expects %d arguments, but got %d", ErrEval, name, count, len(args))
+	}
+
+	return nil
+}
+
+func fnLen(args []interface{}) (interface{}, error) {
+	if err := checkArgs("len", args, 1); err != nil {
+		return nil, err
+	}
+
+	switch v := args[0].(type) {
+	case string:
+		return float64(len([]rune(v))), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	case nil:
+		return float64(0), nil
+	}
+
+	return nil, fmt.Errorf("%w: len of %v", ErrEval, describe(args[0]))
+}
+
+func fnString(args []interface{}) (interface{}, error) {
+	if err := checkArgs("string", args, 1); err != nil {
+		return nil, err
+	}
+
+	return Format(args[0]), nil
+}
+
+func fnNumber(args []interface{}) (interface{}, error) {
+	if err := checkArgs("number", args, 1); err != nil {
+		return nil, err
+	}
+
+	switch v := args[0].(type) {
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return float64(1), nil
+		}
+
+		return float64(0), nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: cannot convert %q to number", ErrEval, v)
+		}
+
+		return number, nil
+	}
+
+	return nil, fmt.Errorf("%w: cannot convert %v to number", ErrEval, describe(args[0]))
+}
+
+// fnContains reports whether a string contains a substring, or a list contains a value.
+func fnContains(args []interface{}) (interface{}, error) {
+	if err := checkArgs("contains", args, 2); err != nil {
+		return nil, err
+	}
+
+	switch v := args[0].(type) {
+	case string:
+		sub, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: contains of string expects a string", ErrEval)
+		}
+
+		return strings.Contains(v, sub), nil
+	case []interface{}:
+		for _, item := range v {
+			if equal(normalize(item), args[1]) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	case nil:
+		return false, nil
+	}
+
+	return nil, fmt.Errorf("%w: contains of %v", ErrEval, describe(args[0]))
+}
+
+// fnHas reports whether a value is not null, e.g. 'has(user.email)'.
+func fnHas(args []interface{}) (interface{}, error) {
+	if err := checkArgs("has", args, 1); err != nil {
+		return nil, err
+	}
+
+	return args[0] != nil, nil
+}
+
+func stringFunc(name string, fn func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s expects a string, but got %v", ErrEval, name, describe(args[0]))
+		}
+
+		return fn(s), nil
+	}
+}
+
+func stringPredicate(name string, fn func(string, string) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if err := checkArgs(name, args, 2); err != nil {
+			return nil, err
+		}
+
+		s, sok := args[0].(string)
+		sub, subok := args[1].(string)
+		if !sok || !subok {
+			return nil, fmt.Errorf("%w: %s expects strings", ErrEval, name)
+		}
+
+		return fn(s, sub), nil
+	}
+}
+
+func numberFunc(name string, fn func(float64) float64) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		if err := checkArgs(name, args, 1); err != nil {
+			return nil, err
+		}
+
+		number, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s expects a number, but got %v", ErrEval, name, describe(args[0]))
+		}
+
+		return fn(number), nil
+	}
+}
@@ -0,0 +1,126 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	// text is the operator or identifier, or the decoded string literal.
+	text   string
+	number float64
+	pos    int
+}
+
+// operators lists the supported operators, longest first so they are matched greedily.
+var operators = []string{
+	"==", "!=", "<=", ">=", "&&", "||",
+	"+", "-", "*", "/", "%", "<", ">", "!", "(", ")", "[", "]", ".", ",", "?", ":",
+}
+
+// tokenize splits the source into tokens.
+func tokenize(src string) ([]token, error) {
+	tokens := make([]token, 0)
+
+	for pos := 0; pos < len(src); {
+		c := rune(src[pos])
+
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+
+		case unicode.IsDigit(c):
+			end := pos
+			for end < len(src) && (unicode.IsDigit(rune(src[end])) || src[end] == '.') {
+				end++
+			}
+
+			number, err := strconv.ParseFloat(src[pos:end], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid number %q at %d", ErrSyntax, src[pos:end], pos)
+			}
+
+			tokens = append(tokens, token{kind: tokenNumber, number: number, pos: pos})
+			pos = end
+
+		case c == '"' || c == '\'':
+			text, end, err := readString(src, pos)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{kind: tokenString, text: text, pos: pos})
+			pos = end
+
+		case c == '_' || unicode.IsLetter(c):
+			end := pos
+			for end < len(src) && (src[end] == '_' || unicode.IsLetter(rune(src[end])) || unicode.IsDigit(rune(src[end]))) {
+				end++
+			}
+
+			tokens = append(tokens, token{kind: tokenIdent, text: src[pos:end], pos: pos})
+			pos = end
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[pos:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+					pos += len(op)
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				return nil, fmt.Errorf("%w: unexpected character %q at %d", ErrSyntax, c, pos)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// readString decodes the quoted string literal starting at pos, and returns the position after it.
+func readString(src string, pos int) (string, int, error) {
+	quote := src[pos]
+
+	var sb strings.Builder
+	for idx := pos + 1; idx < len(src); idx++ {
+		switch src[idx] {
+		case quote:
+			return sb.String(), idx + 1, nil
+		case '\\':
+			idx++
+			if idx == len(src) {
+				break
+			}
+
+			switch src[idx] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			default:
+				sb.WriteByte(src[idx])
+			}
+		default:
+			sb.WriteByte(src[idx])
+		}
+	}
+
+	return "", 0, fmt.Errorf("%w: unterminated string at %d", ErrSyntax, pos)
+}
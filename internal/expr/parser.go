@@ -0,0 +1,284 @@
+package expr
+
+import (
+	"fmt"
+)
+
+// node is an element of the parsed expression tree.
+type node interface {
+	eval(vars map[string]interface{}) (interface{}, error)
+}
+
+type (
+	literalNode struct {
+		value interface{}
+	}
+
+	identNode struct {
+		name string
+	}
+
+	memberNode struct {
+		target node
+		name   string
+	}
+
+	indexNode struct {
+		target node
+		index  node
+	}
+
+	callNode struct {
+		name string
+		args []node
+	}
+
+	unaryNode struct {
+		op      string
+		operand node
+	}
+
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+
+	conditionalNode struct {
+		cond, then, otherwise node
+	}
+)
+
+// parser is a recursive descent parser. Each method parses one precedence level.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+
+	return t
+}
+
+// accept consumes the next token if it is one of the operators.
+func (p *parser) accept(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op, true
+		}
+	}
+
+	return "", false
+}
+
+func (p *parser) expect(op string) error {
+	if _, ok := p.accept(op); !ok {
+		return p.unexpected()
+	}
+
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of expression", ErrSyntax)
+	}
+
+	return fmt.Errorf("%w: unexpected token at %d", ErrSyntax, t.pos)
+}
+
+func (p *parser) parseConditional() (node, error) {
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := p.accept("?"); !ok {
+		return cond, nil
+	}
+
+	then, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	otherwise, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+
+	return &conditionalNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+// binaryLevels lists the binary operators by increasing precedence.
+var binaryLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) parseBinary(level int) (node, error) {
+	if level == len(binaryLevels) {
+		return p.parseUnary()
+	}
+
+	left, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.accept(binaryLevels[level]...)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := p.parseBinary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if _, ok := p.accept("."); ok {
+			t := p.next()
+			if t.kind != tokenIdent {
+				return nil, fmt.Errorf("%w: expected field name at %d", ErrSyntax, t.pos)
+			}
+
+			n = &memberNode{target: n, name: t.text}
+			continue
+		}
+
+		if _, ok := p.accept("["); ok {
+			index, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+
+			if err = p.expect("]"); err != nil {
+				return nil, err
+			}
+
+			n = &indexNode{target: n, index: index}
+			continue
+		}
+
+		return n, nil
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		return &literalNode{value: t.number}, nil
+
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null":
+			return &literalNode{value: nil}, nil
+		}
+
+		if _, ok := p.accept("("); !ok {
+			return &identNode{name: t.text}, nil
+		}
+
+		if _, ok := functions[t.text]; !ok {
+			return nil, fmt.Errorf("%w: unknown function %q", ErrSyntax, t.text)
+		}
+
+		args := make([]node, 0)
+		if _, ok := p.accept(")"); ok {
+			return &callNode{name: t.text, args: args}, nil
+		}
+
+		for {
+			arg, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+
+			args = append(args, arg)
+
+			if _, ok := p.accept(")"); ok {
+				return &callNode{name: t.text, args: args}, nil
+			}
+
+			if err = p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+	case tokenOperator:
+		if t.text == "(" {
+			n, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+
+			if err = p.expect(")"); err != nil {
+				return nil, err
+			}
+
+			return n, nil
+		}
+	}
+
+	if t.kind != tokenEOF {
+		p.pos--
+	}
+
+	return nil, p.unexpected()
+}
@@ -0,0 +1,67 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Template is a string with embedded expressions, e.g. 'Hello {{ user.name }}'. It is safe for
+// concurrent use.
+type Template struct {
+	// parts alternate between literal text and expressions, starting with text.
+	texts []string
+	exprs []*Expr
+}
+
+// ParseTemplate parses a string with expressions enclosed in '{{' and '}}'.
+func ParseTemplate(src string) (*Template, error) {
+	t := &Template{}
+
+	rest := src
+	for {
+		start := strings.Index(rest, "{{")
+		if start < 0 {
+			t.texts = append(t.texts, rest)
+			return t, nil
+		}
+
+		end := strings.Index(rest[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unterminated '{{' in template", ErrSyntax)
+		}
+
+		e, err := Parse(rest[start+2 : start+end])
+		if err != nil {
+			return nil, err
+		}
+
+		t.texts = append(t.texts, rest[:start])
+		t.exprs = append(t.exprs, e)
+
+		rest = rest[start+end+2:]
+	}
+}
+
+// Eval evaluates the template. A template consisting of a single expression results in the value of
+// the expression, keeping its type, while other templates result in a string.
+func (t *Template) Eval(vars map[string]interface{}) (interface{}, error) {
+	if len(t.exprs) == 1 && t.texts[0] == "" && t.texts[1] == "" {
+		return t.exprs[0].Eval(vars)
+	}
+
+	var sb strings.Builder
+	for idx, text := range t.texts {
+		sb.WriteString(text)
+
+		if idx < len(t.exprs) {
+			val, err := t.exprs[idx].Eval(vars)
+			if err != nil {
+				return nil, err
+			}
+
+			sb.WriteString(Format(val))
+		}
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,41 @@
+package faker
+
+var firstNames = []string{
+	"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth",
+	"David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+	"Daniel", "Nancy", "Matthew", "Lisa", "Anthony", "Betty", "Mark", "Sandra", "Steven", "Ashley",
+	"Andrew", "Emily", "Joshua", "Olivia", "Kevin", "Emma", "Brian", "Sophia", "George", "Chloe",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+	"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+	"Lee", "Perez", "Thompson", "White", "Harris", "Sanchez", "Clark", "Ramirez", "Lewis", "Robinson",
+	"Walker", "Young", "Allen", "King", "Wright", "Scott", "Torres", "Nguyen", "Hill", "Flores",
+}
+
+var domains = []string{"example.com", "example.org", "example.net", "mail.test", "inbox.test"}
+
+var tlds = []string{"com", "org", "net", "io", "dev"}
+
+var companySuffixes = []string{"Inc", "LLC", "Group", "Ltd", "and Sons", "Holdings", "Labs", "Partners"}
+
+var streetSuffixes = []string{"Street", "Avenue", "Road", "Lane", "Boulevard", "Drive", "Court", "Way"}
+
+var cities = []string{
+	"New York", "London", "Paris", "Berlin", "Madrid", "Rome", "Athens", "Stockholm", "Amsterdam", "Vienna",
+	"Tokyo", "Sydney", "Toronto", "Chicago", "Lisbon", "Dublin", "Prague", "Oslo", "Helsinki", "Copenhagen",
+}
+
+var countries = []string{
+	"United States", "United Kingdom", "France", "Germany", "Spain", "Italy", "Greece", "Sweden", "Netherlands",
+	"Austria", "Japan", "Australia", "Canada", "Portugal", "Ireland", "Czech Republic", "Norway", "Finland",
+	"Denmark", "Brazil",
+}
+
+var words = []string{
+	"lorem", "ipsum", "dolor", "sit", "amet", "consectetur", "adipiscing", "elit", "sed", "do",
+	"eiusmod", "tempor", "incididunt", "ut", "labore", "et", "dolore", "magna", "aliqua", "enim",
+	"ad", "minim", "veniam", "quis", "nostrud", "exercitation", "ullamco", "laboris", "nisi", "aliquip",
+	"ex", "ea", "commodo", "consequat", "duis", "aute", "irure", "in", "reprehenderit", "voluptate",
+}
@@ -0,0 +1,234 @@
+// Package faker generates random, realistic looking values, like names, emails and addresses.
+// Generated values are reproducible when using the same seed.
+package faker
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKind returns an error when the requested kind of value is not supported.
+var ErrUnknownKind = errors.New("unknown kind of value")
+
+// Faker generates random values. It is safe for concurrent use.
+type Faker struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// New returns a new faker instance, seeded with the provided seed.
+func New(seed int64) *Faker {
+	return &Faker{r: rand.New(rand.NewSource(seed))}
+}
+
+// generators maps each supported kind of value to its generator.
+var generators = map[string]func(f *Faker) interface{}{
+	"name":      func(f *Faker) interface{} { return f.Name() },
+	"firstName": func(f *Faker) interface{} { return f.FirstName() },
+	"lastName":  func(f *Faker) interface{} { return f.LastName() },
+	"username":  func(f *Faker) interface{} { return f.Username() },
+	"email":     func(f *Faker) interface{} { return f.Email() },
+	"phone":     func(f *Faker) interface{} { return f.Phone() },
+	"uuid":      func(f *Faker) interface{} { return f.UUID() },
+	"company":   func(f *Faker) interface{} { return f.Company() },
+	"street":    func(f *Faker) interface{} { return f.Street() },
+	"city":      func(f *Faker) interface{} { return f.City() },
+	"country":   func(f *Faker) interface{} { return f.Country() },
+	"zipCode":   func(f *Faker) interface{} { return f.ZipCode() },
+	"address":   func(f *Faker) interface{} { return f.Address() },
+	"url":       func(f *Faker) interface{} { return f.URL() },
+	"ip":        func(f *Faker) interface{} { return f.IP() },
+	"color":     func(f *Faker) interface{} { return f.Color() },
+	"word":      func(f *Faker) interface{} { return f.Word() },
+	"sentence":  func(f *Faker) interface{} { return f.Sentence() },
+	"paragraph": func(f *Faker) interface{} { return f.Paragraph() },
+	"number":    func(f *Faker) interface{} { return f.Number(0, 1000) },
+	"boolean":   func(f *Faker) interface{} { return f.Bool() },
+	"date":      func(f *Faker) interface{} { return f.Date().Format(time.RFC3339) },
+}
+
+// Kinds returns the sorted list of supported kinds of values.
+func Kinds() []string {
+	kinds := make([]string, 0, len(generators))
+	for kind := range generators {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// Generate returns a random value of the requested kind.
+func (f *Faker) Generate(kind string) (interface{}, error) {
+	generator, ok := generators[kind]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKind, kind)
+	}
+
+	return generator(f), nil
+}
+
+// Name returns a random full name.
+func (f *Faker) Name() string {
+	return f.FirstName() + " " + f.LastName()
+}
+
+// FirstName returns a random first name.
+func (f *Faker) FirstName() string {
+	return f.pick(firstNames)
+}
+
+// LastName returns a random last name.
+func (f *Faker) LastName() string {
+	return f.pick(lastNames)
+}
+
+// Username returns a random username.
+func (f *Faker) Username() string {
+	return strings.ToLower(f.FirstName()) + fmt.Sprintf("%d", f.intn(100))
+}
+
+// Email returns a random email address.
+func (f *Faker) Email() string {
+	return fmt.Sprintf("%s.%s@%s", strings.ToLower(f.FirstName()), strings.ToLower(f.LastName()), f.pick(domains))
+}
+
+// Phone returns a random phone number.
+func (f *Faker) Phone() string {
+	return fmt.Sprintf("+1-%03d-%03d-%04d", 200+f.intn(800), f.intn(1000), f.intn(10000))
+}
+
+// UUID returns a random version 4 UUID.
+func (f *Faker) UUID() string {
+	b := make([]byte, 16)
+
+	f.mu.Lock()
+	// nolint
+	f.r.Read(b)
+	f.mu.Unlock()
+
+	// Set the version and variant bits.
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// Company returns a random company name.
+func (f *Faker) Company() string {
+	return f.LastName() + " " + f.pick(companySuffixes)
+}
+
+// Street returns a random street address.
+func (f *Faker) Street() string {
+	return fmt.Sprintf("%d %s %s", 1+f.intn(9999), f.LastName(), f.pick(streetSuffixes))
+}
+
+// City returns a random city.
+func (f *Faker) City() string {
+	return f.pick(cities)
+}
+
+// Country returns a random country.
+func (f *Faker) Country() string {
+	return f.pick(countries)
+}
+
+// ZipCode returns a random zip code.
+func (f *Faker) ZipCode() string {
+	return fmt.Sprintf("%05d", f.intn(100000))
+}
+
+// Address returns a random full address.
+func (f *Faker) Address() string {
+	return fmt.Sprintf("%s, %s %s, %s", f.Street(), f.City(), f.ZipCode(), f.Country())
+}
+
+// URL returns a random url.
+func (f *Faker) URL() string {
+	return fmt.Sprintf("https://www.%s.%s", strings.ToLower(f.LastName()), f.pick(tlds))
+}
+
+// IP returns a random IPv4 address.
+func (f *Faker) IP() string {
+	return fmt.Sprintf("%d.%d.%d.%d", 1+f.intn(223), f.intn(256), f.intn(256), 1+f.intn(254))
+}
+
+// Color returns a random hex color.
+func (f *Faker) Color() string {
+	return fmt.Sprintf("#%06x", f.intn(1<<24))
+}
+
+// Word returns a random word.
+func (f *Faker) Word() string {
+	return f.pick(words)
+}
+
+// Sentence returns a random sentence.
+func (f *Faker) Sentence() string {
+	count := 4 + f.intn(8)
+
+	list := make([]string, 0, count)
+	for idx := 0; idx < count; idx++ {
+		list = append(list, f.Word())
+	}
+
+	sentence := strings.Join(list, " ")
+
+	return strings.ToUpper(sentence[:1]) + sentence[1:] + "."
+}
+
+// Paragraph returns a random paragraph.
+func (f *Faker) Paragraph() string {
+	count := 3 + f.intn(4)
+
+	list := make([]string, 0, count)
+	for idx := 0; idx < count; idx++ {
+		list = append(list, f.Sentence())
+	}
+
+	return strings.Join(list, " ")
+}
+
+// Number returns a random integer in [min, max].
+func (f *Faker) Number(min, max int) int {
+	if max <= min {
+		return min
+	}
+
+	return min + f.intn(max-min+1)
+}
+
+// Bool returns a random boolean.
+func (f *Faker) Bool() bool {
+	return f.intn(2) == 1
+}
+
+// Date returns a random date within the ten years before 2020-01-01.
+func (f *Faker) Date() time.Time {
+	end := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(-10, 0, 0)
+
+	f.mu.Lock()
+	offset := f.r.Int63n(int64(end.Sub(start) / time.Second))
+	f.mu.Unlock()
+
+	return start.Add(time.Duration(offset) * time.Second)
+}
+
+func (f *Faker) intn(n int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.r.Intn(n)
+}
+
+func (f *Faker) pick(list []string) string {
+	return list[f.intn(len(list))]
+}
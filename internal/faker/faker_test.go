@@ -0,0 +1,69 @@
+package faker_test
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/faker"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, kind := range faker.Kinds() {
+		first, err := faker.New(1).Generate(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		second, err := faker.New(1).Generate(kind)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(first, second) {
+			t.Fatalf("%s: expected same value %v for the same seed, but got %v", kind, first, second)
+		}
+	}
+
+	if _, err := faker.New(1).Generate("randomKind"); !errors.Is(err, faker.ErrUnknownKind) {
+		t.Fatalf("expected error %v, but got %v", faker.ErrUnknownKind, err)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	f := faker.New(1)
+
+	testCases := []struct {
+		name    string
+		value   string
+		pattern string
+	}{
+		{
+			name:    "Email",
+			value:   f.Email(),
+			pattern: `^[a-z]+\.[a-z]+@[a-z.]+$`,
+		},
+		{
+			name:    "UUID",
+			value:   f.UUID(),
+			pattern: `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		},
+		{
+			name:    "Color",
+			value:   f.Color(),
+			pattern: `^#[0-9a-f]{6}$`,
+		},
+		{
+			name:    "IP",
+			value:   f.IP(),
+			pattern: `^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}$`,
+		},
+	}
+
+	for _, tt := range testCases {
+		if !regexp.MustCompile(tt.pattern).MatchString(tt.value) {
+			t.Fatalf("%s: expected value to match %v, but got %v", tt.name, tt.pattern, tt.value)
+		}
+	}
+}
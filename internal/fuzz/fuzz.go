@@ -0,0 +1,500 @@
+// Package fuzz sends malformed and unexpected requests at a running JSON server
+// and reports any crashes or server errors it provokes.
+package fuzz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoResources returns an error when the target server exposes no resources.
+	ErrNoResources = errors.New("target server has no resources")
+	// ErrServerUnreachable returns an error when the target server stops responding.
+	ErrServerUnreachable = errors.New("target server unreachable")
+	// ErrRestoreFailed returns an error when the data of the target server could not be restored after a run.
+	ErrRestoreFailed = errors.New("failed to restore target server data")
+)
+
+// Config holds the settings of a fuzzing run.
+type Config struct {
+	Target     string
+	Iterations int
+	Seed       int64
+	// MaxPayload is the size in bytes of the generated oversized payloads.
+	MaxPayload int
+	Client     *http.Client
+}
+
+// Case is a single generated request.
+type Case struct {
+	Kind        string
+	Method      string
+	Path        string
+	ContentType string
+	Body        []byte
+}
+
+// Failure describes a request which crashed the server or produced a server error.
+type Failure struct {
+	Case
+	StatusCode int
+	Err        string
+}
+
+// Report contains the outcome of a fuzzing run.
+type Report struct {
+	Requests int
+	Failures []Failure
+	// Unreachable is set when the server stopped responding during the run.
+	Unreachable bool
+	// Restored is the number of resources created, changed or deleted by the run, which were restored.
+	Restored int
+}
+
+// generator produces a request for a random resource.
+type generator func(r *rand.Rand, key string, cfg Config) Case
+
+var generators = []generator{
+	malformedBody,
+	unexpectedBodyType,
+	weirdQuery,
+	oversizedPayload,
+	invalidID,
+	wrongContentType,
+}
+
+// Run executes a fuzzing run against the configured target and returns the collected report. Generated
+// writes might succeed, so the data of the target is recorded before the run and restored afterwards.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: time.Second * 30}
+	}
+
+	target := strings.TrimSuffix(cfg.Target, "/")
+
+	keys, err := discover(ctx, cfg.Client, target)
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := snapshot(ctx, cfg.Client, target)
+	if err != nil {
+		return nil, err
+	}
+
+	r := rand.New(rand.NewSource(cfg.Seed))
+	report := &Report{Failures: make([]Failure, 0)}
+	written := make(map[string]map[string]bool)
+
+	for idx := 0; idx < cfg.Iterations && ctx.Err() == nil; idx++ {
+		key := keys[r.Intn(len(keys))]
+		c := generators[r.Intn(len(generators))](r, key, cfg)
+
+		report.Requests++
+
+		statusCode, body, err := send(ctx, cfg.Client, target, c)
+		if err == nil && c.Method != http.MethodGet && statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices {
+			for _, id := range writtenIds(key, c, body) {
+				if written[key] == nil {
+					written[key] = make(map[string]bool)
+				}
+
+				written[key][id] = true
+			}
+		}
+
+		if err == nil && statusCode < http.StatusInternalServerError {
+			continue
+		}
+
+		failure := Failure{Case: c, StatusCode: statusCode}
+		if err != nil {
+			failure.Err = err.Error()
+		}
+
+		report.Failures = append(report.Failures, failure)
+
+		// A transport error might indicate a crash, so stop if the server is gone.
+		if err != nil {
+			if _, err = discover(ctx, cfg.Client, target); err != nil {
+				report.Unreachable = true
+				break
+			}
+		}
+	}
+
+	if report.Unreachable {
+		return report, nil
+	}
+
+	// Restore even when the run was cancelled.
+	if report.Restored, err = restore(context.Background(), cfg.Client, target, before, written); err != nil {
+		return report, fmt.Errorf("%w: %v", ErrRestoreFailed, err)
+	}
+
+	return report, nil
+}
+
+// send issues a generated request and returns the response status code and body.
+func send(ctx context.Context, client *http.Client, target string, c Case) (int, []byte, error) {
+	var body io.Reader
+	if c.Body != nil {
+		body = bytes.NewReader(c.Body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, c.Method, target+c.Path, body)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if c.ContentType != "" {
+		req.Header.Set("Content-Type", c.ContentType)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+
+	return resp.StatusCode, respBody, nil
+}
+
+// writtenIds returns the ids of the resources written by a successful request, either from the path, or
+// from the response of a create request, which holds all the created resources for array bodies.
+func writtenIds(key string, c Case, respBody []byte) []string {
+	if c.Method != http.MethodPost {
+		id, err := url.PathUnescape(strings.TrimPrefix(c.Path, "/"+key+"/"))
+		if err != nil {
+			return nil
+		}
+
+		return []string{id}
+	}
+
+	type createdResource struct {
+		ID interface{} `json:"id"`
+	}
+
+	var created []createdResource
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		var single createdResource
+		if err = json.Unmarshal(respBody, &single); err != nil {
+			return nil
+		}
+
+		created = []createdResource{single}
+	}
+
+	ids := make([]string, 0, len(created))
+	for _, resource := range created {
+		if resource.ID != nil {
+			ids = append(ids, fmt.Sprint(resource.ID))
+		}
+	}
+
+	return ids
+}
+
+// discover retrieves the available resource keys of the target server from the '/db' endpoint.
+func discover(ctx context.Context, client *http.Client, target string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target+"/db", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrServerUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	content := make(map[string]json.RawMessage)
+	if err = json.NewDecoder(resp.Body).Decode(&content); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0)
+	for key := range content {
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, ErrNoResources
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// snapshot retrieves the resources of the target server from the '/db' endpoint, by resource key and id.
+func snapshot(ctx context.Context, client *http.Client, target string) (map[string]map[string]map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target+"/db", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrServerUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	content := make(map[string][]map[string]interface{})
+	if err = json.NewDecoder(resp.Body).Decode(&content); err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]map[string]map[string]interface{}, len(content))
+	for key, resources := range content {
+		data[key] = make(map[string]map[string]interface{}, len(resources))
+		for _, resource := range resources {
+			data[key][fmt.Sprint(resource["id"])] = resource
+		}
+	}
+
+	return data, nil
+}
+
+// restore reverts the resources written by the run to the snapshot, by replacing or recreating the existing
+// ones, and deleting the created ones. It returns the number of restored resources.
+func restore(ctx context.Context, client *http.Client, target string, before map[string]map[string]map[string]interface{}, written map[string]map[string]bool) (int, error) {
+	restored := 0
+	for key, ids := range written {
+		for id := range ids {
+			path := fmt.Sprintf("/%s/%s", key, url.PathEscape(id))
+
+			original, ok := before[key][id]
+			if !ok {
+				statusCode, _, err := send(ctx, client, target, Case{Method: http.MethodDelete, Path: path})
+				if err != nil {
+					return restored, err
+				}
+
+				// Created resources might have been deleted by the run as well.
+				if statusCode >= http.StatusBadRequest && statusCode != http.StatusNotFound {
+					return restored, fmt.Errorf("DELETE %s responded with %d", path, statusCode)
+				}
+
+				restored++
+				continue
+			}
+
+			body, err := json.Marshal(original)
+			if err != nil {
+				return restored, err
+			}
+
+			statusCode, _, err := send(ctx, client, target, Case{Method: http.MethodPut, Path: path, ContentType: "application/json", Body: body})
+			if err != nil {
+				return restored, err
+			}
+
+			// Recreate resources deleted by the run.
+			if statusCode == http.StatusNotFound {
+				c := Case{Method: http.MethodPost, Path: "/" + key, ContentType: "application/json", Body: body}
+				if statusCode, _, err = send(ctx, client, target, c); err != nil {
+					return restored, err
+				}
+			}
+
+			if statusCode >= http.StatusBadRequest {
+				return restored, fmt.Errorf("restoring %s responded with %d", path, statusCode)
+			}
+
+			restored++
+		}
+	}
+
+	return restored, nil
+}
+
+// writeMethods are the methods accepting a request body.
+var writeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch}
+
+// randomWrite returns a random write method and a matching path for the resource.
+func randomWrite(r *rand.Rand, key string) (method, path string) {
+	method = writeMethods[r.Intn(len(writeMethods))]
+	if method == http.MethodPost {
+		return method, "/" + key
+	}
+
+	return method, fmt.Sprintf("/%s/%d", key, r.Intn(10))
+}
+
+func malformedBody(r *rand.Rand, key string, _ Config) Case {
+	bodies := []string{
+		`{`,
+		`{"field": }`,
+		`{"field": "value",}`,
+		`{"field": "\xff\xfe"}`,
+		`{"a":1}{"b":2}`,
+		`{"field": "value"`,
+		strings.Repeat("[", 10000) + strings.Repeat("]", 10000),
+		strings.Repeat(`{"a":`, 10000) + "1" + strings.Repeat("}", 10000),
+		"\x00\x01\x02",
+	}
+
+	method, path := randomWrite(r, key)
+
+	return Case{
+		Kind:        "malformed body",
+		Method:      method,
+		Path:        path,
+		ContentType: "application/json",
+		Body:        []byte(bodies[r.Intn(len(bodies))]),
+	}
+}
+
+func unexpectedBodyType(r *rand.Rand, key string, _ Config) Case {
+	bodies := []string{
+		`null`,
+		`[]`,
+		`[{"field": "value"}]`,
+		`"string"`,
+		`12345`,
+		`true`,
+		`{"id": {"nested": true}, "field": "value"}`,
+		`{"id": null, "field": "value"}`,
+		`{"id": 1.5e308, "field": "value"}`,
+		`{"id": [], "field": "value"}`,
+	}
+
+	body := bodies[r.Intn(len(bodies))]
+
+	// Resources created with such ids cannot be addressed anymore, and so not restored, while on replace
+	// and update the id of the path is kept.
+	method, path := randomWrite(r, key)
+	for method == http.MethodPost && strings.Contains(body, `"id"`) {
+		method, path = randomWrite(r, key)
+	}
+
+	return Case{
+		Kind:        "unexpected body type",
+		Method:      method,
+		Path:        path,
+		ContentType: "application/json",
+		Body:        []byte(body),
+	}
+}
+
+func weirdQuery(r *rand.Rand, key string, _ Config) Case {
+	queries := []string{
+		"_page=-1",
+		"_limit=abc",
+		"_sort=&_order=sideways",
+		"%zz=%zz",
+		"q=" + url.QueryEscape(strings.Repeat("x", 8192)),
+		"a[]=1&a[]=2&a[b]=3",
+		"field=%00",
+		"field_gte=" + url.QueryEscape("‮\u0000"),
+		strings.Repeat("&", 1000),
+		"_cursor=" + url.QueryEscape("not-a-cursor"),
+	}
+
+	return Case{
+		Kind:   "weird query",
+		Method: http.MethodGet,
+		Path:   fmt.Sprintf("/%s?%s", key, queries[r.Intn(len(queries))]),
+	}
+}
+
+func oversizedPayload(r *rand.Rand, key string, cfg Config) Case {
+	size := cfg.MaxPayload
+	if size <= 0 {
+		size = 1 << 20
+	}
+
+	method, path := randomWrite(r, key)
+
+	var body []byte
+	if r.Intn(2) == 0 {
+		// A single huge string field.
+		body = []byte(`{"field": "` + strings.Repeat("x", size) + `"}`)
+	} else {
+		// A huge number of fields.
+		var buf bytes.Buffer
+		buf.WriteString("{")
+		for idx := 0; buf.Len() < size; idx++ {
+			if idx > 0 {
+				buf.WriteString(",")
+			}
+
+			fmt.Fprintf(&buf, `"field_%d": %d`, idx, idx)
+		}
+		buf.WriteString("}")
+
+		body = buf.Bytes()
+	}
+
+	return Case{
+		Kind:        "oversized payload",
+		Method:      method,
+		Path:        path,
+		ContentType: "application/json",
+		Body:        body,
+	}
+}
+
+func invalidID(r *rand.Rand, key string, _ Config) Case {
+	ids := []string{
+		"..%2F..%2Fetc%2Fpasswd",
+		"%00",
+		"-1",
+		"9999999999999999999999999",
+		url.PathEscape(strings.Repeat("a", 4096)),
+		url.PathEscape("🚀"),
+		"%20",
+		"null",
+		url.PathEscape(`{"id":1}`),
+	}
+
+	methods := []string{http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	method := methods[r.Intn(len(methods))]
+
+	c := Case{
+		Kind:   "invalid id",
+		Method: method,
+		Path:   fmt.Sprintf("/%s/%s", key, ids[r.Intn(len(ids))]),
+	}
+
+	if method == http.MethodPut || method == http.MethodPatch {
+		c.ContentType = "application/json"
+		c.Body = []byte(`{"field": "value"}`)
+	}
+
+	return c
+}
+
+func wrongContentType(r *rand.Rand, key string, _ Config) Case {
+	contentTypes := []string{"text/plain", "application/xml", "multipart/form-data; boundary=x", "", "application/json; charset=latin1"}
+	bodies := []string{`<field>value</field>`, `field=value`, `--x--`, `{"field": "value"}`}
+
+	method, path := randomWrite(r, key)
+
+	return Case{
+		Kind:        "wrong content type",
+		Method:      method,
+		Path:        path,
+		ContentType: contentTypes[r.Intn(len(contentTypes))],
+		Body:        []byte(bodies[r.Intn(len(bodies))]),
+	}
+}
@@ -0,0 +1,125 @@
+package fuzz_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/fuzz"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		failures   bool
+	}{
+		{
+			name:       "Fuzz healthy server",
+			statusCode: http.StatusBadRequest,
+			failures:   false,
+		},
+		{
+			name:       "Fuzz failing server",
+			statusCode: http.StatusInternalServerError,
+			failures:   true,
+		},
+	}
+
+	for _, tt := range testCases {
+		statusCode := tt.statusCode
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/db" {
+				w.Write([]byte(`{"resource_key_1": []}`))
+				return
+			}
+
+			w.WriteHeader(statusCode)
+		}))
+
+		report, err := fuzz.Run(context.Background(), fuzz.Config{
+			Target:     server.URL,
+			Iterations: 50,
+			Seed:       1,
+			MaxPayload: 1024,
+		})
+		server.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if report.Requests != 50 {
+			t.Fatalf("%s: expected %v requests, but got %v", tt.name, 50, report.Requests)
+		}
+
+		if got := len(report.Failures) > 0; got != tt.failures {
+			t.Fatalf("%s: expected failures %v, but got %v", tt.name, tt.failures, report.Failures)
+		}
+	}
+}
+
+func TestRun_Restore(t *testing.T) {
+	data := storage.Database{"posts": make([]storage.Resource, 0)}
+	for idx := 0; idx < 10; idx++ {
+		data["posts"] = append(data["posts"], storage.Resource{"id": fmt.Sprint(idx), "title": fmt.Sprintf("title-%d", idx)})
+	}
+
+	db := storage.NewMemoryDB(data)
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"posts", ""} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key == "" {
+			key = "db"
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage))
+	defer server.Close()
+
+	report, err := fuzz.Run(context.Background(), fuzz.Config{
+		Target:     server.URL,
+		Iterations: 200,
+		Seed:       1,
+		MaxPayload: 1024,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Restored == 0 {
+		t.Fatalf("expected restored resources, but got %v", report.Restored)
+	}
+
+	got, err := resourceStorage["posts"].Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byId := make(map[string]storage.Resource)
+	for _, resource := range got {
+		byId[fmt.Sprint(resource["id"])] = resource
+	}
+
+	expected := make(map[string]storage.Resource)
+	for _, resource := range data["posts"] {
+		expected[resource["id"].(string)] = resource
+	}
+
+	if !reflect.DeepEqual(byId, expected) {
+		t.Fatalf("expected resources %v, but got %v", expected, byId)
+	}
+}
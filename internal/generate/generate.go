@@ -0,0 +1,224 @@
+// Package generate builds the data of the resources from a template, filled with random values of the faker,
+// e.g. '{"users": {"_count": 100, "name": "{{name}}", "email": "{{email}}"}}' for a hundred users.
+package generate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/chanioxaris/json-server/internal/faker"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// countField is the field of a resource template holding the number of records to generate.
+const countField = "_count"
+
+var (
+	// ErrFailedReadTemplate returns an error when the template file cannot be read.
+	ErrFailedReadTemplate = errors.New("failed to read template file")
+	// ErrInvalidTemplate returns an error when the template is not valid.
+	ErrInvalidTemplate = errors.New("invalid template")
+
+	errInvalidResource = errors.New("expected an object with the fields of the records")
+	errInvalidCount    = errors.New("invalid _count, expected a non-negative integer")
+	errLiteralID       = errors.New("id must hold a placeholder, e.g. '{{uuid}}', to generate more than one record")
+)
+
+// placeholderPattern matches the placeholders of template strings, e.g. '{{email}}'.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z]+)\s*\}\}`)
+
+// Template describes the records of every resource.
+type Template struct {
+	resources map[string]resourceTemplate
+}
+
+// resourceTemplate describes the records of a resource.
+type resourceTemplate struct {
+	count  int
+	fields map[string]interface{}
+}
+
+// Read reads and parses the template file.
+func Read(file string) (*Template, error) {
+	contentBytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedReadTemplate, err)
+	}
+
+	return Parse(contentBytes)
+}
+
+// Parse parses a template, holding an object of fields per resource. Every resource gets the number of records
+// of its '_count' field, one if missing. String fields may contain placeholders of the kinds of the faker, e.g.
+// '{{name}}', and nested arrays and objects are filled as well. A string which is a single placeholder gets the
+// generated value as is, e.g. a number for '{{number}}'. Other values are copied. An id field must hold a
+// placeholder if more than one record is generated.
+func Parse(contentBytes []byte) (*Template, error) {
+	var resources map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(contentBytes))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&resources); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTemplate, err)
+	}
+
+	t := &Template{resources: make(map[string]resourceTemplate, len(resources))}
+	for resourceKey, val := range resources {
+		fields, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, resourceKey, errInvalidResource)
+		}
+
+		count := 1
+		if val, ok := fields[countField]; ok {
+			number, ok := val.(json.Number)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, resourceKey, errInvalidCount)
+			}
+
+			n, err := strconv.Atoi(number.String())
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, resourceKey, errInvalidCount)
+			}
+
+			count = n
+			delete(fields, countField)
+		}
+
+		if err := validate(fields); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, resourceKey, err)
+		}
+
+		// A literal id would be shared by every record.
+		if id, ok := fields["id"]; ok && count > 1 {
+			if str, ok := id.(string); !ok || !placeholderPattern.MatchString(str) {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, resourceKey, errLiteralID)
+			}
+		}
+
+		t.resources[resourceKey] = resourceTemplate{count: count, fields: fields}
+	}
+
+	return t, nil
+}
+
+// validate checks that the placeholders of the value are kinds of the faker.
+func validate(val interface{}) error {
+	switch v := val.(type) {
+	case string:
+		for _, match := range placeholderPattern.FindAllStringSubmatch(v, -1) {
+			if !knownKind(match[1]) {
+				return fmt.Errorf("%w: %s", faker.ErrUnknownKind, match[1])
+			}
+		}
+	case map[string]interface{}:
+		for _, field := range v {
+			if err := validate(field); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := validate(item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// knownKind reports whether the faker generates values of the kind.
+func knownKind(kind string) bool {
+	for _, known := range faker.Kinds() {
+		if known == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Generate returns the records of every resource, filled with the values of the faker. Records get sequential
+// ids, starting from 1, unless the template has an id field.
+func (t *Template) Generate(f *faker.Faker) storage.Database {
+	resourceKeys := make([]string, 0, len(t.resources))
+	for resourceKey := range t.resources {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	// Fill the resources in a stable order, so the same seed generates the same data.
+	sort.Strings(resourceKeys)
+
+	data := make(storage.Database, len(t.resources))
+	for _, resourceKey := range resourceKeys {
+		resourceTmpl := t.resources[resourceKey]
+
+		resources := make([]storage.Resource, 0, resourceTmpl.count)
+		for idx := 0; idx < resourceTmpl.count; idx++ {
+			resource := fill(resourceTmpl.fields, f).(map[string]interface{})
+			if _, ok := resource["id"]; !ok {
+				resource["id"] = strconv.Itoa(idx + 1)
+			}
+
+			resources = append(resources, resource)
+		}
+
+		data[resourceKey] = resources
+	}
+
+	return data
+}
+
+// fill returns a copy of the value, with the placeholders replaced by generated values.
+func fill(val interface{}, f *faker.Faker) interface{} {
+	switch v := val.(type) {
+	case string:
+		return fillString(v, f)
+	case map[string]interface{}:
+		// Fill the fields in a stable order, so the same seed generates the same data.
+		fields := make([]string, 0, len(v))
+		for field := range v {
+			fields = append(fields, field)
+		}
+
+		sort.Strings(fields)
+
+		object := make(map[string]interface{}, len(v))
+		for _, field := range fields {
+			object[field] = fill(v[field], f)
+		}
+
+		return object
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			items = append(items, fill(item, f))
+		}
+
+		return items
+	}
+
+	return val
+}
+
+// fillString replaces the placeholders of the string with generated values. A string which is a single
+// placeholder gets the generated value as is.
+func fillString(s string, f *faker.Faker) interface{} {
+	if match := placeholderPattern.FindStringSubmatchIndex(s); match != nil && match[0] == 0 && match[1] == len(s) {
+		val, _ := f.Generate(s[match[2]:match[3]])
+		return val
+	}
+
+	return placeholderPattern.ReplaceAllStringFunc(s, func(placeholder string) string {
+		val, _ := f.Generate(placeholderPattern.FindStringSubmatch(placeholder)[1])
+		return fmt.Sprint(val)
+	})
+}
@@ -0,0 +1,87 @@
+package generate_test
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/faker"
+	"github.com/chanioxaris/json-server/internal/generate"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "Parse template", content: `{"users": {"_count": 3, "name": "{{name}}", "tags": ["{{ word }}"]}}`},
+		{name: "Parse template without count", content: `{"profile": {"name": "{{name}}"}}`},
+		{name: "Parse invalid json", content: `{"users": `, err: generate.ErrInvalidTemplate},
+		{name: "Parse resource of records", content: `{"users": [{"name": "{{name}}"}]}`, err: generate.ErrInvalidTemplate},
+		{name: "Parse negative count", content: `{"users": {"_count": -1}}`, err: generate.ErrInvalidTemplate},
+		{name: "Parse decimal count", content: `{"users": {"_count": 1.5}}`, err: generate.ErrInvalidTemplate},
+		{name: "Parse unknown placeholder", content: `{"users": {"pet": "{{dog}}"}}`, err: generate.ErrInvalidTemplate},
+		{name: "Parse placeholder id", content: `{"users": {"_count": 3, "id": "{{uuid}}"}}`},
+		{name: "Parse literal id of single record", content: `{"profile": {"id": 1, "name": "{{name}}"}}`},
+		{name: "Parse literal id of many records", content: `{"users": {"_count": 3, "id": "1"}}`, err: generate.ErrInvalidTemplate},
+		{name: "Parse number id of many records", content: `{"users": {"_count": 3, "id": 1}}`, err: generate.ErrInvalidTemplate},
+	}
+
+	for _, tt := range testCases {
+		if _, err := generate.Parse([]byte(tt.content)); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	tmpl, err := generate.Parse([]byte(`{
+		"users": {
+			"_count": 5,
+			"name": "{{name}}",
+			"contact": {"email": "{{email}}", "city": "Lives in {{city}}"},
+			"age": "{{number}}",
+			"active": true
+		},
+		"settings": {"id": "main", "theme": "{{color}}"}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := tmpl.Generate(faker.New(1))
+
+	if !reflect.DeepEqual(data, tmpl.Generate(faker.New(1))) {
+		t.Fatalf("expected same data for the same seed")
+	}
+
+	if len(data["users"]) != 5 || len(data["settings"]) != 1 {
+		t.Fatalf("expected 5 users and 1 settings, but got %v", data)
+	}
+
+	for idx, user := range data["users"] {
+		if expected := strconv.Itoa(idx + 1); user["id"] != expected {
+			t.Fatalf("expected id %s, but got %v", expected, user["id"])
+		}
+
+		if _, ok := user["age"].(int); !ok {
+			t.Fatalf("expected number age, but got %T", user["age"])
+		}
+
+		if user["active"] != true {
+			t.Fatalf("expected active copied, but got %v", user["active"])
+		}
+
+		contact := user["contact"].(map[string]interface{})
+		if !regexp.MustCompile(`^Lives in [A-Z]`).MatchString(contact["city"].(string)) {
+			t.Fatalf("expected city filled, but got %v", contact["city"])
+		}
+	}
+
+	if data["settings"][0]["id"] != "main" {
+		t.Fatalf("expected id of the template, but got %v", data["settings"][0]["id"])
+	}
+}
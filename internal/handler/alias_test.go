@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestAliases(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"users": {"aliases": ["people", "posts"], "readOnly": true}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {{"id": "1"}},
+		"posts": {{"id": "2"}},
+	})
+
+	usersSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postsSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(
+		map[string]storage.Storage{"users": usersSvc, "posts": postsSvc},
+		handler.WithConfig(cfg),
+		handler.WithIgnoreCase(),
+	))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		statusCode int
+	}{
+		{name: "List by alias", method: http.MethodGet, path: "/people", statusCode: http.StatusOK},
+		{name: "Read by alias", method: http.MethodGet, path: "/people/1", statusCode: http.StatusOK},
+		{name: "Read by alias ignoring case", method: http.MethodGet, path: "/People/1", statusCode: http.StatusOK},
+		{name: "Apply the rules of the resource", method: http.MethodDelete, path: "/people/1", statusCode: http.StatusMethodNotAllowed},
+		{name: "Keep resource named like alias", method: http.MethodGet, path: "/posts/2", statusCode: http.StatusOK},
+		{name: "Keep prefixed resource names", method: http.MethodGet, path: "/peoples", statusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, &bytes.Buffer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, res.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler/common"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+const (
+	emailField    = "email"
+	passwordField = "password"
+	roleField     = "role"
+	rolesField    = "roles"
+)
+
+var (
+	errMissingCredentials = errors.New("email and password are required")
+	errInvalidCredentials = errors.New("invalid email or password")
+	errEmailTaken         = errors.New("email already exists")
+	errUnauthorized       = errors.New("unauthorized")
+	errForbidden          = errors.New("forbidden")
+)
+
+type authResponse struct {
+	AccessToken string           `json:"accessToken"`
+	User        storage.Resource `json:"user"`
+}
+
+// Login operates as a http handler, to authenticate a user of the users resource by email and password,
+// and return a signed access token. The private fields are omitted from the returned user.
+func Login(usersSvc storage.Storage, tokens *auth.Tokens, privateFields ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		email, password, err := decodeCredentials(r)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		users, err := usersSvc.Find()
+		if err != nil {
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		for _, user := range users {
+			if user[emailField] != email {
+				continue
+			}
+
+			hash, _ := user[passwordField].(string)
+			if !checkPassword(hash, password) {
+				break
+			}
+
+			respondToken(w, http.StatusOK, user, tokens, privateFields)
+			return
+		}
+
+		web.Error(w, http.StatusBadRequest, errInvalidCredentials.Error())
+	}
+}
+
+// Register operates as a http handler, to add a new user to the users resource and return a signed
+// access token. The password is stored as a bcrypt hash. Roles are assigned by the server only, so
+// roles of the request are dropped. The private fields are omitted from the returned user.
+func Register(usersSvc storage.Storage, tokens *auth.Tokens, privateFields ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		newUser, err := decodeResource(r)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+			return
+		}
+
+		email, _ := newUser[emailField].(string)
+		password, _ := newUser[passwordField].(string)
+		if email == "" || password == "" {
+			web.Error(w, http.StatusBadRequest, errMissingCredentials.Error())
+			return
+		}
+
+		users, err := usersSvc.Find()
+		if err != nil {
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		for _, user := range users {
+			if user[emailField] == email {
+				web.Error(w, http.StatusConflict, errEmailTaken.Error())
+				return
+			}
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		newUser[passwordField] = string(hash)
+		delete(newUser, roleField)
+		delete(newUser, rolesField)
+
+		user, err := usersSvc.Create(newUser)
+		if err != nil {
+			if errors.Is(err, storage.ErrResourceAlreadyExists) {
+				web.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		respondToken(w, http.StatusCreated, user, tokens, privateFields)
+	}
+}
+
+// requireAuth rejects requests to the protected resources without an access token, which is valid for any
+// of the provided tokens instances. Resources with access rules require the token to grant one of the roles
+// of the rule instead. The claims of valid tokens are added to the request context.
+func requireAuth(verifiers []*auth.Tokens, protected map[string]bool, rules map[string]config.Access) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rule := resourceRule(routeResourceKey(r), r.Method, protected, rules)
+			if rule == "" || rule == config.RulePublic {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, err := verifyToken(r, verifiers)
+			if err != nil {
+				web.Error(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			if roles := config.Roles(rule); roles != nil && !grantsAnyRole(claims, roles) {
+				web.Error(w, http.StatusForbidden, errForbidden.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithClaims(r.Context(), claims)))
+		})
+	}
+}
+
+// authorizedDB operates as a http handler, to list db content, except for the hidden resources and the
+// resources the request is not allowed to read, following the same rules as the resource routes.
+func authorizedDB(storageSvc storage.Storage, hidden map[string]bool, verifiers []*auth.Tokens, protected map[string]bool, rules map[string]config.Access) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		omitted := unreadable(r, verifiers, protected, rules)
+		for resourceKey := range hidden {
+			omitted[resourceKey] = true
+		}
+
+		common.DB(storageSvc, omitted)(w, r)
+	}
+}
+
+// authorizedResources operates as a http handler, serving the request by the handler of the resources the request
+// is allowed to read, following the same rules as the resource routes.
+func authorizedResources(resources map[string]storage.Storage, verifiers []*auth.Tokens, protected map[string]bool, rules map[string]config.Access, handler func(resources map[string]storage.Storage) http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		omitted := unreadable(r, verifiers, protected, rules)
+
+		readable := make(map[string]storage.Storage, len(resources))
+		for resourceKey, storageSvc := range resources {
+			if !omitted[resourceKey] {
+				readable[resourceKey] = storageSvc
+			}
+		}
+
+		handler(readable)(w, r)
+	}
+}
+
+// unreadable returns the resources the request is not allowed to read, following the same rules as the resource
+// routes.
+func unreadable(r *http.Request, verifiers []*auth.Tokens, protected map[string]bool, rules map[string]config.Access) map[string]bool {
+	claims, err := verifyToken(r, verifiers)
+
+	resourceKeys := make([]string, 0, len(protected)+len(rules))
+	for resourceKey := range protected {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	for resourceKey := range rules {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	omitted := make(map[string]bool)
+	for _, resourceKey := range resourceKeys {
+		rule := resourceRule(resourceKey, http.MethodGet, protected, rules)
+		if rule == "" || rule == config.RulePublic {
+			continue
+		}
+
+		if err != nil {
+			omitted[resourceKey] = true
+			continue
+		}
+
+		if roles := config.Roles(rule); roles != nil && !grantsAnyRole(claims, roles) {
+			omitted[resourceKey] = true
+		}
+	}
+
+	return omitted
+}
+
+// authorizedWrite returns a function reporting whether the request is allowed to write the resource, following
+// the same rules as the resource routes.
+func authorizedWrite(verifiers []*auth.Tokens, protected map[string]bool, rules map[string]config.Access) func(r *http.Request, resourceKey string) bool {
+	return func(r *http.Request, resourceKey string) bool {
+		rule := resourceRule(resourceKey, http.MethodPost, protected, rules)
+		if rule == "" || rule == config.RulePublic {
+			return true
+		}
+
+		claims, err := verifyToken(r, verifiers)
+		if err != nil {
+			return false
+		}
+
+		roles := config.Roles(rule)
+
+		return roles == nil || grantsAnyRole(claims, roles)
+	}
+}
+
+// resourceRule returns the access rule of the resource for the request method. Protected resources
+// without an access rule require any valid token.
+func resourceRule(resourceKey, method string, protected map[string]bool, rules map[string]config.Access) string {
+	rule := accessRule(rules[resourceKey], method)
+	if rule == "" && protected[resourceKey] {
+		rule = config.RuleAny
+	}
+
+	return rule
+}
+
+// verifyToken returns the claims of the bearer token of the request, which is valid for any of the
+// provided tokens instances.
+func verifyToken(r *http.Request, verifiers []*auth.Tokens) (auth.Claims, error) {
+	token, err := auth.BearerToken(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, errUnauthorized
+	}
+
+	var claims auth.Claims
+	for _, tokens := range verifiers {
+		if claims, err = tokens.Verify(token); err == nil {
+			return claims, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", errUnauthorized, err)
+}
+
+// accessRule returns the rule of the access rules applying to the request method.
+func accessRule(access config.Access, method string) string {
+	if method == http.MethodGet || method == http.MethodHead {
+		return access.Read
+	}
+
+	return access.Write
+}
+
+// grantsAnyRole reports whether the 'role', 'roles' or 'scope' claims contain any of the roles.
+func grantsAnyRole(claims auth.Claims, roles []string) bool {
+	granted := make(map[string]bool)
+
+	if role, ok := claims["role"].(string); ok {
+		granted[role] = true
+	}
+
+	if list, ok := claims["roles"].([]interface{}); ok {
+		for _, role := range list {
+			if role, ok := role.(string); ok {
+				granted[role] = true
+			}
+		}
+	}
+
+	if scope, ok := claims["scope"].(string); ok {
+		for _, role := range strings.Fields(scope) {
+			granted[role] = true
+		}
+	}
+
+	for _, role := range roles {
+		if granted[role] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// routeResourceKey returns the resource key of the matched route, e.g. 'posts' for '/posts/{id}'.
+func routeResourceKey(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	resourceKey := strings.SplitN(strings.TrimPrefix(tmpl, "/"), "/", 2)[0]
+
+	// Routes of the resources created at runtime hold the resource as variable.
+	if resourceKey == "{resource}" {
+		return mux.Vars(r)["resource"]
+	}
+
+	return resourceKey
+}
+
+func decodeCredentials(r *http.Request) (string, string, error) {
+	credentials, err := decodeResource(r)
+	if err != nil {
+		return "", "", storage.ErrBadRequest
+	}
+
+	email, _ := credentials[emailField].(string)
+	password, _ := credentials[passwordField].(string)
+	if email == "" || password == "" {
+		return "", "", errMissingCredentials
+	}
+
+	return email, password, nil
+}
+
+// checkPassword compares the password with the stored bcrypt hash. Plain text passwords are accepted
+// as well, to allow hand written fixtures.
+func checkPassword(stored, password string) bool {
+	if stored == "" {
+		return false
+	}
+
+	if strings.HasPrefix(stored, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+
+	return stored == password
+}
+
+func respondToken(w http.ResponseWriter, statusCode int, user storage.Resource, tokens *auth.Tokens, privateFields []string) {
+	claims := auth.Claims{"sub": fmt.Sprint(user["id"]), emailField: user[emailField]}
+	if role, ok := user[roleField]; ok {
+		claims[roleField] = role
+	}
+
+	token, err := tokens.Issue(claims)
+	if err != nil {
+		web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+		return
+	}
+
+	web.Success(w, statusCode, authResponse{AccessToken: token, User: safeUser(user, privateFields)})
+}
+
+// safeUser returns a copy of the user, without the password hash and the private fields.
+func safeUser(user storage.Resource, privateFields []string) storage.Resource {
+	omitted := map[string]bool{passwordField: true}
+	for _, field := range privateFields {
+		omitted[field] = true
+	}
+
+	safe := make(storage.Resource, len(user))
+	for key, val := range user {
+		if !omitted[key] {
+			safe[key] = val
+		}
+	}
+
+	return safe
+}
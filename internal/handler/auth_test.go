@@ -0,0 +1,541 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestAuth(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {
+			{"id": "1", "email": "admin@example.com", "password": "secret", "role": "admin"},
+		},
+		"posts":    {{"id": "1", "title": "title-1"}},
+		"comments": {{"id": "1", "body": "body-1"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"users", "posts", "comments"} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithAuth(tokens, "users", "users", "posts")))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		statusCode int
+	}{
+		{
+			name:       "Login with valid credentials",
+			method:     http.MethodPost,
+			path:       "/login",
+			body:       `{"email": "admin@example.com", "password": "secret"}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Login with invalid password",
+			method:     http.MethodPost,
+			path:       "/login",
+			body:       `{"email": "admin@example.com", "password": "wrong"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Login without password",
+			method:     http.MethodPost,
+			path:       "/login",
+			body:       `{"email": "admin@example.com"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Register new user",
+			method:     http.MethodPost,
+			path:       "/register",
+			body:       `{"email": "user@example.com", "password": "pass"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Register existing email",
+			method:     http.MethodPost,
+			path:       "/register",
+			body:       `{"email": "user@example.com", "password": "pass"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Login registered user",
+			method:     http.MethodPost,
+			path:       "/login",
+			body:       `{"email": "user@example.com", "password": "pass"}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Protected resource without token",
+			method:     http.MethodGet,
+			path:       "/posts",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Protected resource with invalid token",
+			method:     http.MethodGet,
+			path:       "/posts/1",
+			token:      "invalid",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Unprotected resource without token",
+			method:     http.MethodGet,
+			path:       "/comments",
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+
+	// Use the token of a successful login to access a protected resource.
+	resp, err := http.Post(server.URL+"/login", "application/json", strings.NewReader(`{"email": "admin@example.com", "password": "secret"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		AccessToken string           `json:"accessToken"`
+		User        storage.Resource `json:"user"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if _, ok := got.User["password"]; ok {
+		t.Fatalf("expected user without password, but got %v", got.User)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/posts/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+got.AccessToken)
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %v, but got %v", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestAccessRules(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "title-1"}},
+		"users": {},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"posts", "users"} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	cfg, err := config.Parse([]byte(`{"resources": {"posts": {"access": {"read": "public", "write": "admin, editor"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithAuth(tokens, "users"), handler.WithConfig(cfg)))
+	defer server.Close()
+
+	adminToken, err := tokens.Issue(auth.Claims{"sub": "1", "role": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	editorToken, err := tokens.Issue(auth.Claims{"sub": "2", "scope": "read editor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userToken, err := tokens.Issue(auth.Claims{"sub": "3", "roles": []string{"user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		statusCode int
+	}{
+		{
+			name:       "Read public resource without token",
+			method:     http.MethodGet,
+			path:       "/posts",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Write resource without token",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "Write resource without role",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			token:      userToken,
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:       "Write resource with role claim",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			token:      adminToken,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Write resource with scope claim",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			token:      editorToken,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Read protected resource without rules",
+			method:     http.MethodGet,
+			path:       "/users",
+			statusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(`{"title": "new-title"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestAuth_DB(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users":    {{"id": "1", "email": "admin@example.com", "password": "secret"}},
+		"posts":    {{"id": "1", "title": "title-1"}},
+		"drafts":   {{"id": "1", "title": "draft-1"}},
+		"comments": {{"id": "1", "body": "body-1"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"users", "posts", "drafts", "comments", ""} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key == "" {
+			key = "db"
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	cfg, err := config.Parse([]byte(`{"resources": {"drafts": {"access": {"read": "admin", "write": "admin"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithAuth(tokens, "users", "users", "posts"), handler.WithConfig(cfg)))
+	defer server.Close()
+
+	adminToken, err := tokens.Issue(auth.Claims{"sub": "1", "role": "admin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userToken, err := tokens.Issue(auth.Claims{"sub": "2", "role": "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name      string
+		token     string
+		resources []string
+	}{
+		{
+			name:      "List db without token",
+			resources: []string{"comments"},
+		},
+		{
+			name:      "List db with invalid token",
+			token:     "invalid",
+			resources: []string{"comments"},
+		},
+		{
+			name:      "List db without role",
+			token:     userToken,
+			resources: []string{"comments", "posts", "users"},
+		},
+		{
+			name:      "List db with role",
+			token:     adminToken,
+			resources: []string{"comments", "drafts", "posts", "users"},
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/db", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got storage.Database
+		if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, http.StatusOK, resp.StatusCode)
+		}
+
+		if len(got) != len(tt.resources) {
+			t.Fatalf("%s: expected resources %v, but got %v", tt.name, tt.resources, got)
+		}
+
+		for _, resourceKey := range tt.resources {
+			if _, ok := got[resourceKey]; !ok {
+				t.Fatalf("%s: expected resources %v, but got %v", tt.name, tt.resources, got)
+			}
+		}
+	}
+}
+
+func TestAuth_RegisterRole(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {},
+		"posts": {{"id": "1", "title": "title-1"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"users", "posts"} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	cfg, err := config.Parse([]byte(`{"resources": {"posts": {"access": {"read": "admin", "write": "admin"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithAuth(tokens, "users"), handler.WithConfig(cfg)))
+	defer server.Close()
+
+	body := `{"email": "user@example.com", "password": "pass", "role": "admin", "roles": ["admin"]}`
+	resp, err := http.Post(server.URL+"/register", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		AccessToken string           `json:"accessToken"`
+		User        storage.Resource `json:"user"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	if _, ok := got.User["role"]; ok {
+		t.Fatalf("expected user without role, but got %v", got.User)
+	}
+
+	if _, ok := got.User["roles"]; ok {
+		t.Fatalf("expected user without roles, but got %v", got.User)
+	}
+
+	claims, err := tokens.Verify(got.AccessToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := claims["role"]; ok {
+		t.Fatalf("expected claims without role, but got %v", claims)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/posts", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+got.AccessToken)
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status code %v, but got %v", http.StatusForbidden, resp.StatusCode)
+	}
+}
+
+func TestAuth_PrivateFields(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {{"id": "1", "email": "admin@example.com", "password": "secret", "ssn": "123-45-6789"}},
+	})
+
+	usersSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Parse([]byte(`{"resources": {"users": {"private": ["ssn"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	server := httptest.NewServer(handler.Setup(
+		map[string]storage.Storage{"users": usersSvc},
+		handler.WithAuth(tokens, "users"),
+		handler.WithConfig(cfg),
+	))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Login omits private fields",
+			path:       "/login",
+			body:       `{"email": "admin@example.com", "password": "secret"}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Register omits private fields",
+			path:       "/register",
+			body:       `{"email": "user@example.com", "password": "pass", "ssn": "987-65-4321"}`,
+			statusCode: http.StatusCreated,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Post(server.URL+tt.path, "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got struct {
+			User storage.Resource `json:"user"`
+		}
+
+		if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if _, ok := got.User["ssn"]; ok {
+			t.Fatalf("%s: expected user without private fields, but got %v", tt.name, got.User)
+		}
+
+		if got.User["email"] == nil {
+			t.Fatalf("%s: expected user with email, but got %v", tt.name, got.User)
+		}
+	}
+}
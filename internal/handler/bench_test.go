@@ -0,0 +1,101 @@
+package handler_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// benchResources is the number of resources of the synthetic dataset of the benchmarks.
+const benchResources = 10000
+
+// benchHandler returns the handler serving a synthetic 'posts' resource, kept in memory.
+func benchHandler(b *testing.B, opts ...handler.Option) http.Handler {
+	b.Helper()
+
+	// Logging every request would dominate the measurements.
+	logrus.SetOutput(ioutil.Discard)
+
+	resources := make([]storage.Resource, 0, benchResources)
+	for idx := 0; idx < benchResources; idx++ {
+		resources = append(resources, storage.Resource{
+			"id":     strconv.Itoa(idx + 1),
+			"title":  fmt.Sprintf("post %d about json servers", idx),
+			"author": fmt.Sprintf("author-%d", idx%100),
+			"views":  float64(idx % 1000),
+			"tags":   []interface{}{"go", fmt.Sprintf("tag-%d", idx%10)},
+		})
+	}
+
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": resources}), "posts")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return handler.Setup(map[string]storage.Storage{"posts": storageSvc}, opts...)
+}
+
+// benchServe serves the request of the benchmark with the handler b.N times, failing on unexpected status codes.
+func benchServe(b *testing.B, h http.Handler, method, target, body string, statusCode int) {
+	b.Helper()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != statusCode {
+			b.Fatalf("expected status code %v, but got %v", statusCode, w.Code)
+		}
+	}
+}
+
+func BenchmarkRead(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodGet, "/posts/5000", "", http.StatusOK)
+}
+
+func BenchmarkList(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodGet, "/posts", "", http.StatusOK)
+}
+
+func BenchmarkList_Page(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodGet, "/posts?_page=50&_limit=20", "", http.StatusOK)
+}
+
+func BenchmarkList_Filter(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodGet, "/posts?author=author-7&views_gte=500", "", http.StatusOK)
+}
+
+func BenchmarkList_Sort(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodGet, "/posts?_sort=views,title&_order=desc,asc&_limit=20", "", http.StatusOK)
+}
+
+func BenchmarkList_Search(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodGet, "/posts?q=servers&_limit=20", "", http.StatusOK)
+}
+
+func BenchmarkList_Cached(b *testing.B) {
+	benchServe(b, benchHandler(b, handler.WithResponseCache(time.Hour)), http.MethodGet, "/posts?_page=50&_limit=20", "", http.StatusOK)
+}
+
+func BenchmarkCreate(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodPost, "/posts", `{"title": "new post", "author": "author-1"}`, http.StatusCreated)
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	benchServe(b, benchHandler(b), http.MethodPatch, "/posts/5000", `{"views": 1}`, http.StatusOK)
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var errBlobNotFound = errors.New("file not found")
+
+// Blob operates as a http handler, to serve the local file referenced by the field of the requested resource.
+// Range requests are supported. Clients asking for json get the resource itself, like on Read.
+func Blob(storageSvc storage.Storage, field, baseDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Read request path parameter id.
+		id := mux.Vars(r)["id"]
+
+		// Find the resource with the requested id.
+		data, err := traced(r, storageSvc).FindById(id)
+		if err != nil {
+			// Resource not found.
+			if errors.Is(err, storage.ErrResourceNotFound) {
+				web.Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		if prefersJSON(r) {
+			web.Success(w, http.StatusOK, data)
+			return
+		}
+
+		name, ok := data[field].(string)
+		if !ok || name == "" {
+			web.Error(w, http.StatusNotFound, errBlobNotFound.Error())
+			return
+		}
+
+		// Paths are resolved against the directory of the watch file, and must stay inside it.
+		name, ok = blobPath(baseDir, name)
+		if !ok {
+			web.Error(w, http.StatusNotFound, errBlobNotFound.Error())
+			return
+		}
+
+		f, err := os.Open(name)
+		if err != nil {
+			web.Error(w, http.StatusNotFound, errBlobNotFound.Error())
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			web.Error(w, http.StatusNotFound, errBlobNotFound.Error())
+			return
+		}
+
+		// An explicit content type on the resource takes precedence over the file extension.
+		contentType, ok := data["contentType"].(string)
+		if !ok {
+			contentType = mime.TypeByExtension(filepath.Ext(name))
+		}
+
+		// Without a known content type, ServeContent sniffs it from the content.
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+
+		http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+	}
+}
+
+// blobPath resolves the relative file path against the base directory. Absolute paths, and paths leaving
+// the base directory, including through symbolic links, are rejected.
+func blobPath(baseDir, name string) (string, bool) {
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", false
+	}
+
+	base, err := filepath.EvalSymlinks(baseDir)
+	if err != nil {
+		return "", false
+	}
+
+	base, err = filepath.Abs(base)
+	if err != nil {
+		return "", false
+	}
+
+	resolved, err := filepath.EvalSymlinks(filepath.Join(base, name))
+	if err != nil {
+		return "", false
+	}
+
+	rel, err := filepath.Rel(base, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return resolved, true
+}
+
+// prefersJSON reports whether the first media type of the Accept header is json.
+func prefersJSON(r *http.Request) bool {
+	accept := strings.Split(r.Header.Get("Accept"), ",")[0]
+	mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(accept))
+
+	return mediaType == "application/json"
+}
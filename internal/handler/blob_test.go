@@ -0,0 +1,157 @@
+package handler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestBlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	baseDir := filepath.Join(dir, "base")
+	if err = os.Mkdir(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(baseDir, "doc.pdf"), []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	secret := filepath.Join(dir, "secret.txt")
+	if err = ioutil.WriteFile(secret, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.Symlink(secret, filepath.Join(baseDir, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	data := storage.Database{
+		"files": {
+			{"id": "1", "file": "doc.pdf"},
+			{"id": "2", "file": "missing.pdf"},
+			{"id": "4", "file": "../secret.txt"},
+			{"id": "5", "file": secret},
+			{"id": "6", "file": "link.txt"},
+		},
+	}
+
+	storageSvc, err := storage.NewMock(data, "files")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(
+		map[string]storage.Storage{"files": storageSvc},
+		handler.WithBlob("files", "file", baseDir),
+	))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		id          string
+		header      http.Header
+		statusCode  int
+		contentType string
+		body        string
+	}{
+		{
+			name:        "Serve file",
+			id:          "1",
+			statusCode:  http.StatusOK,
+			contentType: "application/pdf",
+			body:        "0123456789",
+		},
+		{
+			name:        "Serve file range",
+			id:          "1",
+			header:      http.Header{"Range": {"bytes=2-4"}},
+			statusCode:  http.StatusPartialContent,
+			contentType: "application/pdf",
+			body:        "234",
+		},
+		{
+			name:        "Serve resource for json clients",
+			id:          "1",
+			header:      http.Header{"Accept": {"application/json"}},
+			statusCode:  http.StatusOK,
+			contentType: "application/json",
+			body:        `{"file":"doc.pdf","id":"1"}`,
+		},
+		{
+			name:        "Serve missing file",
+			id:          "2",
+			statusCode:  http.StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			name:        "Serve file of non existing resource",
+			id:          "3",
+			statusCode:  http.StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			name:        "Reject relative path outside base directory",
+			id:          "4",
+			statusCode:  http.StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			name:        "Reject absolute path",
+			id:          "5",
+			statusCode:  http.StatusNotFound,
+			contentType: "application/json",
+		},
+		{
+			name:        "Reject symbolic link outside base directory",
+			id:          "6",
+			statusCode:  http.StatusNotFound,
+			contentType: "application/json",
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/files/"+tt.id, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for key, values := range tt.header {
+			req.Header[key] = values
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if header := resp.Header.Get("Content-Type"); header != tt.contentType {
+			t.Fatalf("%s: expected header Content-Type %v, but got %v", tt.name, tt.contentType, header)
+		}
+
+		if tt.body != "" && string(body) != tt.body {
+			t.Fatalf("%s: expected body %v, but got %v", tt.name, tt.body, string(body))
+		}
+	}
+}
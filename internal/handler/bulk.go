@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var (
+	errEmptyBatch        = errors.New("empty batch")
+	errUnknownResources  = errors.New("unknown resources")
+	errForbiddenResource = errors.New("forbidden resources")
+)
+
+// decodeBatch reports whether the json body of the request is an array, and decodes its resources if so. Other
+// bodies are kept for decodeResource.
+func decodeBatch(r *http.Request) ([]storage.Resource, bool, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data" {
+		return nil, false, nil
+	}
+
+	body := bufio.NewReader(r.Body)
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{body, r.Body}
+
+	for {
+		b, err := body.ReadByte()
+		if err != nil {
+			return nil, false, nil
+		}
+
+		if b == ' ' || b == '\t' || b == '\r' || b == '\n' {
+			continue
+		}
+
+		if err = body.UnreadByte(); err != nil || b != '[' {
+			return nil, false, nil
+		}
+
+		break
+	}
+
+	var resources []storage.Resource
+	if err := storage.Decode(json.NewDecoder(body), &resources); err != nil {
+		return nil, true, err
+	}
+
+	return resources, true, nil
+}
+
+// createBatch creates the resources in a single transaction, responding with the created resources. A resource
+// which cannot be created fails the request, and none of them are created.
+func createBatch(w http.ResponseWriter, r *http.Request, storageSvc storage.Storage, resources []storage.Resource, prepare []prepareFunc) {
+	if len(resources) == 0 {
+		web.Error(w, http.StatusBadRequest, fmt.Sprintf("%v: %v", storage.ErrBadRequest, errEmptyBatch))
+		return
+	}
+
+	for idx, newResource := range resources {
+		// Check if the resource is empty, or contains only id.
+		if _, ok := newResource["id"]; len(newResource) == 0 || (len(newResource) == 1 && ok) {
+			web.Error(w, http.StatusBadRequest, fmt.Sprintf("record %d: %v", idx+1, storage.ErrBadRequest))
+			return
+		}
+
+		for _, fn := range prepare {
+			fn(r, newResource)
+		}
+	}
+
+	created := make([]storage.Resource, 0, len(resources))
+
+	err := storage.Transaction(storageSvc, func(tx storage.Storage) error {
+		for idx, newResource := range resources {
+			data, err := traced(r, tx).Create(newResource)
+			if err != nil {
+				return fmt.Errorf("record %d: %w", idx+1, err)
+			}
+
+			created = append(created, data)
+		}
+
+		return nil
+	})
+	if err != nil {
+		web.Error(w, writeErrorStatus(err), writeErrorMessage(err))
+		return
+	}
+
+	respondWritten(w, r, http.StatusCreated, created)
+}
+
+// DeleteMany operates as a http handler, to delete the resources of the ids of the query, e.g. '?id=1&id=2', in a
+// single transaction. A missing resource fails the request, and none of them are deleted.
+func DeleteMany(storageSvc storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ids := make([]string, 0)
+		for _, val := range r.URL.Query()["id"] {
+			for _, id := range strings.Split(val, ",") {
+				if id != "" {
+					ids = append(ids, id)
+				}
+			}
+		}
+
+		if len(ids) == 0 {
+			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+			return
+		}
+
+		err := storage.Transaction(storageSvc, func(tx storage.Storage) error {
+			for _, id := range ids {
+				if err := traced(r, tx).Delete(id); err != nil {
+					return fmt.Errorf("%w: %s", err, id)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			web.Error(w, writeErrorStatus(err), writeErrorMessage(err))
+			return
+		}
+
+		web.Success(w, http.StatusOK, nil)
+	}
+}
+
+// ReplaceDB operates as a http handler, to replace the resources of the collections of the body, e.g.
+// '{"posts": [...]}', in a single transaction. The writable collections missing from the body are emptied, unless
+// merging with '?merge=true', which replaces the resources with the ids of the body and adds the rest instead.
+// Collections which are unknown, or the request is not allowed to write, fail the request. Once written, the
+// collections are passed to the written function, e.g. to drop their cached responses.
+func ReplaceDB(storageSvc storage.Storage, known map[string]bool, writable func(r *http.Request, resourceKey string) bool, written func(resourceKeys []string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		merge, _ := strconv.ParseBool(r.URL.Query().Get("merge"))
+
+		var body storage.Database
+		if err := storage.Decode(json.NewDecoder(r.Body), &body); err != nil || body == nil {
+			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+			return
+		}
+
+		unknown := make([]string, 0)
+		forbidden := make([]string, 0)
+		for resourceKey := range body {
+			switch {
+			case !known[resourceKey]:
+				unknown = append(unknown, resourceKey)
+			case !writable(r, resourceKey):
+				forbidden = append(forbidden, resourceKey)
+			}
+		}
+
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			web.Error(w, http.StatusBadRequest, fmt.Sprintf("%v: %s", errUnknownResources, strings.Join(unknown, ", ")))
+			return
+		}
+
+		if len(forbidden) > 0 {
+			sort.Strings(forbidden)
+			web.Error(w, http.StatusForbidden, fmt.Sprintf("%v: %s", errForbiddenResource, strings.Join(forbidden, ", ")))
+			return
+		}
+
+		result := make(storage.Database, len(body))
+
+		err := storage.WriteDB(storageSvc, func(data storage.Database) (storage.Database, error) {
+			for resourceKey := range data {
+				if _, ok := body[resourceKey]; !ok && !merge && known[resourceKey] && writable(r, resourceKey) {
+					body[resourceKey] = make([]storage.Resource, 0)
+				}
+			}
+
+			for resourceKey, resources := range body {
+				if merge {
+					resources = mergeResources(data[resourceKey], resources)
+				}
+
+				data[resourceKey] = resources
+				result[resourceKey] = resources
+			}
+
+			return data, nil
+		})
+		if err != nil {
+			web.Error(w, writeErrorStatus(err), writeErrorMessage(err))
+			return
+		}
+
+		resourceKeys := make([]string, 0, len(result))
+		for resourceKey := range result {
+			resourceKeys = append(resourceKeys, resourceKey)
+		}
+
+		sort.Strings(resourceKeys)
+		written(resourceKeys)
+
+		web.Success(w, http.StatusOK, result)
+	}
+}
+
+// mergeResources returns the stored resources, with the ones of the same id replaced by the merged ones, and the
+// rest of the merged ones added.
+func mergeResources(stored, merged []storage.Resource) []storage.Resource {
+	positions := make(map[string]int, len(stored))
+	for idx, resource := range stored {
+		if id, ok := resource["id"]; ok && id != nil {
+			positions[fmt.Sprint(id)] = idx
+		}
+	}
+
+	resources := append(make([]storage.Resource, 0, len(stored)+len(merged)), stored...)
+	for _, resource := range merged {
+		if id, ok := resource["id"]; ok && id != nil {
+			if idx, ok := positions[fmt.Sprint(id)]; ok {
+				resources[idx] = resource
+				continue
+			}
+
+			positions[fmt.Sprint(id)] = len(resources)
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
+
+// writeErrorStatus returns the status code of the responses of writes failing with the error.
+func writeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrResourceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrResourceAlreadyExists), errors.Is(err, storage.ErrDuplicateValue):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrMissingFields), errors.Is(err, storage.ErrConflictingFields):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, storage.ErrFileChanged):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrTransactionUnsupported):
+		return http.StatusNotImplemented
+	}
+
+	return http.StatusInternalServerError
+}
+
+// writeErrorMessage returns the message of the responses of writes failing with the error, hiding the details
+// of unexpected errors.
+func writeErrorMessage(err error) string {
+	if writeErrorStatus(err) == http.StatusInternalServerError {
+		return storage.ErrInternalServerError.Error()
+	}
+
+	return err.Error()
+}
@@ -0,0 +1,175 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestBulk(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {
+		"countries": {"readOnly": true},
+		"secrets": {"hidden": true}
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := testModesServer(t, handler.WithConfig(cfg))
+	defer server.Close()
+
+	// Steps run in order, each expecting the ids of the posts and events after it.
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		posts      []string
+		events     []string
+	}{
+		{
+			name:       "Create batch with duplicate id",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `[{"id": "2", "title": "title-2"}, {"id": "1", "title": "duplicate"}]`,
+			statusCode: http.StatusConflict,
+			posts:      []string{"1"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Create empty batch",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `[]`,
+			statusCode: http.StatusBadRequest,
+			posts:      []string{"1"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Create batch",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       ` [{"id": "2", "title": "title-2"}, {"id": "3", "title": "title-3"}]`,
+			statusCode: http.StatusCreated,
+			posts:      []string{"1", "2", "3"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Delete many with missing id",
+			method:     http.MethodDelete,
+			path:       "/posts?id=1&id=9",
+			statusCode: http.StatusNotFound,
+			posts:      []string{"1", "2", "3"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Delete many",
+			method:     http.MethodDelete,
+			path:       "/posts?id=1&id=3",
+			statusCode: http.StatusOK,
+			posts:      []string{"2"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Write db with unknown resource",
+			method:     http.MethodPost,
+			path:       "/db",
+			body:       `{"posts": [], "secrets": []}`,
+			statusCode: http.StatusBadRequest,
+			posts:      []string{"2"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Write db with read-only resource",
+			method:     http.MethodPost,
+			path:       "/db",
+			body:       `{"countries": []}`,
+			statusCode: http.StatusForbidden,
+			posts:      []string{"2"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Merge db",
+			method:     http.MethodPost,
+			path:       "/db?merge=true",
+			body:       `{"posts": [{"id": "2", "title": "merged"}, {"id": "4", "title": "title-4"}]}`,
+			statusCode: http.StatusOK,
+			posts:      []string{"2", "4"},
+			events:     []string{"1"},
+		},
+		{
+			name:       "Replace db",
+			method:     http.MethodPost,
+			path:       "/db",
+			body:       `{"posts": [{"id": "5", "title": "title-5"}]}`,
+			statusCode: http.StatusOK,
+			posts:      []string{"5"},
+			events:     []string{},
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		for key, expected := range map[string][]string{"posts": tt.posts, "events": tt.events} {
+			if got := testBulkIds(t, server.URL+"/"+key); !reflect.DeepEqual(got, expected) {
+				t.Fatalf("%s: expected %s %v, but got %v", tt.name, key, expected, got)
+			}
+		}
+	}
+
+	// Collections which cannot be written are kept on replace.
+	if got := testBulkIds(t, server.URL+"/countries"); !reflect.DeepEqual(got, []string{"1"}) {
+		t.Fatalf("expected countries [1], but got %v", got)
+	}
+}
+
+func testBulkIds(t *testing.T, url string) []string {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	contentBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources := make([]storage.Resource, 0)
+	if err = json.Unmarshal(contentBytes, &resources); err != nil {
+		t.Fatalf("expected resources, but got %s", contentBytes)
+	}
+
+	ids := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		ids = append(ids, resource["id"].(string))
+	}
+
+	return ids
+}
@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+const (
+	// cacheHeader is the response header telling whether a response was served from the cache, HIT or MISS.
+	cacheHeader = "X-Cache"
+	// maxCachedResponses bounds the number of responses cached at once, across collections.
+	maxCachedResponses = 1024
+)
+
+// responseCache caches the rendered successful responses of GET requests, by collection, so clients polling the
+// same queries are served without listing, filtering and encoding the resources again. Responses are dropped on
+// every write to their collection, or once older than the ttl, which bounds how stale they are after changes
+// made outside the API, e.g. to the watch file.
+type responseCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	size    int
+	entries map[string]map[string]*cachedResponse
+}
+
+// cachedResponse is a rendered response, with the headers set by the handler.
+type cachedResponse struct {
+	status  int
+	header  http.Header
+	body    []byte
+	expires time.Time
+}
+
+// newResponseCache returns a cache keeping responses for the ttl.
+func newResponseCache(ttl time.Duration, now func() time.Time) *responseCache {
+	return &responseCache{ttl: ttl, now: now, entries: make(map[string]map[string]*cachedResponse)}
+}
+
+// cache serves the GET requests of the collection from the cache if possible, or else with the handler, caching
+// their successful responses. Other requests are served by the handler right away. A nil cache serves all
+// requests with the handler.
+func (c *responseCache) cache(collection string, next http.HandlerFunc) http.HandlerFunc {
+	if c == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next(w, r)
+			return
+		}
+
+		key := cacheKey(r)
+
+		if cached, ok := c.get(collection, key); ok {
+			for name, values := range cached.header {
+				w.Header()[name] = values
+			}
+
+			w.Header().Set(cacheHeader, "HIT")
+			w.WriteHeader(cached.status)
+
+			// nolint
+			w.Write(cached.body)
+			return
+		}
+
+		w.Header().Set(cacheHeader, "MISS")
+
+		before := w.Header().Clone()
+		cw := &cacheWriter{ResponseWriter: w, status: http.StatusOK}
+
+		next(cw, r)
+
+		if cw.status != http.StatusOK || cw.skip {
+			return
+		}
+
+		// Only the headers set by the handler are replayed, the ones of the middlewares are set again anyway.
+		header := make(http.Header)
+		for name, values := range w.Header() {
+			if !equalValues(before[name], values) {
+				header[name] = values
+			}
+		}
+
+		c.put(collection, key, &cachedResponse{
+			status:  cw.status,
+			header:  header,
+			body:    cw.body.Bytes(),
+			expires: c.now().Add(c.ttl),
+		})
+	}
+}
+
+// invalidate drops the cached responses of the collection.
+func (c *responseCache) invalidate(collection string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.size -= len(c.entries[collection])
+	delete(c.entries, collection)
+}
+
+func (c *responseCache) get(collection, key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[collection][key]
+	if !ok || !c.now().Before(cached.expires) {
+		return nil, false
+	}
+
+	return cached, true
+}
+
+func (c *responseCache) put(collection, key string, cached *cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.size >= maxCachedResponses {
+		c.evictExpired()
+	}
+
+	// Responses are not cached while full, until some expire or are invalidated.
+	if c.size >= maxCachedResponses {
+		return
+	}
+
+	entries, ok := c.entries[collection]
+	if !ok {
+		entries = make(map[string]*cachedResponse)
+		c.entries[collection] = entries
+	}
+
+	if _, exists := entries[key]; !exists {
+		c.size++
+	}
+
+	entries[key] = cached
+}
+
+// evictExpired drops the expired responses. Callers must hold the lock.
+func (c *responseCache) evictExpired() {
+	now := c.now()
+
+	for collection, entries := range c.entries {
+		for key, cached := range entries {
+			if !now.Before(cached.expires) {
+				delete(entries, key)
+				c.size--
+			}
+		}
+
+		if len(entries) == 0 {
+			delete(c.entries, collection)
+		}
+	}
+}
+
+// cacheKey identifies the requests sharing a response, by their path and query, and the headers the response
+// may depend on.
+func cacheKey(r *http.Request) string {
+	return strings.Join([]string{
+		r.URL.RequestURI(),
+		r.Header.Get("Authorization"),
+		r.Header.Get("Cookie"),
+		r.Header.Get("Accept"),
+		r.Header.Get("Prefer"),
+	}, "\x00")
+}
+
+func equalValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// cacheWriter records the status code and the body of a response, while writing it.
+type cacheWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+	// skip is set by handlers whose response depends on other collections, and must not be cached.
+	skip bool
+}
+
+// skipCache keeps the response from being cached, e.g. as it depends on other collections, whose writes would
+// not drop it.
+func skipCache(w http.ResponseWriter) {
+	if cw, ok := w.(*cacheWriter); ok {
+		cw.skip = true
+	}
+}
+
+func (cw *cacheWriter) WriteHeader(statusCode int) {
+	cw.status = statusCode
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (cw *cacheWriter) Write(b []byte) (int, error) {
+	cw.body.Write(b)
+	return cw.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the wrapped response writer, so the deadlines of the response can be set.
+func (cw *cacheWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// cachedStorage wraps a storage, and drops the cached responses of the collection on every write.
+type cachedStorage struct {
+	storage.Storage
+	cache      *responseCache
+	collection string
+}
+
+func (s *cachedStorage) FindContext(ctx context.Context) ([]storage.Resource, error) {
+	return storage.FindContext(ctx, s.Storage)
+}
+
+func (s *cachedStorage) Create(newResource storage.Resource) (storage.Resource, error) {
+	defer s.cache.invalidate(s.collection)
+	return s.Storage.Create(newResource)
+}
+
+func (s *cachedStorage) Replace(id string, replaced storage.Resource) (storage.Resource, error) {
+	defer s.cache.invalidate(s.collection)
+	return s.Storage.Replace(id, replaced)
+}
+
+func (s *cachedStorage) Update(id string, updatedReq storage.Resource) (storage.Resource, error) {
+	defer s.cache.invalidate(s.collection)
+	return s.Storage.Update(id, updatedReq)
+}
+
+func (s *cachedStorage) Delete(id string) error {
+	defer s.cache.invalidate(s.collection)
+	return s.Storage.Delete(id)
+}
+
+// Transaction drops the cached responses once the transaction completes, as responses cached meanwhile miss its
+// writes.
+func (s *cachedStorage) Transaction(fn func(tx storage.Storage) error) error {
+	defer s.cache.invalidate(s.collection)
+	return storage.Transaction(s.Storage, fn)
+}
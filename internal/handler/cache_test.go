@@ -0,0 +1,125 @@
+package handler_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestResponseCache(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts":    {{"id": "1", "title": "json-server"}},
+		"comments": {{"id": "1", "body": "nice"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"posts", "comments"} {
+		storageSvc, err := storage.NewMemory(db, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithResponseCache(time.Hour)))
+	defer server.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		expectedHit  string
+		expectedBody string
+	}{
+		{name: "First list", method: http.MethodGet, path: "/posts", expectedHit: "MISS"},
+		{name: "Repeated list", method: http.MethodGet, path: "/posts", expectedHit: "HIT"},
+		{name: "Other query", method: http.MethodGet, path: "/posts?title=json-server", expectedHit: "MISS"},
+		{name: "First read", method: http.MethodGet, path: "/posts/1", expectedHit: "MISS"},
+		{name: "Repeated read", method: http.MethodGet, path: "/posts/1", expectedHit: "HIT"},
+		{name: "Missing read", method: http.MethodGet, path: "/posts/2", expectedHit: "MISS"},
+		{name: "Repeated missing read", method: http.MethodGet, path: "/posts/2", expectedHit: "MISS"},
+		{name: "Other resource", method: http.MethodGet, path: "/comments", expectedHit: "MISS"},
+		{name: "Write", method: http.MethodPatch, path: "/posts/1", body: `{"title": "changed"}`},
+		{
+			name:         "List after write",
+			method:       http.MethodGet,
+			path:         "/posts",
+			expectedHit:  "MISS",
+			expectedBody: `[{"id":"1","title":"changed"}]`,
+		},
+		{name: "Read after write", method: http.MethodGet, path: "/posts/1", expectedHit: "MISS"},
+		{name: "Other resource after write", method: http.MethodGet, path: "/comments", expectedHit: "HIT"},
+	}
+
+	for _, tc := range testCases {
+		req, err := http.NewRequest(tc.method, server.URL+tc.path, bytes.NewBufferString(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := resp.Header.Get("X-Cache"); got != tc.expectedHit {
+			t.Fatalf("%s: expected X-Cache %q, but got %q", tc.name, tc.expectedHit, got)
+		}
+
+		if tc.expectedBody != "" && string(body) != tc.expectedBody {
+			t.Fatalf("%s: expected body %s, but got %s", tc.name, tc.expectedBody, body)
+		}
+	}
+}
+
+func TestResponseCache_Headers(t *testing.T) {
+	resources := make([]storage.Resource, 0, 30)
+	for idx := 0; idx < 30; idx++ {
+		resources = append(resources, storage.Resource{"id": string(rune('a' + idx))})
+	}
+
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": resources}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, handler.WithResponseCache(time.Hour)))
+	defer server.Close()
+
+	var first http.Header
+	for idx := 0; idx < 2; idx++ {
+		resp, err := http.Get(server.URL + "/posts?_page=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if idx == 0 {
+			first = resp.Header
+			continue
+		}
+
+		for _, name := range []string{"Content-Type", "X-Total-Count", "Link"} {
+			if got := resp.Header.Get(name); got != first.Get(name) {
+				t.Fatalf("expected cached header %s %q, but got %q", name, first.Get(name), got)
+			}
+		}
+	}
+}
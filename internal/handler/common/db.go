@@ -9,8 +9,8 @@ import (
 	"github.com/chanioxaris/json-server/internal/web"
 )
 
-// DB operates as a http handler, to list db content.
-func DB(storageSvc storage.Storage) http.HandlerFunc {
+// DB operates as a http handler, to list db content, except for the hidden resources.
+func DB(storageSvc storage.Storage, hidden map[string]bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		data, err := storageSvc.DB()
 		if err != nil {
@@ -18,6 +18,10 @@ func DB(storageSvc storage.Storage) http.HandlerFunc {
 			return
 		}
 
+		for resourceKey := range hidden {
+			delete(data, resourceKey)
+		}
+
 		web.Success(w, http.StatusOK, data)
 	}
 }
@@ -0,0 +1,82 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/faker"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// maxFakerCount is the maximum number of values generated in a single request.
+const maxFakerCount = 1000
+
+var (
+	errInvalidFakerCount = fmt.Errorf("count must be between 1 and %d", maxFakerCount)
+	errInvalidFakerSeed  = errors.New("seed must be an integer")
+)
+
+// FakerKinds operates as a http handler, to list the supported kinds of fake values.
+func FakerKinds() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		web.Success(w, http.StatusOK, faker.Kinds())
+	}
+}
+
+// Faker operates as a http handler, to generate a random value of the requested kind. A list of values
+// is returned when the 'count' query parameter is set, and the 'seed' query parameter makes the response
+// reproducible.
+func Faker(defaultFaker *faker.Faker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		f := defaultFaker
+
+		if seedParam := query.Get("seed"); seedParam != "" {
+			seed, err := strconv.ParseInt(seedParam, 10, 64)
+			if err != nil {
+				web.Error(w, http.StatusBadRequest, errInvalidFakerSeed.Error())
+				return
+			}
+
+			f = faker.New(seed)
+		}
+
+		kind := mux.Vars(r)["kind"]
+
+		countParam := query.Get("count")
+		if countParam == "" {
+			value, err := f.Generate(kind)
+			if err != nil {
+				web.Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+
+			web.Success(w, http.StatusOK, value)
+			return
+		}
+
+		count, err := strconv.Atoi(countParam)
+		if err != nil || count < 1 || count > maxFakerCount {
+			web.Error(w, http.StatusBadRequest, errInvalidFakerCount.Error())
+			return
+		}
+
+		values := make([]interface{}, 0, count)
+		for idx := 0; idx < count; idx++ {
+			value, err := f.Generate(kind)
+			if err != nil {
+				web.Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+
+			values = append(values, value)
+		}
+
+		web.Success(w, http.StatusOK, values)
+	}
+}
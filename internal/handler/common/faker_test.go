@@ -0,0 +1,90 @@
+package common_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestFaker(t *testing.T) {
+	testCases := []struct {
+		name       string
+		path       string
+		statusCode int
+		count      int
+	}{
+		{
+			name:       "Faker single value",
+			path:       "/__faker/email",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Faker list of values",
+			path:       "/__faker/uuid?count=5",
+			statusCode: http.StatusOK,
+			count:      5,
+		},
+		{
+			name:       "Faker with unknown kind",
+			path:       "/__faker/randomKind",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Faker with invalid count",
+			path:       "/__faker/name?count=0",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Faker with invalid seed",
+			path:       "/__faker/name?seed=abc",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(mockServer.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.count > 0 {
+			values := make([]string, 0)
+			if err = json.NewDecoder(resp.Body).Decode(&values); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(values) != tt.count {
+				t.Fatalf("%s: expected %v values, but got %v", tt.name, tt.count, len(values))
+			}
+		}
+
+		resp.Body.Close()
+	}
+}
+
+func TestFakerSeed(t *testing.T) {
+	values := make([]string, 0, 2)
+
+	for idx := 0; idx < 2; idx++ {
+		resp, err := http.Get(mockServer.URL + "/__faker/name?seed=42")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var value string
+		if err = json.NewDecoder(resp.Body).Decode(&value); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		values = append(values, value)
+	}
+
+	if values[0] != values[1] {
+		t.Fatalf("expected same value %v for the same seed, but got %v", values[0], values[1])
+	}
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// memoryReport describes the size of the dataset, and the memory used by the process.
+type memoryReport struct {
+	Collections []collectionReport `json:"collections"`
+	Records     int                `json:"records"`
+	Bytes       int                `json:"bytes"`
+	Memory      processMemory      `json:"memory"`
+	GC          gcStats            `json:"gc"`
+	Goroutines  int                `json:"goroutines"`
+}
+
+// collectionReport describes the size of a collection, with the bytes of its records encoded as json.
+type collectionReport struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	Bytes   int    `json:"bytes"`
+}
+
+type processMemory struct {
+	// ResidentBytes is only reported on systems exposing it, like linux.
+	ResidentBytes  uint64 `json:"residentBytes,omitempty"`
+	SysBytes       uint64 `json:"sysBytes"`
+	HeapAllocBytes uint64 `json:"heapAllocBytes"`
+	HeapInuseBytes uint64 `json:"heapInuseBytes"`
+	HeapObjects    uint64 `json:"heapObjects"`
+}
+
+type gcStats struct {
+	Count       uint32  `json:"count"`
+	PauseTotal  string  `json:"pauseTotal"`
+	LastGC      string  `json:"lastGC,omitempty"`
+	NextGCBytes uint64  `json:"nextGCBytes"`
+	CPUFraction float64 `json:"cpuFraction"`
+}
+
+// Memory operates as a http handler, to report the number of records and their size per collection, largest first,
+// with the resident memory and the garbage collection stats of the process, so operators of long-running mocks
+// notice when a dataset has grown too large.
+func Memory(resources map[string]storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := memoryReport{Collections: make([]collectionReport, 0, len(resources))}
+
+		for resourceKey, storageSvc := range resources {
+			records, err := storageSvc.Find()
+			if err != nil {
+				web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+				return
+			}
+
+			recordsBytes, err := json.Marshal(records)
+			if err != nil {
+				web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+				return
+			}
+
+			report.Collections = append(report.Collections, collectionReport{
+				Name:    resourceKey,
+				Records: len(records),
+				Bytes:   len(recordsBytes),
+			})
+
+			report.Records += len(records)
+			report.Bytes += len(recordsBytes)
+		}
+
+		sort.Slice(report.Collections, func(i, j int) bool {
+			if report.Collections[i].Bytes != report.Collections[j].Bytes {
+				return report.Collections[i].Bytes > report.Collections[j].Bytes
+			}
+
+			return report.Collections[i].Name < report.Collections[j].Name
+		})
+
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+
+		report.Memory = processMemory{
+			ResidentBytes:  residentBytes(),
+			SysBytes:       stats.Sys,
+			HeapAllocBytes: stats.HeapAlloc,
+			HeapInuseBytes: stats.HeapInuse,
+			HeapObjects:    stats.HeapObjects,
+		}
+
+		report.GC = gcStats{
+			Count:       stats.NumGC,
+			PauseTotal:  time.Duration(stats.PauseTotalNs).String(),
+			NextGCBytes: stats.NextGC,
+			CPUFraction: stats.GCCPUFraction,
+		}
+
+		if stats.LastGC > 0 {
+			report.GC.LastGC = time.Unix(0, int64(stats.LastGC)).UTC().Format(time.RFC3339Nano)
+		}
+
+		report.Goroutines = runtime.NumGoroutine()
+
+		web.Success(w, http.StatusOK, report)
+	}
+}
+
+// residentBytes returns the resident memory of the process, or 0 if the system does not expose it.
+func residentBytes() uint64 {
+	statm, err := ioutil.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+
+	// The second field is the number of resident pages.
+	fields := strings.Fields(string(statm))
+	if len(fields) < 2 {
+		return 0
+	}
+
+	pages, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return pages * uint64(os.Getpagesize())
+}
@@ -0,0 +1,63 @@
+package common
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/openapi"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+const docsTemplate = `
+<!doctype html>
+<html lang="en">
+	<head>
+		<meta charset="utf-8">
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+
+		<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3.52.5/swagger-ui.css">
+
+		<title>JSON Server API</title>
+	</head>
+	<body>
+		<div id="swagger-ui"></div>
+
+		<script src="https://unpkg.com/swagger-ui-dist@3.52.5/swagger-ui-bundle.js"></script>
+		<script type="text/javascript">
+			window.onload = function () {
+				SwaggerUIBundle({url: {{ . }}, dom_id: "#swagger-ui"});
+			};
+		</script>
+	</body>
+</html>
+`
+
+// OpenAPI operates as a http handler, to return the OpenAPI document of the routes of the resources, as served
+// with the configuration, with the schemas of their records inferred from the current records.
+func OpenAPI(storageSvc storage.Storage, cfg *config.Config, readOnly bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := storageSvc.DB()
+		if err != nil {
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		web.Success(w, http.StatusOK, openapi.New("JSON Server", data, cfg, readOnly))
+	}
+}
+
+// Docs renders Swagger UI, exploring the OpenAPI document served under the path.
+func Docs(specPath string) http.HandlerFunc {
+	t := template.Must(template.New("docs").Parse(docsTemplate))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if err := t.Execute(w, specPath); err != nil {
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// maxPlaceholderSize is the maximum width and height of a placeholder image.
+const maxPlaceholderSize = 4000
+
+var (
+	errInvalidPlaceholderSize  = fmt.Errorf("width and height must be between 1 and %d", maxPlaceholderSize)
+	errInvalidPlaceholderColor = errors.New("colors must be hex values, e.g. cccccc")
+)
+
+// Placeholder operates as a http handler, to generate a PNG or SVG placeholder image of the requested size.
+// The text, background and foreground colors can be set with the 'text', 'bg' and 'fg' query parameters.
+func Placeholder() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		width, errWidth := strconv.Atoi(vars["width"])
+		height, errHeight := strconv.Atoi(vars["height"])
+		if errWidth != nil || errHeight != nil ||
+			width < 1 || height < 1 || width > maxPlaceholderSize || height > maxPlaceholderSize {
+			web.Error(w, http.StatusBadRequest, errInvalidPlaceholderSize.Error())
+			return
+		}
+
+		query := r.URL.Query()
+
+		text := query.Get("text")
+		if text == "" {
+			text = fmt.Sprintf("%dx%d", width, height)
+		}
+
+		bg, errBg := parseHexColor(query.Get("bg"), "cccccc")
+		fg, errFg := parseHexColor(query.Get("fg"), "555555")
+		if errBg != nil || errFg != nil {
+			web.Error(w, http.StatusBadRequest, errInvalidPlaceholderColor.Error())
+			return
+		}
+
+		// PNG is the default format, unless requested otherwise by extension or query parameter.
+		if vars["ext"] == ".svg" || query.Get("format") == "svg" {
+			w.Header().Set("Content-Type", "image/svg+xml")
+			fmt.Fprint(w, placeholderSVG(width, height, text, bg, fg))
+			return
+		}
+
+		w.Header().Set("Content-Type", "image/png")
+		// nolint
+		png.Encode(w, placeholderPNG(width, height, text, bg, fg))
+	}
+}
+
+// placeholderPNG draws the text centered on a solid background, scaled to fit the image.
+func placeholderPNG(width, height int, text string, bg, fg color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: bg}, image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+
+	// Render the text at its natural size first.
+	textWidth := font.MeasureString(face, text).Ceil()
+	textHeight := face.Metrics().Height.Ceil()
+	if textWidth == 0 {
+		return img
+	}
+
+	textImg := image.NewAlpha(image.Rect(0, 0, textWidth, textHeight))
+	d := &font.Drawer{
+		Dst:  textImg,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(0, face.Metrics().Ascent.Ceil()),
+	}
+	d.DrawString(text)
+
+	// Scale it up to about a fifth of the image height, as long as it fits the width.
+	scale := height / 5 / textHeight
+	if maxScale := width * 8 / 10 / textWidth; maxScale < scale {
+		scale = maxScale
+	}
+
+	if scale < 1 {
+		scale = 1
+	}
+
+	offsetX := (width - textWidth*scale) / 2
+	offsetY := (height - textHeight*scale) / 2
+
+	src := &image.Uniform{C: fg}
+	for y := 0; y < textHeight; y++ {
+		for x := 0; x < textWidth; x++ {
+			if textImg.AlphaAt(x, y).A == 0 {
+				continue
+			}
+
+			rect := image.Rect(offsetX+x*scale, offsetY+y*scale, offsetX+(x+1)*scale, offsetY+(y+1)*scale)
+			draw.Draw(img, rect.Intersect(img.Bounds()), src, image.Point{}, draw.Over)
+		}
+	}
+
+	return img
+}
+
+// placeholderSVG returns an SVG document with the text centered on a solid background. Only the parsed
+// colors end up in the document, never the raw query values.
+func placeholderSVG(width, height int, text string, bg, fg color.Color) string {
+	fontSize := height / 5
+	if maxSize := width * 8 / 5 / (len(text) + 1); maxSize < fontSize {
+		fontSize = maxSize
+	}
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+
+		`<rect width="100%%" height="100%%" fill="#%s"/>`+
+		`<text x="50%%" y="50%%" fill="#%s" font-family="sans-serif" font-size="%d" `+
+		`text-anchor="middle" dominant-baseline="middle">%s</text></svg>`,
+		width, height, width, height, hexColor(bg), hexColor(fg), fontSize, html.EscapeString(text))
+}
+
+// hexColor formats the color like 'cccccc'.
+func hexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+
+	return fmt.Sprintf("%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// parseHexColor parses colors like 'ccc' or 'cccccc', falling back to the default one if empty.
+func parseHexColor(val, fallback string) (color.Color, error) {
+	if val == "" {
+		val = fallback
+	}
+
+	val = strings.TrimPrefix(val, "#")
+	if len(val) == 3 {
+		val = string([]byte{val[0], val[0], val[1], val[1], val[2], val[2]})
+	}
+
+	if len(val) != 6 {
+		return nil, errInvalidPlaceholderColor
+	}
+
+	rgb, err := strconv.ParseUint(val, 16, 32)
+	if err != nil {
+		return nil, errInvalidPlaceholderColor
+	}
+
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
@@ -0,0 +1,110 @@
+package common_test
+
+import (
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestPlaceholder(t *testing.T) {
+	testCases := []struct {
+		name        string
+		path        string
+		statusCode  int
+		contentType string
+		width       int
+		height      int
+		contains    string
+	}{
+		{
+			name:        "Placeholder png",
+			path:        "/__placeholder/400x300?text=Hello",
+			statusCode:  http.StatusOK,
+			contentType: "image/png",
+			width:       400,
+			height:      300,
+		},
+		{
+			name:        "Placeholder png with extension",
+			path:        "/__placeholder/10x20.png",
+			statusCode:  http.StatusOK,
+			contentType: "image/png",
+			width:       10,
+			height:      20,
+		},
+		{
+			name:        "Placeholder svg",
+			path:        "/__placeholder/400x300.svg?text=<Hello>&bg=000",
+			statusCode:  http.StatusOK,
+			contentType: "image/svg+xml",
+			contains:    "&lt;Hello&gt;",
+		},
+		{
+			name:        "Placeholder svg with normalized color",
+			path:        "/__placeholder/400x300.svg?bg=%23ABC",
+			statusCode:  http.StatusOK,
+			contentType: "image/svg+xml",
+			contains:    `fill="#aabbcc"`,
+		},
+		{
+			name:        "Placeholder svg with markup in color",
+			path:        "/__placeholder/400x300.svg?fg=" + url.QueryEscape(`ccc"/><script>alert(1)</script>`),
+			statusCode:  http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "Placeholder with invalid size",
+			path:        "/__placeholder/0x300",
+			statusCode:  http.StatusBadRequest,
+			contentType: "application/json",
+		},
+		{
+			name:        "Placeholder with invalid color",
+			path:        "/__placeholder/40x30?bg=red",
+			statusCode:  http.StatusBadRequest,
+			contentType: "application/json",
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(mockServer.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if header := resp.Header.Get("Content-Type"); header != tt.contentType {
+			t.Fatalf("%s: expected header Content-Type %v, but got %v", tt.name, tt.contentType, header)
+		}
+
+		if tt.width > 0 {
+			img, err := png.Decode(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if size := img.Bounds().Size(); size.X != tt.width || size.Y != tt.height {
+				t.Fatalf("%s: expected size %vx%v, but got %vx%v", tt.name, tt.width, tt.height, size.X, size.Y)
+			}
+		}
+
+		if tt.contains != "" {
+			body, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(string(body), tt.contains) {
+				t.Fatalf("%s: expected body to contain %v, but got %v", tt.name, tt.contains, string(body))
+			}
+		}
+
+		resp.Body.Close()
+	}
+}
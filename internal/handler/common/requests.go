@@ -0,0 +1,55 @@
+package common
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var (
+	errInvalidStatusFilter = errors.New("query parameter 'status' must be a status code")
+	errInvalidLimitFilter  = errors.New("query parameter 'limit' must be a positive number")
+)
+
+// Requests operates as a http handler, to list the captured requests, oldest first. The query parameters
+// 'method', 'path' (exact, or prefix when ending with '*'), 'status' and 'limit' select the requests.
+func Requests(buffer *capture.Buffer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := capture.Filter{Method: query.Get("method"), Path: query.Get("path")}
+
+		if val := query.Get("status"); val != "" {
+			status, err := strconv.Atoi(val)
+			if err != nil {
+				web.Error(w, http.StatusBadRequest, errInvalidStatusFilter.Error())
+				return
+			}
+
+			filter.Status = status
+		}
+
+		if val := query.Get("limit"); val != "" {
+			limit, err := strconv.Atoi(val)
+			if err != nil || limit <= 0 {
+				web.Error(w, http.StatusBadRequest, errInvalidLimitFilter.Error())
+				return
+			}
+
+			filter.Limit = limit
+		}
+
+		web.Success(w, http.StatusOK, buffer.Entries(filter))
+	}
+}
+
+// RequestsReset operates as a http handler, to drop all captured requests.
+func RequestsReset(buffer *capture.Buffer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buffer.Reset()
+
+		web.Success(w, http.StatusNoContent, nil)
+	}
+}
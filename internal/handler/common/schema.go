@@ -0,0 +1,65 @@
+package common
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/schema"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// Schema operates as a http handler, to list the JSON Schemas of the records of the resources, inferred from
+// their current records. The values of the secret fields of each resource are never listed as enums.
+func Schema(resources map[string]storage.Storage, secretFields map[string][]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		schemas := make(map[string]interface{})
+
+		for resourceKey, storageSvc := range resources {
+			records, err := storageSvc.Find()
+			if err != nil {
+				web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+				return
+			}
+
+			schemas[resourceKey] = schema.JSONSchema(inferSchema(records, secretFields[resourceKey]))
+		}
+
+		web.Success(w, http.StatusOK, schemas)
+	}
+}
+
+// SchemaResource operates as a http handler, to get the JSON Schema of the records of a resource. The values of
+// the secret fields of the resource are never listed as enums.
+func SchemaResource(resources map[string]storage.Storage, secretFields map[string][]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resourceKey := mux.Vars(r)["resource"]
+
+		storageSvc, ok := resources[resourceKey]
+		if !ok {
+			web.Error(w, http.StatusNotFound, storage.ErrResourceNotFound.Error())
+			return
+		}
+
+		records, err := storageSvc.Find()
+		if err != nil {
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		web.Success(w, http.StatusOK, schema.JSONSchema(inferSchema(records, secretFields[resourceKey])))
+	}
+}
+
+// inferSchema returns the type of the records, without the observed values of the secret fields.
+func inferSchema(records []storage.Resource, secretFields []string) *schema.Type {
+	t := schema.Infer(records)
+	for _, field := range secretFields {
+		if f, ok := t.Fields[field]; ok {
+			f.Type.OmitEnum()
+		}
+	}
+
+	return t
+}
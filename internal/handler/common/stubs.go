@@ -0,0 +1,67 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/stub"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var (
+	errInvalidStub = errors.New("body must be a stub with a 'path' and 'response'")
+	errStubMissing = errors.New("stub not found")
+)
+
+// Stubs operates as a http handler, to list the registered stubs, in the order they respond.
+func Stubs(registry *stub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		web.Success(w, http.StatusOK, registry.Stubs())
+	}
+}
+
+// StubsAdd operates as a http handler, to register a stub, e.g. '{"method": "GET", "path": "/users",
+// "times": 1, "response": {"status": 503}}' to fail the next request only.
+func StubsAdd(registry *stub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var s stub.Stub
+		if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+			web.Error(w, http.StatusBadRequest, errInvalidStub.Error())
+			return
+		}
+
+		added, err := registry.Add(s)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		web.Success(w, http.StatusCreated, added)
+	}
+}
+
+// StubsRemove operates as a http handler, to drop the stub with the provided id.
+func StubsRemove(registry *stub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil || !registry.Remove(id) {
+			web.Error(w, http.StatusNotFound, errStubMissing.Error())
+			return
+		}
+
+		web.Success(w, http.StatusNoContent, nil)
+	}
+}
+
+// StubsReset operates as a http handler, to drop all registered stubs.
+func StubsReset(registry *stub.Registry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry.Reset()
+
+		web.Success(w, http.StatusNoContent, nil)
+	}
+}
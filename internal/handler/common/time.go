@@ -0,0 +1,110 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/clock"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var (
+	errInvalidTime     = errors.New("body must contain a 'now' field in RFC 3339 format")
+	errInvalidDuration = errors.New("body must contain a 'duration' field, e.g. '1h30m'")
+)
+
+type timeResponse struct {
+	Now    string `json:"now"`
+	Frozen bool   `json:"frozen"`
+}
+
+// Time operates as a http handler, to retrieve the current time of the mock clock.
+func Time(c *clock.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondTime(w, c)
+	}
+}
+
+// TimeSet operates as a http handler, to set the mock clock to the time of the 'now' body field.
+func TimeSet(c *clock.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Now string `json:"now"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			web.Error(w, http.StatusBadRequest, errInvalidTime.Error())
+			return
+		}
+
+		now, err := time.Parse(time.RFC3339Nano, req.Now)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, errInvalidTime.Error())
+			return
+		}
+
+		c.Set(now)
+
+		respondTime(w, c)
+	}
+}
+
+// TimeAdvance operates as a http handler, to move the mock clock forward by the 'duration' body field.
+func TimeAdvance(c *clock.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Duration string `json:"duration"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			web.Error(w, http.StatusBadRequest, errInvalidDuration.Error())
+			return
+		}
+
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, errInvalidDuration.Error())
+			return
+		}
+
+		c.Advance(duration)
+
+		respondTime(w, c)
+	}
+}
+
+// TimeFreeze operates as a http handler, to stop the mock clock.
+func TimeFreeze(c *clock.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c.Freeze()
+
+		respondTime(w, c)
+	}
+}
+
+// TimeUnfreeze operates as a http handler, to let the mock clock run again.
+func TimeUnfreeze(c *clock.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c.Unfreeze()
+
+		respondTime(w, c)
+	}
+}
+
+// TimeReset operates as a http handler, to make the mock clock follow the system time again.
+func TimeReset(c *clock.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c.Reset()
+
+		respondTime(w, c)
+	}
+}
+
+func respondTime(w http.ResponseWriter, c *clock.Clock) {
+	web.Success(w, http.StatusOK, timeResponse{
+		Now:    c.Now().UTC().Format(time.RFC3339Nano),
+		Frozen: c.Frozen(),
+	})
+}
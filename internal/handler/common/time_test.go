@@ -0,0 +1,104 @@
+package common_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTime(t *testing.T) {
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		now        string
+		frozen     bool
+	}{
+		{
+			name:       "Freeze time",
+			method:     http.MethodPost,
+			path:       "/__time/freeze",
+			statusCode: http.StatusOK,
+			frozen:     true,
+		},
+		{
+			name:       "Set time",
+			method:     http.MethodPut,
+			path:       "/__time",
+			body:       `{"now": "2020-01-01T00:00:00Z"}`,
+			statusCode: http.StatusOK,
+			now:        "2020-01-01T00:00:00Z",
+			frozen:     true,
+		},
+		{
+			name:       "Advance time",
+			method:     http.MethodPost,
+			path:       "/__time/advance",
+			body:       `{"duration": "1h30m"}`,
+			statusCode: http.StatusOK,
+			now:        "2020-01-01T01:30:00Z",
+			frozen:     true,
+		},
+		{
+			name:       "Get time",
+			method:     http.MethodGet,
+			path:       "/__time",
+			statusCode: http.StatusOK,
+			now:        "2020-01-01T01:30:00Z",
+			frozen:     true,
+		},
+		{
+			name:       "Advance time with invalid duration",
+			method:     http.MethodPost,
+			path:       "/__time/advance",
+			body:       `{"duration": "soon"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Reset time",
+			method:     http.MethodPost,
+			path:       "/__time/reset",
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, mockServer.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode == http.StatusOK {
+			var got struct {
+				Now    string `json:"now"`
+				Frozen bool   `json:"frozen"`
+			}
+
+			if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.now != "" && got.Now != tt.now {
+				t.Fatalf("%s: expected now %v, but got %v", tt.name, tt.now, got.Now)
+			}
+
+			if got.Frozen != tt.frozen {
+				t.Fatalf("%s: expected frozen %v, but got %v", tt.name, tt.frozen, got.Frozen)
+			}
+		}
+
+		resp.Body.Close()
+	}
+}
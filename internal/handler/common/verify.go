@@ -0,0 +1,62 @@
+package common
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var errInvalidExpectations = errors.New("body must contain an expectation, or a list of expectations")
+
+type verifyResponse struct {
+	Passed  bool             `json:"passed"`
+	Results []capture.Result `json:"results"`
+}
+
+// Verify operates as a http handler, to check expectations against the captured requests, e.g.
+// '{"method": "GET", "path": "/users", "query": {"role": "admin"}, "count": 2}'. The body holds an
+// expectation, or a list of expectations.
+func Verify(buffer *capture.Buffer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, errInvalidExpectations.Error())
+			return
+		}
+
+		expectations := make([]capture.Expectation, 0)
+
+		bodyBytes = bytes.TrimSpace(bodyBytes)
+		if bytes.HasPrefix(bodyBytes, []byte("[")) {
+			err = json.Unmarshal(bodyBytes, &expectations)
+		} else {
+			var expectation capture.Expectation
+			err = json.Unmarshal(bodyBytes, &expectation)
+			expectations = append(expectations, expectation)
+		}
+
+		if err != nil || len(expectations) == 0 {
+			web.Error(w, http.StatusBadRequest, errInvalidExpectations.Error())
+			return
+		}
+
+		entries := buffer.Entries(capture.Filter{})
+
+		resp := verifyResponse{Passed: true, Results: make([]capture.Result, 0, len(expectations))}
+		for _, expectation := range expectations {
+			result := capture.Verify(entries, expectation)
+			if !result.Passed {
+				resp.Passed = false
+			}
+
+			resp.Results = append(resp.Results, result)
+		}
+
+		web.Success(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestCompression(t *testing.T) {
+	data := storage.Database{"posts": make([]storage.Resource, 0), "tags": {{"id": "1"}}}
+	for i := 1; i <= 100; i++ {
+		data["posts"] = append(data["posts"], storage.Resource{"id": fmt.Sprint(i), "title": fmt.Sprintf("title-%d", i)})
+	}
+
+	db := storage.NewMemoryDB(data)
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"posts", "tags"} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithCompression()))
+	defer server.Close()
+
+	// Keep the responses as they are sent.
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+
+	testCases := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		encoding       string
+	}{
+		{
+			name:           "Prefer brotli",
+			path:           "/posts",
+			acceptEncoding: "gzip, deflate, br",
+			encoding:       "br",
+		},
+		{
+			name:           "Respect quality values",
+			path:           "/posts",
+			acceptEncoding: "br;q=0.5, gzip",
+			encoding:       "gzip",
+		},
+		{
+			name:           "Skip unsupported encodings",
+			path:           "/posts",
+			acceptEncoding: "compress",
+		},
+		{
+			name:           "Skip small responses",
+			path:           "/tags",
+			acceptEncoding: "br",
+		},
+		{
+			name:           "Compress streamed responses",
+			path:           "/posts/_export",
+			acceptEncoding: "gzip",
+			encoding:       "gzip",
+		},
+	}
+
+	expected := make(map[string]string)
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if encoding := resp.Header.Get("Content-Encoding"); encoding != tt.encoding {
+			t.Fatalf("%s: expected header Content-Encoding %v, but got %v", tt.name, tt.encoding, encoding)
+		}
+
+		if vary := resp.Header.Get("Vary"); vary != "Accept-Encoding" {
+			t.Fatalf("%s: expected header Vary %v, but got %v", tt.name, "Accept-Encoding", vary)
+		}
+
+		raw, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		// Buffered responses are sent with the length of the compressed body.
+		if tt.encoding != "" && tt.path != "/posts/_export" {
+			if length := resp.Header.Get("Content-Length"); length != strconv.Itoa(len(raw)) {
+				t.Fatalf("%s: expected header Content-Length %v, but got %v", tt.name, len(raw), length)
+			}
+		}
+
+		var reader io.Reader = strings.NewReader(string(raw))
+		switch tt.encoding {
+		case "br":
+			reader = brotli.NewReader(reader)
+		case "gzip":
+			if reader, err = gzip.NewReader(reader); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%s: expected %v body, but got error %v", tt.name, tt.encoding, err)
+		}
+
+		if prev, ok := expected[tt.path]; ok && prev != string(body) {
+			t.Fatalf("%s: expected body %v, but got %v", tt.name, prev, string(body))
+		}
+
+		expected[tt.path] = string(body)
+	}
+}
@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestStrictContentType(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "first"}},
+	}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, handler.WithStrictContentType()))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		statusCode  int
+		noBody      bool
+	}{
+		{name: "Read", method: http.MethodGet, path: "/posts/1", statusCode: http.StatusOK},
+		{name: "Create with json", method: http.MethodPost, path: "/posts", contentType: "application/json", body: `{"title": "second"}`, statusCode: http.StatusCreated},
+		{name: "Create with json and charset", method: http.MethodPost, path: "/posts", contentType: "application/json; charset=UTF-8", body: `{"title": "third"}`, statusCode: http.StatusCreated},
+		{name: "Update with json suffix", method: http.MethodPatch, path: "/posts/1", contentType: "application/merge-patch+json", body: `{"title": "updated"}`, statusCode: http.StatusOK},
+		{name: "Create without content type", method: http.MethodPost, path: "/posts", body: `{"title": "fourth"}`, statusCode: http.StatusUnsupportedMediaType},
+		{name: "Create with form", method: http.MethodPost, path: "/posts", contentType: "application/x-www-form-urlencoded", body: "title=fourth", statusCode: http.StatusUnsupportedMediaType},
+		{name: "Replace with other charset", method: http.MethodPut, path: "/posts/1", contentType: "application/json; charset=latin1", body: `{"title": "fourth"}`, statusCode: http.StatusUnsupportedMediaType},
+		{name: "Delete without body", method: http.MethodDelete, path: "/posts/1", statusCode: http.StatusOK, noBody: true},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if got := resp.Header.Get("Content-Type"); !tt.noBody && got != "application/json; charset=utf-8" {
+			t.Fatalf("%s: expected content type with charset, but got %q", tt.name, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/config"
+)
+
+// cors is operating as middleware to allow the cross-origin requests of browsers, following the rules of the
+// resource named by the first segment of the request path, or the rules for all resources. Requests of origins
+// without rules get no cors headers, so browsers allow same-origin requests only. Preflight requests are answered
+// before routing, and fail with 403 unless the origin, method and headers are allowed.
+func cors(rules map[string]config.CORS, fallback *config.CORS, aliases map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Origin")
+
+			resourceKey := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+			if key, ok := aliases[resourceKey]; ok {
+				resourceKey = key
+			}
+
+			rule, ok := rules[resourceKey]
+			if !ok && fallback != nil {
+				rule, ok = *fallback, true
+			}
+
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if !preflight {
+				if ok && allowedOrigin(rule, origin) && allowedMethod(rule, r.Method) {
+					setAllowOrigin(w, rule, origin)
+
+					if len(rule.Expose) > 0 {
+						w.Header().Set("Access-Control-Expose-Headers", strings.Join(rule.Expose, ", "))
+					}
+				}
+
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+			method := r.Header.Get("Access-Control-Request-Method")
+			requested := r.Header.Get("Access-Control-Request-Headers")
+
+			if !ok || !allowedOrigin(rule, origin) || !allowedMethod(rule, method) || !allowedHeaders(rule, requested) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			setAllowOrigin(w, rule, origin)
+			w.Header().Set("Access-Control-Allow-Methods", strings.ToUpper(method))
+
+			if requested != "" {
+				w.Header().Set("Access-Control-Allow-Headers", requested)
+			}
+
+			if rule.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(time.Duration(rule.MaxAge)/time.Second)))
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// setAllowOrigin allows the origin to read the response. Any origin is allowed with '*', unless credentials are,
+// which browsers only accept along the exact origin.
+func setAllowOrigin(w http.ResponseWriter, rule config.CORS, origin string) {
+	if rule.Credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		return
+	}
+
+	for _, allowed := range rule.Origins {
+		if allowed == "*" {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+			return
+		}
+	}
+
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+}
+
+// allowedOrigin reports whether the rule allows the origin. Origins are compared case-insensitively, ignoring
+// a trailing slash of the rule.
+func allowedOrigin(rule config.CORS, origin string) bool {
+	for _, allowed := range rule.Origins {
+		if allowed == "*" || strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedMethod reports whether the rule allows the method, any method if the rule has none.
+func allowedMethod(rule config.CORS, method string) bool {
+	if len(rule.Methods) == 0 {
+		return true
+	}
+
+	for _, allowed := range rule.Methods {
+		if strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedHeaders reports whether the rule allows all the comma-separated request headers, any header if the
+// rule has none.
+func allowedHeaders(rule config.CORS, requested string) bool {
+	if len(rule.Headers) == 0 || requested == "" {
+		return true
+	}
+
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			continue
+		}
+
+		allowed := false
+		for _, h := range rule.Headers {
+			if strings.EqualFold(h, header) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return false
+		}
+	}
+
+	return true
+}
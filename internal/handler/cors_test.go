@@ -0,0 +1,234 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestCORS(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{
+		"cors": {"origins": ["*"], "methods": ["GET"]},
+		"resources": {
+			"users": {"aliases": ["people"], "cors": {"origins": ["https://app.example.com"], "credentials": true, "headers": ["Authorization"], "expose": ["Location"], "maxAge": "10m"}},
+			"orders": {"cors": {}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.NewMemoryDB(storage.Database{
+		"posts":  {{"id": "1"}},
+		"users":  {{"id": "1"}},
+		"orders": {{"id": "1"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"posts", "users", "orders"} {
+		if resourceStorage[resourceKey], err = storage.NewMemory(db, resourceKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	testCases := []struct {
+		name          string
+		method        string
+		path          string
+		header        http.Header
+		statusCode    int
+		allowOrigin   string
+		allowCreds    string
+		allowHeaders  string
+		exposeHeaders string
+		maxAge        string
+	}{
+		{
+			name:       "Request without origin",
+			method:     http.MethodGet,
+			path:       "/posts",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:        "Request allowed for all resources",
+			method:      http.MethodGet,
+			path:        "/posts",
+			header:      http.Header{"Origin": {"https://other.example.com"}},
+			statusCode:  http.StatusOK,
+			allowOrigin: "*",
+		},
+		{
+			name:       "Request with method not allowed for all resources",
+			method:     http.MethodDelete,
+			path:       "/posts/1",
+			header:     http.Header{"Origin": {"https://other.example.com"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:          "Request allowed for resource",
+			method:        http.MethodGet,
+			path:          "/users",
+			header:        http.Header{"Origin": {"https://app.example.com"}},
+			statusCode:    http.StatusOK,
+			allowOrigin:   "https://app.example.com",
+			allowCreds:    "true",
+			exposeHeaders: "Location",
+		},
+		{
+			name:          "Request allowed for resource by alias",
+			method:        http.MethodGet,
+			path:          "/people/1",
+			header:        http.Header{"Origin": {"https://app.example.com"}},
+			statusCode:    http.StatusOK,
+			allowOrigin:   "https://app.example.com",
+			allowCreds:    "true",
+			exposeHeaders: "Location",
+		},
+		{
+			name:       "Request of origin not allowed for resource",
+			method:     http.MethodGet,
+			path:       "/users",
+			header:     http.Header{"Origin": {"https://other.example.com"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Request of same-origin resource",
+			method:     http.MethodGet,
+			path:       "/orders",
+			header:     http.Header{"Origin": {"https://app.example.com"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:   "Preflight allowed for resource",
+			method: http.MethodOptions,
+			path:   "/users/1",
+			header: http.Header{
+				"Origin":                         {"https://app.example.com"},
+				"Access-Control-Request-Method":  {"PATCH"},
+				"Access-Control-Request-Headers": {"authorization"},
+			},
+			statusCode:   http.StatusNoContent,
+			allowOrigin:  "https://app.example.com",
+			allowCreds:   "true",
+			allowHeaders: "authorization",
+			maxAge:       "600",
+		},
+		{
+			name:   "Preflight with header not allowed",
+			method: http.MethodOptions,
+			path:   "/users/1",
+			header: http.Header{
+				"Origin":                         {"https://app.example.com"},
+				"Access-Control-Request-Method":  {"PATCH"},
+				"Access-Control-Request-Headers": {"X-Custom"},
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:   "Preflight with method not allowed",
+			method: http.MethodOptions,
+			path:   "/posts/1",
+			header: http.Header{
+				"Origin":                        {"https://other.example.com"},
+				"Access-Control-Request-Method": {"DELETE"},
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name:   "Preflight of same-origin resource",
+			method: http.MethodOptions,
+			path:   "/orders",
+			header: http.Header{
+				"Origin":                        {"https://app.example.com"},
+				"Access-Control-Request-Method": {"GET"},
+			},
+			statusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for key, values := range tt.header {
+			req.Header[key] = values
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, res.StatusCode)
+		}
+
+		expectedHeaders := map[string]string{
+			"Access-Control-Allow-Origin":      tt.allowOrigin,
+			"Access-Control-Allow-Credentials": tt.allowCreds,
+			"Access-Control-Allow-Headers":     tt.allowHeaders,
+			"Access-Control-Expose-Headers":    tt.exposeHeaders,
+			"Access-Control-Max-Age":           tt.maxAge,
+		}
+
+		for key, expected := range expectedHeaders {
+			if got := res.Header.Get(key); got != expected {
+				t.Fatalf("%s: expected header %s %q, but got %q", tt.name, key, expected, got)
+			}
+		}
+	}
+}
+
+func TestCORS_AllowOrigins(t *testing.T) {
+	data := storage.Database{"posts": {{"id": "1"}}}
+
+	resourceStorage := make(map[string]storage.Storage)
+	for resourceKey := range data {
+		storageSvc, err := storage.NewMock(data, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithAllowOrigins("https://app.example.com")))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		origin      string
+		allowOrigin string
+	}{
+		{name: "Allowed origin", origin: "https://app.example.com", allowOrigin: "https://app.example.com"},
+		{name: "Other origin", origin: "https://other.example.com"},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/posts", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", tt.origin)
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if got := res.Header.Get("Access-Control-Allow-Origin"); got != tt.allowOrigin {
+			t.Fatalf("%s: expected header Access-Control-Allow-Origin %q, but got %q", tt.name, tt.allowOrigin, got)
+		}
+	}
+}
@@ -1,20 +1,35 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/chanioxaris/json-server/internal/storage"
 	"github.com/chanioxaris/json-server/internal/web"
 )
 
-// Create operates as a http handler, to add a new resource.
-func Create(storageSvc storage.Storage) http.HandlerFunc {
+// Create operates as a http handler, to add a new resource, responding with its location. The prepare
+// functions adjust the new resource before it is stored. A json array body adds all its resources at once,
+// or none of them if any fails.
+func Create(storageSvc storage.Storage, prepare ...prepareFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		resources, batch, err := decodeBatch(r)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+			return
+		}
+
+		if batch {
+			createBatch(w, r, storageSvc, resources, prepare)
+			return
+		}
+
 		// Read and decode request body.
-		var newResource storage.Resource
-		if err := json.NewDecoder(r.Body).Decode(&newResource); err != nil {
+		newResource, err := decodeResource(r)
+		if err != nil {
 			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
 			return
 		}
@@ -25,8 +40,12 @@ func Create(storageSvc storage.Storage) http.HandlerFunc {
 			return
 		}
 
+		for _, fn := range prepare {
+			fn(r, newResource)
+		}
+
 		// Create the new resource.
-		data, err := storageSvc.Create(newResource)
+		data, err := traced(r, storageSvc).Create(newResource)
 		if err != nil {
 			// Already exists with the requested id.
 			if errors.Is(err, storage.ErrResourceAlreadyExists) {
@@ -34,10 +53,39 @@ func Create(storageSvc storage.Storage) http.HandlerFunc {
 				return
 			}
 
+			// Duplicate value of a unique field.
+			if errors.Is(err, storage.ErrDuplicateValue) {
+				web.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+
+			// Missing required fields.
+			if errors.Is(err, storage.ErrMissingFields) {
+				web.Error(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+
+			// Fields which only differ in case.
+			if errors.Is(err, storage.ErrConflictingFields) {
+				web.Error(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+
+			// Watch file changed by another writer meanwhile.
+			if errors.Is(err, storage.ErrFileChanged) {
+				web.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+
 			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
 			return
 		}
 
-		web.Success(w, http.StatusCreated, data)
+		// Point to the created resource, so clients can follow it.
+		if id, ok := data["id"]; ok {
+			w.Header().Set("Location", strings.TrimSuffix(r.URL.Path, "/")+"/"+url.PathEscape(fmt.Sprint(id)))
+		}
+
+		respondWritten(w, r, http.StatusCreated, data)
 	}
 }
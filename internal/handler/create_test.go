@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/chanioxaris/json-server/internal/storage"
@@ -112,6 +115,10 @@ func TestCreate(t *testing.T) {
 				t.Fatal(err)
 			}
 
+			if location, expected := resp.Header.Get("Location"), fmt.Sprintf("/%s/%v", tt.key, got["id"]); location != expected {
+				t.Fatalf("expected header Location %v, but got %v", expected, location)
+			}
+
 			resources, err := testListResourcesByKey(tt.key)
 			if err != nil {
 				t.Fatal(err)
@@ -132,3 +139,130 @@ func TestCreate(t *testing.T) {
 		}
 	}
 }
+
+func TestCreateForm(t *testing.T) {
+	randomKeyIndex := rand.Intn(len(testResourceKeys))
+	randomKey := testResourceKeys[randomKeyIndex]
+
+	testCases := []struct {
+		name       string
+		statusCode int
+		key        string
+		form       url.Values
+		expected   storage.Resource
+	}{
+		{
+			name:       "Create resource from form with coerced values",
+			statusCode: http.StatusCreated,
+			key:        randomKey,
+			form: url.Values{
+				"id":        {"form-2020"},
+				"field_1":   {"new-field_1"},
+				"published": {"true"},
+				"count":     {"42"},
+				"code":      {"007"},
+				"tags[]":    {"a"},
+				"ratings":   {"1.5", "2"},
+			},
+			expected: storage.Resource{
+				"id":        "form-2020",
+				"field_1":   "new-field_1",
+				"published": true,
+				"count":     float64(42),
+				"code":      "007",
+				"tags":      []interface{}{"a"},
+				"ratings":   []interface{}{1.5, float64(2)},
+			},
+		},
+		{
+			name:       "Create resource from form with non finite numbers",
+			statusCode: http.StatusCreated,
+			key:        randomKey,
+			form: url.Values{
+				"id":      {"form-2021"},
+				"nan":     {"NaN"},
+				"inf":     {"+Inf"},
+				"neg_inf": {"-Inf"},
+			},
+			expected: storage.Resource{
+				"id":      "form-2021",
+				"nan":     "NaN",
+				"inf":     "+Inf",
+				"neg_inf": "-Inf",
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		testResetData(tt.key)
+
+		url := fmt.Sprintf("%s/%s", mockServer.URL, tt.key)
+
+		resp, err := http.PostForm(url, tt.form)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("expected status code %v, but got %v", tt.statusCode, resp.StatusCode)
+		}
+
+		var got storage.Resource
+		if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Fatalf("expected body %v, but got %v", tt.expected, got)
+		}
+	}
+}
+
+func TestCreatePreciseNumbers(t *testing.T) {
+	randomKeyIndex := rand.Intn(len(testResourceKeys))
+	randomKey := testResourceKeys[randomKeyIndex]
+
+	testResetData(randomKey)
+
+	url := fmt.Sprintf("%s/%s", mockServer.URL, randomKey)
+	body := `{"id": "precise-1", "ref": 12345678901234567890, "amount": 1234567.123456789012345}`
+
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	resp, err = http.Get(url + "/precise-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	got, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, number := range []string{"12345678901234567890", "1234567.123456789012345"} {
+		if !strings.Contains(string(got), number) {
+			t.Fatalf("expected body with number %s, but got %s", number, got)
+		}
+	}
+
+	// Delete the resource, as the number types differ once decoded by other tests.
+	req, err := http.NewRequest(http.MethodDelete, url+"/precise-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+const (
+	// defaultCursorLimit is the page size of cursor pagination without '_limit'.
+	defaultCursorLimit = 10
+	// nextCursorHeader is the response header holding the cursor of the next page.
+	nextCursorHeader = "X-Next-Cursor"
+)
+
+// cursor points after the last resource of a page. When that resource no longer exists, the next page starts
+// at the resource that followed it, or else at the position.
+type cursor struct {
+	After    string `json:"after"`
+	Next     string `json:"next"`
+	Position int    `json:"pos"`
+}
+
+// cursorPage is the response of cursor paginated list requests, when an envelope is requested.
+type cursorPage struct {
+	Data       []storage.Resource `json:"data"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+}
+
+// usesCursor reports whether the list request asks for cursor pagination, either with a '_cursor' (empty
+// for the first page) or with a '_limit' without '_page' or '_start'.
+func usesCursor(query url.Values) bool {
+	if _, ok := query["_cursor"]; ok {
+		return true
+	}
+
+	_, page := query["_page"]
+	_, start := query["_start"]
+
+	return query.Get("_limit") != "" && !page && !start
+}
+
+// paginateCursor returns the page of resources after the cursor, and the cursor of the next page, which
+// is empty on the last page.
+func paginateCursor(resources []storage.Resource, query url.Values) ([]storage.Resource, string, error) {
+	limit := defaultCursorLimit
+	if limitParam := query.Get("_limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 {
+			return nil, "", fmt.Errorf("%w: _limit", errInvalidQuery)
+		}
+	}
+
+	start := 0
+	if token := query.Get("_cursor"); token != "" {
+		c, err := decodeCursor(token)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: _cursor", errInvalidQuery)
+		}
+
+		start = c.Position
+		for idx, resource := range resources {
+			id := fmt.Sprint(resource["id"])
+			if id == c.After {
+				start = idx + 1
+				break
+			}
+
+			if id == c.Next {
+				start = idx
+			}
+		}
+	}
+
+	if start > len(resources) {
+		start = len(resources)
+	}
+
+	end := start + limit
+	if end >= len(resources) {
+		return resources[start:], "", nil
+	}
+
+	next, err := encodeCursor(cursor{
+		After:    fmt.Sprint(resources[end-1]["id"]),
+		Next:     fmt.Sprint(resources[end]["id"]),
+		Position: end,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resources[start:end], next, nil
+}
+
+func encodeCursor(c cursor) (string, error) {
+	cursorBytes, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(cursorBytes), nil
+}
+
+func decodeCursor(token string) (cursor, error) {
+	var c cursor
+
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, err
+	}
+
+	if err = json.Unmarshal(cursorBytes, &c); err != nil {
+		return c, err
+	}
+
+	if c.Position < 0 {
+		return c, errInvalidQuery
+	}
+
+	return c, nil
+}
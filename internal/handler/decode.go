@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// decodeResource reads the request body into a resource. Besides json, form-encoded bodies are
+// supported, with their values coerced to booleans, numbers and null where they look like one.
+// The id is never coerced, as ids are always strings.
+func decodeResource(r *http.Request) (storage.Resource, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	if mediaType == "application/x-www-form-urlencoded" {
+		if err := r.ParseForm(); err != nil {
+			return nil, err
+		}
+
+		resource := make(storage.Resource)
+		for key, values := range r.PostForm {
+			// Keys like 'tags[]' always hold an array.
+			if strings.HasSuffix(key, "[]") {
+				resource[strings.TrimSuffix(key, "[]")] = coerceFormValues(values)
+				continue
+			}
+
+			if key == "id" {
+				resource[key] = values[0]
+				continue
+			}
+
+			if len(values) == 1 {
+				resource[key] = coerceFormValue(values[0])
+				continue
+			}
+
+			resource[key] = coerceFormValues(values)
+		}
+
+		return resource, nil
+	}
+
+	var resource storage.Resource
+	if err := storage.Decode(json.NewDecoder(r.Body), &resource); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// coerceFormValues converts every form value.
+func coerceFormValues(values []string) []interface{} {
+	coerced := make([]interface{}, 0, len(values))
+	for _, val := range values {
+		coerced = append(coerced, coerceFormValue(val))
+	}
+
+	return coerced
+}
+
+// coerceFormValue converts a form value to the json type it looks like.
+func coerceFormValue(val string) interface{} {
+	switch val {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+
+	// Numbers are decoded as float64, the same as json numbers. Values like '007' keep their formatting,
+	// and values like 'NaN' or '+Inf', which have no json representation, are kept as strings.
+	f, err := strconv.ParseFloat(val, 64)
+	if err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) && strconv.FormatFloat(f, 'f', -1, 64) == val {
+		return f
+	}
+
+	return val
+}
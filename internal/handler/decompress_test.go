@@ -0,0 +1,125 @@
+package handler_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestDecompress(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": {}}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	compress := func(newWriter func(w io.Writer) io.WriteCloser, body string) []byte {
+		var buf bytes.Buffer
+
+		zw := newWriter(&buf)
+		if _, err := zw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.Bytes()
+	}
+
+	gzipWriter := func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) }
+	zlibWriter := func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) }
+	flateWriter := func(w io.Writer) io.WriteCloser {
+		fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+		return fw
+	}
+
+	testCases := []struct {
+		name       string
+		encoding   string
+		body       []byte
+		statusCode int
+		title      string
+	}{
+		{
+			name:       "Create resource from gzip body",
+			encoding:   "gzip",
+			body:       compress(gzipWriter, `{"id": "1", "title": "gzip"}`),
+			statusCode: http.StatusCreated,
+			title:      "gzip",
+		},
+		{
+			name:       "Create resource from zlib deflate body",
+			encoding:   "deflate",
+			body:       compress(zlibWriter, `{"id": "2", "title": "zlib"}`),
+			statusCode: http.StatusCreated,
+			title:      "zlib",
+		},
+		{
+			name:       "Create resource from raw deflate body",
+			encoding:   "deflate",
+			body:       compress(flateWriter, `{"id": "3", "title": "flate"}`),
+			statusCode: http.StatusCreated,
+			title:      "flate",
+		},
+		{
+			name:       "Create resource from body with multiple encodings",
+			encoding:   "deflate, gzip",
+			body:       compress(gzipWriter, string(compress(zlibWriter, `{"id": "4", "title": "both"}`))),
+			statusCode: http.StatusCreated,
+			title:      "both",
+		},
+		{
+			name:       "Create resource from invalid gzip body",
+			encoding:   "gzip",
+			body:       []byte(`{"id": "5"}`),
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Create resource from unsupported encoding",
+			encoding:   "compress",
+			body:       []byte(`{"id": "6"}`),
+			statusCode: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodPost, server.URL+"/posts", bytes.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", tt.encoding)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got storage.Resource
+		// nolint
+		json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.title != "" && got["title"] != tt.title {
+			t.Fatalf("%s: expected title %v, but got %v", tt.name, tt.title, got["title"])
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/expr"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// prepareFunc adjusts a decoded resource of a create request, before it is stored.
+type prepareFunc func(r *http.Request, resource storage.Resource)
+
+// applyDefaults assigns the default values to the fields a resource omits. String values are templates,
+// evaluated per request with the variables 'auth' (the claims of the access token), 'now' (the current
+// time of the mock clock) and 'body' (the request body). Fields whose template fails are null.
+func applyDefaults(defaults map[string]interface{}, now func() time.Time) prepareFunc {
+	templates := make(map[string]*expr.Template)
+	for field, val := range defaults {
+		src, ok := val.(string)
+		if !ok {
+			continue
+		}
+
+		tmpl, err := expr.ParseTemplate(src)
+		if err != nil {
+			continue
+		}
+
+		templates[field] = tmpl
+	}
+
+	return func(r *http.Request, resource storage.Resource) {
+		var vars map[string]interface{}
+		if len(templates) > 0 {
+			vars = defaultsVars(r, resource, now)
+		}
+
+		for field, val := range defaults {
+			if _, ok := resource[field]; ok {
+				continue
+			}
+
+			tmpl, ok := templates[field]
+			if !ok {
+				resource[field] = val
+				continue
+			}
+
+			val, err := tmpl.Eval(vars)
+			if err != nil {
+				val = nil
+			}
+
+			resource[field] = val
+		}
+	}
+}
+
+// defaultsVars returns the variables available to the templates of default values.
+func defaultsVars(r *http.Request, resource storage.Resource, now func() time.Time) map[string]interface{} {
+	body := make(map[string]interface{}, len(resource))
+	for key, val := range resource {
+		body[key] = val
+	}
+
+	vars := map[string]interface{}{
+		"now":  now().UTC().Format(time.RFC3339Nano),
+		"body": body,
+	}
+
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+		vars["auth"] = map[string]interface{}(claims)
+	}
+
+	return vars
+}
@@ -17,13 +17,19 @@ func Delete(storageSvc storage.Storage) http.HandlerFunc {
 		id := mux.Vars(r)["id"]
 
 		// Delete resource.
-		if err := storageSvc.Delete(id); err != nil {
+		if err := traced(r, storageSvc).Delete(id); err != nil {
 			// Resource not found.
 			if errors.Is(err, storage.ErrResourceNotFound) {
 				web.Error(w, http.StatusNotFound, err.Error())
 				return
 			}
 
+			// Watch file changed by another writer meanwhile.
+			if errors.Is(err, storage.ErrFileChanged) {
+				web.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+
 			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
 			return
 		}
@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/events"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+const (
+	// eventsHistorySize is the number of events kept for the clients reconnecting to the stream.
+	eventsHistorySize = 256
+	// eventsRetry is the delay in milliseconds after which clients reconnect to the stream once disconnected.
+	eventsRetry = 1000
+	// eventsHeartbeat is the interval of the comments sent to idle streams, so proxies and clients do not drop them.
+	eventsHeartbeat = 15 * time.Second
+	// eventsWriteTimeout bounds every write to the stream, replacing the write timeout of the server, which would
+	// end the stream, so clients which stopped reading are dropped.
+	eventsWriteTimeout = 30 * time.Second
+)
+
+// publishedStorage wraps a storage, and publishes the changes of the collection to the event stream.
+type publishedStorage struct {
+	storage.Storage
+	broker     *events.Broker
+	collection string
+	// pending holds the changes of a transaction, published once it succeeds.
+	pending *[]events.Event
+}
+
+func (s *publishedStorage) FindContext(ctx context.Context) ([]storage.Resource, error) {
+	return storage.FindContext(ctx, s.Storage)
+}
+
+func (s *publishedStorage) Create(newResource storage.Resource) (storage.Resource, error) {
+	created, err := s.Storage.Create(newResource)
+	if err == nil {
+		s.publish(events.ActionCreate, created["id"], created)
+	}
+
+	return created, err
+}
+
+func (s *publishedStorage) Replace(id string, replaced storage.Resource) (storage.Resource, error) {
+	resource, err := s.Storage.Replace(id, replaced)
+	if err == nil {
+		s.publish(events.ActionReplace, id, resource)
+	}
+
+	return resource, err
+}
+
+func (s *publishedStorage) Update(id string, updatedReq storage.Resource) (storage.Resource, error) {
+	updated, err := s.Storage.Update(id, updatedReq)
+	if err == nil {
+		s.publish(events.ActionUpdate, id, updated)
+	}
+
+	return updated, err
+}
+
+func (s *publishedStorage) Delete(id string) error {
+	err := s.Storage.Delete(id)
+	if err == nil {
+		s.publish(events.ActionDelete, id, nil)
+	}
+
+	return err
+}
+
+// Transaction publishes the changes of the transaction once it succeeds, and none of them if it fails.
+func (s *publishedStorage) Transaction(fn func(tx storage.Storage) error) error {
+	var pending []events.Event
+
+	err := storage.Transaction(s.Storage, func(tx storage.Storage) error {
+		return fn(&publishedStorage{Storage: tx, broker: s.broker, collection: s.collection, pending: &pending})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, event := range pending {
+		s.broker.Publish(event)
+	}
+
+	return nil
+}
+
+// publish the change of the resource, with a copy of its data, as the handlers may modify it once responded.
+func (s *publishedStorage) publish(action string, id interface{}, resource storage.Resource) {
+	var data map[string]interface{}
+	if resource != nil {
+		data = make(map[string]interface{}, len(resource))
+		for key, val := range resource {
+			data[key] = val
+		}
+
+		if resourceId, ok := resource["id"]; ok {
+			id = resourceId
+		}
+	}
+
+	event := events.Event{Resource: s.collection, Action: action, ID: id, Data: data}
+	if s.pending != nil {
+		*s.pending = append(*s.pending, event)
+		return
+	}
+
+	s.broker.Publish(event)
+}
+
+// Events operates as a http handler, to stream the changes of the resources as server-sent events, e.g. for
+// dashboards updating once a resource is created, changed or deleted through the API. The 'resource' query
+// parameter, repeated or comma separated, limits the stream to the changes of the resources. Clients
+// reconnecting with the Last-Event-ID header, as browsers do, are sent the changes they missed first, if still
+// kept. Idle streams are sent a comment as heartbeat every eventsHeartbeat.
+func Events(broker *events.Broker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		resources := make([]string, 0)
+		for _, values := range r.URL.Query()["resource"] {
+			for _, resource := range strings.Split(values, ",") {
+				if resource = strings.TrimSpace(resource); resource != "" {
+					resources = append(resources, resource)
+				}
+			}
+		}
+
+		// Clients connecting for the first time, or with an invalid id, are sent the new changes only.
+		lastEventId, err := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+		if err != nil || lastEventId < 0 {
+			lastEventId = -1
+		}
+
+		stream, seq, unsubscribe := broker.Subscribe(resources, lastEventId)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		// Disable the buffering of reverse proxies, like nginx.
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		// New clients are sent the id of the last event right away, so they catch up with the changes they miss
+		// once reconnected, even before any change was sent to them.
+		preamble := fmt.Sprintf("retry: %d\n\n", eventsRetry)
+		if lastEventId < 0 {
+			preamble = fmt.Sprintf("retry: %d\nid: %d\n\n", eventsRetry, seq)
+		}
+
+		// The stream outlives the write timeout of the server, so every write gets its own deadline instead.
+		send := func(format string, args ...interface{}) bool {
+			web.SetWriteDeadline(w, r, time.Now().Add(eventsWriteTimeout))
+
+			if _, err := fmt.Fprintf(w, format, args...); err != nil {
+				return false
+			}
+
+			flusher.Flush()
+
+			return true
+		}
+
+		if !send("%s", preamble) {
+			return
+		}
+
+		heartbeat := time.NewTicker(eventsHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if !send(":\n\n") {
+					return
+				}
+			case event, ok := <-stream:
+				// The client fell behind, it catches up once reconnected.
+				if !ok {
+					return
+				}
+
+				data, err := json.Marshal(event)
+				if err != nil {
+					continue
+				}
+
+				// The client is gone, there is no one to stream to.
+				if !send("id: %d\ndata: %s\n\n", event.Seq, data) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package handler_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestEvents(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"secrets": {"writeOnly": true}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.NewMemoryDB(storage.Database{
+		"posts":    {{"id": "1", "title": "json-server"}},
+		"comments": {{"id": "1"}},
+		"secrets":  {},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"posts", "comments", "secrets"} {
+		if resourceStorage[resourceKey], err = storage.NewMemory(db, resourceKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg), handler.WithCompression()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events?resource=posts,secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Fatalf("expected content type %q, but got %q", "text/event-stream", contentType)
+	}
+
+	received := make(chan map[string]interface{})
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if !strings.HasPrefix(scanner.Text(), "data: ") {
+				continue
+			}
+
+			var event map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(scanner.Text(), "data: ")), &event); err == nil {
+				received <- event
+			}
+		}
+	}()
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodPost, path: "/secrets", body: `{"id": "1"}`},
+		{method: http.MethodPost, path: "/comments", body: `{"id": "2"}`},
+		{method: http.MethodPost, path: "/posts", body: `{"id": "2", "title": "created"}`},
+		{method: http.MethodPatch, path: "/posts/2", body: `{"title": "updated"}`},
+		{method: http.MethodDelete, path: "/posts/1"},
+	}
+
+	for _, request := range requests {
+		req, err := http.NewRequest(request.method, server.URL+request.path, bytes.NewBufferString(request.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	testCases := []struct {
+		name   string
+		action string
+		id     string
+		title  interface{}
+	}{
+		{name: "Stream created resource", action: "create", id: "2", title: "created"},
+		{name: "Stream updated resource", action: "update", id: "2", title: "updated"},
+		{name: "Stream deleted resource", action: "delete", id: "1"},
+	}
+
+	for _, tt := range testCases {
+		select {
+		case event := <-received:
+			var title interface{}
+			if data, ok := event["data"].(map[string]interface{}); ok {
+				title = data["title"]
+			}
+
+			if event["resource"] != "posts" || event["action"] != tt.action || event["id"] != tt.id || title != tt.title {
+				t.Fatalf("%s: expected %s of posts %s, but got %v", tt.name, tt.action, tt.id, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: expected event, but got none", tt.name)
+		}
+	}
+}
+
+func TestEvents_WriteTimeout(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": {{"id": "1", "title": "json-server"}}}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc},
+		handler.WithCompression()))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	received := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "data: ") {
+				received <- scanner.Text()
+			}
+		}
+
+		close(received)
+	}()
+
+	// Hold the stream past the write timeout of the server.
+	time.Sleep(300 * time.Millisecond)
+
+	postResp, err := http.Post(server.URL+"/posts", "application/json", strings.NewReader(`{"id": "2", "title": "created"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	postResp.Body.Close()
+
+	select {
+	case event, ok := <-received:
+		if !ok || !strings.Contains(event, `"action":"create"`) {
+			t.Fatalf("expected the create event, but got %q", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the create event, but got none")
+	}
+}
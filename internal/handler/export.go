@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// exportFlushSize is the number of records written before flushing them to the client.
+const exportFlushSize = 100
+
+// Export operates as a http handler, to stream the resources as newline-delimited JSON, one record per line.
+// Records are encoded one at a time and flushed in chunks, so the response is never built in memory as a
+// whole, and a slow client blocks the writes instead of having them buffered. The list query parameters
+// filter the exported resources, and with strict queries, requests with ignored query parameters fail. The export
+// stops once the client is gone.
+func Export(storageSvc storage.Storage, strictQueries bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := storage.FindContext(r.Context(), traced(r, storageSvc))
+		if err != nil {
+			findError(w, r)
+			return
+		}
+
+		if !checkQuery(w, r, exportParams, data, strictQueries) {
+			return
+		}
+
+		total := len(data)
+		data, err = queryResources(data, r.URL.Query())
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		traceFilter(r, r.URL.Query(), total, len(data))
+
+		if clientGone(w, r) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+
+		flusher, _ := w.(http.Flusher)
+		encoder := json.NewEncoder(w)
+
+		for idx, resource := range data {
+			// The client is gone, there is no one to report to.
+			if err = encoder.Encode(resource); err != nil {
+				return
+			}
+
+			if (idx+1)%exportFlushSize != 0 {
+				continue
+			}
+
+			// Writes are buffered, so they may keep succeeding for a while after the client is gone.
+			if r.Context().Err() != nil {
+				return
+			}
+
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestExport(t *testing.T) {
+	data := storage.Database{"posts": make([]storage.Resource, 0)}
+	for i := 1; i <= 250; i++ {
+		data["posts"] = append(data["posts"], storage.Resource{"id": fmt.Sprint(i), "createdAt": i})
+	}
+
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(data), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+		records    int
+	}{
+		{
+			name:       "Export all resources",
+			statusCode: http.StatusOK,
+			records:    250,
+		},
+		{
+			name:       "Export filtered resources",
+			query:      "?createdAt_after=125",
+			statusCode: http.StatusOK,
+			records:    125,
+		},
+		{
+			name:       "Export with invalid filter",
+			query:      "?createdAt_after=invalid",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + "/posts/_export" + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		if header := resp.Header.Get("Content-Type"); header != "application/x-ndjson" {
+			t.Fatalf("%s: expected header Content-Type %v, but got %v", tt.name, "application/x-ndjson", header)
+		}
+
+		records := 0
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			var resource storage.Resource
+			if err = json.Unmarshal(scanner.Bytes(), &resource); err != nil {
+				t.Fatalf("%s: expected json record, but got %v", tt.name, scanner.Text())
+			}
+
+			records++
+		}
+		resp.Body.Close()
+
+		if err = scanner.Err(); err != nil {
+			t.Fatal(err)
+		}
+
+		if records != tt.records {
+			t.Fatalf("%s: expected %v records, but got %v", tt.name, tt.records, records)
+		}
+	}
+}
+
+func TestExport_ClientGone(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": {{"id": "1"}}}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := handler.Setup(map[string]storage.Storage{"posts": storageSvc})
+
+	for _, path := range []string{"/posts", "/posts/_export"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		router.ServeHTTP(rec, req)
+
+		// Nothing is encoded for a client which is gone.
+		if rec.Code != 499 || rec.Body.Len() != 0 {
+			t.Fatalf("%s: expected status code 499 without body, but got %v %q", path, rec.Code, rec.Body.String())
+		}
+	}
+}
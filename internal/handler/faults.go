@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"math/rand"
+	"net/http"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/web"
+	"github.com/chanioxaris/json-server/internal/web/middleware"
+)
+
+// faults is operating as middleware to break a share of the responses of resources, by a fault picked at random
+// from their kinds.
+func faults(profiles map[string]config.Faults) func(http.Handler) http.Handler {
+	return middleware.Faults(func(r *http.Request) string {
+		profile, ok := profiles[routeResourceKey(r)]
+		if !ok || rand.Float64() >= profile.Rate {
+			return ""
+		}
+
+		return profile.Kinds[rand.Intn(len(profile.Kinds))]
+	})
+}
+
+// chaosStatusCodes are the status codes of the requests failed by chaos, picked at random.
+var chaosStatusCodes = []int{http.StatusInternalServerError, http.StatusServiceUnavailable}
+
+// chaos is operating as middleware to fail the rate of requests to the resources with a server error, before they
+// are served, so writes are not applied. Admin routes, starting with '__', and resources with faults of their own
+// are left alone.
+func chaos(rate float64, profiles map[string]config.Faults) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resourceKey := routeResourceKey(r)
+			if _, ok := profiles[resourceKey]; ok || strings.HasPrefix(resourceKey, "__") || rand.Float64() >= rate {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			statusCode := chaosStatusCodes[rand.Intn(len(chaosStatusCodes))]
+			web.Error(w, statusCode, http.StatusText(statusCode))
+		})
+	}
+}
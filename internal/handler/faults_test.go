@@ -0,0 +1,119 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestFaults(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{
+		"resources": {
+			"truncated": {"faults": {"rate": 1, "kinds": ["truncate"]}},
+			"malformed": {"faults": {"rate": 1, "kinds": ["malformed"]}},
+			"mislength": {"faults": {"rate": 1, "kinds": ["content-length"]}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resourceKeys := []string{"truncated", "malformed", "mislength", "none"}
+
+	data := make(storage.Database)
+	for _, resourceKey := range resourceKeys {
+		data[resourceKey] = []storage.Resource{{"id": "1", "title": "json-server"}}
+	}
+
+	db := storage.NewMemoryDB(data)
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range resourceKeys {
+		if resourceStorage[resourceKey], err = storage.NewMemory(db, resourceKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	testCases := []struct {
+		name      string
+		path      string
+		readError bool
+		valid     bool
+	}{
+		{name: "Truncate body", path: "/truncated/1", readError: true},
+		{name: "Malformed body", path: "/malformed"},
+		{name: "Shorter content length", path: "/mislength/1"},
+		{name: "No fault", path: "/none/1", valid: true},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if (err != nil) != tt.readError {
+			t.Fatalf("%s: expected read error %v, but got %v", tt.name, tt.readError, err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, http.StatusOK, resp.StatusCode)
+		}
+
+		if valid := json.Valid(body); valid != tt.valid {
+			t.Fatalf("%s: expected valid json %v, but got %v for %q", tt.name, tt.valid, valid, body)
+		}
+	}
+}
+
+func TestChaos(t *testing.T) {
+	data := storage.Database{"posts": {{"id": "1", "title": "json-server"}}}
+
+	resourceStorage := make(map[string]storage.Storage)
+	for resourceKey := range data {
+		storageSvc, err := storage.NewMock(data, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithChaos(1)))
+	defer server.Close()
+
+	testCases := []struct {
+		name   string
+		path   string
+		failed bool
+	}{
+		{name: "Fail resource", path: "/posts", failed: true},
+		{name: "Fail resource by id", path: "/posts/1", failed: true},
+		{name: "Serve admin route", path: "/__debug/memory"},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		failed := resp.StatusCode == http.StatusInternalServerError || resp.StatusCode == http.StatusServiceUnavailable
+		if failed != tt.failed {
+			t.Fatalf("%s: expected failure %v, but got status code %v", tt.name, tt.failed, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// earthRadius is the mean radius of the earth in meters.
+const earthRadius = 6371008.8
+
+var (
+	// latFields and lngFields list the field names holding coordinates, in order of preference.
+	latFields = []string{"lat", "latitude"}
+	lngFields = []string{"lng", "lon", "long", "longitude"}
+	// locationFields list the field names of nested objects holding coordinates.
+	locationFields = []string{"location", "geo", "coordinates"}
+	// radiusUnits maps the units of the radius to meters.
+	radiusUnits = map[string]float64{"m": 1, "km": 1000, "mi": 1609.344}
+)
+
+// nearResources returns the resources with coordinates within the radius of the location, e.g. '59.33,18.06'
+// and '5km'. An empty radius keeps all resources with coordinates. Resources are sorted by distance, nearest
+// first, if requested.
+func nearResources(resources []storage.Resource, near, radius string, sortByDistance bool) ([]storage.Resource, error) {
+	lat, lng, ok := parseLocation(near)
+	if !ok {
+		return nil, fmt.Errorf("%w: _near", errInvalidQuery)
+	}
+
+	maxDistance := math.Inf(1)
+	if radius != "" {
+		if maxDistance, ok = parseRadius(radius); !ok {
+			return nil, fmt.Errorf("%w: _radius", errInvalidQuery)
+		}
+	}
+
+	type nearResource struct {
+		resource storage.Resource
+		distance float64
+	}
+
+	matches := make([]nearResource, 0, len(resources))
+	for _, resource := range resources {
+		resourceLat, resourceLng, ok := resourceLocation(resource)
+		if !ok {
+			continue
+		}
+
+		distance := haversine(lat, lng, resourceLat, resourceLng)
+		if distance <= maxDistance {
+			matches = append(matches, nearResource{resource: resource, distance: distance})
+		}
+	}
+
+	if sortByDistance {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].distance < matches[j].distance
+		})
+	}
+
+	filtered := make([]storage.Resource, 0, len(matches))
+	for _, match := range matches {
+		filtered = append(filtered, match.resource)
+	}
+
+	return filtered, nil
+}
+
+// parseLocation parses a location in the form 'lat,lng'.
+func parseLocation(val string) (float64, float64, bool) {
+	parts := strings.Split(val, ",")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, false
+	}
+
+	lng, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+
+	return lat, lng, true
+}
+
+// parseRadius parses a radius with an optional unit, either 'm', 'km' or 'mi', and returns it in meters.
+// Radiuses without unit are in kilometers.
+func parseRadius(val string) (float64, bool) {
+	unit := strings.TrimLeft(val, "0123456789.")
+	if unit == "" {
+		unit = "km"
+	}
+
+	multiplier, ok := radiusUnits[unit]
+	if !ok {
+		return 0, false
+	}
+
+	radius, err := strconv.ParseFloat(strings.TrimSuffix(val, unit), 64)
+	if err != nil || radius < 0 {
+		return 0, false
+	}
+
+	return radius * multiplier, true
+}
+
+// resourceLocation returns the coordinates of a resource, held either by its own fields, or the fields
+// of a nested location object.
+func resourceLocation(resource storage.Resource) (float64, float64, bool) {
+	if lat, lng, ok := coordinates(resource); ok {
+		return lat, lng, true
+	}
+
+	for _, field := range locationFields {
+		if obj, ok := resource[field].(map[string]interface{}); ok {
+			if lat, lng, ok := coordinates(obj); ok {
+				return lat, lng, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+func coordinates(obj map[string]interface{}) (float64, float64, bool) {
+	lat, ok := firstNumber(obj, latFields)
+	if !ok {
+		return 0, 0, false
+	}
+
+	lng, ok := firstNumber(obj, lngFields)
+	if !ok {
+		return 0, 0, false
+	}
+
+	return lat, lng, true
+}
+
+// firstNumber returns the value of the first present field, converting numeric strings.
+func firstNumber(obj map[string]interface{}, fields []string) (float64, bool) {
+	for _, field := range fields {
+		switch v := obj[field].(type) {
+		case float64:
+			return v, true
+		case int:
+			return float64(v), true
+		case json.Number:
+			if number, err := v.Float64(); err == nil {
+				return number, true
+			}
+		case string:
+			if number, err := strconv.ParseFloat(v, 64); err == nil {
+				return number, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// haversine returns the great-circle distance in meters between two coordinates.
+func haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
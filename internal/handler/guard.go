@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/expr"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var errDeniedByGuard = errors.New("denied by guard")
+
+// guard is a parsed guard of the configuration.
+type guard struct {
+	config.Guard
+	when     *expr.Expr
+	location *expr.Template
+}
+
+// guards is operating as middleware to serve, deny or redirect the requests of the routes with guards.
+// The first guard whose condition holds decides, and requests matching none are served. Conditions are
+// evaluated with the variables 'request', 'resource' (the stored resource of routes with an id parameter)
+// and 'auth' (the claims of the access token).
+func guards(routeGuards map[string][]config.Guard, resourceStorage map[string]storage.Storage) func(http.Handler) http.Handler {
+	// Guard routes are matched apart, as they may not match the route which serves the request.
+	routes := make([]string, 0, len(routeGuards))
+	parsed := make(map[string][]guard)
+
+	for route, configGuards := range routeGuards {
+		routes = append(routes, route)
+
+		for _, configGuard := range configGuards {
+			g := guard{Guard: configGuard}
+
+			var err error
+			if g.when, err = expr.Parse(configGuard.When); err != nil {
+				continue
+			}
+
+			if configGuard.Location != "" {
+				if g.location, err = expr.ParseTemplate(configGuard.Location); err != nil {
+					continue
+				}
+			}
+
+			parsed[route] = append(parsed[route], g)
+		}
+	}
+
+	matcher := newRouteMatcher(routes)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, params, ok := matcher.match(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			vars := guardVars(r, params, resourceStorage)
+
+			for _, g := range parsed[route] {
+				holds, err := g.when.Eval(vars)
+				if err != nil {
+					web.Error(w, http.StatusInternalServerError, fmt.Sprintf("guard %q: %v", g.When, err))
+					return
+				}
+
+				if holds != true {
+					continue
+				}
+
+				switch g.Action {
+				case config.GuardDeny:
+					status, message := http.StatusForbidden, errDeniedByGuard.Error()
+					if g.Status != 0 {
+						status = g.Status
+					}
+
+					if g.Message != "" {
+						message = g.Message
+					}
+
+					web.Error(w, status, message)
+				case config.GuardRedirect:
+					location, err := g.location.Eval(vars)
+					if err != nil {
+						web.Error(w, http.StatusInternalServerError, fmt.Sprintf("guard %q: %v", g.Location, err))
+						return
+					}
+
+					status := http.StatusTemporaryRedirect
+					if g.Status != 0 {
+						status = g.Status
+					}
+
+					http.Redirect(w, r, fmt.Sprint(location), status)
+				default:
+					next.ServeHTTP(w, r)
+				}
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// guardVars returns the variables available to the guard conditions. Header names are lower case.
+func guardVars(r *http.Request, params map[string]string, resourceStorage map[string]storage.Storage) map[string]interface{} {
+	query := make(map[string]interface{})
+	for key := range r.URL.Query() {
+		query[key] = r.URL.Query().Get(key)
+	}
+
+	header := make(map[string]interface{})
+	for key := range r.Header {
+		header[strings.ToLower(key)] = r.Header.Get(key)
+	}
+
+	paramVars := make(map[string]interface{}, len(params))
+	for key, val := range params {
+		paramVars[key] = val
+	}
+
+	vars := map[string]interface{}{
+		"request": map[string]interface{}{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"params": paramVars,
+			"query":  query,
+			"header": header,
+		},
+	}
+
+	resourceKey := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+	if storageSvc, ok := resourceStorage[resourceKey]; ok && params["id"] != "" {
+		if resource, err := storageSvc.FindById(params["id"]); err == nil {
+			vars["resource"] = map[string]interface{}(resource)
+		}
+	}
+
+	if claims, ok := auth.ClaimsFromContext(r.Context()); ok {
+		vars["auth"] = map[string]interface{}(claims)
+	}
+
+	return vars
+}
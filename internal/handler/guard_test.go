@@ -0,0 +1,123 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestGuards(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{
+		"guards": {
+			"PATCH /posts/:id": [
+				{"when": "request.header[\"x-admin\"] == \"true\"", "action": "allow"},
+				{"when": "resource.status == \"locked\"", "action": "deny", "status": 423, "message": "post is locked"}
+			],
+			"GET /posts/:id": [
+				{"when": "request.header[\"x-feature\"] == \"beta\"", "action": "redirect", "location": "/drafts/{{ request.params.id }}"}
+			],
+			"GET /posts": [
+				{"when": "request.query.page > 10", "action": "deny"}
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"posts":  {{"id": "1", "status": "locked"}, {"id": "2", "status": "draft"}},
+		"drafts": {{"id": "1"}},
+	}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		header     map[string]string
+		statusCode int
+		location   string
+	}{
+		{
+			name:       "Deny request on resource condition",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			statusCode: http.StatusLocked,
+		},
+		{
+			name:       "Allow request by earlier guard",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			header:     map[string]string{"X-Admin": "true"},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Serve request matching no guard",
+			method:     http.MethodPatch,
+			path:       "/posts/2",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Redirect request on header condition",
+			method:     http.MethodGet,
+			path:       "/posts/2",
+			header:     map[string]string{"X-Feature": "beta"},
+			statusCode: http.StatusTemporaryRedirect,
+			location:   "/drafts/2",
+		},
+		{
+			name:       "Serve request of route without guards",
+			method:     http.MethodDelete,
+			path:       "/posts/2",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Fail on invalid condition",
+			method:     http.MethodGet,
+			path:       "/posts?page=abc",
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(`{"title": "updated"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for key, val := range tt.header {
+			req.Header.Set(key, val)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if location := resp.Header.Get("Location"); location != tt.location {
+			t.Fatalf("%s: expected header Location %v, but got %v", tt.name, tt.location, location)
+		}
+	}
+}
@@ -5,39 +5,694 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/capture"
+	"github.com/chanioxaris/json-server/internal/clock"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/events"
+	"github.com/chanioxaris/json-server/internal/faker"
 	"github.com/chanioxaris/json-server/internal/handler/common"
+	"github.com/chanioxaris/json-server/internal/search"
 	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/stub"
 	"github.com/chanioxaris/json-server/internal/web/middleware"
 )
 
+const (
+	// uploadsPath is the url path uploaded files are served under.
+	uploadsPath = "/uploads/"
+	// defaultUploadLimit is the maximum size in bytes of multipart request bodies, unless set otherwise.
+	defaultUploadLimit = 32 << 20
+	// requestsPath is the url path captured requests are listed under.
+	requestsPath = "/__requests"
+	// verifyPath is the url path expectations are checked against the captured requests under.
+	verifyPath = "/__verify"
+	// stubsPath is the url path stubs are registered under.
+	stubsPath = "/__stubs"
+	// resourcesPath is the url path resources are created at runtime under.
+	resourcesPath = "/__resources"
+	// schemaPath is the url path the inferred schemas of the resources are served under.
+	schemaPath = "/__schema"
+	// memoryPath is the route reporting the size of the dataset and the memory used.
+	memoryPath = "/__debug/memory"
+	// openAPIPath is the url path the OpenAPI document of the resources is served under.
+	openAPIPath = "/openapi.json"
+	// docsPath is the url path Swagger UI is served under.
+	docsPath = "/docs"
+	// eventsPath is the url path the changes of the resources are streamed under.
+	eventsPath = "/events"
+)
+
 // Setup API handler based on provided resources.
-func Setup(resourceStorage map[string]storage.Storage) http.Handler {
+func Setup(resourceStorage map[string]storage.Storage, opts ...Option) http.Handler {
+	o := &options{fakerSeed: time.Now().UnixNano()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.clock == nil {
+		o.clock = clock.New()
+	}
+
+	if o.config == nil {
+		o.config = &config.Config{}
+	}
+
 	router := mux.NewRouter().StrictSlash(true)
 	router.Use(middleware.Recovery)
-	router.Use(middleware.Logger)
+
+	// The layers of the middleware chain which can be reordered or disabled in the configuration file, and the
+	// ones always running after them, applied once all are set up.
+	order := o.config.MiddlewareOrder()
+	enabled := make(map[string]bool)
+	for _, name := range order {
+		enabled[name] = true
+	}
+
+	layers := make(map[string][]mux.MiddlewareFunc)
+	inner := make([]mux.MiddlewareFunc, 0)
+
+	layers[config.MiddlewareLogging] = append(layers[config.MiddlewareLogging], middleware.Logger)
+
+	if o.compress {
+		layers[config.MiddlewareCompress] = append(layers[config.MiddlewareCompress], middleware.Compress)
+	}
+
+	// Encode the json responses in the format requested with the Accept header, or configured, e.g. xml or csv.
+	// Responses are compressed once encoded, so encoding runs right inside the compression.
+	formats := make(map[string]string)
+	for resourceKey, resourceConfig := range o.config.Resources {
+		if resourceConfig.Format != "" {
+			formats[resourceKey] = resourceConfig.Format
+		}
+	}
+
+	encode := serialize(o.config.Format, formats)
+	layers[config.MiddlewareCompress] = append(layers[config.MiddlewareCompress], encode)
+
+	inner = append(inner, middleware.Decompress)
+
+	if o.strictType {
+		inner = append(inner, middleware.StrictContentType)
+	}
+
+	// Reject the requests modifying data, except the logins issuing tokens and the verification of the captured
+	// requests, which are only read.
+	if o.readOnly {
+		inner = append(inner, rejectWrites("/login", oauthPath+"/token", verifyPath))
+	}
+
+	resourceKeys := make([]string, 0, len(resourceStorage))
+	for resourceKey := range resourceStorage {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	privateFields := o.config.PrivateFields(resourceKeys)
+
+	// Require an access token for protected resources and resources with access rules, which is issued
+	// either by the login and register endpoints, or the OAuth provider.
+	verifiers := make([]*auth.Tokens, 0)
+	protected := make(map[string]bool)
+
+	if o.auth != nil {
+		verifiers = append(verifiers, o.auth.tokens)
+
+		for _, resourceKey := range o.auth.protected {
+			protected[resourceKey] = true
+		}
+
+		if len(protected) == 0 {
+			for resourceKey := range resourceStorage {
+				protected[resourceKey] = true
+			}
+		}
+
+		if usersSvc, ok := resourceStorage[o.auth.usersKey]; ok {
+			usersPrivate := privateFields[o.auth.usersKey]
+			router.HandleFunc("/login", Login(usersSvc, o.auth.tokens, usersPrivate...)).Methods(http.MethodPost)
+			router.HandleFunc("/register", Register(usersSvc, o.auth.tokens, usersPrivate...)).Methods(http.MethodPost)
+		}
+	}
+
+	if o.oauth != nil {
+		verifiers = append(verifiers, o.oauth.Tokens())
+	}
+
+	rules := make(map[string]config.Access)
+	for resourceKey, resourceConfig := range o.config.Resources {
+		rules[resourceKey] = resourceConfig.Access
+	}
+
+	authRequired := len(verifiers) > 0 && (o.auth != nil || o.config.HasAccessRules())
+	if authRequired {
+		layers[config.MiddlewareAuth] = append(layers[config.MiddlewareAuth], requireAuth(verifiers, protected, rules))
+	}
+
+	// Act as OAuth2 and OpenID Connect provider.
+	if o.oauth != nil {
+		router.HandleFunc(oauthPath+"/authorize", OAuthAuthorize(o.oauth)).Methods(http.MethodGet)
+		router.HandleFunc(oauthPath+"/token", OAuthToken(o.oauth)).Methods(http.MethodPost)
+		router.HandleFunc(oauthPath+"/jwks", OAuthJWKS(o.oauth)).Methods(http.MethodGet)
+		router.HandleFunc(oauthPath+"/userinfo", OAuthUserInfo(o.oauth)).Methods(http.MethodGet)
+		router.HandleFunc("/.well-known/openid-configuration", OAuthDiscovery(o.oauth)).Methods(http.MethodGet)
+	}
+
+	// Store uploaded files of multipart requests.
+	if o.uploadsDir != "" {
+		uploadLimit := o.uploadLimit
+		if uploadLimit <= 0 {
+			uploadLimit = defaultUploadLimit
+		}
+
+		inner = append(inner, middleware.Uploads(o.uploadsDir, uploadsPath, uploadLimit))
+	}
+
+	computedFields := o.config.ComputedFields()
 
 	// For each resource create the appropriate endpoint handlers.
+	// Resources which are hidden or write-only are omitted from the db contents.
+	hiddenDB := make(map[string]bool)
+	visibleStorage := make(map[string]storage.Storage)
+
+	// Handle routes with scripts, before the resource routes so they take precedence. Scripts access the
+	// visible resources, which are added below.
+	for route, s := range o.scripts {
+		method, path, err := config.ParseRoute(route)
+		if err != nil {
+			continue
+		}
+
+		router.HandleFunc(path, Script(s, visibleStorage)).Methods(method)
+	}
+
+	// Serve, deny or redirect the requests of routes with guards. Added after the authentication, so guards
+	// can check the claims of the access token, and get the visible resources, which are added below.
+	if len(o.config.Guards) > 0 {
+		inner = append(inner, guards(o.config.Guards, visibleStorage))
+	}
+
+	// Override the success status codes of routes.
+	if len(o.config.StatusCodes) > 0 {
+		inner = append(inner, statusCodes(o.config.StatusCodes))
+	}
+
+	// Delay the responses of resources with a latency distribution, or of requests with the X-Mock-Delay header.
+	latencyProfiles := make(map[string]config.Latency)
+	for resourceKey, resourceConfig := range o.config.Resources {
+		if resourceConfig.Latency != nil {
+			latencyProfiles[resourceKey] = *resourceConfig.Latency
+		}
+	}
+
+	// Delay all other responses by the fixed delay, if any.
+	var fallbackLatency *config.Latency
+	if o.delay > 0 {
+		fallbackLatency = &config.Latency{Distribution: config.LatencyFixed, Delay: config.Duration(o.delay)}
+	}
+
+	if len(latencyProfiles) > 0 || fallbackLatency != nil || o.mockHeaders {
+		layers[config.MiddlewareDelay] = append(layers[config.MiddlewareDelay], latency(latencyProfiles, fallbackLatency, o.mockHeaders))
+	}
+
+	// Respond to requests with the X-Mock-Status header with its error, after their delay.
+	if o.mockHeaders {
+		inner = append(inner, mockStatus)
+	}
+
+	// Break a share of the responses of resources with faults.
+	faultProfiles := make(map[string]config.Faults)
+	for resourceKey, resourceConfig := range o.config.Resources {
+		if resourceConfig.Faults != nil {
+			faultProfiles[resourceKey] = *resourceConfig.Faults
+		}
+	}
+
+	if len(faultProfiles) > 0 {
+		inner = append(inner, faults(faultProfiles))
+	}
+
+	// Fail a share of the requests to the other resources with server errors.
+	if o.chaosRate > 0 {
+		layers[config.MiddlewareChaos] = append(layers[config.MiddlewareChaos], chaos(o.chaosRate, faultProfiles))
+	}
+
+	// Without compression, responses are encoded before the other layers.
+	if !enabled[config.MiddlewareCompress] {
+		router.Use(encode)
+	}
+
+	for _, name := range order {
+		router.Use(layers[name]...)
+	}
+
+	router.Use(inner...)
+
+	// Cache the responses of the GET requests, dropped on every write to their collection.
+	var responses *responseCache
+	if o.cacheTTL > 0 {
+		responses = newResponseCache(o.cacheTTL, time.Now)
+	}
+
+	// Publish the changes of the resources to the event stream.
+	changes := events.NewBroker(eventsHistorySize)
+
 	for resourceKey, storageSvc := range resourceStorage {
+		resourceConfig := o.config.Resources[resourceKey]
+		if resourceConfig.Hidden || resourceConfig.WriteOnly {
+			hiddenDB[resourceKey] = true
+		}
+
+		if resourceConfig.Hidden {
+			continue
+		}
+
+		// Evict resources once there are more than the maximum.
+		if resourceConfig.MaxRecords > 0 {
+			storageSvc = storage.NewCapped(storageSvc, resourceConfig.MaxRecords, resourceConfig.Eviction)
+		}
+
+		// Reject resources which miss required fields.
+		if len(resourceConfig.Required) > 0 {
+			storageSvc = storage.NewRequired(storageSvc, resourceConfig.Required)
+		}
+
+		// Reject resources which duplicate the value of a unique field.
+		if len(resourceConfig.Unique) > 0 {
+			storageSvc = storage.NewUnique(storageSvc, resourceConfig.Unique)
+		}
+
+		// Add computed fields to responses, derived from the stored fields.
+		if len(computedFields) > 0 {
+			storageSvc = storage.NewComputed(storageSvc, resourceKey, computedFields)
+		}
+
+		// Strip private fields from responses, while keeping them in storage.
+		if len(privateFields) > 0 {
+			storageSvc = storage.NewPrivate(storageSvc, resourceKey, privateFields)
+		}
+
+		// Convert field names to the requested case, after all fields are in place.
+		if o.keyCase != "" {
+			storageSvc = storage.NewKeyCase(storageSvc, o.keyCase)
+		}
+
+		if responses != nil {
+			storageSvc = &cachedStorage{Storage: storageSvc, cache: responses, collection: resourceKey}
+		}
+
+		// Only the changes of the resources anyone can read are streamed, as the stream needs no access token.
+		if resourceKey != "db" && !resourceConfig.WriteOnly && !protected[resourceKey] && rules[resourceKey].Read == "" {
+			storageSvc = &publishedStorage{Storage: storageSvc, broker: changes, collection: resourceKey}
+		}
+
+		visibleStorage[resourceKey] = storageSvc
+	}
+
+	// Keep track of the resources created, dropped and renamed while the server runs.
+	var runtime *runtimeResources
+	if o.collections != nil && !o.readOnly {
+		static := make(map[string]bool)
+		for resourceKey := range resourceStorage {
+			if resourceKey != "db" {
+				static[resourceKey] = !o.config.Resources[resourceKey].Hidden
+			}
+		}
+
+		runtime = newRuntimeResources(o.collections, static, o.autoCreate, changes)
+	}
+
+	listOpts := ListOptions{StrictQueries: o.strictQuery, MaxUnpaginated: o.maxUnpaged}
+
+	// Bound the number of heavy requests served at once, like searches, exports and imports.
+	var heavy *heavyLimiter
+	if o.heavyLimit > 0 {
+		heavy = newHeavyLimiter(o.heavyLimit, o.heavyQueue)
+	}
+
+	// Resolve the resources related by foreign keys, e.g. the comments of a post, among the readable resources
+	// the request needs no access token for, as the token is checked against the path. Writes to related
+	// resources through the routes of their parent, e.g. '/posts/1/comments', need writable resources.
+	relatedStorage := make(map[string]storage.Storage)
+	writableStorage := make(map[string]storage.Storage)
+	relations := storage.NewRelations(relatedStorage)
+	writableRelations := storage.NewRelations(writableStorage)
+
+	// Keep the search indexes of the resources, dropped once the db is written to.
+	searchCaches := make(map[string]*search.Cache)
+
+	for resourceKey, storageSvc := range visibleStorage {
 		// Common endpoint to retrieve db contents.
 		if resourceKey == "db" {
-			router.HandleFunc("/db", common.DB(storageSvc)).Methods(http.MethodGet)
+			// Omit the resources the request is not allowed to read.
+			if authRequired {
+				router.HandleFunc("/db", authorizedDB(storageSvc, hiddenDB, verifiers, protected, rules)).Methods(http.MethodGet)
+				continue
+			}
+
+			router.HandleFunc("/db", common.DB(storageSvc, hiddenDB)).Methods(http.MethodGet)
 			continue
 		}
 
-		// Register all default endpoint handlers for resource.
-		router.HandleFunc(fmt.Sprintf("/%s", resourceKey), List(storageSvc)).Methods(http.MethodGet)
-		router.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Read(storageSvc)).Methods(http.MethodGet)
-		router.HandleFunc(fmt.Sprintf("/%s", resourceKey), Create(storageSvc)).Methods(http.MethodPost)
-		router.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Replace(storageSvc)).Methods(http.MethodPut)
-		router.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Update(storageSvc)).Methods(http.MethodPatch)
-		router.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Delete(storageSvc)).Methods(http.MethodDelete)
+		resourceConfig := o.config.Resources[resourceKey]
+		readable := !resourceConfig.WriteOnly
+		writable := !resourceConfig.ReadOnly
+
+		// Routes of resources which can be dropped or renamed at runtime stop matching once they are.
+		resourceRouter := router
+		if runtime != nil {
+			resourceRouter = router.MatcherFunc(runtime.live(resourceKey)).Subrouter()
+		}
+
+		// Stream exports, registered before the routes by id.
+		if readable {
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/_export", resourceKey), heavy.limit(Export(storageSvc, o.strictQuery), alwaysHeavy)).Methods(http.MethodGet)
+		}
+
+		// Resources referencing local files serve the file content on GET by id.
+		if blob, ok := o.blobs[resourceKey]; ok && readable {
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Blob(storageSvc, blob.field, blob.baseDir)).
+				Methods(http.MethodGet, http.MethodHead)
+		}
+
+		// Keep the search index of the resource until it is written to.
+		searchCache := search.NewCache()
+		storageSvc = &indexedStorage{Storage: storageSvc, cache: searchCache}
+		searchCaches[resourceKey] = searchCache
+
+		if readable && !protected[resourceKey] && rules[resourceKey].Read == "" {
+			relatedStorage[resourceKey] = storageSvc
+
+			if writable && rules[resourceKey].Write == "" {
+				writableStorage[resourceKey] = storageSvc
+			}
+		}
+
+		resourceListOpts := listOpts
+		resourceListOpts.Relations = relations
+		resourceListOpts.ResourceKey = resourceKey
+
+		// Stream bulk imports, registered before the routes by id.
+		if writable {
+			progress := newImportProgress()
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/_import", resourceKey), heavy.limit(Import(storageSvc, progress), alwaysHeavy)).Methods(http.MethodPost)
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/_import", resourceKey), ImportProgress(progress)).Methods(http.MethodGet)
+		}
+
+		// Register all default endpoint handlers for resource. Requests with other methods
+		// than the registered ones fail with 405.
+		if readable {
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s", resourceKey), responses.cache(resourceKey, heavy.limit(List(storageSvc, searchCache, resourceListOpts), searchHeavy))).Methods(http.MethodGet)
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), responses.cache(resourceKey, readRelated(storageSvc, relations, resourceKey))).Methods(http.MethodGet)
+
+			// Look up resources by unique fields, e.g. '/posts/slug/{value}' or '/posts/by/slug/{value}'.
+			for _, field := range resourceConfig.Unique {
+				resourceRouter.HandleFunc(fmt.Sprintf("/%s/%s/{value}", resourceKey, field), responses.cache(resourceKey, ReadBy(storageSvc, field))).Methods(http.MethodGet)
+				resourceRouter.HandleFunc(fmt.Sprintf("/%s/by/%s/{value}", resourceKey, field), responses.cache(resourceKey, ReadBy(storageSvc, field))).Methods(http.MethodGet)
+			}
+		}
+
+		if writable {
+			prepare := make([]prepareFunc, 0)
+			if len(resourceConfig.Defaults) > 0 {
+				prepare = append(prepare, applyDefaults(resourceConfig.Defaults, o.clock.Now))
+			}
+
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s", resourceKey), Create(storageSvc, prepare...)).Methods(http.MethodPost)
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s", resourceKey), DeleteMany(storageSvc)).Methods(http.MethodDelete).Queries("id", "")
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Replace(storageSvc)).Methods(http.MethodPut)
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Update(storageSvc)).Methods(http.MethodPatch)
+			resourceRouter.HandleFunc(fmt.Sprintf("/%s/{id}", resourceKey), Delete(storageSvc)).Methods(http.MethodDelete)
+		}
+
+		// Serve the arrays of objects nested in resources as collections, e.g. '/users/1/orders', or else the
+		// related resources, e.g. '/posts/1/comments'. Registered after the other routes, which take precedence.
+		nested := storage.NewNested(storageSvc)
+		nestedPath := fmt.Sprintf("/%s/{parentId}/{nested}", resourceKey)
+
+		if readable {
+			resourceRouter.HandleFunc(nestedPath, responses.cache(resourceKey, heavy.limit(nestedHandler(nested, relations, resourceKey, nestedList(listOpts)), searchHeavy))).Methods(http.MethodGet)
+			resourceRouter.HandleFunc(nestedPath+"/{id}", responses.cache(resourceKey, nestedHandler(nested, relations, resourceKey, Read))).Methods(http.MethodGet)
+		}
+
+		if writable {
+			resourceRouter.HandleFunc(nestedPath, nestedHandler(nested, writableRelations, resourceKey, nestedCreate)).Methods(http.MethodPost)
+			resourceRouter.HandleFunc(nestedPath+"/{id}", nestedHandler(nested, writableRelations, resourceKey, Replace)).Methods(http.MethodPut)
+			resourceRouter.HandleFunc(nestedPath+"/{id}", nestedHandler(nested, writableRelations, resourceKey, Update)).Methods(http.MethodPatch)
+			resourceRouter.HandleFunc(nestedPath+"/{id}", nestedHandler(nested, writableRelations, resourceKey, Delete)).Methods(http.MethodDelete)
+		}
+	}
+
+	// Replace or merge the collections of the db at once, e.g. '{"posts": [...]}', writing the undecorated db, as
+	// the storages of the resources wrap its collections.
+	if dbSvc, ok := resourceStorage["db"]; ok {
+		known := make(map[string]bool)
+		for resourceKey := range searchCaches {
+			known[resourceKey] = true
+		}
+
+		writable := authorizedWrite(verifiers, protected, rules)
+		if !authRequired {
+			writable = func(*http.Request, string) bool { return true }
+		}
+
+		dbWritable := func(r *http.Request, resourceKey string) bool {
+			return !o.config.Resources[resourceKey].ReadOnly && writable(r, resourceKey)
+		}
+
+		written := func(resourceKeys []string) {
+			for _, resourceKey := range resourceKeys {
+				if responses != nil {
+					responses.invalidate(resourceKey)
+				}
+
+				searchCaches[resourceKey].Invalidate()
+			}
+		}
+
+		router.HandleFunc("/db", ReplaceDB(dbSvc, known, dbWritable, written)).Methods(http.MethodPost)
+	}
+
+	// Describe the routes of the resources as an OpenAPI document, explored with Swagger UI. Registered after the
+	// resources, so resources named like them take precedence.
+	if dbSvc, ok := visibleStorage["db"]; ok {
+		router.HandleFunc(openAPIPath, common.OpenAPI(dbSvc, o.config, o.readOnly)).Methods(http.MethodGet)
+		router.HandleFunc(docsPath, common.Docs(openAPIPath)).Methods(http.MethodGet)
+	}
+
+	// Stream the changes of the resources as server-sent events, e.g. '/events?resource=posts', unless a resource
+	// is named like it, even one which cannot be read.
+	if _, ok := resourceStorage["events"]; !ok {
+		router.HandleFunc(eventsPath, Events(changes)).Methods(http.MethodGet)
+	}
+
+	// Serve uploaded files. Registered after the resources, so a resource named 'uploads' takes precedence.
+	if o.uploadsDir != "" {
+		router.PathPrefix(uploadsPath).
+			Handler(http.StripPrefix(uploadsPath, http.FileServer(http.Dir(o.uploadsDir)))).
+			Methods(http.MethodGet)
+	}
+
+	// Generate placeholder images, e.g. '/__placeholder/400x300.svg?text=Hello'.
+	router.HandleFunc(`/__placeholder/{width:[0-9]+}x{height:[0-9]+}{ext:(?:\.png|\.svg)?}`, common.Placeholder()).
+		Methods(http.MethodGet)
+
+	// Generate random values, e.g. '/__faker/email?count=10'.
+	fakerSvc := faker.New(o.fakerSeed)
+	router.HandleFunc("/__faker", common.FakerKinds()).Methods(http.MethodGet)
+	router.HandleFunc("/__faker/{kind}", common.Faker(fakerSvc)).Methods(http.MethodGet)
+
+	// Control the mock clock, e.g. 'POST /__time/advance' with body '{"duration": "1h"}'.
+	router.HandleFunc("/__time", common.Time(o.clock)).Methods(http.MethodGet)
+	router.HandleFunc("/__time", common.TimeSet(o.clock)).Methods(http.MethodPut)
+	router.HandleFunc("/__time/advance", common.TimeAdvance(o.clock)).Methods(http.MethodPost)
+	router.HandleFunc("/__time/freeze", common.TimeFreeze(o.clock)).Methods(http.MethodPost)
+	router.HandleFunc("/__time/unfreeze", common.TimeUnfreeze(o.clock)).Methods(http.MethodPost)
+	router.HandleFunc("/__time/reset", common.TimeReset(o.clock)).Methods(http.MethodPost)
+
+	// Describe the records of the readable resources, e.g. '/__schema/posts', inferred from their current records.
+	schemaStorage := make(map[string]storage.Storage)
+	for resourceKey, storageSvc := range visibleStorage {
+		if resourceKey != "db" && !hiddenDB[resourceKey] {
+			schemaStorage[resourceKey] = storageSvc
+		}
+	}
+
+	// The values of private fields and passwords are never listed as enums.
+	secretFields := make(map[string][]string)
+	for resourceKey, fields := range privateFields {
+		secretFields[resourceKey] = append(secretFields[resourceKey], fields...)
+	}
+
+	if o.auth != nil {
+		secretFields[o.auth.usersKey] = append(secretFields[o.auth.usersKey], passwordField)
+	}
+
+	schemaHandler := func(resources map[string]storage.Storage) http.HandlerFunc {
+		return common.Schema(resources, secretFields)
+	}
+
+	schemaResourceHandler := func(resources map[string]storage.Storage) http.HandlerFunc {
+		return common.SchemaResource(resources, secretFields)
+	}
+
+	// Omit the resources the request is not allowed to read, as for the db contents.
+	if authRequired {
+		router.HandleFunc(schemaPath, authorizedResources(schemaStorage, verifiers, protected, rules, schemaHandler)).Methods(http.MethodGet)
+		router.HandleFunc(schemaPath+"/{resource}", authorizedResources(schemaStorage, verifiers, protected, rules, schemaResourceHandler)).Methods(http.MethodGet)
+	} else {
+		router.HandleFunc(schemaPath, schemaHandler(schemaStorage)).Methods(http.MethodGet)
+		router.HandleFunc(schemaPath+"/{resource}", schemaResourceHandler(schemaStorage)).Methods(http.MethodGet)
+	}
+
+	// Report the size of the dataset, including hidden resources, and the memory used by the process.
+	memoryStorage := make(map[string]storage.Storage)
+	for resourceKey, storageSvc := range resourceStorage {
+		if resourceKey != "db" {
+			memoryStorage[resourceKey] = storageSvc
+		}
+	}
+
+	router.HandleFunc(memoryPath, common.Memory(memoryStorage)).Methods(http.MethodGet)
+
+	// List the captured requests, e.g. '/__requests?method=POST&path=/orders', and verify expectations
+	// against them.
+	var captured *capture.Buffer
+	if o.captureSize > 0 {
+		captured = capture.NewBuffer(o.captureSize)
+		router.HandleFunc(requestsPath, common.Requests(captured)).Methods(http.MethodGet)
+		router.HandleFunc(requestsPath, common.RequestsReset(captured)).Methods(http.MethodDelete)
+		router.HandleFunc(verifyPath, common.Verify(captured)).Methods(http.MethodPost)
+	}
+
+	// Register stubs responding instead of the routes, e.g. '{"path": "/users", "times": 1, "response":
+	// {"status": 503}}'.
+	stubs := stub.NewRegistry()
+	for _, s := range o.stubs {
+		// nolint
+		stubs.Add(s)
+	}
+
+	router.HandleFunc(stubsPath, common.Stubs(stubs)).Methods(http.MethodGet)
+	router.HandleFunc(stubsPath, common.StubsAdd(stubs)).Methods(http.MethodPost)
+	router.HandleFunc(stubsPath, common.StubsReset(stubs)).Methods(http.MethodDelete)
+	router.HandleFunc(stubsPath+"/{id}", common.StubsRemove(stubs)).Methods(http.MethodDelete)
+
+	// Create, drop and rename resources while the server runs, e.g. '{"name": "invoices", "plural": true}'.
+	if runtime != nil {
+		router.HandleFunc(resourcesPath, Resources(runtime)).Methods(http.MethodGet)
+		router.HandleFunc(resourcesPath, ResourcesCreate(runtime)).Methods(http.MethodPost)
+		router.HandleFunc(resourcesPath+"/{name}", ResourcesRename(runtime)).Methods(http.MethodPatch)
+		router.HandleFunc(resourcesPath+"/{name}", ResourcesDrop(runtime)).Methods(http.MethodDelete)
+
+		// Serve the resources created at runtime. Their routes only match these resources, and are registered
+		// after the rest, so they never shadow other routes.
+		list := func(s *indexedStorage) http.HandlerFunc { return heavy.limit(List(s, s.cache, listOpts), searchHeavy) }
+		read := func(s *indexedStorage) http.HandlerFunc { return Read(s) }
+		create := func(s *indexedStorage) http.HandlerFunc { return Create(s) }
+		replace := func(s *indexedStorage) http.HandlerFunc { return Replace(s) }
+		update := func(s *indexedStorage) http.HandlerFunc { return Update(s) }
+		remove := func(s *indexedStorage) http.HandlerFunc { return Delete(s) }
+		removeMany := func(s *indexedStorage) http.HandlerFunc { return DeleteMany(s) }
+
+		router.HandleFunc("/{resource}", runtime.handle(list)).Methods(http.MethodGet).MatcherFunc(runtime.match)
+		router.HandleFunc("/{resource}", runtime.handle(create)).Methods(http.MethodPost).MatcherFunc(runtime.match)
+		router.HandleFunc("/{resource}", runtime.handle(removeMany)).Methods(http.MethodDelete).Queries("id", "").MatcherFunc(runtime.match)
+		router.HandleFunc("/{resource}/{id}", runtime.handle(read)).Methods(http.MethodGet).MatcherFunc(runtime.match)
+		router.HandleFunc("/{resource}/{id}", runtime.handle(replace)).Methods(http.MethodPut).MatcherFunc(runtime.match)
+		router.HandleFunc("/{resource}/{id}", runtime.handle(update)).Methods(http.MethodPatch).MatcherFunc(runtime.match)
+		router.HandleFunc("/{resource}/{id}", runtime.handle(remove)).Methods(http.MethodDelete).MatcherFunc(runtime.match)
 	}
 
 	// Render a home page with useful info.
-	router.HandleFunc("/", common.HomePage(resourceStorage)).Methods(http.MethodGet)
+	router.HandleFunc("/", common.HomePage(visibleStorage)).Methods(http.MethodGet)
+
+	// Forward unmatched requests, e.g. to an upstream API. Router middlewares do not run for them, so the
+	// ones needed are applied here.
+	if o.proxy != nil {
+		proxy := o.proxy
+		if enabled[config.MiddlewareLogging] {
+			proxy = middleware.Logger(proxy)
+		}
+
+		router.NotFoundHandler = middleware.Recovery(proxy)
+	}
+
+	// Paths are rewritten before routing, as router middlewares only run for matched routes.
+	var h http.Handler = router
+
+	// Route aliases as the resources they name, so the rules of the resources apply to them as well. Aliases of
+	// existing resources are skipped, as the resources take precedence.
+	aliases := make(map[string]string)
+	for alias, resourceKey := range o.config.Aliases() {
+		if _, ok := resourceStorage[alias]; !ok {
+			aliases[alias] = resourceKey
+		}
+	}
+
+	if len(aliases) > 0 {
+		h = middleware.Aliases(aliases)(h)
+	}
+
+	// Respond with stubs after the paths are rewritten, but before routing, so they can also stub paths matching
+	// no route. The routes under '/__' are never stubbed.
+	h = middleware.Stubs(stubs, "/__")(h)
+
+	// Require the static credentials, including on stubs, but not on preflight requests, which are answered below.
+	if o.credentials != nil && enabled[config.MiddlewareAuth] {
+		h = requireCredentials(o.credentials.token, o.credentials.users, o.credentials.writesOnly)(h)
+	}
+
+	// Allow cross-origin requests, including the ones to stubs. Preflight requests are answered before routing,
+	// as they match no route.
+	corsRules := make(map[string]config.CORS)
+	for resourceKey, resourceConfig := range o.config.Resources {
+		if resourceConfig.CORS != nil {
+			corsRules[resourceKey] = *resourceConfig.CORS
+		}
+	}
+
+	// The allowed origins override the ones of the rule for all resources, keeping its other settings.
+	fallbackCORS := o.config.CORS
+	if len(o.origins) > 0 {
+		rule := config.CORS{}
+		if fallbackCORS != nil {
+			rule = *fallbackCORS
+		}
+
+		rule.Origins = o.origins
+		fallbackCORS = &rule
+	}
+
+	if (len(corsRules) > 0 || fallbackCORS != nil) && enabled[config.MiddlewareCORS] {
+		h = cors(corsRules, fallbackCORS, aliases)(h)
+	}
+
+	if o.ignoreCase {
+		segments := append([]string{}, resourceKeys...)
+		for alias := range aliases {
+			segments = append(segments, alias)
+		}
+
+		h = middleware.IgnoreCase(segments)(h)
+	}
+
+	if o.trimSlash {
+		h = middleware.TrimTrailingSlash(h)
+	}
+
+	// Capture requests as sent, including the ones matching no route.
+	if captured != nil {
+		h = middleware.Capture(captured, o.clock.Now, requestsPath, verifyPath)(h)
+	}
+
+	// Record the requests of the session, leaving out the routes inspecting and controlling the server.
+	if o.recorder != nil {
+		h = middleware.Capture(o.recorder, o.clock.Now, "/__")(h)
+	}
 
-	return router
+	return h
 }
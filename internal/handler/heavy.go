@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// heavyRetryAfter is the number of seconds saturated clients are told to wait before retrying.
+const heavyRetryAfter = 1
+
+var errServerBusy = errors.New("server is busy with heavy requests, retry later")
+
+// heavyLimiter bounds the number of heavy requests served at once, like full-text searches, exports and imports,
+// so they cannot starve interactive traffic. Heavy requests beyond the limit wait in a bounded queue, and fail
+// with 503 once it is full.
+type heavyLimiter struct {
+	slots chan struct{}
+	queue chan struct{}
+}
+
+// newHeavyLimiter returns a limiter serving up to the concurrency of heavy requests at once, while up to the queue
+// size of them wait.
+func newHeavyLimiter(concurrency, queue int) *heavyLimiter {
+	return &heavyLimiter{
+		slots: make(chan struct{}, concurrency),
+		queue: make(chan struct{}, queue),
+	}
+}
+
+// limit serves the heavy requests with the handler once a slot is free, and other requests right away. Requests
+// find a free slot, wait in the queue for one, or fail with 503 and a Retry-After header when the queue is full.
+// Requests whose client is gone stop waiting. A nil limiter serves all requests right away.
+func (l *heavyLimiter) limit(next http.HandlerFunc, heavy func(r *http.Request) bool) http.HandlerFunc {
+	if l == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !heavy(r) {
+			next(w, r)
+			return
+		}
+
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			select {
+			case l.queue <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", strconv.Itoa(heavyRetryAfter))
+				web.Error(w, http.StatusServiceUnavailable, errServerBusy.Error())
+				return
+			}
+
+			select {
+			case l.slots <- struct{}{}:
+				<-l.queue
+			case <-r.Context().Done():
+				<-l.queue
+				return
+			}
+		}
+
+		defer func() { <-l.slots }()
+
+		next(w, r)
+	}
+}
+
+// alwaysHeavy reports every request as heavy.
+func alwaysHeavy(*http.Request) bool {
+	return true
+}
+
+// searchHeavy reports the requests with a full-text search as heavy.
+func searchHeavy(r *http.Request) bool {
+	return r.URL.Query().Get("q") != ""
+}
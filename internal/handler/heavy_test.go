@@ -0,0 +1,97 @@
+package handler_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestHeavyLimit(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": {{"id": "1", "title": "json-server"}}}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, handler.WithHeavyLimit(1, 1)))
+	defer server.Close()
+
+	// Hold a slot and the queue with imports whose bodies are still streamed.
+	writers := make([]*io.PipeWriter, 0, 2)
+	responses := make(chan *http.Response, 2)
+
+	for i := 0; i < 2; i++ {
+		reader, writer := io.Pipe()
+		writers = append(writers, writer)
+
+		go func() {
+			resp, err := http.Post(server.URL+"/posts/_import", "application/x-ndjson", reader)
+			if err != nil {
+				responses <- nil
+				return
+			}
+
+			resp.Body.Close()
+			responses <- resp
+		}()
+
+		if _, err = writer.Write([]byte("{\"title\": \"imported\"}\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The queued import waits for the slot.
+	time.Sleep(50 * time.Millisecond)
+
+	testCases := []struct {
+		name       string
+		path       string
+		statusCode int
+		retryAfter string
+	}{
+		{name: "Saturated export", path: "/posts/_export", statusCode: http.StatusServiceUnavailable, retryAfter: "1"},
+		{name: "Saturated search", path: "/posts?q=json", statusCode: http.StatusServiceUnavailable, retryAfter: "1"},
+		{name: "Interactive list", path: "/posts", statusCode: http.StatusOK},
+		{name: "Interactive read", path: "/posts/1", statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if got := resp.Header.Get("Retry-After"); got != tt.retryAfter {
+			t.Fatalf("%s: expected retry after %q, but got %q", tt.name, tt.retryAfter, got)
+		}
+	}
+
+	for _, writer := range writers {
+		writer.Close()
+	}
+
+	for i := 0; i < 2; i++ {
+		if resp := <-responses; resp == nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected imports to succeed once their turn comes, but got %v", resp)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/posts/_export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %v once heavy requests are done, but got %v", http.StatusOK, resp.StatusCode)
+	}
+}
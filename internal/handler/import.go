@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+const (
+	// maxImportErrors limits the errors listed in the report of an import.
+	maxImportErrors = 100
+	// maxImportReports is the number of reports of past imports kept per resource.
+	maxImportReports = 16
+	// importWriteTimeout bounds the write of the response, once the body of an import is read.
+	importWriteTimeout = 15 * time.Second
+)
+
+// errImportNotFound returns an error when the report of an import is not kept.
+var errImportNotFound = errors.New("import not found")
+
+// importReport describes the progress of an import.
+type importReport struct {
+	ID       string        `json:"id"`
+	Running  bool          `json:"running"`
+	Received int           `json:"received"`
+	Imported int           `json:"imported"`
+	Failed   int           `json:"failed"`
+	Errors   []importError `json:"errors"`
+}
+
+type importError struct {
+	Record int    `json:"record"`
+	Error  string `json:"error"`
+}
+
+// importProgress holds the reports of the last imports of a resource, by id. It is safe for concurrent use.
+type importProgress struct {
+	mu      sync.Mutex
+	lastSeq int
+	// ids holds the ids of the kept reports, oldest first.
+	ids     []string
+	reports map[string]*importReport
+}
+
+func newImportProgress() *importProgress {
+	return &importProgress{reports: make(map[string]*importReport)}
+}
+
+// start adds the report of a new import, with the id if not empty, or a sequential one otherwise, dropping the
+// oldest report once more than maxImportReports are kept. It returns the id of the import.
+func (p *importProgress) start(id string) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.lastSeq++
+	if id == "" {
+		id = strconv.Itoa(p.lastSeq)
+	}
+
+	if _, ok := p.reports[id]; ok {
+		p.remove(id)
+	}
+
+	p.ids = append(p.ids, id)
+	p.reports[id] = &importReport{ID: id, Running: true, Errors: make([]importError, 0)}
+
+	if len(p.ids) > maxImportReports {
+		p.remove(p.ids[0])
+	}
+
+	return id
+}
+
+// remove drops the report of the import. Callers must hold the lock.
+func (p *importProgress) remove(id string) {
+	delete(p.reports, id)
+
+	for idx := range p.ids {
+		if p.ids[idx] == id {
+			p.ids = append(p.ids[:idx], p.ids[idx+1:]...)
+			break
+		}
+	}
+}
+
+func (p *importProgress) update(id string, fn func(report *importReport)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if report, ok := p.reports[id]; ok {
+		fn(report)
+	}
+}
+
+// get returns a copy of the report of the import, or of the last started import if the id is empty.
+func (p *importProgress) get(id string) (importReport, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if id == "" {
+		if len(p.ids) == 0 {
+			return importReport{Errors: make([]importError, 0)}, true
+		}
+
+		id = p.ids[len(p.ids)-1]
+	}
+
+	stored, ok := p.reports[id]
+	if !ok {
+		return importReport{}, false
+	}
+
+	report := *stored
+	report.Errors = append(make([]importError, 0, len(stored.Errors)), stored.Errors...)
+
+	return report, true
+}
+
+// stagedRecord is a decoded record of an import, created once the body is read.
+type stagedRecord struct {
+	record   int
+	resource storage.Resource
+}
+
+// Import operates as a http handler, to create resources from a newline-delimited JSON body. Records are
+// decoded while the body is streamed, so the body is never held in memory as a whole, and created once it is
+// read, in a single write of the storage if it supports transactions. Records that cannot be created are
+// skipped, while an invalid record stops the import with 400, creating the records before it. The import gets
+// the id of the 'id' query parameter, or a sequential one, to look up its progress. The timeouts of the server
+// do not apply to the body of an import, as large imports take a while to upload.
+func Import(storageSvc storage.Storage, progress *importProgress) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		web.SetReadDeadline(w, r, time.Time{})
+		web.SetWriteDeadline(w, r, time.Time{})
+
+		id := progress.start(r.URL.Query().Get("id"))
+
+		addError := func(record int, err error) {
+			progress.update(id, func(report *importReport) {
+				report.Failed++
+				if len(report.Errors) < maxImportErrors {
+					report.Errors = append(report.Errors, importError{Record: record, Error: err.Error()})
+				}
+			})
+		}
+
+		statusCode := http.StatusOK
+		staged := make([]stagedRecord, 0)
+
+		decoder := json.NewDecoder(r.Body)
+		for record := 1; ; record++ {
+			var newResource storage.Resource
+			if err := storage.Decode(decoder, &newResource); err != nil {
+				if !errors.Is(err, io.EOF) {
+					addError(record, fmt.Errorf("%w: %v", storage.ErrBadRequest, err))
+					statusCode = http.StatusBadRequest
+				}
+
+				break
+			}
+
+			// Check if record is empty, or contains only id.
+			if _, ok := newResource["id"]; len(newResource) == 0 || (len(newResource) == 1 && ok) {
+				addError(record, storage.ErrBadRequest)
+				continue
+			}
+
+			staged = append(staged, stagedRecord{record: record, resource: newResource})
+
+			progress.update(id, func(report *importReport) {
+				report.Received++
+			})
+		}
+
+		// The body is read, the response must not take longer than any other.
+		web.SetWriteDeadline(w, r, time.Now().Add(importWriteTimeout))
+
+		if err := createStaged(storageSvc, staged, progress, id, addError); err != nil {
+			statusCode = http.StatusInternalServerError
+		}
+
+		// Invalid records are reported while decoding, and the others while creating, list them in order.
+		progress.update(id, func(report *importReport) {
+			report.Running = false
+			sort.SliceStable(report.Errors, func(i, j int) bool {
+				return report.Errors[i].Record < report.Errors[j].Record
+			})
+		})
+
+		report, _ := progress.get(id)
+		web.Success(w, statusCode, report)
+	}
+}
+
+// createStaged creates the staged records as a single transaction, skipping the ones that cannot be created.
+// Storages without transactions create the records one at a time. If the transaction fails, no record is
+// imported.
+func createStaged(storageSvc storage.Storage, staged []stagedRecord, progress *importProgress, id string, addError func(record int, err error)) error {
+	create := func(storageSvc storage.Storage) error {
+		for _, s := range staged {
+			if _, err := storageSvc.Create(s.resource); err != nil {
+				addError(s.record, err)
+				continue
+			}
+
+			progress.update(id, func(report *importReport) {
+				report.Imported++
+			})
+		}
+
+		return nil
+	}
+
+	err := storage.Transaction(storageSvc, create)
+	if errors.Is(err, storage.ErrTransactionUnsupported) {
+		return create(storageSvc)
+	}
+
+	if err != nil {
+		progress.update(id, func(report *importReport) {
+			report.Failed += report.Imported
+			report.Imported = 0
+			report.Errors = append(report.Errors, importError{Error: err.Error()})
+		})
+	}
+
+	return err
+}
+
+// ImportProgress operates as a http handler, to return the report of the import of the 'id' query parameter,
+// or else of the running, or last, import of a resource.
+func ImportProgress(progress *importProgress) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report, ok := progress.get(r.URL.Query().Get("id"))
+		if !ok {
+			web.Error(w, http.StatusNotFound, errImportNotFound.Error())
+			return
+		}
+
+		web.Success(w, http.StatusOK, report)
+	}
+}
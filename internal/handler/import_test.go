@@ -0,0 +1,215 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+type testImportReport struct {
+	ID       string `json:"id"`
+	Running  bool   `json:"running"`
+	Received int    `json:"received"`
+	Imported int    `json:"imported"`
+	Failed   int    `json:"failed"`
+	Errors   []struct {
+		Record int    `json:"record"`
+		Error  string `json:"error"`
+	} `json:"errors"`
+}
+
+func decodeImportReport(resp *http.Response, err error) (testImportReport, error) {
+	var report testImportReport
+	if err != nil {
+		return report, err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(resp.Body).Decode(&report)
+
+	return report, err
+}
+
+func TestImport(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": {{"id": "1"}}}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	var body strings.Builder
+	for i := 1; i <= 1500; i++ {
+		fmt.Fprintf(&body, "{\"id\": \"%d\", \"title\": \"title-%d\"}\n", i, i)
+	}
+
+	body.WriteString("{}\n")
+	body.WriteString("{\"title\": \"last\"}\n")
+
+	report, err := decodeImportReport(http.Post(server.URL+"/posts/_import", "application/x-ndjson", strings.NewReader(body.String())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Imported != 1500 || report.Failed != 2 || report.Running {
+		t.Fatalf("expected 1500 imported and 2 failed, but got %v", report)
+	}
+
+	failedRecords := []int{report.Errors[0].Record, report.Errors[1].Record}
+	if expected := []int{1, 1501}; !reflect.DeepEqual(failedRecords, expected) {
+		t.Fatalf("expected failed records %v, but got %v", expected, failedRecords)
+	}
+
+	resources, err := storageSvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resources) != 1501 {
+		t.Fatalf("expected 1501 resources, but got %d", len(resources))
+	}
+
+	// Invalid records stop the import.
+	resp, err := http.Post(server.URL+"/posts/_import", "application/x-ndjson", strings.NewReader("{\"title\": \"a\"}\n{invalid\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status code %d, but got %d", http.StatusBadRequest, resp.StatusCode)
+	}
+
+	if report, err = decodeImportReport(resp, nil); err != nil || report.Imported != 1 || report.Failed != 1 {
+		t.Fatalf("expected 1 imported and 1 failed, but got %v", report)
+	}
+}
+
+func TestImport_Progress(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": {}}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	reader, writer := io.Pipe()
+
+	done := make(chan testImportReport)
+	go func() {
+		report, _ := decodeImportReport(http.Post(server.URL+"/posts/_import", "application/x-ndjson", reader))
+		done <- report
+	}()
+
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(writer, "{\"title\": \"title-%d\"}\n", i)
+	}
+
+	// The progress is available while the body is still streamed, and records are created once it is read.
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		report, err := decodeImportReport(http.Get(server.URL + "/posts/_import"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if report.Running && report.Received == 10 && report.Imported == 0 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			writer.Close()
+			t.Fatalf("expected running import with 10 received, but got %v", report)
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	writer.Close()
+
+	if report := <-done; report.Running || report.Imported != 10 {
+		t.Fatalf("expected finished import with 10 imported, but got %v", report)
+	}
+}
+
+func TestImport_Concurrent(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"posts": {}}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Slow uploads are not cut off by the read timeout of the server.
+	server := httptest.NewUnstartedServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	reader, writer := io.Pipe()
+
+	done := make(chan testImportReport)
+	go func() {
+		report, _ := decodeImportReport(http.Post(server.URL+"/posts/_import?id=slow", "application/x-ndjson", reader))
+		done <- report
+	}()
+
+	fmt.Fprint(writer, "{\"title\": \"slow\"}\n")
+
+	report, err := decodeImportReport(http.Post(server.URL+"/posts/_import", "application/x-ndjson",
+		strings.NewReader("{\"title\": \"fast-1\"}\n{\"title\": \"fast-2\"}\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.ID == "slow" || report.Imported != 2 {
+		t.Fatalf("expected a separate import with 2 imported, but got %v", report)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	fmt.Fprint(writer, "{\"title\": \"slow\"}\n")
+
+	// The progress of each import is kept apart.
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		report, err := decodeImportReport(http.Get(server.URL + "/posts/_import?id=slow"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if report.Running && report.Received == 2 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			writer.Close()
+			t.Fatalf("expected running import with 2 received, but got %v", report)
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	writer.Close()
+
+	if report := <-done; report.ID != "slow" || report.Running || report.Imported != 2 {
+		t.Fatalf("expected finished import with 2 imported, but got %v", report)
+	}
+
+	resp, err := http.Get(server.URL + "/posts/_import?id=unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status code %d, but got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
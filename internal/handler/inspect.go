@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+const (
+	// filteredByHeader is the response header listing the query parameters filtering the resources.
+	filteredByHeader = "X-Filtered-By"
+	// sortedByHeader is the response header describing the order of the resources.
+	sortedByHeader = "X-Sorted-By"
+	// queryWarningsHeader is the response header holding the warnings about ignored query parameters.
+	queryWarningsHeader = "X-Query-Warnings"
+)
+
+var (
+	// listParams lists the reserved query parameters of list requests.
+	listParams = map[string]bool{
+		"q": true, "_highlight": true, "_near": true, "_radius": true, "_sort": true, "_order": true,
+		"_collation": true, "_cursor": true, "_limit": true, "_envelope": true, "_page": true, "_start": true,
+		"_end": true, "_embed": true, "_expand": true,
+	}
+	// exportParams lists the reserved query parameters of export requests.
+	exportParams = map[string]bool{
+		"_near": true, "_radius": true, "_sort": true, "_order": true, "_collation": true,
+	}
+)
+
+// queryInspection describes how the query parameters of a request are interpreted.
+type queryInspection struct {
+	// filters lists the parameters filtering the resources, sorted.
+	filters []string
+	// order describes the order of the resources, e.g. 'lastName asc, age desc', empty if unordered.
+	order string
+	// warnings lists the parameters which are ignored, and why.
+	warnings []string
+}
+
+// inspectQuery describes how the query parameters are interpreted by the request, whose reserved parameters
+// are the supported ones. Parameters which are unknown, filter fields none of the resources has, are repeated,
+// or have no effect without another parameter raise warnings, so typos are not silently ignored.
+func inspectQuery(query url.Values, supported map[string]bool, resources []storage.Resource) queryInspection {
+	var inspection queryInspection
+
+	params := make([]string, 0, len(query))
+	for param := range query {
+		params = append(params, param)
+	}
+
+	sort.Strings(params)
+
+	has := func(param string) bool {
+		return supported[param] && query.Get(param) != ""
+	}
+
+	requires := map[string]string{
+		"_highlight": "q",
+		"_radius":    "_near",
+		"_order":     "_sort",
+		"_collation": "_sort",
+		"_end":       "_start",
+	}
+
+	for _, param := range params {
+		field, _, isFilter := filterParam(param)
+
+		switch {
+		case supported[param]:
+			if (param == "q" || param == "_near" || param == "_sort") && query.Get(param) == "" {
+				inspection.warnings = append(inspection.warnings, fmt.Sprintf("%q is ignored when empty", param))
+				continue
+			}
+
+			if required, ok := requires[param]; ok && !has(required) {
+				inspection.warnings = append(inspection.warnings, fmt.Sprintf("%q is ignored without %q", param, required))
+				continue
+			}
+
+			if param == "_highlight" || param == "_envelope" {
+				if _, err := strconv.ParseBool(query.Get(param)); err != nil {
+					inspection.warnings = append(inspection.warnings, fmt.Sprintf("%q is ignored unless true or false", param))
+					continue
+				}
+			}
+
+			if param == "_envelope" && (!usesCursor(query) || usesOffset(query)) {
+				inspection.warnings = append(inspection.warnings, fmt.Sprintf("%q is ignored without pagination", param))
+				continue
+			}
+
+			if param == "q" || param == "_near" || param == "_radius" {
+				inspection.filters = append(inspection.filters, param)
+			}
+		case isFilter:
+			if len(resources) > 0 && !hasField(resources, field) {
+				inspection.warnings = append(inspection.warnings, fmt.Sprintf("unknown field %q", field))
+				continue
+			}
+
+			inspection.filters = append(inspection.filters, param)
+		default:
+			inspection.warnings = append(inspection.warnings, fmt.Sprintf("unknown query parameter %q", param))
+			continue
+		}
+
+		if len(query[param]) > 1 {
+			inspection.warnings = append(inspection.warnings, fmt.Sprintf("repeated query parameter %q uses the first value", param))
+		}
+	}
+
+	switch {
+	case has("_sort"):
+		fields := strings.Split(query.Get("_sort"), ",")
+
+		var orders []string
+		if has("_order") {
+			orders = strings.Split(query.Get("_order"), ",")
+		}
+
+		terms := make([]string, 0, len(fields))
+		for idx, field := range fields {
+			order := "asc"
+			if idx < len(orders) {
+				order = strings.ToLower(orders[idx])
+			}
+
+			terms = append(terms, field+" "+order)
+		}
+
+		inspection.order = strings.Join(terms, ", ")
+	case has("_near"):
+		inspection.order = "distance asc"
+	case has("q"):
+		inspection.order = "relevance desc"
+	}
+
+	return inspection
+}
+
+// checkQuery describes how the query parameters of the request are interpreted with response headers. In strict
+// mode, requests with ignored parameters fail with 400 instead, listing the reasons. It reports whether the request
+// should be served.
+func checkQuery(w http.ResponseWriter, r *http.Request, supported map[string]bool, resources []storage.Resource, strict bool) bool {
+	inspection := inspectQuery(r.URL.Query(), supported, resources)
+	setQueryHeaders(w, inspection)
+
+	if strict && len(inspection.warnings) > 0 {
+		web.Error(w, http.StatusBadRequest, fmt.Sprintf("%v: %s", errInvalidQuery, strings.Join(inspection.warnings, "; ")))
+		return false
+	}
+
+	return true
+}
+
+// hasField reports whether one of the resources has the field, given by path, even if null.
+func hasField(resources []storage.Resource, path string) bool {
+	keys := strings.Split(path, ".")
+
+	for _, resource := range resources {
+		var val interface{} = map[string]interface{}(resource)
+
+		found := true
+		for _, key := range keys {
+			obj, ok := val.(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+
+			if val, ok = obj[key]; !ok {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setQueryHeaders describes with response headers how the query parameters of the request are interpreted.
+func setQueryHeaders(w http.ResponseWriter, inspection queryInspection) {
+	if len(inspection.filters) > 0 {
+		w.Header().Set(filteredByHeader, strings.Join(inspection.filters, ", "))
+	}
+
+	if inspection.order != "" {
+		w.Header().Set(sortedByHeader, inspection.order)
+	}
+
+	for _, warning := range inspection.warnings {
+		w.Header().Add(queryWarningsHeader, warning)
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// mockDelayHeader is the request header overriding the delay of a single request, with mock headers enabled.
+const mockDelayHeader = "X-Mock-Delay"
+
+var errInvalidMockDelay = errors.New("invalid delay")
+
+// latency is operating as middleware to delay the responses of resources, by a delay sampled from their
+// latency distribution, or else from the fallback distribution for all routes, if any. With mock headers enabled, the X-Mock-Delay header of a request overrides the delay of
+// any route, in milliseconds (e.g. '1500') or as a duration (e.g. '1.5s'). Requests whose client is gone stop
+// waiting.
+func latency(profiles map[string]config.Latency, fallback *config.Latency, mockHeaders bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var delay time.Duration
+
+			if val := r.Header.Get(mockDelayHeader); mockHeaders && val != "" {
+				var err error
+				if delay, err = parseMockDelay(val); err != nil {
+					web.Error(w, http.StatusBadRequest, fmt.Sprintf("%v: %s", err, mockDelayHeader))
+					return
+				}
+			} else if profile, ok := profiles[routeResourceKey(r)]; ok {
+				delay = sampleLatency(profile)
+			} else if fallback != nil {
+				delay = sampleLatency(*fallback)
+			}
+
+			if delay > 0 {
+				timer := time.NewTimer(delay)
+				defer timer.Stop()
+
+				select {
+				case <-timer.C:
+				case <-r.Context().Done():
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// parseMockDelay parses the delay of the X-Mock-Delay header, in milliseconds or as a duration.
+func parseMockDelay(val string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(val, 10, 64); err == nil && ms >= 0 {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+
+	delay, err := time.ParseDuration(val)
+	if err != nil || delay < 0 {
+		return 0, errInvalidMockDelay
+	}
+
+	return delay, nil
+}
+
+// sampleLatency returns a delay of the latency distribution.
+func sampleLatency(l config.Latency) time.Duration {
+	var delay time.Duration
+
+	switch l.Distribution {
+	case config.LatencyFixed:
+		delay = time.Duration(l.Delay)
+	case config.LatencyUniform:
+		min, max := time.Duration(l.Min), time.Duration(l.Max)
+		delay = min
+		if max > min {
+			delay += time.Duration(rand.Int63n(int64(max - min + 1)))
+		}
+	case config.LatencyNormal:
+		delay = time.Duration(float64(l.Mean) + rand.NormFloat64()*float64(l.StdDev))
+
+		if delay < time.Duration(l.Min) {
+			delay = time.Duration(l.Min)
+		}
+
+		if l.Max != 0 && delay > time.Duration(l.Max) {
+			delay = time.Duration(l.Max)
+		}
+	case config.LatencyPoisson:
+		delay = time.Duration(l.Delay) + time.Duration(samplePoisson(l.Rate))*time.Duration(l.Burst)
+	}
+
+	if delay < 0 {
+		return 0
+	}
+
+	return delay
+}
+
+// samplePoisson returns a Poisson distributed number with the provided mean, following Knuth's algorithm
+// which is fast for the small means bursts have.
+func samplePoisson(mean float64) int {
+	limit := math.Exp(-mean)
+
+	k, p := 0, rand.Float64()
+	for p > limit {
+		k++
+		p *= rand.Float64()
+	}
+
+	return k
+}
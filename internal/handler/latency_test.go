@@ -0,0 +1,182 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestLatency(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{
+		"resources": {
+			"fixed": {"latency": {"distribution": "fixed", "delay": "50ms"}},
+			"uniform": {"latency": {"distribution": "uniform", "min": "20ms", "max": "40ms"}},
+			"normal": {"latency": {"distribution": "normal", "mean": "30ms", "stddev": "1h", "min": "20ms", "max": "40ms"}},
+			"poisson": {"latency": {"distribution": "poisson", "delay": "20ms", "burst": "1h", "rate": 0}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resourceKeys := []string{"fixed", "uniform", "normal", "poisson", "none"}
+
+	data := make(storage.Database)
+	for _, resourceKey := range resourceKeys {
+		data[resourceKey] = []storage.Resource{{"id": "1"}}
+	}
+
+	db := storage.NewMemoryDB(data)
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range resourceKeys {
+		if resourceStorage[resourceKey], err = storage.NewMemory(db, resourceKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	testCases := []struct {
+		name string
+		path string
+		min  time.Duration
+		max  time.Duration
+	}{
+		{name: "Delay fixed", path: "/fixed/1", min: 50 * time.Millisecond, max: time.Second},
+		{name: "Delay uniform", path: "/uniform", min: 20 * time.Millisecond, max: time.Second},
+		{name: "Delay normal within bounds", path: "/normal/1", min: 20 * time.Millisecond, max: time.Second},
+		{name: "Delay poisson without bursts", path: "/poisson", min: 20 * time.Millisecond, max: time.Second},
+		{name: "No delay", path: "/none", max: 20 * time.Millisecond},
+	}
+
+	for _, tt := range testCases {
+		start := time.Now()
+
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		elapsed := time.Since(start)
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, http.StatusOK, resp.StatusCode)
+		}
+
+		if elapsed < tt.min || elapsed > tt.max {
+			t.Fatalf("%s: expected response within %v and %v, but got %v", tt.name, tt.min, tt.max, elapsed)
+		}
+	}
+}
+
+func TestLatency_MockDelay(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"posts": {"latency": {"distribution": "fixed", "delay": "1h"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.NewMemoryDB(storage.Database{"posts": {{"id": "1"}}, "users": {{"id": "1"}}})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"posts", "users"} {
+		if resourceStorage[resourceKey], err = storage.NewMemory(db, resourceKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg), handler.WithMockHeaders()))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		path       string
+		delay      string
+		statusCode int
+		min        time.Duration
+		max        time.Duration
+	}{
+		{name: "Delay in milliseconds", path: "/users/1", delay: "50", statusCode: http.StatusOK, min: 50 * time.Millisecond, max: time.Second},
+		{name: "Delay as duration", path: "/users", delay: "50ms", statusCode: http.StatusOK, min: 50 * time.Millisecond, max: time.Second},
+		{name: "Override latency of resource", path: "/posts/1", delay: "0", statusCode: http.StatusOK, max: time.Second},
+		{name: "Invalid delay", path: "/users/1", delay: "soon", statusCode: http.StatusBadRequest, max: time.Second},
+		{name: "Negative delay", path: "/users/1", delay: "-1s", statusCode: http.StatusBadRequest, max: time.Second},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("X-Mock-Delay", tt.delay)
+
+		start := time.Now()
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		elapsed := time.Since(start)
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if elapsed < tt.min || elapsed > tt.max {
+			t.Fatalf("%s: expected response within %v and %v, but got %v", tt.name, tt.min, tt.max, elapsed)
+		}
+	}
+}
+
+func TestLatency_Delay(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"fixed": {"latency": {"distribution": "fixed", "delay": "1ms"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.NewMemoryDB(storage.Database{"fixed": {{"id": "1"}}, "posts": {{"id": "1"}}})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"fixed", "posts"} {
+		if resourceStorage[resourceKey], err = storage.NewMemory(db, resourceKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg), handler.WithDelay(100*time.Millisecond)))
+	defer server.Close()
+
+	testCases := []struct {
+		name string
+		path string
+		min  time.Duration
+		max  time.Duration
+	}{
+		{name: "Delayed resource", path: "/posts", min: 100 * time.Millisecond, max: time.Second},
+		{name: "Resource with latency", path: "/fixed", max: 90 * time.Millisecond},
+	}
+
+	for _, tt := range testCases {
+		start := time.Now()
+
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if elapsed := time.Since(start); elapsed < tt.min || elapsed > tt.max {
+			t.Fatalf("%s: expected a response within %v and %v, but got %v", tt.name, tt.min, tt.max, elapsed)
+		}
+	}
+}
@@ -1,22 +1,145 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/chanioxaris/json-server/internal/search"
 	"github.com/chanioxaris/json-server/internal/storage"
 	"github.com/chanioxaris/json-server/internal/web"
 )
 
-// List operates as a http handler, to return all available resources.
-func List(storageSvc storage.Storage) http.HandlerFunc {
+// errTooManyResources returns an error when a list request without pagination has too many resources.
+var errTooManyResources = errors.New("too many resources to list without pagination")
+
+// statusClientClosedRequest is the status of requests whose client is gone before the response, as used by
+// nginx. It is never read by the client, only logged.
+const statusClientClosedRequest = 499
+
+// ListOptions configures the list handler.
+type ListOptions struct {
+	// StrictQueries rejects requests with unknown or ignored query parameters.
+	StrictQueries bool
+	// MaxUnpaginated is the largest number of resources returned without pagination, unlimited if zero.
+	MaxUnpaginated int
+	// Relations resolves the related resources requested by '_embed' and '_expand', of the ResourceKey.
+	Relations   *storage.Relations
+	ResourceKey string
+}
+
+// List operates as a http handler, to return all available resources. The search cache holds the index
+// of the 'q' full-text search.
+func List(storageSvc storage.Storage, searchCache *search.Cache, opts ListOptions) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Find all resources.
-		data, err := storageSvc.Find()
+		// Find all resources, unless the client is gone meanwhile.
+		data, err := storage.FindContext(r.Context(), traced(r, storageSvc))
+		if err != nil {
+			findError(w, r)
+			return
+		}
+
+		// Describe how the query parameters are interpreted, and warn about ignored ones.
+		if !checkQuery(w, r, listParams, data, opts.StrictQueries) {
+			return
+		}
+
+		total := len(data)
+
+		// Search resources, most relevant first.
+		if q := r.URL.Query().Get("q"); q != "" {
+			highlight, _ := strconv.ParseBool(r.URL.Query().Get("_highlight"))
+			data = searchResources(data, searchCache, q, highlight)
+		}
+
+		// Filter resources based on the query parameters.
+		data, err = queryResources(data, r.URL.Query())
 		if err != nil {
-			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			web.Error(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		traceFilter(r, r.URL.Query(), total, len(data))
+
+		// Searching and filtering large collections takes a while, so skip the rest once the client is gone.
+		if clientGone(w, r) {
+			return
+		}
+
+		// Paginate by page or position, with the number of resources in the X-Total-Count header.
+		if usesOffset(r.URL.Query()) {
+			page, err := paginateOffset(data, r.URL.Query())
+			if err != nil {
+				web.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			w.Header().Set(totalCountHeader, strconv.Itoa(len(data)))
+
+			if links := pageLinks(r, len(data)); links != "" {
+				w.Header().Set("Link", links)
+			}
+
+			data = page
+		} else if usesCursor(r.URL.Query()) {
+			// Paginate with an opaque cursor pointing after the last resource of the page.
+			page, next, err := paginateCursor(data, r.URL.Query())
+			if err != nil {
+				web.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			if next != "" {
+				w.Header().Set(nextCursorHeader, next)
+			}
+
+			if envelope, _ := strconv.ParseBool(r.URL.Query().Get("_envelope")); envelope {
+				if page, err = related(w, r, opts.Relations, opts.ResourceKey, page); err != nil {
+					relatedError(w, err)
+					return
+				}
+
+				web.Success(w, http.StatusOK, cursorPage{Data: page, NextCursor: next})
+				return
+			}
+
+			data = page
+		} else if opts.MaxUnpaginated > 0 && len(data) > opts.MaxUnpaginated {
+			// Refuse to dump large collections at once, which would freeze both server and client.
+			web.Error(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("%v: %d of at most %d, paginate with e.g. '_limit=%d'",
+				errTooManyResources, len(data), opts.MaxUnpaginated, defaultCursorLimit))
+			return
+		}
+
+		// Embed and expand the related resources of the returned ones only.
+		if data, err = related(w, r, opts.Relations, opts.ResourceKey, data); err != nil {
+			relatedError(w, err)
 			return
 		}
 
 		web.Success(w, http.StatusOK, data)
 	}
 }
+
+// findError responds the failure to find the resources. Requests whose client is gone get no body, as no one
+// would read it.
+func findError(w http.ResponseWriter, r *http.Request) {
+	if clientGone(w, r) {
+		return
+	}
+
+	web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+}
+
+// clientGone reports whether the client of the request is gone, in which case it responds the status only, for
+// the logs.
+func clientGone(w http.ResponseWriter, r *http.Request) bool {
+	if r.Context().Err() == nil {
+		return false
+	}
+
+	w.WriteHeader(statusClientClosedRequest)
+
+	return true
+}
@@ -3,11 +3,15 @@ package handler_test
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/chanioxaris/json-server/internal/handler"
 	"github.com/chanioxaris/json-server/internal/storage"
 )
 
@@ -58,3 +62,771 @@ func TestList(t *testing.T) {
 		}
 	}
 }
+
+func TestList_DateRange(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"events": {
+			{"id": "1", "createdAt": "2023-12-31T23:00:00Z"},
+			{"id": "2", "createdAt": "2024-01-15"},
+			{"id": "3", "createdAt": "2024-01-20T10:00:00+02:00"},
+			{"id": "4", "createdAt": 1706745600},
+			{"id": "5", "createdAt": "1705312800000"},
+			{"id": "6", "createdAt": "not a date"},
+		},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "events")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"events": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		query       string
+		statusCode  int
+		expectedIds []string
+	}{
+		{
+			name:        "After date",
+			query:       "createdAt_after=2024-01-01",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"2", "3", "4", "5"},
+		},
+		{
+			name:        "Between dates",
+			query:       "createdAt_after=2024-01-01&createdAt_before=2024-02-01",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"2", "3", "5"},
+		},
+		{
+			name:        "Before epoch timestamp",
+			query:       "createdAt_before=1705276800",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"1"},
+		},
+		{
+			name:       "Invalid date",
+			query:      "createdAt_after=yesterday",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(fmt.Sprintf("%s/events?%s", server.URL, tt.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		var body []storage.Resource
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, 0, len(body))
+		for _, resource := range body {
+			ids = append(ids, resource["id"].(string))
+		}
+
+		if !reflect.DeepEqual(ids, tt.expectedIds) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.expectedIds, ids)
+		}
+	}
+}
+
+func TestList_Near(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"stores": {
+			{"id": "gothenburg", "lat": 57.7089, "lng": 11.9746},
+			{"id": "stockholm", "lat": 59.3293, "lng": 18.0686},
+			{"id": "uppsala", "location": map[string]interface{}{"latitude": 59.8586, "longitude": 17.6389}},
+			{"id": "unknown"},
+		},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "stores")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"stores": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		query       string
+		statusCode  int
+		expectedIds []string
+	}{
+		{
+			name:        "Near without radius",
+			query:       "_near=59.33,18.06",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"stockholm", "uppsala", "gothenburg"},
+		},
+		{
+			name:        "Near within radius",
+			query:       "_near=59.33,18.06&_radius=100km",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"stockholm", "uppsala"},
+		},
+		{
+			name:        "Near within radius in meters",
+			query:       "_near=59.33,18.06&_radius=1000m",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"stockholm"},
+		},
+		{
+			name:       "Invalid location",
+			query:      "_near=north",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid radius",
+			query:      "_near=59.33,18.06&_radius=5ly",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(fmt.Sprintf("%s/stores?%s", server.URL, tt.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		var body []storage.Resource
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, 0, len(body))
+		for _, resource := range body {
+			ids = append(ids, resource["id"].(string))
+		}
+
+		if !reflect.DeepEqual(ids, tt.expectedIds) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.expectedIds, ids)
+		}
+	}
+}
+
+func TestList_Sort(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"cities": {
+			{"id": "1", "name": "Örebro", "population": 156381, "country": map[string]interface{}{"code": "se"}},
+			{"id": "2", "name": "Åre", "population": 3200, "country": map[string]interface{}{"code": "se"}},
+			{"id": "3", "name": "Aalborg", "population": 119862, "country": map[string]interface{}{"code": "dk"}},
+			{"id": "4", "name": "Zürich", "population": 421878},
+			{"id": "5", "name": "Uppsala", "population": 177074, "country": map[string]interface{}{"code": "se"}},
+			{"id": "6", "name": "Ängelholm", "population": 42131, "country": map[string]interface{}{"code": "se"}},
+		},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "cities")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"cities": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		query       string
+		statusCode  int
+		expectedIds []string
+	}{
+		{
+			name:        "Sort by code point",
+			query:       "_sort=name",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"3", "5", "4", "6", "2", "1"},
+		},
+		{
+			name:        "Sort with swedish collation",
+			query:       "_sort=name&_collation=sv",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"3", "5", "4", "2", "6", "1"},
+		},
+		{
+			name:        "Sort with root collation",
+			query:       "_sort=name&_collation=en",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"3", "6", "2", "1", "5", "4"},
+		},
+		{
+			name:        "Sort numbers descending",
+			query:       "_sort=population&_order=desc",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"4", "5", "1", "3", "6", "2"},
+		},
+		{
+			name:        "Sort by nested fields, missing last",
+			query:       "_sort=country.code,name&_order=desc,asc&_collation=sv",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"5", "2", "6", "1", "3", "4"},
+		},
+		{
+			name:       "Invalid order",
+			query:      "_sort=name&_order=sideways",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Unknown collation",
+			query:      "_sort=name&_collation=tlh",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(fmt.Sprintf("%s/cities?%s", server.URL, tt.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		var body []storage.Resource
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, 0, len(body))
+		for _, resource := range body {
+			ids = append(ids, resource["id"].(string))
+		}
+
+		if !reflect.DeepEqual(ids, tt.expectedIds) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.expectedIds, ids)
+		}
+	}
+}
+
+func TestList_Search(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"books": {
+			{"id": "1", "title": "Clean Code"},
+			{"id": "2", "title": "The Clean Coder"},
+			{"id": "3", "title": "Refactoring"},
+		},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "books")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"books": storageSvc}))
+	defer server.Close()
+
+	search := func(query string) []storage.Resource {
+		resp, err := http.Get(fmt.Sprintf("%s/books?%s", server.URL, query))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var body []storage.Resource
+		if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+
+		return body
+	}
+
+	if body := search("q=refactorng"); len(body) != 1 || body[0]["id"] != "3" {
+		t.Fatalf("expected fuzzy match of resource 3, but got %v", body)
+	}
+
+	body := search("q=coder&_highlight=true")
+	expectedHighlight := map[string]interface{}{"title": "The Clean <mark>Coder</mark>"}
+	if len(body) == 0 || !reflect.DeepEqual(body[0]["_highlight"], expectedHighlight) {
+		t.Fatalf("expected highlighted resource 2 first, but got %v", body)
+	}
+
+	// Writes are searchable right away.
+	req, err := http.NewRequest(http.MethodPatch, server.URL+"/books/3", strings.NewReader(`{"title": "Working Effectively with Legacy Code"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if body = search("q=legacy"); len(body) != 1 || body[0]["id"] != "3" {
+		t.Fatalf("expected updated resource 3, but got %v", body)
+	}
+}
+
+func TestList_Cursor(t *testing.T) {
+	resources := make([]storage.Resource, 0)
+	for i := 1; i <= 5; i++ {
+		resources = append(resources, storage.Resource{"id": fmt.Sprint(i)})
+	}
+
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{"items": resources}), "items")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"items": storageSvc}))
+	defer server.Close()
+
+	// Walk all pages by following the next cursor.
+	ids := make([]string, 0)
+	pages := 0
+	query := "_cursor=&_limit=2"
+
+	for {
+		resp, err := http.Get(fmt.Sprintf("%s/items?%s", server.URL, query))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body []storage.Resource
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, resource := range body {
+			ids = append(ids, resource["id"].(string))
+		}
+
+		pages++
+
+		next := resp.Header.Get("X-Next-Cursor")
+		if next == "" {
+			break
+		}
+
+		// The cursor still works after its resource is deleted.
+		if pages == 1 {
+			if err = storageSvc.Delete("2"); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		query = "_limit=2&_cursor=" + next
+	}
+
+	if expected := []string{"1", "2", "3", "4", "5"}; pages != 3 || !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("expected ids %v in 3 pages, but got %v in %d pages", expected, ids, pages)
+	}
+
+	// The envelope holds the next cursor as well.
+	resp, err := http.Get(server.URL + "/items?_limit=3&_envelope=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var page struct {
+		Data       []storage.Resource `json:"data"`
+		NextCursor string             `json:"nextCursor"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(page.Data) != 3 || page.NextCursor == "" {
+		t.Fatalf("expected 3 resources and a next cursor, but got %v", page)
+	}
+
+	resp, err = http.Get(server.URL + "/items?_cursor=invalid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status code %v, but got %v", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestList_QueryHeaders(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "json-server", "createdAt": "2024-01-02"}},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		query      string
+		filteredBy string
+		sortedBy   string
+		warnings   []string
+	}{
+		{
+			name:       "Filter and sort",
+			query:      "createdAt_after=2024-01-01&q=json&_sort=title,id&_order=desc",
+			filteredBy: "createdAt_after, q",
+			sortedBy:   "title desc, id asc",
+		},
+		{
+			name:       "Sort by relevance",
+			query:      "q=json",
+			filteredBy: "q",
+			sortedBy:   "relevance desc",
+		},
+		{
+			name:     "Unknown parameter",
+			query:    "_pge=2",
+			warnings: []string{`unknown query parameter "_pge"`},
+		},
+		{
+			name:     "Unknown field",
+			query:    "titel=json",
+			warnings: []string{`unknown field "titel"`},
+		},
+		{
+			name:       "Filter by field",
+			query:      "title=json-server&createdAt_lte=2024-01-02",
+			filteredBy: "createdAt_lte, title",
+		},
+		{
+			name:     "Parameter without required parameter",
+			query:    "_order=desc&_radius=5km",
+			warnings: []string{`"_order" is ignored without "_sort"`, `"_radius" is ignored without "_near"`},
+		},
+		{
+			name:       "Repeated parameter",
+			query:      "q=json&q=go",
+			filteredBy: "q",
+			sortedBy:   "relevance desc",
+			warnings:   []string{`repeated query parameter "q" uses the first value`},
+		},
+		{
+			name:     "Empty parameter",
+			query:    "_sort=",
+			warnings: []string{`"_sort" is ignored when empty`},
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(fmt.Sprintf("%s/posts?%s", server.URL, tt.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if got := resp.Header.Get("X-Filtered-By"); got != tt.filteredBy {
+			t.Fatalf("%s: expected filtered by %q, but got %q", tt.name, tt.filteredBy, got)
+		}
+
+		if got := resp.Header.Get("X-Sorted-By"); got != tt.sortedBy {
+			t.Fatalf("%s: expected sorted by %q, but got %q", tt.name, tt.sortedBy, got)
+		}
+
+		if got := resp.Header.Values("X-Query-Warnings"); !reflect.DeepEqual(got, tt.warnings) {
+			t.Fatalf("%s: expected warnings %q, but got %q", tt.name, tt.warnings, got)
+		}
+	}
+}
+
+func TestList_StrictQueries(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "json-server", "comments": []interface{}{map[string]interface{}{"id": "1"}}}},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, handler.WithStrictQueries()))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		path       string
+		statusCode int
+		message    string
+	}{
+		{name: "Known parameters", path: "/posts?q=json&_sort=title", statusCode: http.StatusOK},
+		{name: "Unknown field", path: "/posts?titel=json", statusCode: http.StatusBadRequest, message: `unknown field "titel"`},
+		{name: "Invalid boolean", path: "/posts?q=json&_highlight=yes please", statusCode: http.StatusBadRequest, message: `"_highlight" is ignored unless true or false`},
+		{name: "Unknown parameter of export", path: "/posts/_export?q=json", statusCode: http.StatusBadRequest, message: `unknown query parameter "q"`},
+		{name: "Unknown parameter of nested collection", path: "/posts/1/comments?_pge=2", statusCode: http.StatusBadRequest, message: `unknown query parameter "_pge"`},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + strings.ReplaceAll(tt.path, " ", "%20"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if !strings.Contains(string(body), strings.ReplaceAll(tt.message, `"`, `\"`)) {
+			t.Fatalf("%s: expected message %v, but got %s", tt.name, tt.message, body)
+		}
+	}
+}
+
+func TestList_MaxUnpaginated(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "json-server"}, {"id": "2", "title": "go"}, {"id": "3", "title": "json"}},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, handler.WithMaxUnpaginated(2)))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+	}{
+		{name: "List too many resources", statusCode: http.StatusRequestEntityTooLarge},
+		{name: "List filtered resources", query: "q=json", statusCode: http.StatusOK},
+		{name: "List paginated resources", query: "_limit=2", statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(fmt.Sprintf("%s/posts?%s", server.URL, tt.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestList_Filter(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": {
+			{"id": "1", "author": "alice", "views": 10, "published": true, "tags": []interface{}{"go", "json"}},
+			{"id": "2", "author": "bob", "views": 250, "published": false, "tags": []interface{}{"rust"}},
+			{"id": "3", "author": "alice", "views": 99.5, "meta": map[string]interface{}{"lang": "sv"}},
+			{"id": "4", "author": "carol", "views": nil},
+		},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		query       string
+		expectedIds []string
+	}{
+		{name: "Filter by field", query: "author=alice", expectedIds: []string{"1", "3"}},
+		{name: "Filter by any of the values", query: "author=bob&author=carol", expectedIds: []string{"2", "4"}},
+		{name: "Filter by number", query: "views=10.0", expectedIds: []string{"1"}},
+		{name: "Filter by boolean", query: "published=false", expectedIds: []string{"2"}},
+		{name: "Filter by array item", query: "tags=json", expectedIds: []string{"1"}},
+		{name: "Filter by nested field", query: "meta.lang=sv", expectedIds: []string{"3"}},
+		{name: "Filter by range", query: "views_gte=50&views_lte=250", expectedIds: []string{"2", "3"}},
+		{name: "Filter by string range", query: "author_gte=b", expectedIds: []string{"2", "4"}},
+		{name: "Filter by inequality", query: "author_ne=alice", expectedIds: []string{"2", "4"}},
+		{name: "Filter and sort", query: "author_ne=carol&_sort=views&_order=desc", expectedIds: []string{"2", "3", "1"}},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(fmt.Sprintf("%s/posts?%s", server.URL, tt.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body []storage.Resource
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, 0, len(body))
+		for _, resource := range body {
+			ids = append(ids, resource["id"].(string))
+		}
+
+		if !reflect.DeepEqual(ids, tt.expectedIds) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.expectedIds, ids)
+		}
+	}
+}
+
+func TestList_Page(t *testing.T) {
+	resources := make([]storage.Resource, 0)
+	for idx := 1; idx <= 25; idx++ {
+		resources = append(resources, storage.Resource{"id": fmt.Sprint(idx), "even": idx%2 == 0})
+	}
+
+	db := storage.NewMemoryDB(storage.Database{"posts": resources})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name        string
+		query       string
+		statusCode  int
+		expectedIds []string
+		totalCount  string
+		link        string
+	}{
+		{
+			name:        "First page",
+			query:       "_page=1&_limit=2",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"1", "2"},
+			totalCount:  "25",
+			link:        `</posts?_limit=2&_page=1>; rel="first", </posts?_limit=2&_page=2>; rel="next", </posts?_limit=2&_page=13>; rel="last"`,
+		},
+		{
+			name:        "Last page with default limit",
+			query:       "_page=3",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"21", "22", "23", "24", "25"},
+			totalCount:  "25",
+			link:        `</posts?_page=1>; rel="first", </posts?_page=2>; rel="prev", </posts?_page=3>; rel="last"`,
+		},
+		{
+			name:        "Page of filtered resources",
+			query:       "even=true&_page=2&_limit=5",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"12", "14", "16", "18", "20"},
+			totalCount:  "12",
+			link:        `</posts?_limit=5&_page=1&even=true>; rel="first", </posts?_limit=5&_page=1&even=true>; rel="prev", </posts?_limit=5&_page=3&even=true>; rel="next", </posts?_limit=5&_page=3&even=true>; rel="last"`,
+		},
+		{
+			name:        "Page past the last one",
+			query:       "_page=9999999999999&_limit=10",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{},
+			totalCount:  "25",
+			link:        `</posts?_limit=10&_page=1>; rel="first", </posts?_limit=10&_page=9999999999998>; rel="prev", </posts?_limit=10&_page=3>; rel="last"`,
+		},
+		{
+			name:        "Slice by position",
+			query:       "_start=3&_end=6",
+			statusCode:  http.StatusOK,
+			expectedIds: []string{"4", "5", "6"},
+			totalCount:  "25",
+		},
+		{
+			name:       "Invalid page",
+			query:      "_page=0",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Invalid end",
+			query:      "_start=5&_end=2",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(fmt.Sprintf("%s/posts?%s", server.URL, tt.query))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		var body []storage.Resource
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, 0, len(body))
+		for _, resource := range body {
+			ids = append(ids, resource["id"].(string))
+		}
+
+		if !reflect.DeepEqual(ids, tt.expectedIds) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.expectedIds, ids)
+		}
+
+		if got := resp.Header.Get("X-Total-Count"); got != tt.totalCount {
+			t.Fatalf("%s: expected total count %v, but got %v", tt.name, tt.totalCount, got)
+		}
+
+		if got := resp.Header.Get("Link"); got != tt.link {
+			t.Fatalf("%s: expected link %v, but got %v", tt.name, tt.link, got)
+		}
+	}
+}
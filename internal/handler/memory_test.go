@@ -0,0 +1,80 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestMemory(t *testing.T) {
+	data := storage.Database{
+		"posts":    {{"id": "1", "title": "json-server"}, {"id": "2", "title": "json-server"}},
+		"comments": {{"id": "1"}},
+	}
+
+	resourceStorage := make(map[string]storage.Storage)
+	for resourceKey := range data {
+		storageSvc, err := storage.NewMock(data, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/__debug/memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %v, but got %v", http.StatusOK, resp.StatusCode)
+	}
+
+	var got struct {
+		Collections []struct {
+			Name    string `json:"name"`
+			Records int    `json:"records"`
+			Bytes   int    `json:"bytes"`
+		} `json:"collections"`
+		Records int `json:"records"`
+		Bytes   int `json:"bytes"`
+		Memory  struct {
+			HeapAllocBytes uint64 `json:"heapAllocBytes"`
+		} `json:"memory"`
+		Goroutines int `json:"goroutines"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.Collections) != 2 {
+		t.Fatalf("expected 2 collections, but got %v", len(got.Collections))
+	}
+
+	// Collections are sorted by size, largest first.
+	if got.Collections[0].Name != "posts" || got.Collections[0].Records != 2 {
+		t.Fatalf("expected posts with 2 records first, but got %+v", got.Collections[0])
+	}
+
+	if got.Records != 3 {
+		t.Fatalf("expected 3 records, but got %v", got.Records)
+	}
+
+	if got.Bytes != got.Collections[0].Bytes+got.Collections[1].Bytes {
+		t.Fatalf("expected %v bytes, but got %v", got.Collections[0].Bytes+got.Collections[1].Bytes, got.Bytes)
+	}
+
+	if got.Memory.HeapAllocBytes == 0 || got.Goroutines == 0 {
+		t.Fatalf("expected memory stats, but got %+v", got)
+	}
+}
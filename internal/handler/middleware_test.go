@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	testCases := []struct {
+		name       string
+		middleware string
+		statusCode int
+		allowed    string
+	}{
+		{
+			name:       "Default order",
+			middleware: `[]`,
+			statusCode: http.StatusUnauthorized,
+			allowed:    "https://app.example.com",
+		},
+		{
+			name:       "Chaos before auth",
+			middleware: `["cors", "chaos", "auth"]`,
+			statusCode: http.StatusInternalServerError,
+			allowed:    "https://app.example.com",
+		},
+		{
+			name:       "Auth and cors disabled",
+			middleware: `["logging", "chaos"]`,
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "Chaos disabled",
+			middleware: `["auth"]`,
+			statusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range testCases {
+		cfg, err := config.Parse([]byte(`{"middleware": ` + tt.middleware + `}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		server := testModesServer(t,
+			handler.WithConfig(cfg),
+			handler.WithAuth(tokens, "users", "posts"),
+			handler.WithChaos(1),
+			handler.WithAllowOrigins("https://app.example.com"),
+		)
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/posts", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Origin", "https://app.example.com")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		server.Close()
+
+		// Chaos fails requests with any server error.
+		statusCode := resp.StatusCode
+		if statusCode > http.StatusInternalServerError {
+			statusCode = http.StatusInternalServerError
+		}
+
+		if statusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tt.allowed {
+			t.Fatalf("%s: expected allowed origin %q, but got %q", tt.name, tt.allowed, got)
+		}
+	}
+}
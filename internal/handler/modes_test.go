@@ -0,0 +1,678 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func testModesServer(t *testing.T, opts ...handler.Option) *httptest.Server {
+	db := storage.NewMemoryDB(storage.Database{
+		"countries": {{"id": "1", "name": "Greece"}},
+		"events":    {{"id": "1", "type": "click"}},
+		"secrets":   {{"id": "1", "value": "secret"}},
+		"posts":     {{"id": "1", "title": "title-1"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"countries", "events", "secrets", "posts", ""} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key == "" {
+			key = "db"
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	return httptest.NewServer(handler.Setup(resourceStorage, opts...))
+}
+
+func TestResourceModes(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {
+		"countries": {"readOnly": true},
+		"events": {"writeOnly": true},
+		"secrets": {"hidden": true}
+	}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := testModesServer(t, handler.WithConfig(cfg))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		statusCode int
+	}{
+		{
+			name:       "Read read-only resource",
+			method:     http.MethodGet,
+			path:       "/countries/1",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Update read-only resource",
+			method:     http.MethodPatch,
+			path:       "/countries/1",
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "Create on read-only resource",
+			method:     http.MethodPost,
+			path:       "/countries",
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "Create on write-only resource",
+			method:     http.MethodPost,
+			path:       "/events",
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "List write-only resource",
+			method:     http.MethodGet,
+			path:       "/events",
+			statusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:       "Read hidden resource",
+			method:     http.MethodGet,
+			path:       "/secrets/1",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Update regular resource",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(`{"field": "value"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data := make(storage.Database)
+	if err = json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"events", "secrets"} {
+		if _, ok := data[key]; ok {
+			t.Fatalf("expected db without resource %v, but got %v", key, data)
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	server := testModesServer(t, handler.WithReadOnly(), handler.WithCapture(10))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "Read resource", method: http.MethodGet, path: "/posts/1", statusCode: http.StatusOK},
+		{name: "Create resource", method: http.MethodPost, path: "/posts", body: `{"title": "title-2"}`, statusCode: http.StatusForbidden},
+		{name: "Update resource", method: http.MethodPatch, path: "/posts/1", body: `{"title": "updated"}`, statusCode: http.StatusForbidden},
+		{name: "Delete resource", method: http.MethodDelete, path: "/posts/1", statusCode: http.StatusForbidden},
+		{name: "Advance clock", method: http.MethodPost, path: "/__time/advance", body: `{"duration": "1h"}`, statusCode: http.StatusForbidden},
+		{name: "Verify requests", method: http.MethodPost, path: "/__verify", body: `{"method": "GET", "path": "/posts/1"}`, statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body := make(map[string]interface{})
+		if tt.statusCode == http.StatusForbidden {
+			if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("%s: expected json error body, but got %v", tt.name, err)
+			}
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode == http.StatusForbidden && body["error"] != "server is read-only" {
+			t.Fatalf("%s: expected error %q, but got %v", tt.name, "server is read-only", body)
+		}
+	}
+}
+
+func TestPrivateFields(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {{"id": "1", "email": "admin@example.com", "password": "secret", "ssn": "123"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, key := range []string{"users", ""} {
+		storageSvc, err := storage.NewMemory(db, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key == "" {
+			key = "db"
+		}
+
+		resourceStorage[key] = storageSvc
+	}
+
+	cfg, err := config.Parse([]byte(`{"private": ["password"], "resources": {"users": {"private": ["ssn"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	expected := storage.Resource{"id": "1", "email": "admin@example.com"}
+
+	for _, path := range []string{"/users/1", "/db"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got storage.Resource
+		if path == "/db" {
+			data := make(storage.Database)
+			err = json.NewDecoder(resp.Body).Decode(&data)
+			got = data["users"][0]
+		} else {
+			err = json.NewDecoder(resp.Body).Decode(&got)
+		}
+		resp.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("%s: expected resource %v, but got %v", path, expected, got)
+		}
+	}
+
+	// Private fields are kept in storage.
+	stored, err := resourceStorage["users"].FindById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored["password"] != "secret" {
+		t.Fatalf("expected stored password %v, but got %v", "secret", stored["password"])
+	}
+}
+
+func TestComputedFields(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"orders": {{"id": "1", "firstName": "John", "lastName": "Doe", "qty": 3, "price": 2.5}},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Parse([]byte(`{"resources": {"orders": {"computed": {
+		"fullName": "firstName + \" \" + lastName",
+		"total": "qty * price",
+		"invalid": "firstName * 2"
+	}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"orders": storageSvc}, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPatch, server.URL+"/orders/1", strings.NewReader(`{"qty": 4, "total": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var got storage.Resource
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := storage.Resource{
+		"id": "1", "firstName": "John", "lastName": "Doe", "qty": float64(4), "price": 2.5,
+		"fullName": "John Doe", "total": float64(10), "invalid": nil,
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected resource %v, but got %v", expected, got)
+	}
+
+	// Computed fields are never stored.
+	stored, err := storageSvc.FindById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := stored["total"]; ok {
+		t.Fatalf("expected stored resource without computed fields, but got %v", stored)
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{"posts": {}})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Parse([]byte(`{"resources": {"posts": {"defaults": {
+		"status": "draft",
+		"likes": 0,
+		"createdBy": "{{ auth.sub }}",
+		"slug": "post-{{ lower(body.title) }}",
+		"invalid": "{{ body.title * 2 }}"
+	}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	token, err := tokens.Issue(auth.Claims{"sub": "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc},
+		handler.WithConfig(cfg), handler.WithAuth(tokens, "users")))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/posts", strings.NewReader(`{"id": "1", "title": "Hello", "status": "published"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %d, but got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	var got storage.Resource
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := storage.Resource{
+		"id": "1", "title": "Hello", "status": "published", "likes": float64(0),
+		"createdBy": "42", "slug": "post-hello", "invalid": nil,
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected resource %v, but got %v", expected, got)
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"posts": {"required": ["title", "author"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := testModesServer(t, handler.WithConfig(cfg))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		error      string
+	}{
+		{
+			name:       "Create with all required fields",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `{"title": "title-2", "author": "john"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Create without required fields",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `{"body": "text"}`,
+			statusCode: http.StatusUnprocessableEntity,
+			error:      "missing required fields: title, author",
+		},
+		{
+			name:       "Replace with null required field",
+			method:     http.MethodPut,
+			path:       "/posts/1",
+			body:       `{"title": "title-1", "author": null}`,
+			statusCode: http.StatusUnprocessableEntity,
+			error:      "missing required fields: author",
+		},
+		{
+			name:       "Update without required fields",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			body:       `{"body": "text"}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Update with null required field",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			body:       `{"title": null}`,
+			statusCode: http.StatusUnprocessableEntity,
+			error:      "missing required fields: title",
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.error != "" && got["error"] != tt.error {
+			t.Fatalf("%s: expected error %q, but got %v", tt.name, tt.error, got["error"])
+		}
+	}
+}
+
+func TestUniqueFields(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"posts": {"unique": ["title"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := testModesServer(t, handler.WithConfig(cfg))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Create with unique value",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `{"id": "2", "title": "title-2"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Create with duplicate value",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `{"title": "title-1"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Replace keeping own value",
+			method:     http.MethodPut,
+			path:       "/posts/1",
+			body:       `{"title": "title-1", "body": "text"}`,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Update with duplicate value",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			body:       `{"title": "title-2"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Update with null value",
+			method:     http.MethodPatch,
+			path:       "/posts/2",
+			body:       `{"title": null}`,
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestReadBy(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"posts": {"unique": ["title"]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := testModesServer(t, handler.WithConfig(cfg))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		path       string
+		statusCode int
+	}{
+		{
+			name:       "Look up by field",
+			path:       "/posts/title/title-1",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Look up by field with 'by' prefix",
+			path:       "/posts/by/title/title-1",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Look up missing value",
+			path:       "/posts/title/title-2",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Look up by field which is not unique",
+			path:       "/countries/name/Greece",
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got storage.Resource
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&got)
+		}
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode == http.StatusOK && got["id"] != "1" {
+			t.Fatalf("%s: expected resource with id 1, but got %v", tt.name, got)
+		}
+	}
+}
+
+func TestRouting(t *testing.T) {
+	testCases := []struct {
+		name       string
+		opts       []handler.Option
+		method     string
+		path       string
+		statusCode int
+	}{
+		{
+			name:       "Mixed case path",
+			method:     http.MethodGet,
+			path:       "/Posts/1",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Mixed case path ignoring case",
+			opts:       []handler.Option{handler.WithIgnoreCase()},
+			method:     http.MethodGet,
+			path:       "/Posts/1",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Mixed case id ignoring case",
+			opts:       []handler.Option{handler.WithIgnoreCase()},
+			method:     http.MethodGet,
+			path:       "/posts/ID",
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Trailing slash",
+			method:     http.MethodPost,
+			path:       "/posts/",
+			statusCode: http.StatusMovedPermanently,
+		},
+		{
+			name:       "Trailing slash ignored",
+			opts:       []handler.Option{handler.WithIgnoreTrailingSlash()},
+			method:     http.MethodPost,
+			path:       "/posts/",
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Trailing slash and mixed case ignored",
+			opts:       []handler.Option{handler.WithIgnoreCase(), handler.WithIgnoreTrailingSlash()},
+			method:     http.MethodGet,
+			path:       "/POSTS/1/",
+			statusCode: http.StatusOK,
+		},
+	}
+
+	// Redirects are returned as is.
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	for _, tt := range testCases {
+		server := testModesServer(t, tt.opts...)
+
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(`{"title": "title-2"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		server.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/search"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// nestedHandler operates as a http handler, serving the requests of the collections nested in the resources,
+// e.g. '/users/1/orders', with the handler of their storage. Resources without such a collection serve the
+// resources of another key pointing at them, e.g. the comments of '/posts/1/comments' whose 'postId' is 1,
+// when the relations of the parent key are given.
+func nestedHandler(nested *storage.Nested, relations *storage.Relations, parentKey string, h func(storageSvc storage.Storage) http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		storageSvc, err := nested.Collection(vars["parentId"], vars["nested"])
+		if errors.Is(err, storage.ErrResourceNotFound) && relations != nil {
+			if storageSvc, err = relations.Children(parentKey, vars["parentId"], vars["nested"]); err == nil {
+				// The resources belong to another collection, whose writes would not drop the cached response.
+				skipCache(w)
+			}
+		}
+
+		if err != nil {
+			// Parent resource or nested collection not found.
+			if errors.Is(err, storage.ErrResourceNotFound) {
+				web.Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		h(storageSvc)(w, r)
+	}
+}
+
+// nestedList returns the list handler of nested collections. Nested collections of different parents share
+// no search index, so each request builds its own.
+func nestedList(opts ListOptions) func(storageSvc storage.Storage) http.HandlerFunc {
+	return func(storageSvc storage.Storage) http.HandlerFunc {
+		return List(storageSvc, search.NewCache(), opts)
+	}
+}
+
+// nestedCreate returns the create handler of a nested collection. The defaults of the resource do not apply
+// to the resources nested in it.
+func nestedCreate(storageSvc storage.Storage) http.HandlerFunc {
+	return Create(storageSvc)
+}
@@ -0,0 +1,84 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestNested(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {
+			{"id": "1", "orders": []interface{}{
+				map[string]interface{}{"id": float64(1), "total": float64(10)},
+			}},
+			{"id": "2"},
+		},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"users": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "List nested", method: http.MethodGet, path: "/users/1/orders", statusCode: http.StatusOK},
+		{name: "Read nested", method: http.MethodGet, path: "/users/1/orders/1", statusCode: http.StatusOK},
+		{name: "Read missing nested", method: http.MethodGet, path: "/users/1/orders/9", statusCode: http.StatusNotFound},
+		{name: "Missing parent", method: http.MethodGet, path: "/users/9/orders", statusCode: http.StatusNotFound},
+		{name: "Missing collection", method: http.MethodGet, path: "/users/2/orders", statusCode: http.StatusNotFound},
+		{name: "Create nested", method: http.MethodPost, path: "/users/1/orders", body: `{"total": 20}`, statusCode: http.StatusCreated},
+		{name: "Replace nested", method: http.MethodPut, path: "/users/1/orders/2", body: `{"total": 25}`, statusCode: http.StatusOK},
+		{name: "Update nested", method: http.MethodPatch, path: "/users/1/orders/1", body: `{"total": 15}`, statusCode: http.StatusOK},
+		{name: "Delete nested", method: http.MethodDelete, path: "/users/1/orders/2", statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, bytes.NewBufferString(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, res.StatusCode)
+		}
+	}
+
+	res, err := http.Get(server.URL + "/users/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var user map[string]interface{}
+	if err = json.NewDecoder(res.Body).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+
+	orders, ok := user["orders"].([]interface{})
+	if !ok || len(orders) != 1 || orders[0].(map[string]interface{})["total"] != float64(15) {
+		t.Fatalf("expected the updated orders in the parent resource, but got %v", user["orders"])
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+const oauthPath = "/__oauth"
+
+// OAuthAuthorize operates as a http handler, to approve an authorization code request and redirect back
+// to the client. The optional 'claims' query parameter holds a JSON object, overriding the claims of the
+// issued tokens for this login.
+func OAuthAuthorize(provider *auth.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if query.Get("response_type") != "code" {
+			web.Error(w, http.StatusBadRequest, "unsupported_response_type")
+			return
+		}
+
+		redirectURL, err := url.Parse(query.Get("redirect_uri"))
+		if err != nil || !redirectURL.IsAbs() {
+			web.Error(w, http.StatusBadRequest, auth.ErrInvalidRequest.Error())
+			return
+		}
+
+		claims := make(auth.Claims)
+		if claimsParam := query.Get("claims"); claimsParam != "" {
+			if err = json.Unmarshal([]byte(claimsParam), &claims); err != nil {
+				web.Error(w, http.StatusBadRequest, auth.ErrInvalidRequest.Error())
+				return
+			}
+		}
+
+		code, err := provider.Authorize(auth.AuthorizeRequest{
+			ClientID:            query.Get("client_id"),
+			RedirectURI:         query.Get("redirect_uri"),
+			Scope:               query.Get("scope"),
+			Nonce:               query.Get("nonce"),
+			CodeChallenge:       query.Get("code_challenge"),
+			CodeChallengeMethod: query.Get("code_challenge_method"),
+			Claims:              claims,
+		})
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidRequest) {
+				web.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		redirectQuery := redirectURL.Query()
+		redirectQuery.Set("code", code)
+		if state := query.Get("state"); state != "" {
+			redirectQuery.Set("state", state)
+		}
+
+		redirectURL.RawQuery = redirectQuery.Encode()
+
+		http.Redirect(w, r, redirectURL.String(), http.StatusFound)
+	}
+}
+
+// OAuthToken operates as a http handler, to issue tokens for the authorization code, refresh token and
+// client credentials grants. Any client is accepted.
+func OAuthToken(provider *auth.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			web.Error(w, http.StatusBadRequest, auth.ErrInvalidRequest.Error())
+			return
+		}
+
+		clientID := r.PostForm.Get("client_id")
+		if basicID, _, ok := r.BasicAuth(); ok {
+			clientID = basicID
+		}
+
+		var (
+			resp *auth.TokenResponse
+			err  error
+		)
+
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			resp, err = provider.Exchange(r.PostForm.Get("code"), clientID, r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+		case "refresh_token":
+			resp, err = provider.Refresh(r.PostForm.Get("refresh_token"))
+		case "client_credentials":
+			resp, err = provider.ClientCredentials(clientID, r.PostForm.Get("scope"))
+		default:
+			web.Error(w, http.StatusBadRequest, "unsupported_grant_type")
+			return
+		}
+
+		if err != nil {
+			if errors.Is(err, auth.ErrInvalidGrant) || errors.Is(err, auth.ErrInvalidRequest) {
+				web.Error(w, http.StatusBadRequest, err.Error())
+				return
+			}
+
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		w.Header().Set("Cache-Control", "no-store")
+		web.Success(w, http.StatusOK, resp)
+	}
+}
+
+// OAuthJWKS operates as a http handler, to publish the keys tokens of the provider are signed with.
+func OAuthJWKS(provider *auth.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		web.Success(w, http.StatusOK, provider.Tokens().JWKS())
+	}
+}
+
+// OAuthUserInfo operates as a http handler, to return the claims of a valid access token.
+func OAuthUserInfo(provider *auth.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, err := auth.BearerToken(r.Header.Get("Authorization"))
+		if err != nil {
+			web.Error(w, http.StatusUnauthorized, "invalid_token")
+			return
+		}
+
+		claims, err := provider.Tokens().Verify(token)
+		if err != nil {
+			web.Error(w, http.StatusUnauthorized, "invalid_token")
+			return
+		}
+
+		web.Success(w, http.StatusOK, claims)
+	}
+}
+
+// OAuthDiscovery operates as a http handler, to return the OpenID Connect discovery document.
+func OAuthDiscovery(provider *auth.Provider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		issuer := provider.Issuer()
+
+		web.Success(w, http.StatusOK, map[string]interface{}{
+			"issuer":                                issuer,
+			"authorization_endpoint":                issuer + oauthPath + "/authorize",
+			"token_endpoint":                        issuer + oauthPath + "/token",
+			"userinfo_endpoint":                     issuer + oauthPath + "/userinfo",
+			"jwks_uri":                              issuer + oauthPath + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"grant_types_supported":                 []string{"authorization_code", "refresh_token", "client_credentials"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{"plain", "S256"},
+			"scopes_supported":                      []string{"openid", "profile", "email"},
+		})
+	}
+}
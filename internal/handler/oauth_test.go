@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestOAuth(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := auth.NewProvider(auth.NewRSATokens(key, time.Now, time.Hour), "http://localhost:3000", auth.Claims{"sub": "1"})
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{}, handler.WithOAuth(provider)))
+	defer server.Close()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	query := url.Values{
+		"response_type": {"code"},
+		"client_id":     {"app"},
+		"redirect_uri":  {"http://localhost:8080/callback"},
+		"scope":         {"openid"},
+		"state":         {"state-1"},
+	}
+
+	resp, err := client.Get(server.URL + "/__oauth/authorize?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected status code %v, but got %v", http.StatusFound, resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if state := location.Query().Get("state"); state != "state-1" {
+		t.Fatalf("expected state %v, but got %v", "state-1", state)
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {location.Query().Get("code")},
+		"client_id":    {"app"},
+		"redirect_uri": {"http://localhost:8080/callback"},
+	}
+
+	resp, err = http.Post(server.URL+"/__oauth/token", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %v, but got %v", http.StatusOK, resp.StatusCode)
+	}
+
+	var tokenResp auth.TokenResponse
+	if err = json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if tokenResp.IDToken == "" || tokenResp.RefreshToken == "" {
+		t.Fatalf("expected id and refresh token, but got %v", tokenResp)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/__oauth/userinfo", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+tokenResp.AccessToken)
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims := make(auth.Claims)
+	if err = json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if claims["sub"] != "1" {
+		t.Fatalf("expected claim sub %v, but got %v", "1", claims["sub"])
+	}
+
+	resp, err = http.Get(server.URL + "/__oauth/jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var jwks struct {
+		Keys []map[string]string `json:"keys"`
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(jwks.Keys) != 1 || jwks.Keys[0]["kty"] != "RSA" {
+		t.Fatalf("expected a single RSA key, but got %v", jwks.Keys)
+	}
+
+	// Codes are single use.
+	resp, err = http.Post(server.URL+"/__oauth/token", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status code %v, but got %v", http.StatusBadRequest, resp.StatusCode)
+	}
+}
@@ -0,0 +1,66 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestOpenAPI(t *testing.T) {
+	data := storage.Database{"posts": {{"id": "1", "title": "json-server"}}}
+
+	dbSvc, err := storage.NewMock(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postsSvc, err := storage.NewMock(data, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resourceStorage := map[string]storage.Storage{"db": dbSvc, "posts": postsSvc}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithReadOnly()))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&doc)
+	resp.Body.Close()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != "3.0.3" {
+		t.Fatalf("expected openapi version 3.0.3, but got %q", doc.OpenAPI)
+	}
+
+	if posts, ok := doc.Paths["/posts"]; !ok || len(posts) != 1 {
+		t.Fatalf("expected the list route of posts only, but got %v", doc.Paths)
+	}
+
+	resp, err = http.Get(server.URL + "/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Fatalf("expected the html page of the docs, but got %v %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
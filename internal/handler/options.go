@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/capture"
+	"github.com/chanioxaris/json-server/internal/clock"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/script"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/stub"
+)
+
+// Option configures the API handler.
+type Option func(*options)
+
+// options holds the optional settings of the API handler.
+type options struct {
+	uploadsDir  string
+	uploadLimit int64
+	blobs       map[string]blobOptions
+	fakerSeed   int64
+	clock       *clock.Clock
+	auth        *authOptions
+	oauth       *auth.Provider
+	config      *config.Config
+	readOnly    bool
+	credentials *credentialsOptions
+	ignoreCase  bool
+	trimSlash   bool
+	keyCase     string
+	compress    bool
+	proxy       http.Handler
+	scripts     map[string]*script.Script
+	captureSize int
+	recorder    capture.Sink
+	stubs       []stub.Stub
+	collections storage.Collections
+	autoCreate  bool
+	strictType  bool
+	mockHeaders bool
+	strictQuery bool
+	maxUnpaged  int
+	heavyLimit  int
+	heavyQueue  int
+	cacheTTL    time.Duration
+	origins     []string
+	delay       time.Duration
+	chaosRate   float64
+}
+
+// authOptions describes the mock authentication flow.
+type authOptions struct {
+	tokens    *auth.Tokens
+	usersKey  string
+	protected []string
+}
+
+// credentialsOptions describes the static credentials required on requests.
+type credentialsOptions struct {
+	token      string
+	users      map[string]string
+	writesOnly bool
+}
+
+// blobOptions describes a resource whose records reference local files.
+type blobOptions struct {
+	field   string
+	baseDir string
+}
+
+// WithUploads enables multipart file uploads on create, replace and update. Files are stored
+// in the provided directory and served under '/uploads'.
+func WithUploads(dir string) Option {
+	return func(o *options) {
+		o.uploadsDir = dir
+	}
+}
+
+// WithUploadLimit sets the maximum size in bytes of multipart request bodies, when uploads are enabled.
+// Larger bodies are rejected with 413.
+func WithUploadLimit(maxBytes int64) Option {
+	return func(o *options) {
+		o.uploadLimit = maxBytes
+	}
+}
+
+// WithBlob serves the local file referenced by the provided field on GET by id of the resource, instead
+// of the resource itself. Relative file paths are resolved against the base directory.
+func WithBlob(resourceKey, field, baseDir string) Option {
+	return func(o *options) {
+		if o.blobs == nil {
+			o.blobs = make(map[string]blobOptions)
+		}
+
+		o.blobs[resourceKey] = blobOptions{field: field, baseDir: baseDir}
+	}
+}
+
+// WithFakerSeed sets the seed of the values generated by the '/__faker' endpoints. By default the
+// current time is used.
+func WithFakerSeed(seed int64) Option {
+	return func(o *options) {
+		o.fakerSeed = seed
+	}
+}
+
+// WithClock sets the mock clock controlled by the '/__time' endpoints. It should be the clock used
+// as time source of the resource storages.
+func WithClock(c *clock.Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithAuth enables the mock authentication flow. Users of the provided resource can log in and register
+// on '/login' and '/register', and requests to the protected resources require the issued access token.
+// All resources are protected when none are provided.
+func WithAuth(tokens *auth.Tokens, usersKey string, protected ...string) Option {
+	return func(o *options) {
+		o.auth = &authOptions{tokens: tokens, usersKey: usersKey, protected: protected}
+	}
+}
+
+// WithOAuth enables the mock OAuth2 and OpenID Connect provider endpoints under '/__oauth'. When the mock
+// authentication flow is enabled as well, protected resources also accept access tokens of the provider.
+func WithOAuth(provider *auth.Provider) Option {
+	return func(o *options) {
+		o.oauth = provider
+	}
+}
+
+// WithConfig customizes the behavior of individual resources, based on the configuration file.
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// WithReadOnly rejects the requests modifying data with 403, like create, replace, update and delete requests on
+// all resources. Logins are still served, as they only issue tokens.
+func WithReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithCredentials requires the static access token in an 'Authorization: Bearer <token>' header, or the
+// credentials of one of the users, by username, in an 'Authorization: Basic' header, on all requests, or only on
+// the ones modifying data if writesOnly is set. Passwords may be plain text or bcrypt hashes.
+func WithCredentials(token string, users map[string]string, writesOnly bool) Option {
+	return func(o *options) {
+		o.credentials = &credentialsOptions{token: token, users: users, writesOnly: writesOnly}
+	}
+}
+
+// WithIgnoreCase matches resource names in request paths case-insensitively, e.g. '/Posts' is routed
+// as '/posts'.
+func WithIgnoreCase() Option {
+	return func(o *options) {
+		o.ignoreCase = true
+	}
+}
+
+// WithIgnoreTrailingSlash routes request paths with a trailing slash as the ones without, e.g. '/posts/'
+// as '/posts', instead of redirecting them.
+func WithIgnoreTrailingSlash() Option {
+	return func(o *options) {
+		o.trimSlash = true
+	}
+}
+
+// WithKeyCase converts the field names of all responses to the key case, either camel, snake or kebab.
+// Requests may use field names in any case.
+func WithKeyCase(keyCase string) Option {
+	return func(o *options) {
+		o.keyCase = keyCase
+	}
+}
+
+// WithCompression compresses responses with brotli or gzip, as negotiated with the Accept-Encoding header.
+func WithCompression() Option {
+	return func(o *options) {
+		o.compress = true
+	}
+}
+
+// WithProxy forwards requests which match no route to the provided handler, e.g. to an upstream API.
+func WithProxy(h http.Handler) Option {
+	return func(o *options) {
+		o.proxy = h
+	}
+}
+
+// WithScript handles the requests of the route, e.g. 'POST /orders/:id/cancel', with the Lua script. Scripts
+// take precedence over the resource routes, and access the resources after applying their configuration.
+func WithScript(route string, s *script.Script) Option {
+	return func(o *options) {
+		if o.scripts == nil {
+			o.scripts = make(map[string]*script.Script)
+		}
+
+		o.scripts[route] = s
+	}
+}
+
+// WithCapture keeps the provided number of latest requests with their responses, listed on '/__requests'.
+func WithCapture(size int) Option {
+	return func(o *options) {
+		o.captureSize = size
+	}
+}
+
+// WithRecorder adds the requests with their responses to the recorder, except for the routes under '/__', e.g.
+// to record a manual session into a script.
+func WithRecorder(recorder capture.Sink) Option {
+	return func(o *options) {
+		o.recorder = recorder
+	}
+}
+
+// WithStubs registers the stubs when the server starts, as if added on '/__stubs'. The stubs must be valid, e.g.
+// read by stub.Load.
+func WithStubs(stubs []stub.Stub) Option {
+	return func(o *options) {
+		o.stubs = append(o.stubs, stubs...)
+	}
+}
+
+// WithRuntimeResources allows resources to be created while the server runs on '/__resources', as
+// collections of the provided database.
+func WithRuntimeResources(collections storage.Collections) Option {
+	return func(o *options) {
+		o.collections = collections
+	}
+}
+
+// WithAutoResources creates a resource on the first POST to its path, when runtime resources are allowed.
+func WithAutoResources() Option {
+	return func(o *options) {
+		o.autoCreate = true
+	}
+}
+
+// WithStrictContentType rejects write requests with a body which is not sent as json with 415, and sends json
+// responses with the utf-8 charset.
+func WithStrictContentType() Option {
+	return func(o *options) {
+		o.strictType = true
+	}
+}
+
+// WithMockHeaders lets requests override how they are served with headers, a delay with X-Mock-Delay and an
+// error status code with X-Mock-Status.
+func WithMockHeaders() Option {
+	return func(o *options) {
+		o.mockHeaders = true
+	}
+}
+
+// WithStrictQueries rejects list and export requests with unknown or ignored query parameters with 400, instead
+// of ignoring them.
+func WithStrictQueries() Option {
+	return func(o *options) {
+		o.strictQuery = true
+	}
+}
+
+// WithMaxUnpaginated fails list requests without pagination with 413, when they have more resources than the
+// maximum.
+func WithMaxUnpaginated(max int) Option {
+	return func(o *options) {
+		o.maxUnpaged = max
+	}
+}
+
+// WithHeavyLimit serves up to the concurrency of heavy requests at once, like full-text searches, exports and
+// imports, while up to the queue size of them wait. Heavy requests beyond that fail with 503.
+func WithHeavyLimit(concurrency, queue int) Option {
+	return func(o *options) {
+		o.heavyLimit = concurrency
+		o.heavyQueue = queue
+	}
+}
+
+// WithAllowOrigins allows the cross-origin requests of the origins to all resources, e.g.
+// 'https://app.example.com' or '*' for any origin, overriding the origins of the configuration for all resources.
+func WithAllowOrigins(origins ...string) Option {
+	return func(o *options) {
+		o.origins = origins
+	}
+}
+
+// WithDelay delays all responses by the delay, simulating network latency. Resources with a latency distribution
+// of their own keep it.
+func WithDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.delay = delay
+	}
+}
+
+// WithChaos fails the rate of requests to the resources, between 0 and 1, with 500 or 503 picked at random.
+// Resources with faults of their own keep them.
+func WithChaos(rate float64) Option {
+	return func(o *options) {
+		o.chaosRate = rate
+	}
+}
+
+// WithResponseCache caches the successful responses of GET requests to the resources for the ttl, so identical
+// requests are served without rendering them again. Writes to a resource drop its cached responses.
+func WithResponseCache(ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+	}
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+const (
+	// defaultPageLimit is the page size of offset pagination without '_limit' or '_end'.
+	defaultPageLimit = 10
+	// totalCountHeader is the response header holding the number of resources of paginated list requests.
+	totalCountHeader = "X-Total-Count"
+)
+
+// usesOffset reports whether the list request asks for offset pagination, either by page with '_page', or
+// by position with '_start'.
+func usesOffset(query url.Values) bool {
+	_, page := query["_page"]
+	_, start := query["_start"]
+
+	return page || start
+}
+
+// paginateOffset returns the resources of the page, either the '_page' (starting at 1) of '_limit' resources,
+// or the resources from the position '_start' (starting at 0) up to '_end', or '_limit' resources.
+func paginateOffset(resources []storage.Resource, query url.Values) ([]storage.Resource, error) {
+	limit := defaultPageLimit
+	if limitParam := query.Get("_limit"); limitParam != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitParam); err != nil || limit <= 0 {
+			return nil, fmt.Errorf("%w: _limit", errInvalidQuery)
+		}
+	}
+
+	var start int
+	if _, ok := query["_page"]; ok {
+		page, err := strconv.Atoi(query.Get("_page"))
+		if err != nil || page < 1 {
+			return nil, fmt.Errorf("%w: _page", errInvalidQuery)
+		}
+
+		// Pages past the last one are empty, without overflowing the position.
+		start = len(resources)
+		if page-1 <= len(resources)/limit {
+			start = (page - 1) * limit
+		}
+	} else {
+		var err error
+		if start, err = strconv.Atoi(query.Get("_start")); err != nil || start < 0 {
+			return nil, fmt.Errorf("%w: _start", errInvalidQuery)
+		}
+
+		if endParam := query.Get("_end"); endParam != "" {
+			end, err := strconv.Atoi(endParam)
+			if err != nil || end < start {
+				return nil, fmt.Errorf("%w: _end", errInvalidQuery)
+			}
+
+			limit = end - start
+		}
+	}
+
+	if start > len(resources) {
+		start = len(resources)
+	}
+
+	end := len(resources)
+	if limit < end-start {
+		end = start + limit
+	}
+
+	return resources[start:end], nil
+}
+
+// pageLinks returns the Link header of a '_page' request, pointing to the first, previous, next and last pages
+// of the total resources. Requests by position get none.
+func pageLinks(r *http.Request, total int) string {
+	query := r.URL.Query()
+
+	page, err := strconv.Atoi(query.Get("_page"))
+	if err != nil {
+		return ""
+	}
+
+	limit := defaultPageLimit
+	if l, err := strconv.Atoi(query.Get("_limit")); err == nil {
+		limit = l
+	}
+
+	last := (total + limit - 1) / limit
+	if last < 1 {
+		last = 1
+	}
+
+	link := func(page int, rel string) string {
+		query.Set("_page", strconv.Itoa(page))
+		return fmt.Sprintf("<%s?%s>; rel=%q", r.URL.Path, query.Encode(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if page > 1 {
+		links = append(links, link(page-1, "prev"))
+	}
+
+	if page < last {
+		links = append(links, link(page+1, "next"))
+	}
+
+	links = append(links, link(last, "last"))
+
+	return strings.Join(links, ", ")
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// preferMinimal is the preference of clients which do not need the written resource in the response.
+const preferMinimal = "return=minimal"
+
+// prefersMinimal reports whether the request asks for a response without the resource, with the
+// 'Prefer: return=minimal' header.
+func prefersMinimal(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			// Preferences may have parameters, e.g. 'return=minimal; foo=bar'.
+			preference = strings.TrimSpace(strings.SplitN(preference, ";", 2)[0])
+			preference = strings.ToLower(strings.Replace(preference, `"`, "", -1))
+
+			if strings.Replace(preference, " ", "", -1) == preferMinimal {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// respondWritten responds with the written resources, or without a body if the request prefers a minimal
+// response. Minimal responses keep the created status, and use 204 otherwise.
+func respondWritten(w http.ResponseWriter, r *http.Request, statusCode int, data interface{}) {
+	if !prefersMinimal(r) {
+		web.Success(w, statusCode, data)
+		return
+	}
+
+	w.Header().Set("Preference-Applied", preferMinimal)
+
+	if statusCode != http.StatusCreated {
+		statusCode = http.StatusNoContent
+	}
+
+	web.Success(w, statusCode, nil)
+}
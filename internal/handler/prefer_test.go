@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestPreferMinimal(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "first"}},
+	}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		prefer     string
+		statusCode int
+		location   string
+		emptyBody  bool
+	}{
+		{
+			name:       "Create with minimal response",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `{"id": "a b", "title": "second"}`,
+			prefer:     "return=minimal",
+			statusCode: http.StatusCreated,
+			location:   "/posts/a%20b",
+			emptyBody:  true,
+		},
+		{
+			name:       "Create with full response",
+			method:     http.MethodPost,
+			path:       "/posts",
+			body:       `{"id": "3", "title": "third"}`,
+			prefer:     "return=representation",
+			statusCode: http.StatusCreated,
+			location:   "/posts/3",
+		},
+		{
+			name:       "Replace with minimal response among other preferences",
+			method:     http.MethodPut,
+			path:       "/posts/1",
+			body:       `{"title": "replaced"}`,
+			prefer:     "respond-async, return=minimal; foo=bar",
+			statusCode: http.StatusNoContent,
+			emptyBody:  true,
+		},
+		{
+			name:       "Update with minimal response",
+			method:     http.MethodPatch,
+			path:       "/posts/1",
+			body:       `{"title": "updated"}`,
+			prefer:     `return="minimal"`,
+			statusCode: http.StatusNoContent,
+			emptyBody:  true,
+		},
+		{
+			name:       "Update missing resource with minimal response",
+			method:     http.MethodPatch,
+			path:       "/posts/4",
+			body:       `{"title": "updated"}`,
+			prefer:     "return=minimal",
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Prefer", tt.prefer)
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if location := resp.Header.Get("Location"); location != tt.location {
+			t.Fatalf("%s: expected header Location %v, but got %v", tt.name, tt.location, location)
+		}
+
+		if tt.emptyBody != (len(body) == 0) {
+			t.Fatalf("%s: expected empty body %v, but got %v", tt.name, tt.emptyBody, string(body))
+		}
+
+		if applied := resp.Header.Get("Preference-Applied"); tt.emptyBody && applied != "return=minimal" {
+			t.Fatalf("%s: expected header Preference-Applied %v, but got %v", tt.name, "return=minimal", applied)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// credentialsRealm is the realm of the basic authentication challenge.
+const credentialsRealm = "json-server"
+
+var errReadOnly = errors.New("server is read-only")
+
+// rejectWrites is operating as middleware to reject the requests modifying data with 403, for servers in read-only
+// mode. Requests to the allowed routes, like the login endpoints issuing tokens, are served.
+func rejectWrites(allowed ...string) func(http.Handler) http.Handler {
+	allowedRoutes := make(map[string]bool)
+	for _, route := range allowed {
+		allowedRoutes[route] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !mutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if route := mux.CurrentRoute(r); route != nil {
+				if tmpl, err := route.GetPathTemplate(); err == nil && allowedRoutes[tmpl] {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			web.Error(w, http.StatusForbidden, errReadOnly.Error())
+		})
+	}
+}
+
+// requireCredentials is operating as middleware to reject the requests without the static access token in an
+// 'Authorization: Bearer <token>' header, or the credentials of one of the users in an 'Authorization: Basic'
+// header, with 401. Only requests modifying data, and requests to the routes under '/__', are checked if writesOnly
+// is set. Users may have plain text or bcrypt hashed passwords.
+func requireCredentials(token string, users map[string]string, writesOnly bool) func(http.Handler) http.Handler {
+	challenge := "Bearer"
+	if len(users) > 0 {
+		challenge = `Basic realm="` + credentialsRealm + `"`
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The routes under '/__' inspect and control the server, e.g. list the captured requests, so they
+			// always require credentials.
+			if writesOnly && !mutating(r.Method) && !strings.HasPrefix(r.URL.Path, "/__") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if validCredentials(r, token, users) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", challenge)
+			web.Error(w, http.StatusUnauthorized, errUnauthorized.Error())
+		})
+	}
+}
+
+// validCredentials reports whether the Authorization header of the request holds the token or the credentials of
+// one of the users.
+func validCredentials(r *http.Request, token string, users map[string]string) bool {
+	if token != "" {
+		bearer, err := auth.BearerToken(r.Header.Get("Authorization"))
+		if err == nil && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			return true
+		}
+	}
+
+	if username, password, ok := r.BasicAuth(); ok {
+		if stored, ok := users[username]; ok && checkPassword(stored, password) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package handler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+)
+
+func TestCredentials(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("hashed"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := map[string]string{"olivia": "plain", "noah": string(hash)}
+
+	all := testModesServer(t, handler.WithCredentials("s3cret", users, false))
+	defer all.Close()
+
+	writes := testModesServer(t, handler.WithCredentials("s3cret", nil, true))
+	defer writes.Close()
+
+	testCases := []struct {
+		name       string
+		url        string
+		method     string
+		token      string
+		username   string
+		password   string
+		statusCode int
+		challenge  string
+	}{
+		{name: "Read without credentials", url: all.URL, method: http.MethodGet, statusCode: http.StatusUnauthorized, challenge: `Basic realm="json-server"`},
+		{name: "Read with token", url: all.URL, method: http.MethodGet, token: "s3cret", statusCode: http.StatusOK},
+		{name: "Read with invalid token", url: all.URL, method: http.MethodGet, token: "guess", statusCode: http.StatusUnauthorized},
+		{name: "Read with plain password", url: all.URL, method: http.MethodGet, username: "olivia", password: "plain", statusCode: http.StatusOK},
+		{name: "Read with hashed password", url: all.URL, method: http.MethodGet, username: "noah", password: "hashed", statusCode: http.StatusOK},
+		{name: "Read with invalid password", url: all.URL, method: http.MethodGet, username: "noah", password: "plain", statusCode: http.StatusUnauthorized},
+		{name: "Read of writes only", url: writes.URL, method: http.MethodGet, statusCode: http.StatusOK},
+		{name: "Write of writes only without credentials", url: writes.URL, method: http.MethodDelete, statusCode: http.StatusUnauthorized, challenge: "Bearer"},
+		{name: "Write of writes only with token", url: writes.URL, method: http.MethodDelete, token: "s3cret", statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, tt.url+"/posts/1", strings.NewReader(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		if tt.username != "" {
+			req.SetBasicAuth(tt.username, tt.password)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.challenge != "" && resp.Header.Get("WWW-Authenticate") != tt.challenge {
+			t.Fatalf("%s: expected challenge %q, but got %q", tt.name, tt.challenge, resp.Header.Get("WWW-Authenticate"))
+		}
+	}
+}
+
+func TestCredentials_Captures(t *testing.T) {
+	server := testModesServer(t, handler.WithCredentials("s3cret", nil, true), handler.WithCapture(10))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/posts", strings.NewReader(`{"title": "title-2"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer s3cret")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	// The captured requests are guarded even though only writes require credentials.
+	resp, err = http.Get(server.URL + "/__requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status code %v, but got %v", http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	req, err = http.NewRequest(http.MethodGet, server.URL+"/__requests", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Authorization", "Bearer s3cret")
+
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "title-2") {
+		t.Fatalf("expected the captured request, but got %v %s", resp.StatusCode, body)
+	}
+
+	if strings.Contains(string(body), "s3cret") {
+		t.Fatalf("expected the token to be redacted, but got %s", body)
+	}
+}
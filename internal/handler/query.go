@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// errInvalidQuery returns an error when a query parameter of a list request has an invalid value.
+var errInvalidQuery = errors.New("invalid query parameter")
+
+// dateLayouts lists the supported layouts of date values, besides epoch timestamps.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// filterOps lists the operator suffixes of filter query parameters.
+var filterOps = map[string]bool{"after": true, "before": true, "gte": true, "lte": true, "ne": true}
+
+// queryResources returns the resources matching the query parameters of a list request. Parameters named
+// after a field keep resources whose field equals one of the values, e.g. 'author=alice', and nested fields
+// are given by path, e.g. 'author.name=alice'. Parameters with the suffix '_gte' or '_lte' keep resources whose
+// field is at least or at most the value, '_ne' keeps resources whose field differs from the values, '_after'
+// or '_before' keep resources whose date field is after or before the value, and '_near' keeps resources
+// close to a location. Resources are sorted by the '_sort' fields.
+func queryResources(resources []storage.Resource, query url.Values) ([]storage.Resource, error) {
+	for param, values := range query {
+		field, op, ok := filterParam(param)
+		if !ok {
+			continue
+		}
+
+		var bound time.Time
+		if op == "after" || op == "before" {
+			if bound, ok = parseDate(values[0]); !ok {
+				return nil, fmt.Errorf("%w: %s", errInvalidQuery, param)
+			}
+		}
+
+		filtered := make([]storage.Resource, 0, len(resources))
+		for _, resource := range resources {
+			val, ok := fieldValue(resource, field)
+
+			var keep bool
+			switch op {
+			case "after", "before":
+				date, isDate := parseDate(val)
+				keep = ok && isDate && ((op == "after" && date.After(bound)) || (op == "before" && date.Before(bound)))
+			case "gte", "lte":
+				cmp, comparable := compareQueryValue(val, values[0])
+				keep = ok && comparable && ((op == "gte" && cmp >= 0) || (op == "lte" && cmp <= 0))
+			case "ne":
+				keep = !ok || !matchesQueryValues(val, values)
+			default:
+				keep = ok && matchesQueryValues(val, values)
+			}
+
+			if keep {
+				filtered = append(filtered, resource)
+			}
+		}
+
+		resources = filtered
+	}
+
+	if near := query.Get("_near"); near != "" {
+		var err error
+		if resources, err = nearResources(resources, near, query.Get("_radius"), query.Get("_sort") == ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if query.Get("_sort") != "" {
+		return sortResources(resources, query)
+	}
+
+	return resources, nil
+}
+
+// filterParam returns the field and operator of a filter query parameter, e.g. 'views' and 'gte' of
+// 'views_gte', and an empty operator for equality, e.g. of 'author'. Reserved parameters, starting with '_',
+// and the 'q' search are no filters.
+func filterParam(param string) (string, string, bool) {
+	if param == "q" || strings.HasPrefix(param, "_") {
+		return "", "", false
+	}
+
+	if field, op := splitQueryParam(param); filterOps[op] {
+		return field, op, true
+	}
+
+	return param, "", true
+}
+
+// matchesQueryValues reports whether the field value equals one of the query values. Numbers are compared
+// numerically, and arrays match if one of their items does.
+func matchesQueryValues(val interface{}, values []string) bool {
+	if items, ok := val.([]interface{}); ok {
+		for _, item := range items {
+			if item != nil && matchesQueryValues(item, values) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, queryVal := range values {
+		if cmp, ok := compareQueryValue(val, queryVal); ok && cmp == 0 {
+			return true
+		}
+
+		if _, isString := val.(string); !isString && fmt.Sprint(val) == queryVal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compareQueryValue compares the field value with the query value, numerically if both are numbers, or
+// else as strings if the field is a string. It reports whether the values are comparable.
+func compareQueryValue(val interface{}, queryVal string) (int, bool) {
+	if n, ok := number(val); ok {
+		q, err := strconv.ParseFloat(queryVal, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		switch {
+		case n < q:
+			return -1, true
+		case n > q:
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	if str, ok := val.(string); ok {
+		return strings.Compare(str, queryVal), true
+	}
+
+	return 0, false
+}
+
+// splitQueryParam splits a query parameter into the field and the operator suffix, e.g. 'createdAt_after'
+// into 'createdAt' and 'after'.
+func splitQueryParam(param string) (string, string) {
+	idx := strings.LastIndex(param, "_")
+	if idx <= 0 {
+		return param, ""
+	}
+
+	return param[:idx], param[idx+1:]
+}
+
+// parseDate parses RFC3339 dates, dates with an optional time, and epoch timestamps in seconds or
+// milliseconds, either as numbers or strings. Dates without a time zone are in UTC.
+func parseDate(val interface{}) (time.Time, bool) {
+	switch v := val.(type) {
+	case float64:
+		return epochDate(v), true
+	case int:
+		return epochDate(float64(v)), true
+	case int64:
+		return epochDate(float64(v)), true
+	case json.Number:
+		if epoch, err := v.Float64(); err == nil {
+			return epochDate(epoch), true
+		}
+	case string:
+		for _, layout := range dateLayouts {
+			if date, err := time.Parse(layout, v); err == nil {
+				return date, true
+			}
+		}
+
+		if epoch, err := strconv.ParseFloat(v, 64); err == nil {
+			return epochDate(epoch), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// epochDate converts an epoch timestamp to a date. Timestamps beyond the year 5138 in seconds are
+// considered milliseconds.
+func epochDate(epoch float64) time.Time {
+	if epoch > 1e11 || epoch < -1e11 {
+		return time.Unix(0, int64(epoch*float64(time.Millisecond))).UTC()
+	}
+
+	return time.Unix(0, int64(epoch*float64(time.Second))).UTC()
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -12,12 +13,18 @@ import (
 
 // Read operates as a http handler, to return the requested resource by id.
 func Read(storageSvc storage.Storage) http.HandlerFunc {
+	return readRelated(storageSvc, nil, "")
+}
+
+// readRelated operates as a http handler, to return the requested resource by id, with the related resources
+// requested by '_embed' and '_expand'.
+func readRelated(storageSvc storage.Storage, relations *storage.Relations, resourceKey string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Read request path parameter id.
 		id := mux.Vars(r)["id"]
 
 		// Find the resource with the requested id.
-		data, err := storageSvc.FindById(id)
+		data, err := traced(r, storageSvc).FindById(id)
 		if err != nil {
 			// Resource not found.
 			if errors.Is(err, storage.ErrResourceNotFound) {
@@ -29,6 +36,37 @@ func Read(storageSvc storage.Storage) http.HandlerFunc {
 			return
 		}
 
-		web.Success(w, http.StatusOK, data)
+		resources, err := related(w, r, relations, resourceKey, []storage.Resource{data})
+		if err != nil {
+			relatedError(w, err)
+			return
+		}
+
+		web.Success(w, http.StatusOK, resources[0])
+	}
+}
+
+// ReadBy operates as a http handler, to return the resource whose field holds the requested value. The
+// field should be unique, otherwise the first matching resource is returned.
+func ReadBy(storageSvc storage.Storage, field string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Read request path parameter value.
+		value := mux.Vars(r)["value"]
+
+		resources, err := storage.FindContext(r.Context(), traced(r, storageSvc))
+		if err != nil {
+			findError(w, r)
+			return
+		}
+
+		// Values are compared by their string representation, as path parameters are always strings.
+		for _, resource := range resources {
+			if val, ok := resource[field]; ok && val != nil && fmt.Sprint(val) == value {
+				web.Success(w, http.StatusOK, resource)
+				return
+			}
+		}
+
+		web.Error(w, http.StatusNotFound, storage.ErrResourceNotFound.Error())
 	}
 }
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// related returns the resources of the key with their related resources, as requested by the '_embed' and '_expand'
+// query parameters, e.g. the comments of posts with '_embed=comments', or the post of comments with '_expand=post'.
+// Both parameters can be repeated. Responses with related resources depend on other collections, so they are not
+// cached. Nil relations resolve nothing.
+func related(w http.ResponseWriter, r *http.Request, relations *storage.Relations, resourceKey string, resources []storage.Resource) ([]storage.Resource, error) {
+	query := r.URL.Query()
+	if relations == nil || (len(query["_embed"]) == 0 && len(query["_expand"]) == 0) {
+		return resources, nil
+	}
+
+	skipCache(w)
+
+	var err error
+	for _, childKey := range query["_embed"] {
+		if resources, err = relations.Embed(resourceKey, resources, childKey); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, parent := range query["_expand"] {
+		if resources, err = relations.Expand(resources, parent); err != nil {
+			return nil, err
+		}
+	}
+
+	return resources, nil
+}
+
+// relatedError responds with the error of resolving the related resources.
+func relatedError(w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrUnknownRelation) {
+		web.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+}
@@ -0,0 +1,121 @@
+package handler_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestRelations(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"authors":  {{"id": "1", "name": "alice"}},
+		"posts":    {{"id": "1", "authorId": "1"}, {"id": "2", "authorId": "9"}},
+		"comments": {{"id": "1", "postId": "1"}, {"id": "2", "postId": "2"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"authors", "posts", "comments"} {
+		storageSvc, err := storage.NewMemory(db, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithResponseCache(time.Hour)))
+	defer server.Close()
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		statusCode   int
+		expectedBody string
+	}{
+		{
+			name:         "Embed children",
+			method:       http.MethodGet,
+			path:         "/posts?_embed=comments",
+			statusCode:   http.StatusOK,
+			expectedBody: `[{"authorId":"1","comments":[{"id":"1","postId":"1"}],"id":"1"},{"authorId":"9","comments":[{"id":"2","postId":"2"}],"id":"2"}]`,
+		},
+		{
+			name:         "Expand parent",
+			method:       http.MethodGet,
+			path:         "/posts?_expand=author",
+			statusCode:   http.StatusOK,
+			expectedBody: `[{"author":{"id":"1","name":"alice"},"authorId":"1","id":"1"},{"authorId":"9","id":"2"}]`,
+		},
+		{
+			name:         "Embed and expand by id",
+			method:       http.MethodGet,
+			path:         "/posts/1?_embed=comments&_expand=author",
+			statusCode:   http.StatusOK,
+			expectedBody: `{"author":{"id":"1","name":"alice"},"authorId":"1","comments":[{"id":"1","postId":"1"}],"id":"1"}`,
+		},
+		{name: "Embed unknown resource", method: http.MethodGet, path: "/posts?_embed=likes", statusCode: http.StatusBadRequest},
+		{name: "Expand unknown resource", method: http.MethodGet, path: "/posts/1?_expand=user", statusCode: http.StatusBadRequest},
+		{
+			name:         "List children",
+			method:       http.MethodGet,
+			path:         "/posts/1/comments",
+			statusCode:   http.StatusOK,
+			expectedBody: `[{"id":"1","postId":"1"}]`,
+		},
+		{name: "Read child of other parent", method: http.MethodGet, path: "/posts/1/comments/2", statusCode: http.StatusNotFound},
+		{name: "Missing parent", method: http.MethodGet, path: "/posts/9/comments", statusCode: http.StatusNotFound},
+		{
+			name:         "Create child",
+			method:       http.MethodPost,
+			path:         "/posts/1/comments",
+			body:         `{"body": "nice"}`,
+			statusCode:   http.StatusCreated,
+			expectedBody: `{"body":"nice","id":"3","postId":"1"}`,
+		},
+		{
+			name:         "Embed created child",
+			method:       http.MethodGet,
+			path:         "/posts/1?_embed=comments",
+			statusCode:   http.StatusOK,
+			expectedBody: `{"authorId":"1","comments":[{"id":"1","postId":"1"},{"body":"nice","id":"3","postId":"1"}],"id":"1"}`,
+		},
+		{name: "Delete child of other parent", method: http.MethodDelete, path: "/posts/1/comments/2", statusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, bytes.NewBufferString(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, res.StatusCode)
+		}
+
+		if tt.expectedBody != "" && string(body) != tt.expectedBody {
+			t.Fatalf("%s: expected body %s, but got %s", tt.name, tt.expectedBody, body)
+		}
+	}
+}
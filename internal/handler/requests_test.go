@@ -0,0 +1,158 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestRequests(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"orders": {{"id": "1", "total": 10}},
+	}), "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"orders": storageSvc}, handler.WithCapture(10)))
+	defer server.Close()
+
+	sent := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodGet, path: "/orders?total=10"},
+		{method: http.MethodPost, path: "/orders", body: `{"id": "2", "total": 20}`},
+		{method: http.MethodGet, path: "/unknown"},
+	}
+
+	for _, req := range sent {
+		r, err := http.NewRequest(req.method, server.URL+req.path, strings.NewReader(req.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	testCases := []struct {
+		name       string
+		query      string
+		statusCode int
+		expected   []capture.Entry
+	}{
+		{
+			name:       "List all requests",
+			statusCode: http.StatusOK,
+			expected: []capture.Entry{
+				{ID: 1, Method: http.MethodGet, Path: "/orders", Response: capture.Response{Status: http.StatusOK}},
+				{ID: 2, Method: http.MethodPost, Path: "/orders", Response: capture.Response{Status: http.StatusCreated}},
+				{ID: 3, Method: http.MethodGet, Path: "/unknown", Response: capture.Response{Status: http.StatusNotFound}},
+			},
+		},
+		{
+			name:       "List requests by method and path",
+			query:      "?method=POST&path=/orders",
+			statusCode: http.StatusOK,
+			expected: []capture.Entry{
+				{
+					ID:     2,
+					Method: http.MethodPost,
+					Path:   "/orders",
+					Body:   map[string]interface{}{"id": "2", "total": float64(20)},
+					Response: capture.Response{
+						Status: http.StatusCreated,
+						Body:   map[string]interface{}{"id": "2", "total": float64(20)},
+					},
+				},
+			},
+		},
+		{
+			name:       "List requests with invalid status",
+			query:      "?status=created",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + "/__requests" + tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			resp.Body.Close()
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		var entries []capture.Entry
+		err = json.NewDecoder(resp.Body).Decode(&entries)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != len(tt.expected) {
+			t.Fatalf("%s: expected %v requests, but got %v", tt.name, len(tt.expected), len(entries))
+		}
+
+		for idx, expected := range tt.expected {
+			got := entries[idx]
+			if got.ID != expected.ID || got.Method != expected.Method || got.Path != expected.Path || got.Response.Status != expected.Response.Status {
+				t.Fatalf("%s: expected request %v, but got %v", tt.name, expected, got)
+			}
+
+			if expected.Body != nil && !reflect.DeepEqual(got.Body, expected.Body) {
+				t.Fatalf("%s: expected request body %v, but got %v", tt.name, expected.Body, got.Body)
+			}
+
+			if expected.Response.Body != nil && !reflect.DeepEqual(got.Response.Body, expected.Response.Body) {
+				t.Fatalf("%s: expected response body %v, but got %v", tt.name, expected.Response.Body, got.Response.Body)
+			}
+		}
+	}
+
+	// Dropping the captured requests, the listing itself is not captured.
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/__requests", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	resp, err = http.Get(server.URL + "/__requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var entries []capture.Entry
+	if err = json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 0 {
+		t.Fatalf("expected no requests after reset, but got %v", entries)
+	}
+}
@@ -0,0 +1,341 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/events"
+	"github.com/chanioxaris/json-server/internal/search"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var (
+	errInvalidResourceName = errors.New("name must contain letters, digits, '-' and '_' only")
+	errSingularResource    = errors.New("only plural resources are supported")
+)
+
+// resourceNamePattern matches the names of resources created at runtime, which are used as url path.
+var resourceNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// runtimeResources keeps the resources created, dropped and renamed while the server runs. Their routes are
+// registered once, and only match the resources existing so far. It is safe for concurrent use.
+type runtimeResources struct {
+	mu          sync.RWMutex
+	collections storage.Collections
+	// static holds the resources the server started with and which are not dropped or renamed, and whether
+	// they are listed.
+	static    map[string]bool
+	resources map[string]*indexedStorage
+	// autoCreate creates resources on the first POST to their path.
+	autoCreate bool
+	// changes publishes the changes of the resources to the event stream.
+	changes *events.Broker
+}
+
+type resourceInfo struct {
+	Name   string `json:"name"`
+	Plural bool   `json:"plural"`
+	// Runtime reports whether the resource was created while the server runs.
+	Runtime bool `json:"runtime"`
+}
+
+func newRuntimeResources(collections storage.Collections, static map[string]bool, autoCreate bool, changes *events.Broker) *runtimeResources {
+	return &runtimeResources{
+		collections: collections,
+		static:      static,
+		resources:   make(map[string]*indexedStorage),
+		autoCreate:  autoCreate,
+		changes:     changes,
+	}
+}
+
+// create adds an empty collection for the resource, failing if a resource with the name exists.
+func (rr *runtimeResources) create(name string) (*indexedStorage, error) {
+	if !resourceNamePattern.MatchString(name) || name == "db" {
+		return nil, fmt.Errorf("%w: %s", errInvalidResourceName, name)
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if rr.exists(name) == nil {
+		return nil, storage.ErrResourceAlreadyExists
+	}
+
+	storageSvc, err := rr.collections.AddCollection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := rr.indexed(name, storageSvc)
+	rr.resources[name] = indexed
+
+	return indexed, nil
+}
+
+// drop drops the resource with all its resources.
+func (rr *runtimeResources) drop(name string) error {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if err := rr.exists(name); err != nil {
+		return err
+	}
+
+	if err := rr.collections.DropCollection(name); err != nil {
+		return err
+	}
+
+	delete(rr.static, name)
+	delete(rr.resources, name)
+
+	return nil
+}
+
+// rename moves the resource to a new name, which serves the default routes from now on.
+func (rr *runtimeResources) rename(name, newName string) (*indexedStorage, error) {
+	if !resourceNamePattern.MatchString(newName) || newName == "db" {
+		return nil, fmt.Errorf("%w: %s", errInvalidResourceName, newName)
+	}
+
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+
+	if err := rr.exists(name); err != nil {
+		return nil, err
+	}
+
+	if rr.exists(newName) == nil {
+		return nil, storage.ErrResourceAlreadyExists
+	}
+
+	storageSvc, err := rr.collections.RenameCollection(name, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(rr.static, name)
+	delete(rr.resources, name)
+
+	indexed := rr.indexed(newName, storageSvc)
+	rr.resources[newName] = indexed
+
+	return indexed, nil
+}
+
+// indexed returns the storage of the resource serving its routes, publishing its changes to the event stream, if
+// any, with the search index kept until it is written to.
+func (rr *runtimeResources) indexed(name string, storageSvc storage.Storage) *indexedStorage {
+	if rr.changes != nil {
+		storageSvc = &publishedStorage{Storage: storageSvc, broker: rr.changes, collection: name}
+	}
+
+	return &indexedStorage{Storage: storageSvc, cache: search.NewCache()}
+}
+
+// exists checks the resource exists, either static or created at runtime. Callers must hold the lock.
+func (rr *runtimeResources) exists(name string) error {
+	if _, ok := rr.static[name]; ok {
+		return nil
+	}
+
+	if _, ok := rr.resources[name]; ok {
+		return nil
+	}
+
+	return storage.ErrResourceNotFound
+}
+
+// live returns a matcher of the routes of a static resource, which stop matching once it is dropped or
+// renamed.
+func (rr *runtimeResources) live(name string) mux.MatcherFunc {
+	return func(r *http.Request, rm *mux.RouteMatch) bool {
+		rr.mu.RLock()
+		defer rr.mu.RUnlock()
+
+		_, ok := rr.static[name]
+
+		return ok
+	}
+}
+
+func (rr *runtimeResources) get(name string) (*indexedStorage, bool) {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	storageSvc, ok := rr.resources[name]
+
+	return storageSvc, ok
+}
+
+// list returns the resources, sorted by name.
+func (rr *runtimeResources) list() []resourceInfo {
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	list := make([]resourceInfo, 0, len(rr.static)+len(rr.resources))
+	for name, listed := range rr.static {
+		if listed {
+			list = append(list, resourceInfo{Name: name, Plural: true})
+		}
+	}
+
+	for name := range rr.resources {
+		list = append(list, resourceInfo{Name: name, Plural: true, Runtime: true})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
+
+// match matches the requests to the resources created at runtime, and on POST to the resources which
+// would be created automatically. Route variables are not set while matching, so the name is taken from
+// the path.
+func (rr *runtimeResources) match(r *http.Request, rm *mux.RouteMatch) bool {
+	segments := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+
+	name := segments[0]
+	if _, ok := rr.get(name); ok {
+		return true
+	}
+
+	rr.mu.RLock()
+	defer rr.mu.RUnlock()
+
+	_, isStatic := rr.static[name]
+
+	return rr.autoCreate && r.Method == http.MethodPost && len(segments) == 1 && !isStatic &&
+		resourceNamePattern.MatchString(name)
+}
+
+// handle operates as a http handler, serving the requests of the resources created at runtime with the
+// handler of their storage.
+func (rr *runtimeResources) handle(h func(storageSvc *indexedStorage) http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)["resource"]
+
+		storageSvc, ok := rr.get(name)
+		if !ok && rr.autoCreate && r.Method == http.MethodPost {
+			var err error
+			if storageSvc, err = rr.create(name); errors.Is(err, storage.ErrResourceAlreadyExists) {
+				// Created by a concurrent request.
+				storageSvc, ok = rr.get(name)
+			} else {
+				ok = err == nil
+			}
+		}
+
+		if !ok {
+			web.Error(w, http.StatusNotFound, storage.ErrResourceNotFound.Error())
+			return
+		}
+
+		h(storageSvc)(w, r)
+	}
+}
+
+// Resources operates as a http handler, to list the resources, including the ones created at runtime.
+func Resources(rr *runtimeResources) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		web.Success(w, http.StatusOK, rr.list())
+	}
+}
+
+// ResourcesCreate operates as a http handler, to create an empty resource while the server runs, e.g.
+// '{"name": "invoices", "plural": true}'.
+func ResourcesCreate(rr *runtimeResources) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name   string `json:"name"`
+			Plural *bool  `json:"plural"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+			return
+		}
+
+		if req.Plural != nil && !*req.Plural {
+			web.Error(w, http.StatusBadRequest, errSingularResource.Error())
+			return
+		}
+
+		if _, err := rr.create(req.Name); err != nil {
+			switch {
+			case errors.Is(err, errInvalidResourceName):
+				web.Error(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, storage.ErrResourceAlreadyExists):
+				web.Error(w, http.StatusConflict, err.Error())
+			default:
+				web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			}
+
+			return
+		}
+
+		w.Header().Set("Location", "/"+req.Name)
+		web.Success(w, http.StatusCreated, resourceInfo{Name: req.Name, Plural: true, Runtime: true})
+	}
+}
+
+// ResourcesDrop operates as a http handler, to drop a resource with all its resources while the server runs.
+func ResourcesDrop(rr *runtimeResources) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := rr.drop(mux.Vars(r)["name"]); err != nil {
+			if errors.Is(err, storage.ErrResourceNotFound) {
+				web.Error(w, http.StatusNotFound, err.Error())
+				return
+			}
+
+			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			return
+		}
+
+		web.Success(w, http.StatusNoContent, nil)
+	}
+}
+
+// ResourcesRename operates as a http handler, to rename a resource while the server runs, e.g.
+// '{"name": "bills"}'. Renamed resources serve the default routes under their new name.
+func ResourcesRename(rr *runtimeResources) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name string `json:"name"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+			return
+		}
+
+		if _, err := rr.rename(mux.Vars(r)["name"], req.Name); err != nil {
+			switch {
+			case errors.Is(err, errInvalidResourceName):
+				web.Error(w, http.StatusBadRequest, err.Error())
+			case errors.Is(err, storage.ErrResourceNotFound):
+				web.Error(w, http.StatusNotFound, err.Error())
+			case errors.Is(err, storage.ErrResourceAlreadyExists):
+				web.Error(w, http.StatusConflict, err.Error())
+			default:
+				web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+			}
+
+			return
+		}
+
+		w.Header().Set("Location", "/"+req.Name)
+		web.Success(w, http.StatusOK, resourceInfo{Name: req.Name, Plural: true, Runtime: true})
+	}
+}
@@ -0,0 +1,211 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestResourcesCreate(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "first"}},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, handler.WithRuntimeResources(db)))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "Unknown resource", method: http.MethodGet, path: "/invoices", statusCode: http.StatusNotFound},
+		{name: "Create on unknown resource", method: http.MethodPost, path: "/invoices", body: `{"total": 10}`, statusCode: http.StatusNotFound},
+		{name: "Create resource", method: http.MethodPost, path: "/__resources", body: `{"name": "invoices", "plural": true}`, statusCode: http.StatusCreated},
+		{name: "Create existing resource", method: http.MethodPost, path: "/__resources", body: `{"name": "invoices"}`, statusCode: http.StatusConflict},
+		{name: "Create static resource", method: http.MethodPost, path: "/__resources", body: `{"name": "posts"}`, statusCode: http.StatusConflict},
+		{name: "Create invalid resource", method: http.MethodPost, path: "/__resources", body: `{"name": "in/voices"}`, statusCode: http.StatusBadRequest},
+		{name: "Create singular resource", method: http.MethodPost, path: "/__resources", body: `{"name": "profile", "plural": false}`, statusCode: http.StatusBadRequest},
+		{name: "List created resource", method: http.MethodGet, path: "/invoices", statusCode: http.StatusOK},
+		{name: "Create in created resource", method: http.MethodPost, path: "/invoices", body: `{"id": "1", "total": 10}`, statusCode: http.StatusCreated},
+		{name: "Read in created resource", method: http.MethodGet, path: "/invoices/1", statusCode: http.StatusOK},
+		{name: "Update in created resource", method: http.MethodPatch, path: "/invoices/1", body: `{"total": 20}`, statusCode: http.StatusOK},
+		{name: "Delete in created resource", method: http.MethodDelete, path: "/invoices/1", statusCode: http.StatusOK},
+		{name: "Read deleted in created resource", method: http.MethodGet, path: "/invoices/1", statusCode: http.StatusNotFound},
+		{name: "Static resource unchanged", method: http.MethodGet, path: "/posts/1", statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+
+	resp, err := http.Get(server.URL + "/__resources")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var resources []struct {
+		Name    string `json:"name"`
+		Runtime bool   `json:"runtime"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&resources); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resources) != 2 || resources[0].Name != "invoices" || !resources[0].Runtime || resources[1].Name != "posts" || resources[1].Runtime {
+		t.Fatalf("expected the created and static resources, but got %v", resources)
+	}
+}
+
+func TestResourcesAutoCreate(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "title": "first"}},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(
+		map[string]storage.Storage{"posts": storageSvc},
+		handler.WithRuntimeResources(db),
+		handler.WithAutoResources(),
+	))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "Read unknown resource", method: http.MethodGet, path: "/invoices", statusCode: http.StatusNotFound},
+		{name: "Update unknown resource", method: http.MethodPatch, path: "/invoices/1", body: `{"total": 10}`, statusCode: http.StatusNotFound},
+		{name: "Create on unknown resource", method: http.MethodPost, path: "/invoices", body: `{"id": "1", "total": 10}`, statusCode: http.StatusCreated},
+		{name: "Create again on created resource", method: http.MethodPost, path: "/invoices", body: `{"id": "2", "total": 20}`, statusCode: http.StatusCreated},
+		{name: "Read created resource", method: http.MethodGet, path: "/invoices/2", statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+
+	data, err := storageSvc.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data["invoices"]) != 2 {
+		t.Fatalf("expected 2 stored invoices, but got %v", data["invoices"])
+	}
+}
+
+func TestResourcesDropAndRename(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"posts":    {{"id": "1", "title": "first"}},
+		"comments": {{"id": "1", "body": "nice"}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"posts", "comments"} {
+		storageSvc, err := storage.NewMemory(db, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithRuntimeResources(db)))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "Static resource with unsupported method", method: http.MethodPut, path: "/posts", statusCode: http.StatusMethodNotAllowed},
+		{name: "Rename static resource", method: http.MethodPatch, path: "/__resources/posts", body: `{"name": "articles"}`, statusCode: http.StatusOK},
+		{name: "Read renamed resource by old name", method: http.MethodGet, path: "/posts/1", statusCode: http.StatusNotFound},
+		{name: "Read renamed resource by new name", method: http.MethodGet, path: "/articles/1", statusCode: http.StatusOK},
+		{name: "Rename to existing resource", method: http.MethodPatch, path: "/__resources/articles", body: `{"name": "comments"}`, statusCode: http.StatusConflict},
+		{name: "Rename to invalid name", method: http.MethodPatch, path: "/__resources/articles", body: `{"name": ""}`, statusCode: http.StatusBadRequest},
+		{name: "Rename unknown resource", method: http.MethodPatch, path: "/__resources/posts", body: `{"name": "news"}`, statusCode: http.StatusNotFound},
+		{name: "Drop static resource", method: http.MethodDelete, path: "/__resources/comments", statusCode: http.StatusNoContent},
+		{name: "List dropped resource", method: http.MethodGet, path: "/comments", statusCode: http.StatusNotFound},
+		{name: "Drop dropped resource", method: http.MethodDelete, path: "/__resources/comments", statusCode: http.StatusNotFound},
+		{name: "Create dropped resource again", method: http.MethodPost, path: "/__resources", body: `{"name": "comments"}`, statusCode: http.StatusCreated},
+		{name: "List created resource", method: http.MethodGet, path: "/comments", statusCode: http.StatusOK},
+		{name: "Drop renamed resource", method: http.MethodDelete, path: "/__resources/articles", statusCode: http.StatusNoContent},
+		{name: "List dropped renamed resource", method: http.MethodGet, path: "/articles", statusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+
+	data, err := resourceStorage["posts"].DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data) != 1 || len(data["comments"]) != 0 {
+		t.Fatalf("expected only the empty comments resource, but got %v", data)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/config"
+)
+
+// routeMatcher matches requests against the routes of the configuration, e.g. 'DELETE /users/:id', apart
+// from the router serving them.
+type routeMatcher struct {
+	router *mux.Router
+	routes map[*mux.Route]string
+}
+
+// newRouteMatcher returns a matcher of the routes. Invalid routes are skipped.
+func newRouteMatcher(routes []string) *routeMatcher {
+	m := &routeMatcher{router: mux.NewRouter(), routes: make(map[*mux.Route]string)}
+
+	for _, route := range routes {
+		method, path, err := config.ParseRoute(route)
+		if err != nil {
+			continue
+		}
+
+		m.routes[m.router.Path(path).Methods(method)] = route
+	}
+
+	return m
+}
+
+// match returns the route matching the request, and its parameters.
+func (m *routeMatcher) match(r *http.Request) (string, map[string]string, bool) {
+	// The router has no handlers, so a match is always a route, while the match error may be left over
+	// from routes of other methods.
+	var match mux.RouteMatch
+	if !m.router.Match(r, &match) {
+		return "", nil, false
+	}
+
+	return m.routes[match.Route], match.Vars, true
+}
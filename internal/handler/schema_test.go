@@ -0,0 +1,181 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/auth"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestSchema(t *testing.T) {
+	data := storage.Database{
+		"posts":  {{"id": "1", "status": "draft"}, {"id": "2", "status": "draft"}, {"id": "3", "status": "published"}, {"id": "4", "status": "published"}},
+		"events": {{"id": "1", "name": "signup"}},
+	}
+
+	resourceStorage := make(map[string]storage.Storage)
+	for resourceKey := range data {
+		storageSvc, err := storage.NewMock(data, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	cfg := &config.Config{Resources: map[string]config.Resource{"events": {WriteOnly: true}}}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		path       string
+		statusCode int
+		resources  []string
+		enumField  string
+	}{
+		{name: "Get schemas", path: "/__schema", statusCode: http.StatusOK, resources: []string{"posts"}},
+		{name: "Get schema of resource", path: "/__schema/posts", statusCode: http.StatusOK, enumField: "status"},
+		{name: "Get schema of write-only resource", path: "/__schema/events", statusCode: http.StatusNotFound},
+		{name: "Get schema of unknown resource", path: "/__schema/unknown", statusCode: http.StatusNotFound},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.resources != nil && len(body) != len(tt.resources) {
+			t.Fatalf("%s: expected schemas of %v, but got %v", tt.name, tt.resources, body)
+		}
+
+		for _, resourceKey := range tt.resources {
+			if _, ok := body[resourceKey]; !ok {
+				t.Fatalf("%s: expected schema of %v, but got %v", tt.name, resourceKey, body)
+			}
+		}
+
+		if tt.enumField != "" {
+			properties, _ := body["properties"].(map[string]interface{})
+			field, _ := properties[tt.enumField].(map[string]interface{})
+
+			if enum, _ := field["enum"].([]interface{}); len(enum) != 2 {
+				t.Fatalf("%s: expected enum of %v, but got %v", tt.name, tt.enumField, body)
+			}
+		}
+	}
+}
+
+func TestSchema_Protected(t *testing.T) {
+	data := storage.Database{
+		"users": {
+			{"id": "1", "role": "admin", "password": "secret"},
+			{"id": "2", "role": "admin", "password": "secret"},
+			{"id": "3", "role": "user", "password": "secret"},
+			{"id": "4", "role": "user", "password": "secret"},
+		},
+		"posts": {{"id": "1"}},
+	}
+
+	resourceStorage := make(map[string]storage.Storage)
+	for resourceKey := range data {
+		storageSvc, err := storage.NewMock(data, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	tokens := auth.NewTokens([]byte("secret"), time.Now, time.Hour)
+
+	token, err := tokens.Issue(auth.Claims{"sub": "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithAuth(tokens, "users", "users")))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		path       string
+		token      string
+		statusCode int
+		resources  []string
+	}{
+		{name: "Get schemas without token", path: "/__schema", statusCode: http.StatusOK, resources: []string{"posts"}},
+		{name: "Get schemas with token", path: "/__schema", token: token, statusCode: http.StatusOK, resources: []string{"posts", "users"}},
+		{name: "Get schema of protected resource without token", path: "/__schema/users", statusCode: http.StatusNotFound},
+		{name: "Get schema of protected resource with token", path: "/__schema/users", token: token, statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.resources != nil && len(body) != len(tt.resources) {
+			t.Fatalf("%s: expected schemas of %v, but got %v", tt.name, tt.resources, body)
+		}
+
+		if tt.path != "/__schema/users" || tt.statusCode != http.StatusOK {
+			continue
+		}
+
+		// Roles are listed as enum, but passwords never are.
+		properties, _ := body["properties"].(map[string]interface{})
+		role, _ := properties["role"].(map[string]interface{})
+		password, _ := properties["password"].(map[string]interface{})
+
+		if _, ok := role["enum"]; !ok {
+			t.Fatalf("%s: expected enum of role, but got %v", tt.name, role)
+		}
+
+		if _, ok := password["enum"]; ok {
+			t.Fatalf("%s: expected no enum of password, but got %v", tt.name, password)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/chanioxaris/json-server/internal/script"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// Script operates as a http handler, to respond with the result of a Lua script. The script gets the
+// request, with its json body decoded, and access to the resources.
+func Script(s *script.Script, resourceStorage map[string]storage.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+			return
+		}
+
+		req := script.Request{
+			Method: r.Method,
+			Path:   r.URL.Path,
+			Params: mux.Vars(r),
+			Query:  make(map[string]string),
+			Header: make(map[string]string),
+		}
+
+		for key := range r.URL.Query() {
+			req.Query[key] = r.URL.Query().Get(key)
+		}
+
+		for key := range r.Header {
+			req.Header[key] = r.Header.Get(key)
+		}
+
+		// Bodies which are no json are passed as is.
+		if len(bytes.TrimSpace(bodyBytes)) > 0 {
+			if err = json.Unmarshal(bodyBytes, &req.Body); err != nil {
+				req.Body = string(bodyBytes)
+			}
+		}
+
+		resp, err := s.Run(r.Context(), req, resourceStorage)
+		if err != nil {
+			web.Error(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		for key, val := range resp.Header {
+			w.Header().Set(key, val)
+		}
+
+		switch body := resp.Body.(type) {
+		case nil:
+			w.WriteHeader(resp.Status)
+		case string:
+			if w.Header().Get("Content-Type") == "" {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			}
+
+			w.WriteHeader(resp.Status)
+
+			// nolint
+			w.Write([]byte(body))
+		default:
+			web.Success(w, resp.Status, body)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package handler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/script"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestScript(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"orders": {{"id": "1", "status": "open"}},
+	}), "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel, err := script.Compile("cancel", `
+local order, err = db.update("orders", request.params.id, {status = "cancelled", reason = request.body.reason})
+if err then
+  return {status = 404, body = {error = err}}
+end
+
+return {status = 202, body = order}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create, err := script.Compile("create", `return {status = 201, headers = {["Content-Type"] = "text/csv"}, body = "id,status"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fail, err := script.Compile("fail", `error("boom")`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(
+		map[string]storage.Storage{"orders": storageSvc},
+		handler.WithScript("POST /orders/:id/cancel", cancel),
+		handler.WithScript("POST /orders", create),
+		handler.WithScript("GET /fail", fail),
+	))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		expected   string
+	}{
+		{
+			name:       "Run script with route params and body",
+			method:     http.MethodPost,
+			path:       "/orders/1/cancel",
+			body:       `{"reason": "duplicate"}`,
+			statusCode: http.StatusAccepted,
+			expected:   `{"id":"1","reason":"duplicate","status":"cancelled"}`,
+		},
+		{
+			name:       "Run script reporting storage error",
+			method:     http.MethodPost,
+			path:       "/orders/2/cancel",
+			body:       `{"reason": "duplicate"}`,
+			statusCode: http.StatusNotFound,
+			expected:   `{"error":"resource not found"}`,
+		},
+		{
+			name:       "Run script instead of resource route",
+			method:     http.MethodPost,
+			path:       "/orders",
+			body:       `{"id": "3"}`,
+			statusCode: http.StatusCreated,
+			expected:   `id,status`,
+		},
+		{
+			name:       "Keep other resource routes",
+			method:     http.MethodGet,
+			path:       "/orders/1",
+			statusCode: http.StatusOK,
+			expected:   `{"id":"1","reason":"duplicate","status":"cancelled"}`,
+		},
+		{
+			name:       "Fail script",
+			method:     http.MethodGet,
+			path:       "/fail",
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.expected != "" && string(body) != tt.expected {
+			t.Fatalf("%s: expected body %v, but got %v", tt.name, tt.expected, string(body))
+		}
+	}
+}
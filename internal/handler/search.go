@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chanioxaris/json-server/internal/search"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// highlightField is the field holding the highlighted matches of a search result.
+const highlightField = "_highlight"
+
+// indexedStorage wraps a storage, and invalidates the search index of the resource on every write.
+type indexedStorage struct {
+	storage.Storage
+	cache *search.Cache
+}
+
+func (s *indexedStorage) FindContext(ctx context.Context) ([]storage.Resource, error) {
+	return storage.FindContext(ctx, s.Storage)
+}
+
+func (s *indexedStorage) Create(newResource storage.Resource) (storage.Resource, error) {
+	defer s.cache.Invalidate()
+	return s.Storage.Create(newResource)
+}
+
+func (s *indexedStorage) Replace(id string, replaced storage.Resource) (storage.Resource, error) {
+	defer s.cache.Invalidate()
+	return s.Storage.Replace(id, replaced)
+}
+
+func (s *indexedStorage) Update(id string, updatedReq storage.Resource) (storage.Resource, error) {
+	defer s.cache.Invalidate()
+	return s.Storage.Update(id, updatedReq)
+}
+
+func (s *indexedStorage) Delete(id string) error {
+	defer s.cache.Invalidate()
+	return s.Storage.Delete(id)
+}
+
+// Transaction invalidates the search index once the transaction completes, as an index built meanwhile misses its
+// writes.
+func (s *indexedStorage) Transaction(fn func(tx storage.Storage) error) error {
+	defer s.cache.Invalidate()
+	return storage.Transaction(s.Storage, fn)
+}
+
+// searchResources returns the resources matching the search query, most relevant first. Matched fields
+// are added to each resource under '_highlight' if requested.
+func searchResources(resources []storage.Resource, cache *search.Cache, query string, highlight bool) []storage.Resource {
+	byID := make(map[string]storage.Resource, len(resources))
+	for _, resource := range resources {
+		if id, ok := resource["id"]; ok && id != nil {
+			byID[fmt.Sprint(id)] = resource
+		}
+	}
+
+	hits := cache.Index(resources).Search(query)
+
+	matches := make([]storage.Resource, 0, len(hits))
+	for _, hit := range hits {
+		resource, ok := byID[hit.ID]
+		if !ok {
+			continue
+		}
+
+		if highlight {
+			highlighted := make(storage.Resource, len(resource)+1)
+			for key, val := range resource {
+				highlighted[key] = val
+			}
+
+			highlighted[highlightField] = hit.Highlights
+			resource = highlighted
+		}
+
+		matches = append(matches, resource)
+	}
+
+	return matches
+}
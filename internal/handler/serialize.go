@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/chanioxaris/json-server/internal/serializer"
+)
+
+// serialize is operating as middleware to encode the json responses in the format the Accept header asks for, or
+// else in the format of the resource of the route, or the default format, if any. Other responses, like streams,
+// are sent as they are.
+func serialize(defaultFormat string, formats map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept")
+
+			format, ok := serializer.Negotiate(r.Header.Get("Accept"))
+			if !ok {
+				if format, ok = formats[routeResourceKey(r)]; !ok {
+					format = defaultFormat
+				}
+			}
+
+			s, err := serializer.Lookup(format)
+			if err != nil || format == serializer.FormatJSON {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			sw := &serializeWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			if !sw.buffered {
+				return
+			}
+
+			var data interface{}
+
+			decoder := json.NewDecoder(&sw.body)
+			decoder.UseNumber()
+
+			// Bodies which are not json after all are sent as they are.
+			if err = decoder.Decode(&data); err != nil {
+				w.WriteHeader(sw.status)
+
+				// nolint
+				w.Write(sw.body.Bytes())
+				return
+			}
+
+			var body bytes.Buffer
+			if err = s.Serialize(&body, serializer.Response{Resource: routeResourceKey(r), Status: sw.status, Data: data}); err != nil {
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+				return
+			}
+
+			w.Header().Set("Content-Type", s.ContentType())
+			w.WriteHeader(sw.status)
+
+			// nolint
+			w.Write(body.Bytes())
+		})
+	}
+}
+
+// serializeWriter buffers json responses to encode them once complete, and sends other responses right away.
+type serializeWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+	// buffered reports whether the response is json, kept in the body.
+	buffered bool
+	status   int
+	body     bytes.Buffer
+}
+
+func (s *serializeWriter) WriteHeader(statusCode int) {
+	if s.wroteHeader {
+		return
+	}
+
+	s.wroteHeader = true
+	s.status = statusCode
+
+	mediaType, _, err := mime.ParseMediaType(s.Header().Get("Content-Type"))
+	if err == nil && mediaType == "application/json" {
+		s.buffered = true
+		s.Header().Del("Content-Length")
+		return
+	}
+
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *serializeWriter) Write(b []byte) (int, error) {
+	if !s.wroteHeader {
+		s.WriteHeader(http.StatusOK)
+	}
+
+	if s.buffered {
+		return s.body.Write(b)
+	}
+
+	return s.ResponseWriter.Write(b)
+}
+
+// Flush sends the responses which are not buffered.
+func (s *serializeWriter) Flush() {
+	if s.buffered {
+		return
+	}
+
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped response writer, so the deadlines of the response can be set.
+func (s *serializeWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestSerialize(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"resources": {"reports": {"format": "csv"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.NewMemoryDB(storage.Database{
+		"posts":   {{"id": "1", "title": "json-server"}},
+		"reports": {{"id": "1", "total": 3}},
+	})
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"posts", "reports"} {
+		if resourceStorage[resourceKey], err = storage.NewMemory(db, resourceKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	server := httptest.NewServer(handler.Setup(resourceStorage, handler.WithConfig(cfg)))
+	defer server.Close()
+
+	testCases := []struct {
+		name                string
+		path                string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Serialize json by default",
+			path:                "/posts/1",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `{"id":"1","title":"json-server"}`,
+		},
+		{
+			name:                "Serialize xml by accept header",
+			path:                "/posts",
+			accept:              "application/xml",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/xml; charset=utf-8",
+			expectedBody: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<response><item><id>1</id><title>json-server</title></item></response>`,
+		},
+		{
+			name:                "Serialize csv by accept header",
+			path:                "/posts",
+			accept:              "text/csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,title\n1,json-server\n",
+		},
+		{
+			name:                "Serialize jsonapi error",
+			path:                "/posts/2",
+			accept:              "application/vnd.api+json",
+			expectedStatus:      http.StatusNotFound,
+			expectedContentType: "application/vnd.api+json",
+			expectedBody:        `{"errors":[{"status":"404","title":"Not Found","detail":"resource not found"}]}` + "\n",
+		},
+		{
+			name:                "Serialize resource format",
+			path:                "/reports",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,total\n1,3\n",
+		},
+		{
+			name:                "Serialize browser requests of resource format",
+			path:                "/reports",
+			accept:              "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,total\n1,3\n",
+		},
+		{
+			name:                "Serialize json by accept header over resource format",
+			path:                "/reports/1",
+			accept:              "application/json",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        `{"id":"1","total":3}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(http.MethodGet, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.expectedStatus {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.expectedStatus, resp.StatusCode)
+		}
+
+		if contentType := resp.Header.Get("Content-Type"); contentType != tt.expectedContentType {
+			t.Fatalf("%s: expected content type %q, but got %q", tt.name, tt.expectedContentType, contentType)
+		}
+
+		if string(body) != tt.expectedBody {
+			t.Fatalf("%s: expected body %q, but got %q", tt.name, tt.expectedBody, string(body))
+		}
+	}
+}
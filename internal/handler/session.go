@@ -0,0 +1,325 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+const (
+	// sessionPath is the url path of the current session, and the login and logout endpoints.
+	sessionPath = "/session"
+	// sessionCookie is the name of the cookie holding the session id.
+	sessionCookie = "session"
+	// csrfPath is the url path csrf tokens are issued under.
+	csrfPath = "/__csrf"
+	// csrfHeader is the request header holding the csrf token.
+	csrfHeader = "X-CSRF-Token"
+	// xsrfHeader is the request header holding the csrf token, as sent by some http clients.
+	xsrfHeader = "X-XSRF-Token"
+)
+
+var errInvalidCSRFToken = errors.New("missing or invalid csrf token")
+
+// Sandbox returns the handler serving a copy of the database, whose writes are kept apart from the database and
+// the other sandboxes.
+type Sandbox func(data storage.Database) (http.Handler, error)
+
+// Sessions keeps the cookie sessions of the users, each serving its requests with its own sandbox of the database.
+// It is safe for concurrent use.
+type Sessions struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	usersSvc storage.Storage
+	dbSvc    storage.Storage
+	sandbox  Sandbox
+	expiry   time.Duration
+	now      func() time.Time
+	// maxSessions is the number of live sessions, each holding a copy of the database, unlimited if zero.
+	maxSessions int
+	// csrf requires a csrf token on mutating requests.
+	csrf bool
+}
+
+type session struct {
+	user    storage.Resource
+	handler http.Handler
+	expires time.Time
+	// csrfToken is issued on the first request for it.
+	csrfToken string
+}
+
+type csrfResponse struct {
+	Token string `json:"token"`
+}
+
+// NewSessions returns a new sessions instance, authenticating the users of the users storage by email and password.
+// The sandbox of a session is created on login, from the contents of the db storage. Sessions expire based on the
+// provided time source. At most maxSessions sessions are kept, the ones expiring first are dropped on login once more. With
+// csrf, mutating requests of sessions require the csrf token of their session.
+func NewSessions(usersSvc, dbSvc storage.Storage, sandbox Sandbox, expiry time.Duration, now func() time.Time, maxSessions int, csrf bool) *Sessions {
+	return &Sessions{
+		sessions:    make(map[string]*session),
+		usersSvc:    usersSvc,
+		dbSvc:       dbSvc,
+		sandbox:     sandbox,
+		expiry:      expiry,
+		now:         now,
+		maxSessions: maxSessions,
+		csrf:        csrf,
+	}
+}
+
+// Len returns the number of sessions kept, including expired ones not dropped yet.
+func (s *Sessions) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
+
+// Handler operates as a http handler, serving the login, logout and current session endpoints, and the requests
+// with a valid session cookie by the sandbox of their session. Requests without a valid session fail with 401,
+// except for the home page and the routes under '/__', which are served by the fallback handler. The private
+// fields are omitted from the returned users. With csrf, '/__csrf' issues the csrf token of the session, and
+// mutating requests of the session without it fail with 403.
+func (s *Sessions) Handler(fallback http.Handler, privateFields ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == sessionPath+"/login" && r.Method == http.MethodPost:
+			s.login(w, r, privateFields)
+			return
+		case r.URL.Path == sessionPath+"/logout" && r.Method == http.MethodPost:
+			s.logout(w, r)
+			return
+		}
+
+		current, ok := s.get(r)
+
+		switch {
+		case r.URL.Path == sessionPath && r.Method == http.MethodGet:
+			if !ok {
+				web.Error(w, http.StatusUnauthorized, errUnauthorized.Error())
+				return
+			}
+
+			web.Success(w, http.StatusOK, safeUser(current.user, privateFields))
+		case r.URL.Path == csrfPath && r.Method == http.MethodGet && s.csrf:
+			if !ok {
+				web.Error(w, http.StatusUnauthorized, errUnauthorized.Error())
+				return
+			}
+
+			token, err := s.csrfToken(current)
+			if err != nil {
+				web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+				return
+			}
+
+			w.Header().Set(csrfHeader, token)
+			web.Success(w, http.StatusOK, csrfResponse{Token: token})
+		case ok:
+			if s.csrf && mutating(r.Method) && !s.validCSRFToken(current, r) {
+				web.Error(w, http.StatusForbidden, errInvalidCSRFToken.Error())
+				return
+			}
+
+			current.handler.ServeHTTP(w, r)
+		case r.URL.Path == "/" || strings.HasPrefix(r.URL.Path, "/__"):
+			fallback.ServeHTTP(w, r)
+		default:
+			web.Error(w, http.StatusUnauthorized, errUnauthorized.Error())
+		}
+	})
+}
+
+// login authenticates the user by email and password, and starts a session with a sandbox of the database.
+func (s *Sessions) login(w http.ResponseWriter, r *http.Request, privateFields []string) {
+	email, password, err := decodeCredentials(r)
+	if err != nil {
+		web.Error(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, err := s.usersSvc.Find()
+	if err != nil {
+		web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+		return
+	}
+
+	var user storage.Resource
+	for _, u := range users {
+		if u[emailField] == email {
+			user = u
+			break
+		}
+	}
+
+	hash, _ := user[passwordField].(string)
+	if user == nil || !checkPassword(hash, password) {
+		web.Error(w, http.StatusBadRequest, errInvalidCredentials.Error())
+		return
+	}
+
+	data, err := s.dbSvc.DB()
+	if err != nil {
+		web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+		return
+	}
+
+	sandbox, err := s.sandbox(data)
+	if err != nil {
+		web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+		return
+	}
+
+	id, err := newToken()
+	if err != nil {
+		web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+		return
+	}
+
+	expires := s.now().Add(s.expiry)
+
+	s.mu.Lock()
+	s.evict()
+	s.sessions[id] = &session{user: user, handler: sandbox, expires: expires}
+	s.mu.Unlock()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(s.expiry / time.Second),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	web.Success(w, http.StatusOK, safeUser(user, privateFields))
+}
+
+// logout ends the session of the request, dropping its sandbox, and clears the session cookie.
+func (s *Sessions) logout(w http.ResponseWriter, r *http.Request) {
+	if cookie, err := r.Cookie(sessionCookie); err == nil {
+		s.mu.Lock()
+		delete(s.sessions, cookie.Value)
+		s.mu.Unlock()
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// get returns the session of the request cookie, unless missing or expired. Expired sessions are dropped.
+func (s *Sessions) get(r *http.Request) (*session, bool) {
+	cookie, err := r.Cookie(sessionCookie)
+	if err != nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.sessions[cookie.Value]
+	if !ok {
+		return nil, false
+	}
+
+	if !s.now().Before(current.expires) {
+		delete(s.sessions, cookie.Value)
+		return nil, false
+	}
+
+	return current, true
+}
+
+// evict drops the expired sessions, whose cookies may never be sent again, and the sessions expiring first while
+// there is no room for another session. Callers must hold the lock.
+func (s *Sessions) evict() {
+	now := s.now()
+	for id, current := range s.sessions {
+		if !now.Before(current.expires) {
+			delete(s.sessions, id)
+		}
+	}
+
+	for s.maxSessions > 0 && len(s.sessions) >= s.maxSessions {
+		var first string
+		for id, current := range s.sessions {
+			if first == "" || current.expires.Before(s.sessions[first].expires) {
+				first = id
+			}
+		}
+
+		delete(s.sessions, first)
+	}
+}
+
+// csrfToken returns the csrf token of the session, issuing it on the first call.
+func (s *Sessions) csrfToken(current *session) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current.csrfToken == "" {
+		token, err := newToken()
+		if err != nil {
+			return "", err
+		}
+
+		current.csrfToken = token
+	}
+
+	return current.csrfToken, nil
+}
+
+// validCSRFToken reports whether the request holds the csrf token of the session, in either csrf header.
+func (s *Sessions) validCSRFToken(current *session, r *http.Request) bool {
+	s.mu.Lock()
+	expected := current.csrfToken
+	s.mu.Unlock()
+
+	token := r.Header.Get(csrfHeader)
+	if token == "" {
+		token = r.Header.Get(xsrfHeader)
+	}
+
+	return expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// mutating reports whether requests of the method change data.
+func mutating(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	return true
+}
+
+// newToken returns a random token, which cannot be guessed, for session ids and csrf tokens.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,349 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// newSandbox returns the handler serving the data in memory.
+func newSandbox(data storage.Database) (http.Handler, error) {
+	db := storage.NewMemoryDB(data)
+
+	resourceStorage := make(map[string]storage.Storage)
+	for resourceKey := range data {
+		storageSvc, err := storage.NewMemory(db, resourceKey)
+		if err != nil {
+			return nil, err
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	dbSvc, err := storage.NewMemory(db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resourceStorage["db"] = dbSvc
+
+	return handler.Setup(resourceStorage), nil
+}
+
+func TestSessions(t *testing.T) {
+	data := storage.Database{
+		"users": {{"id": "1", "email": "john@example.com", "password": "secret"}},
+		"posts": {{"id": "1"}},
+	}
+
+	db := storage.NewMemoryDB(data)
+
+	usersSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbSvc, err := storage.NewMemory(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fallback, err := newSandbox(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	sessions := handler.NewSessions(usersSvc, dbSvc, newSandbox, time.Hour, func() time.Time { return now }, 0, false)
+
+	server := httptest.NewServer(sessions.Handler(fallback))
+	defer server.Close()
+
+	newClient := func() *http.Client {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return &http.Client{Jar: jar}
+	}
+
+	do := func(client *http.Client, method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res
+	}
+
+	countPosts := func(client *http.Client) int {
+		res := do(client, http.MethodGet, "/posts", "")
+		defer res.Body.Close()
+
+		var posts []interface{}
+		if err := json.NewDecoder(res.Body).Decode(&posts); err != nil {
+			t.Fatal(err)
+		}
+
+		return len(posts)
+	}
+
+	john, jane := newClient(), newClient()
+
+	testCases := []struct {
+		name       string
+		client     *http.Client
+		method     string
+		path       string
+		body       string
+		statusCode int
+	}{
+		{name: "Request without session", client: john, method: http.MethodGet, path: "/posts", statusCode: http.StatusUnauthorized},
+		{name: "Current session without session", client: john, method: http.MethodGet, path: "/session", statusCode: http.StatusUnauthorized},
+		{name: "Admin route without session", client: john, method: http.MethodGet, path: "/__faker", statusCode: http.StatusOK},
+		{name: "Login with invalid credentials", client: john, method: http.MethodPost, path: "/session/login", body: `{"email": "john@example.com", "password": "wrong"}`, statusCode: http.StatusBadRequest},
+		{name: "Login", client: john, method: http.MethodPost, path: "/session/login", body: `{"email": "john@example.com", "password": "secret"}`, statusCode: http.StatusOK},
+		{name: "Login other session", client: jane, method: http.MethodPost, path: "/session/login", body: `{"email": "john@example.com", "password": "secret"}`, statusCode: http.StatusOK},
+		{name: "Current session", client: john, method: http.MethodGet, path: "/session", statusCode: http.StatusOK},
+		{name: "Create in session", client: john, method: http.MethodPost, path: "/posts", body: `{"id": "2", "title": "draft"}`, statusCode: http.StatusCreated},
+	}
+
+	for _, tt := range testCases {
+		res := do(tt.client, tt.method, tt.path, tt.body)
+		res.Body.Close()
+
+		if res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, res.StatusCode)
+		}
+	}
+
+	if john, jane := countPosts(john), countPosts(jane); john != 2 || jane != 1 {
+		t.Fatalf("expected the sessions to hold 2 and 1 posts, but got %d and %d", john, jane)
+	}
+
+	if posts, _ := dbSvc.DB(); len(posts["posts"]) != 1 {
+		t.Fatalf("expected the database to be unchanged, but got %v", posts["posts"])
+	}
+
+	res := do(john, http.MethodPost, "/session/logout", "")
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status code %d on logout, but got %d", http.StatusNoContent, res.StatusCode)
+	}
+
+	if res = do(john, http.MethodGet, "/posts", ""); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status code %d after logout, but got %d", http.StatusUnauthorized, res.StatusCode)
+	}
+	res.Body.Close()
+
+	now = now.Add(2 * time.Hour)
+
+	if res = do(jane, http.MethodGet, "/posts", ""); res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status code %d after expiry, but got %d", http.StatusUnauthorized, res.StatusCode)
+	}
+	res.Body.Close()
+}
+
+func TestSessions_CSRF(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {{"id": "1", "email": "john@example.com", "password": "secret"}},
+		"posts": {},
+	})
+
+	usersSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbSvc, err := storage.NewMemory(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := handler.NewSessions(usersSvc, dbSvc, newSandbox, time.Hour, time.Now, 0, true)
+
+	server := httptest.NewServer(sessions.Handler(http.NotFoundHandler()))
+	defer server.Close()
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &http.Client{Jar: jar}
+
+	do := func(method, path, body string, header http.Header) *http.Response {
+		req, err := http.NewRequest(method, server.URL+path, bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return res
+	}
+
+	res := do(http.MethodGet, "/__csrf", "", nil)
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected status code %d without session, but got %d", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	res = do(http.MethodPost, "/session/login", `{"email": "john@example.com", "password": "secret"}`, nil)
+	res.Body.Close()
+
+	res = do(http.MethodGet, "/__csrf", "", nil)
+
+	var csrf struct {
+		Token string `json:"token"`
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(&csrf); err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if csrf.Token == "" || res.Header.Get("X-CSRF-Token") != csrf.Token {
+		t.Fatalf("expected csrf token in body and header, but got %q and %q", csrf.Token, res.Header.Get("X-CSRF-Token"))
+	}
+
+	testCases := []struct {
+		name       string
+		method     string
+		header     http.Header
+		statusCode int
+	}{
+		{name: "Read without token", method: http.MethodGet, statusCode: http.StatusOK},
+		{name: "Create without token", method: http.MethodPost, statusCode: http.StatusForbidden},
+		{name: "Create with invalid token", method: http.MethodPost, header: http.Header{"X-Csrf-Token": {"invalid"}}, statusCode: http.StatusForbidden},
+		{name: "Create with token", method: http.MethodPost, header: http.Header{"X-Csrf-Token": {csrf.Token}}, statusCode: http.StatusCreated},
+		{name: "Create with xsrf token", method: http.MethodPost, header: http.Header{"X-Xsrf-Token": {csrf.Token}}, statusCode: http.StatusCreated},
+	}
+
+	for _, tt := range testCases {
+		body := ""
+		if tt.method == http.MethodPost {
+			body = `{"title": "draft"}`
+		}
+
+		res := do(tt.method, "/posts", body, tt.header)
+		res.Body.Close()
+
+		if res.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, tt.statusCode, res.StatusCode)
+		}
+	}
+}
+
+func TestSessions_Evict(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {{"id": "1", "email": "john@example.com", "password": "secret"}},
+		"posts": {},
+	})
+
+	usersSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbSvc, err := storage.NewMemory(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	sessions := handler.NewSessions(usersSvc, dbSvc, newSandbox, time.Hour, func() time.Time { return now }, 3, false)
+
+	server := httptest.NewServer(sessions.Handler(http.NotFoundHandler()))
+	defer server.Close()
+
+	// Every login without a cookie starts a new session.
+	login := func() *http.Cookie {
+		res, err := http.Post(server.URL+"/session/login", "application/json",
+			bytes.NewBufferString(`{"email": "john@example.com", "password": "secret"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("expected status code %d on login, but got %d", http.StatusOK, res.StatusCode)
+		}
+
+		return res.Cookies()[0]
+	}
+
+	status := func(cookie *http.Cookie) int {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/session", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.AddCookie(cookie)
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		return res.StatusCode
+	}
+
+	login()
+	login()
+
+	// Abandoned sessions are dropped once expired, without their cookies being sent again.
+	now = now.Add(2 * time.Hour)
+	login()
+
+	if n := sessions.Len(); n != 1 {
+		t.Fatalf("expected 1 session after expiry, but got %d", n)
+	}
+
+	// The sessions expiring first are dropped once there is no room for another one.
+	now = now.Add(time.Minute)
+	first := login()
+	now = now.Add(time.Minute)
+	second := login()
+	login()
+
+	login()
+
+	if n := sessions.Len(); n != 3 {
+		t.Fatalf("expected 3 sessions at most, but got %d", n)
+	}
+
+	if code := status(first); code != http.StatusUnauthorized {
+		t.Fatalf("expected status code %d for the dropped session, but got %d", http.StatusUnauthorized, code)
+	}
+
+	if code := status(second); code != http.StatusOK {
+		t.Fatalf("expected status code %d for a kept session, but got %d", http.StatusOK, code)
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/collate"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// sortResources sorts the resources by the '_sort' fields, e.g. 'lastName,firstName', in the '_order' of each
+// field, either 'asc' (default) or 'desc'. Nested fields are given by path, e.g. 'author.name'. Strings are
+// compared by code point, or following the alphabetical order of the '_collation' language, e.g. 'sv'. Numbers
+// are compared numerically and sort before strings, and resources missing a field sort last.
+func sortResources(resources []storage.Resource, query url.Values) ([]storage.Resource, error) {
+	fields := strings.Split(query.Get("_sort"), ",")
+
+	var orders []string
+	if order := query.Get("_order"); order != "" {
+		orders = strings.Split(order, ",")
+	}
+
+	descending := make([]bool, len(fields))
+	for idx := range fields {
+		if idx >= len(orders) {
+			continue
+		}
+
+		switch strings.ToLower(orders[idx]) {
+		case "asc":
+		case "desc":
+			descending[idx] = true
+		default:
+			return nil, fmt.Errorf("%w: _order", errInvalidQuery)
+		}
+	}
+
+	compareStrings := func(a, b string) int {
+		return strings.Compare(collate.Normalize(a), collate.Normalize(b))
+	}
+
+	if locale := query.Get("_collation"); locale != "" {
+		c, err := collate.New(locale)
+		if err != nil {
+			return nil, fmt.Errorf("%w: _collation", errInvalidQuery)
+		}
+
+		compareStrings = c.Compare
+	}
+
+	sorted := make([]storage.Resource, len(resources))
+	copy(sorted, resources)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		for idx, field := range fields {
+			a, okA := fieldValue(sorted[i], field)
+			b, okB := fieldValue(sorted[j], field)
+
+			// Resources missing the field sort last, in both orders.
+			if !okA || !okB {
+				if okA != okB {
+					return okA
+				}
+
+				continue
+			}
+
+			cmp := compareValues(a, b, compareStrings)
+			if descending[idx] {
+				cmp = -cmp
+			}
+
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+
+		return false
+	})
+
+	return sorted, nil
+}
+
+// fieldValue returns the value of a resource field by path, e.g. 'author.name'. Null values count as missing.
+func fieldValue(resource storage.Resource, path string) (interface{}, bool) {
+	var val interface{} = map[string]interface{}(resource)
+
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if val, ok = obj[key]; !ok || val == nil {
+			return nil, false
+		}
+	}
+
+	return val, true
+}
+
+// compareValues compares numbers numerically, strings with the string comparison, and booleans with false
+// first. Values of different types are ordered by type, numbers first, then strings, booleans and the rest.
+func compareValues(a, b interface{}, compareStrings func(a, b string) int) int {
+	rankA, rankB := valueRank(a), valueRank(b)
+	if rankA != rankB {
+		return compareInts(rankA, rankB)
+	}
+
+	if na, ok := number(a); ok {
+		nb, _ := number(b)
+		if na < nb {
+			return -1
+		}
+
+		if na > nb {
+			return 1
+		}
+
+		return 0
+	}
+
+	switch va := a.(type) {
+	case string:
+		return compareStrings(va, b.(string))
+	case bool:
+		vb := b.(bool)
+		if va == vb {
+			return 0
+		}
+
+		if !va {
+			return -1
+		}
+
+		return 1
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func valueRank(val interface{}) int {
+	if _, ok := number(val); ok {
+		return 0
+	}
+
+	switch val.(type) {
+	case string:
+		return 1
+	case bool:
+		return 2
+	}
+
+	return 3
+}
+
+func number(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+
+	if a > b {
+		return 1
+	}
+
+	return 0
+}
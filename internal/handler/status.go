@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// mockStatusHeader is the request header forcing the error status code of a single request, with mock headers
+// enabled.
+const mockStatusHeader = "X-Mock-Status"
+
+var errInvalidMockStatus = errors.New("status code must be between 400 and 599")
+
+// statusCodes is operating as middleware to override the success status codes of routes, e.g. to respond
+// 202 instead of 201 to 'POST /orders'. Error responses keep their status code. Bodies are dropped when
+// the overriding status code does not allow one.
+func statusCodes(routeStatus map[string]int) func(http.Handler) http.Handler {
+	routes := make([]string, 0, len(routeStatus))
+	for route := range routeStatus {
+		routes = append(routes, route)
+	}
+
+	matcher := newRouteMatcher(routes)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, _, ok := matcher.match(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(&statusWriter{ResponseWriter: w, statusCode: routeStatus[route]}, r)
+		})
+	}
+}
+
+// mockStatus is operating as middleware to respond to requests with the X-Mock-Status header with the error
+// status code of the header, e.g. 503, instead of serving them, so they have no side effects.
+func mockStatus(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		val := r.Header.Get(mockStatusHeader)
+		if val == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		statusCode, err := strconv.Atoi(val)
+		if err != nil || statusCode < 400 || statusCode > 599 {
+			web.Error(w, http.StatusBadRequest, fmt.Sprintf("%v: %s", errInvalidMockStatus, mockStatusHeader))
+			return
+		}
+
+		web.Error(w, statusCode, http.StatusText(statusCode))
+	})
+}
+
+// statusWriter replaces the success status code of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	noBody      bool
+}
+
+func (s *statusWriter) WriteHeader(statusCode int) {
+	if s.wroteHeader {
+		return
+	}
+
+	s.wroteHeader = true
+
+	if statusCode >= 200 && statusCode < 300 {
+		statusCode = s.statusCode
+	}
+
+	if statusCode == http.StatusNoContent || statusCode == http.StatusResetContent {
+		s.noBody = true
+		s.Header().Del("Content-Type")
+		s.Header().Del("Content-Length")
+	}
+
+	s.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (s *statusWriter) Write(b []byte) (int, error) {
+	if !s.wroteHeader {
+		s.WriteHeader(http.StatusOK)
+	}
+
+	// Report the body as written, as handlers cannot tell it is dropped.
+	if s.noBody {
+		return len(b), nil
+	}
+
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusWriter) Flush() {
+	if f, ok := s.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped response writer, so the deadlines of the response can be set.
+func (s *statusWriter) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,171 @@
+package handler_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestStatusCodes(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{"statusCodes": {"POST /orders": 202, "PUT /orders/:id": 204, "DELETE /users/:id": 204}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := storage.NewMemoryDB(storage.Database{
+		"orders": {{"id": "1"}},
+		"users":  {{"id": "1"}},
+	})
+
+	ordersSvc, err := storage.NewMemory(db, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	usersSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(
+		map[string]storage.Storage{"orders": ordersSvc, "users": usersSvc},
+		handler.WithConfig(cfg),
+	))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		statusCode int
+		emptyBody  bool
+	}{
+		{
+			name:       "Override created status",
+			method:     http.MethodPost,
+			path:       "/orders",
+			body:       `{"id": "2", "total": 10}`,
+			statusCode: http.StatusAccepted,
+		},
+		{
+			name:       "Override status and drop body",
+			method:     http.MethodPut,
+			path:       "/orders/1",
+			body:       `{"total": 10}`,
+			statusCode: http.StatusNoContent,
+			emptyBody:  true,
+		},
+		{
+			name:       "Override status of route with parameter",
+			method:     http.MethodDelete,
+			path:       "/users/1",
+			statusCode: http.StatusNoContent,
+			emptyBody:  true,
+		},
+		{
+			name:       "Keep error status",
+			method:     http.MethodPost,
+			path:       "/orders",
+			body:       `{"id": "2", "total": 10}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Keep status of other methods",
+			method:     http.MethodGet,
+			path:       "/orders/1",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Keep status of other routes",
+			method:     http.MethodDelete,
+			path:       "/orders/2",
+			statusCode: http.StatusOK,
+			emptyBody:  true,
+		},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.emptyBody != (len(body) == 0) {
+			t.Fatalf("%s: expected empty body %v, but got %v", tt.name, tt.emptyBody, string(body))
+		}
+	}
+}
+
+func TestMockStatus(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{"orders": {{"id": "1"}}})
+
+	ordersSvc, err := storage.NewMemory(db, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"orders": ordersSvc}, handler.WithMockHeaders()))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		status     string
+		statusCode int
+	}{
+		{name: "Force error", method: http.MethodGet, path: "/orders/1", status: "503", statusCode: http.StatusServiceUnavailable},
+		{name: "Force error of write", method: http.MethodPost, path: "/orders", status: "500", statusCode: http.StatusInternalServerError},
+		{name: "Invalid status code", method: http.MethodGet, path: "/orders", status: "200", statusCode: http.StatusBadRequest},
+		{name: "No status code", method: http.MethodGet, path: "/orders", statusCode: http.StatusOK},
+	}
+
+	for _, tt := range testCases {
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader(`{"item": "book"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		if tt.status != "" {
+			req.Header.Set("X-Mock-Status", tt.status)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+	}
+
+	if records, err := ordersSvc.Find(); err != nil || len(records) != 1 {
+		t.Fatalf("expected forced errors to create no orders, but got %v", records)
+	}
+}
@@ -0,0 +1,239 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/stub"
+)
+
+func TestStubs(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"orders": {{"id": "1", "total": 10}},
+	}), "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"orders": storageSvc}))
+	defer server.Close()
+
+	registered := []struct {
+		body       string
+		statusCode int
+	}{
+		{body: `{"method": "GET", "path": "/orders/1", "times": 1, "response": {"status": 503}}`, statusCode: http.StatusCreated},
+		{body: `{"path": "/payments*", "response": {"status": 202, "headers": {"X-Stub": "yes"}, "body": {"ok": true}}}`, statusCode: http.StatusCreated},
+		{body: `{"path": "/orders", "times": 0}`, statusCode: http.StatusBadRequest},
+		{body: `["/orders"]`, statusCode: http.StatusBadRequest},
+	}
+
+	for _, reg := range registered {
+		resp, err := http.Post(server.URL+"/__stubs", "application/json", strings.NewReader(reg.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != reg.statusCode {
+			t.Fatalf("expected status code %v registering %s, but got %v", reg.statusCode, reg.body, resp.StatusCode)
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		path       string
+		statusCode int
+		header     string
+		body       string
+	}{
+		{name: "Fail once", path: "/orders/1", statusCode: http.StatusServiceUnavailable},
+		{name: "Then succeed", path: "/orders/1", statusCode: http.StatusOK, body: `{"id":"1","total":10}`},
+		{name: "Stub unknown path", path: "/payments/42", statusCode: http.StatusAccepted, header: "yes", body: `{"ok":true}`},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body json.RawMessage
+		if tt.body != "" {
+			err = json.NewDecoder(resp.Body).Decode(&body)
+		}
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if got := resp.Header.Get("X-Stub"); got != tt.header {
+			t.Fatalf("%s: expected header %q, but got %q", tt.name, tt.header, got)
+		}
+
+		if string(body) != tt.body {
+			t.Fatalf("%s: expected body %s, but got %s", tt.name, tt.body, body)
+		}
+	}
+
+	// Only the stub without times is left, which can be removed by id.
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/__stubs/2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status code %v removing the stub, but got %v", http.StatusNoContent, resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/payments/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status code %v after removing the stub, but got %v", http.StatusNotFound, resp.StatusCode)
+	}
+}
+
+func TestStubs_Faults(t *testing.T) {
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{}))
+	defer server.Close()
+
+	stubs := []string{
+		`{"path": "/slow", "response": {"delay": "200ms", "status": 202}}`,
+		`{"path": "/hang", "response": {"fault": "hang"}}`,
+		`{"path": "/reset", "response": {"fault": "reset"}}`,
+		`{"path": "/truncate", "response": {"fault": "truncate", "body": {"title": "json-server"}}}`,
+	}
+
+	for _, body := range stubs {
+		resp, err := http.Post(server.URL+"/__stubs", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status code %v registering %s, but got %v", http.StatusCreated, body, resp.StatusCode)
+		}
+	}
+
+	start := time.Now()
+
+	resp, err := http.Get(server.URL + "/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if elapsed := time.Since(start); resp.StatusCode != http.StatusAccepted || elapsed < 200*time.Millisecond {
+		t.Fatalf("expected status code %v after the delay, but got %v after %v", http.StatusAccepted, resp.StatusCode, elapsed)
+	}
+
+	client := &http.Client{Timeout: 200 * time.Millisecond}
+	if _, err = client.Get(server.URL + "/hang"); err == nil {
+		t.Fatal("expected timeout for hanging stub, but got a response")
+	}
+
+	if _, err = http.Get(server.URL + "/reset"); err == nil {
+		t.Fatal("expected error for reset stub, but got a response")
+	}
+
+	resp, err = http.Get(server.URL + "/truncate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err == nil {
+		t.Fatal("expected error reading the body of truncate stub, but got none")
+	}
+
+	resp, err = http.Post(server.URL+"/__stubs", "application/json", strings.NewReader(`{"path": "/x", "response": {"fault": "close"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status code %v registering unknown fault, but got %v", http.StatusBadRequest, resp.StatusCode)
+	}
+}
+
+func TestStubs_WriteTimeout(t *testing.T) {
+	server := httptest.NewUnstartedServer(handler.Setup(map[string]storage.Storage{}))
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	body := `{"path": "/slow", "response": {"delay": "300ms", "status": 202, "body": {"ok": true}}}`
+
+	resp, err := http.Post(server.URL+"/__stubs", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v registering %s, but got %v", http.StatusCreated, body, resp.StatusCode)
+	}
+
+	// The delay is longer than the write timeout of the server.
+	resp, err = http.Get(server.URL + "/slow")
+	if err != nil {
+		t.Fatalf("expected response after the delay, but got %v", err)
+	}
+	defer resp.Body.Close()
+
+	var got map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusAccepted || got["ok"] != true {
+		t.Fatalf("expected status code %v and body of the stub, but got %v and %v", http.StatusAccepted, resp.StatusCode, got)
+	}
+}
+
+func TestWithStubs(t *testing.T) {
+	stubs := []stub.Stub{{Method: http.MethodGet, Path: "/profile", Response: stub.Response{Body: map[string]interface{}{"name": "alice"}}}}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{}, handler.WithStubs(stubs)))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/profile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK || body["name"] != "alice" {
+		t.Fatalf("expected status code %v with the stub body, but got %v with %v", http.StatusOK, resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Tenants operates as a http handler, serving the requests of every tenant with its handler, by the subdomain of
+// the request host, e.g. 'acme' for 'acme.localhost:3000'. Requests of other hosts, including ip addresses and
+// unknown subdomains, are served by the fallback handler.
+func Tenants(tenants map[string]http.Handler, fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := tenants[subdomain(r.Host)]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		fallback.ServeHTTP(w, r)
+	})
+}
+
+// subdomain returns the first label of the host, or an empty string for hosts without subdomain and ip addresses.
+func subdomain(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	if net.ParseIP(host) != nil {
+		return ""
+	}
+
+	idx := strings.Index(host, ".")
+	if idx < 0 {
+		return ""
+	}
+
+	return strings.ToLower(host[:idx])
+}
@@ -0,0 +1,60 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestTenants(t *testing.T) {
+	setup := func(name string) http.Handler {
+		db := storage.NewMemoryDB(storage.Database{"users": {{"id": "1", "name": name}}})
+
+		storageSvc, err := storage.NewMemory(db, "users")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return handler.Setup(map[string]storage.Storage{"users": storageSvc})
+	}
+
+	h := handler.Tenants(map[string]http.Handler{"acme": setup("acme"), "globex": setup("globex")}, setup("default"))
+
+	testCases := []struct {
+		name     string
+		host     string
+		expected string
+	}{
+		{name: "Serve tenant", host: "acme.localhost:3000", expected: "acme"},
+		{name: "Serve other tenant", host: "globex.example.com", expected: "globex"},
+		{name: "Serve tenant ignoring case", host: "ACME.localhost", expected: "acme"},
+		{name: "Serve host without subdomain", host: "localhost:3000", expected: "default"},
+		{name: "Serve unknown subdomain", host: "initech.localhost:3000", expected: "default"},
+		{name: "Serve ip address", host: "127.0.0.1:3000", expected: "default"},
+	}
+
+	for _, tt := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Host = tt.host
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: expected status code %d, but got %d", tt.name, http.StatusOK, rec.Code)
+		}
+
+		var user map[string]interface{}
+		if err := json.NewDecoder(rec.Body).Decode(&user); err != nil {
+			t.Fatal(err)
+		}
+
+		if user["name"] != tt.expected {
+			t.Fatalf("%s: expected resource of %s, but got %v", tt.name, tt.expected, user["name"])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// traced returns the storage, logging its operations on the debug level along with the request, to
+// troubleshoot why a request responds unexpected data.
+func traced(r *http.Request, storageSvc storage.Storage) storage.Storage {
+	if !logrus.IsLevelEnabled(logrus.DebugLevel) {
+		return storageSvc
+	}
+
+	return &tracedStorage{Storage: storageSvc, entry: traceEntry(r), resourceKey: routeResourceKey(r)}
+}
+
+// traceFilter logs the filters of the query on the debug level, with the number of resources they kept.
+func traceFilter(r *http.Request, query url.Values, total, kept int) {
+	if !logrus.IsLevelEnabled(logrus.DebugLevel) || len(query) == 0 {
+		return
+	}
+
+	traceEntry(r).Debugf("filter %s ?%s: %d of %d resources", routeResourceKey(r), query.Encode(), kept, total)
+}
+
+func traceEntry(r *http.Request) *logrus.Entry {
+	return logrus.WithField("method", r.Method).WithField("url", r.URL.Path)
+}
+
+// tracedStorage logs the storage operations of a request.
+type tracedStorage struct {
+	storage.Storage
+	entry       *logrus.Entry
+	resourceKey string
+}
+
+func (t *tracedStorage) Find() ([]storage.Resource, error) {
+	return t.FindContext(context.Background())
+}
+
+func (t *tracedStorage) FindContext(ctx context.Context) ([]storage.Resource, error) {
+	data, err := storage.FindContext(ctx, t.Storage)
+	if err != nil {
+		t.entry.Debugf("storage list %s: %v", t.resourceKey, err)
+		return data, err
+	}
+
+	t.entry.Debugf("storage list %s: %d resources", t.resourceKey, len(data))
+
+	return data, nil
+}
+
+func (t *tracedStorage) FindById(id string) (storage.Resource, error) {
+	data, err := t.Storage.FindById(id)
+	t.log("find", id, err)
+
+	return data, err
+}
+
+func (t *tracedStorage) Create(resource storage.Resource) (storage.Resource, error) {
+	data, err := t.Storage.Create(resource)
+
+	id := ""
+	if err == nil {
+		id, _ = data["id"].(string)
+	}
+
+	t.log("insert", id, err)
+
+	return data, err
+}
+
+func (t *tracedStorage) Replace(id string, resource storage.Resource) (storage.Resource, error) {
+	data, err := t.Storage.Replace(id, resource)
+	t.log("replace", id, err)
+
+	return data, err
+}
+
+func (t *tracedStorage) Update(id string, resource storage.Resource) (storage.Resource, error) {
+	data, err := t.Storage.Update(id, resource)
+	t.log("update", id, err)
+
+	return data, err
+}
+
+func (t *tracedStorage) Delete(id string) error {
+	err := t.Storage.Delete(id)
+	t.log("delete", id, err)
+
+	return err
+}
+
+// log logs an operation on a single resource.
+func (t *tracedStorage) log(operation, id string, err error) {
+	if err != nil {
+		t.entry.Debugf("storage %s %s/%s: %v", operation, t.resourceKey, id, err)
+		return
+	}
+
+	t.entry.Debugf("storage %s %s/%s: ok", operation, t.resourceKey, id)
+}
@@ -0,0 +1,117 @@
+package handler_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/logger"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// lockedBuffer collects logs, which the request logger may still write while they are read.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+func (b *lockedBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.buf.Reset()
+}
+
+func TestTrace(t *testing.T) {
+	var logs lockedBuffer
+
+	logrus.SetOutput(&logs)
+	logrus.SetFormatter(&logger.CustomFormatter{})
+	logrus.SetLevel(logrus.DebugLevel)
+
+	defer func() {
+		logrus.SetOutput(os.Stdout)
+		logrus.SetLevel(logrus.InfoLevel)
+	}()
+
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"posts": {{"id": "1", "createdAt": 1}, {"id": "2", "createdAt": 2}, {"id": "3", "createdAt": 3}},
+	}), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+	defer server.Close()
+
+	testCases := []struct {
+		name     string
+		method   string
+		path     string
+		expected []string
+	}{
+		{
+			name:   "Trace list and filters",
+			method: http.MethodGet,
+			path:   "/posts?createdAt_after=1",
+			expected: []string{
+				"GET /posts - storage list posts: 3 resources",
+				"GET /posts - filter posts ?createdAt_after=1: 2 of 3 resources",
+			},
+		},
+		{
+			name:     "Trace missing resource",
+			method:   http.MethodGet,
+			path:     "/posts/4",
+			expected: []string{"GET /posts/4 - storage find posts/4: resource not found"},
+		},
+		{
+			name:     "Trace delete",
+			method:   http.MethodDelete,
+			path:     "/posts/1",
+			expected: []string{"DELETE /posts/1 - storage delete posts/1: ok"},
+		},
+	}
+
+	for _, tt := range testCases {
+		logs.Reset()
+
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		for _, expected := range tt.expected {
+			if !strings.Contains(logs.String(), expected+"\n") {
+				t.Fatalf("%s: expected log %q, but got %q", tt.name, expected, logs.String())
+			}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 
@@ -18,8 +17,8 @@ func Update(storageSvc storage.Storage) http.HandlerFunc {
 		id := mux.Vars(r)["id"]
 
 		// Read and decode request body.
-		var newResource storage.Resource
-		if err := json.NewDecoder(r.Body).Decode(&newResource); err != nil {
+		newResource, err := decodeResource(r)
+		if err != nil {
 			web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
 			return
 		}
@@ -31,7 +30,7 @@ func Update(storageSvc storage.Storage) http.HandlerFunc {
 		}
 
 		// Update the resource.
-		data, err := storageSvc.Update(id, newResource)
+		data, err := traced(r, storageSvc).Update(id, newResource)
 		if err != nil {
 			// Resource not found.
 			if errors.Is(err, storage.ErrResourceNotFound) {
@@ -39,10 +38,34 @@ func Update(storageSvc storage.Storage) http.HandlerFunc {
 				return
 			}
 
+			// Duplicate value of a unique field.
+			if errors.Is(err, storage.ErrDuplicateValue) {
+				web.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+
+			// Missing required fields.
+			if errors.Is(err, storage.ErrMissingFields) {
+				web.Error(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+
+			// Fields which only differ in case.
+			if errors.Is(err, storage.ErrConflictingFields) {
+				web.Error(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			}
+
+			// Watch file changed by another writer meanwhile.
+			if errors.Is(err, storage.ErrFileChanged) {
+				web.Error(w, http.StatusConflict, err.Error())
+				return
+			}
+
 			web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
 			return
 		}
 
-		web.Success(w, http.StatusOK, data)
+		respondWritten(w, r, http.StatusOK, data)
 	}
 }
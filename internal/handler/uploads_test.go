@@ -0,0 +1,170 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestUploads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	storageSvc, err := storage.NewMock(storage.Database{"users": {}}, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"users": storageSvc}, handler.WithUploads(dir)))
+	defer server.Close()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	if err = mw.WriteField("name", "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	fw, err := mw.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = fw.Write([]byte("avatar-content")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Post(server.URL+"/users", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	var got storage.Resource
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["name"] != "user" {
+		t.Fatalf("expected name %v, but got %v", "user", got["name"])
+	}
+
+	avatarURL, ok := got["avatar"].(string)
+	if !ok || !strings.HasPrefix(avatarURL, "/uploads/") || !strings.HasSuffix(avatarURL, ".png") {
+		t.Fatalf("expected avatar upload url, but got %v", got["avatar"])
+	}
+
+	resp, err = http.Get(server.URL + avatarURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "avatar-content" {
+		t.Fatalf("expected uploaded content %v, but got %v", "avatar-content", string(content))
+	}
+}
+
+func TestUploads_Cleanup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "uploads")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	storageSvc, err := storage.NewMock(storage.Database{"users": {{"id": "1", "name": "user"}}}, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(
+		map[string]storage.Storage{"users": storageSvc},
+		handler.WithUploads(dir),
+		handler.WithUploadLimit(1024),
+	))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		id         string
+		content    string
+		statusCode int
+	}{
+		{
+			name:       "Reject body over the limit",
+			id:         "2",
+			content:    strings.Repeat("a", 2048),
+			statusCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:       "Remove files of failed request",
+			id:         "1",
+			content:    "avatar-content",
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range testCases {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+
+		if err = mw.WriteField("id", tt.id); err != nil {
+			t.Fatal(err)
+		}
+
+		fw, err := mw.CreateFormFile("avatar", "avatar.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = fw.Write([]byte(tt.content)); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = mw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.Post(server.URL+"/users", mw.FormDataContentType(), &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(files) != 0 {
+			t.Fatalf("%s: expected no stored files, but got %v", tt.name, len(files))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestVerify(t *testing.T) {
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"orders": {{"id": "1", "total": 10}},
+	}), "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"orders": storageSvc}, handler.WithCapture(10)))
+	defer server.Close()
+
+	sent := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodGet, path: "/orders?total=10"},
+		{method: http.MethodPost, path: "/orders", body: `{"id": "2", "total": 20, "note": "gift"}`},
+	}
+
+	for _, req := range sent {
+		r, err := http.NewRequest(req.method, server.URL+req.path, strings.NewReader(req.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	testCases := []struct {
+		name       string
+		body       string
+		statusCode int
+		passed     bool
+		diffs      [][]string
+	}{
+		{
+			name:       "Verify single expectation",
+			body:       `{"method": "GET", "path": "/orders", "query": {"total": "10"}, "count": 1}`,
+			statusCode: http.StatusOK,
+			passed:     true,
+			diffs:      [][]string{nil},
+		},
+		{
+			name: "Verify list of expectations",
+			body: `[
+				{"method": "POST", "path": "/orders", "body": {"total": 20}},
+				{"method": "POST", "path": "/orders", "body": {"total": 30}},
+				{"method": "DELETE", "path": "/orders/*", "count": 0}
+			]`,
+			statusCode: http.StatusOK,
+			diffs: [][]string{
+				nil,
+				{"expected at least 1 matching requests, but got 0", "request 2: body.total: expected 30, but got 20"},
+				nil,
+			},
+		},
+		{
+			name:       "Verify invalid expectations",
+			body:       `"orders"`,
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range testCases {
+		resp, err := http.Post(server.URL+"/__verify", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			resp.Body.Close()
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if tt.statusCode != http.StatusOK {
+			resp.Body.Close()
+			continue
+		}
+
+		var got struct {
+			Passed  bool             `json:"passed"`
+			Results []capture.Result `json:"results"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Passed != tt.passed {
+			t.Fatalf("%s: expected passed %v, but got %v", tt.name, tt.passed, got.Passed)
+		}
+
+		if len(got.Results) != len(tt.diffs) {
+			t.Fatalf("%s: expected %v results, but got %v", tt.name, len(tt.diffs), len(got.Results))
+		}
+
+		for idx, diffs := range tt.diffs {
+			if strings.Join(got.Results[idx].Diffs, "\n") != strings.Join(diffs, "\n") {
+				t.Fatalf("%s: expected diffs %q, but got %q", tt.name, diffs, got.Results[idx].Diffs)
+			}
+		}
+	}
+
+	// Verifying is not captured, so the requests are unchanged.
+	resp, err := http.Get(server.URL + "/__requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var entries []capture.Entry
+	if err = json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(sent) {
+		t.Fatalf("expected %v captured requests, but got %v", len(sent), len(entries))
+	}
+}
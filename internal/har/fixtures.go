@@ -0,0 +1,122 @@
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/stub"
+)
+
+// Fixtures builds the data and stubs of a mock serving the successful json GET requests of the entries. Arrays
+// of objects served by '/:resource', and objects served by '/:resource/:id', are merged by id into the resource,
+// later entries overriding earlier ones. Ids are written as strings, as the server looks them up. Any other
+// response, e.g. of '/users/1/orders' or '/profile', becomes a stub of its path, the latest entry winning. The
+// prefix is stripped from the request paths.
+func Fixtures(entries []Entry, stripPrefix string) (storage.Database, []stub.Stub) {
+	data := make(storage.Database)
+	stubs := make(map[string]stub.Stub)
+
+	for _, entry := range entries {
+		if entry.Method != http.MethodGet || entry.Status < 200 || entry.Status > 299 || !strings.Contains(entry.MimeType, "json") {
+			continue
+		}
+
+		var body interface{}
+		if err := json.Unmarshal(entry.Body, &body); err != nil {
+			continue
+		}
+
+		path := entry.Path(stripPrefix)
+		segments := strings.Split(strings.Trim(path, "/"), "/")
+
+		switch {
+		case len(segments) == 1 && segments[0] != "" && objects(body) != nil:
+			if _, ok := data[segments[0]]; !ok {
+				data[segments[0]] = make([]storage.Resource, 0)
+			}
+
+			for _, obj := range objects(body) {
+				data[segments[0]] = merge(data[segments[0]], obj)
+			}
+
+			continue
+		case len(segments) == 2:
+			if obj, ok := body.(map[string]interface{}); ok {
+				if _, ok := obj["id"]; !ok {
+					obj["id"] = segments[1]
+				}
+
+				data[segments[0]] = merge(data[segments[0]], obj)
+
+				continue
+			}
+		}
+
+		stubs[path] = stub.Stub{
+			Method:   http.MethodGet,
+			Path:     path,
+			Response: stub.Response{Status: entry.Status, Body: body},
+		}
+	}
+
+	paths := make([]string, 0, len(stubs))
+	for path := range stubs {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	list := make([]stub.Stub, 0, len(paths))
+	for _, path := range paths {
+		list = append(list, stubs[path])
+	}
+
+	return data, list
+}
+
+// objects returns the objects of the array, or nil if the value is no array of objects.
+func objects(val interface{}) []map[string]interface{} {
+	values, ok := val.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	objs := make([]map[string]interface{}, 0, len(values))
+	for _, v := range values {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs
+}
+
+// merge adds the object to the resources, replacing the resource with the same id. Objects without id are added
+// unless an equal one exists.
+func merge(resources []storage.Resource, obj map[string]interface{}) []storage.Resource {
+	id, ok := obj["id"]
+	if ok && id != nil {
+		obj["id"] = fmt.Sprint(id)
+	}
+
+	for idx, resource := range resources {
+		if ok && id != nil && resource["id"] == obj["id"] {
+			resources[idx] = obj
+			return resources
+		}
+
+		if !ok && reflect.DeepEqual(map[string]interface{}(resource), obj) {
+			return resources
+		}
+	}
+
+	return append(resources, obj)
+}
@@ -0,0 +1,124 @@
+// Package har reads HAR (HTTP Archive) files, as exported by the browser devtools, and builds the data and stubs
+// of a mock serving the captured json api traffic.
+package har
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrInvalidHAR returns an error when a HAR file cannot be parsed.
+var ErrInvalidHAR = errors.New("invalid har file")
+
+// Entry is a captured request and its response.
+type Entry struct {
+	Method string
+	URL    *url.URL
+	Header http.Header
+	// RequestMimeType and RequestBody are empty for requests without a body.
+	RequestMimeType string
+	RequestBody     []byte
+	// Status is 0 for failed requests, which got no response.
+	Status   int
+	MimeType string
+	Body     []byte
+}
+
+// archive is the part of a HAR file describing the requests and their responses.
+type archive struct {
+	Log struct {
+		Entries []struct {
+			Request struct {
+				Method  string `json:"method"`
+				URL     string `json:"url"`
+				Headers []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"headers"`
+				PostData *struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+				} `json:"postData"`
+			} `json:"request"`
+			Response struct {
+				Status  int `json:"status"`
+				Content struct {
+					MimeType string `json:"mimeType"`
+					Text     string `json:"text"`
+					Encoding string `json:"encoding"`
+				} `json:"content"`
+			} `json:"response"`
+		} `json:"entries"`
+	} `json:"log"`
+}
+
+// Read returns the entries of the HAR file, in the order they were captured.
+func Read(file string) ([]Entry, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	src := archive{}
+	if err = json.Unmarshal(content, &src); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHAR, err)
+	}
+
+	entries := make([]Entry, 0, len(src.Log.Entries))
+	for idx, e := range src.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: entry %d: %v", ErrInvalidHAR, idx+1, err)
+		}
+
+		entry := Entry{
+			Method:   e.Request.Method,
+			URL:      u,
+			Header:   make(http.Header),
+			Status:   e.Response.Status,
+			MimeType: e.Response.Content.MimeType,
+			Body:     []byte(e.Response.Content.Text),
+		}
+
+		for _, header := range e.Request.Headers {
+			entry.Header.Add(header.Name, header.Value)
+		}
+
+		if e.Request.PostData != nil {
+			entry.RequestMimeType = e.Request.PostData.MimeType
+			entry.RequestBody = []byte(e.Request.PostData.Text)
+		}
+
+		if e.Response.Content.Encoding == "base64" {
+			if entry.Body, err = base64.StdEncoding.DecodeString(e.Response.Content.Text); err != nil {
+				return nil, fmt.Errorf("%w: entry %d: %v", ErrInvalidHAR, idx+1, err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// JSON reports whether the request of the entry is a json api request, i.e. sends or receives json.
+func (e Entry) JSON() bool {
+	return strings.Contains(e.MimeType, "json") || strings.Contains(e.RequestMimeType, "json")
+}
+
+// Path returns the escaped path of the request without the prefix, e.g. '/api' for requests to '/api/posts'
+// served by '/posts'.
+func (e Entry) Path(stripPrefix string) string {
+	path := strings.TrimPrefix(e.URL.EscapedPath(), strings.TrimSuffix(stripPrefix, "/"))
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+
+	return path
+}
@@ -0,0 +1,117 @@
+package har_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/har"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+const testHAR = `{
+	"log": {
+		"entries": [
+			{
+				"request": {"method": "GET", "url": "https://example.com/index.html", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "text/html", "text": "<html></html>"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/posts?_page=1", "headers": [{"name": "accept", "value": "application/json"}]},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "[{\"id\": 1, \"title\": \"draft\"}, {\"id\": 2, \"title\": \"json-server\"}]"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/posts/1", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "application/json; charset=utf-8", "text": "eyJpZCI6MSwidGl0bGUiOiJwdWJsaXNoZWQifQ==", "encoding": "base64"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/users/7", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"name\": \"alice\"}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/users/7/orders", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "[{\"id\": 3}]"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/profile", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"name\": \"alice\"}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/tags", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "[]"}}
+			},
+			{
+				"request": {"method": "POST", "url": "https://example.com/api/posts", "headers": [], "postData": {"mimeType": "application/json", "text": "{\"title\": \"new\"}"}},
+				"response": {"status": 201, "content": {"mimeType": "application/json", "text": "{\"id\": 4, \"title\": \"new\"}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/missing", "headers": []},
+				"response": {"status": 404, "content": {"mimeType": "application/json", "text": "{}"}}
+			}
+		]
+	}
+}`
+
+func TestFixtures(t *testing.T) {
+	dir, err := ioutil.TempDir("", "har")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "traffic.har")
+	if err = ioutil.WriteFile(file, []byte(testHAR), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := har.Read(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 9 {
+		t.Fatalf("expected %v entries, but got %v", 9, len(entries))
+	}
+
+	data, stubs := har.Fixtures(entries, "/api/")
+
+	expectedData := storage.Database{
+		"posts": []storage.Resource{{"id": "1", "title": "published"}, {"id": "2", "title": "json-server"}},
+		"users": []storage.Resource{{"id": "7", "name": "alice"}},
+		"tags":  []storage.Resource{},
+	}
+
+	if !reflect.DeepEqual(data, expectedData) {
+		t.Fatalf("expected data %v, but got %v", expectedData, data)
+	}
+
+	expectedPaths := []string{"/profile", "/users/7/orders"}
+	if len(stubs) != len(expectedPaths) {
+		t.Fatalf("expected %v stubs, but got %+v", len(expectedPaths), stubs)
+	}
+
+	for idx, path := range expectedPaths {
+		if stubs[idx].Path != path || stubs[idx].Method != "GET" || stubs[idx].Response.Status != 200 {
+			t.Fatalf("expected GET stub of %v, but got %+v", path, stubs[idx])
+		}
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "har")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "traffic.har")
+	if err = ioutil.WriteFile(file, []byte(`{"log": {"entries": [{"request": {"url": "%%"}}]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = har.Read(file); err == nil {
+		t.Fatal("expected error reading invalid url, but got none")
+	}
+}
@@ -1,53 +1,106 @@
 package logger
 
 import (
+	"bytes"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/gookit/color"
 	"github.com/sirupsen/logrus"
 )
 
+// slowRequestThreshold is the duration above which a request is highlighted as slow.
+const slowRequestThreshold = 500 * time.Millisecond
+
 // CustomFormatter for logrus logger.
 type CustomFormatter struct {
+	// Colors enables colorized output, meant for interactive terminals.
+	Colors bool
 }
 
 // Format renders a single custom log entry.
 func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b bytes.Buffer
+
 	// Log method field.
 	if method, ok := entry.Data["method"]; ok {
-		fmt.Printf("%v", method)
+		fmt.Fprintf(&b, "%s", f.paint(methodColor(fmt.Sprintf("%v", method)), method))
 	}
 
 	// Log url field.
 	if url, ok := entry.Data["url"]; ok {
-		fmt.Printf(" %v", url)
+		fmt.Fprintf(&b, " %v", url)
 	}
 
 	// Log status field.
 	if status, ok := entry.Data["status"]; ok {
-		switch entry.Level {
-		case logrus.InfoLevel:
-			color.Green.Printf(" %v", status)
-		case logrus.WarnLevel:
-			color.Yellow.Printf(" %v", status)
-		case logrus.ErrorLevel:
-			color.Red.Printf(" %v", status)
-		default:
-			color.White.Printf(" %v", status)
-		}
+		fmt.Fprintf(&b, " %s", f.paint(levelColor(entry.Level), status))
 	}
 
-	// Log duration field.
+	// Log duration field, highlighting slow requests.
 	if duration, ok := entry.Data["duration"]; ok {
-		fmt.Printf(" - %v", duration)
+		if d, isDuration := duration.(time.Duration); isDuration && d >= slowRequestThreshold {
+			fmt.Fprintf(&b, " - %s", f.paint(color.Magenta, fmt.Sprintf("%v (slow)", d)))
+		} else {
+			fmt.Fprintf(&b, " - %v", duration)
+		}
 	}
 
 	// Log size field.
 	if size, ok := entry.Data["size"]; ok {
-		fmt.Printf(" - %v Bytes", size)
+		fmt.Fprintf(&b, " - %v Bytes", size)
+	}
+
+	// Log plain messages, e.g. entries without request fields.
+	if entry.Message != "" {
+		if b.Len() > 0 {
+			b.WriteString(" - ")
+		}
+
+		b.WriteString(entry.Message)
+	}
+
+	b.WriteByte('\n')
+
+	return b.Bytes(), nil
+}
+
+// paint renders the value with the provided color, if colors are enabled.
+func (f *CustomFormatter) paint(c color.Color, val interface{}) string {
+	if !f.Colors {
+		return fmt.Sprintf("%v", val)
 	}
 
-	fmt.Println()
+	return c.Sprint(val)
+}
 
-	return nil, nil
+// methodColor returns the color of a http method.
+func methodColor(method string) color.Color {
+	switch method {
+	case http.MethodGet:
+		return color.Cyan
+	case http.MethodPost:
+		return color.Green
+	case http.MethodPut, http.MethodPatch:
+		return color.Yellow
+	case http.MethodDelete:
+		return color.Red
+	default:
+		return color.White
+	}
+}
+
+// levelColor returns the color of a log level.
+func levelColor(level logrus.Level) color.Color {
+	switch level {
+	case logrus.InfoLevel:
+		return color.Green
+	case logrus.WarnLevel:
+		return color.Yellow
+	case logrus.ErrorLevel:
+		return color.Red
+	default:
+		return color.White
+	}
 }
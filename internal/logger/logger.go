@@ -3,15 +3,34 @@ package logger
 
 import (
 	"io/ioutil"
+	"os"
 
 	"github.com/sirupsen/logrus"
 )
 
-// Setup logger options.
-func Setup(show bool) {
-	logrus.SetFormatter(&CustomFormatter{})
+// Setup logger options. Colorized output is only used when logging to an interactive terminal. Debug
+// logs trace the storage operations of every request, and imply showing logs.
+func Setup(show, debug bool) {
+	logrus.SetOutput(os.Stdout)
+	logrus.SetFormatter(&CustomFormatter{Colors: isTerminal(os.Stdout)})
+	logrus.SetLevel(logrus.InfoLevel)
+
+	if debug {
+		logrus.SetLevel(logrus.DebugLevel)
+		return
+	}
 
 	if !show {
 		logrus.SetOutput(ioutil.Discard)
 	}
 }
+
+// isTerminal reports whether the file is an interactive terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
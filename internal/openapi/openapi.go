@@ -0,0 +1,258 @@
+// Package openapi describes the routes of the served resources as an OpenAPI 3 document, with the schemas of their
+// records inferred from the data, so the mocked api can be explored with Swagger UI or fed to client generators.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/codegen"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/schema"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// version is the OpenAPI version of the generated documents.
+const version = "3.0.3"
+
+// errorSchema is the name of the schema of error responses.
+const errorSchema = "Error"
+
+// Document is an OpenAPI document, holding the routes of the resources and the schemas of their records.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the api.
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path, by lower case method.
+type PathItem map[string]*Operation
+
+// Operation is a route of a resource.
+type Operation struct {
+	Tags        []string            `json:"tags"`
+	Summary     string              `json:"summary"`
+	OperationID string              `json:"operationId"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path or query parameter of an operation.
+type Parameter struct {
+	Name        string                 `json:"name"`
+	In          string                 `json:"in"`
+	Description string                 `json:"description,omitempty"`
+	Required    bool                   `json:"required,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// RequestBody is the json body of an operation.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation, with a json body unless empty.
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header is a response header.
+type Header struct {
+	Description string                 `json:"description"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// MediaType holds the schema of a json body.
+type MediaType struct {
+	Schema map[string]interface{} `json:"schema"`
+}
+
+// Components holds the schemas of the records of the resources, by type name, e.g. 'Post' of 'posts'.
+type Components struct {
+	Schemas map[string]interface{} `json:"schemas"`
+}
+
+// relatedParams describes the query parameters of list and read requests, resolving the related resources.
+var relatedParams = []Parameter{
+	queryParam("_embed", "Resource whose records pointing at the returned ones are embedded, e.g. comments"),
+	queryParam("_expand", "Resource whose record the returned ones point at is expanded, e.g. post"),
+}
+
+// listParams describes the query parameters of list requests. Fields of the records filter them as well.
+var listParams = append([]Parameter{
+	queryParam("q", "Full-text search"),
+	queryParam("_page", "Page number, starting at 1"),
+	queryParam("_limit", "Number of resources per page"),
+	queryParam("_sort", "Fields to sort by, separated by commas"),
+	queryParam("_order", "Order of the sort fields, asc or desc"),
+}, relatedParams...)
+
+// New returns the document of the routes of the resources, as served with the configuration. Hidden resources are
+// left out, and so are the read routes of write-only resources and the write routes of read-only ones, or of all
+// resources if read only. The schemas of the records are inferred from the data, without private fields.
+func New(title string, data storage.Database, cfg *config.Config, readOnly bool) *Document {
+	resourceKeys := make([]string, 0, len(data))
+	for resourceKey := range data {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	sort.Strings(resourceKeys)
+
+	privateFields := cfg.PrivateFields(resourceKeys)
+
+	doc := &Document{
+		OpenAPI: version,
+		Info:    Info{Title: title, Version: "1.0.0"},
+		Paths:   make(map[string]PathItem),
+		Components: Components{Schemas: map[string]interface{}{
+			errorSchema: map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"error": map[string]interface{}{"type": "string"}},
+			},
+		}},
+	}
+
+	for _, resourceKey := range resourceKeys {
+		resourceConfig := cfg.Resources[resourceKey]
+		if resourceConfig.Hidden {
+			continue
+		}
+
+		t := schema.Infer(data[resourceKey])
+		for _, field := range privateFields[resourceKey] {
+			delete(t.Fields, field)
+		}
+
+		name := codegen.TypeName(resourceKey)
+		doc.Components.Schemas[name] = schema.OpenAPISchema(t)
+
+		addRoutes(doc, resourceKey, name, !resourceConfig.WriteOnly, !resourceConfig.ReadOnly && !readOnly)
+	}
+
+	return doc
+}
+
+// addRoutes adds the read and write routes of the resource, whose records have the schema of the name.
+func addRoutes(doc *Document, resourceKey, name string, read, write bool) {
+	collection := PathItem{}
+	item := PathItem{}
+
+	tags := []string{resourceKey}
+	record := ref(name)
+	idParam := Parameter{Name: "id", In: "path", Required: true, Schema: map[string]interface{}{"type": "string"}}
+
+	if read {
+		collection["get"] = &Operation{
+			Tags:        tags,
+			Summary:     "List " + resourceKey,
+			OperationID: "list" + codegen.PluralName(resourceKey),
+			Parameters:  listParams,
+			Responses: map[string]Response{
+				"200": {
+					Description: "The resources matching the query",
+					Headers: map[string]Header{"X-Total-Count": {
+						Description: "Number of resources matching the query, if paginated",
+						Schema:      map[string]interface{}{"type": "integer"},
+					}},
+					Content: jsonContent(map[string]interface{}{"type": "array", "items": record}),
+				},
+				"400": errorResponse("Invalid query parameter"),
+			},
+		}
+
+		item["get"] = &Operation{
+			Tags:        tags,
+			Summary:     "Get " + resourceKey + " by id",
+			OperationID: "get" + name,
+			Parameters:  append([]Parameter{idParam}, relatedParams...),
+			Responses: map[string]Response{
+				"200": {Description: "The resource", Content: jsonContent(record)},
+				"404": errorResponse("Resource not found"),
+			},
+		}
+	}
+
+	if write {
+		body := &RequestBody{Required: true, Content: jsonContent(record)}
+
+		collection["post"] = &Operation{
+			Tags:        tags,
+			Summary:     "Create " + resourceKey,
+			OperationID: "create" + name,
+			RequestBody: body,
+			Responses: map[string]Response{
+				"201": {Description: "The created resource", Content: jsonContent(record)},
+				"400": errorResponse("Invalid body"),
+				"409": errorResponse("Resource already exists"),
+			},
+		}
+
+		for _, method := range []string{http.MethodPut, http.MethodPatch} {
+			verb := "Replace"
+			if method == http.MethodPatch {
+				verb = "Update"
+			}
+
+			item[strings.ToLower(method)] = &Operation{
+				Tags:        tags,
+				Summary:     verb + " " + resourceKey,
+				OperationID: strings.ToLower(verb) + name,
+				Parameters:  []Parameter{idParam},
+				RequestBody: body,
+				Responses: map[string]Response{
+					"200": {Description: fmt.Sprintf("The %sd resource", strings.ToLower(verb)), Content: jsonContent(record)},
+					"400": errorResponse("Invalid body"),
+					"404": errorResponse("Resource not found"),
+				},
+			}
+		}
+
+		item["delete"] = &Operation{
+			Tags:        tags,
+			Summary:     "Delete " + resourceKey,
+			OperationID: "delete" + name,
+			Parameters:  []Parameter{idParam},
+			Responses: map[string]Response{
+				"200": {Description: "The resource is deleted"},
+				"404": errorResponse("Resource not found"),
+			},
+		}
+	}
+
+	if len(collection) > 0 {
+		doc.Paths["/"+resourceKey] = collection
+	}
+
+	if len(item) > 0 {
+		doc.Paths["/"+resourceKey+"/{id}"] = item
+	}
+}
+
+func queryParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: map[string]interface{}{"type": "string"}}
+}
+
+func ref(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonContent(s map[string]interface{}) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+func errorResponse(description string) Response {
+	return Response{Description: description, Content: jsonContent(ref(errorSchema))}
+}
@@ -0,0 +1,107 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/openapi"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestNew(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{
+		"private": ["password"],
+		"resources": {
+			"logs": {"readOnly": true},
+			"secrets": {"hidden": true}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := storage.Database{
+		"logs":    []storage.Resource{{"id": "1", "level": "info"}},
+		"secrets": []storage.Resource{{"id": "1"}},
+		"users":   []storage.Resource{{"id": float64(7), "name": "Ada", "password": "secret", "nickname": nil}},
+	}
+
+	doc := openapi.New("db", data, cfg, false)
+
+	testCases := []struct {
+		name    string
+		path    string
+		methods []string
+	}{
+		{name: "Read-only collection", path: "/logs", methods: []string{"get"}},
+		{name: "Read-only resource", path: "/logs/{id}", methods: []string{"get"}},
+		{name: "Collection", path: "/users", methods: []string{"get", "post"}},
+		{name: "Resource", path: "/users/{id}", methods: []string{"delete", "get", "patch", "put"}},
+	}
+
+	if len(doc.Paths) != len(testCases) {
+		t.Fatalf("expected %v paths, but got %v", len(testCases), len(doc.Paths))
+	}
+
+	for _, tt := range testCases {
+		item, ok := doc.Paths[tt.path]
+		if !ok {
+			t.Fatalf("%s: expected path %s", tt.name, tt.path)
+		}
+
+		if len(item) != len(tt.methods) {
+			t.Fatalf("%s: expected methods %v, but got %v", tt.name, tt.methods, item)
+		}
+
+		for _, method := range tt.methods {
+			if _, ok := item[method]; !ok {
+				t.Fatalf("%s: expected method %s", tt.name, method)
+			}
+		}
+	}
+
+	user, ok := doc.Components.Schemas["User"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected the schema of users, but got %v", doc.Components.Schemas)
+	}
+
+	properties := user["properties"].(map[string]interface{})
+	if _, ok := properties["password"]; ok {
+		t.Fatal("expected the private field to be left out")
+	}
+
+	if nickname := properties["nickname"].(map[string]interface{}); nickname["nullable"] != true {
+		t.Fatalf("expected a nullable nickname, but got %v", nickname)
+	}
+
+	if doc = openapi.New("db", data, cfg, true); len(doc.Paths["/users"]) != 1 {
+		t.Fatalf("expected the read route only when read only, but got %v", doc.Paths["/users"])
+	}
+}
+
+func TestDocument_YAML(t *testing.T) {
+	doc := openapi.New("db", storage.Database{"posts": {{"id": "1", "tags": []interface{}{"a"}}}}, &config.Config{}, false)
+
+	content, err := doc.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"openapi: \"3.0.3\"\n",
+		"  \"/posts/{id}\":\n",
+		"        - in: \"path\"\n          name: \"id\"\n          required: true\n",
+		"\"$ref\": \"#/components/schemas/Post\"\n",
+	} {
+		if !strings.Contains(string(content), expected) {
+			t.Fatalf("expected yaml holding %q, but got %s", expected, content)
+		}
+	}
+
+	// Documents are written as json as well.
+	if content, err = doc.JSON(); err != nil || !json.Valid(content) {
+		t.Fatalf("expected a valid json document, but got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// plainKey matches the keys written without quotes in yaml.
+var plainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// JSON returns the document as indented json.
+func (d *Document) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// YAML returns the document as yaml. Strings are double quoted, as in json, so they never need escaping rules
+// of their own.
+func (d *Document) YAML() ([]byte, error) {
+	content, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var val interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	if err = decoder.Decode(&val); err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	if err = writeYAML(&b, val, 0); err != nil {
+		return nil, err
+	}
+
+	return []byte(b.String()), nil
+}
+
+// writeYAML writes the decoded json value as a yaml block at the indentation. Empty objects and arrays, and
+// scalars, are written as json, which is valid yaml.
+func writeYAML(b *strings.Builder, val interface{}, indent int) error {
+	pad := strings.Repeat(" ", indent)
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			name := key
+			if !plainKey.MatchString(key) {
+				quoted, err := scalar(key)
+				if err != nil {
+					return err
+				}
+
+				name = quoted
+			}
+
+			b.WriteString(pad + name + ":")
+
+			if isBlock(v[key]) {
+				b.WriteString("\n")
+
+				if err := writeYAML(b, v[key], indent+2); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			s, err := scalar(v[key])
+			if err != nil {
+				return err
+			}
+
+			b.WriteString(" " + s + "\n")
+		}
+	case []interface{}:
+		for _, item := range v {
+			if !isBlock(item) {
+				s, err := scalar(item)
+				if err != nil {
+					return err
+				}
+
+				b.WriteString(pad + "- " + s + "\n")
+				continue
+			}
+
+			// Write the item as a block indented past the dash, then put the dash on its first line.
+			var itemBuilder strings.Builder
+			if err := writeYAML(&itemBuilder, item, indent+2); err != nil {
+				return err
+			}
+
+			b.WriteString(pad + "- " + strings.TrimPrefix(itemBuilder.String(), pad+"  "))
+		}
+	default:
+		s, err := scalar(v)
+		if err != nil {
+			return err
+		}
+
+		b.WriteString(pad + s + "\n")
+	}
+
+	return nil
+}
+
+// isBlock reports whether the value is written as a yaml block, i.e. a non-empty object or array.
+func isBlock(val interface{}) bool {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return len(v) > 0
+	case []interface{}:
+		return len(v) > 0
+	}
+
+	return false
+}
+
+// scalar returns the value as json, without escaping html characters.
+func scalar(val interface{}) (string, error) {
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(val); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -0,0 +1,196 @@
+// Package postman describes the routes of the served resources as a Postman collection, which Postman and
+// Insomnia import, so the mocked api can be clicked through right away.
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// schemaURL is the schema of the generated collections, version 2.1.
+const schemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// baseURLVariable is the collection variable holding the url of the server, so it can be changed in one place.
+const baseURLVariable = "baseUrl"
+
+// Collection is a Postman collection, holding a folder of requests per resource.
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Variable []Variable `json:"variable"`
+}
+
+// Info describes the collection.
+type Info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// Item is either a folder holding items, or a request.
+type Item struct {
+	Name    string   `json:"name"`
+	Item    []Item   `json:"item,omitempty"`
+	Request *Request `json:"request,omitempty"`
+}
+
+// Request is a request of the collection.
+type Request struct {
+	Method string   `json:"method"`
+	Header []Header `json:"header"`
+	URL    URL      `json:"url"`
+	Body   *Body    `json:"body,omitempty"`
+}
+
+// Header is a request header.
+type Header struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// URL is the url of a request, relative to the base url variable. Path segments starting with ':' are path
+// variables, e.g. ':id'.
+type URL struct {
+	Raw      string     `json:"raw"`
+	Host     []string   `json:"host"`
+	Path     []string   `json:"path"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+// Body is the raw json body of a request.
+type Body struct {
+	Mode    string      `json:"mode"`
+	Raw     string      `json:"raw"`
+	Options BodyOptions `json:"options"`
+}
+
+// BodyOptions describe the language of a raw body.
+type BodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// Variable is a collection or path variable.
+type Variable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// New returns the collection of the routes of the resources, as served with the configuration. Hidden resources
+// are left out, and so are the read routes of write-only resources and the write routes of read-only ones. The
+// bodies of create, replace and update requests are the first record of the resource, without its id, private
+// and computed fields.
+func New(name, baseURL string, data storage.Database, cfg *config.Config) *Collection {
+	resourceKeys := make([]string, 0, len(data))
+	for resourceKey := range data {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	sort.Strings(resourceKeys)
+
+	privateFields := cfg.PrivateFields(resourceKeys)
+
+	collection := &Collection{
+		Info:     Info{Name: name, Schema: schemaURL},
+		Item:     []Item{newRequest("Get database", http.MethodGet, "db", "", nil)},
+		Variable: []Variable{{Key: baseURLVariable, Value: baseURL}},
+	}
+
+	for _, resourceKey := range resourceKeys {
+		resourceConfig := cfg.Resources[resourceKey]
+		if resourceConfig.Hidden {
+			continue
+		}
+
+		example := exampleBody(data[resourceKey], privateFields[resourceKey], resourceConfig.Computed)
+
+		id := "1"
+		if records := data[resourceKey]; len(records) > 0 && records[0]["id"] != nil {
+			id = fmt.Sprint(records[0]["id"])
+		}
+
+		folder := Item{Name: resourceKey, Item: make([]Item, 0)}
+
+		if !resourceConfig.WriteOnly {
+			folder.Item = append(folder.Item,
+				newRequest("List "+resourceKey, http.MethodGet, resourceKey, "", nil),
+				newRequest("Get "+resourceKey+" by id", http.MethodGet, resourceKey+"/:id", id, nil),
+			)
+		}
+
+		if !resourceConfig.ReadOnly {
+			folder.Item = append(folder.Item,
+				newRequest("Create "+resourceKey, http.MethodPost, resourceKey, "", example),
+				newRequest("Replace "+resourceKey, http.MethodPut, resourceKey+"/:id", id, example),
+				newRequest("Update "+resourceKey, http.MethodPatch, resourceKey+"/:id", id, example),
+				newRequest("Delete "+resourceKey, http.MethodDelete, resourceKey+"/:id", id, nil),
+			)
+		}
+
+		collection.Item = append(collection.Item, folder)
+	}
+
+	return collection
+}
+
+// newRequest returns the request item of the route, with the example id as value of the ':id' path variable, and
+// a json body unless nil.
+func newRequest(name, method, path, id string, body storage.Resource) Item {
+	request := &Request{
+		Method: method,
+		Header: make([]Header, 0),
+		URL: URL{
+			Raw:  fmt.Sprintf("{{%s}}/%s", baseURLVariable, path),
+			Host: []string{fmt.Sprintf("{{%s}}", baseURLVariable)},
+			Path: strings.Split(path, "/"),
+		},
+	}
+
+	for _, segment := range request.URL.Path {
+		if segment == ":id" {
+			request.URL.Variable = append(request.URL.Variable, Variable{Key: "id", Value: id})
+		}
+	}
+
+	if body != nil {
+		// Marshaling a decoded json object cannot fail.
+		raw, _ := json.MarshalIndent(body, "", "  ")
+
+		request.Header = append(request.Header, Header{Key: "Content-Type", Value: "application/json"})
+		request.Body = &Body{Mode: "raw", Raw: string(raw)}
+		request.Body.Options.Raw.Language = "json"
+	}
+
+	return Item{Name: name, Request: request}
+}
+
+// exampleBody returns the first record without its id, private and computed fields, or an empty object if the
+// resource has no records.
+func exampleBody(records []storage.Resource, private []string, computed map[string]string) storage.Resource {
+	example := make(storage.Resource)
+	if len(records) == 0 {
+		return example
+	}
+
+	for key, val := range records[0] {
+		example[key] = val
+	}
+
+	delete(example, "id")
+
+	for _, field := range private {
+		delete(example, field)
+	}
+
+	for field := range computed {
+		delete(example, field)
+	}
+
+	return example
+}
@@ -0,0 +1,93 @@
+package postman_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/postman"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestNew(t *testing.T) {
+	cfg, err := config.Parse([]byte(`{
+		"private": ["password"],
+		"resources": {
+			"logs": {"readOnly": true},
+			"secrets": {"hidden": true},
+			"users": {"computed": {"name": "first + ' ' + last"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := storage.Database{
+		"logs":    []storage.Resource{},
+		"secrets": []storage.Resource{{"id": "1"}},
+		"users":   []storage.Resource{{"id": float64(7), "first": "Ada", "last": "Lovelace", "password": "secret"}},
+	}
+
+	collection := postman.New("db", "http://localhost:3000", data, cfg)
+
+	if len(collection.Item) != 3 || collection.Item[1].Name != "logs" || collection.Item[2].Name != "users" {
+		t.Fatalf("expected the database request and the logs and users folders, but got %+v", collection.Item)
+	}
+
+	if logs := collection.Item[1].Item; len(logs) != 2 {
+		t.Fatalf("expected %v read requests of read-only logs, but got %v", 2, len(logs))
+	}
+
+	users := collection.Item[2].Item
+	if len(users) != 6 {
+		t.Fatalf("expected %v requests of users, but got %v", 6, len(users))
+	}
+
+	testCases := []struct {
+		name   string
+		item   postman.Item
+		method string
+		raw    string
+		id     string
+		body   map[string]interface{}
+	}{
+		{name: "List users", item: users[0], method: "GET", raw: "{{baseUrl}}/users"},
+		{name: "Get user by id", item: users[1], method: "GET", raw: "{{baseUrl}}/users/:id", id: "7"},
+		{name: "Create user", item: users[2], method: "POST", raw: "{{baseUrl}}/users", body: map[string]interface{}{"first": "Ada", "last": "Lovelace"}},
+		{name: "Update user", item: users[4], method: "PATCH", raw: "{{baseUrl}}/users/:id", id: "7", body: map[string]interface{}{"first": "Ada", "last": "Lovelace"}},
+		{name: "Delete user", item: users[5], method: "DELETE", raw: "{{baseUrl}}/users/:id", id: "7"},
+	}
+
+	for _, tt := range testCases {
+		request := tt.item.Request
+
+		if request.Method != tt.method || request.URL.Raw != tt.raw {
+			t.Fatalf("%s: expected %s %s, but got %s %s", tt.name, tt.method, tt.raw, request.Method, request.URL.Raw)
+		}
+
+		if tt.id != "" && (len(request.URL.Variable) != 1 || request.URL.Variable[0].Value != tt.id) {
+			t.Fatalf("%s: expected id variable %v, but got %+v", tt.name, tt.id, request.URL.Variable)
+		}
+
+		if tt.body == nil {
+			if request.Body != nil {
+				t.Fatalf("%s: expected no body, but got %v", tt.name, request.Body.Raw)
+			}
+
+			continue
+		}
+
+		var body map[string]interface{}
+		if err = json.Unmarshal([]byte(request.Body.Raw), &body); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(body) != len(tt.body) || body["first"] != tt.body["first"] || body["last"] != tt.body["last"] {
+			t.Fatalf("%s: expected body %v, but got %v", tt.name, tt.body, body)
+		}
+	}
+
+	if len(collection.Variable) != 1 || collection.Variable[0].Value != "http://localhost:3000" {
+		t.Fatalf("expected the base url variable, but got %+v", collection.Variable)
+	}
+}
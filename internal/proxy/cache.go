@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Cache stores upstream responses by request uri. Responses are kept in memory, and in a directory if
+// provided, so they outlive restarts of the server.
+type Cache struct {
+	dir       string
+	mu        sync.RWMutex
+	responses map[string]*Response
+}
+
+// NewCache returns a new cache of upstream responses. When the directory is not empty, responses are
+// persisted in it, and the ones already there are served as well.
+func NewCache(dir string) (*Cache, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Cache{dir: dir, responses: make(map[string]*Response)}, nil
+}
+
+// Get returns the cached response of the request uri.
+func (c *Cache) Get(key string) (*Response, bool) {
+	c.mu.RLock()
+	resp, ok := c.responses[key]
+	c.mu.RUnlock()
+
+	if ok || c.dir == "" {
+		return resp, ok
+	}
+
+	content, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	resp = new(Response)
+	if err = json.Unmarshal(content, resp); err != nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	c.responses[key] = resp
+	c.mu.Unlock()
+
+	return resp, true
+}
+
+// Set caches the response of the request uri.
+func (c *Cache) Set(key string, resp *Response) error {
+	c.mu.Lock()
+	c.responses[key] = resp
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.path(key), content, 0644)
+}
+
+// path returns the file of the request uri, named after its hash as uris are no valid file names.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
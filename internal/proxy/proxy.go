@@ -0,0 +1,165 @@
+// Package proxy forwards requests to an upstream API, and optionally caches its GET responses, to keep
+// serving them while the upstream is offline.
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// cacheHeader is the response header telling whether a response was served from the cache.
+const cacheHeader = "X-Cache"
+
+// errUpstreamUnavailable returns an error when the upstream fails to respond.
+var errUpstreamUnavailable = errors.New("upstream unavailable")
+
+// Proxy forwards requests to the upstream API.
+type Proxy struct {
+	reverse *httputil.ReverseProxy
+	cache   *Cache
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// Option configures the proxy.
+type Option func(*Proxy)
+
+// WithCache caches successful GET responses for the ttl, and serves expired ones while the upstream fails.
+func WithCache(cache *Cache, ttl time.Duration) Option {
+	return func(p *Proxy) {
+		p.cache = cache
+		p.ttl = ttl
+	}
+}
+
+// WithClock sets the clock deciding whether cached responses expired.
+func WithClock(now func() time.Time) Option {
+	return func(p *Proxy) {
+		p.now = now
+	}
+}
+
+// New returns a new proxy to the upstream url.
+func New(upstream *url.URL, opts ...Option) *Proxy {
+	p := &Proxy{now: time.Now}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.reverse = httputil.NewSingleHostReverseProxy(upstream)
+
+	// Send the host of the upstream, as virtual hosts expect it.
+	director := p.reverse.Director
+	p.reverse.Director = func(r *http.Request) {
+		director(r)
+		r.Host = upstream.Host
+	}
+
+	p.reverse.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		web.Error(w, http.StatusBadGateway, errUpstreamUnavailable.Error())
+	}
+
+	return p
+}
+
+// ServeHTTP forwards the request to the upstream. GET responses are served from the cache while fresh, and
+// expired ones are served when the upstream fails.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if p.cache == nil || r.Method != http.MethodGet {
+		p.reverse.ServeHTTP(w, r)
+		return
+	}
+
+	key := r.URL.RequestURI()
+
+	cached, ok := p.cache.Get(key)
+	if ok && p.now().Sub(cached.Stored) < p.ttl {
+		cached.write(w, "HIT")
+		return
+	}
+
+	rec := newRecorder()
+	p.reverse.ServeHTTP(rec, r)
+
+	// Serve the expired response while offline, or while the upstream is failing.
+	if rec.statusCode >= http.StatusInternalServerError && ok {
+		cached.write(w, "STALE")
+		return
+	}
+
+	resp := &Response{StatusCode: rec.statusCode, Header: rec.header, Body: rec.body.Bytes(), Stored: p.now()}
+	if cacheable(resp) {
+		// Failing to persist the response should not fail the request.
+		// nolint
+		p.cache.Set(key, resp)
+	}
+
+	resp.write(w, "MISS")
+}
+
+// cacheable reports whether the response can be cached, which is the case for successful responses the
+// upstream does not forbid to store.
+func cacheable(resp *Response) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+
+	for _, directive := range strings.Split(resp.Header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if directive == "no-store" || directive == "private" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// recorder captures the upstream response.
+type recorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header), statusCode: http.StatusOK}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// Response is a cached upstream response.
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Stored     time.Time   `json:"stored"`
+}
+
+func (resp *Response) write(w http.ResponseWriter, cacheStatus string) {
+	for key, values := range resp.Header {
+		w.Header()[key] = values
+	}
+
+	w.Header().Set(cacheHeader, cacheStatus)
+	w.WriteHeader(resp.StatusCode)
+
+	// nolint
+	w.Write(resp.Body)
+}
@@ -0,0 +1,170 @@
+package proxy_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/proxy"
+)
+
+func TestProxy_Cache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "proxy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	requests := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		if r.URL.Path == "/private" {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+
+		fmt.Fprintf(w, `{"request": %d}`, requests)
+	}))
+
+	upstreamURL, err := url.Parse(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	cache, err := proxy.NewCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(proxy.New(upstreamURL, proxy.WithCache(cache, time.Minute), proxy.WithClock(clock)))
+	defer server.Close()
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		advance    time.Duration
+		offline    bool
+		statusCode int
+		cache      string
+		body       string
+	}{
+		{
+			name:       "Forward uncached response",
+			method:     http.MethodGet,
+			path:       "/posts",
+			statusCode: http.StatusOK,
+			cache:      "MISS",
+			body:       `{"request": 1}`,
+		},
+		{
+			name:       "Serve cached response",
+			method:     http.MethodGet,
+			path:       "/posts",
+			advance:    30 * time.Second,
+			statusCode: http.StatusOK,
+			cache:      "HIT",
+			body:       `{"request": 1}`,
+		},
+		{
+			name:       "Forward expired response",
+			method:     http.MethodGet,
+			path:       "/posts",
+			advance:    time.Minute,
+			statusCode: http.StatusOK,
+			cache:      "MISS",
+			body:       `{"request": 2}`,
+		},
+		{
+			name:       "Forward non GET request",
+			method:     http.MethodPost,
+			path:       "/posts",
+			statusCode: http.StatusOK,
+			body:       `{"request": 3}`,
+		},
+		{
+			name:       "Forward response not to store",
+			method:     http.MethodGet,
+			path:       "/private",
+			statusCode: http.StatusOK,
+			cache:      "MISS",
+			body:       `{"request": 4}`,
+		},
+		{
+			name:       "Serve expired response while offline",
+			method:     http.MethodGet,
+			path:       "/posts",
+			advance:    time.Hour,
+			offline:    true,
+			statusCode: http.StatusOK,
+			cache:      "STALE",
+			body:       `{"request": 2}`,
+		},
+		{
+			name:       "Fail uncached request while offline",
+			method:     http.MethodGet,
+			path:       "/private",
+			offline:    true,
+			statusCode: http.StatusBadGateway,
+			cache:      "MISS",
+		},
+	}
+
+	for _, tt := range testCases {
+		now = now.Add(tt.advance)
+
+		if tt.offline {
+			upstream.Close()
+		}
+
+		req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if header := resp.Header.Get("X-Cache"); header != tt.cache {
+			t.Fatalf("%s: expected header X-Cache %v, but got %v", tt.name, tt.cache, header)
+		}
+
+		if tt.body != "" && string(body) != tt.body {
+			t.Fatalf("%s: expected body %v, but got %v", tt.name, tt.body, string(body))
+		}
+	}
+
+	// Responses persisted in the directory outlive the cache.
+	persisted, err := proxy.NewCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp, ok := persisted.Get("/posts"); !ok || string(resp.Body) != `{"request": 2}` {
+		t.Fatalf("expected persisted response, but got %v", resp)
+	}
+
+	if _, ok := persisted.Get("/private"); ok {
+		t.Fatal("expected response not to store to not be persisted")
+	}
+}
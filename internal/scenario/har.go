@@ -0,0 +1,53 @@
+package scenario
+
+import (
+	"github.com/chanioxaris/json-server/internal/capture"
+	"github.com/chanioxaris/json-server/internal/har"
+)
+
+// LoadHAR reads the requests of a HAR file as a script, expecting their captured responses. Only the requests
+// of the json api are kept, i.e. the ones sending or receiving json, leaving out pages, scripts and images.
+// Failed requests, which got no response, are left out as well. The prefix is stripped from the request paths,
+// e.g. '/api' for requests to '/api/posts' served by '/posts'.
+func LoadHAR(file, stripPrefix string) (*Script, error) {
+	entries, err := har.Read(file)
+	if err != nil {
+		return nil, err
+	}
+
+	script := &Script{Steps: make([]Step, 0)}
+
+	for _, entry := range entries {
+		if entry.Status == 0 || !entry.JSON() {
+			continue
+		}
+
+		step := Step{
+			Method: entry.Method,
+			Path:   entry.Path(stripPrefix),
+			Expect: Expect{Status: entry.Status, Body: capture.DecodeBody(entry.Body)},
+		}
+
+		if entry.URL.RawQuery != "" {
+			step.Path += "?" + entry.URL.RawQuery
+		}
+
+		for _, key := range recordedHeaders {
+			if val := entry.Header.Get(key); val != "" {
+				if step.Headers == nil {
+					step.Headers = make(map[string]string)
+				}
+
+				step.Headers[key] = val
+			}
+		}
+
+		if entry.RequestBody != nil {
+			step.Body = capture.DecodeBody(entry.RequestBody)
+		}
+
+		script.Steps = append(script.Steps, step)
+	}
+
+	return script, nil
+}
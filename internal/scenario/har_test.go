@@ -0,0 +1,89 @@
+package scenario_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/scenario"
+)
+
+const testHAR = `{
+	"log": {
+		"entries": [
+			{
+				"request": {"method": "GET", "url": "https://example.com/app.js", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "text/javascript", "text": "console.log(1)"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/posts/1", "headers": [{"name": "accept", "value": "application/json"}, {"name": "cookie", "value": "session=1"}]},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "eyJpZCI6IjEiLCJ0aXRsZSI6Impzb24tc2VydmVyIn0=", "encoding": "base64"}}
+			},
+			{
+				"request": {"method": "POST", "url": "https://example.com/api/posts?notify=true", "headers": [], "postData": {"mimeType": "application/json", "text": "{\"title\": \"har\"}"}},
+				"response": {"status": 201, "content": {"mimeType": "application/json", "text": "{\"id\": \"2\", \"title\": \"har\"}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/comments", "headers": []},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "[]"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://example.com/api/blocked", "headers": []},
+				"response": {"status": 0, "content": {"mimeType": "application/json"}}
+			}
+		]
+	}
+}`
+
+func TestLoadHAR(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "traffic.har")
+	if err = ioutil.WriteFile(file, []byte(testHAR), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script, err := scenario.LoadHAR(file, "/api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedPaths := []string{"/posts/1", "/posts?notify=true", "/comments"}
+	if len(script.Steps) != len(expectedPaths) {
+		t.Fatalf("expected %v steps, but got %+v", len(expectedPaths), script.Steps)
+	}
+
+	for idx, path := range expectedPaths {
+		if script.Steps[idx].Path != path {
+			t.Fatalf("expected path %v of step %d, but got %v", path, idx+1, script.Steps[idx].Path)
+		}
+	}
+
+	if headers := script.Steps[0].Headers; len(headers) != 1 || headers["Accept"] != "application/json" {
+		t.Fatalf("expected the accept header only, but got %v", headers)
+	}
+
+	server := newServer(t)
+	defer server.Close()
+
+	report, err := scenario.Replay(context.Background(), scenario.Config{Target: server.URL}, script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The comments are missing from the data.
+	if len(report.Failures) != 1 || report.Failures[0].Path != "/comments" {
+		t.Fatalf("expected a failure of the missing comments, but got %+v", report.Failures)
+	}
+
+	if diffs := report.Failures[0].Diffs; len(diffs) == 0 || diffs[0] != "status: expected 200, but got 404" {
+		t.Fatalf("expected status code %v of the missing comments, but got %v", http.StatusNotFound, diffs)
+	}
+}
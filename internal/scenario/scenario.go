@@ -0,0 +1,226 @@
+// Package scenario records the requests of a manual session against a server into a script, and replays
+// scripts, or the traffic of HAR files, against a running server, asserting their responses, as smoke tests.
+package scenario
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+)
+
+var (
+	// ErrInvalidScript returns an error when a script file cannot be parsed.
+	ErrInvalidScript = errors.New("invalid script")
+	// ErrServerUnreachable returns an error when the target server does not respond.
+	ErrServerUnreachable = errors.New("target server unreachable")
+)
+
+// recordedHeaders are the request headers kept in scripts, as they change the responses of the server.
+var recordedHeaders = []string{"Accept", "Authorization", "Content-Type", "Prefer"}
+
+// Script is an ordered list of requests, with the responses they are expected to get.
+type Script struct {
+	Steps []Step `json:"steps"`
+}
+
+// Step is a request of a script and its expected response.
+type Step struct {
+	Method string `json:"method"`
+	// Path holds the query, e.g. '/posts?_sort=title'.
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is sent as json, unless it is a string, which is sent as is.
+	Body   interface{} `json:"body,omitempty"`
+	Expect Expect      `json:"expect"`
+}
+
+// Expect describes the expected response of a step.
+type Expect struct {
+	// Status is ignored when 0.
+	Status int `json:"status,omitempty"`
+	// Body holds the json body the response must have. Objects may omit fields of the response body, e.g.
+	// timestamps which change on every run.
+	Body interface{} `json:"body,omitempty"`
+}
+
+// Load reads the script of the file.
+func Load(file string) (*Script, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	script := &Script{}
+	if err = json.Unmarshal(content, script); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScript, err)
+	}
+
+	for idx, step := range script.Steps {
+		if step.Method == "" || !strings.HasPrefix(step.Path, "/") {
+			return nil, fmt.Errorf("%w: step %d: expected a method and a path starting with '/'", ErrInvalidScript, idx+1)
+		}
+	}
+
+	return script, nil
+}
+
+// Recorder adds the captured requests as steps to the script of a file, which is written after every step, so
+// it is complete whenever the server stops. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	file   string
+	script Script
+}
+
+// NewRecorder returns a new recorder, writing the script to the file.
+func NewRecorder(file string) *Recorder {
+	return &Recorder{file: file, script: Script{Steps: make([]Step, 0)}}
+}
+
+// Add records the captured request as a step, expecting its captured response. Failures to write the script
+// are logged, as they should not fail the request.
+func (rec *Recorder) Add(entry capture.Entry) {
+	step := Step{
+		Method: entry.Method,
+		Path:   entry.Path,
+		Body:   entry.Body,
+		Expect: Expect{Status: entry.Response.Status, Body: entry.Response.Body},
+	}
+
+	if len(entry.Query) > 0 {
+		step.Path += "?" + entry.Query.Encode()
+	}
+
+	for _, key := range recordedHeaders {
+		if val := entry.Header.Get(key); val != "" {
+			if step.Headers == nil {
+				step.Headers = make(map[string]string)
+			}
+
+			step.Headers[key] = val
+		}
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	rec.script.Steps = append(rec.script.Steps, step)
+
+	content, err := json.MarshalIndent(rec.script, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(rec.file, content, 0644)
+	}
+
+	if err != nil {
+		logrus.WithError(err).Warnf("failed to write recorded script %s", rec.file)
+	}
+}
+
+// Config holds the settings of a replay.
+type Config struct {
+	Target string
+	Client *http.Client
+}
+
+// Failure describes a step whose response differs from the expected one.
+type Failure struct {
+	// Step is the position of the step in the script, starting at 1.
+	Step   int
+	Method string
+	Path   string
+	Diffs  []string
+}
+
+// Report contains the outcome of a replay.
+type Report struct {
+	Steps    int
+	Failures []Failure
+}
+
+// Replay sends the requests of the script in order against the configured target, and reports the steps
+// whose responses differ from the expected ones. It stops if the target does not respond.
+func Replay(ctx context.Context, cfg Config, script *Script) (*Report, error) {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: time.Second * 30}
+	}
+
+	target := strings.TrimSuffix(cfg.Target, "/")
+	report := &Report{Failures: make([]Failure, 0)}
+
+	for idx, step := range script.Steps {
+		diffs, err := replayStep(ctx, cfg.Client, target, step)
+		if err != nil {
+			return report, fmt.Errorf("%w: step %d: %v", ErrServerUnreachable, idx+1, err)
+		}
+
+		report.Steps++
+
+		if len(diffs) > 0 {
+			report.Failures = append(report.Failures, Failure{Step: idx + 1, Method: step.Method, Path: step.Path, Diffs: diffs})
+		}
+	}
+
+	return report, nil
+}
+
+// replayStep sends the request of the step, and returns the differences of its response from the expected one.
+func replayStep(ctx context.Context, client *http.Client, target string, step Step) ([]string, error) {
+	var body []byte
+	switch val := step.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(val)
+	default:
+		var err error
+		if body, err = json.Marshal(val); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, step.Method, target+step.Path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, val := range step.Headers {
+		req.Header.Set(key, val)
+	}
+
+	if body != nil && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := make([]string, 0)
+
+	if step.Expect.Status != 0 && resp.StatusCode != step.Expect.Status {
+		diffs = append(diffs, fmt.Sprintf("status: expected %d, but got %d", step.Expect.Status, resp.StatusCode))
+	}
+
+	if step.Expect.Body != nil {
+		diffs = append(diffs, capture.Diff("body", step.Expect.Body, capture.DecodeBody(respBody))...)
+	}
+
+	return diffs, nil
+}
@@ -0,0 +1,145 @@
+package scenario_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/scenario"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func newServer(t *testing.T, opts ...handler.Option) *httptest.Server {
+	t.Helper()
+
+	db := storage.NewMemoryDB(storage.Database{
+		"posts": []storage.Resource{{"id": "1", "title": "json-server"}},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}, opts...))
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "session.json")
+
+	recorded := newServer(t, handler.WithRecorder(scenario.NewRecorder(file)))
+
+	requests := []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodGet, path: "/posts?title=json-server"},
+		{method: http.MethodPost, path: "/posts", body: `{"id": "2", "title": "replay"}`},
+		{method: http.MethodGet, path: "/posts/2"},
+		{method: http.MethodGet, path: "/__requests"},
+	}
+
+	for _, r := range requests {
+		req, err := http.NewRequest(r.method, recorded.URL+r.path, strings.NewReader(r.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	recorded.Close()
+
+	script, err := scenario.Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(script.Steps) != 3 {
+		t.Fatalf("expected %v recorded steps, but got %v", 3, len(script.Steps))
+	}
+
+	if step := script.Steps[1]; step.Method != http.MethodPost || step.Expect.Status != http.StatusCreated || step.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("expected recorded create with status code %v, but got %+v", http.StatusCreated, step)
+	}
+
+	testCases := []struct {
+		name     string
+		data     storage.Resource
+		failures int
+	}{
+		{name: "Replay against the same data", data: storage.Resource{"id": "1", "title": "json-server"}},
+		{name: "Replay against changed data", data: storage.Resource{"id": "1", "title": "changed"}, failures: 1},
+	}
+
+	for _, tt := range testCases {
+		db := storage.NewMemoryDB(storage.Database{"posts": []storage.Resource{tt.data}})
+
+		storageSvc, err := storage.NewMemory(db, "posts")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		server := httptest.NewServer(handler.Setup(map[string]storage.Storage{"posts": storageSvc}))
+
+		report, err := scenario.Replay(context.Background(), scenario.Config{Target: server.URL}, script)
+		server.Close()
+
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if report.Steps != 3 {
+			t.Fatalf("%s: expected %v replayed steps, but got %v", tt.name, 3, report.Steps)
+		}
+
+		if len(report.Failures) != tt.failures {
+			t.Fatalf("%s: expected %v failures, but got %+v", tt.name, tt.failures, report.Failures)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenario")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "Load invalid json", content: `{"steps": [`},
+		{name: "Load step without method", content: `{"steps": [{"path": "/posts"}]}`},
+		{name: "Load step with relative path", content: `{"steps": [{"method": "GET", "path": "posts"}]}`},
+	}
+
+	for _, tt := range testCases {
+		file := filepath.Join(dir, "script.json")
+		if err := ioutil.WriteFile(file, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := scenario.Load(file); err == nil {
+			t.Fatalf("%s: expected error, but got none", tt.name)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package schema
+
+// draft is the JSON Schema version of the generated schemas.
+const draft = "http://json-schema.org/draft-07/schema#"
+
+// JSONSchema returns the JSON Schema of the records of a resource, with the types of their fields, the required
+// fields, i.e. the ones no record misses, and the observed values of enums.
+func JSONSchema(t *Type) map[string]interface{} {
+	s := jsonSchema(t)
+	s["$schema"] = draft
+
+	return s
+}
+
+// jsonSchema returns the JSON Schema of the observed values.
+func jsonSchema(t *Type) map[string]interface{} {
+	s := make(map[string]interface{})
+	if t == nil || len(t.Kinds) == 0 {
+		return s
+	}
+
+	if len(t.Kinds) == 1 {
+		s["type"] = t.Kinds[0]
+	} else {
+		s["type"] = t.Kinds
+	}
+
+	if enum := t.Enum(); enum != nil {
+		values := make([]interface{}, 0, len(enum)+1)
+		for _, val := range enum {
+			values = append(values, val)
+		}
+
+		if t.Nullable() {
+			values = append(values, nil)
+		}
+
+		s["enum"] = values
+	}
+
+	if t.Has(KindObject) {
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+
+		for _, name := range t.FieldNames() {
+			field := t.Fields[name]
+
+			properties[name] = jsonSchema(field.Type)
+			if !field.Optional {
+				required = append(required, name)
+			}
+		}
+
+		s["properties"] = properties
+		s["required"] = required
+	}
+
+	if t.Has(KindArray) && t.Items != nil {
+		s["items"] = jsonSchema(t.Items)
+	}
+
+	return s
+}
+
+// OpenAPISchema returns the OpenAPI 3.0 schema of the records of a resource, which differs from the JSON Schema by
+// allowing a single type, with 'nullable' for null values, and 'oneOf' for values of several types.
+func OpenAPISchema(t *Type) map[string]interface{} {
+	s := make(map[string]interface{})
+	if t == nil || len(t.Kinds) == 0 {
+		return s
+	}
+
+	kinds := t.NonNull()
+
+	switch len(kinds) {
+	case 0:
+	case 1:
+		s = openAPIKindSchema(t, kinds[0])
+	default:
+		oneOf := make([]interface{}, 0, len(kinds))
+		for _, kind := range kinds {
+			oneOf = append(oneOf, openAPIKindSchema(t, kind))
+		}
+
+		s["oneOf"] = oneOf
+	}
+
+	if t.Has(KindNull) {
+		s["nullable"] = true
+	}
+
+	return s
+}
+
+// openAPIKindSchema returns the OpenAPI 3.0 schema of the observed values of the kind.
+func openAPIKindSchema(t *Type, kind string) map[string]interface{} {
+	s := map[string]interface{}{"type": kind}
+
+	switch kind {
+	case KindString:
+		if enum := t.Enum(); enum != nil {
+			s["enum"] = enum
+		}
+	case KindObject:
+		properties := make(map[string]interface{})
+		required := make([]string, 0)
+
+		for _, name := range t.FieldNames() {
+			field := t.Fields[name]
+
+			properties[name] = OpenAPISchema(field.Type)
+			if !field.Optional {
+				required = append(required, name)
+			}
+		}
+
+		s["properties"] = properties
+		if len(required) > 0 {
+			s["required"] = required
+		}
+	case KindArray:
+		// Arrays require the schema of their items, any value if all observed arrays are empty.
+		s["items"] = OpenAPISchema(t.Items)
+	}
+
+	return s
+}
@@ -0,0 +1,248 @@
+// Package schema infers the shape of the records of resources, i.e. the types of their fields, which fields are
+// optional and which are nullable, to generate typed clients and schemas.
+package schema
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// Kinds of json values.
+const (
+	KindString  = "string"
+	KindInteger = "integer"
+	KindNumber  = "number"
+	KindBoolean = "boolean"
+	KindNull    = "null"
+	KindObject  = "object"
+	KindArray   = "array"
+)
+
+// Type is the union of the json values observed at a position of the records.
+type Type struct {
+	// Kinds holds the observed kinds, sorted. Whole numbers are integers, unless other numbers are observed too.
+	Kinds []string
+	// Fields holds the fields of the observed objects.
+	Fields map[string]*Field
+	// Items holds the type of the items of the observed arrays, nil if all of them are empty.
+	Items *Type
+
+	// objects is the number of observed objects, to tell which fields are optional.
+	objects int
+	// strings counts the observed strings by value, up to maxEnumValues distinct values, to tell enums.
+	strings map[string]int
+	// manyStrings is set once more than maxEnumValues distinct strings are observed.
+	manyStrings bool
+}
+
+// maxEnumValues is the largest number of distinct strings of an enum.
+const maxEnumValues = 10
+
+// Field is a field of the observed objects.
+type Field struct {
+	Type *Type
+	// Optional is set if the field is missing from some of the observed objects.
+	Optional bool
+
+	// seen is the number of observed objects holding the field.
+	seen int
+}
+
+// Infer returns the object type of the records.
+func Infer(records []storage.Resource) *Type {
+	t := &Type{}
+	for _, record := range records {
+		t.observe(map[string]interface{}(record))
+	}
+
+	if len(records) == 0 {
+		t.addKind(KindObject)
+		t.Fields = make(map[string]*Field)
+	}
+
+	return t
+}
+
+// Has reports whether the kind is observed.
+func (t *Type) Has(kind string) bool {
+	for _, k := range t.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Nullable reports whether null is observed, along other kinds.
+func (t *Type) Nullable() bool {
+	return t.Has(KindNull) && len(t.Kinds) > 1
+}
+
+// NonNull returns the observed kinds except null.
+func (t *Type) NonNull() []string {
+	kinds := make([]string, 0, len(t.Kinds))
+	for _, k := range t.Kinds {
+		if k != KindNull {
+			kinds = append(kinds, k)
+		}
+	}
+
+	return kinds
+}
+
+// FieldNames returns the names of the fields, sorted.
+func (t *Type) FieldNames() []string {
+	names := make([]string, 0, len(t.Fields))
+	for name := range t.Fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// observe adds the value to the type.
+func (t *Type) observe(val interface{}) {
+	switch v := val.(type) {
+	case nil:
+		t.addKind(KindNull)
+	case string:
+		t.addKind(KindString)
+		t.observeString(v)
+	case bool:
+		t.addKind(KindBoolean)
+	case float64:
+		if v == math.Trunc(v) && !math.IsInf(v, 0) {
+			t.addKind(KindInteger)
+		} else {
+			t.addKind(KindNumber)
+		}
+	case json.Number:
+		// Numbers a float64 cannot hold exactly.
+		if _, err := v.Int64(); err == nil {
+			t.addKind(KindInteger)
+		} else {
+			t.addKind(KindNumber)
+		}
+	case []interface{}:
+		t.addKind(KindArray)
+
+		for _, item := range v {
+			if t.Items == nil {
+				t.Items = &Type{}
+			}
+
+			t.Items.observe(item)
+		}
+	case map[string]interface{}:
+		t.addKind(KindObject)
+
+		if t.Fields == nil {
+			t.Fields = make(map[string]*Field)
+		}
+
+		t.objects++
+
+		for name, fieldVal := range v {
+			field, ok := t.Fields[name]
+			if !ok {
+				field = &Field{Type: &Type{}}
+				t.Fields[name] = field
+			}
+
+			field.seen++
+			field.Type.observe(fieldVal)
+		}
+
+		for _, field := range t.Fields {
+			field.Optional = field.seen < t.objects
+		}
+	default:
+		// Values decoded from json hold no other types.
+		t.addKind(KindString)
+	}
+}
+
+// observeString counts the string by value, until there are too many distinct ones for an enum.
+func (t *Type) observeString(val string) {
+	if t.manyStrings {
+		return
+	}
+
+	if t.strings == nil {
+		t.strings = make(map[string]int)
+	}
+
+	if _, ok := t.strings[val]; !ok && len(t.strings) == maxEnumValues {
+		t.manyStrings = true
+		t.strings = nil
+
+		return
+	}
+
+	t.strings[val]++
+}
+
+// OmitEnum drops the observed strings, so the type is never an enum, e.g. for fields whose values are secret.
+func (t *Type) OmitEnum() {
+	t.strings = nil
+	t.manyStrings = true
+}
+
+// Enum returns the observed strings, sorted, if the values are strings only (or null) and few distinct strings
+// repeat, e.g. the 'draft' and 'published' of a status field. Otherwise it returns nil.
+func (t *Type) Enum() []string {
+	kinds := t.NonNull()
+	if len(kinds) != 1 || kinds[0] != KindString || t.manyStrings {
+		return nil
+	}
+
+	total := 0
+	for _, count := range t.strings {
+		total += count
+	}
+
+	// Every value should be observed twice on average, so unique values like names are no enums.
+	if total < 2*len(t.strings) {
+		return nil
+	}
+
+	values := make([]string, 0, len(t.strings))
+	for val := range t.strings {
+		values = append(values, val)
+	}
+
+	sort.Strings(values)
+
+	return values
+}
+
+// addKind adds the kind, keeping the kinds sorted. Integers are numbers once other numbers are observed.
+func (t *Type) addKind(kind string) {
+	if kind == KindInteger && t.Has(KindNumber) {
+		return
+	}
+
+	if kind == KindNumber && t.Has(KindInteger) {
+		kinds := make([]string, 0, len(t.Kinds))
+		for _, k := range t.Kinds {
+			if k != KindInteger {
+				kinds = append(kinds, k)
+			}
+		}
+
+		t.Kinds = kinds
+	}
+
+	if t.Has(kind) {
+		return
+	}
+
+	t.Kinds = append(t.Kinds, kind)
+	sort.Strings(t.Kinds)
+}
@@ -0,0 +1,110 @@
+package schema_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/schema"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestInfer(t *testing.T) {
+	records := []storage.Resource{
+		{"id": "1", "title": "json-server", "views": float64(3), "rating": float64(4), "author": map[string]interface{}{"name": "alice"}},
+		{"id": "2", "title": nil, "views": float64(5), "rating": 4.5, "tags": []interface{}{"go", float64(1)}},
+	}
+
+	typ := schema.Infer(records)
+
+	testCases := []struct {
+		name     string
+		field    string
+		kinds    []string
+		optional bool
+		nullable bool
+	}{
+		{name: "Infer string", field: "id", kinds: []string{schema.KindString}},
+		{name: "Infer nullable string", field: "title", kinds: []string{schema.KindNull, schema.KindString}, nullable: true},
+		{name: "Infer integer", field: "views", kinds: []string{schema.KindInteger}},
+		{name: "Infer number of integers and decimals", field: "rating", kinds: []string{schema.KindNumber}},
+		{name: "Infer optional object", field: "author", kinds: []string{schema.KindObject}, optional: true},
+		{name: "Infer optional array", field: "tags", kinds: []string{schema.KindArray}, optional: true},
+	}
+
+	for _, tt := range testCases {
+		field, ok := typ.Fields[tt.field]
+		if !ok {
+			t.Fatalf("%s: expected field %v, but got none", tt.name, tt.field)
+		}
+
+		if !reflect.DeepEqual(field.Type.Kinds, tt.kinds) {
+			t.Fatalf("%s: expected kinds %v, but got %v", tt.name, tt.kinds, field.Type.Kinds)
+		}
+
+		if field.Optional != tt.optional {
+			t.Fatalf("%s: expected optional %v, but got %v", tt.name, tt.optional, field.Optional)
+		}
+
+		if field.Type.Nullable() != tt.nullable {
+			t.Fatalf("%s: expected nullable %v, but got %v", tt.name, tt.nullable, field.Type.Nullable())
+		}
+	}
+
+	if items := typ.Fields["tags"].Type.Items; !reflect.DeepEqual(items.Kinds, []string{schema.KindInteger, schema.KindString}) {
+		t.Fatalf("expected tags of integers and strings, but got %v", items.Kinds)
+	}
+
+	if author := typ.Fields["author"].Type; author.Fields["name"] == nil || author.Fields["name"].Optional {
+		t.Fatalf("expected required name of author, but got %+v", author.Fields)
+	}
+}
+
+func TestEnum(t *testing.T) {
+	testCases := []struct {
+		name     string
+		values   []interface{}
+		expected []string
+	}{
+		{name: "Repeated strings", values: []interface{}{"draft", "published", "draft", "published"}, expected: []string{"draft", "published"}},
+		{name: "Repeated strings and null", values: []interface{}{"draft", nil, "draft", "published", "published"}, expected: []string{"draft", "published"}},
+		{name: "Unique strings", values: []interface{}{"alice", "bob", "carol"}},
+		{name: "Strings and numbers", values: []interface{}{"1", float64(1), "1", float64(1)}},
+		{name: "Too many distinct strings", values: []interface{}{"a", "a", "b", "b", "c", "c", "d", "d", "e", "e", "f", "f", "g", "g", "h", "h", "i", "i", "j", "j", "k", "k"}},
+	}
+
+	for _, tt := range testCases {
+		records := make([]storage.Resource, 0, len(tt.values))
+		for _, val := range tt.values {
+			records = append(records, storage.Resource{"status": val})
+		}
+
+		if enum := schema.Infer(records).Fields["status"].Type.Enum(); !reflect.DeepEqual(enum, tt.expected) {
+			t.Fatalf("%s: expected enum %v, but got %v", tt.name, tt.expected, enum)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	records := []storage.Resource{
+		{"id": "1", "status": "draft", "views": float64(3), "tags": []interface{}{"go"}},
+		{"id": "2", "status": "draft", "views": nil},
+		{"id": "3", "status": "published", "views": float64(5)},
+		{"id": "4", "status": "published", "views": float64(8)},
+	}
+
+	expected := map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"id":     map[string]interface{}{"type": "string"},
+			"status": map[string]interface{}{"type": "string", "enum": []interface{}{"draft", "published"}},
+			"views":  map[string]interface{}{"type": []string{"integer", "null"}},
+			"tags":   map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+		"required": []string{"id", "status", "views"},
+	}
+
+	if got := schema.JSONSchema(schema.Infer(records)); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected schema %v, but got %v", expected, got)
+	}
+}
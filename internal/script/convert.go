@@ -0,0 +1,133 @@
+package script
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// maxDepth is the deepest nesting of tables converted from Lua, which also stops at cyclic tables.
+const maxDepth = 100
+
+// toLua converts a json value to a Lua value. Objects and arrays become tables.
+func toLua(L *lua.LState, val interface{}) lua.LValue {
+	switch val := val.(type) {
+	case nil:
+		return lua.LNil
+	case bool:
+		return lua.LBool(val)
+	case string:
+		return lua.LString(val)
+	case float64:
+		return lua.LNumber(val)
+	case int:
+		return lua.LNumber(val)
+	case int64:
+		return lua.LNumber(val)
+	case json.Number:
+		f, err := val.Float64()
+		if err != nil {
+			return lua.LString(val)
+		}
+
+		return lua.LNumber(f)
+	case storage.Resource:
+		return toLua(L, map[string]interface{}(val))
+	case map[string]interface{}:
+		table := L.NewTable()
+		for key, fieldVal := range val {
+			table.RawSetString(key, toLua(L, fieldVal))
+		}
+
+		return table
+	case []storage.Resource:
+		table := L.NewTable()
+		for _, resource := range val {
+			table.Append(toLua(L, resource))
+		}
+
+		return table
+	case []interface{}:
+		table := L.NewTable()
+		for _, elem := range val {
+			table.Append(toLua(L, elem))
+		}
+
+		return table
+	default:
+		return lua.LString(fmt.Sprint(val))
+	}
+}
+
+// fromLua converts a Lua value to a json value. Tables with consecutive integer keys starting at 1 become
+// arrays, and all other tables objects.
+func fromLua(val lua.LValue) (interface{}, error) {
+	return fromLuaDepth(val, 0)
+}
+
+func fromLuaDepth(val lua.LValue, depth int) (interface{}, error) {
+	switch val := val.(type) {
+	case *lua.LNilType:
+		return nil, nil
+	case lua.LBool:
+		return bool(val), nil
+	case lua.LString:
+		return string(val), nil
+	case lua.LNumber:
+		if math.IsNaN(float64(val)) || math.IsInf(float64(val), 0) {
+			return nil, fmt.Errorf("unsupported number %v", val)
+		}
+
+		return float64(val), nil
+	case *lua.LTable:
+		if depth >= maxDepth {
+			return nil, fmt.Errorf("tables nested deeper than %d", maxDepth)
+		}
+
+		return fromTable(val, depth+1)
+	default:
+		return nil, fmt.Errorf("unsupported value of type %s", val.Type())
+	}
+}
+
+func fromTable(table *lua.LTable, depth int) (interface{}, error) {
+	count := 0
+	table.ForEach(func(_, _ lua.LValue) {
+		count++
+	})
+
+	if n := table.MaxN(); n > 0 && n == count {
+		arr := make([]interface{}, 0, n)
+		for idx := 1; idx <= n; idx++ {
+			elem, err := fromLuaDepth(table.RawGetInt(idx), depth)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, elem)
+		}
+
+		return arr, nil
+	}
+
+	obj := make(map[string]interface{}, count)
+
+	var err error
+	table.ForEach(func(key, fieldVal lua.LValue) {
+		if err != nil {
+			return
+		}
+
+		obj[key.String()], err = fromLuaDepth(fieldVal, depth)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,136 @@
+package script
+
+import (
+	"fmt"
+
+	lua "github.com/yuin/gopher-lua"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// dbTable returns the functions scripts access the resources with. Like Lua's own functions, they return
+// nil and an error message on failure, e.g. 'local post, err = db.get("posts", request.params.id)'.
+func dbTable(L *lua.LState, resourceStorage map[string]storage.Storage) *lua.LTable {
+	// storageArg returns the storage of the resource named by the first argument.
+	storageArg := func(L *lua.LState) (storage.Storage, bool) {
+		resourceKey := L.CheckString(1)
+
+		storageSvc, ok := resourceStorage[resourceKey]
+		if !ok {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(fmt.Sprintf("unknown resource: %s", resourceKey)))
+		}
+
+		return storageSvc, ok
+	}
+
+	// respond pushes the result of a storage operation.
+	respond := func(L *lua.LState, val interface{}, err error) int {
+		if err != nil {
+			L.Push(lua.LNil)
+			L.Push(lua.LString(err.Error()))
+			return 2
+		}
+
+		L.Push(toLua(L, val))
+		return 1
+	}
+
+	funcs := map[string]lua.LGFunction{
+		"find": func(L *lua.LState) int {
+			storageSvc, ok := storageArg(L)
+			if !ok {
+				return 2
+			}
+
+			data, err := storageSvc.Find()
+			return respond(L, data, err)
+		},
+		"get": func(L *lua.LState) int {
+			storageSvc, ok := storageArg(L)
+			if !ok {
+				return 2
+			}
+
+			resource, err := storageSvc.FindById(L.CheckString(2))
+			return respond(L, resource, err)
+		},
+		"create": func(L *lua.LState) int {
+			storageSvc, ok := storageArg(L)
+			if !ok {
+				return 2
+			}
+
+			resource, err := resourceArg(L, 2)
+			if err == nil {
+				resource, err = storageSvc.Create(resource)
+			}
+
+			return respond(L, resource, err)
+		},
+		"replace": func(L *lua.LState) int {
+			storageSvc, ok := storageArg(L)
+			if !ok {
+				return 2
+			}
+
+			id := L.CheckString(2)
+
+			resource, err := resourceArg(L, 3)
+			if err == nil {
+				resource, err = storageSvc.Replace(id, resource)
+			}
+
+			return respond(L, resource, err)
+		},
+		"update": func(L *lua.LState) int {
+			storageSvc, ok := storageArg(L)
+			if !ok {
+				return 2
+			}
+
+			id := L.CheckString(2)
+
+			resource, err := resourceArg(L, 3)
+			if err == nil {
+				resource, err = storageSvc.Update(id, resource)
+			}
+
+			return respond(L, resource, err)
+		},
+		"delete": func(L *lua.LState) int {
+			storageSvc, ok := storageArg(L)
+			if !ok {
+				return 2
+			}
+
+			if err := storageSvc.Delete(L.CheckString(2)); err != nil {
+				return respond(L, nil, err)
+			}
+
+			return respond(L, true, nil)
+		},
+	}
+
+	table := L.NewTable()
+	for name, fn := range funcs {
+		table.RawSetString(name, L.NewFunction(fn))
+	}
+
+	return table
+}
+
+// resourceArg converts the table argument at the position to a resource.
+func resourceArg(L *lua.LState, n int) (storage.Resource, error) {
+	val, err := fromLua(L.CheckTable(n))
+	if err != nil {
+		return nil, err
+	}
+
+	fields, ok := val.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: expected an object", storage.ErrBadRequest)
+	}
+
+	return storage.Resource(fields), nil
+}
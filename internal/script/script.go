@@ -0,0 +1,188 @@
+// Package script runs Lua scripts handling requests. Scripts inspect the request through the global
+// 'request' table, read and write resources through the global 'db' table, and return the response as
+// a table like '{status = 201, headers = {...}, body = {...}}'.
+package script
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// timeout is the longest a script may run.
+const timeout = 5 * time.Second
+
+var (
+	// ErrFailedLoadScript returns an error when a script cannot be read or compiled.
+	ErrFailedLoadScript = errors.New("failed to load script")
+	// ErrFailedRunScript returns an error when a script fails while running, or returns an invalid response.
+	ErrFailedRunScript = errors.New("failed to run script")
+)
+
+// Script is a compiled Lua script. It is safe for concurrent use, as every run gets its own Lua state.
+type Script struct {
+	name  string
+	proto *lua.FunctionProto
+}
+
+// Request describes the request to the script.
+type Request struct {
+	Method string
+	Path   string
+	// Params holds the route parameters, e.g. 'id' of '/orders/:id'.
+	Params map[string]string
+	Query  map[string]string
+	Header map[string]string
+	// Body holds the decoded json body, the raw body if it is no json, or nil if empty.
+	Body interface{}
+}
+
+// Response describes the response returned by the script.
+type Response struct {
+	Status int
+	Header map[string]string
+	// Body holds the value to respond as json, or a string to respond as is. Nil responds no body.
+	Body interface{}
+}
+
+// Load reads and compiles the script file.
+func Load(filename string) (*Script, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedLoadScript, err)
+	}
+
+	return Compile(filename, string(content))
+}
+
+// Compile the script source. The name is used in error messages.
+func Compile(name, src string) (*Script, error) {
+	chunk, err := parse.Parse(strings.NewReader(src), name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedLoadScript, err)
+	}
+
+	proto, err := lua.Compile(chunk, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedLoadScript, err)
+	}
+
+	return &Script{name: name, proto: proto}, nil
+}
+
+// Run the script for the request, with access to the provided resources. Scripts returning nothing respond
+// with 204.
+func (s *Script) Run(ctx context.Context, req Request, resourceStorage map[string]storage.Storage) (*Response, error) {
+	L := newState()
+	defer L.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	L.SetContext(ctx)
+
+	L.SetGlobal("request", requestTable(L, req))
+	L.SetGlobal("db", dbTable(L, resourceStorage))
+
+	L.Push(L.NewFunctionFromProto(s.proto))
+	if err := L.PCall(0, 1, nil); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFailedRunScript, err)
+	}
+
+	ret := L.Get(-1)
+	if ret == lua.LNil {
+		return &Response{Status: 204}, nil
+	}
+
+	table, ok := ret.(*lua.LTable)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s: expected a response table, but got %s", ErrFailedRunScript, s.name, ret.Type())
+	}
+
+	return responseFromTable(s.name, table)
+}
+
+// newState returns a Lua state with the libraries which cannot reach the file system or the process.
+func newState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	libs := []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{name: lua.BaseLibName, open: lua.OpenBase},
+		{name: lua.TabLibName, open: lua.OpenTable},
+		{name: lua.StringLibName, open: lua.OpenString},
+		{name: lua.MathLibName, open: lua.OpenMath},
+	}
+
+	for _, lib := range libs {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+
+	for _, name := range []string{"dofile", "loadfile", "load", "loadstring", "require", "module"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	return L
+}
+
+func requestTable(L *lua.LState, req Request) *lua.LTable {
+	table := L.NewTable()
+	table.RawSetString("method", lua.LString(req.Method))
+	table.RawSetString("path", lua.LString(req.Path))
+	table.RawSetString("params", stringsTable(L, req.Params))
+	table.RawSetString("query", stringsTable(L, req.Query))
+	table.RawSetString("header", stringsTable(L, req.Header))
+	table.RawSetString("body", toLua(L, req.Body))
+
+	return table
+}
+
+func stringsTable(L *lua.LState, values map[string]string) *lua.LTable {
+	table := L.NewTable()
+	for key, val := range values {
+		table.RawSetString(key, lua.LString(val))
+	}
+
+	return table
+}
+
+func responseFromTable(name string, table *lua.LTable) (*Response, error) {
+	resp := &Response{Status: 200, Header: make(map[string]string)}
+
+	switch status := table.RawGetString("status").(type) {
+	case *lua.LNilType:
+	case lua.LNumber:
+		resp.Status = int(status)
+		if float64(resp.Status) != float64(status) || resp.Status < 100 || resp.Status > 599 {
+			return nil, fmt.Errorf("%w: %s: invalid status %v", ErrFailedRunScript, name, status)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s: invalid status %v", ErrFailedRunScript, name, status)
+	}
+
+	if headers, ok := table.RawGetString("headers").(*lua.LTable); ok {
+		headers.ForEach(func(key, val lua.LValue) {
+			resp.Header[key.String()] = val.String()
+		})
+	}
+
+	body, err := fromLua(table.RawGetString("body"))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrFailedRunScript, name, err)
+	}
+
+	resp.Body = body
+
+	return resp, nil
+}
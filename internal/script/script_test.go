@@ -0,0 +1,124 @@
+package script_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/script"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name     string
+		src      string
+		req      script.Request
+		expected *script.Response
+		err      error
+	}{
+		{
+			name: "Respond request fields",
+			src:  `return {status = 202, headers = {["X-Id"] = request.params.id}, body = {method = request.method, q = request.query.q, name = request.body.name}}`,
+			req: script.Request{
+				Method: "POST",
+				Params: map[string]string{"id": "1"},
+				Query:  map[string]string{"q": "search"},
+				Body:   map[string]interface{}{"name": "John"},
+			},
+			expected: &script.Response{
+				Status: 202,
+				Header: map[string]string{"X-Id": "1"},
+				Body:   map[string]interface{}{"method": "POST", "q": "search", "name": "John"},
+			},
+		},
+		{
+			name: "Read and write resources",
+			src: `
+local order, err = db.get("orders", "1")
+if err then
+  return {status = 404, body = {error = err}}
+end
+
+order.status = "cancelled"
+return {body = db.replace("orders", order.id, order)}`,
+			expected: &script.Response{
+				Status: 200,
+				Header: map[string]string{},
+				Body:   map[string]interface{}{"id": "1", "status": "cancelled", "items": []interface{}{"a", "b"}},
+			},
+		},
+		{
+			name: "Report storage errors",
+			src: `
+local _, err = db.get("orders", "2")
+local _, unknownErr = db.find("unknown")
+return {body = {err, unknownErr}}`,
+			expected: &script.Response{
+				Status: 200,
+				Header: map[string]string{},
+				Body:   []interface{}{storage.ErrResourceNotFound.Error(), "unknown resource: unknown"},
+			},
+		},
+		{
+			name:     "Respond no content",
+			src:      `local x = 1`,
+			expected: &script.Response{Status: 204},
+		},
+		{
+			name: "Fail on runtime error",
+			src:  `error("boom")`,
+			err:  script.ErrFailedRunScript,
+		},
+		{
+			name: "Fail on invalid status",
+			src:  `return {status = 1000}`,
+			err:  script.ErrFailedRunScript,
+		},
+		{
+			name: "Fail on unsupported body",
+			src:  `return {body = {callback = print}}`,
+			err:  script.ErrFailedRunScript,
+		},
+		{
+			name: "Deny file system access",
+			src:  `return {body = io.open("/etc/passwd")}`,
+			err:  script.ErrFailedRunScript,
+		},
+		{
+			name: "Deny loading files",
+			src:  `dofile("/etc/passwd")`,
+			err:  script.ErrFailedRunScript,
+		},
+	}
+
+	for _, tt := range testCases {
+		orders, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+			"orders": {{"id": "1", "status": "open", "items": []interface{}{"a", "b"}}},
+		}), "orders")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := script.Compile(tt.name, tt.src)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		resp, err := s.Run(context.Background(), tt.req, map[string]storage.Storage{"orders": orders})
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if !reflect.DeepEqual(resp, tt.expected) {
+			t.Fatalf("%s: expected response %v, but got %v", tt.name, tt.expected, resp)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if _, err := script.Compile("invalid", `return {`); !errors.Is(err, script.ErrFailedLoadScript) {
+		t.Fatalf("expected error %v, but got %v", script.ErrFailedLoadScript, err)
+	}
+}
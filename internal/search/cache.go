@@ -0,0 +1,49 @@
+package search
+
+import (
+	"sync"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// Cache holds the index of a resource, and rebuilds it once invalidated. It is safe for concurrent use.
+type Cache struct {
+	mu    sync.Mutex
+	index *Index
+}
+
+// NewCache returns a new empty cache.
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+// Index returns the cached index, or builds it from the resources when the cache is invalidated, or the
+// number of resources changed since it was built.
+func (c *Cache) Index(resources []storage.Resource) *Index {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index == nil || c.index.Len() != countIndexable(resources) {
+		c.index = NewIndex(resources)
+	}
+
+	return c.index
+}
+
+// Invalidate the cached index, as the resources changed.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.index = nil
+	c.mu.Unlock()
+}
+
+func countIndexable(resources []storage.Resource) int {
+	count := 0
+	for _, resource := range resources {
+		if resource["id"] != nil {
+			count++
+		}
+	}
+
+	return count
+}
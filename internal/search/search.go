@@ -0,0 +1,403 @@
+// Package search provides full-text search over resources, using an inverted index. Query terms match
+// exactly, by prefix, or fuzzily within a few typos, and results are ranked by relevance.
+package search
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/chanioxaris/json-server/internal/collate"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+const (
+	// Weights of the ways a query term matches an indexed term.
+	exactWeight  = 1.0
+	prefixWeight = 0.7
+	fuzzyWeight  = 0.5
+	// maxExpansions limits the indexed terms matched by a single query term.
+	maxExpansions = 50
+	// BM25 ranking parameters.
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Highlight tags wrapping the matched words of highlighted fields.
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// Hit is a resource matching a search query.
+type Hit struct {
+	ID    string
+	Score float64
+	// Highlights holds the matched fields, by their path (e.g. 'author.name'), with the matched words
+	// wrapped in highlight tags.
+	Highlights map[string]string
+}
+
+// field is a text value of a resource.
+type field struct {
+	path string
+	text string
+}
+
+type document struct {
+	id     string
+	fields []field
+	length int
+}
+
+// posting describes the occurrences of a term in a document.
+type posting struct {
+	doc    int
+	tf     int
+	fields []int
+}
+
+// Index is an inverted index over resources. It is immutable once built, and safe for concurrent use.
+type Index struct {
+	docs     []document
+	postings map[string][]posting
+	// terms holds all indexed terms, sorted for prefix lookups.
+	terms     []string
+	avgLength float64
+}
+
+// NewIndex builds an index of the text, number and boolean values of the resources, including nested ones.
+// Resources without id are not indexed.
+func NewIndex(resources []storage.Resource) *Index {
+	idx := &Index{postings: make(map[string][]posting)}
+
+	totalLength := 0
+	for _, resource := range resources {
+		id, ok := resource["id"]
+		if !ok || id == nil {
+			continue
+		}
+
+		doc := document{id: fmt.Sprint(id)}
+		collectFields(map[string]interface{}(resource), "", &doc.fields)
+
+		docIdx := len(idx.docs)
+		counts := make(map[string]*posting)
+		for fieldIdx, f := range doc.fields {
+			for _, t := range tokenize(f.text) {
+				doc.length++
+
+				p, ok := counts[t.term]
+				if !ok {
+					p = &posting{doc: docIdx}
+					counts[t.term] = p
+				}
+
+				p.tf++
+				if len(p.fields) == 0 || p.fields[len(p.fields)-1] != fieldIdx {
+					p.fields = append(p.fields, fieldIdx)
+				}
+			}
+		}
+
+		for term, p := range counts {
+			idx.postings[term] = append(idx.postings[term], *p)
+		}
+
+		totalLength += doc.length
+		idx.docs = append(idx.docs, doc)
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+
+	sort.Strings(idx.terms)
+
+	if len(idx.docs) > 0 {
+		idx.avgLength = float64(totalLength) / float64(len(idx.docs))
+	}
+
+	return idx
+}
+
+// Len returns the number of indexed resources.
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search returns the resources matching all terms of the query, most relevant first.
+func (idx *Index) Search(query string) []Hit {
+	queryTerms := tokenize(collate.Normalize(query))
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	matchedTerms := make(map[int]map[string]bool)
+
+	for termIdx, qt := range queryTerms {
+		termScores := make(map[int]float64)
+
+		for term, weight := range idx.expand(qt.term) {
+			postings := idx.postings[term]
+			idf := math.Log(1 + (float64(len(idx.docs))-float64(len(postings))+0.5)/(float64(len(postings))+0.5))
+
+			for _, p := range postings {
+				// Documents must match every previous query term.
+				if _, ok := scores[p.doc]; !ok && termIdx > 0 {
+					continue
+				}
+
+				norm := 1 - b + b*float64(idx.docs[p.doc].length)/idx.avgLength
+				score := weight * idf * float64(p.tf) * (k1 + 1) / (float64(p.tf) + k1*norm)
+				if score > termScores[p.doc] {
+					termScores[p.doc] = score
+				}
+
+				if matchedTerms[p.doc] == nil {
+					matchedTerms[p.doc] = make(map[string]bool)
+				}
+
+				matchedTerms[p.doc][term] = true
+			}
+		}
+
+		next := make(map[int]float64, len(termScores))
+		for doc, score := range termScores {
+			next[doc] = scores[doc] + score
+		}
+
+		scores = next
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	docIdxs := make([]int, 0, len(scores))
+	for doc := range scores {
+		docIdxs = append(docIdxs, doc)
+	}
+
+	// Equally relevant resources keep their order.
+	sort.Slice(docIdxs, func(i, j int) bool {
+		if scores[docIdxs[i]] != scores[docIdxs[j]] {
+			return scores[docIdxs[i]] > scores[docIdxs[j]]
+		}
+
+		return docIdxs[i] < docIdxs[j]
+	})
+
+	for _, doc := range docIdxs {
+		hits = append(hits, Hit{
+			ID:         idx.docs[doc].id,
+			Score:      scores[doc],
+			Highlights: idx.highlight(doc, matchedTerms[doc]),
+		})
+	}
+
+	return hits
+}
+
+// expand returns the indexed terms matching the query term exactly, by prefix or fuzzily, with the weight
+// of the best way each matches.
+func (idx *Index) expand(queryTerm string) map[string]float64 {
+	expansions := make(map[string]float64)
+
+	if _, ok := idx.postings[queryTerm]; ok {
+		expansions[queryTerm] = exactWeight
+	}
+
+	// Prefix matches, for terms being typed.
+	if len([]rune(queryTerm)) >= 2 {
+		for pos := sort.SearchStrings(idx.terms, queryTerm); pos < len(idx.terms) && len(expansions) < maxExpansions; pos++ {
+			if !strings.HasPrefix(idx.terms[pos], queryTerm) {
+				break
+			}
+
+			if _, ok := expansions[idx.terms[pos]]; !ok {
+				expansions[idx.terms[pos]] = prefixWeight
+			}
+		}
+	}
+
+	// Fuzzy matches, for typos.
+	maxEdits := allowedEdits(queryTerm)
+	if maxEdits == 0 {
+		return expansions
+	}
+
+	query := []rune(queryTerm)
+	for _, term := range idx.terms {
+		if len(expansions) >= maxExpansions {
+			break
+		}
+
+		if _, ok := expansions[term]; ok {
+			continue
+		}
+
+		if editDistance(query, []rune(term), maxEdits) <= maxEdits {
+			expansions[term] = fuzzyWeight
+		}
+	}
+
+	return expansions
+}
+
+// highlight returns the fields of the document containing matched terms, with the matched words wrapped
+// in highlight tags.
+func (idx *Index) highlight(doc int, matched map[string]bool) map[string]string {
+	highlights := make(map[string]string)
+
+	for _, f := range idx.docs[doc].fields {
+		var sb strings.Builder
+
+		last := 0
+		for _, t := range tokenize(f.text) {
+			if !matched[t.term] {
+				continue
+			}
+
+			sb.WriteString(f.text[last:t.start])
+			sb.WriteString(HighlightStart)
+			sb.WriteString(f.text[t.start:t.end])
+			sb.WriteString(HighlightEnd)
+			last = t.end
+		}
+
+		if last > 0 {
+			sb.WriteString(f.text[last:])
+			highlights[f.path] = sb.String()
+		}
+	}
+
+	return highlights
+}
+
+// collectFields appends the text of every value of the object, with its dotted path, e.g. 'tags.0'.
+func collectFields(val interface{}, path string, fields *[]field) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			collectFields(v[key], joinPath(path, key), fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			collectFields(item, joinPath(path, fmt.Sprint(i)), fields)
+		}
+	case string:
+		*fields = append(*fields, field{path: path, text: collate.Normalize(v)})
+	case nil:
+	default:
+		*fields = append(*fields, field{path: path, text: fmt.Sprint(v)})
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// token is a word of a text, with its byte offsets.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits a text into lower case words of letters and digits.
+func tokenize(text string) []token {
+	tokens := make([]token, 0)
+
+	start := -1
+	for pos, c := range text {
+		isWord := unicode.IsLetter(c) || unicode.IsDigit(c)
+
+		if isWord && start < 0 {
+			start = pos
+		}
+
+		if !isWord && start >= 0 {
+			tokens = append(tokens, token{term: strings.ToLower(text[start:pos]), start: start, end: pos})
+			start = -1
+		}
+	}
+
+	if start >= 0 {
+		tokens = append(tokens, token{term: strings.ToLower(text[start:]), start: start, end: len(text)})
+	}
+
+	return tokens
+}
+
+// allowedEdits returns the number of typos tolerated for a term, based on its length.
+func allowedEdits(term string) int {
+	switch n := len([]rune(term)); {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance returns the Levenshtein distance of the terms, or a value above maxEdits once it is exceeded.
+func editDistance(a, b []rune, maxEdits int) int {
+	if diff := len(a) - len(b); diff > maxEdits || -diff > maxEdits {
+		return maxEdits + 1
+	}
+
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+
+		if rowMin > maxEdits {
+			return maxEdits + 1
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(vals ...int) int {
+	m := vals[0]
+	for _, val := range vals[1:] {
+		if val < m {
+			m = val
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,109 @@
+package search_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/search"
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func testResources() []storage.Resource {
+	return []storage.Resource{
+		{"id": "1", "title": "Clean Code", "author": map[string]interface{}{"name": "Robert Martin"}},
+		{"id": "2", "title": "Crime and Punishment", "author": map[string]interface{}{"name": "Fyodor Dostoevsky"}},
+		{"id": "3", "title": "The Clean Coder", "tags": []interface{}{"clean", "code", "career"}},
+		{"id": "4", "title": "Refactoring", "published": float64(1999)},
+		{"id": "5", "title": "Smörgåsbord"},
+		{"title": "Without id"},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := search.NewIndex(testResources())
+
+	testCases := []struct {
+		name        string
+		query       string
+		expectedIds []string
+	}{
+		{
+			name:        "Exact match, most relevant first",
+			query:       "clean",
+			expectedIds: []string{"3", "1"},
+		},
+		{
+			name:        "All terms must match",
+			query:       "clean martin",
+			expectedIds: []string{"1"},
+		},
+		{
+			name:        "Prefix match",
+			query:       "dostoev",
+			expectedIds: []string{"2"},
+		},
+		{
+			name:        "Fuzzy match",
+			query:       "refactorng",
+			expectedIds: []string{"4"},
+		},
+		{
+			name:        "Number match",
+			query:       "1999",
+			expectedIds: []string{"4"},
+		},
+		{
+			name:        "Decomposed accents match composed ones",
+			query:       "smörgåsbord",
+			expectedIds: []string{"5"},
+		},
+		{
+			name:        "No match",
+			query:       "without",
+			expectedIds: []string{},
+		},
+	}
+
+	for _, tt := range testCases {
+		ids := make([]string, 0)
+		for _, hit := range idx.Search(tt.query) {
+			ids = append(ids, hit.ID)
+		}
+
+		if !reflect.DeepEqual(ids, tt.expectedIds) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.expectedIds, ids)
+		}
+	}
+}
+
+func TestSearch_Highlights(t *testing.T) {
+	hits := search.NewIndex(testResources()).Search("martin")
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, but got %d", len(hits))
+	}
+
+	expected := map[string]string{"author.name": "Robert <mark>Martin</mark>"}
+	if !reflect.DeepEqual(hits[0].Highlights, expected) {
+		t.Fatalf("expected highlights %v, but got %v", expected, hits[0].Highlights)
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache := search.NewCache()
+	resources := testResources()
+
+	idx := cache.Index(resources)
+	if cache.Index(resources) != idx {
+		t.Fatal("expected cached index to be reused")
+	}
+
+	cache.Invalidate()
+	if cache.Index(resources) == idx {
+		t.Fatal("expected index to be rebuilt once invalidated")
+	}
+
+	idx = cache.Index(resources)
+	if cache.Index(resources[1:]) == idx {
+		t.Fatal("expected index to be rebuilt once the number of resources changed")
+	}
+}
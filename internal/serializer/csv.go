@@ -0,0 +1,71 @@
+package serializer
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// csvValueColumn is the column of the values which are not objects.
+const csvValueColumn = "value"
+
+// csvSerializer encodes responses as csv, with a row per object of arrays, or a single row for an object. The
+// header holds the fields of all objects, with the id first. Arrays and objects in fields are encoded as json.
+type csvSerializer struct{}
+
+func (csvSerializer) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (csvSerializer) Serialize(w io.Writer, resp Response) error {
+	rows, ok := resp.Data.([]interface{})
+	if !ok {
+		rows = []interface{}{resp.Data}
+	}
+
+	// The columns are the fields of all objects, ordered like the fields of a single object.
+	fields := make(map[string]interface{})
+	for _, row := range rows {
+		object, ok := row.(map[string]interface{})
+		if !ok {
+			fields[csvValueColumn] = nil
+			continue
+		}
+
+		for key := range object {
+			fields[key] = nil
+		}
+	}
+
+	// Empty arrays have no columns to write.
+	columns := sortedKeys(fields)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		object, isObject := row.(map[string]interface{})
+
+		for idx, column := range columns {
+			switch {
+			case isObject:
+				record[idx] = text(object[column])
+			case column == csvValueColumn:
+				record[idx] = text(row)
+			}
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,101 @@
+package serializer
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// jsonAPISerializer encodes responses as JSON:API documents. The records of resources are resource objects, with
+// the resource as type and their other fields as attributes, errors are error objects, and other responses, like
+// the ones of the admin routes, are meta information.
+type jsonAPISerializer struct{}
+
+type jsonAPIDocument struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []jsonAPIError `json:"errors,omitempty"`
+	Meta   interface{}    `json:"meta,omitempty"`
+}
+
+type jsonAPIResource struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type jsonAPIError struct {
+	Status string `json:"status"`
+	Title  string `json:"title"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func (jsonAPISerializer) ContentType() string {
+	return "application/vnd.api+json"
+}
+
+func (jsonAPISerializer) Serialize(w io.Writer, resp Response) error {
+	return json.NewEncoder(w).Encode(jsonAPIDocumentOf(resp))
+}
+
+// jsonAPIDocumentOf returns the document of the response.
+func jsonAPIDocumentOf(resp Response) jsonAPIDocument {
+	if resp.Status >= http.StatusBadRequest {
+		object, _ := resp.Data.(map[string]interface{})
+
+		return jsonAPIDocument{Errors: []jsonAPIError{{
+			Status: strconv.Itoa(resp.Status),
+			Title:  http.StatusText(resp.Status),
+			Detail: text(object["error"]),
+		}}}
+	}
+
+	if resp.Resource != "" {
+		switch v := resp.Data.(type) {
+		case map[string]interface{}:
+			if resource, ok := jsonAPIResourceOf(resp.Resource, v); ok {
+				return jsonAPIDocument{Data: resource}
+			}
+		case []interface{}:
+			resources := make([]jsonAPIResource, 0, len(v))
+			for _, item := range v {
+				object, _ := item.(map[string]interface{})
+
+				resource, ok := jsonAPIResourceOf(resp.Resource, object)
+				if !ok {
+					break
+				}
+
+				resources = append(resources, resource)
+			}
+
+			if len(resources) == len(v) {
+				return jsonAPIDocument{Data: resources}
+			}
+		}
+	}
+
+	// Meta information must be an object.
+	if object, ok := resp.Data.(map[string]interface{}); ok {
+		return jsonAPIDocument{Meta: object}
+	}
+
+	return jsonAPIDocument{Meta: map[string]interface{}{"value": resp.Data}}
+}
+
+// jsonAPIResourceOf returns the resource object of the record, if it has an id.
+func jsonAPIResourceOf(resourceKey string, record map[string]interface{}) (jsonAPIResource, bool) {
+	id, ok := record["id"]
+	if !ok || id == nil {
+		return jsonAPIResource{}, false
+	}
+
+	attributes := make(map[string]interface{}, len(record))
+	for key, val := range record {
+		if key != "id" {
+			attributes[key] = val
+		}
+	}
+
+	return jsonAPIResource{Type: resourceKey, ID: text(id), Attributes: attributes}, true
+}
@@ -0,0 +1,131 @@
+package serializer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"strconv"
+)
+
+// msgpackSerializer encodes responses as MessagePack. Integers are encoded in the smallest type holding them, and
+// other numbers as 64-bit floats.
+type msgpackSerializer struct{}
+
+func (msgpackSerializer) ContentType() string {
+	return "application/msgpack"
+}
+
+func (msgpackSerializer) Serialize(w io.Writer, resp Response) error {
+	bw := bufio.NewWriter(w)
+
+	if err := encodeMsgpack(bw, resp.Data); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+// encodeMsgpack encodes the decoded json value. Write errors are reported by the flush of the writer.
+func encodeMsgpack(w *bufio.Writer, val interface{}) error {
+	switch v := val.(type) {
+	case nil:
+		w.WriteByte(0xc0)
+	case bool:
+		if v {
+			w.WriteByte(0xc3)
+		} else {
+			w.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := strconv.ParseInt(v.String(), 10, 64); err == nil {
+			encodeMsgpackInt(w, i)
+		} else if u, err := strconv.ParseUint(v.String(), 10, 64); err == nil {
+			w.WriteByte(0xcf)
+			writeBigEndian(w, u, 8)
+		} else {
+			f, err := v.Float64()
+			if err != nil {
+				return err
+			}
+
+			w.WriteByte(0xcb)
+			writeBigEndian(w, math.Float64bits(f), 8)
+		}
+	case string:
+		encodeMsgpackHeader(w, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb)
+		w.WriteString(v)
+	case []interface{}:
+		encodeMsgpackHeader(w, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := encodeMsgpack(w, item); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		encodeMsgpackHeader(w, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, key := range sortedKeys(v) {
+			if err := encodeMsgpack(w, key); err != nil {
+				return err
+			}
+
+			if err := encodeMsgpack(w, v[key]); err != nil {
+				return err
+			}
+		}
+	default:
+		return encodeMsgpack(w, text(v))
+	}
+
+	return nil
+}
+
+// encodeMsgpackInt encodes the integer in the smallest type holding it.
+func encodeMsgpackInt(w *bufio.Writer, i int64) {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		w.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		w.WriteByte(byte(0xe0 | (i + 32)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		w.WriteByte(0xd0)
+		writeBigEndian(w, uint64(i), 1)
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		w.WriteByte(0xd1)
+		writeBigEndian(w, uint64(i), 2)
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		w.WriteByte(0xd2)
+		writeBigEndian(w, uint64(i), 4)
+	default:
+		w.WriteByte(0xd3)
+		writeBigEndian(w, uint64(i), 8)
+	}
+}
+
+// encodeMsgpackHeader encodes the length of a string, array or map, in its fixed type below the fixed limit, or
+// else in the 8-bit type, if any, the 16-bit or the 32-bit one.
+func encodeMsgpackHeader(w *bufio.Writer, length int, fixed byte, fixedLimit int, type8, type16, type32 byte) {
+	switch {
+	case length < fixedLimit:
+		w.WriteByte(fixed | byte(length))
+	case type8 != 0 && length <= math.MaxUint8:
+		w.WriteByte(type8)
+		writeBigEndian(w, uint64(length), 1)
+	case length <= math.MaxUint16:
+		w.WriteByte(type16)
+		writeBigEndian(w, uint64(length), 2)
+	default:
+		w.WriteByte(type32)
+		writeBigEndian(w, uint64(length), 4)
+	}
+}
+
+// writeBigEndian writes the lowest bytes of the value, most significant first.
+func writeBigEndian(w *bufio.Writer, val uint64, size int) {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, val)
+
+	// nolint
+	w.Write(buf[8-size:])
+}
@@ -0,0 +1,208 @@
+// Package serializer encodes the responses of the server in the formats clients ask for with the Accept header,
+// like XML or CSV. Handlers respond with json, which is decoded and encoded again by the serializer of the
+// requested format, so adding a format needs no changes to the handlers.
+package serializer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownFormat returns an error when no serializer is registered for a format.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// Names of the built-in formats.
+const (
+	FormatJSON    = "json"
+	FormatXML     = "xml"
+	FormatCSV     = "csv"
+	FormatMsgpack = "msgpack"
+	FormatJSONAPI = "jsonapi"
+)
+
+// Response is the response of a handler, to encode.
+type Response struct {
+	// Resource is the key of the resource of the route, if any, e.g. 'posts'.
+	Resource string
+	Status   int
+	// Data is the decoded json body, with numbers as json.Number.
+	Data interface{}
+}
+
+// Serializer encodes responses in a format.
+type Serializer interface {
+	// ContentType returns the content type of the encoded responses.
+	ContentType() string
+	// Serialize writes the encoded response.
+	Serialize(w io.Writer, resp Response) error
+}
+
+// format is a registered serializer, with the media types it is requested with.
+type format struct {
+	name       string
+	mediaTypes []string
+	serializer Serializer
+}
+
+var (
+	formatsMu sync.RWMutex
+	// formats are kept in the order they are registered, which breaks ties when negotiating.
+	formats []format
+)
+
+func init() {
+	Register(FormatJSON, jsonSerializer{}, "application/json")
+	Register(FormatXML, xmlSerializer{}, "application/xml", "text/xml")
+	Register(FormatCSV, csvSerializer{}, "text/csv")
+	Register(FormatMsgpack, msgpackSerializer{}, "application/msgpack", "application/x-msgpack", "application/vnd.msgpack")
+	Register(FormatJSONAPI, jsonAPISerializer{}, "application/vnd.api+json")
+}
+
+// Register makes the serializer available by the format name, for the media types. It panics if the serializer is
+// nil, or registered twice.
+func Register(name string, s Serializer, mediaTypes ...string) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	if s == nil {
+		panic("serializer: register serializer is nil")
+	}
+
+	for _, f := range formats {
+		if f.name == name {
+			panic("serializer: register called twice for format " + name)
+		}
+	}
+
+	formats = append(formats, format{name: name, mediaTypes: mediaTypes, serializer: s})
+}
+
+// Formats returns the names of the registered formats, sorted.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for _, f := range formats {
+		names = append(names, f.name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Lookup returns the serializer of the format. It fails with ErrUnknownFormat if there is none.
+func Lookup(name string) (Serializer, error) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	for _, f := range formats {
+		if f.name == name {
+			return f.serializer, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+}
+
+// Negotiate returns the format of the media type the Accept header prefers, by quality. Only media types named
+// explicitly count, and it reports false if there is none, e.g. for '*/*', so the default format applies. Requests
+// accepting html, like the ones of browsers, also report false, as browsers accept xml before any other type.
+func Negotiate(accept string) (string, bool) {
+	if accept == "" {
+		return "", false
+	}
+
+	quality := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if val, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(val, 64); err != nil {
+				continue
+			}
+		}
+
+		quality[mediaType] = q
+	}
+
+	if quality["text/html"] > 0 {
+		return "", false
+	}
+
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	best, bestQ := "", 0.0
+	for _, f := range formats {
+		for _, mediaType := range f.mediaTypes {
+			if q := quality[mediaType]; q > bestQ {
+				best, bestQ = f.name, q
+			}
+		}
+	}
+
+	return best, best != ""
+}
+
+// jsonSerializer encodes responses as json, as responded by the handlers.
+type jsonSerializer struct{}
+
+func (jsonSerializer) ContentType() string {
+	return "application/json"
+}
+
+func (jsonSerializer) Serialize(w io.Writer, resp Response) error {
+	return json.NewEncoder(w).Encode(resp.Data)
+}
+
+// sortedKeys returns the keys of the object sorted, with the id first, as records are identified by it.
+func sortedKeys(object map[string]interface{}) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		if key != "id" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	if _, ok := object["id"]; ok {
+		keys = append([]string{"id"}, keys...)
+	}
+
+	return keys
+}
+
+// text returns the text of a scalar value, or its json for arrays and objects.
+func text(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+
+	contentBytes, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprint(val)
+	}
+
+	return string(contentBytes)
+}
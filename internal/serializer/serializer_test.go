@@ -0,0 +1,172 @@
+package serializer_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/serializer"
+)
+
+func TestNegotiate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		accept string
+		format string
+		ok     bool
+	}{
+		{name: "Negotiate without accept header", accept: ""},
+		{name: "Negotiate any media type", accept: "*/*"},
+		{name: "Negotiate json", accept: "application/json", format: serializer.FormatJSON, ok: true},
+		{name: "Negotiate xml", accept: "text/xml", format: serializer.FormatXML, ok: true},
+		{name: "Negotiate csv with parameters", accept: "text/csv; charset=utf-8", format: serializer.FormatCSV, ok: true},
+		{name: "Negotiate msgpack", accept: "application/x-msgpack", format: serializer.FormatMsgpack, ok: true},
+		{name: "Negotiate jsonapi", accept: "application/vnd.api+json", format: serializer.FormatJSONAPI, ok: true},
+		{
+			name:   "Negotiate by quality",
+			accept: "application/json;q=0.5, text/csv;q=0.9, */*;q=0.1",
+			format: serializer.FormatCSV,
+			ok:     true,
+		},
+		{
+			name:   "Negotiate ignores excluded media types",
+			accept: "text/csv;q=0, application/xml;q=0.2",
+			format: serializer.FormatXML,
+			ok:     true,
+		},
+		{name: "Negotiate browser", accept: "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+		{name: "Negotiate unknown media type", accept: "image/png"},
+	}
+
+	for _, tt := range testCases {
+		format, ok := serializer.Negotiate(tt.accept)
+		if ok != tt.ok || format != tt.format {
+			t.Fatalf("%s: expected format %q (%v), but got %q (%v)", tt.name, tt.format, tt.ok, format, ok)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, format := range serializer.Formats() {
+		if _, err := serializer.Lookup(format); err != nil {
+			t.Fatalf("expected serializer of format %s, but got error %v", format, err)
+		}
+	}
+
+	if _, err := serializer.Lookup("yaml"); !errors.Is(err, serializer.ErrUnknownFormat) {
+		t.Fatalf("expected error %v, but got %v", serializer.ErrUnknownFormat, err)
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	records := decode(t, `[{"id": "1", "title": "a, b", "tags": ["x"], "views": 3}, {"id": 2, "draft": true}]`)
+	record := decode(t, `{"id": "1", "title": "a <b>", "1st": null}`)
+
+	testCases := []struct {
+		name     string
+		format   string
+		response serializer.Response
+		expected string
+	}{
+		{
+			name:     "Serialize json",
+			format:   serializer.FormatJSON,
+			response: serializer.Response{Data: record},
+			expected: `{"1st":null,"id":"1","title":"a \u003cb\u003e"}` + "\n",
+		},
+		{
+			name:     "Serialize xml array",
+			format:   serializer.FormatXML,
+			response: serializer.Response{Data: records},
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<response><item><id>1</id><tags><item>x</item></tags><title>a, b</title><views>3</views></item>` +
+				`<item><id>2</id><draft>true</draft></item></response>`,
+		},
+		{
+			name:     "Serialize xml object with invalid names",
+			format:   serializer.FormatXML,
+			response: serializer.Response{Data: record},
+			expected: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+				`<response><id>1</id><field name="1st"></field><title>a &lt;b&gt;</title></response>`,
+		},
+		{
+			name:     "Serialize csv array",
+			format:   serializer.FormatCSV,
+			response: serializer.Response{Data: records},
+			expected: "id,draft,tags,title,views\n1,,\"[\"\"x\"\"]\",\"a, b\",3\n2,true,,,\n",
+		},
+		{
+			name:     "Serialize csv values",
+			format:   serializer.FormatCSV,
+			response: serializer.Response{Data: decode(t, `["a", 1]`)},
+			expected: "value\na\n1\n",
+		},
+		{
+			name:     "Serialize csv empty array",
+			format:   serializer.FormatCSV,
+			response: serializer.Response{Data: decode(t, `[]`)},
+		},
+		{
+			name:     "Serialize msgpack",
+			format:   serializer.FormatMsgpack,
+			response: serializer.Response{Data: decode(t, `{"id": "1", "n": [-1, 300, 1.5, null, false]}`)},
+			expected: "\x82\xa2id\xa11\xa1n\x95\xff\xd1\x01\x2c\xcb\x3f\xf8\x00\x00\x00\x00\x00\x00\xc0\xc2",
+		},
+		{
+			name:     "Serialize jsonapi resources",
+			format:   serializer.FormatJSONAPI,
+			response: serializer.Response{Resource: "posts", Status: http.StatusOK, Data: records},
+			expected: `{"data":[{"type":"posts","id":"1","attributes":{"tags":["x"],"title":"a, b","views":3}},` +
+				`{"type":"posts","id":"2","attributes":{"draft":true}}]}` + "\n",
+		},
+		{
+			name:     "Serialize jsonapi meta",
+			format:   serializer.FormatJSONAPI,
+			response: serializer.Response{Status: http.StatusOK, Data: decode(t, `{"dirty": false}`)},
+			expected: `{"meta":{"dirty":false}}` + "\n",
+		},
+		{
+			name:   "Serialize jsonapi error",
+			format: serializer.FormatJSONAPI,
+			response: serializer.Response{
+				Resource: "posts",
+				Status:   http.StatusNotFound,
+				Data:     decode(t, `{"error": "resource not found"}`),
+			},
+			expected: `{"errors":[{"status":"404","title":"Not Found","detail":"resource not found"}]}` + "\n",
+		},
+	}
+
+	for _, tt := range testCases {
+		s, err := serializer.Lookup(tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err = s.Serialize(&buf, tt.response); err != nil {
+			t.Fatalf("%s: expected no error, but got %v", tt.name, err)
+		}
+
+		if buf.String() != tt.expected {
+			t.Fatalf("%s: expected body %q, but got %q", tt.name, tt.expected, buf.String())
+		}
+	}
+}
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+
+	var val interface{}
+
+	decoder := json.NewDecoder(bytes.NewBufferString(data))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&val); err != nil {
+		t.Fatal(err)
+	}
+
+	return val
+}
@@ -0,0 +1,75 @@
+package serializer
+
+import (
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// xmlNamePattern matches the keys which are valid element names. Other keys are encoded as 'field' elements, with
+// the key as 'name' attribute.
+var xmlNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9._-]*$`)
+
+// xmlSerializer encodes responses as xml, in a 'response' element. Objects hold an element per field, and arrays
+// an 'item' element per value.
+type xmlSerializer struct{}
+
+func (xmlSerializer) ContentType() string {
+	return "application/xml; charset=utf-8"
+}
+
+func (xmlSerializer) Serialize(w io.Writer, resp Response) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+
+	if err := encodeXML(encoder, xml.StartElement{Name: xml.Name{Local: "response"}}, resp.Data); err != nil {
+		return err
+	}
+
+	return encoder.Flush()
+}
+
+// encodeXML encodes the value in the element.
+func encodeXML(encoder *xml.Encoder, start xml.StartElement, val interface{}) error {
+	if err := encoder.EncodeToken(start); err != nil {
+		return err
+	}
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			if err := encodeXML(encoder, xmlElement(key), v[key]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := encodeXML(encoder, xml.StartElement{Name: xml.Name{Local: "item"}}, item); err != nil {
+				return err
+			}
+		}
+	case nil:
+	default:
+		if err := encoder.EncodeToken(xml.CharData(text(v))); err != nil {
+			return err
+		}
+	}
+
+	return encoder.EncodeToken(start.End())
+}
+
+// xmlElement returns the element of the field of the key.
+func xmlElement(key string) xml.StartElement {
+	if xmlNamePattern.MatchString(key) && !strings.HasPrefix(strings.ToLower(key), "xml") {
+		return xml.StartElement{Name: xml.Name{Local: key}}
+	}
+
+	return xml.StartElement{
+		Name: xml.Name{Local: "field"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: key}},
+	}
+}
@@ -0,0 +1,446 @@
+// Package server builds the http handler serving the resources of a json data source, either a watch file or data
+// kept in memory. It is shared by the start command and the public jsonserver package.
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/clock"
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/script"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var (
+	// ErrFileNotFound is returned when the watch file cannot be read.
+	ErrFileNotFound = errors.New("unable to find requested file")
+	// ErrFailedParseFile is returned when the watch file holds invalid resources.
+	ErrFailedParseFile = errors.New("failed to parse file")
+	// ErrFailedInitResources is returned when the storage of the resources cannot be created.
+	ErrFailedInitResources = errors.New("failed to initialize resources")
+	// ErrUsersNotFound is returned when the resource holding the users of the sessions does not exist.
+	ErrUsersNotFound = errors.New("unable to find users resource for authentication")
+	// ErrUnknownStorage is returned when no driver is registered for the storage backend.
+	ErrUnknownStorage = storage.ErrUnknownDriver
+	// ErrNoSnapshot is returned when snapshots are requested without a snapshot file.
+	ErrNoSnapshot = errors.New("no snapshot file configured")
+	// ErrUnpersisted is returned when changes kept in memory cannot be persisted, without a snapshot file.
+	ErrUnpersisted = errors.New("changes are kept in memory only, without a snapshot file")
+	// ErrPendingWrites is returned when the writes to the watch file do not complete in time.
+	ErrPendingWrites = errors.New("writes to the watch file did not complete in time")
+)
+
+// Built-in storage backends. Others are provided by the drivers registered with storage.Register.
+const (
+	// StorageFile keeps the resources in the watch file, rewritten on every write.
+	StorageFile = storage.DriverFile
+	// StorageMemory keeps the resources in memory, seeded from the watch file, which is never written.
+	StorageMemory = storage.DriverMemory
+	// StorageSQLite keeps the resources in a sqlite database next to the watch file, seeded from it once.
+	StorageSQLite = storage.DriverSQLite
+)
+
+// snapshotPath is the route writing the resources to the snapshot file on demand.
+const snapshotPath = "/__snapshot"
+
+// flushPath is the route persisting the changes on demand, and reporting whether any are unpersisted.
+const flushPath = "/__flush"
+
+// flushTimeout is the duration to wait for the writes to the watch file in progress, when flushing.
+const flushTimeout = time.Second * 5
+
+// Options configures the handler.
+type Options struct {
+	// File is the watch file holding the resources, which writes are persisted to, unless Data is set.
+	File string
+	// Lenient skips the invalid resources and records of the watch file, instead of failing.
+	Lenient bool
+	// Storage is the name of the driver of the backend keeping the resources of the watch file, the file itself
+	// if empty.
+	Storage string
+	// Snapshot is the file the resources are written to on demand, with a POST request to /__snapshot, and by
+	// Snapshot.
+	Snapshot string
+	// Data holds the resources to serve from memory instead of the watch file, if set.
+	Data storage.Database
+	// Config customizes individual resources, and its scripts handle routes.
+	Config *config.Config
+	// Clock is the mock clock stamping and expiring resources, a new one if nil.
+	Clock *clock.Clock
+	// Timestamps stamps resources with createdAt and updatedAt fields.
+	Timestamps bool
+	// TTL expires resources once their createdAt field is older than it, implying Timestamps.
+	TTL time.Duration
+	// BlobResources lists the resources whose records reference local files, in the BlobField, relative to the
+	// watch file.
+	BlobResources []string
+	BlobField     string
+	// Sessions serves every cookie session with a sandbox of the resources kept in memory, if set.
+	Sessions *Sessions
+	// HandlerOptions configure the handler further.
+	HandlerOptions []handler.Option
+}
+
+// Sessions describes the cookie sessions.
+type Sessions struct {
+	// UsersKey is the resource holding the users logging in.
+	UsersKey string
+	// PrivateFields lists the fields of the users left out of responses.
+	PrivateFields []string
+	Expiry        time.Duration
+	// MaxSessions is the number of live sessions, unlimited if zero.
+	MaxSessions int
+	CSRF        bool
+}
+
+// Load reads the resources of the watch file. If lenient, invalid resources and records are skipped, and their
+// errors returned.
+func Load(filename string, lenient bool) (storage.Database, []error, error) {
+	contentBytes, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrFileNotFound, filename)
+	}
+
+	var (
+		data    storage.Database
+		skipped []error
+	)
+
+	if lenient {
+		data, skipped, err = storage.ParseDatabaseLenient(contentBytes, storage.ArrayResourceKey(filename))
+	} else {
+		data, err = storage.ParseDatabase(contentBytes, storage.ArrayResourceKey(filename))
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrFailedParseFile, filename, err)
+	}
+
+	return data, skipped, nil
+}
+
+// ResourceKeys returns the keys of the resources, sorted.
+func ResourceKeys(data storage.Database) []string {
+	resourceKeys := make([]string, 0, len(data))
+	for resourceKey := range data {
+		resourceKeys = append(resourceKeys, resourceKey)
+	}
+
+	sort.Strings(resourceKeys)
+
+	return resourceKeys
+}
+
+// Server is the handler serving the resources of a data source.
+type Server struct {
+	http.Handler
+	// db is the storage of the common db endpoint, holding all resources.
+	db       storage.Storage
+	snapshot string
+	// closer closes the storage, if it holds resources open.
+	closer io.Closer
+	// versioned tracks the changes of the resources kept in memory, if so, and persisted is its version last
+	// written to the snapshot file.
+	versioned versioned
+	persisted uint64
+	// mu serializes the snapshots, and guards the persisted version.
+	mu sync.Mutex
+}
+
+// versioned is implemented by the storages keeping resources in memory, whose version changes on every write.
+type versioned interface {
+	Version() uint64
+}
+
+// flushStatus reports whether there are unpersisted changes.
+type flushStatus struct {
+	Dirty bool `json:"dirty"`
+}
+
+// Close closes the storage of the resources.
+func (s *Server) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
+
+// Snapshot writes all resources to the snapshot file, so it can be served as a watch file.
+func (s *Server) Snapshot() error {
+	if s.snapshot == "" {
+		return ErrNoSnapshot
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The version is read first, so writes racing the snapshot leave it dirty rather than lost.
+	var version uint64
+	if s.versioned != nil {
+		version = s.versioned.Version()
+	}
+
+	data, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+
+	if err = storage.SaveFile(s.snapshot, data); err != nil {
+		return err
+	}
+
+	s.persisted = version
+
+	return nil
+}
+
+// Dirty reports whether the resources kept in memory changed since they were last written to the snapshot file.
+// Resources kept in the watch file or a database are written through, and never dirty.
+func (s *Server) Dirty() bool {
+	if s.versioned == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.versioned.Version() != s.persisted
+}
+
+// Flush persists all changes: it waits for the writes to the watch file in progress, and writes the snapshot
+// file, if any. It fails with ErrUnpersisted if changes are kept in memory only.
+func (s *Server) Flush() error {
+	if !storage.WaitWrites(flushTimeout) {
+		return ErrPendingWrites
+	}
+
+	if s.snapshot != "" {
+		return s.Snapshot()
+	}
+
+	if s.Dirty() {
+		return ErrUnpersisted
+	}
+
+	return nil
+}
+
+// New returns the handler serving the resources of the data source.
+func New(o Options) (http.Handler, error) {
+	s, err := Open(o)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Open returns the server of the resources of the data source.
+func Open(o Options) (*Server, error) {
+	if o.Clock == nil {
+		o.Clock = clock.New()
+	}
+
+	if o.Config == nil {
+		o.Config = &config.Config{}
+	}
+
+	handlerOpts := []handler.Option{handler.WithClock(o.Clock), handler.WithConfig(o.Config)}
+
+	// Handle the routes of the configuration with scripts.
+	for route, scriptFile := range o.Config.Scripts {
+		s, err := script.Load(scriptFile)
+		if err != nil {
+			return nil, err
+		}
+
+		handlerOpts = append(handlerOpts, handler.WithScript(route, s))
+	}
+
+	o.HandlerOptions = append(handlerOpts, o.HandlerOptions...)
+
+	resourceStorage, collections, err := createStorage(o)
+	if err != nil {
+		return nil, err
+	}
+
+	srv := &Server{db: resourceStorage["db"], snapshot: o.Snapshot}
+	if closer, ok := collections.(io.Closer); ok {
+		srv.closer = closer
+	}
+
+	if v, ok := collections.(versioned); ok {
+		srv.versioned = v
+		srv.persisted = v.Version()
+	}
+
+	h := setup(o, resourceStorage, collections)
+
+	if o.Sessions != nil {
+		if h, err = withSessions(o, h, resourceStorage); err != nil {
+			srv.Close()
+			return nil, err
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", h)
+
+	// Report unpersisted changes, and persist them on demand.
+	mux.HandleFunc(flushPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			web.Success(w, http.StatusOK, flushStatus{Dirty: srv.Dirty()})
+		case http.MethodPost:
+			if err := srv.Flush(); errors.Is(err, ErrUnpersisted) {
+				web.Error(w, http.StatusConflict, err.Error())
+				return
+			} else if err != nil {
+				web.Error(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			web.Success(w, http.StatusOK, flushStatus{Dirty: srv.Dirty()})
+		default:
+			h.ServeHTTP(w, r)
+		}
+	})
+
+	// Write the snapshot on demand.
+	if o.Snapshot != "" {
+		mux.HandleFunc(snapshotPath, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			if err := srv.Snapshot(); err != nil {
+				web.Error(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+
+			web.Success(w, http.StatusNoContent, nil)
+		})
+	}
+
+	srv.Handler = mux
+
+	return srv, nil
+}
+
+// withSessions serves the requests of every session of the handler with a sandbox of the resources, kept in
+// memory.
+func withSessions(o Options, h http.Handler, resourceStorage map[string]storage.Storage) (http.Handler, error) {
+	usersSvc, ok := resourceStorage[o.Sessions.UsersKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUsersNotFound, o.Sessions.UsersKey)
+	}
+
+	sandbox := func(data storage.Database) (http.Handler, error) {
+		sandboxOpts := o
+		sandboxOpts.Data = data
+
+		sandboxStorage, sandboxCollections, err := createStorage(sandboxOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		return setup(sandboxOpts, sandboxStorage, sandboxCollections), nil
+	}
+
+	sessionStore := handler.NewSessions(usersSvc, resourceStorage["db"], sandbox, o.Sessions.Expiry, o.Clock.Now,
+		o.Sessions.MaxSessions, o.Sessions.CSRF)
+
+	return sessionStore.Handler(h, o.Sessions.PrivateFields...), nil
+}
+
+// createStorage returns the storage of every resource, and of the common db endpoint, with the collections the
+// resources created at runtime are added to. Resources are kept in memory if the data is set, or else in the
+// backend of the storage driver, seeded from the watch file.
+func createStorage(o Options) (map[string]storage.Storage, storage.Collections, error) {
+	name := o.Storage
+	if name == "" {
+		name = StorageFile
+	}
+
+	src := storage.Source{File: o.File, Data: o.Data, Lenient: o.Lenient}
+	if o.Data != nil {
+		name = StorageMemory
+	}
+
+	driver, err := storage.Lookup(name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if src.Data == nil {
+		if src.Data, _, err = Load(o.File, o.Lenient); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	backend, err := driver.Open(src)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrFailedInitResources, err)
+	}
+
+	resourceKeys, err := backend.Keys()
+	if err != nil {
+		closeBackend(backend)
+		return nil, nil, fmt.Errorf("%w: %v", ErrFailedInitResources, err)
+	}
+
+	resourceStorage := make(map[string]storage.Storage)
+	for _, resourceKey := range append(resourceKeys, "") {
+		storageSvc, err := backend.Storage(resourceKey)
+		if err != nil {
+			closeBackend(backend)
+			return nil, nil, fmt.Errorf("%w: %v", ErrFailedInitResources, err)
+		}
+
+		// The storage of all resources serves the common db endpoint.
+		if resourceKey == "" {
+			resourceKey = "db"
+		}
+
+		resourceStorage[resourceKey] = storageSvc
+	}
+
+	return resourceStorage, backend, nil
+}
+
+// closeBackend closes the backend, if it holds resources open.
+func closeBackend(backend storage.Backend) {
+	if closer, ok := backend.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// setup returns the handler serving the resources of the storages, with the blobs of the watch file.
+func setup(o Options, resourceStorage map[string]storage.Storage, collections storage.Collections) http.Handler {
+	// Stamp and expire resources based on the mock clock.
+	if o.Timestamps || o.TTL > 0 {
+		for resourceKey, storageSvc := range resourceStorage {
+			resourceStorage[resourceKey] = storage.NewTimestamps(storageSvc, o.Clock.Now, o.TTL)
+		}
+	}
+
+	// Add the resources created at runtime to the collections.
+	opts := append([]handler.Option{handler.WithRuntimeResources(collections)}, o.HandlerOptions...)
+	for _, resourceKey := range o.BlobResources {
+		opts = append(opts, handler.WithBlob(resourceKey, o.BlobField, filepath.Dir(o.File)))
+	}
+
+	return handler.Setup(resourceStorage, opts...)
+}
@@ -0,0 +1,163 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/server"
+)
+
+func TestOpen_MemorySnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "db.json")
+	content := `{"posts": [{"id": "1", "title": "json-server"}]}`
+	if err = ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := filepath.Join(dir, "snapshot.json")
+
+	srv, err := server.Open(server.Options{File: file, Storage: server.StorageMemory, Snapshot: snapshot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/posts", "application/json", bytes.NewBufferString(`{"title": "in memory"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	got, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(got) != content {
+		t.Fatalf("expected watch file %s to be kept, but got %s", content, got)
+	}
+
+	if resp, err = http.Post(ts.URL+"/__snapshot", "application/json", nil); err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected status code %v, but got %v", http.StatusNoContent, resp.StatusCode)
+	}
+
+	if got, err = ioutil.ReadFile(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(got), "in memory") {
+		t.Fatalf("expected snapshot to hold the created resource, but got %s", got)
+	}
+
+	// The snapshot serves as a watch file.
+	if _, err = server.Open(server.Options{File: snapshot}); err != nil {
+		t.Fatalf("expected snapshot to be a valid watch file, but got %v", err)
+	}
+}
+
+func TestOpen_UnknownStorage(t *testing.T) {
+	if _, err := server.Open(server.Options{File: "db.json", Storage: "postgres"}); !errors.Is(err, server.ErrUnknownStorage) {
+		t.Fatalf("expected error %v, but got %v", server.ErrUnknownStorage, err)
+	}
+}
+
+func TestServer_Flush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "server")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "db.json")
+	if err = ioutil.WriteFile(file, []byte(`{"posts": [{"id": "1", "title": "json-server"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name       string
+		storage    string
+		snapshot   string
+		dirty      bool
+		statusCode int
+	}{
+		{name: "Flush file storage", storage: server.StorageFile, statusCode: http.StatusOK},
+		{name: "Flush memory storage to snapshot", storage: server.StorageMemory, snapshot: filepath.Join(dir, "snapshot.json"), dirty: true, statusCode: http.StatusOK},
+		{name: "Flush memory storage without snapshot", storage: server.StorageMemory, dirty: true, statusCode: http.StatusConflict},
+	}
+
+	for _, tt := range testCases {
+		srv, err := server.Open(server.Options{File: file, Storage: tt.storage, Snapshot: tt.snapshot})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ts := httptest.NewServer(srv)
+
+		resp, err := http.Post(ts.URL+"/posts", "application/json", bytes.NewBufferString(`{"title": "changed"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if dirty := srv.Dirty(); dirty != tt.dirty {
+			t.Fatalf("%s: expected dirty %v, but got %v", tt.name, tt.dirty, dirty)
+		}
+
+		if resp, err = http.Post(ts.URL+"/__flush", "application/json", nil); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.StatusCode)
+		}
+
+		if resp, err = http.Get(ts.URL + "/__flush"); err != nil {
+			t.Fatal(err)
+		}
+
+		var got struct {
+			Dirty bool `json:"dirty"`
+		}
+
+		err = json.NewDecoder(resp.Body).Decode(&got)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Only the changes kept in memory without a snapshot are left unpersisted.
+		if expected := tt.statusCode != http.StatusOK; got.Dirty != expected {
+			t.Fatalf("%s: expected dirty %v after flush, but got %v", tt.name, expected, got.Dirty)
+		}
+
+		ts.Close()
+		srv.Close()
+	}
+}
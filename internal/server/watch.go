@@ -0,0 +1,107 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// Watcher serves the handler of a watch file, built again once another process changes the file, e.g. when edited
+// by hand, so the routes of the resources added to or removed from it are registered, and cached responses
+// dropped. Writes served by the handler itself are not reloaded. Invalid versions of the file, e.g. saved halfway
+// through an edit, keep the routes of the previous version until fixed.
+type Watcher struct {
+	filename string
+	build    func() (http.Handler, error)
+	handler  atomic.Value
+	// info describes the version of the file last seen, only accessed by the polling goroutine.
+	info os.FileInfo
+	stop chan struct{}
+	once sync.Once
+}
+
+// servedHandler boxes the handler, as an atomic value must always hold the same concrete type.
+type servedHandler struct {
+	http.Handler
+}
+
+// Watch returns a watcher serving the handler built for the watch file, polling it for changes at the interval.
+// It must be closed to stop polling.
+func Watch(filename string, interval time.Duration, build func() (http.Handler, error)) (*Watcher, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrFileNotFound, filename)
+	}
+
+	h, err := build()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{filename: filename, build: build, info: info, stop: make(chan struct{})}
+	w.handler.Store(servedHandler{h})
+
+	go w.poll(interval)
+
+	return w, nil
+}
+
+// ServeHTTP serves the request with the handler of the latest valid version of the watch file.
+func (w *Watcher) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.handler.Load().(servedHandler).ServeHTTP(rw, r)
+}
+
+// Close stops polling the watch file.
+func (w *Watcher) Close() {
+	w.once.Do(func() { close(w.stop) })
+}
+
+func (w *Watcher) poll(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			w.reload()
+		}
+	}
+}
+
+// reload builds the handler again if the watch file changed since last seen, unless by the handler itself.
+func (w *Watcher) reload() {
+	info, err := os.Stat(w.filename)
+	// Editors may remove the file briefly while saving it.
+	if err != nil {
+		return
+	}
+
+	if os.SameFile(w.info, info) && w.info.Size() == info.Size() && w.info.ModTime().Equal(info.ModTime()) {
+		return
+	}
+
+	w.info = info
+
+	if storage.WrittenByProcess(w.filename, info) {
+		return
+	}
+
+	h, err := w.build()
+	if err != nil {
+		logrus.Warnf("failed to reload %s, serving its previous version: %v", w.filename, err)
+		return
+	}
+
+	w.handler.Store(servedHandler{h})
+
+	logrus.Infof("reloaded %s", w.filename)
+}
@@ -0,0 +1,90 @@
+package server_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/server"
+)
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "db.json")
+	if err = ioutil.WriteFile(file, []byte(`{"posts": [{"id": "1"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var builds int32
+	watcher, err := server.Watch(file, 10*time.Millisecond, func() (http.Handler, error) {
+		atomic.AddInt32(&builds, 1)
+		return server.New(server.Options{File: file})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	srv := httptest.NewServer(watcher)
+	defer srv.Close()
+
+	if statusCode := get(t, srv.URL+"/comments"); statusCode != http.StatusNotFound {
+		t.Fatalf("expected status code %v before the edit, but got %v", http.StatusNotFound, statusCode)
+	}
+
+	// Edit the file by hand, adding a resource.
+	if err = ioutil.WriteFile(file, []byte(`{"posts": [{"id": "1"}], "comments": [{"id": "1"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for get(t, srv.URL+"/comments") != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the added resource to be served after the edit")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reloaded := atomic.LoadInt32(&builds)
+
+	// Writes of the server itself are not reloaded.
+	resp, err := http.Post(srv.URL+"/posts", "application/json", bytes.NewBufferString(`{"title": "created"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if got := atomic.LoadInt32(&builds); got != reloaded {
+		t.Fatalf("expected %v builds after a write of the server, but got %v", reloaded, got)
+	}
+}
+
+func get(t *testing.T, url string) int {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode
+}
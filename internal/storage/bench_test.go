@@ -0,0 +1,155 @@
+package storage_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+// benchResources is the number of resources of the synthetic dataset of the benchmarks.
+const benchResources = 10000
+
+func benchData() storage.Database {
+	resources := make([]storage.Resource, 0, benchResources)
+	for idx := 0; idx < benchResources; idx++ {
+		resources = append(resources, storage.Resource{
+			"id":     strconv.Itoa(idx),
+			"title":  fmt.Sprintf("post %d about json servers", idx),
+			"author": fmt.Sprintf("author-%d", idx%100),
+		})
+	}
+
+	return storage.Database{"posts": resources}
+}
+
+// benchFile returns the file storage of a synthetic 'posts' resource, and a function removing its watch file.
+func benchFile(b *testing.B) (*storage.File, func()) {
+	b.Helper()
+
+	f, err := ioutil.TempFile(".", "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	f.Close()
+
+	contentBytes, err := json.Marshal(benchData())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	if err = ioutil.WriteFile(f.Name(), contentBytes, 0644); err != nil {
+		b.Fatal(err)
+	}
+
+	// Files modified just now have their contents compared on every read, unlike settled ones.
+	past := time.Now().Add(-time.Minute)
+	if err = os.Chtimes(f.Name(), past, past); err != nil {
+		b.Fatal(err)
+	}
+
+	storageSvc, err := storage.NewFile(f.Name(), "posts")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return storageSvc, func() { os.Remove(f.Name()) }
+}
+
+func benchMemory(b *testing.B) *storage.Memory {
+	b.Helper()
+
+	storageSvc, err := storage.NewMemory(storage.NewMemoryDB(benchData()), "posts")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return storageSvc
+}
+
+func BenchmarkFile_Find(b *testing.B) {
+	storageSvc, cleanup := benchFile(b)
+	defer cleanup()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := storageSvc.Find(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFile_FindById(b *testing.B) {
+	storageSvc, cleanup := benchFile(b)
+	defer cleanup()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := storageSvc.FindById(strconv.Itoa(idx % benchResources)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFile_Create(b *testing.B) {
+	storageSvc, cleanup := benchFile(b)
+	defer cleanup()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := storageSvc.Create(storage.Resource{"title": "new post"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFile_Update(b *testing.B) {
+	storageSvc, cleanup := benchFile(b)
+	defer cleanup()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := storageSvc.Update(strconv.Itoa(idx%benchResources), storage.Resource{"title": "updated"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMemory_FindById(b *testing.B) {
+	storageSvc := benchMemory(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := storageSvc.FindById(strconv.Itoa(idx % benchResources)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMemory_Create(b *testing.B) {
+	storageSvc := benchMemory(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for idx := 0; idx < b.N; idx++ {
+		if _, err := storageSvc.Create(storage.Resource{"title": "new post"}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Eviction policies of capped storages.
+const (
+	// EvictFIFO evicts the resources created first.
+	EvictFIFO = "fifo"
+	// EvictLRU evicts the resources read or written least recently.
+	EvictLRU = "lru"
+)
+
+// Capped implements the storage interface, by wrapping another storage. It keeps at most the maximum
+// number of resources, evicting resources on create following the eviction policy.
+type Capped struct {
+	storage    Storage
+	maxRecords int
+	lru        bool
+
+	// mu serializes creates, so concurrent creates never evict more resources than needed, and guards
+	// the uses of resources.
+	mu sync.Mutex
+	// used holds the last use of resources by id, for LRU eviction. Resources never used since the start
+	// are the least recently used, in storage order.
+	used    map[string]uint64
+	useTick uint64
+}
+
+// NewCapped returns a new capped instance, keeping at most maxRecords resources. The eviction policy is
+// either EvictFIFO, which is the default, or EvictLRU.
+func NewCapped(storageSvc Storage, maxRecords int, eviction string) *Capped {
+	return &Capped{
+		storage:    storageSvc,
+		maxRecords: maxRecords,
+		lru:        eviction == EvictLRU,
+		used:       make(map[string]uint64),
+	}
+}
+
+// Find all resources for the specific key.
+func (c *Capped) Find() ([]Resource, error) {
+	return c.storage.Find()
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (c *Capped) FindContext(ctx context.Context) ([]Resource, error) {
+	return FindContext(ctx, c.storage)
+}
+
+// FindById a resource for the specific key.
+func (c *Capped) FindById(id string) (Resource, error) {
+	resource, err := c.storage.FindById(id)
+	if err == nil {
+		c.use(id)
+	}
+
+	return resource, err
+}
+
+// Create a new resource for the specific key, evicting resources once there are more than the maximum.
+func (c *Capped) Create(newResource Resource) (Resource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	created, err := c.storage.Create(newResource)
+	if err != nil {
+		return nil, err
+	}
+
+	id := fmt.Sprint(created["id"])
+
+	c.useTick++
+	c.used[id] = c.useTick
+
+	if err = c.evict(id); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// Replace an existing resource for the specific key.
+func (c *Capped) Replace(id string, replaced Resource) (Resource, error) {
+	resource, err := c.storage.Replace(id, replaced)
+	if err == nil {
+		c.use(id)
+	}
+
+	return resource, err
+}
+
+// Update an existing resource for the specific key.
+func (c *Capped) Update(id string, updatedReq Resource) (Resource, error) {
+	resource, err := c.storage.Update(id, updatedReq)
+	if err == nil {
+		c.use(id)
+	}
+
+	return resource, err
+}
+
+// Delete an existing resource for the specific key.
+func (c *Capped) Delete(id string) error {
+	if err := c.storage.Delete(id); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	delete(c.used, id)
+	c.mu.Unlock()
+
+	return nil
+}
+
+// DB returns all resources.
+func (c *Capped) DB() (Database, error) {
+	return c.storage.DB()
+}
+
+// Transaction runs the function with the storage of the transaction, capped the same way. Uses of resources in
+// the transaction are only tracked once it succeeds.
+func (c *Capped) Transaction(fn func(tx Storage) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	used := make(map[string]uint64, len(c.used))
+	for id, tick := range c.used {
+		used[id] = tick
+	}
+
+	capped := &Capped{maxRecords: c.maxRecords, lru: c.lru, used: used, useTick: c.useTick}
+
+	err := Transaction(c.storage, func(tx Storage) error {
+		capped.storage = tx
+		return fn(capped)
+	})
+	if err != nil {
+		return err
+	}
+
+	c.used, c.useTick = capped.used, capped.useTick
+
+	return nil
+}
+
+func (c *Capped) use(id string) {
+	if !c.lru {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.useTick++
+	c.used[id] = c.useTick
+}
+
+// evict deletes the resources exceeding the maximum, except the created one. Callers must hold the lock.
+func (c *Capped) evict(createdID string) error {
+	resources, err := c.storage.Find()
+	if err != nil {
+		return err
+	}
+
+	excess := len(resources) - c.maxRecords
+	if excess <= 0 {
+		return nil
+	}
+
+	candidates := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		if id := fmt.Sprint(resource["id"]); id != createdID {
+			candidates = append(candidates, id)
+		}
+	}
+
+	// Resources are kept in the order they were created, so the first ones are evicted by FIFO.
+	if c.lru {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return c.used[candidates[i]] < c.used[candidates[j]]
+		})
+	}
+
+	for _, id := range candidates[:excess] {
+		if err := c.storage.Delete(id); err != nil && !errors.Is(err, ErrResourceNotFound) {
+			return err
+		}
+
+		delete(c.used, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package storage_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestCapped(t *testing.T) {
+	testCases := []struct {
+		name     string
+		eviction string
+		read     []string
+		expected []string
+	}{
+		{name: "Evict first created", eviction: storage.EvictFIFO, read: []string{"1"}, expected: []string{"2", "3", "4"}},
+		{name: "Evict first created by default", read: []string{"1"}, expected: []string{"2", "3", "4"}},
+		{name: "Evict least recently used", eviction: storage.EvictLRU, read: []string{"1"}, expected: []string{"1", "3", "4"}},
+	}
+
+	for _, tt := range testCases {
+		db := storage.NewMemoryDB(storage.Database{
+			"events": {{"id": "1"}, {"id": "2"}},
+		})
+
+		storageSvc, err := storage.NewMemory(db, "events")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		capped := storage.NewCapped(storageSvc, 3, tt.eviction)
+
+		if _, err = capped.Create(storage.Resource{"id": "3"}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, id := range tt.read {
+			if _, err = capped.FindById(id); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err = capped.Create(storage.Resource{"id": "4"}); err != nil {
+			t.Fatal(err)
+		}
+
+		resources, err := capped.Find()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, 0)
+		for _, resource := range resources {
+			ids = append(ids, resource["id"].(string))
+		}
+
+		if !reflect.DeepEqual(ids, tt.expected) {
+			t.Fatalf("%s: expected ids %v, but got %v", tt.name, tt.expected, ids)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package storage
+
+import "context"
+
+// ComputeFunc derives the value of a computed field from the stored resource.
+type ComputeFunc func(resource Resource) (interface{}, error)
+
+// Computed implements the storage interface, by wrapping another storage. It adds computed fields to all
+// returned resources, and never stores them.
+type Computed struct {
+	storage Storage
+	key     string
+	fields  map[string]map[string]ComputeFunc
+}
+
+// NewComputed returns a new computed instance for the resource key. The fields map holds the computed
+// fields of each resource key, and is used as a whole for the db contents.
+func NewComputed(storageSvc Storage, key string, fields map[string]map[string]ComputeFunc) *Computed {
+	return &Computed{storage: storageSvc, key: key, fields: fields}
+}
+
+// Find all resources for the specific key.
+func (c *Computed) Find() ([]Resource, error) {
+	return c.FindContext(context.Background())
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (c *Computed) FindContext(ctx context.Context) ([]Resource, error) {
+	resources, err := FindContext(ctx, c.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return computeAll(resources, c.fields[c.key]), nil
+}
+
+// FindById a resource for the specific key.
+func (c *Computed) FindById(id string) (Resource, error) {
+	return c.compute(c.storage.FindById(id))
+}
+
+// Create a new resource for the specific key.
+func (c *Computed) Create(newResource Resource) (Resource, error) {
+	return c.compute(c.storage.Create(c.withoutComputed(newResource)))
+}
+
+// Replace an existing resource for the specific key.
+func (c *Computed) Replace(id string, replaced Resource) (Resource, error) {
+	return c.compute(c.storage.Replace(id, c.withoutComputed(replaced)))
+}
+
+// Update an existing resource for the specific key.
+func (c *Computed) Update(id string, updatedReq Resource) (Resource, error) {
+	return c.compute(c.storage.Update(id, c.withoutComputed(updatedReq)))
+}
+
+// Delete an existing resource for the specific key.
+func (c *Computed) Delete(id string) error {
+	return c.storage.Delete(id)
+}
+
+// DB returns all resources.
+func (c *Computed) DB() (Database, error) {
+	data, err := c.storage.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	computed := make(Database, len(data))
+	for key, resources := range data {
+		computed[key] = computeAll(resources, c.fields[key])
+	}
+
+	return computed, nil
+}
+
+// Transaction runs the function with the storage of the transaction, computing the same fields.
+func (c *Computed) Transaction(fn func(tx Storage) error) error {
+	return Transaction(c.storage, func(tx Storage) error {
+		return fn(NewComputed(tx, c.key, c.fields))
+	})
+}
+
+func (c *Computed) compute(resource Resource, err error) (Resource, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return computeFields(resource, c.fields[c.key]), nil
+}
+
+// withoutComputed removes computed fields sent by clients, as they are never stored.
+func (c *Computed) withoutComputed(resource Resource) Resource {
+	for field := range c.fields[c.key] {
+		delete(resource, field)
+	}
+
+	return resource
+}
+
+func computeAll(resources []Resource, fields map[string]ComputeFunc) []Resource {
+	computed := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		computed = append(computed, computeFields(resource, fields))
+	}
+
+	return computed
+}
+
+// computeFields returns a copy of the resource with the computed fields. Fields are computed from the
+// stored fields only, and are null when their computation fails.
+func computeFields(resource Resource, fields map[string]ComputeFunc) Resource {
+	if len(fields) == 0 {
+		return resource
+	}
+
+	computed := copyResource(resource)
+	for field, compute := range fields {
+		val, err := compute(resource)
+		if err != nil {
+			val = nil
+		}
+
+		computed[field] = val
+	}
+
+	return computed
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownDriver returns an error when a storage driver is not registered.
+var ErrUnknownDriver = errors.New("unknown storage")
+
+// Names of the drivers of the built-in storages.
+const (
+	// DriverFile keeps the resources in the watch file, rewritten on every write.
+	DriverFile = "file"
+	// DriverMemory keeps the resources in memory, seeded from the watch file, which is never written.
+	DriverMemory = "memory"
+	// DriverSQLite keeps the resources in a sqlite database next to the watch file, seeded from it once.
+	DriverSQLite = "sqlite"
+)
+
+// Driver opens the backend keeping the resources of a watch file. Drivers are registered by name with Register,
+// usually in the init function of their package, and picked by the name with the storage flag, the same way as
+// the drivers of database/sql.
+type Driver interface {
+	// Open returns the backend of the resources of the source. It is called once per served watch file, and once
+	// per sandbox of the cookie sessions, with their own data.
+	Open(src Source) (Backend, error)
+}
+
+// Source describes the resources a backend is opened for.
+type Source struct {
+	// File is the watch file, e.g. to keep a database next to it. It is empty for the resources served from
+	// memory, like the sandboxes of the cookie sessions.
+	File string
+	// Data holds the resources of the watch file, to seed an empty backend with.
+	Data Database
+	// Lenient skips the invalid resources and records of the watch file, if read again.
+	Lenient bool
+}
+
+// Backend is a database of collections of resources, opened by a driver. Backends holding resources open, like
+// connections, implement io.Closer, and are closed once the server shuts down.
+type Backend interface {
+	Collections
+	// Keys returns the keys of the collections.
+	Keys() ([]string, error)
+	// Storage returns the storage of the resources of the collection of the key, or of all resources for the
+	// common db endpoint if the key is empty. The storages must be safe for concurrent use, look resources up
+	// by their 'id' field, fail with ErrResourceNotFound for missing resources and collections, and with
+	// ErrResourceAlreadyExists when creating a resource with an existing id. Resources created without id are
+	// assigned a new one.
+	Storage(key string) (Storage, error)
+}
+
+var (
+	driversMu sync.RWMutex
+	drivers   = make(map[string]Driver)
+)
+
+func init() {
+	Register(DriverFile, fileDriver{})
+	Register(DriverMemory, memoryDriver{})
+	Register(DriverSQLite, sqliteDriver{})
+}
+
+// Register makes the driver available by the name. It panics if the driver is nil, or registered twice.
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if driver == nil {
+		panic("storage: register driver is nil")
+	}
+
+	if _, ok := drivers[name]; ok {
+		panic("storage: register called twice for driver " + name)
+	}
+
+	drivers[name] = driver
+}
+
+// Drivers returns the names of the registered drivers, sorted.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Lookup returns the driver registered by the name. It fails with ErrUnknownDriver if there is none.
+func Lookup(name string) (Driver, error) {
+	driversMu.RLock()
+	driver, ok := drivers[name]
+	driversMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s, expected one of %s", ErrUnknownDriver, name, strings.Join(Drivers(), ", "))
+	}
+
+	return driver, nil
+}
+
+// fileDriver opens the watch file itself.
+type fileDriver struct{}
+
+// fileBackend keeps the resources in the watch file, whose storage of all resources manages the collections.
+type fileBackend struct {
+	file    string
+	lenient bool
+	keys    []string
+	db      *File
+}
+
+// memoryDriver opens an in-memory database, seeded with the data of the source.
+type memoryDriver struct{}
+
+// sqliteDriver opens the sqlite database next to the watch file.
+type sqliteDriver struct{}
+
+// sqliteBackend keeps the resources in a sqlite database.
+type sqliteBackend struct {
+	*SQLiteDB
+}
+
+// Open the watch file of the source.
+func (fileDriver) Open(src Source) (Backend, error) {
+	b := &fileBackend{file: src.File, lenient: src.Lenient}
+
+	for key := range src.Data {
+		b.keys = append(b.keys, key)
+	}
+
+	sort.Strings(b.keys)
+
+	storageSvc, err := b.Storage("")
+	if err != nil {
+		return nil, err
+	}
+
+	b.db = storageSvc.(*File)
+
+	return b, nil
+}
+
+// Keys returns the keys of the resources of the watch file, when opened.
+func (b *fileBackend) Keys() ([]string, error) {
+	return b.keys, nil
+}
+
+// Storage returns the file storage of the resources of the key, skipping invalid records if lenient.
+func (b *fileBackend) Storage(key string) (Storage, error) {
+	if b.lenient {
+		return NewLenientFile(b.file, key)
+	}
+
+	return NewFile(b.file, key)
+}
+
+// AddCollection adds an empty collection to the watch file.
+func (b *fileBackend) AddCollection(key string) (Storage, error) {
+	return b.db.AddCollection(key)
+}
+
+// DropCollection drops the collection from the watch file.
+func (b *fileBackend) DropCollection(key string) error {
+	return b.db.DropCollection(key)
+}
+
+// RenameCollection renames the collection of the watch file.
+func (b *fileBackend) RenameCollection(key, newKey string) (Storage, error) {
+	return b.db.RenameCollection(key, newKey)
+}
+
+// Open an in-memory database with a copy of the data of the source.
+func (memoryDriver) Open(src Source) (Backend, error) {
+	return NewMemoryDB(src.Data), nil
+}
+
+// Open the sqlite database next to the watch file of the source, e.g. 'db.sqlite' for 'db.json', seeded with its
+// data when created.
+func (sqliteDriver) Open(src Source) (Backend, error) {
+	db, err := OpenSQLite(SQLiteFile(src.File), src.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqliteBackend{db}, nil
+}
+
+// Storage returns the sqlite storage of the resources of the key.
+func (b sqliteBackend) Storage(key string) (Storage, error) {
+	return NewSQLite(b.SQLiteDB, key)
+}
+
+// SQLiteFile returns the sqlite database file of the watch file, next to it with the '.sqlite' extension.
+func SQLiteFile(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".sqlite"
+}
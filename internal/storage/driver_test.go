@@ -0,0 +1,68 @@
+package storage_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestLookup(t *testing.T) {
+	testCases := []struct {
+		name   string
+		driver string
+		err    error
+	}{
+		{name: "Lookup file driver", driver: storage.DriverFile},
+		{name: "Lookup memory driver", driver: storage.DriverMemory},
+		{name: "Lookup sqlite driver", driver: storage.DriverSQLite},
+		{name: "Lookup unknown driver", driver: "postgres", err: storage.ErrUnknownDriver},
+	}
+
+	for _, tt := range testCases {
+		driver, err := storage.Lookup(tt.driver)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if tt.err == nil && driver == nil {
+			t.Fatalf("%s: expected driver, but got nil", tt.name)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	driver, err := storage.Lookup(storage.DriverMemory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage.Register("memory-copy", driver)
+
+	if got := storage.Drivers(); !reflect.DeepEqual(got, []string{"file", "memory", "memory-copy", "sqlite"}) {
+		t.Fatalf("expected sorted drivers, but got %v", got)
+	}
+
+	backend, err := driver.Open(storage.Source{Data: testMemoryData()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := backend.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(keys, []string{"key1"}) {
+		t.Fatalf("expected keys %v, but got %v", []string{"key1"}, keys)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering a driver twice to panic")
+		}
+	}()
+
+	storage.Register("memory-copy", driver)
+}
@@ -1,21 +1,34 @@
 package storage
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
-	"math/rand"
+	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
+	// ErrFileChanged returns an error when the watch file changed while being written, by another writer.
+	ErrFileChanged = errors.New("watch file changed by another process")
+
 	errResourceInvalidType = errors.New("invalid resource type")
+	errLockFile            = errors.New("failed to lock watch file")
 )
 
 // File implements the storage interface, and uses a file as 'database'.
 type File struct {
 	filename string
 	key      string
+	// lenient skips the invalid resources and records of the file, instead of failing.
+	lenient bool
 }
 
 // NewFile returns a new file instance.
@@ -23,43 +36,55 @@ func NewFile(filename, key string) (*File, error) {
 	return &File{filename: filename, key: key}, nil
 }
 
+// NewLenientFile returns a new file instance, which skips the invalid resources and records of the file.
+// Skipped records are dropped from the file on the next write.
+func NewLenientFile(filename, key string) (*File, error) {
+	return &File{filename: filename, key: key, lenient: true}, nil
+}
+
 // Find all resources for the specific key.
 func (f *File) Find() ([]Resource, error) {
-	data, err := readFile(f.filename)
+	return f.FindContext(context.Background())
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (f *File) FindContext(ctx context.Context) ([]Resource, error) {
+	snapshot, err := f.load()
 	if err != nil {
 		return nil, err
 	}
 
-	if err = checkResourceKeyExists(data, f.key); err != nil {
+	if err = checkResourceKeyExists(snapshot.data, f.key); err != nil {
 		return nil, ErrResourceNotFound
 	}
 
-	return data[f.key], nil
+	return copyResourcesContext(ctx, snapshot.data[f.key])
 }
 
 // FindById a resource for the specific key.
 func (f *File) FindById(id string) (Resource, error) {
-	data, err := readFile(f.filename)
+	snapshot, err := f.load()
 	if err != nil {
 		return nil, err
 	}
 
-	if err = checkResourceKeyExists(data, f.key); err != nil {
+	if err = checkResourceKeyExists(snapshot.data, f.key); err != nil {
 		return nil, ErrResourceNotFound
 	}
 
-	for _, resource := range data[f.key] {
-		if resource["id"] == id {
-			return resource, nil
-		}
+	idx, ok := snapshot.index[f.key][id]
+	if !ok {
+		return nil, ErrResourceNotFound
 	}
 
-	return nil, ErrResourceNotFound
+	return copyResource(snapshot.data[f.key][idx]), nil
 }
 
 // Create a new resource for the specific key.
 func (f *File) Create(newResource Resource) (Resource, error) {
-	data, err := readFile(f.filename)
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +116,9 @@ func (f *File) Create(newResource Resource) (Resource, error) {
 
 // Replace an existing resource for the specific key.
 func (f *File) Replace(id string, replaced Resource) (Resource, error) {
-	data, err := readFile(f.filename)
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +154,9 @@ func (f *File) Replace(id string, replaced Resource) (Resource, error) {
 
 // Update an existing resource for the specific key.
 func (f *File) Update(id string, updatedReq Resource) (Resource, error) {
-	data, err := readFile(f.filename)
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +198,9 @@ func (f *File) Update(id string, updatedReq Resource) (Resource, error) {
 
 // Delete an existing resource for the specific key.
 func (f *File) Delete(id string) error {
-	data, err := readFile(f.filename)
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
 	if err != nil {
 		return err
 	}
@@ -199,77 +230,444 @@ func (f *File) Delete(id string) error {
 
 // DB returns all resources.
 func (f *File) DB() (Database, error) {
-	return readFile(f.filename)
+	return f.read()
+}
+
+// Transaction runs the function with a storage of the resources of the key, writing to a staged copy of the
+// watch file contents, which is written to the file at once when the function succeeds. The file stays locked
+// meanwhile.
+func (f *File) Transaction(fn func(tx Storage) error) error {
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	if err = checkResourceKeyExists(data, f.key); err != nil {
+		return ErrResourceNotFound
+	}
+
+	staged := newStagedMemoryDB(data)
+
+	if err = fn(&Memory{db: staged, key: f.key}); err != nil {
+		return err
+	}
+
+	if staged.Version() == 0 {
+		return nil
+	}
+
+	return updateFile(f.filename, staged.load().data)
+}
+
+// WriteDB replaces all resources of the watch file with the data returned by the function.
+func (f *File) WriteDB(fn func(data Database) (Database, error)) error {
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	if data, err = fn(data); err != nil {
+		return err
+	}
+
+	return updateFile(f.filename, data)
+}
+
+// AddCollection adds an empty collection to the watch file, and returns the storage of its resources.
+func (f *File) AddCollection(key string) (Storage, error) {
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := data[key]; ok {
+		return nil, ErrResourceAlreadyExists
+	}
+
+	data[key] = make([]Resource, 0)
+
+	if err := updateFile(f.filename, data); err != nil {
+		return nil, err
+	}
+
+	return &File{filename: f.filename, key: key, lenient: f.lenient}, nil
+}
+
+// DropCollection drops the collection with all its resources from the watch file.
+func (f *File) DropCollection(key string) error {
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
+	if err != nil {
+		return err
+	}
+
+	if err = checkResourceKeyExists(data, key); err != nil {
+		return ErrResourceNotFound
+	}
+
+	delete(data, key)
+
+	return updateFile(f.filename, data)
+}
+
+// RenameCollection moves the resources of the collection to a new key in the watch file, and returns
+// their storage.
+func (f *File) RenameCollection(key, newKey string) (Storage, error) {
+	defer lockFile(f.filename)()
+
+	data, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkResourceKeyExists(data, key); err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	if _, ok := data[newKey]; ok {
+		return nil, ErrResourceAlreadyExists
+	}
+
+	data[newKey] = data[key]
+	delete(data, key)
+
+	if err := updateFile(f.filename, data); err != nil {
+		return nil, err
+	}
+
+	return &File{filename: f.filename, key: newKey, lenient: f.lenient}, nil
 }
 
-// readFile returns all the data from the watch file.
-func readFile(file string) (Database, error) {
-	contentBytes, err := ioutil.ReadFile(file)
+// read returns all the data from the watch file, which callers may modify.
+func (f *File) read() (Database, error) {
+	snapshot, err := f.load()
 	if err != nil {
 		return nil, err
 	}
 
-	content := make(map[string]interface{})
-	if err = json.Unmarshal(contentBytes, &content); err != nil {
+	return copyDatabase(snapshot.data), nil
+}
+
+// racyWindow is the age a watch file must have had when parsed, for later reads to trust its modification time.
+// Timestamps are coarse, so younger files may have changed again within the same tick, keeping their size.
+const racyWindow = time.Second
+
+// fileSnapshots caches the parsed contents of each watch file, by file name and leniency, so reads only parse
+// the file again once it changed.
+var (
+	fileSnapshotsMu sync.Mutex
+	fileSnapshots   = make(map[fileSnapshotKey]*fileSnapshot)
+)
+
+type fileSnapshotKey struct {
+	filename string
+	lenient  bool
+}
+
+// fileSnapshot is the parsed contents of a watch file, which are never modified once cached.
+type fileSnapshot struct {
+	// info identifies the version of the file parsed. Writes replace the file, so they change it.
+	info os.FileInfo
+	// racy reports whether the file was too recent when parsed for its info to be trusted, so its contents
+	// are compared instead.
+	racy     bool
+	contents []byte
+	data     Database
+	// index maps the ids of the resources of every key to their position.
+	index map[string]map[string]int
+}
+
+// load returns the parsed contents of the watch file, parsing it again only if it changed since last loaded.
+func (f *File) load() (*fileSnapshot, error) {
+	info, err := os.Stat(f.filename)
+	if err != nil {
 		return nil, err
 	}
 
-	database := make(Database)
-	for key, val := range content {
-		valResources, ok := val.([]interface{})
-		if !ok {
-			return nil, errResourceInvalidType
+	key := fileSnapshotKey{filename: f.filename, lenient: f.lenient}
+
+	fileSnapshotsMu.Lock()
+	cached, ok := fileSnapshots[key]
+	fileSnapshotsMu.Unlock()
+
+	if ok && !cached.racy && sameFileVersion(cached.info, info) {
+		return cached, nil
+	}
+
+	contentBytes, err := ioutil.ReadFile(f.filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && cached.racy && bytes.Equal(cached.contents, contentBytes) {
+		// Once old enough, the unchanged contents are identified by the info again.
+		if time.Since(info.ModTime()) >= racyWindow {
+			settled := *cached
+			settled.info, settled.racy = info, false
+
+			fileSnapshotsMu.Lock()
+			fileSnapshots[key] = &settled
+			fileSnapshotsMu.Unlock()
 		}
 
-		data := make([]Resource, 0)
-		for _, resource := range valResources {
-			resourceBytes, err := json.Marshal(resource)
-			if err != nil {
-				return nil, err
-			}
+		return cached, nil
+	}
+
+	var data Database
+	if f.lenient {
+		data, _, err = ParseDatabaseLenient(contentBytes, ArrayResourceKey(f.filename))
+	} else {
+		data, err = ParseDatabase(contentBytes, ArrayResourceKey(f.filename))
+	}
 
-			var newResource Resource
-			if err := json.Unmarshal(resourceBytes, &newResource); err != nil {
-				return nil, err
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &fileSnapshot{
+		info:     info,
+		racy:     time.Since(info.ModTime()) < racyWindow,
+		contents: contentBytes,
+		data:     data,
+		index:    make(map[string]map[string]int, len(data)),
+	}
+	for resourceKey, resources := range data {
+		ids := make(map[string]int, len(resources))
+		for idx, resource := range resources {
+			// The first resource with an id wins, as with a linear search.
+			if id, ok := resource["id"].(string); ok {
+				if _, exists := ids[id]; !exists {
+					ids[id] = idx
+				}
 			}
+		}
+
+		snapshot.index[resourceKey] = ids
+	}
+
+	// The file may have changed while being read, so it is only cached if it still matches.
+	if current, err := os.Stat(f.filename); err == nil && sameFileVersion(info, current) {
+		fileSnapshotsMu.Lock()
+		fileSnapshots[key] = snapshot
+		fileSnapshotsMu.Unlock()
+	}
+
+	return snapshot, nil
+}
+
+// sameFileVersion reports whether both file infos describe the same version of the same file.
+func sameFileVersion(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// fileLocks serializes writes to each watch file, by file name.
+var (
+	fileLocksMu sync.Mutex
+	fileLocks   = make(map[string]*fileLock)
+)
 
-			data = append(data, newResource)
+// fileLock serializes the writes to a watch file, within the process and across processes.
+type fileLock struct {
+	mu sync.Mutex
+
+	// Set while the lock is held: the function unlocking the file for other processes, the error failing to
+	// lock it, which fails the write, and the file info when it was locked, to detect changes of other writers.
+	unlock func()
+	err    error
+	info   os.FileInfo
+}
+
+// lockFile locks the watch file for writing, and returns the function unlocking it. Other processes are locked
+// out as well, as long as they also lock the file.
+func lockFile(file string) func() {
+	fileLocksMu.Lock()
+	fl, ok := fileLocks[file]
+	if !ok {
+		fl = &fileLock{}
+		fileLocks[file] = fl
+	}
+	fileLocksMu.Unlock()
+
+	fl.mu.Lock()
+
+	fl.unlock, fl.err = lockProcesses(file)
+	fl.info, _ = os.Stat(file)
+
+	return func() {
+		if fl.unlock != nil {
+			fl.unlock()
 		}
 
-		database[key] = data
+		fl.unlock, fl.err, fl.info = nil, nil, nil
+		fl.mu.Unlock()
 	}
+}
+
+// checkFileLock returns an error if the watch file could not be locked, or changed since it was locked, e.g. by
+// an editor, so writes never overwrite changes they have not read. Callers must hold the lock.
+func checkFileLock(file string) error {
+	fileLocksMu.Lock()
+	fl := fileLocks[file]
+	fileLocksMu.Unlock()
 
-	return database, nil
+	if fl == nil || fl.info == nil {
+		return nil
+	}
+
+	if fl.err != nil {
+		return fmt.Errorf("%w: %v", errLockFile, fl.err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	if !os.SameFile(fl.info, info) || !fl.info.ModTime().Equal(info.ModTime()) || fl.info.Size() != info.Size() {
+		return ErrFileChanged
+	}
+
+	return nil
 }
 
-// updateFile formats and writes the new data to the watch file.
+// SaveFile writes the database to the file, e.g. a snapshot of another storage, so it can be served as a watch
+// file. An existing file is replaced, keeping its indentation and key order, or else created.
+func SaveFile(filename string, data Database) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		if err = ioutil.WriteFile(filename, nil, 0644); err != nil {
+			return err
+		}
+	}
+
+	defer lockFile(filename)()
+
+	return updateFile(filename, data)
+}
+
+// updateFile formats and writes the new data to the watch file, keeping the indentation and the key order of
+// the file. The data is written to a temporary file first, and then renamed to the watch file, so concurrent
+// reads see either the old or the new data. A symbolic link is followed, so the file it points to is replaced,
+// keeping its permissions.
 func updateFile(file string, content Database) error {
-	contentBytes, err := json.MarshalIndent(content, "", "  ")
+	atomic.AddInt64(&pendingWrites, 1)
+	defer atomic.AddInt64(&pendingWrites, -1)
+
+	target, err := filepath.EvalSymlinks(file)
 	if err != nil {
 		return err
 	}
 
-	if err = ioutil.WriteFile(file, contentBytes, 0644); err != nil {
+	contentBytes, err := readLayout(target).marshal(content, ArrayResourceKey(file))
+	if err != nil {
 		return err
 	}
 
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(target), filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err = tmpFile.Write(contentBytes); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmpFile.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if err = checkFileLock(file); err != nil {
+		return err
+	}
+
+	if err = os.Rename(tmpFile.Name(), target); err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(file); err == nil {
+		fileWritesMu.Lock()
+		fileWrites[file] = info
+		fileWritesMu.Unlock()
+	}
+
 	return nil
 }
 
-// generateNewId and validate that is unique across provided data.
-func generateNewId(data []Resource) string {
-	existingIds := make(map[string]bool)
-	for _, d := range data {
-		existingIds[d["id"].(string)] = true
+// fileWrites records the version of each watch file last written by this process, by file name.
+var (
+	fileWritesMu sync.Mutex
+	fileWrites   = make(map[string]os.FileInfo)
+)
+
+// pendingWrites counts the writes to watch files in progress.
+var pendingWrites int64
+
+// WaitWrites waits for the writes to watch files in progress to complete, e.g. of requests still served once the
+// server shuts down, so their changes are not lost on exit. It reports whether they completed within the timeout.
+func WaitWrites(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+
+	for atomic.LoadInt64(&pendingWrites) > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		time.Sleep(10 * time.Millisecond)
 	}
 
-	for {
-		newId := strconv.Itoa(rand.Intn(1000))
+	return true
+}
+
+// WrittenByProcess reports whether the version of the watch file, described by its info, was written by this
+// process, as opposed to edited by another one.
+func WrittenByProcess(filename string, info os.FileInfo) bool {
+	fileWritesMu.Lock()
+	written, ok := fileWrites[filename]
+	fileWritesMu.Unlock()
+
+	return ok && sameFileVersion(written, info)
+}
+
+// generateNewId returns an id one above the highest numeric id of the provided data, so it is unique and
+// never runs out. Non numeric ids are ignored.
+func generateNewId(data []Resource) string {
+	maxId := 0
+	for _, d := range data {
+		var id int
+		switch v := d["id"].(type) {
+		case string:
+			id, _ = strconv.Atoi(v)
+		case float64:
+			id = int(v)
+		case int:
+			id = v
+		case json.Number:
+			id, _ = strconv.Atoi(v.String())
+		}
 
-		if !existingIds[newId] {
-			return newId
+		if id > maxId {
+			maxId = id
 		}
 	}
+
+	return strconv.Itoa(maxId + 1)
 }
 
 // checkResourceKeyExists in the file data.
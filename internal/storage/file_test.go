@@ -7,8 +7,11 @@ import (
 	"io/ioutil"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -712,6 +715,270 @@ func TestDB(t *testing.T) {
 	}
 }
 
+func TestCreate_Symlink(t *testing.T) {
+	f, err := testGenerateStorageFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	if err = os.Chmod(f.Name(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	link := f.Name() + ".link"
+	if err = os.Symlink(filepath.Base(f.Name()), link); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(link)
+
+	storageSvc, err := storage.NewFile(link, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = storageSvc.Create(storage.Resource{"id": "symlink"}); err != nil {
+		t.Fatal(err)
+	}
+
+	linkInfo, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if linkInfo.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected symbolic link to be kept, but got mode %v", linkInfo.Mode())
+	}
+
+	info, err := os.Stat(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("expected file mode %v, but got %v", os.FileMode(0600), info.Mode().Perm())
+	}
+
+	if _, err = storageSvc.FindById("symlink"); err != nil {
+		t.Fatalf("expected created resource, but got error %v", err)
+	}
+}
+
+func TestCreate_SharedFile(t *testing.T) {
+	f, err := testGenerateStorageFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	// Writers of the same file by different names only share the lock of the file, like other processes.
+	names := []string{f.Name(), "./" + f.Name()}
+
+	const creates = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(names)*creates)
+
+	for _, name := range names {
+		storageSvc, err := storage.NewFile(name, keys[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for idx := 0; idx < creates; idx++ {
+			wg.Add(1)
+			go func(id string) {
+				defer wg.Done()
+
+				_, err := storageSvc.Create(storage.Resource{"id": id})
+				errs <- err
+			}(fmt.Sprintf("%s-%d", name, idx))
+		}
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	storageSvc, err := storage.NewFile(f.Name(), keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := storageSvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := len(testData[keys[0]]) + len(names)*creates; len(resources) != expected {
+		t.Fatalf("expected %v resources, but got %v", expected, len(resources))
+	}
+}
+
+func TestCreate_Layout(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "Spaces with trailing newline",
+			content:  "{\n    \"posts\": [\n        {\n            \"title\": \"first\",\n            \"id\": \"1\"\n        }\n    ],\n    \"comments\": []\n}\n",
+			expected: "{\n    \"posts\": [\n        {\n            \"title\": \"first\",\n            \"id\": \"1\"\n        },\n        {\n            \"title\": \"second\",\n            \"id\": \"2\",\n            \"body\": \"text\",\n            \"tags\": [\n                \"a\"\n            ]\n        }\n    ],\n    \"comments\": []\n}\n",
+		},
+		{
+			name:     "Tabs without trailing newline",
+			content:  "{\n\t\"comments\": [],\n\t\"posts\": [\n\t\t{\"id\": \"1\", \"title\": \"first\"}\n\t]\n}",
+			expected: "{\n\t\"comments\": [],\n\t\"posts\": [\n\t\t{\n\t\t\t\"id\": \"1\",\n\t\t\t\"title\": \"first\"\n\t\t},\n\t\t{\n\t\t\t\"id\": \"2\",\n\t\t\t\"title\": \"second\",\n\t\t\t\"body\": \"text\",\n\t\t\t\"tags\": [\n\t\t\t\t\"a\"\n\t\t\t]\n\t\t}\n\t]\n}",
+		},
+		{
+			name:     "Compact",
+			content:  `{"posts":[{"title":"first","id":"1"}],"comments":[]}`,
+			expected: `{"posts":[{"title":"first","id":"1"},{"title":"second","id":"2","body":"text","tags":["a"]}],"comments":[]}`,
+		},
+	}
+
+	for _, tt := range testCases {
+		f, err := ioutil.TempFile(".", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+
+		if err = ioutil.WriteFile(f.Name(), []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		storageSvc, err := storage.NewFile(f.Name(), "posts")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = storageSvc.Create(storage.Resource{"body": "text", "id": "2", "tags": []interface{}{"a"}, "title": "second"}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := ioutil.ReadFile(f.Name())
+		os.Remove(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != tt.expected {
+			t.Fatalf("%s: expected content %q, but got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestCreate_ArrayFile(t *testing.T) {
+	f, err := ioutil.TempFile(".", "users-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	if err = ioutil.WriteFile(f.Name(), []byte("[\n  {\n    \"id\": \"1\",\n    \"name\": \"first\"\n  }\n]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key := storage.ArrayResourceKey(f.Name())
+	if !strings.HasPrefix(key, "users-") || strings.HasSuffix(key, ".json") {
+		t.Fatalf("expected resource key from the file name, but got %s", key)
+	}
+
+	storageSvc, err := storage.NewFile(f.Name(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = storageSvc.Create(storage.Resource{"id": "2", "name": "second"}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[\n  {\n    \"id\": \"1\",\n    \"name\": \"first\"\n  },\n  {\n    \"id\": \"2\",\n    \"name\": \"second\"\n  }\n]\n"
+	if string(got) != expected {
+		t.Fatalf("expected content %q, but got %q", expected, got)
+	}
+
+	resources, err := storageSvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("expected 2 resources, but got %v", resources)
+	}
+}
+
+func TestFindById_ExternalChange(t *testing.T) {
+	f, err := ioutil.TempFile(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	storageSvc, err := storage.NewFile(f.Name(), "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rewrite the file in place with contents of the same size, so only the contents tell the versions apart.
+	for _, title := range []string{"first", "other", "third"} {
+		content := fmt.Sprintf(`{"posts": [{"id": "1", "title": %q}]}`, title)
+		if err = ioutil.WriteFile(f.Name(), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := storageSvc.FindById("1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got["title"] != title {
+			t.Fatalf("expected title %q, but got %v", title, got["title"])
+		}
+	}
+}
+
+func TestFindById_Copy(t *testing.T) {
+	f, err := testGenerateStorageFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	storageSvc, err := storage.NewFile(f.Name(), keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storageSvc.FindById("0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got["field_1"] = "modified"
+
+	if got, err = storageSvc.FindById("0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["field_1"] == "modified" {
+		t.Fatal("expected returned resources not to share the cached ones")
+	}
+}
+
 func testGenerateStorageFile() (*os.File, error) {
 	f, err := ioutil.TempFile(".", "")
 	if err != nil {
@@ -761,3 +1028,52 @@ func testResetData(filename string) error {
 
 	return nil
 }
+
+func TestTransaction(t *testing.T) {
+	f, err := testGenerateStorageFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	storageSvc, err := storage.NewFile(f.Name(), "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failing transaction leaves the file untouched.
+	err = storage.Transaction(storageSvc, func(tx storage.Storage) error {
+		if _, err := tx.Create(storage.Resource{"id": "new"}); err != nil {
+			return err
+		}
+
+		_, err := tx.Create(storage.Resource{"id": "0"})
+		return err
+	})
+	if !errors.Is(err, storage.ErrResourceAlreadyExists) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceAlreadyExists, err)
+	}
+
+	if _, err = storageSvc.FindById("new"); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+
+	err = storage.Transaction(storageSvc, func(tx storage.Storage) error {
+		if _, err := tx.Create(storage.Resource{"id": "new"}); err != nil {
+			return err
+		}
+
+		return tx.Delete("0")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = storageSvc.FindById("new"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = storageSvc.FindById("0"); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+}
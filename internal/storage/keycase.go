@@ -0,0 +1,349 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Supported key cases.
+const (
+	KeyCaseCamel = "camel"
+	KeyCaseSnake = "snake"
+	KeyCaseKebab = "kebab"
+)
+
+var (
+	// ErrUnknownKeyCase returns an error when a key case is not supported.
+	ErrUnknownKeyCase = errors.New("unknown key case, expected camel, snake or kebab")
+	// ErrConflictingFields returns an error when fields of a resource only differ in case.
+	ErrConflictingFields = errors.New("fields differ only in case")
+)
+
+// KeyCase implements the storage interface, by wrapping another storage. It converts the field names of
+// all returned resources, including nested ones, to the key case. Field names of written resources are
+// accepted in any case, and mapped to the stored field names.
+type KeyCase struct {
+	storage Storage
+	keyCase string
+}
+
+// NewKeyCase returns a new key case instance, converting field names to the provided key case. Unknown key
+// cases leave field names untouched.
+func NewKeyCase(storageSvc Storage, keyCase string) *KeyCase {
+	return &KeyCase{storage: storageSvc, keyCase: keyCase}
+}
+
+// ValidateKeyCase returns an error if the key case is not supported.
+func ValidateKeyCase(keyCase string) error {
+	switch keyCase {
+	case KeyCaseCamel, KeyCaseSnake, KeyCaseKebab:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownKeyCase, keyCase)
+}
+
+// Find all resources for the specific key.
+func (k *KeyCase) Find() ([]Resource, error) {
+	return k.FindContext(context.Background())
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (k *KeyCase) FindContext(ctx context.Context) ([]Resource, error) {
+	resources, err := FindContext(ctx, k.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.convertAll(resources), nil
+}
+
+// FindById a resource for the specific key.
+func (k *KeyCase) FindById(id string) (Resource, error) {
+	return k.convert(k.storage.FindById(id))
+}
+
+// Create a new resource for the specific key. Field names are mapped to the ones of existing resources.
+func (k *KeyCase) Create(newResource Resource) (Resource, error) {
+	existing, err := k.storage.Find()
+	if err != nil {
+		return nil, err
+	}
+
+	mapped, err := mapKeys(newResource, existing...)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.convert(k.storage.Create(mapped))
+}
+
+// Replace an existing resource for the specific key. Field names are mapped to the ones of the
+// replaced resource.
+func (k *KeyCase) Replace(id string, replaced Resource) (Resource, error) {
+	existing, _ := k.storage.FindById(id)
+
+	mapped, err := mapKeys(replaced, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.convert(k.storage.Replace(id, mapped))
+}
+
+// Update an existing resource for the specific key. Field names are mapped to the ones of the
+// updated resource.
+func (k *KeyCase) Update(id string, updatedReq Resource) (Resource, error) {
+	existing, _ := k.storage.FindById(id)
+
+	mapped, err := mapKeys(updatedReq, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.convert(k.storage.Update(id, mapped))
+}
+
+// Delete an existing resource for the specific key.
+func (k *KeyCase) Delete(id string) error {
+	return k.storage.Delete(id)
+}
+
+// DB returns all resources.
+func (k *KeyCase) DB() (Database, error) {
+	data, err := k.storage.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make(Database, len(data))
+	for key, resources := range data {
+		converted[key] = k.convertAll(resources)
+	}
+
+	return converted, nil
+}
+
+// Transaction runs the function with the storage of the transaction, converting field names the same way.
+func (k *KeyCase) Transaction(fn func(tx Storage) error) error {
+	return Transaction(k.storage, func(tx Storage) error {
+		return fn(NewKeyCase(tx, k.keyCase))
+	})
+}
+
+func (k *KeyCase) convert(resource Resource, err error) (Resource, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return Resource(convertKeys(map[string]interface{}(resource), k.keyCase)), nil
+}
+
+func (k *KeyCase) convertAll(resources []Resource) []Resource {
+	converted := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		converted = append(converted, Resource(convertKeys(map[string]interface{}(resource), k.keyCase)))
+	}
+
+	return converted
+}
+
+// convertKeys returns a copy of the object, with the field names of it and its nested objects converted.
+func convertKeys(obj map[string]interface{}, keyCase string) map[string]interface{} {
+	converted := make(map[string]interface{}, len(obj))
+	for key, val := range obj {
+		converted[ConvertKey(key, keyCase)] = convertValue(val, keyCase)
+	}
+
+	return converted
+}
+
+func convertValue(val interface{}, keyCase string) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return convertKeys(v, keyCase)
+	case []interface{}:
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			list = append(list, convertValue(item, keyCase))
+		}
+
+		return list
+	}
+
+	return val
+}
+
+// mapKeys renames the fields of the written resource, including the ones of nested objects, to the stored
+// field names of the existing resources, which only differ in case. Other fields are kept as is. Fields
+// which only differ in case from each other are rejected, as they would be returned as the same field.
+func mapKeys(resource Resource, existing ...Resource) (Resource, error) {
+	objs := make([]map[string]interface{}, 0, len(existing))
+	for _, e := range existing {
+		objs = append(objs, e)
+	}
+
+	mapped, err := mapObjectKeys(resource, objs)
+	if err != nil {
+		return nil, err
+	}
+
+	return Resource(mapped), nil
+}
+
+func mapObjectKeys(obj map[string]interface{}, existing []map[string]interface{}) (map[string]interface{}, error) {
+	stored := make(map[string]string)
+	storedValues := make(map[string][]interface{})
+	for _, e := range existing {
+		for key, val := range e {
+			folded := foldKey(key)
+			stored[folded] = key
+			storedValues[folded] = append(storedValues[folded], val)
+		}
+	}
+
+	written := make(map[string]string, len(obj))
+	mapped := make(map[string]interface{}, len(obj))
+	for key, val := range obj {
+		folded := foldKey(key)
+		if other, ok := written[folded]; ok {
+			names := []string{other, key}
+			sort.Strings(names)
+
+			return nil, fmt.Errorf("%w: %s", ErrConflictingFields, strings.Join(names, ", "))
+		}
+
+		written[folded] = key
+
+		mappedVal, err := mapValueKeys(val, storedValues[folded])
+		if err != nil {
+			return nil, err
+		}
+
+		if storedKey, ok := stored[folded]; ok {
+			key = storedKey
+		}
+
+		mapped[key] = mappedVal
+	}
+
+	return mapped, nil
+}
+
+func mapValueKeys(val interface{}, existing []interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return mapObjectKeys(v, nestedObjects(existing))
+	case []interface{}:
+		objs := nestedObjects(existing)
+
+		list := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			if obj, ok := item.(map[string]interface{}); ok {
+				mappedObj, err := mapObjectKeys(obj, objs)
+				if err != nil {
+					return nil, err
+				}
+
+				item = mappedObj
+			}
+
+			list = append(list, item)
+		}
+
+		return list, nil
+	}
+
+	return val, nil
+}
+
+// nestedObjects returns the objects among the values, including the ones in lists.
+func nestedObjects(values []interface{}) []map[string]interface{} {
+	objs := make([]map[string]interface{}, 0)
+	for _, val := range values {
+		switch v := val.(type) {
+		case map[string]interface{}:
+			objs = append(objs, v)
+		case []interface{}:
+			objs = append(objs, nestedObjects(v)...)
+		}
+	}
+
+	return objs
+}
+
+// foldKey returns the form of a field name shared by all its cases, e.g. 'firstname' for 'firstName'
+// and 'first_name'. Field names without words, like '_', are kept as is.
+func foldKey(key string) string {
+	words := splitWords(key)
+	if len(words) == 0 {
+		return key
+	}
+
+	return strings.ToLower(strings.Join(words, ""))
+}
+
+// ConvertKey converts the field name to the key case, e.g. 'first_name' to 'firstName' for the camel case.
+func ConvertKey(key, keyCase string) string {
+	words := splitWords(key)
+	if len(words) == 0 {
+		return key
+	}
+
+	switch keyCase {
+	case KeyCaseCamel:
+		for idx, word := range words {
+			word = strings.ToLower(word)
+			if idx > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+
+			words[idx] = word
+		}
+
+		return strings.Join(words, "")
+	case KeyCaseSnake:
+		return strings.ToLower(strings.Join(words, "_"))
+	case KeyCaseKebab:
+		return strings.ToLower(strings.Join(words, "-"))
+	}
+
+	return key
+}
+
+// splitWords splits a field name into words, on separators and case changes, e.g. 'userID' into 'user'
+// and 'ID', and 'HTTPStatus' into 'HTTP' and 'Status'.
+func splitWords(key string) []string {
+	runes := []rune(key)
+	words := make([]string, 0)
+
+	start := 0
+	for idx := 0; idx <= len(runes); idx++ {
+		if idx == len(runes) || runes[idx] == '_' || runes[idx] == '-' || unicode.IsSpace(runes[idx]) {
+			if idx > start {
+				words = append(words, string(runes[start:idx]))
+			}
+
+			start = idx + 1
+			continue
+		}
+
+		if idx == start || !unicode.IsUpper(runes[idx]) {
+			continue
+		}
+
+		// A new word starts at an upper case letter following a lower case one, or at the last upper
+		// case letter of an acronym followed by a lower case one.
+		prev := runes[idx-1]
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && idx+1 < len(runes) && unicode.IsLower(runes[idx+1])) {
+			words = append(words, string(runes[start:idx]))
+			start = idx
+		}
+	}
+
+	return words
+}
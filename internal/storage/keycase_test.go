@@ -0,0 +1,108 @@
+package storage_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestConvertKey(t *testing.T) {
+	testCases := []struct {
+		key      string
+		keyCase  string
+		expected string
+	}{
+		{key: "first_name", keyCase: storage.KeyCaseCamel, expected: "firstName"},
+		{key: "first-name", keyCase: storage.KeyCaseCamel, expected: "firstName"},
+		{key: "userID", keyCase: storage.KeyCaseSnake, expected: "user_id"},
+		{key: "HTTPStatus", keyCase: storage.KeyCaseKebab, expected: "http-status"},
+		{key: "address2Line", keyCase: storage.KeyCaseSnake, expected: "address2_line"},
+		{key: "id", keyCase: storage.KeyCaseCamel, expected: "id"},
+	}
+
+	for _, tt := range testCases {
+		if got := storage.ConvertKey(tt.key, tt.keyCase); got != tt.expected {
+			t.Fatalf("expected %q for %q in %s case, but got %q", tt.expected, tt.key, tt.keyCase, got)
+		}
+	}
+
+	if err := storage.ValidateKeyCase("pascal"); !errors.Is(err, storage.ErrUnknownKeyCase) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrUnknownKeyCase, err)
+	}
+}
+
+func TestKeyCase(t *testing.T) {
+	memorySvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"users": {{"id": "1", "first_name": "John", "home_address": map[string]interface{}{"zip_code": "12345"}}},
+	}), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storageSvc := storage.NewKeyCase(memorySvc, storage.KeyCaseCamel)
+
+	got, err := storageSvc.FindById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := storage.Resource{"id": "1", "firstName": "John", "homeAddress": map[string]interface{}{"zipCode": "12345"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected resource %v, but got %v", expected, got)
+	}
+
+	// Field names in any case are mapped to the stored ones.
+	if _, err = storageSvc.Update("1", storage.Resource{"firstName": "Jane"}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := memorySvc.FindById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored["first_name"] != "Jane" || len(stored) != 3 {
+		t.Fatalf("expected stored field first_name to be updated, but got %v", stored)
+	}
+
+	// Nested field names are mapped as well.
+	if _, err = storageSvc.Update("1", storage.Resource{"homeAddress": map[string]interface{}{"zipCode": "54321"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if stored, err = memorySvc.FindById("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedAddress := map[string]interface{}{"zip_code": "54321"}
+	if !reflect.DeepEqual(stored["home_address"], expectedAddress) {
+		t.Fatalf("expected stored field home_address %v, but got %v", expectedAddress, stored["home_address"])
+	}
+
+	// Field names which only differ in case are rejected.
+	testCases := []struct {
+		name     string
+		resource storage.Resource
+	}{
+		{
+			name:     "Conflicting fields",
+			resource: storage.Resource{"first_name": "Jane", "firstName": "John"},
+		},
+		{
+			name:     "Conflicting nested fields",
+			resource: storage.Resource{"home_address": map[string]interface{}{"zip_code": "1", "zipCode": "2"}},
+		},
+		{
+			name:     "Conflicting fields of nested lists",
+			resource: storage.Resource{"tags": []interface{}{map[string]interface{}{"tag_name": "a", "TagName": "b"}}},
+		},
+	}
+
+	for _, tt := range testCases {
+		if _, err = storageSvc.Update("1", tt.resource); !errors.Is(err, storage.ErrConflictingFields) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, storage.ErrConflictingFields, err)
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// defaultIndent is the indentation of watch files without any, e.g. empty ones.
+const defaultIndent = "  "
+
+// layout holds the formatting of a watch file, so writes keep it: the indentation, the trailing newline, and
+// the order of the object keys.
+type layout struct {
+	// indent is empty for compact files.
+	indent  string
+	newline bool
+	order   *keyOrder
+	// array is set for files holding an array of resources at the root.
+	array bool
+}
+
+// keyOrder holds the order of the keys of an object, and of the objects nested in it.
+type keyOrder struct {
+	keys   []string
+	fields map[string]*keyOrder
+	// id is the id of the object, as read from the file.
+	id string
+	// items holds the order of the objects of an array by their id, and item the order of its first object,
+	// which new objects follow.
+	items map[string]*keyOrder
+	item  *keyOrder
+}
+
+// readLayout returns the layout of the watch file. Files that cannot be parsed have the default layout, with
+// keys in alphabetical order.
+func readLayout(file string) layout {
+	l := layout{indent: defaultIndent}
+
+	contentBytes, err := ioutil.ReadFile(file)
+	if err != nil {
+		return l
+	}
+
+	trimmed := bytes.TrimSpace(contentBytes)
+	if len(trimmed) == 0 {
+		return l
+	}
+
+	order, _, err := readKeyOrder(json.NewDecoder(bytes.NewReader(trimmed)))
+	if err != nil {
+		return l
+	}
+
+	l.order = order
+	l.array = trimmed[0] == '['
+	l.newline = bytes.HasSuffix(contentBytes, []byte("\n"))
+	l.indent = detectIndent(trimmed)
+
+	return l
+}
+
+// detectIndent returns the leading whitespace of the first indented line, which is one level deep.
+func detectIndent(contentBytes []byte) string {
+	for _, line := range strings.Split(string(contentBytes), "\n")[1:] {
+		line = strings.TrimRight(line, "\r")
+		if indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]; indent != "" {
+			return indent
+		}
+	}
+
+	if bytes.IndexByte(contentBytes, '\n') < 0 {
+		return ""
+	}
+
+	return defaultIndent
+}
+
+// readKeyOrder reads the next json value, and returns the order of its keys, if it is an object or an array,
+// and the value itself, if it is a string or a number.
+func readKeyOrder(decoder *json.Decoder) (*keyOrder, string, error) {
+	decoder.UseNumber()
+
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		order := &keyOrder{fields: make(map[string]*keyOrder)}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, "", err
+			}
+
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, "", errResourceInvalidType
+			}
+
+			field, val, err := readKeyOrder(decoder)
+			if err != nil {
+				return nil, "", err
+			}
+
+			order.keys = append(order.keys, key)
+			if field != nil {
+				order.fields[key] = field
+			}
+
+			if key == "id" {
+				order.id = val
+			}
+		}
+
+		// Consume the closing delimiter.
+		if _, err = decoder.Token(); err != nil {
+			return nil, "", err
+		}
+
+		return order, "", nil
+	case json.Delim('['):
+		order := &keyOrder{items: make(map[string]*keyOrder)}
+		for decoder.More() {
+			item, _, err := readKeyOrder(decoder)
+			if err != nil {
+				return nil, "", err
+			}
+
+			if item == nil || item.keys == nil {
+				continue
+			}
+
+			if order.item == nil {
+				order.item = item
+			}
+
+			if item.id != "" {
+				order.items[item.id] = item
+			}
+		}
+
+		if _, err = decoder.Token(); err != nil {
+			return nil, "", err
+		}
+
+		return order, "", nil
+	}
+
+	switch v := token.(type) {
+	case string:
+		return nil, v, nil
+	case json.Number:
+		return nil, v.String(), nil
+	}
+
+	return nil, "", nil
+}
+
+// field returns the order of the object nested under the key.
+func (o *keyOrder) field(key string) *keyOrder {
+	if o == nil {
+		return nil
+	}
+
+	return o.fields[key]
+}
+
+// itemOf returns the order of the array object with the id, or of the first array object for new ones.
+func (o *keyOrder) itemOf(val interface{}) *keyOrder {
+	if o == nil {
+		return nil
+	}
+
+	if obj, ok := val.(map[string]interface{}); ok {
+		if id, ok := obj["id"]; ok && id != nil {
+			if item, ok := o.items[fmt.Sprint(id)]; ok {
+				return item
+			}
+		}
+	}
+
+	return o.item
+}
+
+// sortKeys returns the keys of the object in the order of the file, with new keys last, in alphabetical order.
+func (o *keyOrder) sortKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	seen := make(map[string]bool, len(obj))
+
+	if o != nil {
+		for _, key := range o.keys {
+			if _, ok := obj[key]; ok && !seen[key] {
+				keys = append(keys, key)
+				seen[key] = true
+			}
+		}
+	}
+
+	rest := make([]string, 0, len(obj)-len(keys))
+	for key := range obj {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+// marshal encodes the database in the layout. Files holding an array at the root keep holding the array, as long
+// as the database only has the resource with the array key.
+func (l layout) marshal(content Database, arrayKey string) ([]byte, error) {
+	data := make(map[string]interface{}, len(content))
+	for key, resources := range content {
+		items := make([]interface{}, 0, len(resources))
+		for _, resource := range resources {
+			items = append(items, map[string]interface{}(resource))
+		}
+
+		data[key] = items
+	}
+
+	var root interface{} = data
+	if _, ok := data[arrayKey]; ok && l.array && len(data) == 1 {
+		root = data[arrayKey]
+	}
+
+	buf := &bytes.Buffer{}
+	if err := l.encode(buf, root, l.order, 0); err != nil {
+		return nil, err
+	}
+
+	if l.newline {
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (l layout) encode(w *bytes.Buffer, val interface{}, order *keyOrder, depth int) error {
+	switch v := val.(type) {
+	case Resource:
+		return l.encode(w, map[string]interface{}(v), order, depth)
+	case map[string]interface{}:
+		if len(v) == 0 {
+			w.WriteString("{}")
+			return nil
+		}
+
+		w.WriteByte('{')
+		for idx, key := range order.sortKeys(v) {
+			if idx > 0 {
+				w.WriteByte(',')
+			}
+
+			l.writeNewline(w, depth+1)
+
+			keyBytes, err := json.Marshal(key)
+			if err != nil {
+				return err
+			}
+
+			w.Write(keyBytes)
+			w.WriteByte(':')
+			if l.indent != "" {
+				w.WriteByte(' ')
+			}
+
+			if err = l.encode(w, v[key], order.field(key), depth+1); err != nil {
+				return err
+			}
+		}
+
+		l.writeNewline(w, depth)
+		w.WriteByte('}')
+	case []interface{}:
+		if len(v) == 0 {
+			w.WriteString("[]")
+			return nil
+		}
+
+		w.WriteByte('[')
+		for idx, item := range v {
+			if idx > 0 {
+				w.WriteByte(',')
+			}
+
+			l.writeNewline(w, depth+1)
+
+			if err := l.encode(w, item, order.itemOf(item), depth+1); err != nil {
+				return err
+			}
+		}
+
+		l.writeNewline(w, depth)
+		w.WriteByte(']')
+	default:
+		valBytes, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+
+		// Other objects and arrays, e.g. of structs, are decoded to be encoded in the layout as well.
+		if len(valBytes) > 0 && (valBytes[0] == '{' || valBytes[0] == '[') {
+			var generic interface{}
+			if err = Unmarshal(valBytes, &generic); err != nil {
+				return err
+			}
+
+			return l.encode(w, generic, order, depth)
+		}
+
+		w.Write(valBytes)
+	}
+
+	return nil
+}
+
+func (l layout) writeNewline(w *bytes.Buffer, depth int) {
+	if l.indent == "" {
+		return
+	}
+
+	w.WriteByte('\n')
+	w.WriteString(strings.Repeat(l.indent, depth))
+}
@@ -0,0 +1,35 @@
+//go:build !windows
+// +build !windows
+
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockProcesses takes an advisory lock on the directory of the watch file, so the writes of other processes
+// serving the same file wait for it, and returns the function unlocking it. The directory is locked, rather
+// than the file, as writes replace the file.
+func lockProcesses(file string) (func(), error) {
+	target, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.Open(filepath.Dir(target))
+	if err != nil {
+		return nil, err
+	}
+
+	if err = syscall.Flock(int(dir.Fd()), syscall.LOCK_EX); err != nil {
+		dir.Close()
+		return nil, err
+	}
+
+	return func() {
+		syscall.Flock(int(dir.Fd()), syscall.LOCK_UN)
+		dir.Close()
+	}, nil
+}
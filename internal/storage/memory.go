@@ -0,0 +1,419 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// MemoryDB holds the database contents shared by all in-memory storage instances. Contents are copy-on-write:
+// every write publishes a new snapshot, so reads never block and always see a consistent state, even while
+// concurrent writes proceed. Snapshots are not kept, each read sees the latest one.
+type MemoryDB struct {
+	// version counts the published snapshots, read atomically. It comes first to be 64-bit aligned.
+	version uint64
+	// mu serializes writes.
+	mu       sync.Mutex
+	snapshot atomic.Value
+}
+
+// memorySnapshot is an immutable version of the database contents. Resources and collections are never
+// modified once published, writes replace them instead.
+type memorySnapshot struct {
+	data Database
+}
+
+// NewMemoryDB returns a new in-memory database, initialized with a copy of the provided data.
+func NewMemoryDB(data Database) *MemoryDB {
+	db := &MemoryDB{}
+	db.snapshot.Store(&memorySnapshot{data: copyDatabase(data)})
+
+	return db
+}
+
+// newStagedMemoryDB returns an in-memory database holding the data without copying it, to stage the writes of a
+// transaction. The data must not be modified by others while staged, as the writes share the unchanged resources.
+func newStagedMemoryDB(data Database) *MemoryDB {
+	db := &MemoryDB{}
+	db.snapshot.Store(&memorySnapshot{data: data})
+
+	return db
+}
+
+func (db *MemoryDB) load() *memorySnapshot {
+	return db.snapshot.Load().(*memorySnapshot)
+}
+
+// publish replaces the database contents, with the data of a new version. Callers must hold the write lock.
+func (db *MemoryDB) publish(data Database) {
+	db.snapshot.Store(&memorySnapshot{data: data})
+	atomic.AddUint64(&db.version, 1)
+}
+
+// Version returns the version of the database contents, changed on every write. Comparing versions tells whether
+// the contents changed since, e.g. since they were last persisted.
+func (db *MemoryDB) Version() uint64 {
+	return atomic.LoadUint64(&db.version)
+}
+
+// write replaces the collection of the key, with the result of the function. Callers must not modify
+// the provided collection.
+func (db *MemoryDB) write(key string, fn func(resources []Resource) ([]Resource, error)) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current := db.load()
+
+	resources, ok := current.data[key]
+	if !ok {
+		return ErrResourceNotFound
+	}
+
+	newResources, err := fn(resources)
+	if err != nil {
+		return err
+	}
+
+	// Only the changed collection is replaced, the rest are shared with the previous snapshot.
+	data := make(Database, len(current.data))
+	for k, v := range current.data {
+		data[k] = v
+	}
+
+	data[key] = newResources
+
+	db.publish(data)
+
+	return nil
+}
+
+// Keys returns the keys of the collections, sorted.
+func (db *MemoryDB) Keys() ([]string, error) {
+	data := db.load().data
+
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys, nil
+}
+
+// Storage returns the storage of the resources of the key, or of all resources if empty.
+func (db *MemoryDB) Storage(key string) (Storage, error) {
+	return NewMemory(db, key)
+}
+
+// AddCollection adds an empty collection, and returns the storage of its resources.
+func (db *MemoryDB) AddCollection(key string) (Storage, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current := db.load()
+	if _, ok := current.data[key]; ok {
+		return nil, ErrResourceAlreadyExists
+	}
+
+	data := make(Database, len(current.data)+1)
+	for k, v := range current.data {
+		data[k] = v
+	}
+
+	data[key] = make([]Resource, 0)
+
+	db.publish(data)
+
+	return NewMemory(db, key)
+}
+
+// DropCollection drops the collection with all its resources.
+func (db *MemoryDB) DropCollection(key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current := db.load()
+	if _, ok := current.data[key]; !ok {
+		return ErrResourceNotFound
+	}
+
+	data := make(Database, len(current.data))
+	for k, v := range current.data {
+		if k != key {
+			data[k] = v
+		}
+	}
+
+	db.publish(data)
+
+	return nil
+}
+
+// RenameCollection moves the resources of the collection to a new key, and returns their storage.
+func (db *MemoryDB) RenameCollection(key, newKey string) (Storage, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	current := db.load()
+	if _, ok := current.data[key]; !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	if _, ok := current.data[newKey]; ok {
+		return nil, ErrResourceAlreadyExists
+	}
+
+	data := make(Database, len(current.data))
+	for k, v := range current.data {
+		if k == key {
+			k = newKey
+		}
+
+		data[k] = v
+	}
+
+	db.publish(data)
+
+	return NewMemory(db, newKey)
+}
+
+// Memory implements the storage interface, and keeps all resources in memory.
+// It is safe for concurrent use.
+type Memory struct {
+	db  *MemoryDB
+	key string
+}
+
+// NewMemory returns a new memory instance.
+func NewMemory(db *MemoryDB, key string) (*Memory, error) {
+	return &Memory{db: db, key: key}, nil
+}
+
+// Find all resources for the specific key.
+func (m *Memory) Find() ([]Resource, error) {
+	return m.FindContext(context.Background())
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (m *Memory) FindContext(ctx context.Context) ([]Resource, error) {
+	data := m.db.load().data
+
+	if err := checkResourceKeyExists(data, m.key); err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	return copyResourcesContext(ctx, data[m.key])
+}
+
+// FindById a resource for the specific key.
+func (m *Memory) FindById(id string) (Resource, error) {
+	resources := m.db.load().data[m.key]
+
+	idx, err := indexOf(resources, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return copyResource(resources[idx]), nil
+}
+
+// Create a new resource for the specific key.
+func (m *Memory) Create(newResource Resource) (Resource, error) {
+	err := m.db.write(m.key, func(resources []Resource) ([]Resource, error) {
+		_, ok := newResource["id"]
+		if !ok {
+			newResource["id"] = generateNewId(resources)
+		} else {
+			for _, resource := range resources {
+				if resource["id"] == newResource["id"] {
+					return nil, ErrResourceAlreadyExists
+				}
+			}
+		}
+
+		newResources := make([]Resource, 0, len(resources)+1)
+		newResources = append(newResources, resources...)
+
+		return append(newResources, copyResource(newResource)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newResource, nil
+}
+
+// Replace an existing resource for the specific key.
+func (m *Memory) Replace(id string, replaced Resource) (Resource, error) {
+	err := m.db.write(m.key, func(resources []Resource) ([]Resource, error) {
+		idx, err := indexOf(resources, id)
+		if err != nil {
+			return nil, err
+		}
+
+		replaced["id"] = id
+
+		return replaceAt(resources, idx, copyResource(replaced)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// Update an existing resource for the specific key.
+func (m *Memory) Update(id string, updatedReq Resource) (Resource, error) {
+	var updated Resource
+
+	err := m.db.write(m.key, func(resources []Resource) ([]Resource, error) {
+		idx, err := indexOf(resources, id)
+		if err != nil {
+			return nil, err
+		}
+
+		// Apply any changes to a copy of the current resource.
+		updated = copyResource(resources[idx])
+		for key, val := range updatedReq {
+			updated[key] = val
+		}
+
+		updated["id"] = id
+
+		return replaceAt(resources, idx, updated), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copyResource(updated), nil
+}
+
+// Delete an existing resource for the specific key.
+func (m *Memory) Delete(id string) error {
+	return m.db.write(m.key, func(resources []Resource) ([]Resource, error) {
+		idx, err := indexOf(resources, id)
+		if err != nil {
+			return nil, err
+		}
+
+		newResources := make([]Resource, 0, len(resources)-1)
+		newResources = append(newResources, resources[:idx]...)
+
+		return append(newResources, resources[idx+1:]...), nil
+	})
+}
+
+// DB returns all resources.
+func (m *Memory) DB() (Database, error) {
+	return copyDatabase(m.db.load().data), nil
+}
+
+// Transaction runs the function with a storage of the resources of the key, writing to a staged version of the
+// database, which is published as a whole once the function succeeds.
+func (m *Memory) Transaction(fn func(tx Storage) error) error {
+	m.db.mu.Lock()
+	defer m.db.mu.Unlock()
+
+	staged := newStagedMemoryDB(m.db.load().data)
+
+	if err := fn(&Memory{db: staged, key: m.key}); err != nil {
+		return err
+	}
+
+	if staged.Version() > 0 {
+		m.db.publish(staged.load().data)
+	}
+
+	return nil
+}
+
+// WriteDB replaces all resources with the data returned by the function.
+func (m *Memory) WriteDB(fn func(data Database) (Database, error)) error {
+	m.db.mu.Lock()
+	defer m.db.mu.Unlock()
+
+	data, err := fn(copyDatabase(m.db.load().data))
+	if err != nil {
+		return err
+	}
+
+	m.db.publish(copyDatabase(data))
+
+	return nil
+}
+
+// indexOf returns the position of the resource with the requested id.
+func indexOf(resources []Resource, id string) (int, error) {
+	if resources == nil {
+		return 0, ErrResourceNotFound
+	}
+
+	for idx, resource := range resources {
+		if resource["id"] == id {
+			return idx, nil
+		}
+	}
+
+	return 0, ErrResourceNotFound
+}
+
+// replaceAt returns a copy of the resources, with the resource at the position replaced.
+func replaceAt(resources []Resource, idx int, resource Resource) []Resource {
+	newResources := make([]Resource, len(resources))
+	copy(newResources, resources)
+	newResources[idx] = resource
+
+	return newResources
+}
+
+// copyResource returns a shallow copy of the resource.
+func copyResource(resource Resource) Resource {
+	newResource := make(Resource, len(resource))
+	for key, val := range resource {
+		newResource[key] = val
+	}
+
+	return newResource
+}
+
+// copyResources returns a copy of the resources.
+func copyResources(resources []Resource) []Resource {
+	newResources := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		newResources = append(newResources, copyResource(resource))
+	}
+
+	return newResources
+}
+
+// contextCheckInterval is the number of resources copied between checks whether the context is done.
+const contextCheckInterval = 1024
+
+// copyResourcesContext returns a copy of the resources, failing with the error of the context once it is done.
+func copyResourcesContext(ctx context.Context, resources []Resource) ([]Resource, error) {
+	newResources := make([]Resource, 0, len(resources))
+	for idx, resource := range resources {
+		if idx%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		newResources = append(newResources, copyResource(resource))
+	}
+
+	return newResources, nil
+}
+
+// copyDatabase returns a copy of the database.
+func copyDatabase(data Database) Database {
+	newData := make(Database, len(data))
+	for key, resources := range data {
+		newData[key] = copyResources(resources)
+	}
+
+	return newData
+}
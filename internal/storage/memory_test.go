@@ -0,0 +1,340 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func testMemoryData() storage.Database {
+	return storage.Database{
+		"key1": {
+			{"id": "1", "field_1": "field_1-key1-1"},
+			{"id": "2", "field_1": "field_1-key1-2"},
+		},
+	}
+}
+
+func TestMemoryCRUD(t *testing.T) {
+	db := storage.NewMemoryDB(testMemoryData())
+
+	storageSvc, err := storage.NewMemory(db, "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := storageSvc.Create(storage.Resource{"field_1": "new-field_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := created["id"].(string)
+
+	if _, err = storageSvc.Create(storage.Resource{"id": id}); !errors.Is(err, storage.ErrResourceAlreadyExists) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceAlreadyExists, err)
+	}
+
+	updated, err := storageSvc.Update(id, storage.Resource{"field_2": "new-field_2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := storage.Resource{"id": id, "field_1": "new-field_1", "field_2": "new-field_2"}
+	if !reflect.DeepEqual(updated, expected) {
+		t.Fatalf("expected updated %v, but got %v", expected, updated)
+	}
+
+	replaced, err := storageSvc.Replace(id, storage.Resource{"field_3": "new-field_3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = storage.Resource{"id": id, "field_3": "new-field_3"}
+	if !reflect.DeepEqual(replaced, expected) {
+		t.Fatalf("expected replaced %v, but got %v", expected, replaced)
+	}
+
+	if err = storageSvc.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = storageSvc.FindById(id); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+
+	got, err := storageSvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expectedData := testMemoryData()["key1"]; !reflect.DeepEqual(got, expectedData) {
+		t.Fatalf("expected data %v, but got %v", expectedData, got)
+	}
+}
+
+func TestMemoryIsolation(t *testing.T) {
+	data := testMemoryData()
+	db := storage.NewMemoryDB(data)
+
+	storageSvc, err := storage.NewMemory(db, "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mutating returned or provided resources must not alter the stored ones.
+	got, err := storageSvc.FindById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got["field_1"] = "mutated"
+	data["key1"][1]["field_1"] = "mutated"
+
+	resources, err := storageSvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expectedData := testMemoryData()["key1"]; !reflect.DeepEqual(resources, expectedData) {
+		t.Fatalf("expected data %v, but got %v", expectedData, resources)
+	}
+
+	invalidSvc, err := storage.NewMemory(db, "randomKey")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = invalidSvc.Find(); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+}
+
+func TestMemorySnapshots(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{"key1": {{"id": "1", "a": 0, "b": 0}}})
+
+	storageSvc, err := storage.NewMemory(db, "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const writes = 500
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := 1; i <= writes; i++ {
+			if _, err := storageSvc.Update("1", storage.Resource{"a": i, "b": i}); err != nil {
+				t.Error(err)
+				return
+			}
+
+			if _, err := storageSvc.Create(storage.Resource{"id": fmt.Sprintf("new-%d", i)}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	// Reads never see a partially applied write, and never go back to an older state.
+	for reading, count := true, 0; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+
+		resources, err := storageSvc.Find()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resources[0]["a"] != resources[0]["b"] {
+			t.Fatalf("expected equal fields, but got %v", resources[0])
+		}
+
+		if len(resources) < count {
+			t.Fatalf("expected at least %d resources, but got %d", count, len(resources))
+		}
+
+		count = len(resources)
+	}
+
+	if resources, _ := storageSvc.Find(); len(resources) != writes+1 {
+		t.Fatalf("expected %d resources, but got %d", writes+1, len(resources))
+	}
+}
+
+func TestMemoryAddCollection(t *testing.T) {
+	db := storage.NewMemoryDB(testMemoryData())
+
+	storageSvc, err := db.AddCollection("key2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = storageSvc.Create(storage.Resource{"id": "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = db.AddCollection("key1"); !errors.Is(err, storage.ErrResourceAlreadyExists) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceAlreadyExists, err)
+	}
+
+	data, err := storageSvc.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(data["key1"]) != 2 || len(data["key2"]) != 1 {
+		t.Fatalf("expected the new collection next to the existing ones, but got %v", data)
+	}
+}
+
+func TestMemoryTransaction(t *testing.T) {
+	db := storage.NewMemoryDB(testMemoryData())
+
+	storageSvc, err := storage.NewMemory(db, "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A failing transaction discards all its writes.
+	err = storage.Transaction(storageSvc, func(tx storage.Storage) error {
+		if _, err := tx.Create(storage.Resource{"id": "3"}); err != nil {
+			return err
+		}
+
+		if err := tx.Delete("1"); err != nil {
+			return err
+		}
+
+		_, err := tx.Create(storage.Resource{"id": "2"})
+		return err
+	})
+	if !errors.Is(err, storage.ErrResourceAlreadyExists) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceAlreadyExists, err)
+	}
+
+	got, err := storageSvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || got[0]["id"] != "1" {
+		t.Fatalf("expected resources without the writes of the failed transaction, but got %v", got)
+	}
+
+	err = storage.Transaction(storageSvc, func(tx storage.Storage) error {
+		if _, err := tx.Create(storage.Resource{"id": "3"}); err != nil {
+			return err
+		}
+
+		return tx.Delete("1")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err = storageSvc.Find(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 2 || got[0]["id"] != "2" || got[1]["id"] != "3" {
+		t.Fatalf("expected resources with the writes of the transaction, but got %v", got)
+	}
+}
+
+func TestMemoryWriteDB(t *testing.T) {
+	db := storage.NewMemoryDB(testMemoryData())
+
+	dbSvc, err := storage.NewMemory(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = storage.WriteDB(dbSvc, func(data storage.Database) (storage.Database, error) {
+		data["key1"] = []storage.Resource{{"id": "9"}}
+		return data, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storageSvc, err := storage.NewMemory(db, "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := storageSvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []storage.Resource{{"id": "9"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected resources %v, but got %v", expected, got)
+	}
+}
+
+func TestMemoryFindContext(t *testing.T) {
+	memorySvc, err := storage.NewMemory(storage.NewMemoryDB(testMemoryData()), "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Storages wrapping another storage find with the context as well.
+	storageSvc := storage.NewPrivate(storage.NewCapped(memorySvc, 10, storage.EvictFIFO), "key1",
+		map[string][]string{"key1": {"field_1"}})
+
+	got, err := storage.FindContext(context.Background(), storageSvc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []storage.Resource{{"id": "1"}, {"id": "2"}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected resources %v, but got %v", expected, got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err = storage.FindContext(ctx, storageSvc); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error %v, but got %v", context.Canceled, err)
+	}
+
+	if _, err = memorySvc.FindContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error %v, but got %v", context.Canceled, err)
+	}
+
+	// Nested collections find with the context as well.
+	usersSvc, err := storage.NewMemory(storage.NewMemoryDB(storage.Database{
+		"users": {{"id": "1", "orders": []interface{}{map[string]interface{}{"id": "1"}}}},
+	}), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := storage.NewNested(usersSvc).Collection("1", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	finder, ok := collection.(storage.ContextFinder)
+	if !ok {
+		t.Fatal("expected nested collection to find with the context, but it does not")
+	}
+
+	if got, err = finder.FindContext(context.Background()); err != nil || len(got) != 1 {
+		t.Fatalf("expected 1 nested resource, but got %v and error %v", got, err)
+	}
+
+	if _, err = finder.FindContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected error %v, but got %v", context.Canceled, err)
+	}
+}
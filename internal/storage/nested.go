@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Nested provides the storages of the collections nested in the resources of another storage, i.e. the arrays of
+// objects held by their fields, e.g. the 'orders' of a user. Writes to a nested collection update the field of the
+// parent resource.
+type Nested struct {
+	storage Storage
+	// mu serializes the writes, which read and update the whole nested collection.
+	mu sync.Mutex
+}
+
+// nestedCollection implements the storage interface for the collection nested in the field of a parent resource.
+type nestedCollection struct {
+	nested   *Nested
+	parentId string
+	field    string
+}
+
+// NewNested returns a new nested instance, for the collections nested in the resources of the storage.
+func NewNested(storageSvc Storage) *Nested {
+	return &Nested{storage: storageSvc}
+}
+
+// Collection returns the storage of the collection nested in the field of the parent resource. It fails with
+// ErrResourceNotFound if the parent resource does not exist, or its field does not hold an array of objects.
+func (n *Nested) Collection(parentId, field string) (Storage, error) {
+	collection := &nestedCollection{nested: n, parentId: parentId, field: field}
+	if _, err := collection.items(); err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// Find all resources of the nested collection.
+func (nc *nestedCollection) Find() ([]Resource, error) {
+	return nc.FindContext(context.Background())
+}
+
+// FindContext finds all resources of the nested collection, failing with the error of the context once it is done.
+func (nc *nestedCollection) FindContext(ctx context.Context) ([]Resource, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items, err := nc.items()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// FindById a resource of the nested collection. Ids are compared by their string representation, as nested
+// resources often have numeric ids.
+func (nc *nestedCollection) FindById(id string) (Resource, error) {
+	items, err := nc.items()
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := nestedIndexOf(items, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return items[idx], nil
+}
+
+// Create a new resource in the nested collection.
+func (nc *nestedCollection) Create(newResource Resource) (Resource, error) {
+	nc.nested.mu.Lock()
+	defer nc.nested.mu.Unlock()
+
+	items, err := nc.items()
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := newResource["id"]
+	if !ok {
+		newResource["id"] = generateNewId(items)
+	} else if _, err = nestedIndexOf(items, fmt.Sprint(id)); err == nil {
+		return nil, ErrResourceAlreadyExists
+	}
+
+	if err = nc.write(append(items, copyResource(newResource))); err != nil {
+		return nil, err
+	}
+
+	return newResource, nil
+}
+
+// Replace an existing resource of the nested collection. The resource keeps its id, with its type.
+func (nc *nestedCollection) Replace(id string, replaced Resource) (Resource, error) {
+	nc.nested.mu.Lock()
+	defer nc.nested.mu.Unlock()
+
+	items, err := nc.items()
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := nestedIndexOf(items, id)
+	if err != nil {
+		return nil, err
+	}
+
+	replaced["id"] = items[idx]["id"]
+	items[idx] = copyResource(replaced)
+
+	if err = nc.write(items); err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// Update an existing resource of the nested collection. The resource keeps its id, with its type.
+func (nc *nestedCollection) Update(id string, updatedReq Resource) (Resource, error) {
+	nc.nested.mu.Lock()
+	defer nc.nested.mu.Unlock()
+
+	items, err := nc.items()
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := nestedIndexOf(items, id)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := items[idx]
+	for key, val := range updatedReq {
+		if key != "id" {
+			updated[key] = val
+		}
+	}
+
+	if err = nc.write(items); err != nil {
+		return nil, err
+	}
+
+	return copyResource(updated), nil
+}
+
+// Delete an existing resource of the nested collection.
+func (nc *nestedCollection) Delete(id string) error {
+	nc.nested.mu.Lock()
+	defer nc.nested.mu.Unlock()
+
+	items, err := nc.items()
+	if err != nil {
+		return err
+	}
+
+	idx, err := nestedIndexOf(items, id)
+	if err != nil {
+		return err
+	}
+
+	return nc.write(append(items[:idx], items[idx+1:]...))
+}
+
+// DB returns the database of the parent resources.
+func (nc *nestedCollection) DB() (Database, error) {
+	return nc.nested.storage.DB()
+}
+
+// items returns copies of the resources of the nested collection, so they can be modified before writing them.
+func (nc *nestedCollection) items() ([]Resource, error) {
+	parent, err := nc.nested.storage.FindById(nc.parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := parent[nc.field].([]interface{})
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	items := make([]Resource, 0, len(values))
+	for _, val := range values {
+		obj, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, ErrResourceNotFound
+		}
+
+		items = append(items, copyResource(obj))
+	}
+
+	return items, nil
+}
+
+// write updates the field of the parent resource with the resources of the nested collection.
+func (nc *nestedCollection) write(items []Resource) error {
+	values := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		values = append(values, map[string]interface{}(item))
+	}
+
+	_, err := nc.nested.storage.Update(nc.parentId, Resource{nc.field: values})
+
+	return err
+}
+
+// nestedIndexOf returns the position of the resource with the id, comparing ids by their string representation.
+func nestedIndexOf(resources []Resource, id string) (int, error) {
+	for idx, resource := range resources {
+		if val, ok := resource["id"]; ok && val != nil && fmt.Sprint(val) == id {
+			return idx, nil
+		}
+	}
+
+	return 0, ErrResourceNotFound
+}
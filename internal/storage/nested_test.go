@@ -0,0 +1,87 @@
+package storage_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestNested(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"users": {
+			{"id": "1", "name": "John", "orders": []interface{}{
+				map[string]interface{}{"id": float64(1), "total": float64(10)},
+				map[string]interface{}{"id": float64(2), "total": float64(20)},
+			}},
+			{"id": "2", "name": "Jane", "orders": "none"},
+		},
+	})
+
+	storageSvc, err := storage.NewMemory(db, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nested := storage.NewNested(storageSvc)
+
+	for _, parentId := range []string{"2", "3"} {
+		if _, err = nested.Collection(parentId, "orders"); !errors.Is(err, storage.ErrResourceNotFound) {
+			t.Fatalf("expected error %v for parent %s, but got %v", storage.ErrResourceNotFound, parentId, err)
+		}
+	}
+
+	orders, err := nested.Collection("1", "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := orders.FindById("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if order["total"] != float64(20) {
+		t.Fatalf("expected total 20, but got %v", order["total"])
+	}
+
+	created, err := orders.Create(storage.Resource{"total": float64(30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created["id"] != "3" {
+		t.Fatalf("expected generated id 3, but got %v", created["id"])
+	}
+
+	if _, err = orders.Create(storage.Resource{"id": "1"}); !errors.Is(err, storage.ErrResourceAlreadyExists) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceAlreadyExists, err)
+	}
+
+	updated, err := orders.Update("1", storage.Resource{"id": "5", "total": float64(15)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated["id"] != float64(1) || updated["total"] != float64(15) {
+		t.Fatalf("expected order 1 with total 15, but got %v", updated)
+	}
+
+	if err = orders.Delete("2"); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := storageSvc.FindById("1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	items, ok := user["orders"].([]interface{})
+	if !ok || len(items) != 2 {
+		t.Fatalf("expected 2 orders in the parent resource, but got %v", user["orders"])
+	}
+
+	if user["name"] != "John" {
+		t.Fatalf("expected the other fields of the parent resource to be kept, but got %v", user)
+	}
+}
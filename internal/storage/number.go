@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+var errTrailingData = errors.New("invalid character after top-level value")
+
+// Unmarshal parses the json data into v, like json.Unmarshal, keeping the precision of numbers.
+func Unmarshal(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if err := Decode(decoder, v); err != nil {
+		return err
+	}
+
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errTrailingData
+	}
+
+	return nil
+}
+
+// Decode reads the next json value of the decoder into v, keeping the precision of numbers. Numbers are decoded
+// as float64, unless a float64 cannot hold them exactly, e.g. large integers or high-precision decimals, which
+// are kept as json.Number and so encoded back verbatim.
+func Decode(decoder *json.Decoder, v interface{}) error {
+	decoder.UseNumber()
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	switch v := v.(type) {
+	case *Resource:
+		preciseNumbers(map[string]interface{}(*v))
+	case *map[string]interface{}:
+		preciseNumbers(*v)
+	case *[]interface{}:
+		preciseNumbers(*v)
+	case *interface{}:
+		*v = preciseNumbers(*v)
+	}
+
+	return nil
+}
+
+// preciseNumbers converts the json numbers of a decoded value, in place.
+func preciseNumbers(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = preciseNumbers(item)
+		}
+	case []interface{}:
+		for idx, item := range v {
+			v[idx] = preciseNumbers(item)
+		}
+	case json.Number:
+		return preciseNumber(v)
+	}
+
+	return val
+}
+
+// preciseNumber returns the number as float64, if its shortest representation has the same value.
+func preciseNumber(n json.Number) interface{} {
+	f, err := n.Float64()
+	if err != nil {
+		return n
+	}
+
+	exact, ok := new(big.Rat).SetString(n.String())
+	if !ok {
+		return n
+	}
+
+	shortest, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok || exact.Cmp(shortest) != 0 {
+		return n
+	}
+
+	return f
+}
@@ -0,0 +1,62 @@
+package storage_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestUnmarshal(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		err      bool
+	}{
+		{name: "Small numbers", input: `{"count": 3, "price": 19.99, "ratio": 0.1}`, expected: `{"count":3,"price":19.99,"ratio":0.1}`},
+		{name: "Large integer", input: `{"id": 12345678901234567890}`, expected: `{"id":12345678901234567890}`},
+		{name: "Integer beyond float precision", input: `{"id": 9007199254740993}`, expected: `{"id":9007199254740993}`},
+		{name: "High-precision decimal", input: `{"amount": 1234567.123456789012345}`, expected: `{"amount":1234567.123456789012345}`},
+		{name: "Nested numbers", input: `{"items": [{"total": 0.30000000000000000001}]}`, expected: `{"items":[{"total":0.30000000000000000001}]}`},
+		{name: "Out of range", input: `{"big": 1e400}`, expected: `{"big":1e400}`},
+		{name: "Trailing data", input: `{"id": 1} {}`, err: true},
+		{name: "Invalid json", input: `{"id": }`, err: true},
+	}
+
+	for _, tt := range testCases {
+		var resource storage.Resource
+		err := storage.Unmarshal([]byte(tt.input), &resource)
+		if (err != nil) != tt.err {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if tt.err {
+			continue
+		}
+
+		got, err := json.Marshal(resource)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(got) != tt.expected {
+			t.Fatalf("%s: expected %s, but got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestUnmarshal_Float64(t *testing.T) {
+	var resource storage.Resource
+	if err := storage.Unmarshal([]byte(`{"price": 19.99, "id": 12345678901234567890}`), &resource); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := resource["price"].(float64); !ok {
+		t.Fatalf("expected float64 for exact numbers, but got %T", resource["price"])
+	}
+
+	if _, ok := resource["id"].(json.Number); !ok {
+		t.Fatalf("expected json number for inexact numbers, but got %T", resource["id"])
+	}
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrInvalidRecord returns an error when a record of the watch file is invalid.
+	ErrInvalidRecord = errors.New("invalid record")
+
+	errInvalidJSON      = errors.New("invalid json")
+	errInvalidDatabase  = errors.New("content must be an object of resources")
+	errResourceNotArray = errors.New("resource must be an array of records")
+	errRecordNotObject  = errors.New("record must be an object")
+	errInvalidRecordId  = errors.New("id must be a string or a number")
+)
+
+// ParseDatabase decodes json content into a database. Every top level value must be an array of resources,
+// which must be objects. Content holding an array of resources at the root is the single resource with the array
+// key, unless empty. Errors point to the line and column of the invalid json, resource or record.
+func ParseDatabase(contentBytes []byte, arrayKey string) (Database, error) {
+	database, invalid, err := parseDatabase(contentBytes, arrayKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(invalid) > 0 {
+		return nil, invalid[0]
+	}
+
+	return database, nil
+}
+
+// ParseDatabaseLenient decodes json content into a database like ParseDatabase, but skips the invalid resources
+// and records, and returns their errors instead. Invalid json still fails.
+func ParseDatabaseLenient(contentBytes []byte, arrayKey string) (Database, []error, error) {
+	return parseDatabase(contentBytes, arrayKey)
+}
+
+// ArrayResourceKey returns the resource key of a file holding an array of resources at the root, which is the
+// file name without extension, e.g. 'users' for 'data/users.json'.
+func ArrayResourceKey(filename string) string {
+	base := filepath.Base(filename)
+
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+func parseDatabase(contentBytes []byte, arrayKey string) (Database, []error, error) {
+	// Unmarshal reports the offset of syntax errors within the whole content, unlike a decoder.
+	var content interface{}
+	if err := json.Unmarshal(contentBytes, &content); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := position(contentBytes, syntaxErr.Offset-1)
+			return nil, nil, fmt.Errorf("%w: line %d, column %d: %v", errInvalidJSON, line, column, err)
+		}
+
+		return nil, nil, fmt.Errorf("%w: %v", errInvalidJSON, err)
+	}
+
+	database := make(Database)
+	if content == nil {
+		return database, nil, nil
+	}
+
+	if _, ok := content.([]interface{}); ok && arrayKey != "" {
+		resources, invalid, err := parseRecords(contentBytes, arrayKey, skipSeparators(contentBytes, 0))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		database[arrayKey] = resources
+
+		return database, invalid, nil
+	}
+
+	if _, ok := content.(map[string]interface{}); !ok {
+		return nil, nil, errInvalidDatabase
+	}
+
+	var invalid []error
+
+	decoder := json.NewDecoder(bytes.NewReader(contentBytes))
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	for decoder.More() {
+		keyToken, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key := keyToken.(string)
+
+		start := skipSeparators(contentBytes, decoder.InputOffset())
+
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+
+		if raw[0] != '[' {
+			line, column := position(contentBytes, start)
+			invalid = append(invalid, fmt.Errorf("%w: %s at line %d, column %d: %v", errResourceInvalidType, key, line, column, errResourceNotArray))
+			continue
+		}
+
+		resources, recordErrs, err := parseRecords(contentBytes, key, start)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		database[key] = resources
+		invalid = append(invalid, recordErrs...)
+	}
+
+	return database, invalid, nil
+}
+
+// parseRecords decodes the records of the resource array at the offset, skipping the invalid ones.
+func parseRecords(contentBytes []byte, key string, offset int64) ([]Resource, []error, error) {
+	decoder := json.NewDecoder(bytes.NewReader(contentBytes[offset:]))
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	resources := make([]Resource, 0)
+	var invalid []error
+
+	for idx := 0; decoder.More(); idx++ {
+		start := skipSeparators(contentBytes, offset+decoder.InputOffset())
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+
+		resource, err := parseRecord(raw)
+		if err != nil {
+			line, column := position(contentBytes, start)
+			invalid = append(invalid, fmt.Errorf("%w: %s[%d] at line %d, column %d: %v", ErrInvalidRecord, key, idx, line, column, err))
+			continue
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, invalid, nil
+}
+
+func parseRecord(raw json.RawMessage) (Resource, error) {
+	if raw[0] != '{' {
+		return nil, errRecordNotObject
+	}
+
+	var resource Resource
+	if err := Unmarshal(raw, &resource); err != nil {
+		return nil, err
+	}
+
+	switch resource["id"].(type) {
+	case nil:
+		if _, ok := resource["id"]; ok {
+			return nil, errInvalidRecordId
+		}
+	case string, float64, json.Number:
+	default:
+		return nil, errInvalidRecordId
+	}
+
+	return resource, nil
+}
+
+// skipSeparators returns the offset of the next value, after any whitespace, colon or comma.
+func skipSeparators(contentBytes []byte, offset int64) int64 {
+	for offset < int64(len(contentBytes)) {
+		switch contentBytes[offset] {
+		case ' ', '\t', '\r', '\n', ':', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+
+	return offset
+}
+
+// position returns the line and column, both starting at 1, of the offset.
+func position(contentBytes []byte, offset int64) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset > int64(len(contentBytes)) {
+		offset = int64(len(contentBytes))
+	}
+
+	before := contentBytes[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
@@ -0,0 +1,94 @@
+package storage_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+const testMalformedContent = `{
+  "posts": [
+    {"id": "1", "title": "first"},
+    "second",
+    {"id": {"value": 3}},
+    {"id": 4, "title": "fourth"}
+  ],
+  "profile": {"name": "typicode"},
+  "comments": []
+}`
+
+func TestParseDatabase(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{name: "Valid content", content: `{"posts": [{"id": "1"}], "comments": []}`},
+		{name: "Empty content", content: `null`},
+		{name: "Invalid json", content: "{\n  \"posts\": [\n    {\"id\": 1,}\n  ]\n}", err: "line 3, column 14"},
+		{name: "Not an object", content: `"posts"`, err: "content must be an object of resources"},
+		{name: "Array without key", content: `[{"id": "1"}]`, err: "content must be an object of resources"},
+		{name: "Invalid record", content: testMalformedContent, err: "posts[1] at line 4, column 5: record must be an object"},
+		{name: "Invalid resource", content: `{"profile": {"name": "typicode"}}`, err: "profile at line 1, column 13: resource must be an array of records"},
+		{name: "Invalid record id", content: `{"posts": [{"id": true}]}`, err: "posts[0] at line 1, column 12: id must be a string or a number"},
+	}
+
+	for _, tt := range testCases {
+		_, err := storage.ParseDatabase([]byte(tt.content), "")
+		if tt.err == "" {
+			if err != nil {
+				t.Fatalf("%s: expected no error, but got %v", tt.name, err)
+			}
+
+			continue
+		}
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Fatalf("%s: expected error containing %q, but got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestParseDatabaseLenient(t *testing.T) {
+	database, skipped, err := storage.ParseDatabaseLenient([]byte(testMalformedContent), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(skipped) != 3 {
+		t.Fatalf("expected 3 skipped records, but got %v", skipped)
+	}
+
+	if !errors.Is(skipped[0], storage.ErrInvalidRecord) || !errors.Is(skipped[1], storage.ErrInvalidRecord) {
+		t.Fatalf("expected invalid record errors, but got %v", skipped)
+	}
+
+	if _, ok := database["profile"]; ok {
+		t.Fatalf("expected invalid resource to be skipped, but got %v", database["profile"])
+	}
+
+	if len(database["posts"]) != 2 || database["posts"][0]["id"] != "1" || database["posts"][1]["id"] != float64(4) {
+		t.Fatalf("expected the valid posts, but got %v", database["posts"])
+	}
+
+	if _, _, err = storage.ParseDatabaseLenient([]byte(`{"posts": [`), ""); err == nil {
+		t.Fatal("expected error for invalid json, but got none")
+	}
+}
+
+func TestParseDatabase_Array(t *testing.T) {
+	database, err := storage.ParseDatabase([]byte(`[{"id": "1"}, {"id": "2"}]`), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(database) != 1 || len(database["users"]) != 2 {
+		t.Fatalf("expected the users resource, but got %v", database)
+	}
+
+	if _, err = storage.ParseDatabase([]byte("[\n  {\"id\": \"1\"},\n  true\n]"), "users"); err == nil || !strings.Contains(err.Error(), "users[1] at line 3, column 3") {
+		t.Fatalf("expected invalid record error, but got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import "context"
+
+// Private implements the storage interface, by wrapping another storage. It strips private fields from
+// all returned resources, while keeping them in the wrapped storage.
+type Private struct {
+	storage Storage
+	key     string
+	fields  map[string][]string
+}
+
+// NewPrivate returns a new private instance for the resource key. The fields map holds the private fields
+// of each resource key, and is used as a whole for the db contents.
+func NewPrivate(storageSvc Storage, key string, fields map[string][]string) *Private {
+	return &Private{storage: storageSvc, key: key, fields: fields}
+}
+
+// Find all resources for the specific key.
+func (p *Private) Find() ([]Resource, error) {
+	return p.FindContext(context.Background())
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (p *Private) FindContext(ctx context.Context) ([]Resource, error) {
+	resources, err := FindContext(ctx, p.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.stripAll(resources, p.fields[p.key]), nil
+}
+
+// FindById a resource for the specific key.
+func (p *Private) FindById(id string) (Resource, error) {
+	return p.strip(p.storage.FindById(id))
+}
+
+// Create a new resource for the specific key.
+func (p *Private) Create(newResource Resource) (Resource, error) {
+	return p.strip(p.storage.Create(newResource))
+}
+
+// Replace an existing resource for the specific key.
+func (p *Private) Replace(id string, replaced Resource) (Resource, error) {
+	return p.strip(p.storage.Replace(id, replaced))
+}
+
+// Update an existing resource for the specific key.
+func (p *Private) Update(id string, updatedReq Resource) (Resource, error) {
+	return p.strip(p.storage.Update(id, updatedReq))
+}
+
+// Delete an existing resource for the specific key.
+func (p *Private) Delete(id string) error {
+	return p.storage.Delete(id)
+}
+
+// DB returns all resources.
+func (p *Private) DB() (Database, error) {
+	data, err := p.storage.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	stripped := make(Database, len(data))
+	for key, resources := range data {
+		stripped[key] = p.stripAll(resources, p.fields[key])
+	}
+
+	return stripped, nil
+}
+
+// Transaction runs the function with the storage of the transaction, stripping the same fields.
+func (p *Private) Transaction(fn func(tx Storage) error) error {
+	return Transaction(p.storage, func(tx Storage) error {
+		return fn(NewPrivate(tx, p.key, p.fields))
+	})
+}
+
+func (p *Private) strip(resource Resource, err error) (Resource, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return stripFields(resource, p.fields[p.key]), nil
+}
+
+func (p *Private) stripAll(resources []Resource, fields []string) []Resource {
+	stripped := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		stripped = append(stripped, stripFields(resource, fields))
+	}
+
+	return stripped
+}
+
+// stripFields returns a copy of the resource without the fields. The resource itself is left untouched,
+// as it might be shared with the wrapped storage.
+func stripFields(resource Resource, fields []string) Resource {
+	if len(fields) == 0 {
+		return resource
+	}
+
+	stripped := copyResource(resource)
+	for _, field := range fields {
+		delete(stripped, field)
+	}
+
+	return stripped
+}
@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownRelation returns an error when resources are embedded or expanded with a resource which does not exist.
+var ErrUnknownRelation = errors.New("unknown relation")
+
+// Relations resolves the relationships between the resources of different storages, by their foreign keys. The
+// foreign key of a resource is its singular followed by 'Id', e.g. the 'postId' of the comments of a post.
+type Relations struct {
+	resources map[string]Storage
+}
+
+// relatedCollection implements the storage interface for the resources of a storage whose foreign key points at
+// a parent resource, e.g. the comments of a post.
+type relatedCollection struct {
+	storage    Storage
+	foreignKey string
+	parentId   interface{}
+}
+
+// NewRelations returns a new relations instance, for the resources of the storages by key.
+func NewRelations(resources map[string]Storage) *Relations {
+	return &Relations{resources: resources}
+}
+
+// ForeignKey returns the field of the resources pointing at a resource of the key, e.g. 'postId' for 'posts'.
+func ForeignKey(resourceKey string) string {
+	return singular(resourceKey) + "Id"
+}
+
+// Embed returns copies of the resources of the key, with the resources of the child key pointing at them in the
+// field named after the child key, e.g. the 'comments' of posts.
+func (rl *Relations) Embed(resourceKey string, resources []Resource, childKey string) ([]Resource, error) {
+	childSvc, ok := rl.resources[childKey]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRelation, childKey)
+	}
+
+	children, err := childSvc.Find()
+	if err != nil {
+		return nil, err
+	}
+
+	foreignKey := ForeignKey(resourceKey)
+
+	byParent := make(map[string][]Resource)
+	for _, child := range children {
+		if val, ok := child[foreignKey]; ok && val != nil {
+			byParent[fmt.Sprint(val)] = append(byParent[fmt.Sprint(val)], child)
+		}
+	}
+
+	embedded := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		resource = copyResource(resource)
+
+		related := make([]Resource, 0)
+		if id, ok := resource["id"]; ok && id != nil {
+			related = append(related, byParent[fmt.Sprint(id)]...)
+		}
+
+		resource[childKey] = related
+		embedded = append(embedded, resource)
+	}
+
+	return embedded, nil
+}
+
+// Expand returns copies of the resources, with the parent resource their foreign key points at in the field named
+// after the parent, e.g. the 'post' of comments. Resources whose parent does not exist are not expanded.
+func (rl *Relations) Expand(resources []Resource, parent string) ([]Resource, error) {
+	var parentSvc Storage
+	for resourceKey, storageSvc := range rl.resources {
+		if singular(resourceKey) == parent && resourceKey != parent {
+			parentSvc = storageSvc
+			break
+		}
+	}
+
+	if parentSvc == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRelation, parent)
+	}
+
+	parents, err := parentSvc.Find()
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]Resource, len(parents))
+	for _, resource := range parents {
+		if id, ok := resource["id"]; ok && id != nil {
+			byId[fmt.Sprint(id)] = resource
+		}
+	}
+
+	foreignKey := parent + "Id"
+
+	expanded := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		resource = copyResource(resource)
+
+		if val, ok := resource[foreignKey]; ok && val != nil {
+			if related, ok := byId[fmt.Sprint(val)]; ok {
+				resource[parent] = related
+			}
+		}
+
+		expanded = append(expanded, resource)
+	}
+
+	return expanded, nil
+}
+
+// Children returns the storage of the resources of the child key pointing at the parent resource, e.g. the
+// comments of a post. It fails with ErrResourceNotFound if either resource does not exist.
+func (rl *Relations) Children(parentKey, parentId, childKey string) (Storage, error) {
+	parentSvc, ok := rl.resources[parentKey]
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+
+	childSvc, ok := rl.resources[childKey]
+	if !ok || childKey == parentKey {
+		return nil, ErrResourceNotFound
+	}
+
+	parent, err := parentSvc.FindById(parentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &relatedCollection{storage: childSvc, foreignKey: ForeignKey(parentKey), parentId: parent["id"]}, nil
+}
+
+// Find all resources pointing at the parent resource.
+func (rc *relatedCollection) Find() ([]Resource, error) {
+	return rc.FindContext(context.Background())
+}
+
+// FindContext finds all resources pointing at the parent resource, failing with the error of the context once it
+// is done.
+func (rc *relatedCollection) FindContext(ctx context.Context) ([]Resource, error) {
+	resources, err := FindContext(ctx, rc.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	related := make([]Resource, 0)
+	for _, resource := range resources {
+		if rc.belongs(resource) {
+			related = append(related, resource)
+		}
+	}
+
+	return related, nil
+}
+
+// FindById a resource pointing at the parent resource.
+func (rc *relatedCollection) FindById(id string) (Resource, error) {
+	resource, err := rc.storage.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rc.belongs(resource) {
+		return nil, ErrResourceNotFound
+	}
+
+	return resource, nil
+}
+
+// Create a new resource pointing at the parent resource.
+func (rc *relatedCollection) Create(newResource Resource) (Resource, error) {
+	newResource[rc.foreignKey] = rc.parentId
+
+	return rc.storage.Create(newResource)
+}
+
+// Replace an existing resource pointing at the parent resource. The resource keeps pointing at it.
+func (rc *relatedCollection) Replace(id string, replaced Resource) (Resource, error) {
+	if _, err := rc.FindById(id); err != nil {
+		return nil, err
+	}
+
+	replaced[rc.foreignKey] = rc.parentId
+
+	return rc.storage.Replace(id, replaced)
+}
+
+// Update an existing resource pointing at the parent resource. The resource keeps pointing at it.
+func (rc *relatedCollection) Update(id string, updatedReq Resource) (Resource, error) {
+	if _, err := rc.FindById(id); err != nil {
+		return nil, err
+	}
+
+	if _, ok := updatedReq[rc.foreignKey]; ok {
+		updatedReq[rc.foreignKey] = rc.parentId
+	}
+
+	return rc.storage.Update(id, updatedReq)
+}
+
+// Delete an existing resource pointing at the parent resource.
+func (rc *relatedCollection) Delete(id string) error {
+	if _, err := rc.FindById(id); err != nil {
+		return err
+	}
+
+	return rc.storage.Delete(id)
+}
+
+// DB returns all resources.
+func (rc *relatedCollection) DB() (Database, error) {
+	return rc.storage.DB()
+}
+
+// belongs reports whether the foreign key of the resource points at the parent resource. Ids are compared by
+// their string representation, as foreign keys often hold numeric ids.
+func (rc *relatedCollection) belongs(resource Resource) bool {
+	val, ok := resource[rc.foreignKey]
+
+	return ok && val != nil && fmt.Sprint(val) == fmt.Sprint(rc.parentId)
+}
+
+// singular returns the singular of the english plural, e.g. 'category' of 'categories'.
+func singular(word string) string {
+	switch {
+	case strings.HasSuffix(word, "ies") && len(word) > 3:
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "ss"):
+		return word
+	case strings.HasSuffix(word, "s") && len(word) > 1:
+		return strings.TrimSuffix(word, "s")
+	}
+
+	return word
+}
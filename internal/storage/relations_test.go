@@ -0,0 +1,104 @@
+package storage_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestRelations(t *testing.T) {
+	db := storage.NewMemoryDB(storage.Database{
+		"categories": {{"id": float64(1), "name": "news"}},
+		"posts":      {{"id": "1", "categoryId": float64(1)}, {"id": "2"}},
+		"comments":   {{"id": "1", "postId": "1"}, {"id": "2", "postId": "2"}},
+	})
+
+	resources := make(map[string]storage.Storage)
+	for _, resourceKey := range []string{"categories", "posts", "comments"} {
+		storageSvc, err := storage.NewMemory(db, resourceKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resources[resourceKey] = storageSvc
+	}
+
+	relations := storage.NewRelations(resources)
+
+	if got := storage.ForeignKey("categories"); got != "categoryId" {
+		t.Fatalf("expected foreign key categoryId, but got %s", got)
+	}
+
+	categories, err := resources["categories"].Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Numeric ids match the foreign keys of any type.
+	embedded, err := relations.Embed("categories", categories, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if posts, ok := embedded[0]["posts"].([]storage.Resource); !ok || len(posts) != 1 {
+		t.Fatalf("expected 1 embedded post, but got %v", embedded[0]["posts"])
+	}
+
+	if _, ok := categories[0]["posts"]; ok {
+		t.Fatal("expected the embedded resources to be copies")
+	}
+
+	posts, err := resources["posts"].Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expanded, err := relations.Expand(posts, "category")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := expanded[0]["category"]; !ok {
+		t.Fatal("expected the category of the first post to be expanded")
+	}
+
+	if _, ok := expanded[1]["category"]; ok {
+		t.Fatal("expected the post without category not to be expanded")
+	}
+
+	if _, err = relations.Expand(posts, "author"); !errors.Is(err, storage.ErrUnknownRelation) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrUnknownRelation, err)
+	}
+
+	if _, err = relations.Children("posts", "9", "comments"); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+
+	comments, err := relations.Children("posts", "1", "comments")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := comments.Create(storage.Resource{"body": "nice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created["postId"] != "1" {
+		t.Fatalf("expected the created comment to point at post 1, but got %v", created["postId"])
+	}
+
+	if _, err = comments.Update("2", storage.Resource{"body": "moved"}); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v for the comment of another post, but got %v", storage.ErrResourceNotFound, err)
+	}
+
+	found, err := comments.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(found) != 2 {
+		t.Fatalf("expected 2 comments, but got %d", len(found))
+	}
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingFields returns an error when a resource misses required fields.
+var ErrMissingFields = errors.New("missing required fields")
+
+// Required implements the storage interface, by wrapping another storage. It rejects created and replaced
+// resources which miss any of the required fields, or hold null for them.
+type Required struct {
+	storage Storage
+	fields  []string
+}
+
+// NewRequired returns a new required instance, enforcing the provided fields.
+func NewRequired(storageSvc Storage, fields []string) *Required {
+	return &Required{storage: storageSvc, fields: fields}
+}
+
+// Find all resources for the specific key.
+func (rq *Required) Find() ([]Resource, error) {
+	return rq.storage.Find()
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (rq *Required) FindContext(ctx context.Context) ([]Resource, error) {
+	return FindContext(ctx, rq.storage)
+}
+
+// FindById a resource for the specific key.
+func (rq *Required) FindById(id string) (Resource, error) {
+	return rq.storage.FindById(id)
+}
+
+// Create a new resource for the specific key.
+func (rq *Required) Create(newResource Resource) (Resource, error) {
+	if err := rq.validate(newResource); err != nil {
+		return nil, err
+	}
+
+	return rq.storage.Create(newResource)
+}
+
+// Replace an existing resource for the specific key.
+func (rq *Required) Replace(id string, replaced Resource) (Resource, error) {
+	if err := rq.validate(replaced); err != nil {
+		return nil, err
+	}
+
+	return rq.storage.Replace(id, replaced)
+}
+
+// Update an existing resource for the specific key. Omitted fields are kept, so only fields set
+// to null are rejected.
+func (rq *Required) Update(id string, updatedReq Resource) (Resource, error) {
+	missing := make([]string, 0)
+	for _, field := range rq.fields {
+		if val, ok := updatedReq[field]; ok && val == nil {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingFields, strings.Join(missing, ", "))
+	}
+
+	return rq.storage.Update(id, updatedReq)
+}
+
+// Delete an existing resource for the specific key.
+func (rq *Required) Delete(id string) error {
+	return rq.storage.Delete(id)
+}
+
+// DB returns all resources.
+func (rq *Required) DB() (Database, error) {
+	return rq.storage.DB()
+}
+
+// Transaction runs the function with the storage of the transaction, enforcing the same fields.
+func (rq *Required) Transaction(fn func(tx Storage) error) error {
+	return Transaction(rq.storage, func(tx Storage) error {
+		return fn(NewRequired(tx, rq.fields))
+	})
+}
+
+func (rq *Required) validate(resource Resource) error {
+	missing := make([]string, 0)
+	for _, field := range rq.fields {
+		if resource[field] == nil {
+			missing = append(missing, field)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrMissingFields, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,601 @@
+//go:build sqlite
+// +build sqlite
+
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	// Registers the sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS collections (
+	name TEXT PRIMARY KEY,
+	seq  INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS records (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	collection TEXT NOT NULL,
+	id         TEXT,
+	data       TEXT NOT NULL,
+	UNIQUE (collection, id)
+);`
+
+// SQLiteDB holds the database contents shared by all sqlite storage instances, in a sqlite database file. Every
+// write is a transaction, so it is durable and never rewrites the other resources. Collections and records keep
+// the order they were created in.
+type SQLiteDB struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens the sqlite database file, creating it if needed. A database without collections is seeded
+// with the provided data.
+func OpenSQLite(filename string, seed Database) (*SQLiteDB, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", filename))
+	if err != nil {
+		return nil, err
+	}
+
+	// A single connection serializes the writes, which sqlite would otherwise fail as busy.
+	db.SetMaxOpenConns(1)
+
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	s := &SQLiteDB{db: db}
+
+	keys, err := s.Keys()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if len(keys) == 0 && len(seed) > 0 {
+		if err = s.seed(seed); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Close closes the database file.
+func (s *SQLiteDB) Close() error {
+	return s.db.Close()
+}
+
+// Keys returns the keys of the collections, in the order they were created.
+func (s *SQLiteDB) Keys() ([]string, error) {
+	rows, err := s.db.Query(`SELECT name FROM collections ORDER BY seq`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// seed inserts the collections and records of the data, in a single transaction.
+func (s *SQLiteDB) seed(data Database) error {
+	return s.transact(func(tx *sql.Tx) error {
+		for key, resources := range data {
+			if err := addCollection(tx, key); err != nil {
+				return err
+			}
+
+			for _, resource := range resources {
+				if err := insertRecord(tx, key, resource); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// AddCollection adds an empty collection, and returns the storage of its resources.
+func (s *SQLiteDB) AddCollection(key string) (Storage, error) {
+	err := s.transact(func(tx *sql.Tx) error {
+		exists, err := collectionExists(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrResourceAlreadyExists
+		}
+
+		return addCollection(tx, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSQLite(s, key)
+}
+
+// DropCollection drops the collection with all its resources.
+func (s *SQLiteDB) DropCollection(key string) error {
+	return s.transact(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`DELETE FROM collections WHERE name = ?`, key)
+		if err != nil {
+			return err
+		}
+
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			return ErrResourceNotFound
+		}
+
+		_, err = tx.Exec(`DELETE FROM records WHERE collection = ?`, key)
+
+		return err
+	})
+}
+
+// RenameCollection moves the resources of the collection to a new key, and returns their storage.
+func (s *SQLiteDB) RenameCollection(key, newKey string) (Storage, error) {
+	err := s.transact(func(tx *sql.Tx) error {
+		exists, err := collectionExists(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return ErrResourceNotFound
+		}
+
+		if exists, err = collectionExists(tx, newKey); err != nil {
+			return err
+		}
+
+		if exists {
+			return ErrResourceAlreadyExists
+		}
+
+		if _, err = tx.Exec(`UPDATE collections SET name = ? WHERE name = ?`, newKey, key); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE records SET collection = ? WHERE collection = ?`, newKey, key)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSQLite(s, newKey)
+}
+
+// transact runs the function in a transaction, committed unless it fails.
+func (s *SQLiteDB) transact(fn func(tx *sql.Tx) error) error {
+	return s.transactContext(context.Background(), fn)
+}
+
+// transactContext runs the function in a transaction, rolled back once the context is done.
+func (s *SQLiteDB) transactContext(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SQLite implements the storage interface, and keeps the resources in a sqlite database.
+// It is safe for concurrent use.
+type SQLite struct {
+	db  *SQLiteDB
+	key string
+	// tx is the transaction all operations run in, if any.
+	tx *sql.Tx
+}
+
+// NewSQLite returns a new sqlite instance.
+func NewSQLite(db *SQLiteDB, key string) (*SQLite, error) {
+	return &SQLite{db: db, key: key}, nil
+}
+
+// Find all resources for the specific key.
+func (s *SQLite) Find() ([]Resource, error) {
+	return s.FindContext(context.Background())
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (s *SQLite) FindContext(ctx context.Context) ([]Resource, error) {
+	var resources []Resource
+
+	err := s.transactContext(ctx, func(tx *sql.Tx) error {
+		exists, err := collectionExists(tx, s.key)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return ErrResourceNotFound
+		}
+
+		resources, err = findRecords(ctx, tx, s.key)
+
+		return err
+	})
+
+	return resources, err
+}
+
+// FindById a resource for the specific key.
+func (s *SQLite) FindById(id string) (Resource, error) {
+	var resource Resource
+
+	err := s.transact(func(tx *sql.Tx) error {
+		var err error
+		resource, err = findRecord(tx, s.key, id)
+
+		return err
+	})
+
+	return resource, err
+}
+
+// Create a new resource for the specific key.
+func (s *SQLite) Create(newResource Resource) (Resource, error) {
+	err := s.transact(func(tx *sql.Tx) error {
+		exists, err := collectionExists(tx, s.key)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return ErrResourceNotFound
+		}
+
+		if _, ok := newResource["id"]; !ok {
+			ids, err := recordIds(tx, s.key)
+			if err != nil {
+				return err
+			}
+
+			newResource["id"] = generateNewId(ids)
+		} else if _, err = findRecord(tx, s.key, fmt.Sprint(newResource["id"])); err == nil {
+			return ErrResourceAlreadyExists
+		} else if err != ErrResourceNotFound {
+			return err
+		}
+
+		return insertRecord(tx, s.key, newResource)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newResource, nil
+}
+
+// Replace an existing resource for the specific key.
+func (s *SQLite) Replace(id string, replaced Resource) (Resource, error) {
+	replaced["id"] = id
+
+	err := s.transact(func(tx *sql.Tx) error {
+		return updateRecord(tx, s.key, id, replaced)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replaced, nil
+}
+
+// Update an existing resource for the specific key.
+func (s *SQLite) Update(id string, updatedReq Resource) (Resource, error) {
+	var updated Resource
+
+	err := s.transact(func(tx *sql.Tx) error {
+		var err error
+		if updated, err = findRecord(tx, s.key, id); err != nil {
+			return err
+		}
+
+		// Apply any changes to current resource.
+		for key, val := range updatedReq {
+			updated[key] = val
+		}
+
+		updated["id"] = id
+
+		return updateRecord(tx, s.key, id, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// Delete an existing resource for the specific key.
+func (s *SQLite) Delete(id string) error {
+	return s.transact(func(tx *sql.Tx) error {
+		result, err := tx.Exec(`DELETE FROM records WHERE collection = ? AND id = ?`, s.key, id)
+		if err != nil {
+			return err
+		}
+
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			return ErrResourceNotFound
+		}
+
+		return nil
+	})
+}
+
+// DB returns all resources.
+func (s *SQLite) DB() (Database, error) {
+	var data Database
+
+	err := s.transact(func(tx *sql.Tx) error {
+		var err error
+		data, _, err = readDatabase(tx)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Transaction runs the function with a storage of the resources of the key, whose operations all run in a single
+// sqlite transaction, committed once the function succeeds.
+func (s *SQLite) Transaction(fn func(tx Storage) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	return s.db.transact(func(tx *sql.Tx) error {
+		return fn(&SQLite{db: s.db, key: s.key, tx: tx})
+	})
+}
+
+// WriteDB replaces all resources with the data returned by the function, in a single transaction. Collections
+// keep the order they were created in, and new ones are added in alphabetical order.
+func (s *SQLite) WriteDB(fn func(data Database) (Database, error)) error {
+	return s.transact(func(tx *sql.Tx) error {
+		data, keys, err := readDatabase(tx)
+		if err != nil {
+			return err
+		}
+
+		if data, err = fn(data); err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(`DELETE FROM records`); err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if _, ok := data[key]; ok {
+				continue
+			}
+
+			if _, err = tx.Exec(`DELETE FROM collections WHERE name = ?`, key); err != nil {
+				return err
+			}
+		}
+
+		newKeys := make([]string, 0, len(data))
+		for key := range data {
+			newKeys = append(newKeys, key)
+		}
+
+		sort.Strings(newKeys)
+
+		for _, key := range newKeys {
+			exists, err := collectionExists(tx, key)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				if err = addCollection(tx, key); err != nil {
+					return err
+				}
+			}
+
+			for _, resource := range data[key] {
+				if err = insertRecord(tx, key, resource); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// transact runs the function in the transaction of the storage, if any, or else in a new one.
+func (s *SQLite) transact(fn func(tx *sql.Tx) error) error {
+	return s.transactContext(context.Background(), fn)
+}
+
+func (s *SQLite) transactContext(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+
+	return s.db.transactContext(ctx, fn)
+}
+
+// readDatabase returns all resources, and the keys of the collections in the order they were created.
+func readDatabase(tx *sql.Tx) (Database, []string, error) {
+	rows, err := tx.Query(`SELECT name FROM collections ORDER BY seq`)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	rows.Close()
+
+	data := make(Database, len(keys))
+	for _, key := range keys {
+		if data[key], err = findRecords(context.Background(), tx, key); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return data, keys, nil
+}
+
+func collectionExists(tx *sql.Tx, key string) (bool, error) {
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM collections WHERE name = ?`, key).Scan(&count); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func addCollection(tx *sql.Tx, key string) error {
+	_, err := tx.Exec(`INSERT INTO collections (name, seq) VALUES (?, (SELECT COALESCE(MAX(seq), 0) + 1 FROM collections))`, key)
+	return err
+}
+
+func findRecords(ctx context.Context, tx *sql.Tx, key string) ([]Resource, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT data FROM records WHERE collection = ? ORDER BY seq`, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	resources := make([]Resource, 0)
+	for rows.Next() {
+		var data []byte
+		if err = rows.Scan(&data); err != nil {
+			return nil, err
+		}
+
+		var resource Resource
+		if err = Unmarshal(data, &resource); err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, resource)
+	}
+
+	return resources, rows.Err()
+}
+
+func findRecord(tx *sql.Tx, key, id string) (Resource, error) {
+	var data []byte
+
+	err := tx.QueryRow(`SELECT data FROM records WHERE collection = ? AND id = ?`, key, id).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, ErrResourceNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var resource Resource
+	if err = Unmarshal(data, &resource); err != nil {
+		return nil, err
+	}
+
+	return resource, nil
+}
+
+// recordIds returns the resources of the collection holding only their id, to generate a new one.
+func recordIds(tx *sql.Tx, key string) ([]Resource, error) {
+	rows, err := tx.Query(`SELECT id FROM records WHERE collection = ?`, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make([]Resource, 0)
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, Resource{"id": id})
+	}
+
+	return ids, rows.Err()
+}
+
+func insertRecord(tx *sql.Tx, key string, resource Resource) error {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO records (collection, id, data) VALUES (?, ?, ?)`, key, recordId(resource), data)
+
+	return err
+}
+
+func updateRecord(tx *sql.Tx, key, id string, resource Resource) error {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`UPDATE records SET data = ? WHERE collection = ? AND id = ?`, data, key, id)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err != nil || n == 0 {
+		return ErrResourceNotFound
+	}
+
+	return nil
+}
+
+// recordId returns the id of the resource, as stored in the id column. Numeric ids are stored like string ones,
+// and resources without id as null, which is not unique.
+func recordId(resource Resource) interface{} {
+	if id, ok := resource["id"]; ok && id != nil {
+		return fmt.Sprint(id)
+	}
+
+	return nil
+}
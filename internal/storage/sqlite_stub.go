@@ -0,0 +1,47 @@
+//go:build !sqlite
+// +build !sqlite
+
+package storage
+
+import "errors"
+
+// ErrSQLiteUnsupported returns an error when the sqlite storage is used by a binary built without it.
+var ErrSQLiteUnsupported = errors.New("sqlite storage is not supported by this build, build with '-tags sqlite' and cgo")
+
+// SQLiteDB is not supported without the sqlite build tag.
+type SQLiteDB struct{}
+
+// OpenSQLite fails, as the sqlite storage is not supported by this build.
+func OpenSQLite(string, Database) (*SQLiteDB, error) {
+	return nil, ErrSQLiteUnsupported
+}
+
+// Close does nothing.
+func (s *SQLiteDB) Close() error {
+	return nil
+}
+
+// Keys fails, as the sqlite storage is not supported by this build.
+func (s *SQLiteDB) Keys() ([]string, error) {
+	return nil, ErrSQLiteUnsupported
+}
+
+// AddCollection fails, as the sqlite storage is not supported by this build.
+func (s *SQLiteDB) AddCollection(string) (Storage, error) {
+	return nil, ErrSQLiteUnsupported
+}
+
+// DropCollection fails, as the sqlite storage is not supported by this build.
+func (s *SQLiteDB) DropCollection(string) error {
+	return ErrSQLiteUnsupported
+}
+
+// RenameCollection fails, as the sqlite storage is not supported by this build.
+func (s *SQLiteDB) RenameCollection(string, string) (Storage, error) {
+	return nil, ErrSQLiteUnsupported
+}
+
+// NewSQLite fails, as the sqlite storage is not supported by this build.
+func NewSQLite(*SQLiteDB, string) (Storage, error) {
+	return nil, ErrSQLiteUnsupported
+}
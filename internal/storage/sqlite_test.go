@@ -0,0 +1,112 @@
+//go:build sqlite
+// +build sqlite
+
+package storage_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestSQLite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "db.sqlite")
+
+	db, err := storage.OpenSQLite(filename, storage.Database{
+		"posts": {{"id": "1", "title": "first"}, {"id": "2", "title": "second"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	posts, err := storage.NewSQLite(db, "posts")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := posts.Create(storage.Resource{"title": "third"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created["id"] != "3" {
+		t.Fatalf("expected generated id %v, but got %v", "3", created["id"])
+	}
+
+	if _, err = posts.Create(storage.Resource{"id": "1", "title": "duplicate"}); !errors.Is(err, storage.ErrResourceAlreadyExists) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceAlreadyExists, err)
+	}
+
+	if _, err = posts.Update("2", storage.Resource{"views": float64(10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = posts.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = posts.Delete("1"); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopened databases keep their resources, instead of being seeded again.
+	if db, err = storage.OpenSQLite(filename, storage.Database{"posts": {}}); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if posts, err = storage.NewSQLite(db, "posts"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := posts.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []storage.Resource{
+		{"id": "2", "title": "second", "views": float64(10)},
+		{"id": "3", "title": "third"},
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected resources %v, but got %v", expected, got)
+	}
+
+	if _, err = db.RenameCollection("posts", "articles"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = posts.Find(); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+
+	all, err := storage.NewSQLite(db, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := all.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(data, storage.Database{"articles": expected}) {
+		t.Fatalf("expected database %v, but got %v", storage.Database{"articles": expected}, data)
+	}
+}
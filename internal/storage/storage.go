@@ -2,6 +2,7 @@
 package storage
 
 import (
+	"context"
 	"errors"
 )
 
@@ -14,6 +15,8 @@ var (
 	ErrBadRequest = errors.New("bad request")
 	// ErrInternalServerError returns an error when an unexpected error occurs.
 	ErrInternalServerError = errors.New("internal Server Error")
+	// ErrTransactionUnsupported returns an error when a storage cannot apply several writes as a transaction.
+	ErrTransactionUnsupported = errors.New("transactions not supported by storage")
 )
 
 // Resource represents the structure of a singe resource in storage.
@@ -32,3 +35,79 @@ type Storage interface {
 	Delete(string) error
 	DB() (Database, error)
 }
+
+// Collections manages the collections of a database, to add, drop and rename resources while the server runs.
+type Collections interface {
+	// AddCollection adds an empty collection, and returns the storage of its resources.
+	AddCollection(key string) (Storage, error)
+	// DropCollection drops the collection with all its resources.
+	DropCollection(key string) error
+	// RenameCollection moves the resources of the collection to a new key, and returns their storage.
+	RenameCollection(key, newKey string) (Storage, error)
+}
+
+// Transactional is implemented by storages which apply several writes as a single transaction. Storages wrapping
+// another storage implement it by wrapping the storage of the transaction the same way.
+type Transactional interface {
+	// Transaction runs the function with a storage of the same resources, whose writes are all stored once the
+	// function succeeds, or none of them if it fails. Other writes wait for the transaction to complete.
+	Transaction(fn func(tx Storage) error) error
+}
+
+// DBWriter is implemented by storages which replace all resources at once, as a single transaction.
+type DBWriter interface {
+	// WriteDB stores the data returned by the function, given a copy of all resources, unless it fails.
+	WriteDB(fn func(data Database) (Database, error)) error
+}
+
+// ContextFinder is implemented by storages which stop finding resources once the context is done, e.g. when the
+// client of the request is gone. Storages wrapping another storage implement it by finding with the context.
+type ContextFinder interface {
+	// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+	FindContext(ctx context.Context) ([]Resource, error)
+}
+
+// Transaction runs the function as a transaction of the storage. It fails with ErrTransactionUnsupported if the
+// storage, or any storage it wraps, does not support transactions.
+func Transaction(storageSvc Storage, fn func(tx Storage) error) error {
+	t, ok := storageSvc.(Transactional)
+	if !ok {
+		return ErrTransactionUnsupported
+	}
+
+	return t.Transaction(fn)
+}
+
+// WriteDB replaces all resources of the storage with the data returned by the function, given a copy of all
+// resources. It fails with ErrTransactionUnsupported if the storage does not support it.
+func WriteDB(storageSvc Storage, fn func(data Database) (Database, error)) error {
+	w, ok := storageSvc.(DBWriter)
+	if !ok {
+		return ErrTransactionUnsupported
+	}
+
+	return w.WriteDB(fn)
+}
+
+// FindContext finds all resources of the storage, failing with the error of the context once it is done. Storages
+// which do not support it find all resources at once, unless the context is done before or after.
+func FindContext(ctx context.Context, storageSvc Storage) ([]Resource, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if f, ok := storageSvc.(ContextFinder); ok {
+		return f.FindContext(ctx)
+	}
+
+	resources, err := storageSvc.Find()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return resources, nil
+}
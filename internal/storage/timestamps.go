@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	// CreatedAtField holds the creation time of a resource.
+	CreatedAtField = "createdAt"
+	// UpdatedAtField holds the last modification time of a resource.
+	UpdatedAtField = "updatedAt"
+)
+
+// Timestamps implements the storage interface, by wrapping another storage. It stamps resources with their
+// creation and modification time, and optionally expires resources after a time to live.
+type Timestamps struct {
+	storage Storage
+	now     func() time.Time
+	ttl     time.Duration
+}
+
+// NewTimestamps returns a new timestamps instance, using the provided function as time source. Resources
+// are expired once older than the time to live, unless it is zero.
+func NewTimestamps(storageSvc Storage, now func() time.Time, ttl time.Duration) *Timestamps {
+	return &Timestamps{storage: storageSvc, now: now, ttl: ttl}
+}
+
+// Find all resources for the specific key, deleting any expired ones.
+func (t *Timestamps) Find() ([]Resource, error) {
+	return t.FindContext(context.Background())
+}
+
+// FindContext finds all resources for the specific key, deleting any expired ones, failing with the error of the
+// context once it is done.
+func (t *Timestamps) FindContext(ctx context.Context) ([]Resource, error) {
+	resources, err := FindContext(ctx, t.storage)
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+
+	active := make([]Resource, 0, len(resources))
+	for _, resource := range resources {
+		if !t.expired(resource, now) {
+			active = append(active, resource)
+			continue
+		}
+
+		if id, ok := resource["id"].(string); ok {
+			if err = t.storage.Delete(id); err != nil && !errors.Is(err, ErrResourceNotFound) {
+				return nil, err
+			}
+		}
+	}
+
+	return active, nil
+}
+
+// FindById a resource for the specific key. Expired resources are deleted and reported as not found.
+func (t *Timestamps) FindById(id string) (Resource, error) {
+	resource, err := t.storage.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.expired(resource, t.now()) {
+		if err = t.storage.Delete(id); err != nil && !errors.Is(err, ErrResourceNotFound) {
+			return nil, err
+		}
+
+		return nil, ErrResourceNotFound
+	}
+
+	return resource, nil
+}
+
+// Create a new resource for the specific key. A provided creation time is honored.
+func (t *Timestamps) Create(newResource Resource) (Resource, error) {
+	now := formatTimestamp(t.now())
+
+	if _, ok := newResource[CreatedAtField]; !ok {
+		newResource[CreatedAtField] = now
+	}
+
+	newResource[UpdatedAtField] = now
+
+	return t.storage.Create(newResource)
+}
+
+// Replace an existing resource for the specific key, keeping its creation time.
+func (t *Timestamps) Replace(id string, replaced Resource) (Resource, error) {
+	existing, err := t.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdAt, ok := existing[CreatedAtField]; ok {
+		replaced[CreatedAtField] = createdAt
+	}
+
+	replaced[UpdatedAtField] = formatTimestamp(t.now())
+
+	return t.storage.Replace(id, replaced)
+}
+
+// Update an existing resource for the specific key.
+func (t *Timestamps) Update(id string, updatedReq Resource) (Resource, error) {
+	if _, err := t.FindById(id); err != nil {
+		return nil, err
+	}
+
+	updatedReq[UpdatedAtField] = formatTimestamp(t.now())
+
+	return t.storage.Update(id, updatedReq)
+}
+
+// Delete an existing resource for the specific key.
+func (t *Timestamps) Delete(id string) error {
+	if _, err := t.FindById(id); err != nil {
+		return err
+	}
+
+	return t.storage.Delete(id)
+}
+
+// DB returns all resources, except the expired ones.
+func (t *Timestamps) DB() (Database, error) {
+	data, err := t.storage.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	now := t.now()
+
+	for key, resources := range data {
+		active := make([]Resource, 0, len(resources))
+		for _, resource := range resources {
+			if !t.expired(resource, now) {
+				active = append(active, resource)
+			}
+		}
+
+		data[key] = active
+	}
+
+	return data, nil
+}
+
+// Transaction runs the function with the storage of the transaction, stamping resources the same way.
+func (t *Timestamps) Transaction(fn func(tx Storage) error) error {
+	return Transaction(t.storage, func(tx Storage) error {
+		return fn(NewTimestamps(tx, t.now, t.ttl))
+	})
+}
+
+// WriteDB replaces all resources with the data returned by the function, as given, without stamping them.
+func (t *Timestamps) WriteDB(fn func(data Database) (Database, error)) error {
+	return WriteDB(t.storage, fn)
+}
+
+// expired reports whether the resource outlived the time to live. Resources without a valid
+// creation time never expire.
+func (t *Timestamps) expired(resource Resource, now time.Time) bool {
+	if t.ttl <= 0 {
+		return false
+	}
+
+	value, ok := resource[CreatedAtField].(string)
+	if !ok {
+		return false
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return false
+	}
+
+	return !now.Before(createdAt.Add(t.ttl))
+}
+
+func formatTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,64 @@
+package storage_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+)
+
+func TestTimestamps(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	nowFunc := func() time.Time { return now }
+
+	memorySvc, err := storage.NewMemory(storage.NewMemoryDB(testMemoryData()), "key1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storageSvc := storage.NewTimestamps(memorySvc, nowFunc, time.Hour)
+
+	created, err := storageSvc.Create(storage.Resource{"field_1": "new-field_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "2020-01-01T00:00:00Z"
+	if created[storage.CreatedAtField] != expected || created[storage.UpdatedAtField] != expected {
+		t.Fatalf("expected timestamps %v, but got %v", expected, created)
+	}
+
+	id := created["id"].(string)
+
+	now = now.Add(time.Minute * 30)
+
+	replaced, err := storageSvc.Replace(id, storage.Resource{"field_2": "new-field_2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if replaced[storage.CreatedAtField] != expected {
+		t.Fatalf("expected createdAt %v, but got %v", expected, replaced[storage.CreatedAtField])
+	}
+
+	if expectedUpdated := "2020-01-01T00:30:00Z"; replaced[storage.UpdatedAtField] != expectedUpdated {
+		t.Fatalf("expected updatedAt %v, but got %v", expectedUpdated, replaced[storage.UpdatedAtField])
+	}
+
+	now = now.Add(time.Minute * 30)
+
+	if _, err = storageSvc.FindById(id); !errors.Is(err, storage.ErrResourceNotFound) {
+		t.Fatalf("expected error %v, but got %v", storage.ErrResourceNotFound, err)
+	}
+
+	// Expired resources are deleted, while resources without createdAt never expire.
+	resources, err := memorySvc.Find()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resources) != len(testMemoryData()["key1"]) {
+		t.Fatalf("expected %v resources, but got %v", len(testMemoryData()["key1"]), len(resources))
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ErrDuplicateValue returns an error when a resource holds the value of a unique field of another resource.
+var ErrDuplicateValue = errors.New("duplicate value of unique field")
+
+// Unique implements the storage interface, by wrapping another storage. It rejects created, replaced and
+// updated resources, which hold the same value of a unique field as another resource. Null values are
+// never considered duplicates.
+type Unique struct {
+	storage Storage
+	fields  []string
+	// mu serializes writes, so concurrent requests cannot store the same value.
+	mu sync.Mutex
+}
+
+// NewUnique returns a new unique instance, enforcing the provided fields.
+func NewUnique(storageSvc Storage, fields []string) *Unique {
+	return &Unique{storage: storageSvc, fields: fields}
+}
+
+// Find all resources for the specific key.
+func (u *Unique) Find() ([]Resource, error) {
+	return u.storage.Find()
+}
+
+// FindContext finds all resources for the specific key, failing with the error of the context once it is done.
+func (u *Unique) FindContext(ctx context.Context) ([]Resource, error) {
+	return FindContext(ctx, u.storage)
+}
+
+// FindById a resource for the specific key.
+func (u *Unique) FindById(id string) (Resource, error) {
+	return u.storage.FindById(id)
+}
+
+// Create a new resource for the specific key.
+func (u *Unique) Create(newResource Resource) (Resource, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.validate("", newResource); err != nil {
+		return nil, err
+	}
+
+	return u.storage.Create(newResource)
+}
+
+// Replace an existing resource for the specific key.
+func (u *Unique) Replace(id string, replaced Resource) (Resource, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.validate(id, replaced); err != nil {
+		return nil, err
+	}
+
+	return u.storage.Replace(id, replaced)
+}
+
+// Update an existing resource for the specific key.
+func (u *Unique) Update(id string, updatedReq Resource) (Resource, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if err := u.validate(id, updatedReq); err != nil {
+		return nil, err
+	}
+
+	return u.storage.Update(id, updatedReq)
+}
+
+// Delete an existing resource for the specific key.
+func (u *Unique) Delete(id string) error {
+	return u.storage.Delete(id)
+}
+
+// DB returns all resources.
+func (u *Unique) DB() (Database, error) {
+	return u.storage.DB()
+}
+
+// Transaction runs the function with the storage of the transaction, enforcing the same fields. Other writes
+// wait for the transaction, so they cannot duplicate the values it writes.
+func (u *Unique) Transaction(fn func(tx Storage) error) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return Transaction(u.storage, func(tx Storage) error {
+		return fn(NewUnique(tx, u.fields))
+	})
+}
+
+// validate checks the unique fields of the resource against all other resources. The id is the one of
+// the resource being replaced or updated, and empty for created resources.
+func (u *Unique) validate(id string, resource Resource) error {
+	resources, err := u.storage.Find()
+	if err != nil {
+		return err
+	}
+
+	for _, field := range u.fields {
+		val, ok := resource[field]
+		if !ok || val == nil {
+			continue
+		}
+
+		for _, existing := range resources {
+			if id != "" && fmt.Sprint(existing["id"]) == id {
+				continue
+			}
+
+			if reflect.DeepEqual(existing[field], val) {
+				return fmt.Errorf("%w: %s", ErrDuplicateValue, field)
+			}
+		}
+	}
+
+	return nil
+}
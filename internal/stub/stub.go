@@ -0,0 +1,269 @@
+// Package stub keeps canned responses registered at runtime, which are served instead of the regular
+// handlers, e.g. to make a route fail once before it succeeds.
+package stub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errInvalidPath   = errors.New("path must start with '/'")
+	errInvalidStatus = errors.New("status must be a status code")
+	errInvalidTimes  = errors.New("times must be a positive number")
+	errInvalidDelay  = errors.New("delay must be a positive duration like '30s'")
+	errUnknownFault  = errors.New("unknown fault, expected hang, reset, truncate, malformed or content-length")
+	// ErrInvalidFile returns an error when a stubs file cannot be parsed.
+	ErrInvalidFile = errors.New("invalid stubs file")
+)
+
+// Faults of stub responses.
+const (
+	// FaultHang never responds, holding the connection until the client gives up.
+	FaultHang = "hang"
+	// FaultReset resets the connection without responding.
+	FaultReset = "reset"
+	// FaultTruncate closes the connection in the middle of the body.
+	FaultTruncate = "truncate"
+	// FaultMalformed responds with the body cut in half, which is invalid json.
+	FaultMalformed = "malformed"
+	// FaultContentLength responds with a Content-Length header shorter than the body.
+	FaultContentLength = "content-length"
+)
+
+// ValidateFault checks that the fault is known.
+func ValidateFault(fault string) error {
+	switch fault {
+	case FaultHang, FaultReset, FaultTruncate, FaultMalformed, FaultContentLength:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", errUnknownFault, fault)
+}
+
+// Stub is a canned response of the requests matching its method and path.
+type Stub struct {
+	ID int `json:"id"`
+	// Method matches case insensitive, or any method when empty.
+	Method string `json:"method,omitempty"`
+	// Path matches exactly, or as prefix when ending with '*'.
+	Path string `json:"path"`
+	// Priority orders the stubs matching a request, highest first. Stubs with the same priority respond in
+	// the order they were registered.
+	Priority int `json:"priority"`
+	// Times is the number of remaining responses, after which the stub is dropped and requests fall
+	// through. Stubs without it respond until removed.
+	Times    *int     `json:"times,omitempty"`
+	Response Response `json:"response"`
+}
+
+// Response is the response of a stub. The status defaults to 200.
+type Response struct {
+	Status  int               `json:"status,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    interface{}       `json:"body,omitempty"`
+	// Delay holds the connection for the duration before responding, or before the fault.
+	Delay Duration `json:"delay,omitempty"`
+	// Fault breaks the response, either 'hang', 'reset', 'truncate', 'malformed' or 'content-length'.
+	Fault string `json:"fault,omitempty"`
+}
+
+// Duration is a duration written as a string, e.g. '30s'.
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON parses the duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var val string
+	if err := json.Unmarshal(data, &val); err != nil {
+		return errInvalidDelay
+	}
+
+	parsed, err := time.ParseDuration(val)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errInvalidDelay, val)
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// Validate checks the path, status and times of the stub.
+func (s Stub) Validate() error {
+	if !strings.HasPrefix(s.Path, "/") {
+		return errInvalidPath
+	}
+
+	if s.Response.Status != 0 && (s.Response.Status < 100 || s.Response.Status > 599) {
+		return fmt.Errorf("%w: %d", errInvalidStatus, s.Response.Status)
+	}
+
+	if s.Times != nil && *s.Times <= 0 {
+		return errInvalidTimes
+	}
+
+	if s.Response.Delay < 0 {
+		return errInvalidDelay
+	}
+
+	if s.Response.Fault != "" {
+		if err := ValidateFault(s.Response.Fault); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s Stub) matches(r *http.Request) bool {
+	if s.Method != "" && !strings.EqualFold(s.Method, r.Method) {
+		return false
+	}
+
+	if strings.HasSuffix(s.Path, "*") {
+		return strings.HasPrefix(r.URL.Path, strings.TrimSuffix(s.Path, "*"))
+	}
+
+	return s.Path == r.URL.Path
+}
+
+// Load reads the stubs of a file holding a json array of stubs, and validates them.
+func Load(file string) ([]Stub, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	stubs := make([]Stub, 0)
+	if err = json.Unmarshal(content, &stubs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	for idx, s := range stubs {
+		if err = s.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: stub %d: %v", ErrInvalidFile, idx+1, err)
+		}
+	}
+
+	return stubs, nil
+}
+
+// Registry keeps the registered stubs. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.Mutex
+	stubs  []Stub
+	lastID int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{stubs: make([]Stub, 0)}
+}
+
+// Add validates and registers the stub, assigning its id.
+func (reg *Registry) Add(s Stub) (Stub, error) {
+	if err := s.Validate(); err != nil {
+		return Stub{}, err
+	}
+
+	if s.Response.Status == 0 {
+		s.Response.Status = http.StatusOK
+	}
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	reg.lastID++
+	s.ID = reg.lastID
+
+	reg.stubs = append(reg.stubs, s.copy())
+
+	// Keep the stubs ordered by priority, so the first matching stub responds.
+	sort.SliceStable(reg.stubs, func(i, j int) bool {
+		return reg.stubs[i].Priority > reg.stubs[j].Priority
+	})
+
+	return s, nil
+}
+
+// Stubs returns the registered stubs, in the order they respond.
+func (reg *Registry) Stubs() []Stub {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	stubs := make([]Stub, 0, len(reg.stubs))
+	for _, s := range reg.stubs {
+		stubs = append(stubs, s.copy())
+	}
+
+	return stubs
+}
+
+// Remove drops the stub with the provided id, reporting whether it was registered.
+func (reg *Registry) Remove(id int) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for idx, s := range reg.stubs {
+		if s.ID == id {
+			reg.stubs = append(reg.stubs[:idx], reg.stubs[idx+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reset drops all registered stubs.
+func (reg *Registry) Reset() {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	reg.stubs = make([]Stub, 0)
+}
+
+// Match returns the response of the stub with the highest priority matching the request, using up one of
+// its remaining times.
+func (reg *Registry) Match(r *http.Request) (Response, bool) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	for idx, s := range reg.stubs {
+		if !s.matches(r) {
+			continue
+		}
+
+		if s.Times != nil {
+			*s.Times--
+			if *s.Times == 0 {
+				reg.stubs = append(reg.stubs[:idx], reg.stubs[idx+1:]...)
+			}
+		}
+
+		return s.Response, true
+	}
+
+	return Response{}, false
+}
+
+// copy returns the stub with its own remaining times, so it is not changed by later matches.
+func (s Stub) copy() Stub {
+	if s.Times != nil {
+		times := *s.Times
+		s.Times = &times
+	}
+
+	return s
+}
@@ -0,0 +1,136 @@
+package stub_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/stub"
+)
+
+func TestRegistry(t *testing.T) {
+	registry := stub.NewRegistry()
+	once := 1
+
+	stubs := []stub.Stub{
+		{Method: "GET", Path: "/users", Times: &once, Response: stub.Response{Status: 503}},
+		{Method: "GET", Path: "/users", Response: stub.Response{Body: "any"}},
+		{Path: "/users*", Priority: 1, Times: &once, Response: stub.Response{Status: 429}},
+		{Method: "POST", Path: "/orders", Response: stub.Response{Status: 201}},
+	}
+
+	for _, s := range stubs {
+		if _, err := registry.Add(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if once != 1 {
+		t.Fatalf("expected the registered times to be copied, but got %v", once)
+	}
+
+	testCases := []struct {
+		name       string
+		method     string
+		path       string
+		matched    bool
+		statusCode int
+	}{
+		{name: "Highest priority first", method: http.MethodGet, path: "/users", matched: true, statusCode: 429},
+		{name: "Same priority in registered order", method: http.MethodGet, path: "/users", matched: true, statusCode: 503},
+		{name: "Fall through after times", method: http.MethodGet, path: "/users", matched: true, statusCode: 200},
+		{name: "Keep stubs without times", method: http.MethodGet, path: "/users", matched: true, statusCode: 200},
+		{name: "Match method", method: http.MethodGet, path: "/orders"},
+		{name: "Match path", method: http.MethodPost, path: "/orders/1"},
+		{name: "Match other stub", method: http.MethodPost, path: "/orders", matched: true, statusCode: 201},
+	}
+
+	for _, tt := range testCases {
+		resp, ok := registry.Match(httptest.NewRequest(tt.method, tt.path, nil))
+		if ok != tt.matched {
+			t.Fatalf("%s: expected matched %v, but got %v", tt.name, tt.matched, ok)
+		}
+
+		if resp.Status != tt.statusCode {
+			t.Fatalf("%s: expected status code %v, but got %v", tt.name, tt.statusCode, resp.Status)
+		}
+	}
+
+	if got := len(registry.Stubs()); got != 2 {
+		t.Fatalf("expected 2 stubs left, but got %v", got)
+	}
+
+	if !registry.Remove(2) || registry.Remove(2) {
+		t.Fatal("expected stub 2 to be removed once")
+	}
+
+	registry.Reset()
+
+	if got := len(registry.Stubs()); got != 0 {
+		t.Fatalf("expected no stubs after reset, but got %v", got)
+	}
+}
+
+func TestStub_Validate(t *testing.T) {
+	zero := 0
+
+	testCases := []struct {
+		name  string
+		stub  stub.Stub
+		valid bool
+	}{
+		{name: "Valid stub", stub: stub.Stub{Path: "/users"}, valid: true},
+		{name: "Relative path", stub: stub.Stub{Path: "users"}},
+		{name: "Invalid status", stub: stub.Stub{Path: "/users", Response: stub.Response{Status: 42}}},
+		{name: "Zero times", stub: stub.Stub{Path: "/users", Times: &zero}},
+		{name: "Valid fault", stub: stub.Stub{Path: "/users", Response: stub.Response{Fault: stub.FaultReset, Delay: stub.Duration(time.Second)}}, valid: true},
+		{name: "Unknown fault", stub: stub.Stub{Path: "/users", Response: stub.Response{Fault: "timeout"}}},
+		{name: "Negative delay", stub: stub.Stub{Path: "/users", Response: stub.Response{Delay: stub.Duration(-time.Second)}}},
+	}
+
+	for _, tt := range testCases {
+		if err := tt.stub.Validate(); (err == nil) != tt.valid {
+			t.Fatalf("%s: expected valid %v, but got error %v", tt.name, tt.valid, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "stub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		name    string
+		content string
+		stubs   int
+		err     error
+	}{
+		{name: "Load stubs", content: `[{"method": "GET", "path": "/profile", "response": {"body": {"name": "json-server"}}}, {"path": "/orders*", "response": {"status": 503}}]`, stubs: 2},
+		{name: "Load invalid json", content: `{"path": "/profile"}`, err: stub.ErrInvalidFile},
+		{name: "Load invalid stub", content: `[{"path": "profile"}]`, err: stub.ErrInvalidFile},
+	}
+
+	for _, tt := range testCases {
+		file := filepath.Join(dir, "stubs.json")
+		if err := ioutil.WriteFile(file, []byte(tt.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		stubs, err := stub.Load(file)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: expected error %v, but got %v", tt.name, tt.err, err)
+		}
+
+		if len(stubs) != tt.stubs {
+			t.Fatalf("%s: expected %v stubs, but got %v", tt.name, tt.stubs, len(stubs))
+		}
+	}
+}
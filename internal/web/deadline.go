@@ -0,0 +1,92 @@
+package web
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// connContextKey is the context key of the connection of a request.
+type connContextKey struct{}
+
+// writeDeadliner is implemented by the response writers of the http server which set the write deadline of the
+// response, since go1.20.
+type writeDeadliner interface {
+	SetWriteDeadline(deadline time.Time) error
+}
+
+// readDeadliner is implemented by the response writers of the http server which set the read deadline of the
+// request body, since go1.20.
+type readDeadliner interface {
+	SetReadDeadline(deadline time.Time) error
+}
+
+// unwrapper is implemented by the response writers of middleware wrapping another response writer.
+type unwrapper interface {
+	Unwrap() http.ResponseWriter
+}
+
+// ConnContext stores the connection in the context of its requests, as the ConnContext of a http.Server, so the
+// deadlines of http/1 requests can be set by servers built with older go versions as well.
+func ConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, connContextKey{}, c)
+}
+
+// SetWriteDeadline sets the deadline of writing the response, overriding the write timeout of the server, e.g. to
+// stream a response for longer. A zero deadline means no deadline. It reports whether the deadline is set.
+func SetWriteDeadline(w http.ResponseWriter, r *http.Request, deadline time.Time) bool {
+	for {
+		if d, ok := w.(writeDeadliner); ok {
+			return d.SetWriteDeadline(deadline) == nil
+		}
+
+		u, ok := w.(unwrapper)
+		if !ok {
+			break
+		}
+
+		w = u.Unwrap()
+	}
+
+	if conn, ok := requestConn(r); ok {
+		return conn.SetWriteDeadline(deadline) == nil
+	}
+
+	return false
+}
+
+// SetReadDeadline sets the deadline of reading the request body, overriding the read timeout of the server, e.g.
+// to receive a large upload. A zero deadline means no deadline. It reports whether the deadline is set.
+func SetReadDeadline(w http.ResponseWriter, r *http.Request, deadline time.Time) bool {
+	for {
+		if d, ok := w.(readDeadliner); ok {
+			return d.SetReadDeadline(deadline) == nil
+		}
+
+		u, ok := w.(unwrapper)
+		if !ok {
+			break
+		}
+
+		w = u.Unwrap()
+	}
+
+	if conn, ok := requestConn(r); ok {
+		return conn.SetReadDeadline(deadline) == nil
+	}
+
+	return false
+}
+
+// requestConn returns the connection of the request, for http/1 requests only, as the connections of http/2
+// requests are shared by many requests.
+func requestConn(r *http.Request) (net.Conn, bool) {
+	if r.ProtoMajor != 1 {
+		return nil, false
+	}
+
+	conn, ok := r.Context().Value(connContextKey{}).(net.Conn)
+
+	return conn, ok
+}
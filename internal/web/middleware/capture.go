@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/capture"
+)
+
+// Capture is operating as middleware to record requests and their responses in the sink, except for the
+// requests to the skipped path prefixes. Bodies are kept up to capture.MaxBodySize, and encoded bodies
+// are not kept.
+func Capture(sink capture.Sink, now func() time.Time, skip ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range skip {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			entry := capture.Entry{
+				Time:   now(),
+				Method: r.Method,
+				Path:   r.URL.Path,
+				Query:  r.URL.Query(),
+				Header: r.Header.Clone(),
+			}
+
+			// Read the start of the body up front, so it is kept even if the handler never reads it.
+			if r.Body != nil && r.Body != http.NoBody {
+				head, err := ioutil.ReadAll(io.LimitReader(r.Body, capture.MaxBodySize))
+				if err == nil && r.Header.Get("Content-Encoding") == "" {
+					entry.Body = capture.DecodeBody(head)
+				}
+
+				r.Body = &capturedBody{Reader: io.MultiReader(bytes.NewReader(head), r.Body), body: r.Body}
+			}
+
+			cw := &captureWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(cw, r)
+
+			if cw.header == nil {
+				cw.header = w.Header().Clone()
+			}
+
+			entry.Response = capture.Response{Status: cw.statusCode, Header: cw.header}
+			if cw.header.Get("Content-Encoding") == "" {
+				entry.Response.Body = capture.DecodeBody(cw.body.Bytes())
+			}
+
+			sink.Add(entry)
+		})
+	}
+}
+
+// capturedBody reads the buffered start of the body, followed by the rest of it.
+type capturedBody struct {
+	io.Reader
+	body io.Closer
+}
+
+func (b *capturedBody) Close() error {
+	return b.body.Close()
+}
+
+// captureWriter keeps the status code, headers and start of the body of the response.
+type captureWriter struct {
+	http.ResponseWriter
+	statusCode int
+	header     http.Header
+	body       bytes.Buffer
+}
+
+func (c *captureWriter) WriteHeader(statusCode int) {
+	if c.header == nil {
+		c.statusCode = statusCode
+		c.header = c.Header().Clone()
+	}
+
+	c.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (c *captureWriter) Write(b []byte) (int, error) {
+	if c.header == nil {
+		c.header = c.Header().Clone()
+	}
+
+	if remaining := capture.MaxBodySize - c.body.Len(); remaining > 0 {
+		if len(b) < remaining {
+			remaining = len(b)
+		}
+
+		c.body.Write(b[:remaining])
+	}
+
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *captureWriter) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped response writer, so the deadlines of the response can be set.
+func (c *captureWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func (c *captureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := c.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("ResponseWriter does not implement the Hijacker interface")
+	}
+
+	return hijacker.Hijack()
+}
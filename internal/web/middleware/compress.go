@@ -0,0 +1,274 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+const (
+	// minCompressSize is the size in bytes below which responses are sent uncompressed.
+	minCompressSize = 1024
+	// maxCompressBuffer is the size in bytes up to which responses are compressed as a whole, and sent with
+	// a Content-Length. Larger responses are compressed while they are written instead.
+	maxCompressBuffer = 1 << 20
+	// brotliLevel trades compression ratio for latency, like CDNs compressing on the fly do.
+	brotliLevel = 5
+)
+
+// compressEncodings lists the supported content encodings, by preference for equal quality values.
+var compressEncodings = []string{"br", "gzip"}
+
+// Compress is operating as middleware to compress responses with brotli or gzip, as negotiated with the
+// Accept-Encoding header of the request. Only text based responses of at least 1KiB are compressed.
+func Compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, statusCode: http.StatusOK}
+		defer cw.finish()
+
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// negotiateEncoding returns the supported encoding with the highest quality value of the Accept-Encoding
+// header, or an empty one if none is accepted.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+
+		name := strings.ToLower(strings.TrimSpace(params[0]))
+		quality := 1.0
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+				quality = q
+			}
+		}
+
+		qualities[name] = quality
+	}
+
+	best, bestQuality := "", 0.0
+	for _, encoding := range compressEncodings {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+
+		if ok && quality > bestQuality {
+			best, bestQuality = encoding, quality
+		}
+	}
+
+	return best
+}
+
+// compressWriter buffers the response, to compress it as a whole once complete. Responses growing beyond
+// the buffer, or flushed by the handler, are compressed while they are written.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding   string
+	statusCode int
+	// decided is set once the headers are known, and skip if they rule out compression.
+	decided bool
+	skip    bool
+	buf     bytes.Buffer
+	encoder io.WriteCloser
+}
+
+func (c *compressWriter) WriteHeader(statusCode int) {
+	if c.decided {
+		return
+	}
+
+	c.statusCode = statusCode
+	c.decide()
+
+	if c.skip {
+		c.ResponseWriter.WriteHeader(statusCode)
+	}
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if !c.decided {
+		c.decide()
+
+		if c.skip {
+			c.ResponseWriter.WriteHeader(c.statusCode)
+		}
+	}
+
+	if c.skip {
+		return c.ResponseWriter.Write(b)
+	}
+
+	if c.encoder != nil {
+		return c.encoder.Write(b)
+	}
+
+	n, err := c.buf.Write(b)
+	if err == nil && c.buf.Len() > maxCompressBuffer {
+		err = c.stream()
+	}
+
+	return n, err
+}
+
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		c.decide()
+
+		if c.skip {
+			c.ResponseWriter.WriteHeader(c.statusCode)
+		}
+	}
+
+	if !c.skip {
+		if c.encoder == nil {
+			// nolint
+			c.stream()
+		}
+
+		if f, ok := c.encoder.(interface{ Flush() error }); ok {
+			// nolint
+			f.Flush()
+		}
+	}
+
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped response writer, so the deadlines of the response can be set.
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+// decide skips compression for responses without a body, already encoded or partial responses, and
+// responses which are not text based.
+func (c *compressWriter) decide() {
+	c.decided = true
+
+	header := c.Header()
+	c.skip = c.statusCode < http.StatusOK ||
+		c.statusCode == http.StatusNoContent ||
+		c.statusCode == http.StatusNotModified ||
+		header.Get("Content-Encoding") != "" ||
+		header.Get("Content-Range") != "" ||
+		!compressible(header.Get("Content-Type"))
+}
+
+// stream starts compressing the response while it is written, after the buffered part.
+func (c *compressWriter) stream() error {
+	c.prepareHeader()
+	c.Header().Del("Content-Length")
+	c.ResponseWriter.WriteHeader(c.statusCode)
+
+	c.encoder = newEncoder(c.ResponseWriter, c.encoding)
+
+	_, err := c.encoder.Write(c.buf.Bytes())
+	c.buf.Reset()
+
+	return err
+}
+
+// finish writes the buffered response, compressed unless it is too small, or completes the compressed stream.
+func (c *compressWriter) finish() {
+	if !c.decided {
+		c.decide()
+
+		if c.skip {
+			c.ResponseWriter.WriteHeader(c.statusCode)
+		}
+	}
+
+	if c.skip {
+		return
+	}
+
+	if c.encoder != nil {
+		// nolint
+		c.encoder.Close()
+		return
+	}
+
+	body := c.buf.Bytes()
+
+	if len(body) >= minCompressSize {
+		var compressed bytes.Buffer
+
+		encoder := newEncoder(&compressed, c.encoding)
+		_, err := encoder.Write(body)
+		if err == nil {
+			err = encoder.Close()
+		}
+
+		if err == nil {
+			c.prepareHeader()
+			body = compressed.Bytes()
+		}
+	}
+
+	c.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	c.ResponseWriter.WriteHeader(c.statusCode)
+
+	// nolint
+	c.ResponseWriter.Write(body)
+}
+
+func (c *compressWriter) prepareHeader() {
+	c.Header().Set("Content-Encoding", c.encoding)
+	c.Header().Del("Accept-Ranges")
+}
+
+func newEncoder(w io.Writer, encoding string) io.WriteCloser {
+	if encoding == "br" {
+		return brotli.NewWriterLevel(w, brotliLevel)
+	}
+
+	return gzip.NewWriter(w)
+}
+
+// compressible reports whether the content type is text based.
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	if strings.HasPrefix(mediaType, "text/") || strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml") {
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/x-ndjson", "application/javascript", "application/xml":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// jsonContentType is the content type of json responses in strict mode.
+const jsonContentType = "application/json; charset=utf-8"
+
+var errUnsupportedMediaType = errors.New("content type must be application/json")
+
+// StrictContentType is operating as middleware to reject POST, PUT and PATCH requests with a body, unless
+// sent with 'Content-Type: application/json' (or a '+json' type), with 415. Json responses are sent with
+// the utf-8 charset.
+func StrictContentType(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			if r.ContentLength != 0 && !isJSON(r.Header.Get("Content-Type")) {
+				web.Error(&charsetWriter{ResponseWriter: w}, http.StatusUnsupportedMediaType, errUnsupportedMediaType.Error())
+				return
+			}
+		}
+
+		next.ServeHTTP(&charsetWriter{ResponseWriter: w}, r)
+	})
+}
+
+func isJSON(contentType string) bool {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	if charset, ok := params["charset"]; ok && !strings.EqualFold(charset, "utf-8") {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// charsetWriter adds the utf-8 charset to json responses.
+type charsetWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (c *charsetWriter) WriteHeader(statusCode int) {
+	if !c.wroteHeader {
+		c.wroteHeader = true
+
+		if c.Header().Get("Content-Type") == "application/json" {
+			c.Header().Set("Content-Type", jsonContentType)
+		}
+	}
+
+	c.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (c *charsetWriter) Write(b []byte) (int, error) {
+	if !c.wroteHeader {
+		c.WriteHeader(http.StatusOK)
+	}
+
+	return c.ResponseWriter.Write(b)
+}
+
+func (c *charsetWriter) Flush() {
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped response writer, so the deadlines of the response can be set.
+func (c *charsetWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
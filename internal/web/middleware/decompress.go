@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+var (
+	errUnsupportedEncoding = errors.New("unsupported content encoding")
+	errInvalidEncoding     = errors.New("invalid compressed body")
+)
+
+// Decompress is operating as middleware to decompress request bodies sent with 'Content-Encoding: gzip' or
+// 'deflate', so handlers receive the plain body. Bodies are decompressed while they are read. Other
+// encodings are rejected with 415.
+func Decompress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Content-Encoding")
+		if header == "" || r.Body == nil || r.Body == http.NoBody {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Encodings are listed in the order they were applied, so they are undone in reverse.
+		encodings := strings.Split(header, ",")
+
+		body := r.Body
+		for idx := len(encodings) - 1; idx >= 0; idx-- {
+			encoding := strings.ToLower(strings.TrimSpace(encodings[idx]))
+
+			var err error
+			switch encoding {
+			case "identity", "":
+				continue
+			case "gzip", "x-gzip":
+				body, err = newGzipBody(body)
+			case "deflate":
+				body, err = newDeflateBody(body)
+			default:
+				web.Error(w, http.StatusUnsupportedMediaType, fmt.Sprintf("%s: %s", errUnsupportedEncoding, encoding))
+				return
+			}
+
+			if err != nil {
+				web.Error(w, http.StatusBadRequest, errInvalidEncoding.Error())
+				return
+			}
+		}
+
+		r.Body = body
+		r.ContentLength = -1
+		r.Header.Del("Content-Encoding")
+		r.Header.Del("Content-Length")
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decompressedBody reads the decompressed content, and closes both readers.
+type decompressedBody struct {
+	io.Reader
+	decompressor io.Closer
+	body         io.Closer
+}
+
+func (b *decompressedBody) Close() error {
+	// nolint
+	b.decompressor.Close()
+
+	return b.body.Close()
+}
+
+func newGzipBody(body io.ReadCloser) (io.ReadCloser, error) {
+	zr, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decompressedBody{Reader: zr, decompressor: zr, body: body}, nil
+}
+
+// newDeflateBody decompresses zlib streams, as the 'deflate' encoding is defined, as well as raw deflate
+// streams, which some clients send instead.
+func newDeflateBody(body io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(body)
+
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+
+	// A zlib header uses the deflate method, and read as a 16-bit number it is a multiple of 31.
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		return &decompressedBody{Reader: zr, decompressor: zr, body: body}, nil
+	}
+
+	fr := flate.NewReader(br)
+
+	return &decompressedBody{Reader: fr, decompressor: fr, body: body}, nil
+}
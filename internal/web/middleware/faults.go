@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/chanioxaris/json-server/internal/stub"
+)
+
+// Faults is operating as middleware to break the responses of the requests the pick function returns a fault
+// for, e.g. by chance. The request is served as usual, and its response is broken by the fault: 'hang' never
+// responds, 'reset' resets the connection, 'truncate' closes the connection in the middle of the body,
+// 'malformed' cuts the body in half and 'content-length' declares a shorter body than sent. Responses whose
+// connection cannot be taken over, e.g. of HTTP/2, fail with 500 instead.
+func Faults(pick func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fault := pick(r)
+			if fault == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := &bufferWriter{header: make(http.Header), statusCode: http.StatusOK}
+			next.ServeHTTP(buf, r)
+
+			body := buf.body.Bytes()
+			if len(body) == 0 {
+				body = []byte("{}")
+			}
+
+			switch fault {
+			case stub.FaultHang:
+				<-r.Context().Done()
+			case stub.FaultReset:
+				resetConnection(w)
+			case stub.FaultMalformed:
+				for key, values := range buf.header {
+					w.Header()[key] = values
+				}
+
+				malformed := body[:len(body)/2]
+				w.Header().Set("Content-Length", strconv.Itoa(len(malformed)))
+				w.WriteHeader(buf.statusCode)
+
+				// nolint
+				w.Write(malformed)
+			case stub.FaultTruncate:
+				writeRaw(w, buf, body[:len(body)/2], len(body))
+			case stub.FaultContentLength:
+				writeRaw(w, buf, body, len(body)/2)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+	}
+}
+
+// bufferWriter keeps the status code, headers and body of a response, to break it before it is sent.
+type bufferWriter struct {
+	header      http.Header
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferWriter) WriteHeader(statusCode int) {
+	if !b.wroteHeader {
+		b.wroteHeader = true
+		b.statusCode = statusCode
+	}
+}
+
+func (b *bufferWriter) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+
+	return b.body.Write(p)
+}
+
+// writeRaw writes the response on the taken over connection, with the declared Content-Length whatever the
+// length of the body, and closes the connection.
+func writeRaw(w http.ResponseWriter, buf *bufferWriter, body []byte, contentLength int) {
+	conn, rw, ok := hijack(w)
+	if !ok {
+		return
+	}
+	defer conn.Close()
+
+	header := buf.header.Clone()
+	header.Set("Content-Length", strconv.Itoa(contentLength))
+	header.Set("Connection", "close")
+
+	// Errors are ignored, as the response is broken anyway.
+	fmt.Fprintf(rw, "HTTP/1.1 %d %s\r\n", buf.statusCode, http.StatusText(buf.statusCode))
+	_ = header.Write(rw)
+	_, _ = rw.WriteString("\r\n")
+	_, _ = rw.Write(body)
+	_ = rw.Flush()
+}
+
+// resetConnection closes the connection of the response without responding. TCP connections are reset, instead of
+// closed gracefully.
+func resetConnection(w http.ResponseWriter) {
+	conn, _, ok := hijack(w)
+	if !ok {
+		return
+	}
+
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		// Discard unsent data and send a RST on close.
+		_ = tcpConn.SetLinger(0)
+	}
+
+	conn.Close()
+}
+
+// hijack takes over the connection of the response, which fails with 500 if not possible.
+func hijack(w http.ResponseWriter) (net.Conn, *bufio.ReadWriter, bool) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return nil, nil, false
+	}
+
+	return conn, rw, true
+}
@@ -78,6 +78,11 @@ func (c *responseWriterWrapper) Flush() {
 	}
 }
 
+// Unwrap returns the wrapped response writer, so the deadlines of the response can be set.
+func (c *responseWriterWrapper) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
 func (c *responseWriterWrapper) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	hijacker, ok := c.ResponseWriter.(http.Hijacker)
 	if !ok {
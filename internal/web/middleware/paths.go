@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TrimTrailingSlash is operating as middleware to remove the trailing slash of the request path, so
+// '/posts/' is routed as '/posts'.
+func TrimTrailingSlash(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) > 1 && strings.HasSuffix(r.URL.Path, "/") {
+			r.URL.Path = strings.TrimRight(r.URL.Path, "/")
+			if r.URL.Path == "" {
+				r.URL.Path = "/"
+			}
+
+			r.URL.RawPath = ""
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IgnoreCase is operating as middleware to match the first segment of the request path case-insensitively
+// against the provided segments, e.g. '/Posts/1' is routed as '/posts/1'. The rest of the path, like
+// resource ids, is left untouched.
+func IgnoreCase(segments []string) func(http.Handler) http.Handler {
+	lookup := make(map[string]string, len(segments))
+	for _, segment := range segments {
+		lookup[strings.ToLower(segment)] = segment
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/")
+
+			first, rest := path, ""
+			if idx := strings.Index(path, "/"); idx >= 0 {
+				first, rest = path[:idx], path[idx:]
+			}
+
+			if segment, ok := lookup[strings.ToLower(first)]; ok && segment != first {
+				r.URL.Path = "/" + segment + rest
+				r.URL.RawPath = ""
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Aliases is operating as middleware to route the first segment of the request path by the segment it is an
+// alias of, e.g. '/people/1' is routed as '/users/1' for the alias 'people' of 'users'.
+func Aliases(aliases map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := strings.TrimPrefix(r.URL.Path, "/")
+
+			first, rest := path, ""
+			if idx := strings.Index(path, "/"); idx >= 0 {
+				first, rest = path[:idx], path[idx:]
+			}
+
+			if segment, ok := aliases[first]; ok {
+				r.URL.Path = "/" + segment + rest
+				r.URL.RawPath = ""
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/stub"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// stubWriteTimeout bounds the write of the response of a delayed stub, once the delay is over.
+const stubWriteTimeout = 15 * time.Second
+
+// Stubs is operating as middleware to respond with the registered stub matching the request, except for
+// the requests to the skipped path prefixes. Requests matching no stub fall through.
+func Stubs(registry *stub.Registry, skip ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, prefix := range skip {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			resp, ok := registry.Match(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Stubbed requests skip the router, so they are logged here.
+			Logger(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// The delay, and the hang fault, hold the connection past the write timeout of the server, which
+				// would cut the response off.
+				if resp.Delay > 0 || resp.Fault == stub.FaultHang {
+					web.SetWriteDeadline(w, r, time.Time{})
+				}
+
+				// Requests whose client is gone stop waiting.
+				if resp.Delay > 0 {
+					timer := time.NewTimer(time.Duration(resp.Delay))
+					defer timer.Stop()
+
+					select {
+					case <-timer.C:
+					case <-r.Context().Done():
+						return
+					}
+
+					if resp.Fault != stub.FaultHang {
+						web.SetWriteDeadline(w, r, time.Now().Add(stubWriteTimeout))
+					}
+				}
+
+				respond := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					for key, val := range resp.Headers {
+						w.Header().Set(key, val)
+					}
+
+					web.Success(w, resp.Status, resp.Body)
+				})
+
+				if resp.Fault != "" {
+					Faults(func(*http.Request) string { return resp.Fault })(respond).ServeHTTP(w, r)
+					return
+				}
+
+				respond.ServeHTTP(w, r)
+			})).ServeHTTP(w, r)
+		})
+	}
+}
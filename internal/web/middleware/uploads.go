@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/web"
+)
+
+// maxUploadMemory is the part of a multipart body kept in memory, the rest is buffered on disk.
+const maxUploadMemory = 32 << 20
+
+var errUploadTooLarge = errors.New("request body too large")
+
+// Uploads is operating as middleware to store the files of multipart requests in the provided directory.
+// The request is rewritten to a form-encoded one, with every file field replaced by the url the file is
+// served at, so handlers store the url in the resource. Bodies larger than maxSize bytes are rejected,
+// and the stored files are removed again when the handler responds with an error.
+func Uploads(dir, urlPrefix string, maxSize int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if mediaType != "multipart/form-data" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.ContentLength > maxSize {
+				web.Error(w, http.StatusRequestEntityTooLarge, errUploadTooLarge.Error())
+				return
+			}
+
+			body := &limitedBody{ReadCloser: http.MaxBytesReader(w, r.Body, maxSize), limit: maxSize}
+			r.Body = body
+
+			if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+				if body.exceeded() {
+					web.Error(w, http.StatusRequestEntityTooLarge, errUploadTooLarge.Error())
+					return
+				}
+
+				web.Error(w, http.StatusBadRequest, storage.ErrBadRequest.Error())
+				return
+			}
+			// nolint
+			defer r.MultipartForm.RemoveAll()
+
+			form := url.Values{}
+			for key, values := range r.MultipartForm.Value {
+				form[key] = append(form[key], values...)
+			}
+
+			saved := make([]string, 0)
+			removeSaved := func() {
+				for _, name := range saved {
+					// nolint
+					os.Remove(filepath.Join(dir, name))
+				}
+			}
+
+			for key, files := range r.MultipartForm.File {
+				for _, fh := range files {
+					name, err := saveUpload(dir, fh)
+					if err != nil {
+						removeSaved()
+						web.Error(w, http.StatusInternalServerError, storage.ErrInternalServerError.Error())
+						return
+					}
+
+					saved = append(saved, name)
+					form.Add(key, path.Join(urlPrefix, name))
+				}
+			}
+
+			formBody := form.Encode()
+
+			r.Body = ioutil.NopCloser(strings.NewReader(formBody))
+			r.ContentLength = int64(len(formBody))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			// Reset the parsed forms, so the handler parses the rewritten body.
+			r.Form, r.PostForm, r.MultipartForm = nil, nil, nil
+
+			rww := newResponseWriterWrapper(w)
+			next.ServeHTTP(rww, r)
+
+			// Files of rejected requests are not referenced by any resource.
+			if rww.statusCode >= http.StatusBadRequest {
+				removeSaved()
+			}
+		})
+	}
+}
+
+// limitedBody counts the bytes read from a body limited by http.MaxBytesReader, to tell a body over
+// the limit apart from a malformed one.
+type limitedBody struct {
+	io.ReadCloser
+	limit int64
+	read  int64
+	err   error
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+
+	return n, err
+}
+
+// exceeded reports whether reading failed because the body is over the limit.
+func (b *limitedBody) exceeded() bool {
+	return b.err != nil && b.read >= b.limit
+}
+
+// saveUpload stores the uploaded file under a random name, keeping its extension, and returns the name.
+func saveUpload(dir string, fh *multipart.FileHeader) (string, error) {
+	src, err := fh.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	randomBytes := make([]byte, 16)
+	if _, err = rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+
+	name := hex.EncodeToString(randomBytes) + strings.ToLower(filepath.Ext(filepath.Base(fh.Filename)))
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	dst, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	if _, err = io.Copy(dst, src); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
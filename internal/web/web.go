@@ -2,48 +2,63 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
+	"sync"
 )
 
+// maxPooledBuffer is the capacity above which buffers are dropped instead of reused, so a single large response
+// does not keep its memory alive.
+const maxPooledBuffer = 64 << 10
+
+// buffers reuses the buffers encoding the responses, sparing an allocation per request.
+var buffers = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
 type errorResponse struct {
 	Error string `json:"error"`
 }
 
 // Success response on http request. Contains a json body with the provided data.
 func Success(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.WriteHeader(statusCode)
-
-	if data != nil {
-		dataBytes, err := json.Marshal(data)
-		if err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		_, err = w.Write(dataBytes)
-		if err != nil {
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
+	if data == nil {
+		w.WriteHeader(statusCode)
+		return
 	}
+
+	write(w, statusCode, data)
 }
 
 // Error response on http request. Contains a json body with a single field 'error' with the error message.
 func Error(w http.ResponseWriter, statusCode int, error string) {
-	w.WriteHeader(statusCode)
+	write(w, statusCode, errorResponse{Error: error})
+}
+
+// write encodes the data as the json body of the response, with a pooled buffer.
+func write(w http.ResponseWriter, statusCode int, data interface{}) {
+	buf := buffers.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	defer func() {
+		if buf.Cap() <= maxPooledBuffer {
+			buffers.Put(buf)
+		}
+	}()
 
-	data := errorResponse{Error: error}
-	dataBytes, err := json.Marshal(data)
-	if err != nil {
+	if err := json.NewEncoder(buf).Encode(data); err != nil {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
+	// Headers must be set before writing the status code.
 	w.Header().Set("Content-Type", "application/json")
-	if _, err := w.Write(dataBytes); err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
+	w.WriteHeader(statusCode)
+
+	// The encoder ends the json with a newline, which json.Marshal does not.
+	// nolint
+	w.Write(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
 }
@@ -0,0 +1,339 @@
+// Package jsonserver embeds a JSON server in Go programs. It returns a standard http.Handler serving the resources
+// of a json data source, to mount on a mux or in an httptest.Server:
+//
+//	h, err := jsonserver.New(jsonserver.WithFile("db.json"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	srv := httptest.NewServer(h)
+//	defer srv.Close()
+package jsonserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/chanioxaris/json-server/internal/config"
+	"github.com/chanioxaris/json-server/internal/handler"
+	"github.com/chanioxaris/json-server/internal/serializer"
+	"github.com/chanioxaris/json-server/internal/server"
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/internal/stub"
+)
+
+var (
+	// ErrMissingData is returned when none of WithFile, WithReader or WithData is given.
+	ErrMissingData = errors.New("missing data source")
+	// ErrFileNotFound is returned when the file of WithFile cannot be read.
+	ErrFileNotFound = server.ErrFileNotFound
+	// ErrFailedParseData is returned when the data source holds invalid resources.
+	ErrFailedParseData = errors.New("failed to parse data")
+)
+
+// Types to implement the storage drivers of other backends, registered with RegisterDriver.
+type (
+	// Driver opens the backend keeping the resources of a watch file.
+	Driver = storage.Driver
+	// Source describes the resources a backend is opened for.
+	Source = storage.Source
+	// Backend is a database of collections of resources, opened by a driver.
+	Backend = storage.Backend
+	// Storage handles the operations on the resources of a collection.
+	Storage = storage.Storage
+	// Resource is a single resource, with its fields by name.
+	Resource = storage.Resource
+	// Database holds the resources of every collection, by key.
+	Database = storage.Database
+)
+
+// Errors of the storages of the drivers, mapped to the status codes of the responses.
+var (
+	// ErrResourceNotFound is returned by storages for missing resources and collections.
+	ErrResourceNotFound = storage.ErrResourceNotFound
+	// ErrResourceAlreadyExists is returned by storages when creating a resource with an existing id.
+	ErrResourceAlreadyExists = storage.ErrResourceAlreadyExists
+)
+
+// RegisterDriver makes the storage driver available by the name, to WithStorage and the storage flag of binaries
+// importing it. It panics if the driver is nil, or registered twice. Drivers usually register in the init function
+// of their package:
+//
+//	func init() {
+//		jsonserver.RegisterDriver("dynamodb", &Driver{})
+//	}
+func RegisterDriver(name string, driver Driver) {
+	storage.Register(name, driver)
+}
+
+// Drivers returns the names of the registered storage drivers, sorted.
+func Drivers() []string {
+	return storage.Drivers()
+}
+
+// Types to implement the serializers of other response formats, registered with RegisterSerializer.
+type (
+	// Serializer encodes responses in a format.
+	Serializer = serializer.Serializer
+	// Response is the response of a handler, with its json body decoded, to encode.
+	Response = serializer.Response
+)
+
+// RegisterSerializer makes the serializer available by the format name, to the format of the configuration, and
+// to requests asking for any of the media types with the Accept header. It panics if the serializer is nil, or
+// registered twice.
+func RegisterSerializer(name string, s Serializer, mediaTypes ...string) {
+	serializer.Register(name, s, mediaTypes...)
+}
+
+// Formats returns the names of the registered response formats, sorted.
+func Formats() []string {
+	return serializer.Formats()
+}
+
+// Option configures the handler returned by New.
+type Option func(*settings)
+
+type settings struct {
+	opts       server.Options
+	data       storage.Database
+	configFile string
+	stubsFile  string
+	err        error
+}
+
+// WithFile serves the resources of the json file, persisting writes to it.
+func WithFile(filename string) Option {
+	return func(s *settings) {
+		s.opts.File = filename
+	}
+}
+
+// WithLenient skips the invalid resources and records of the file of WithFile, instead of failing.
+func WithLenient() Option {
+	return func(s *settings) {
+		s.opts.Lenient = true
+	}
+}
+
+// WithStorage keeps the resources of the file of WithFile in the storage backend: "file" itself, "memory" seeded
+// from it, "sqlite" next to it, e.g. db.sqlite, which requires a build with the sqlite tag and cgo, or the backend
+// of a driver registered with RegisterDriver.
+func WithStorage(backend string) Option {
+	return func(s *settings) {
+		s.opts.Storage = backend
+	}
+}
+
+// WithReader serves the resources of the json read from the reader, kept in memory.
+func WithReader(r io.Reader) Option {
+	return func(s *settings) {
+		contentBytes, err := ioutil.ReadAll(r)
+		if err != nil {
+			s.err = fmt.Errorf("%w: %v", ErrFailedParseData, err)
+			return
+		}
+
+		s.parse(contentBytes)
+	}
+}
+
+// WithData serves a copy of the resources, kept in memory, so the map is never modified.
+func WithData(data map[string]interface{}) Option {
+	return func(s *settings) {
+		contentBytes, err := json.Marshal(data)
+		if err != nil {
+			s.err = fmt.Errorf("%w: %v", ErrFailedParseData, err)
+			return
+		}
+
+		s.parse(contentBytes)
+	}
+}
+
+// WithConfigFile customizes individual resources with the configuration file.
+func WithConfigFile(filename string) Option {
+	return func(s *settings) {
+		s.configFile = filename
+	}
+}
+
+// WithStubsFile serves the stubbed routes of the file.
+func WithStubsFile(filename string) Option {
+	return func(s *settings) {
+		s.stubsFile = filename
+	}
+}
+
+// WithReadOnly rejects requests modifying the resources with 403.
+func WithReadOnly() Option {
+	return withHandlerOption(handler.WithReadOnly())
+}
+
+// WithCredentials requires the static access token as bearer token, or the credentials of one of the users, by
+// username, with basic authentication, on all requests, or only on the ones modifying data if writesOnly is set.
+func WithCredentials(token string, users map[string]string, writesOnly bool) Option {
+	return withHandlerOption(handler.WithCredentials(token, users, writesOnly))
+}
+
+// WithIgnoreCase matches routes regardless of their case.
+func WithIgnoreCase() Option {
+	return withHandlerOption(handler.WithIgnoreCase())
+}
+
+// WithIgnoreTrailingSlash matches routes regardless of a trailing slash.
+func WithIgnoreTrailingSlash() Option {
+	return withHandlerOption(handler.WithIgnoreTrailingSlash())
+}
+
+// WithKeyCase converts the field names of the responses to camel, snake or kebab case.
+func WithKeyCase(keyCase string) Option {
+	return func(s *settings) {
+		if err := storage.ValidateKeyCase(keyCase); err != nil {
+			s.err = err
+			return
+		}
+
+		s.opts.HandlerOptions = append(s.opts.HandlerOptions, handler.WithKeyCase(keyCase))
+	}
+}
+
+// WithCompression compresses the responses of clients accepting it.
+func WithCompression() Option {
+	return withHandlerOption(handler.WithCompression())
+}
+
+// WithStrictContentType rejects request bodies which are not json.
+func WithStrictContentType() Option {
+	return withHandlerOption(handler.WithStrictContentType())
+}
+
+// WithStrictQueries rejects list requests with unknown or ignored query parameters.
+func WithStrictQueries() Option {
+	return withHandlerOption(handler.WithStrictQueries())
+}
+
+// WithMaxUnpaginated rejects list requests without pagination matching more than max resources.
+func WithMaxUnpaginated(max int) Option {
+	return withHandlerOption(handler.WithMaxUnpaginated(max))
+}
+
+// WithHeavyLimit serves up to concurrency heavy requests at once, while up to queue of them wait.
+func WithHeavyLimit(concurrency, queue int) Option {
+	return withHandlerOption(handler.WithHeavyLimit(concurrency, queue))
+}
+
+// WithResponseCache caches the responses of GET requests to the resources for the ttl, dropped on writes.
+func WithResponseCache(ttl time.Duration) Option {
+	return withHandlerOption(handler.WithResponseCache(ttl))
+}
+
+// WithAllowOrigins allows the cross-origin requests of the origins to all resources, or of any origin with '*'.
+func WithAllowOrigins(origins ...string) Option {
+	return withHandlerOption(handler.WithAllowOrigins(origins...))
+}
+
+// WithDelay delays all responses by the delay, simulating network latency.
+func WithDelay(delay time.Duration) Option {
+	return withHandlerOption(handler.WithDelay(delay))
+}
+
+// WithChaos fails the rate of requests to the resources, between 0 and 1, with 500 or 503.
+func WithChaos(rate float64) Option {
+	return withHandlerOption(handler.WithChaos(rate))
+}
+
+// WithMockHeaders delays and fails requests on demand, with the X-Mock-Delay and X-Mock-Status headers.
+func WithMockHeaders() Option {
+	return withHandlerOption(handler.WithMockHeaders())
+}
+
+// WithFakerSeed seeds the fake values of the resources, so they are reproducible.
+func WithFakerSeed(seed int64) Option {
+	return withHandlerOption(handler.WithFakerSeed(seed))
+}
+
+// WithCapture keeps the last size requests, served on /__requests.
+func WithCapture(size int) Option {
+	return withHandlerOption(handler.WithCapture(size))
+}
+
+// WithAutoResources creates the resources written to, which do not exist.
+func WithAutoResources() Option {
+	return withHandlerOption(handler.WithAutoResources())
+}
+
+// WithTimestamps stamps resources with createdAt and updatedAt fields, and expires them once older than the ttl,
+// if positive.
+func WithTimestamps(ttl time.Duration) Option {
+	return func(s *settings) {
+		s.opts.Timestamps = true
+		s.opts.TTL = ttl
+	}
+}
+
+// WithUploads stores the uploaded files of up to maxSize bytes in the directory.
+func WithUploads(dir string, maxSize int64) Option {
+	return withHandlerOption(handler.WithUploads(dir), handler.WithUploadLimit(maxSize))
+}
+
+// New returns the handler serving the resources of the data source, given by WithFile, WithReader or WithData.
+func New(opts ...Option) (http.Handler, error) {
+	s := &settings{}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if s.opts.File == "" && s.data == nil {
+		return nil, ErrMissingData
+	}
+
+	s.opts.Data = s.data
+
+	if s.configFile != "" {
+		cfg, err := config.Load(s.configFile)
+		if err != nil {
+			return nil, err
+		}
+
+		s.opts.Config = cfg
+	}
+
+	if s.stubsFile != "" {
+		stubs, err := stub.Load(s.stubsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		s.opts.HandlerOptions = append(s.opts.HandlerOptions, handler.WithStubs(stubs))
+	}
+
+	return server.New(s.opts)
+}
+
+// parse sets the resources of the json held in memory.
+func (s *settings) parse(contentBytes []byte) {
+	data, err := storage.ParseDatabase(contentBytes, "")
+	if err != nil {
+		s.err = fmt.Errorf("%w: %v", ErrFailedParseData, err)
+		return
+	}
+
+	s.data = data
+}
+
+// withHandlerOption configures the handler with the options.
+func withHandlerOption(opts ...handler.Option) Option {
+	return func(s *settings) {
+		s.opts.HandlerOptions = append(s.opts.HandlerOptions, opts...)
+	}
+}
@@ -0,0 +1,239 @@
+package jsonserver_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chanioxaris/json-server/internal/storage"
+	"github.com/chanioxaris/json-server/jsonserver"
+)
+
+const testData = `{"posts": [{"id": "1", "title": "json-server"}]}`
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []jsonserver.Option
+	}{
+		{
+			name: "reader",
+			opts: []jsonserver.Option{jsonserver.WithReader(strings.NewReader(testData))},
+		},
+		{
+			name: "data",
+			opts: []jsonserver.Option{jsonserver.WithData(map[string]interface{}{
+				"posts": []interface{}{map[string]interface{}{"id": "1", "title": "json-server"}},
+			})},
+		},
+	}
+
+	for _, tc := range testCases {
+		h, err := jsonserver.New(tc.opts...)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+
+		srv := httptest.NewServer(h)
+
+		got := getTitle(t, srv.URL+"/posts/1")
+		if got != "json-server" {
+			t.Fatalf("%s: expected title %q, but got %q", tc.name, "json-server", got)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestNew_File(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "db.json")
+	if err = ioutil.WriteFile(file, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := jsonserver.New(jsonserver.WithFile(file))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/posts", "application/json", bytes.NewBufferString(`{"id": "2", "title": "persisted"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(content), "persisted") {
+		t.Fatalf("expected the created resource to be persisted, but got %s", content)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		opts        []jsonserver.Option
+		expectedErr error
+	}{
+		{
+			name:        "missing data",
+			expectedErr: jsonserver.ErrMissingData,
+		},
+		{
+			name:        "missing file",
+			opts:        []jsonserver.Option{jsonserver.WithFile("missing.json")},
+			expectedErr: jsonserver.ErrFileNotFound,
+		},
+		{
+			name:        "invalid json",
+			opts:        []jsonserver.Option{jsonserver.WithReader(strings.NewReader(`{"posts": `))},
+			expectedErr: jsonserver.ErrFailedParseData,
+		},
+	}
+
+	for _, tc := range testCases {
+		if _, err := jsonserver.New(tc.opts...); !errors.Is(err, tc.expectedErr) {
+			t.Fatalf("%s: expected error %v, but got %v", tc.name, tc.expectedErr, err)
+		}
+	}
+}
+
+func getTitle(t *testing.T, url string) string {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var got map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	title, _ := got["title"].(string)
+
+	return title
+}
+
+// testDriver keeps the resources in memory, recording the sources it opens.
+type testDriver struct {
+	sources []jsonserver.Source
+}
+
+func (d *testDriver) Open(src jsonserver.Source) (jsonserver.Backend, error) {
+	d.sources = append(d.sources, src)
+
+	return storage.NewMemoryDB(src.Data), nil
+}
+
+func TestRegisterDriver(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jsonserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "db.json")
+	if err = ioutil.WriteFile(file, []byte(testData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	driver := &testDriver{}
+	jsonserver.RegisterDriver("test", driver)
+
+	h, err := jsonserver.New(jsonserver.WithFile(file), jsonserver.WithStorage("test"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	if got := getTitle(t, srv.URL+"/posts/1"); got != "json-server" {
+		t.Fatalf("expected title %q, but got %q", "json-server", got)
+	}
+
+	if len(driver.sources) != 1 || driver.sources[0].File != file {
+		t.Fatalf("expected the driver to open %s, but got %+v", file, driver.sources)
+	}
+
+	if _, err = jsonserver.New(jsonserver.WithFile(file), jsonserver.WithStorage("missing")); err == nil {
+		t.Fatal("expected error for an unregistered driver, but got nil")
+	}
+}
+
+// testSerializer encodes responses as the title of their records.
+type testSerializer struct{}
+
+func (testSerializer) ContentType() string {
+	return "text/plain"
+}
+
+func (testSerializer) Serialize(w io.Writer, resp jsonserver.Response) error {
+	record, _ := resp.Data.(map[string]interface{})
+
+	_, err := fmt.Fprint(w, record["title"])
+
+	return err
+}
+
+func TestRegisterSerializer(t *testing.T) {
+	jsonserver.RegisterSerializer("title", testSerializer{}, "text/plain")
+
+	h, err := jsonserver.New(jsonserver.WithReader(strings.NewReader(testData)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/posts/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req.Header.Set("Accept", "text/plain")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != "json-server" || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Fatalf("expected title %q as text, but got %q as %q", "json-server", body, resp.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,26 @@
+// Package jsonservertest provides utilities for running a JSON server within Go tests.
+package jsonservertest
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/chanioxaris/json-server/jsonserver"
+)
+
+// StartServer starts a JSON server on a random local port, serving the provided json fixture from memory.
+// It returns the base URL of the server and a function that shuts it down. Changes made through the API
+// are never persisted, so every server starts from the same fixture.
+func StartServer(t testing.TB, fixtureJSON []byte) (string, func()) {
+	t.Helper()
+
+	h, err := jsonserver.New(jsonserver.WithReader(bytes.NewReader(fixtureJSON)))
+	if err != nil {
+		t.Fatalf("jsonservertest: failed to start server: %v", err)
+	}
+
+	server := httptest.NewServer(h)
+
+	return server.URL, server.Close
+}
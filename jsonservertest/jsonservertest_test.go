@@ -0,0 +1,63 @@
+package jsonservertest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/chanioxaris/json-server/jsonservertest"
+)
+
+const testFixture = `{
+  "posts": [
+    {"id": "1", "title": "json-server", "author": "chanioxaris"}
+  ]
+}`
+
+func TestStartServer(t *testing.T) {
+	url, cleanup := jsonservertest.StartServer(t, []byte(testFixture))
+	defer cleanup()
+
+	resp, err := http.Get(url + "/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status code %v, but got %v", http.StatusOK, resp.StatusCode)
+	}
+
+	var got map[string]interface{}
+	if err = json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{"id": "1", "title": "json-server", "author": "chanioxaris"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected body %v, but got %v", expected, got)
+	}
+}
+
+func TestStartServerIsolation(t *testing.T) {
+	newPost := []byte(`{"id": "2", "title": "new post"}`)
+
+	// Changes made on one server must not leak to another one started from the same fixture.
+	for idx := 0; idx < 2; idx++ {
+		url, cleanup := jsonservertest.StartServer(t, []byte(testFixture))
+
+		resp, err := http.Post(url+"/posts", "application/json", bytes.NewReader(newPost))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected status code %v, but got %v", http.StatusCreated, resp.StatusCode)
+		}
+
+		cleanup()
+	}
+}